@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const defaultConfigArchiveName = "credimi-config.zip"
+
+var (
+	configOutput     string
+	configDryRun     bool
+	configOnConflict string
+)
+
+// errConfigImportBlocked is returned when the instance refused to apply an
+// import because of conflicts or invalid resources. The report is printed.
+var errConfigImportBlocked = errors.New(
+	"import not applied: fix the conflicts and errors reported above",
+)
+
+// NewConfigCmd creates the "config" command, which moves the configuration
+// of an organization between instances.
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Export or import the configuration of your organization",
+	}
+	cmd.AddCommand(NewConfigExportCmd())
+	cmd.AddCommand(NewConfigImportCmd())
+	return cmd
+}
+
+// NewConfigExportCmd creates the "config export" subcommand.
+func NewConfigExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Download the configuration of your organization as a zip archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			path, err := exportConfig(cmd.Context(), token, configOutput)
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+
+	addConfigFlags(cmd)
+	cmd.Flags().StringVarP(
+		&configOutput,
+		"output",
+		"o",
+		"",
+		"Path of the archive (default: the name suggested by the instance)",
+	)
+	return cmd
+}
+
+// NewConfigImportCmd creates the "config import" subcommand.
+func NewConfigImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Import a configuration archive into your organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return importConfig(cmd.Context(), token, args[0], configDryRun, configOnConflict)
+		},
+	}
+
+	addConfigFlags(cmd)
+	cmd.Flags().BoolVar(
+		&configDryRun,
+		"dry-run",
+		false,
+		"Report what the import would do without changing anything",
+	)
+	cmd.Flags().StringVar(
+		&configOnConflict,
+		"on-conflict",
+		"fail",
+		"What to do with resources that already exist: fail, skip or overwrite",
+	)
+	return cmd
+}
+
+func addConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&apiKey, "api-key", "k", "", "API key for authentication")
+	cmd.MarkFlagRequired("api-key")
+	cmd.Flags().
+		StringVarP(&instanceURL, "instance", "i", "https://credimi.io", "URL of the PocketBase instance")
+}
+
+// exportConfig downloads the configuration archive to output, or to the file
+// name suggested by the instance, and returns the path written.
+func exportConfig(ctx context.Context, token string, output string) (string, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		utils.JoinURL(instanceURL, "api", "organizations", "my", "config", "export"),
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call config export endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("config export failed (%d): %s", resp.StatusCode, b)
+	}
+
+	if output == "" {
+		output = suggestedFilename(resp.Header.Get("Content-Disposition"))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return output, nil
+}
+
+// suggestedFilename returns the base name of the Content-Disposition file
+// name, so that a response cannot write outside the working directory.
+func suggestedFilename(disposition string) string {
+	_, params, err := mime.ParseMediaType(disposition)
+	if err != nil {
+		return defaultConfigArchiveName
+	}
+	name := filepath.Base(params["filename"])
+	if name == "." || name == "/" || name == ".." {
+		return defaultConfigArchiveName
+	}
+	return name
+}
+
+// importConfig uploads the archive at path and prints the import report.
+func importConfig(
+	ctx context.Context,
+	token string,
+	path string,
+	dryRun bool,
+	onConflict string,
+) error {
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("dry_run", strconv.FormatBool(dryRun))
+	query.Set("on_conflict", onConflict)
+	importURL := utils.JoinURL(
+		instanceURL,
+		"api",
+		"organizations",
+		"my",
+		"config",
+		"import",
+	) + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		importURL,
+		bytes.NewReader(archive),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call config import endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read import response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		return fmt.Errorf("config import failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var report map[string]any
+	if err := json.Unmarshal(body, &report); err != nil {
+		return fmt.Errorf("failed to decode import report: %w", err)
+	}
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return errConfigImportBlocked
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigCmdSubcommands(t *testing.T) {
+	cmd := NewConfigCmd()
+	export, _, err := cmd.Find([]string{"export"})
+	require.NoError(t, err)
+	require.NotNil(t, export.Flag("output"))
+
+	imp, _, err := cmd.Find([]string{"import"})
+	require.NoError(t, err)
+	require.Equal(t, "fail", imp.Flag("on-conflict").DefValue)
+	require.NotNil(t, imp.Flag("dry-run"))
+	require.Equal(
+		t,
+		[]string{"true"},
+		imp.Flag("api-key").Annotations[cobra.BashCompOneRequiredFlag],
+	)
+	require.Error(t, imp.Args(imp, nil))
+}
+
+func TestExportConfigWritesSuggestedFile(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/organizations/my/config/export", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Disposition", `attachment; filename="../acme-config.zip"`)
+		_, _ = w.Write([]byte("zip-bytes"))
+	}))
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	t.Chdir(t.TempDir())
+	path, err := exportConfig(context.Background(), "token", "")
+	require.NoError(t, err)
+	require.Equal(t, "acme-config.zip", path)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "zip-bytes", string(content))
+}
+
+func TestExportConfigFailure(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("organization_role_not_allowed"))
+	}))
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	output := filepath.Join(t.TempDir(), "config.zip")
+	_, err := exportConfig(context.Background(), "token", output)
+	require.ErrorContains(t, err, "config export failed (403)")
+	require.NoFileExists(t, output)
+}
+
+func TestImportConfigPrintsReport(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "config.zip")
+	require.NoError(t, os.WriteFile(archive, []byte("zip-bytes"), 0o600))
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/organizations/my/config/import", r.URL.Path)
+		require.Equal(t, "true", r.URL.Query().Get("dry_run"))
+		require.Equal(t, "skip", r.URL.Query().Get("on_conflict"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "zip-bytes", string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dry_run":true,"counts":{"create":2}}`))
+	}))
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, importConfig(context.Background(), "token", archive, true, "skip"))
+	})
+	require.Contains(t, output, `"create": 2`)
+}
+
+func TestImportConfigBlocked(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "config.zip")
+	require.NoError(t, os.WriteFile(archive, []byte("zip-bytes"), 0o600))
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"items":[{"path":"acme/wallet","action":"conflict"}]}`))
+	}))
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	var err error
+	output := captureStdout(t, func() {
+		err = importConfig(context.Background(), "token", archive, false, "fail")
+	})
+	require.ErrorIs(t, err, errConfigImportBlocked)
+	require.Contains(t, output, "acme/wallet")
+}
+
+func TestImportConfigFailure(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "config.zip")
+	require.NoError(t, os.WriteFile(archive, []byte("nope"), 0o600))
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid_archive"))
+	}))
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	err := importConfig(context.Background(), "token", archive, false, "fail")
+	require.ErrorContains(t, err, "config import failed (400): invalid_archive")
+}
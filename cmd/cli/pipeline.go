@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/forkbombeu/credimi/pkg/apiclient"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
 	"github.com/spf13/cobra"
@@ -24,6 +26,9 @@ var (
 	yamlPath    string
 	apiKey      string
 	instanceURL string
+	// idempotencyKey makes a retried command return the pipeline started by
+	// the first attempt instead of starting another one.
+	idempotencyKey string
 )
 
 const (
@@ -61,8 +66,15 @@ func NewPipelineCmd() *cobra.Command {
 	}
 
 	addPipelineFlags(cmd)
+	cmd.Flags().StringVar(
+		&idempotencyKey,
+		"idempotency-key",
+		"",
+		"Key that makes retries return the original run instead of starting a new one",
+	)
 	cmd.AddCommand(NewSchemaCmd())
 	cmd.AddCommand(NewPipelineStoreCmd())
+	cmd.AddCommand(NewPipelineWaitCmd())
 	return cmd
 }
 
@@ -109,35 +121,21 @@ func addPipelineFlags(cmd *cobra.Command) {
 		StringVarP(&instanceURL, "instance", "i", "https://credimi.io", "URL of the PocketBase instance")
 }
 
+// newAPIClient returns a client for the configured instance. It resolves
+// http.DefaultClient on every request, like the hand-written calls below.
+func newAPIClient(opts ...apiclient.Option) *apiclient.Client {
+	return apiclient.New(instanceURL, opts...)
+}
+
 func authenticate(ctx context.Context) (string, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		"GET",
-		utils.JoinURL(instanceURL, "api", "apikey", "authenticate"),
-		nil,
-	)
-	if err != nil {
-		return "", err
+	out, err := newAPIClient(apiclient.WithAPIKey(apiKey)).ApiKeyAuthenticate(ctx)
+	var apiErr *apiclient.Error
+	if errors.As(err, &apiErr) {
+		return "", fmt.Errorf("auth failed: %s", apiErr.Body)
 	}
-	req.Header.Set("Credimi-Api-Key", apiKey)
-
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("auth failed: %s", b)
-	}
-
-	var out struct {
-		Token string `json:"token"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", fmt.Errorf("failed to decode auth response: %w", err)
-	}
 
 	return out.Token, nil
 }
@@ -146,37 +144,18 @@ func getMyOrganization(
 	ctx context.Context,
 	token string,
 ) (orgID string, canonName string, err error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		"GET",
-		utils.JoinURL(instanceURL, "api", "organizations", "my"),
-		nil,
-	)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to create org request: %w", err)
+	orgData, err := newAPIClient(apiclient.WithToken(token)).OrganizationGet(ctx)
+	var apiErr *apiclient.Error
+	if errors.As(err, &apiErr) {
+		return "", "", fmt.Errorf("failed to get organization: %s", string(apiErr.Body))
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to call get organization endpoint: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("failed to get organization: %s", string(body))
-	}
-
-	var orgData struct {
-		Id             string `json:"id"`
-		CanonifiedName string `json:"canonified_name"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&orgData); err != nil {
-		return "", "", fmt.Errorf("failed to decode org response: %w", err)
-	}
 
-	return orgData.Id, orgData.CanonifiedName, nil
+	orgID, _ = orgData["id"].(string)
+	canonName, _ = orgData["canonified_name"].(string)
+	return orgID, canonName, nil
 }
 
 type WorkflowYAML struct {
@@ -196,21 +175,6 @@ type PipelineCLIInput struct {
 	YAML string
 }
 
-type pipelineQueueResponse struct {
-	Status            string   `json:"status"`
-	TicketID          string   `json:"ticket_id"`
-	EnqueuedAt        string   `json:"enqueued_at"`
-	RunnerIDs         []string `json:"runner_ids"`
-	Position          int      `json:"position"`
-	LineLen           int      `json:"line_len"`
-	WorkflowID        string   `json:"workflow_id"`
-	RunID             string   `json:"run_id"`
-	WorkflowNamespace string   `json:"workflow_namespace"`
-	PipelineURL       string   `json:"pipeline_url"`
-	RunURL            string   `json:"run_url"`
-	ErrorMessage      string   `json:"error_message"`
-}
-
 // Checks for existing pipeline, otherwise creates
 func findOrCreatePipeline(
 	ctx context.Context,
@@ -391,37 +355,6 @@ func readPipelineInput() (*PipelineCLIInput, error) {
 	}, nil
 }
 
-// postPipelineRequest sends a POST request to a pipeline endpoint and returns status/body.
-func postPipelineRequest(
-	ctx context.Context,
-	token string,
-	path string,
-	payload []byte,
-) (int, []byte, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		utils.JoinURL(instanceURL, "api", "pipeline", path),
-		bytes.NewBuffer(payload),
-	)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to create %s request: %w", path, err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to call pipeline %s endpoint: %w", path, err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	return resp.StatusCode, body, nil
-}
-
 // decodeJSONPayload parses JSON responses and falls back to raw strings.
 func decodeJSONPayload(respBody []byte) any {
 	var respJSON any
@@ -432,35 +365,37 @@ func decodeJSONPayload(respBody []byte) any {
 }
 
 func startPipeline(ctx context.Context, token string, canonName string, rec map[string]any) error {
-	payload := map[string]any{
-		"yaml":                rec["yaml"].(string),
-		"pipeline_identifier": fmt.Sprintf("%s/%s", canonName, rec["canonified_name"].(string)),
+	input := apiclient.PipelineQueueInput{
+		YAML:               rec["yaml"].(string),
+		PipelineIdentifier: fmt.Sprintf("%s/%s", canonName, rec["canonified_name"].(string)),
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal start payload: %w", err)
+	var queueBody []byte
+	opts := []apiclient.RequestOption{apiclient.WithRawResponse(&queueBody)}
+	if idempotencyKey != "" {
+		opts = append(opts, apiclient.WithHeader("Idempotency-Key", idempotencyKey))
 	}
-
-	queueStatus, queueBody, err := postPipelineRequest(ctx, token, "queue", body)
-	if err != nil {
-		return err
-	}
-	if queueStatus != http.StatusOK {
+	queueResp, err := newAPIClient(apiclient.WithToken(token)).PipelineQueue(ctx, input, opts...)
+	var apiErr *apiclient.Error
+	if errors.As(err, &apiErr) {
 		return printJSON(map[string]any{
-			"status":  queueStatus,
-			"payload": decodeJSONPayload(queueBody),
+			"status":  apiErr.StatusCode,
+			"payload": decodeJSONPayload(apiErr.Body),
 		})
 	}
-
-	var queueResp pipelineQueueResponse
-	if err := json.Unmarshal(queueBody, &queueResp); err != nil {
+	if err != nil && queueBody != nil {
 		return fmt.Errorf("failed to decode queue response: %w", err)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to call pipeline queue endpoint: %w", err)
+	}
 
 	switch queueResp.Status {
 	case "queued", "starting":
-		position := queueResp.Position + 1
+		position := 1
+		if queueResp.Position != nil {
+			position = *queueResp.Position + 1
+		}
 		return printJSON(map[string]any{
 			"status":               queueResp.Status,
 			"ticket_id":            queueResp.TicketID,
@@ -473,9 +408,9 @@ func startPipeline(ctx context.Context, token string, canonName string, rec map[
 	case "running":
 		return printJSON(map[string]any{
 			"status":               queueResp.Status,
+			"pipeline_id":          rec["id"],
 			"workflow_id":          queueResp.WorkflowID,
 			"run_id":               queueResp.RunID,
-			"workflow_namespace":   queueResp.WorkflowNamespace,
 			pipelineURLResponseKey: queueResp.PipelineURL,
 			"run_url":              queueResp.RunURL,
 		})
@@ -38,11 +38,11 @@ func TestNewPipelineCmdFlagsAndSubcommands(t *testing.T) {
 	)
 
 	subcommands := cmd.Commands()
-	require.Len(t, subcommands, 2)
+	require.Len(t, subcommands, 3)
 	require.ElementsMatch(
 		t,
-		[]string{"schema", "store"},
-		[]string{subcommands[0].Use, subcommands[1].Use},
+		[]string{"schema", "store", "wait"},
+		[]string{subcommands[0].Name(), subcommands[1].Name(), subcommands[2].Name()},
 	)
 }
 
@@ -388,6 +388,7 @@ func TestStartPipelineQueuesRunnerPipelines(t *testing.T) {
 // TestStartPipelineHandlesStartedPipelines verifies started output for non-runner pipelines.
 func TestStartPipelineHandlesStartedPipelines(t *testing.T) {
 	rec := map[string]any{
+		"id":              "pipe-1",
 		"yaml":            "name: demo",
 		"canonified_name": "pipeline123",
 	}
@@ -416,6 +417,7 @@ func TestStartPipelineHandlesStartedPipelines(t *testing.T) {
 	require.NoError(t, json.Unmarshal([]byte(output), &got))
 	require.Equal(t, "running", got["status"])
 	require.NotContains(t, got, "mode")
+	require.Equal(t, "pipe-1", got["pipeline_id"])
 	require.Equal(t, "wf-123", got["workflow_id"])
 	require.Equal(t, "run-456", got["run_id"])
 	require.Equal(
@@ -544,6 +546,32 @@ func TestStartPipelineDecodeQueueError(t *testing.T) {
 	require.Contains(t, err.Error(), "failed to decode queue response")
 }
 
+func TestStartPipelineSendsIdempotencyKey(t *testing.T) {
+	rec := map[string]any{
+		"yaml":            "name: demo",
+		"canonified_name": "pipeline123",
+	}
+
+	prevKey := idempotencyKey
+	idempotencyKey = "ci-run-42"
+	defer func() { idempotencyKey = prevKey }()
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "ci-run-42", r.Header.Get("Idempotency-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"queued","ticket_id":"ticket-1"}`))
+	}))
+	defer server.Close()
+
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, startPipeline(context.Background(), "token", "org", rec))
+	})
+	require.Contains(t, output, "ticket-1")
+}
+
 func overrideHTTPDefaults(server *testServer) func() {
 	prevURL := instanceURL
 	prevClient := http.DefaultClient
@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/apiclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	pipelineEventDone  = "done"
+	pipelineEventError = "error"
+
+	// pipelineWaitReconnects is how many times wait resumes a stream that was
+	// closed before the run finished.
+	pipelineWaitReconnects = 3
+	maxPipelineEventBytes  = 1024 * 1024
+)
+
+// NewPipelineWaitCmd creates the "pipeline wait" subcommand, which follows
+// the progress of a run until it finishes.
+func NewPipelineWaitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait <pipeline-id> <workflow-id> <run-id>",
+		Short: "Print the progress of a pipeline run until it finishes",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			result, err := waitPipeline(cmd.Context(), token, args[0], args[1], args[2], os.Stdout)
+			if err != nil {
+				return err
+			}
+			if result != "success" {
+				return fmt.Errorf("pipeline finished with result %q", result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&apiKey, "api-key", "k", "", "API key for authentication")
+	cmd.MarkFlagRequired("api-key")
+	cmd.Flags().
+		StringVarP(&instanceURL, "instance", "i", "https://credimi.io", "URL of the PocketBase instance")
+	return cmd
+}
+
+// waitPipeline writes one JSON line per progress event to out and returns the
+// run result once the stream reports it.
+func waitPipeline(
+	ctx context.Context,
+	token string,
+	pipelineID string,
+	workflowID string,
+	runID string,
+	out io.Writer,
+) (string, error) {
+	params := apiclient.PipelineExecutionEventsParams{
+		Id:         pipelineID,
+		WorkflowId: workflowID,
+		RunId:      runID,
+	}
+
+	lastEventID := ""
+	for attempt := 0; ; attempt++ {
+		result, done, err := streamPipelineEvents(ctx, token, params, &lastEventID, out)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return result, nil
+		}
+		if attempt >= pipelineWaitReconnects {
+			return "", fmt.Errorf("event stream closed before the pipeline finished")
+		}
+	}
+}
+
+func streamPipelineEvents(
+	ctx context.Context,
+	token string,
+	params apiclient.PipelineExecutionEventsParams,
+	lastEventID *string,
+	out io.Writer,
+) (string, bool, error) {
+	var opts []apiclient.RequestOption
+	if *lastEventID != "" {
+		opts = append(opts, apiclient.WithHeader("Last-Event-ID", *lastEventID))
+	}
+
+	resp, err := newAPIClient(apiclient.WithToken(token)).
+		PipelineExecutionEvents(ctx, params, opts...)
+	var apiErr *apiclient.Error
+	if errors.As(err, &apiErr) {
+		return "", false, fmt.Errorf(
+			"events request failed (%d): %s",
+			apiErr.StatusCode,
+			apiErr.Body,
+		)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to call pipeline events endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPipelineEventBytes)
+	var id, event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) == 0 {
+				continue
+			}
+			payload := strings.Join(data, "\n")
+			if id != "" {
+				*lastEventID = id
+			}
+			switch event {
+			case pipelineEventDone:
+				var done struct {
+					Result string `json:"result"`
+				}
+				if err := json.Unmarshal([]byte(payload), &done); err != nil {
+					return "", false, fmt.Errorf("failed to decode done event: %w", err)
+				}
+				return done.Result, true, nil
+			case pipelineEventError:
+				return "", false, fmt.Errorf("pipeline events stream failed: %s", payload)
+			default:
+				if _, err := fmt.Fprintln(out, payload); err != nil {
+					return "", false, err
+				}
+			}
+			id, event, data = "", "", nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	// A read error means the connection dropped; the caller resumes from the
+	// last event id, like an interrupted stream.
+	return "", false, nil
+}
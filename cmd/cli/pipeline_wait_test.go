@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipelineEventsPath = "/api/pipeline/executions/pipe-1/wf-1/run-1/events"
+
+func TestNewPipelineWaitCmdFlags(t *testing.T) {
+	cmd := NewPipelineWaitCmd()
+	require.Equal(t, "wait", cmd.Name())
+	require.NotNil(t, cmd.Flag("instance"))
+	require.Equal(
+		t,
+		[]string{"true"},
+		cmd.Flag("api-key").Annotations[cobra.BashCompOneRequiredFlag],
+	)
+	require.Error(t, cmd.Args(cmd, []string{"pipe-1"}))
+}
+
+func TestWaitPipelinePrintsEventsUntilDone(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, testPipelineEventsPath, r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": keep-alive\n\n"+
+			"id: 1\nevent: step.started\ndata: {\"seq\":1,\"type\":\"step.started\"}\n\n"+
+			"id: 2\nevent: step.finished\ndata: {\"seq\":2,\"status\":\"success\"}\n\n"+
+			"id: 2\nevent: done\ndata: {\"result\":\"success\"}\n\n")
+	}))
+
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	var out bytes.Buffer
+	result, err := waitPipeline(context.Background(), "token", "pipe-1", "wf-1", "run-1", &out)
+	require.NoError(t, err)
+	require.Equal(t, "success", result)
+	require.Equal(t,
+		"{\"seq\":1,\"type\":\"step.started\"}\n{\"seq\":2,\"status\":\"success\"}\n",
+		out.String(),
+	)
+}
+
+func TestWaitPipelineResumesFromLastEventID(t *testing.T) {
+	lastEventIDs := []string{}
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		if len(lastEventIDs) == 1 {
+			_, _ = io.WriteString(w, "id: 4\nevent: warning\ndata: {\"seq\":4}\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, "id: 4\nevent: done\ndata: {\"result\":\"failed\"}\n\n")
+	}))
+
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	result, err := waitPipeline(
+		context.Background(),
+		"token",
+		"pipe-1",
+		"wf-1",
+		"run-1",
+		io.Discard,
+	)
+	require.NoError(t, err)
+	require.Equal(t, "failed", result)
+	require.Equal(t, []string{"", "4"}, lastEventIDs)
+}
+
+func TestWaitPipelineGivesUpOnClosedStreams(t *testing.T) {
+	calls := 0
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	_, err := waitPipeline(context.Background(), "token", "pipe-1", "wf-1", "run-1", io.Discard)
+	require.ErrorContains(t, err, "event stream closed before the pipeline finished")
+	require.Equal(t, pipelineWaitReconnects+1, calls)
+}
+
+func TestWaitPipelineReportsErrors(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Authorization"), "bad") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"message":"pipeline execution not found"}`)
+			return
+		}
+		_, _ = io.WriteString(w, "event: error\ndata: {\"message\":\"worker unavailable\"}\n\n")
+	}))
+
+	restoreDefaults := overrideHTTPDefaults(server)
+	defer restoreDefaults()
+
+	_, err := waitPipeline(context.Background(), "bad", "pipe-1", "wf-1", "run-1", io.Discard)
+	require.ErrorContains(t, err, "events request failed (404)")
+
+	_, err = waitPipeline(context.Background(), "token", "pipe-1", "wf-1", "run-1", io.Discard)
+	require.ErrorContains(t, err, "worker unavailable")
+}
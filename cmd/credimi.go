@@ -32,6 +32,7 @@ func Start() {
 	routes.Setup(app)
 
 	app.RootCmd.AddCommand(cli.NewPipelineCmd())
+	app.RootCmd.AddCommand(cli.NewConfigCmd())
 
 	godotenv.Load()
 	if err := app.Start(); err != nil {
@@ -0,0 +1,25 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_1919502272")
+
+  // add field
+  collection.fields.add(new Field({
+    "hidden": false,
+    "id": "json2848376519",
+    "maxSize": 0,
+    "name": "flaky_steps",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_1919502272")
+
+  // remove field
+  collection.fields.removeById("json2848376519")
+
+  return app.save(collection)
+})
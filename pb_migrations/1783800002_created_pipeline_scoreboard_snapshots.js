@@ -0,0 +1,186 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = new Collection({
+    "createRule": null,
+    "deleteRule": null,
+    "fields": [
+      {
+        "autogeneratePattern": "[a-z0-9]{15}",
+        "hidden": false,
+        "id": "text3208210256",
+        "max": 15,
+        "min": 15,
+        "name": "id",
+        "pattern": "^[a-z0-9]+$",
+        "presentable": false,
+        "primaryKey": true,
+        "required": true,
+        "system": true,
+        "type": "text"
+      },
+      {
+        "cascadeDelete": true,
+        "collectionId": "pbc_2153234234",
+        "hidden": false,
+        "id": "relation2113722841",
+        "maxSelect": 1,
+        "minSelect": 0,
+        "name": "pipeline",
+        "presentable": false,
+        "required": true,
+        "system": false,
+        "type": "relation"
+      },
+      {
+        "autogeneratePattern": "",
+        "hidden": false,
+        "id": "text1666891397",
+        "max": 0,
+        "min": 0,
+        "name": "pipeline_name",
+        "pattern": "",
+        "presentable": false,
+        "primaryKey": false,
+        "required": false,
+        "system": false,
+        "type": "text"
+      },
+      {
+        "hidden": false,
+        "id": "date2504119376",
+        "max": "",
+        "min": "",
+        "name": "snapshot_at",
+        "presentable": false,
+        "required": true,
+        "system": false,
+        "type": "date"
+      },
+      {
+        "hidden": false,
+        "id": "number2255831746",
+        "max": null,
+        "min": 0,
+        "name": "total_runs",
+        "onlyInt": true,
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "number"
+      },
+      {
+        "hidden": false,
+        "id": "number2166005852",
+        "max": null,
+        "min": 0,
+        "name": "total_successes",
+        "onlyInt": true,
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "number"
+      },
+      {
+        "hidden": false,
+        "id": "number3608927474",
+        "max": null,
+        "min": 0,
+        "name": "success_rate",
+        "onlyInt": false,
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "number"
+      },
+      {
+        "hidden": false,
+        "id": "number440442913",
+        "max": null,
+        "min": 0,
+        "name": "min_execution_seconds",
+        "onlyInt": false,
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "number"
+      },
+      {
+        "hidden": false,
+        "id": "json1664422070",
+        "maxSize": 0,
+        "name": "wallets",
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "json"
+      },
+      {
+        "hidden": false,
+        "id": "json2155604232",
+        "maxSize": 0,
+        "name": "wallet_versions",
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "json"
+      },
+      {
+        "hidden": false,
+        "id": "json2201086837",
+        "maxSize": 0,
+        "name": "issuers",
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "json"
+      },
+      {
+        "hidden": false,
+        "id": "json865146283",
+        "maxSize": 0,
+        "name": "verifiers",
+        "presentable": false,
+        "required": false,
+        "system": false,
+        "type": "json"
+      },
+      {
+        "hidden": false,
+        "id": "autodate2990389176",
+        "name": "created",
+        "onCreate": true,
+        "onUpdate": false,
+        "presentable": false,
+        "system": false,
+        "type": "autodate"
+      },
+      {
+        "hidden": false,
+        "id": "autodate3332085495",
+        "name": "updated",
+        "onCreate": true,
+        "onUpdate": true,
+        "presentable": false,
+        "system": false,
+        "type": "autodate"
+      }
+    ],
+    "id": "pbc_2718293004",
+    "indexes": [
+      "CREATE INDEX `idx_scoreboard_snapshot_pipeline` ON `pipeline_scoreboard_snapshots` (\n  `pipeline`,\n  `snapshot_at`\n)",
+      "CREATE INDEX `idx_scoreboard_snapshot_at` ON `pipeline_scoreboard_snapshots` (`snapshot_at`)"
+    ],
+    "listRule": "",
+    "name": "pipeline_scoreboard_snapshots",
+    "system": false,
+    "type": "base",
+    "updateRule": null,
+    "viewRule": ""
+  });
+
+  return app.save(collection);
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_2718293004");
+
+  return app.delete(collection);
+})
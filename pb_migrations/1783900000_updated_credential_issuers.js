@@ -0,0 +1,45 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_678514665")
+
+  // add field
+  collection.fields.addAt(13, new Field({
+    "hidden": false,
+    "id": "select2174610386",
+    "maxSelect": 1,
+    "name": "signed_metadata_status",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "select",
+    "values": [
+      "absent",
+      "verified",
+      "invalid"
+    ]
+  }))
+
+  // add field
+  collection.fields.addAt(14, new Field({
+    "hidden": false,
+    "id": "json3361578217",
+    "maxSize": 0,
+    "name": "signed_metadata_verification",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_678514665")
+
+  // remove field
+  collection.fields.removeById("select2174610386")
+
+  // remove field
+  collection.fields.removeById("json3361578217")
+
+  return app.save(collection)
+})
@@ -0,0 +1,25 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_678514665")
+
+  // add field
+  collection.fields.addAt(15, new Field({
+    "hidden": false,
+    "id": "json2164645249",
+    "maxSize": 0,
+    "name": "provenance",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_678514665")
+
+  // remove field
+  collection.fields.removeById("json2164645249")
+
+  return app.save(collection)
+})
@@ -0,0 +1,179 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_131690875")
+
+  // update field
+  collection.fields.addAt(7, new Field({
+    "autogeneratePattern": "",
+    "hidden": false,
+    "id": "text2833051024",
+    "max": 0,
+    "min": 0,
+    "name": "standard_and_version",
+    "pattern": "",
+    "presentable": false,
+    "primaryKey": false,
+    "required": false,
+    "system": false,
+    "type": "text"
+  }))
+
+  // update field
+  collection.fields.addAt(8, new Field({
+    "hidden": false,
+    "id": "select3736761055",
+    "maxSelect": 3,
+    "name": "format",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "select",
+    "values": [
+      "SD-JWT",
+      "mDOC",
+      "W3C-VC"
+    ]
+  }))
+
+  // update field
+  collection.fields.addAt(9, new Field({
+    "hidden": false,
+    "id": "select384687787",
+    "maxSelect": 7,
+    "name": "signing_algorithms",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "select",
+    "values": [
+      "ES256",
+      "EdDSA",
+      "Ed25519Signature2020",
+      "RS256",
+      "ES256K",
+      "RSA",
+      "RsaSignature2018"
+    ]
+  }))
+
+  // update field
+  collection.fields.addAt(10, new Field({
+    "hidden": false,
+    "id": "select3281364177",
+    "maxSelect": 2,
+    "name": "cryptographic_binding_methods",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "select",
+    "values": [
+      "jwk",
+      "cose_key"
+    ]
+  }))
+
+  // add field
+  collection.fields.addAt(14, new Field({
+    "hidden": false,
+    "id": "bool2419865273",
+    "name": "imported",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "bool"
+  }))
+
+  // add field
+  collection.fields.addAt(15, new Field({
+    "hidden": false,
+    "id": "json2164645249",
+    "maxSize": 0,
+    "name": "provenance",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_131690875")
+
+  // update field
+  collection.fields.addAt(7, new Field({
+    "autogeneratePattern": "",
+    "hidden": false,
+    "id": "text2833051024",
+    "max": 0,
+    "min": 0,
+    "name": "standard_and_version",
+    "pattern": "",
+    "presentable": false,
+    "primaryKey": false,
+    "required": true,
+    "system": false,
+    "type": "text"
+  }))
+
+  // update field
+  collection.fields.addAt(8, new Field({
+    "hidden": false,
+    "id": "select3736761055",
+    "maxSelect": 3,
+    "name": "format",
+    "presentable": false,
+    "required": true,
+    "system": false,
+    "type": "select",
+    "values": [
+      "SD-JWT",
+      "mDOC",
+      "W3C-VC"
+    ]
+  }))
+
+  // update field
+  collection.fields.addAt(9, new Field({
+    "hidden": false,
+    "id": "select384687787",
+    "maxSelect": 7,
+    "name": "signing_algorithms",
+    "presentable": false,
+    "required": true,
+    "system": false,
+    "type": "select",
+    "values": [
+      "ES256",
+      "EdDSA",
+      "Ed25519Signature2020",
+      "RS256",
+      "ES256K",
+      "RSA",
+      "RsaSignature2018"
+    ]
+  }))
+
+  // update field
+  collection.fields.addAt(10, new Field({
+    "hidden": false,
+    "id": "select3281364177",
+    "maxSelect": 2,
+    "name": "cryptographic_binding_methods",
+    "presentable": false,
+    "required": true,
+    "system": false,
+    "type": "select",
+    "values": [
+      "jwk",
+      "cose_key"
+    ]
+  }))
+
+  // remove field
+  collection.fields.removeById("bool2419865273")
+
+  // remove field
+  collection.fields.removeById("json2164645249")
+
+  return app.save(collection)
+})
@@ -0,0 +1,77 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_3577178630")
+
+  // update collection data
+  unmarshal({
+    "indexes": [
+      "CREATE UNIQUE INDEX `idx_api_keys_key_id` ON `api_keys` (`key_id`) WHERE `key_id` != ''",
+      "CREATE UNIQUE INDEX `idx_api_keys_legacy_fingerprint` ON `api_keys` (`legacy_fingerprint`) WHERE `legacy_fingerprint` != ''"
+    ]
+  }, collection)
+
+  // add field
+  collection.fields.addAt(8, new Field({
+    "autogeneratePattern": "",
+    "hidden": false,
+    "id": "text1492817374",
+    "max": 0,
+    "min": 0,
+    "name": "key_id",
+    "pattern": "",
+    "presentable": false,
+    "primaryKey": false,
+    "required": false,
+    "system": false,
+    "type": "text"
+  }))
+
+  // add field
+  collection.fields.addAt(9, new Field({
+    "hidden": false,
+    "id": "date2810236497",
+    "max": "",
+    "min": "",
+    "name": "last_used_at",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "date"
+  }))
+
+  // add field
+  collection.fields.addAt(10, new Field({
+    "autogeneratePattern": "",
+    "hidden": false,
+    "id": "text2093586416",
+    "max": 0,
+    "min": 0,
+    "name": "legacy_fingerprint",
+    "pattern": "",
+    "presentable": false,
+    "primaryKey": false,
+    "required": false,
+    "system": false,
+    "type": "text"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_3577178630")
+
+  // update collection data
+  unmarshal({
+    "indexes": []
+  }, collection)
+
+  // remove field
+  collection.fields.removeById("text1492817374")
+
+  // remove field
+  collection.fields.removeById("date2810236497")
+
+  // remove field
+  collection.fields.removeById("text2093586416")
+
+  return app.save(collection)
+})
@@ -0,0 +1,55 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_3577178630")
+
+  // add field
+  collection.fields.addAt(10, new Field({
+    "hidden": false,
+    "id": "json3136052744",
+    "maxSize": 0,
+    "name": "permissions",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  // add field
+  collection.fields.addAt(11, new Field({
+    "hidden": false,
+    "id": "json1416584731",
+    "maxSize": 0,
+    "name": "resources",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  // add field
+  collection.fields.addAt(12, new Field({
+    "hidden": false,
+    "id": "json2573081460",
+    "maxSize": 0,
+    "name": "ip_ranges",
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "json"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_3577178630")
+
+  // remove field
+  collection.fields.removeById("json3136052744")
+
+  // remove field
+  collection.fields.removeById("json1416584731")
+
+  // remove field
+  collection.fields.removeById("json2573081460")
+
+  return app.save(collection)
+})
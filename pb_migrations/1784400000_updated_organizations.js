@@ -0,0 +1,78 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("aako88kt3br4npt")
+
+  // add field
+  collection.fields.addAt(999, new Field({
+    "hidden": false,
+    "id": "number1784400001",
+    "max": null,
+    "min": 0,
+    "name": "quota_concurrent_runs",
+    "onlyInt": true,
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "number"
+  }))
+
+  // add field
+  collection.fields.addAt(999, new Field({
+    "hidden": false,
+    "id": "number1784400002",
+    "max": null,
+    "min": 0,
+    "name": "quota_runs_per_day",
+    "onlyInt": true,
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "number"
+  }))
+
+  // add field
+  collection.fields.addAt(999, new Field({
+    "hidden": false,
+    "id": "number1784400003",
+    "max": null,
+    "min": 0,
+    "name": "quota_storage_mb",
+    "onlyInt": true,
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "number"
+  }))
+
+  // add field
+  collection.fields.addAt(999, new Field({
+    "hidden": false,
+    "id": "number1784400004",
+    "max": null,
+    "min": 0,
+    "name": "quota_requests_per_minute",
+    "onlyInt": true,
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "number"
+  }))
+
+  return app.save(collection)
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("aako88kt3br4npt")
+
+  // remove field
+  collection.fields.removeById("number1784400001")
+
+  // remove field
+  collection.fields.removeById("number1784400002")
+
+  // remove field
+  collection.fields.removeById("number1784400003")
+
+  // remove field
+  collection.fields.removeById("number1784400004")
+
+  return app.save(collection)
+})
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// @ts-check
+
+/// <reference path="../pb_data/types.d.ts" />
+
+/** @type {Array<{name:string, level:number, id:string}>} */
+const roles = [
+    { name: "pipeline_author", level: 10, id: "pipelineauthor0" },
+    { name: "runner_operator", level: 11, id: "runneroperator0" },
+    { name: "viewer", level: 20, id: "viewer000000000" },
+];
+
+//
+
+migrate(
+    (app) => {
+        const rolesCollection = app.findCollectionByNameOrId("orgRoles");
+
+        roles
+            .map((role) => new Record(rolesCollection, role))
+            .forEach((roleRecord) => app.save(roleRecord));
+    },
+    (app) => {
+        roles.forEach((role) => {
+            app.delete(app.findRecordById("orgRoles", role.id));
+        });
+    }
+);
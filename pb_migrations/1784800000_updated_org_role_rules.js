@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// @ts-check
+
+/// <reference path="../pb_data/types.d.ts" />
+
+// Owners keep writing these collections through the record API, and the
+// role that manages each collection is allowed to create, update and delete
+// its records too.
+
+/** @type {Array<{collection:string, roles:string[]}>} */
+const writers = [
+    {
+        collection: "pipelines",
+        roles: ["owner", "pipeline_author"],
+    },
+    {
+        collection: "wallets",
+        roles: ["owner", "pipeline_author"],
+    },
+    {
+        collection: "mobile_runners",
+        roles: ["owner", "runner_operator"],
+    },
+    {
+        collection: "schedules",
+        roles: ["owner", "pipeline_author"],
+    },
+];
+
+const ownerOnlyRule =
+    "@collection.orgAuthorizations.user.id ?= @request.auth.id &&\n" +
+    "@collection.orgAuthorizations.organization.id ?= owner.id &&\n" +
+    '@collection.orgAuthorizations.role.name ?= "owner"';
+
+/**
+ * @param {string[]} roles
+ * @returns {string}
+ */
+function roleRule(roles) {
+    const names = roles
+        .map((role) => `@collection.orgAuthorizations.role.name ?= "${role}"`)
+        .join(" ||\n  ");
+    return (
+        "@collection.orgAuthorizations.user.id ?= @request.auth.id &&\n" +
+        "@collection.orgAuthorizations.organization.id ?= owner.id && (\n  " +
+        names +
+        "\n)"
+    );
+}
+
+//
+
+migrate(
+    (app) => {
+        writers.forEach(({ collection: name, roles }) => {
+            const collection = app.findCollectionByNameOrId(name);
+            const rule = roleRule(roles);
+            collection.createRule = rule;
+            collection.updateRule = rule;
+            collection.deleteRule = rule;
+            app.save(collection);
+        });
+    },
+    (app) => {
+        writers.forEach(({ collection: name }) => {
+            const collection = app.findCollectionByNameOrId(name);
+            collection.createRule = ownerOnlyRule;
+            collection.updateRule = ownerOnlyRule;
+            collection.deleteRule = ownerOnlyRule;
+            app.save(collection);
+        });
+    }
+);
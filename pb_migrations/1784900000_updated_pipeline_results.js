@@ -0,0 +1,53 @@
+/// <reference path="../pb_data/types.d.ts" />
+migrate((app) => {
+  const collection = app.findCollectionByNameOrId("pbc_2980015441")
+
+  // add field
+  collection.fields.addAt(999, new Field({
+    "hidden": false,
+    "id": "number1784900001",
+    "max": null,
+    "min": 0,
+    "name": "files_bytes",
+    "onlyInt": true,
+    "presentable": false,
+    "required": false,
+    "system": false,
+    "type": "number"
+  }))
+
+  app.save(collection)
+
+  // backfill the size of the files already stored
+  const fileFields = ["video_results", "screenshots", "logcats", "ios_logstreams", "report"]
+  const fsys = app.newFilesystem()
+  try {
+    for (const record of app.findAllRecords("pipeline_results")) {
+      let size = 0
+      for (const field of fileFields) {
+        for (const name of record.getStringSlice(field)) {
+          try {
+            size += fsys.attributes(record.baseFilesPath() + "/" + name).size
+          } catch (e) {
+            // missing files are not counted
+          }
+        }
+      }
+      if (size > 0) {
+        app.db()
+          .newQuery("UPDATE pipeline_results SET files_bytes = {:size} WHERE id = {:id}")
+          .bind({ size: size, id: record.id })
+          .execute()
+      }
+    }
+  } finally {
+    fsys.close()
+  }
+}, (app) => {
+  const collection = app.findCollectionByNameOrId("pbc_2980015441")
+
+  // remove field
+  collection.fields.removeById("number1784900001")
+
+  return app.save(collection)
+})
@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package apiclient is a typed client for the exported Credimi API. The
+// operations in client_gen.go are generated by pkg/generate_client from the
+// same route definitions as docs/public/API/openapi.yml; run
+// `go run ./generate_client` from pkg/ after changing an exported route.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+)
+
+// APIError is the error body the API answers with.
+type APIError = apierror.APIError
+
+// ErrMissingCredentials is returned, before any request is sent, by
+// operations that need a token or an API key when the client has neither.
+var ErrMissingCredentials = errors.New("apiclient: a token or an API key is required")
+
+// Error is returned for responses outside the 2xx range.
+type Error struct {
+	StatusCode int
+	// API is the decoded body, nil when the server did not answer an APIError.
+	API  *APIError
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	if e.API != nil && e.API.Message != "" {
+		return fmt.Sprintf("status %d: %s", e.StatusCode, e.API.Error())
+	}
+	return fmt.Sprintf("status %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+func newError(statusCode int, body []byte) *Error {
+	err := &Error{StatusCode: statusCode, Body: body}
+	var apiErr APIError
+	if json.Unmarshal(body, &apiErr) == nil && (apiErr.Message != "" || apiErr.Reason != "") {
+		err.API = &apiErr
+	}
+	return err
+}
+
+// Client calls the API of one Credimi instance. Token is sent as a bearer
+// token and APIKey as the Credimi-Api-Key header whenever they are set.
+type Client struct {
+	BaseURL string
+	Token   string
+	APIKey  string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type Option func(*Client)
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{BaseURL: baseURL}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.Token = token
+	}
+}
+
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.APIKey = apiKey
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// RequestOption customizes a single operation call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header  http.Header
+	rawBody *[]byte
+}
+
+// WithHeader sets an extra request header, e.g. Last-Event-ID on streams or
+// Idempotency-Key on run-starting operations.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithRawResponse stores the response body in dst, for error responses too,
+// so callers can show fields the response schema does not declare.
+func WithRawResponse(dst *[]byte) RequestOption {
+	return func(o *requestOptions) {
+		o.rawBody = dst
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) newRequest(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	auth bool,
+	opts []RequestOption,
+) (*http.Request, *requestOptions, error) {
+	if auth && c.Token == "" && c.APIKey == "" {
+		return nil, nil, ErrMissingCredentials
+	}
+
+	endpoint := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode %s %s request: %w", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("Credimi-Api-Key", c.APIKey)
+	}
+
+	options := &requestOptions{header: http.Header{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	for key, values := range options.header {
+		req.Header[key] = values
+	}
+	return req, options, nil
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	auth bool,
+	out any,
+	opts []RequestOption,
+) error {
+	req, options, err := c.newRequest(ctx, method, path, query, body, auth, opts)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s %s response: %w", method, path, err)
+	}
+	if options.rawBody != nil {
+		*options.rawBody = data
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp.StatusCode, data)
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) stream(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	auth bool,
+	opts []RequestOption,
+) (*http.Response, error) {
+	req, _, err := c.newRequest(ctx, method, path, query, body, auth, opts)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, newError(resp.StatusCode, data)
+	}
+	return resp, nil
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
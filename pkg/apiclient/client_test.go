@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSendsCredentialsAndDecodesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/api/pipeline/queue/ticket%2F1", r.URL.EscapedPath())
+		require.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		require.Equal(t, "key-1", r.Header.Get("Credimi-Api-Key"))
+		require.Equal(t, "application/json", r.Header.Get("Accept"))
+		require.Equal(t, "yes", r.Header.Get("X-Test"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"ticket_id":"ticket/1","status":"queued","extra":1}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/", WithToken("token-1"), WithAPIKey("key-1"))
+	var raw []byte
+	resp, err := c.PipelineQueueStatus(
+		context.Background(),
+		PipelineQueueStatusParams{Ticket: "ticket/1"},
+		WithHeader("X-Test", "yes"),
+		WithRawResponse(&raw),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "ticket/1", resp.TicketID)
+	require.EqualValues(t, "queued", resp.Status)
+	require.JSONEq(t, `{"ticket_id":"ticket/1","status":"queued","extra":1}`, string(raw))
+}
+
+func TestClientSendsJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/pipeline/queue", r.URL.Path)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "org/pipeline", body["pipeline_identifier"])
+		_, _ = io.WriteString(w, `{"ticket_id":"t-1","status":"queued"}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithAPIKey("key-1"))
+	resp, err := c.PipelineQueue(
+		context.Background(),
+		PipelineQueueInput{PipelineIdentifier: "org/pipeline"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "t-1", resp.TicketID)
+}
+
+func TestClientReturnsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(
+			w,
+			`{"status":403,"error":"auth","reason":"forbidden","message":"nope"}`,
+		)
+	}))
+	defer server.Close()
+
+	var raw []byte
+	_, err := New(server.URL, WithToken("token")).
+		OrganizationGet(context.Background(), WithRawResponse(&raw))
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	require.NotNil(t, apiErr.API)
+	require.Equal(t, "nope", apiErr.API.Message)
+	require.Contains(t, apiErr.Error(), "status 403")
+	require.Contains(t, string(raw), "forbidden")
+}
+
+func TestClientReturnsPlainErrorBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, WithToken("token")).OrganizationGet(context.Background())
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Nil(t, apiErr.API)
+	require.Equal(t, "status 502: bad gateway", apiErr.Error())
+}
+
+func TestClientRequiresCredentials(t *testing.T) {
+	c := New("http://127.0.0.1:0", WithHTTPClient(&http.Client{
+		Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			t.Fatal("request must not be sent without credentials")
+			return nil, nil
+		}),
+	}))
+
+	_, err := c.OrganizationGet(context.Background())
+	require.True(t, errors.Is(err, ErrMissingCredentials))
+}
+
+func TestClientNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "/api/organizations/my/webhooks/hook-1", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := New(server.URL, WithToken("token")).
+		WebhooksDelete(context.Background(), WebhooksDeleteParams{Id: "hook-1"})
+	require.NoError(t, err)
+}
+
+func TestClientStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/pipeline/executions/p-1/wf-1/run-1/events", r.URL.Path)
+		require.Equal(t, "4", r.URL.Query().Get("after"))
+		require.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		require.Equal(t, "4", r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "id: 5\nevent: done\ndata: {}\n\n")
+	}))
+	defer server.Close()
+
+	resp, err := New(server.URL, WithToken("token")).PipelineExecutionEvents(
+		context.Background(),
+		PipelineExecutionEventsParams{
+			Id:         "p-1",
+			WorkflowId: "wf-1",
+			RunId:      "run-1",
+			After:      "4",
+		},
+		WithHeader("Last-Event-ID", "4"),
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "id: 5\nevent: done\ndata: {}\n\n", string(body))
+}
+
+func TestClientStreamErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"status":404,"message":"pipeline not found"}`)
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, WithToken("token")).PipelineExecutionEvents(
+		context.Background(),
+		PipelineExecutionEventsParams{Id: "p-1", WorkflowId: "wf-1", RunId: "run-1"},
+	)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Equal(t, "pipeline not found", apiErr.API.Message)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
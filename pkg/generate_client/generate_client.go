@@ -5,6 +5,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
 	api "github.com/forkbombeu/credimi/pkg/internal/apis"
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/pocketbase/pocketbase/apis"
@@ -37,9 +39,13 @@ type RouteInfo struct {
 	AuthHeaders           []authHeaderParam
 	PathParams            []string
 	HasInputBody          bool
+	NoContent             bool
+	Idempotent            bool
+	ResponseContentType   string
 	Summary               string
 	Description           string
 	Tags                  []string
+	Permission            string
 }
 
 type authHeaderParam struct {
@@ -48,6 +54,10 @@ type authHeaderParam struct {
 	Description string
 }
 
+// requiredPermissionsExtension lists the permissions an API key restricted
+// to a set of permissions needs to call the operation.
+const requiredPermissionsExtension = "x-required-permissions"
+
 // =================================================================
 // =============== MAIN LOGIC
 // =================================================================
@@ -62,13 +72,34 @@ func main() {
 		)
 	}
 
+	log.Println("Processing routes...")
+	routes, err := collectRoutes(routeGroups)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if err := validateRouteSchemas(routes); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	log.Println("Generating OpenAPI YAML documentation...")
+	generateOpenAPIYAML(routes)
+
+	log.Println("Generating API clients...")
+	writeGeneratedFile(goClientOutputPath, renderGoClient, routes)
+	writeGeneratedFile(typeScriptClientOutputPath, renderTypeScriptClient, routes)
+
+	log.Println("✅ Generation complete.")
+}
+
+// collectRoutes flattens the exported route groups into the routes that the
+// OpenAPI document and the API clients describe.
+func collectRoutes(routeGroups []routing.RouteGroup) ([]RouteInfo, error) {
 	totalRoutes := 0
 	for _, group := range routeGroups {
 		totalRoutes += len(group.Routes)
 	}
 	routes := make([]RouteInfo, 0, totalRoutes)
 
-	log.Println("Processing routes...")
 	for _, group := range routeGroups {
 		for _, route := range group.Routes {
 			// Use url.JoinPath for proper URL path joining instead of file path.Join
@@ -80,6 +111,8 @@ func main() {
 				Summary:               route.Summary,
 				Description:           route.Description,
 				QuerySearchAttributes: route.QuerySearchAttributes,
+				ResponseContentType:   route.ResponseContentType,
+				Permission:            string(route.Permission),
 				AuthHeaders: authHeadersForRoute(
 					group.AuthenticationRequired,
 					group.Middlewares,
@@ -92,14 +125,21 @@ func main() {
 			if route.RequestSchema != nil {
 				r.InputSchema = route.RequestSchema
 			}
+			r.Idempotent = hasMiddleware(
+				middlewares.IdempotentMiddlewareID,
+				group.Middlewares,
+				route.Middlewares,
+			)
 
-			if route.ResponseSchema != nil {
+			if _, ok := route.ResponseSchema.(routing.NoContent); ok {
+				r.NoContent = true
+			} else if route.ResponseSchema != nil {
 				r.OutputSchema = route.ResponseSchema
 			}
 
 			if r.OperationID == "" {
-				log.Fatalf(
-					"FATAL: missing operation ID for exported route %s %s",
+				return nil, fmt.Errorf(
+					"missing operation ID for exported route %s %s",
 					r.Method,
 					r.Path,
 				)
@@ -113,11 +153,49 @@ func main() {
 			routes = append(routes, r)
 		}
 	}
+	return routes, nil
+}
 
-	log.Println("Generating OpenAPI YAML documentation...")
-	generateOpenAPIYAML(routes)
+// validateRouteSchemas reports exported routes that the clients could not
+// type: every route declares a response (routing.NoContent when it has no
+// body) and every PUT or PATCH declares its request body.
+func validateRouteSchemas(routes []RouteInfo) error {
+	var errs []error
+	for _, route := range routes {
+		if route.OutputSchema == nil && !route.NoContent {
+			errs = append(errs, fmt.Errorf(
+				"%s %s (%s) has no response schema",
+				route.Method,
+				route.Path,
+				route.OperationID,
+			))
+		}
+		if route.InputSchema == nil &&
+			(route.Method == http.MethodPut || route.Method == http.MethodPatch) {
+			errs = append(errs, fmt.Errorf(
+				"%s %s (%s) has no request schema",
+				route.Method,
+				route.Path,
+				route.OperationID,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	log.Println("✅ Generation complete.")
+func writeGeneratedFile(
+	outputPath string,
+	render func([]RouteInfo) ([]byte, error),
+	routes []RouteInfo,
+) {
+	contents, err := render(routes)
+	if err != nil {
+		log.Fatalf("FATAL: Failed to render '%s': %v", outputPath, err)
+	}
+	if err := os.WriteFile(outputPath, contents, 0644); err != nil {
+		log.Fatalf("FATAL: Failed to write '%s': %v", outputPath, err)
+	}
+	log.Printf("✅ Client successfully generated at: %s", outputPath)
 }
 
 // =================================================================
@@ -199,6 +277,12 @@ func setOperationMetadata(operation openapi.OperationInfo, route RouteInfo) {
 	if len(route.Tags) > 0 {
 		operation.SetTags(route.Tags...)
 	}
+	if exposer, ok := operation.(openapi3.OperationExposer); ok && route.Permission != "" {
+		exposer.Operation().WithMapOfAnythingItem(
+			requiredPermissionsExtension,
+			[]string{route.Permission},
+		)
+	}
 }
 
 func addOperationRequest(operation openapi.OperationContext, route RouteInfo) error {
@@ -227,14 +311,28 @@ func addOperationRequest(operation openapi.OperationContext, route RouteInfo) er
 }
 
 func addOperationResponses(operation openapi.OperationContext, route RouteInfo) {
-	if route.OutputSchema != nil {
+	switch {
+	case route.NoContent:
+		operation.AddRespStructure(
+			nil,
+			openapi.WithHTTPStatus(http.StatusNoContent),
+			withResponseDescription("Successful response without a body"),
+		)
+	case route.OutputSchema != nil && route.ResponseContentType == routing.ContentTypeEventStream:
+		operation.AddRespStructure(
+			route.OutputSchema,
+			openapi.WithHTTPStatus(http.StatusOK),
+			openapi.WithContentType(routing.ContentTypeEventStream),
+			withResponseDescription("Stream of server-sent events; each data line holds one event"),
+		)
+	case route.OutputSchema != nil:
 		operation.AddRespStructure(
 			route.OutputSchema,
 			openapi.WithHTTPStatus(http.StatusOK),
 			openapi.WithContentType("application/json"),
 			withResponseDescription("Successful response"),
 		)
-	} else {
+	default:
 		operation.AddRespStructure(
 			nil,
 			openapi.WithHTTPStatus(http.StatusOK),
@@ -281,6 +379,20 @@ func buildRequestStructure(route RouteInfo) any {
 		})
 	}
 
+	if route.Idempotent {
+		fields = append(fields, reflect.StructField{
+			Name: uniqueFieldName("HeaderIdempotencyKey", used),
+			Type: reflect.TypeOf(""),
+			Tag: buildTag(
+				"header",
+				idempotency.Header,
+				false,
+				"Retries with the same key return the original response instead of "+
+					"starting another run.",
+			),
+		})
+	}
+
 	if !route.HasInputBody {
 		for _, attr := range route.QuerySearchAttributes {
 			fieldName := uniqueFieldName(paramFieldName("Query", attr.Name), used)
@@ -373,6 +485,17 @@ func authHeadersForRoute(
 	return headers
 }
 
+func hasMiddleware(id string, handlers ...[]*hook.Handler[*core.RequestEvent]) bool {
+	for _, list := range handlers {
+		for _, handler := range list {
+			if handler != nil && handler.Id == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func addAuthOrAPIKeyHeaders(headers []authHeaderParam) []authHeaderParam {
 	headers = addAuthHeader(headers, authHeaderParam{
 		Name:        "Authorization",
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 
+	api "github.com/forkbombeu/credimi/pkg/internal/apis"
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/pocketbase/pocketbase/apis"
@@ -112,6 +114,31 @@ func TestBuildOpenAPISpec_RequestBodyRequired(t *testing.T) {
 	require.NotNil(t, op.Responses.Default)
 }
 
+func TestBuildOpenAPISpec_RequiredPermissions(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/things/run",
+			OperationID: "things.run",
+			Permission:  "things:run",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/things",
+			OperationID: "things.list",
+		},
+	}
+
+	spec, err := buildOpenAPISpec(routes)
+	require.NoError(t, err)
+
+	op := requireOperation(t, spec, "/api/things/run", http.MethodPost)
+	assert.Equal(t, []string{"things:run"}, op.MapOfAnything[requiredPermissionsExtension])
+
+	op = requireOperation(t, spec, "/api/things", http.MethodGet)
+	assert.NotContains(t, op.MapOfAnything, requiredPermissionsExtension)
+}
+
 func requireOperation(t *testing.T, spec *openapi3.Spec, path, method string) openapi3.Operation {
 	t.Helper()
 
@@ -212,3 +239,127 @@ func TestPathHelpers(t *testing.T) {
 	require.Equal(t, "/", joinOpenAPIPath("/"))
 	require.Equal(t, "/api/v1/items", joinOpenAPIPath("/api/", "/v1/", "/items/"))
 }
+
+func TestBuildOpenAPISpec_NoContentAndEventStreamResponses(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/things/{id}",
+			OperationID: "things.delete",
+			PathParams:  []string{"id"},
+			NoContent:   true,
+		},
+		{
+			Method:              http.MethodGet,
+			Path:                "/api/things/{id}/events",
+			OperationID:         "things.events",
+			PathParams:          []string{"id"},
+			OutputSchema:        TestBodyResponse{},
+			ResponseContentType: routing.ContentTypeEventStream,
+		},
+	}
+
+	spec, err := buildOpenAPISpec(routes)
+	require.NoError(t, err)
+
+	op := requireOperation(t, spec, "/api/things/{id}", http.MethodDelete)
+	require.Contains(t, op.Responses.MapOfResponseOrRefValues, "204")
+	require.NotContains(t, op.Responses.MapOfResponseOrRefValues, "200")
+
+	op = requireOperation(t, spec, "/api/things/{id}/events", http.MethodGet)
+	resp := op.Responses.MapOfResponseOrRefValues["200"].Response
+	require.NotNil(t, resp)
+	require.Contains(t, resp.Content, routing.ContentTypeEventStream)
+	require.NotContains(t, resp.Content, "application/json")
+}
+
+func TestBuildOpenAPISpec_IdempotencyKeyHeader(t *testing.T) {
+	routes := []RouteInfo{{
+		Method:       http.MethodPost,
+		Path:         "/api/things/run",
+		OperationID:  "things.run",
+		InputSchema:  TestBodyRequest{},
+		HasInputBody: true,
+		OutputSchema: TestBodyResponse{},
+		Idempotent:   true,
+	}}
+
+	spec, err := buildOpenAPISpec(routes)
+	require.NoError(t, err)
+
+	op := requireOperation(t, spec, "/api/things/run", http.MethodPost)
+	var found bool
+	for _, param := range op.Parameters {
+		if param.Parameter != nil && param.Parameter.Name == idempotency.Header {
+			found = true
+			require.Equal(t, openapi3.ParameterInHeader, param.Parameter.In)
+			require.False(t, param.Parameter.Required != nil && *param.Parameter.Required)
+		}
+	}
+	require.True(t, found, "missing Idempotency-Key header")
+}
+
+func TestCollectRoutes(t *testing.T) {
+	groups := []routing.RouteGroup{{
+		BaseURL:                "/api/things",
+		AuthenticationRequired: true,
+		Routes: []routing.RouteDefinition{
+			{
+				Method:         http.MethodPut,
+				Path:           "/{id}",
+				OperationID:    "things.update",
+				RequestSchema:  TestBodyRequest{},
+				ResponseSchema: TestBodyResponse{},
+			},
+			{
+				Method:         http.MethodDelete,
+				Path:           "/{id}",
+				OperationID:    "things.delete",
+				ResponseSchema: routing.NoContent{},
+				Middlewares: []*hook.Handler[*core.RequestEvent]{
+					middlewares.Idempotent(),
+				},
+			},
+		},
+	}}
+
+	routes, err := collectRoutes(groups)
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	require.False(t, routes[0].Idempotent)
+	require.True(t, routes[1].Idempotent)
+	require.Equal(t, "/api/things/{id}", routes[0].Path)
+	require.Equal(t, []string{"id"}, routes[0].PathParams)
+	require.True(t, routes[0].HasInputBody)
+	require.True(t, routes[1].NoContent)
+	require.Nil(t, routes[1].OutputSchema)
+
+	groups[0].Routes[1].OperationID = ""
+	_, err = collectRoutes(groups)
+	require.ErrorContains(t, err, "missing operation ID for exported route DELETE /api/things/{id}")
+}
+
+func TestValidateRouteSchemas(t *testing.T) {
+	require.NoError(t, validateRouteSchemas([]RouteInfo{
+		{Method: http.MethodGet, Path: "/a", OperationID: "a", OutputSchema: TestBodyResponse{}},
+		{Method: http.MethodDelete, Path: "/a", OperationID: "a.delete", NoContent: true},
+	}))
+
+	err := validateRouteSchemas([]RouteInfo{
+		{Method: http.MethodGet, Path: "/a", OperationID: "a.get"},
+		{
+			Method:       http.MethodPatch,
+			Path:         "/a",
+			OperationID:  "a.patch",
+			OutputSchema: TestBodyResponse{},
+		},
+	})
+	require.ErrorContains(t, err, "GET /a (a.get) has no response schema")
+	require.ErrorContains(t, err, "PATCH /a (a.patch) has no request schema")
+}
+
+func TestExportedRoutesDeclareSchemas(t *testing.T) {
+	routes, err := collectRoutes(api.RouteGroups)
+	require.NoError(t, err)
+	require.NoError(t, validateRouteSchemas(routes))
+}
@@ -0,0 +1,415 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+)
+
+const (
+	// goClientOutputPath is relative to pkg/, where the generator runs.
+	goClientOutputPath = "apiclient/client_gen.go"
+	goModulePath       = "github.com/forkbombeu/credimi"
+
+	generatedFileHeader = "Code generated by pkg/generate_client; DO NOT EDIT."
+)
+
+// goTypeNamer renders schema types as Go type expressions. Named types of this
+// module get an alias in the client package, so callers outside pkg/ (which
+// cannot import pkg/internal) can still name them.
+type goTypeNamer struct {
+	importAliases map[string]string
+	importPaths   map[string]string
+	aliases       map[reflect.Type]string
+	aliasTypes    map[string]reflect.Type
+}
+
+func newGoTypeNamer() *goTypeNamer {
+	return &goTypeNamer{
+		importAliases: map[string]string{},
+		importPaths:   map[string]string{},
+		aliases:       map[reflect.Type]string{},
+		aliasTypes:    map[string]reflect.Type{},
+	}
+}
+
+func (n *goTypeNamer) expr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if strings.Contains(t.Name(), "[") {
+			return "", fmt.Errorf("generic schema type %s is not supported", t)
+		}
+		if !token.IsExported(t.Name()) {
+			return "", fmt.Errorf("schema type %s is unexported", t)
+		}
+		if strings.HasPrefix(t.PkgPath(), goModulePath+"/") {
+			return n.alias(t), nil
+		}
+		return n.importAlias(t.PkgPath()) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := n.expr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := n.expr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := n.expr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := n.expr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := n.expr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}", nil
+		}
+	}
+	return "", fmt.Errorf("anonymous schema type %s is not supported", t)
+}
+
+func (n *goTypeNamer) alias(t reflect.Type) string {
+	if name, ok := n.aliases[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := n.aliasTypes[name]; taken {
+		name = exportName(n.importAlias(t.PkgPath())) + name
+	}
+	n.importAlias(t.PkgPath())
+	n.aliases[t] = name
+	n.aliasTypes[name] = t
+	return name
+}
+
+func (n *goTypeNamer) importAlias(path string) string {
+	if alias, ok := n.importAliases[path]; ok {
+		return alias
+	}
+	elems := strings.Split(path, "/")
+	alias := ""
+	for i := len(elems) - 1; i >= 0; i-- {
+		alias = sanitizeImportAlias(elems[i]) + alias
+		if _, taken := n.importPaths[alias]; !taken && alias != "" {
+			break
+		}
+	}
+	n.importAliases[path] = alias
+	n.importPaths[alias] = path
+	return alias
+}
+
+func sanitizeImportAlias(elem string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(elem) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+type goClientParam struct {
+	Field       string
+	Name        string
+	In          string
+	Description string
+}
+
+type goClientOperation struct {
+	Route    RouteInfo
+	Name     string
+	Params   []goClientParam
+	BodyType string
+	RespType string
+}
+
+func newGoClientOperation(route RouteInfo, namer *goTypeNamer) (goClientOperation, error) {
+	op := goClientOperation{Route: route, Name: exportName(route.OperationID)}
+	used := map[string]struct{}{}
+	for _, param := range route.PathParams {
+		op.Params = append(op.Params, goClientParam{
+			Field: uniqueFieldName(exportName(param), used),
+			Name:  param,
+			In:    "path",
+		})
+	}
+	if !route.HasInputBody {
+		for _, attr := range route.QuerySearchAttributes {
+			op.Params = append(op.Params, goClientParam{
+				Field:       uniqueFieldName(exportName(attr.Name), used),
+				Name:        attr.Name,
+				In:          "query",
+				Description: attr.Description,
+			})
+		}
+	}
+
+	var err error
+	if route.HasInputBody {
+		if op.BodyType, err = namer.expr(reflect.TypeOf(route.InputSchema)); err != nil {
+			return op, fmt.Errorf("%s request: %w", route.OperationID, err)
+		}
+	}
+	if route.OutputSchema != nil && !op.streams() {
+		if op.RespType, err = namer.expr(reflect.TypeOf(route.OutputSchema)); err != nil {
+			return op, fmt.Errorf("%s response: %w", route.OperationID, err)
+		}
+	}
+	return op, nil
+}
+
+func (op goClientOperation) streams() bool {
+	return op.Route.ResponseContentType == routing.ContentTypeEventStream
+}
+
+func (op goClientOperation) paramsType() string {
+	if len(op.Params) == 0 {
+		return ""
+	}
+	return op.Name + "Params"
+}
+
+// pathExpr builds the Go expression of the request path, escaping each path
+// parameter.
+func (op goClientOperation) pathExpr() string {
+	fields := map[string]string{}
+	for _, param := range op.Params {
+		if param.In == "path" {
+			fields[param.Name] = param.Field
+		}
+	}
+	var parts []string
+	literal := ""
+	for _, segment := range strings.Split(strings.Trim(op.Route.Path, "/"), "/") {
+		if !isPathParam(segment) {
+			literal += "/" + segment
+			continue
+		}
+		parts = append(parts, strconv.Quote(literal+"/"))
+		literal = ""
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		parts = append(parts, "url.PathEscape(params."+fields[name]+")")
+	}
+	if literal != "" {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func (op goClientOperation) hasQuery() bool {
+	for _, param := range op.Params {
+		if param.In == "query" {
+			return true
+		}
+	}
+	return false
+}
+
+func (op goClientOperation) authText() string {
+	if len(op.Route.AuthHeaders) == 0 {
+		return "No authentication middleware; configured credentials are still sent."
+	}
+	text := "Requires a token or an API key."
+	for _, header := range op.Route.AuthHeaders {
+		if header.Required && header.Name == "Credimi-Api-Key" {
+			text = "Requires the internal admin API key."
+		}
+	}
+	if op.Route.Permission != "" {
+		text += " Restricted API keys need the " + op.Route.Permission + " permission."
+	}
+	return text
+}
+
+func renderGoClient(routes []RouteInfo) ([]byte, error) {
+	namer := newGoTypeNamer()
+	ops := make([]goClientOperation, 0, len(routes))
+	for _, route := range routes {
+		op, err := newGoClientOperation(route, namer)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	var body bytes.Buffer
+	usesURL := false
+	for _, op := range ops {
+		usesURL = usesURL || len(op.Params) > 0
+		writeGoOperation(&body, op)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// SPDX-FileCopyrightText: 2026 Forkbomb BV\n//\n")
+	out.WriteString("// SPDX-License-Identifier: AGPL-3.0-or-later\n\n")
+	out.WriteString("// " + generatedFileHeader + "\n\n")
+	out.WriteString("package apiclient\n\nimport (\n\t\"context\"\n\t\"net/http\"\n")
+	if usesURL {
+		out.WriteString("\t\"net/url\"\n")
+	}
+	out.WriteString("\n")
+	paths := make([]string, 0, len(namer.importAliases))
+	for path := range namer.importAliases {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%s %q\n", namer.importAliases[path], path)
+	}
+	out.WriteString(")\n\n")
+
+	if len(namer.aliases) > 0 {
+		names := make([]string, 0, len(namer.aliasTypes))
+		for name := range namer.aliasTypes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out.WriteString("type (\n")
+		for _, name := range names {
+			t := namer.aliasTypes[name]
+			fmt.Fprintf(&out, "\t%s = %s.%s\n", name, namer.importAliases[t.PkgPath()], t.Name())
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go client: %w", err)
+	}
+	return formatted, nil
+}
+
+func writeGoOperation(w *bytes.Buffer, op goClientOperation) {
+	route := op.Route
+	if paramsType := op.paramsType(); paramsType != "" {
+		fmt.Fprintf(w, "\n// %s holds the path and query parameters of %s.\n", paramsType, op.Name)
+		fmt.Fprintf(w, "type %s struct {\n", paramsType)
+		for _, param := range op.Params {
+			if param.Description != "" {
+				fmt.Fprintf(w, "\t// %s\n", param.Description)
+			}
+			fmt.Fprintf(w, "\t%s string\n", param.Field)
+		}
+		w.WriteString("}\n")
+	}
+
+	fmt.Fprintf(w, "\n// %s calls %s %s.\n", op.Name, route.Method, route.Path)
+	if description := firstNonEmpty(route.Description, route.Summary); description != "" {
+		fmt.Fprintf(w, "//\n// %s\n", sanitizeComment(description))
+	}
+	fmt.Fprintf(w, "// %s\n", op.authText())
+	if op.streams() {
+		w.WriteString(
+			"// The caller reads the event stream from the response body and closes it.\n",
+		)
+	}
+
+	args := []string{"ctx context.Context"}
+	if paramsType := op.paramsType(); paramsType != "" {
+		args = append(args, "params "+paramsType)
+	}
+	if op.BodyType != "" {
+		args = append(args, "body "+op.BodyType)
+	}
+	args = append(args, "opts ...RequestOption")
+
+	result := "error"
+	switch {
+	case op.streams():
+		result = "(*http.Response, error)"
+	case op.RespType != "":
+		result = "(" + op.RespType + ", error)"
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(%s) %s {\n", op.Name, strings.Join(args, ", "), result)
+
+	query := "nil"
+	if op.hasQuery() {
+		query = "query"
+		w.WriteString("\tquery := url.Values{}\n")
+		for _, param := range op.Params {
+			if param.In == "query" {
+				fmt.Fprintf(w, "\tsetQuery(query, %q, params.%s)\n", param.Name, param.Field)
+			}
+		}
+	}
+	bodyArg := "nil"
+	if op.BodyType != "" {
+		bodyArg = "body"
+	}
+	method := "http.Method" + methodConstName(route.Method)
+	auth := strconv.FormatBool(len(route.AuthHeaders) > 0)
+	call := fmt.Sprintf("%s, %s, %s, %s", method, op.pathExpr(), query, bodyArg)
+
+	switch {
+	case op.streams():
+		fmt.Fprintf(w, "\treturn c.stream(ctx, %s, %s, opts)\n", call, auth)
+	case op.RespType != "":
+		fmt.Fprintf(w, "\tvar out %s\n", op.RespType)
+		fmt.Fprintf(w, "\terr := c.do(ctx, %s, %s, &out, opts)\n", call, auth)
+		w.WriteString("\treturn out, err\n")
+	default:
+		fmt.Fprintf(w, "\treturn c.do(ctx, %s, %s, nil, opts)\n", call, auth)
+	}
+	w.WriteString("}\n")
+}
+
+func methodConstName(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "Get"
+	case http.MethodPost:
+		return "Post"
+	case http.MethodPut:
+		return "Put"
+	case http.MethodPatch:
+		return "Patch"
+	case http.MethodDelete:
+		return "Delete"
+	case http.MethodHead:
+		return "Head"
+	default:
+		return "Options"
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func sanitizeComment(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if !strings.HasSuffix(text, ".") {
+		text += "."
+	}
+	return text
+}
@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	api "github.com/forkbombeu/credimi/pkg/internal/apis"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/stretchr/testify/require"
+)
+
+var testAuthHeaders = []authHeaderParam{{Name: "Authorization"}, {Name: "Credimi-Api-Key"}}
+
+func TestGoTypeNamer(t *testing.T) {
+	namer := newGoTypeNamer()
+
+	expr, err := namer.expr(reflect.TypeOf([]*apierror.APIError{}))
+	require.NoError(t, err)
+	require.Equal(t, "[]*APIError", expr)
+
+	expr, err = namer.expr(reflect.TypeOf(map[string]any{}))
+	require.NoError(t, err)
+	require.Equal(t, "map[string]any", expr)
+
+	expr, err = namer.expr(reflect.TypeOf(routing.NoContent{}))
+	require.NoError(t, err)
+	require.Equal(t, "NoContent", expr)
+	require.Equal(
+		t,
+		"apierror",
+		namer.importAliases["github.com/forkbombeu/credimi/pkg/internal/apierror"],
+	)
+
+	_, err = namer.expr(reflect.TypeOf(struct{ Name string }{}))
+	require.ErrorContains(t, err, "anonymous schema type")
+}
+
+func TestGoClientOperationPathAndParams(t *testing.T) {
+	route := RouteInfo{
+		Method:      http.MethodGet,
+		Path:        "/api/things/{thing_id}/runs/{run-id}",
+		OperationID: "thing.runs",
+		PathParams:  []string{"thing_id", "run-id"},
+		QuerySearchAttributes: []routing.QuerySearchAttribute{
+			{Name: "status", Description: "Filter by status"},
+		},
+		OutputSchema: apierror.APIError{},
+		AuthHeaders:  testAuthHeaders,
+		Permission:   "things:read",
+	}
+
+	op, err := newGoClientOperation(route, newGoTypeNamer())
+	require.NoError(t, err)
+	require.Equal(t, "ThingRuns", op.Name)
+	require.Equal(t, "ThingRunsParams", op.paramsType())
+	require.Equal(
+		t,
+		`"/api/things/" + url.PathEscape(params.ThingId) + "/runs/" + url.PathEscape(params.RunId)`,
+		op.pathExpr(),
+	)
+	require.True(t, op.hasQuery())
+	require.Equal(t, "APIError", op.RespType)
+	require.Equal(
+		t,
+		"Requires a token or an API key. Restricted API keys need the things:read permission.",
+		op.authText(),
+	)
+}
+
+func TestRenderGoClient(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:       http.MethodPost,
+			Path:         "/api/things",
+			OperationID:  "things.create",
+			Description:  "Create a thing",
+			InputSchema:  routing.QuerySearchAttribute{},
+			OutputSchema: apierror.APIError{},
+			HasInputBody: true,
+			AuthHeaders:  testAuthHeaders,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/things/{id}",
+			OperationID: "things.delete",
+			PathParams:  []string{"id"},
+			NoContent:   true,
+		},
+		{
+			Method:              http.MethodGet,
+			Path:                "/api/things/{id}/events",
+			OperationID:         "things.events",
+			PathParams:          []string{"id"},
+			OutputSchema:        apierror.APIError{},
+			ResponseContentType: routing.ContentTypeEventStream,
+			AuthHeaders:         testAuthHeaders,
+		},
+	}
+
+	rendered, err := renderGoClient(routes)
+	require.NoError(t, err)
+	source := string(rendered)
+	require.Contains(t, source, "// "+generatedFileHeader)
+	require.Contains(t, source, `apierror "github.com/forkbombeu/credimi/pkg/internal/apierror"`)
+	require.Contains(t, source, "QuerySearchAttribute = routing.QuerySearchAttribute")
+	require.Contains(t, source, "// Create a thing.\n")
+	require.Contains(
+		t,
+		source,
+		"func (c *Client) ThingsCreate(ctx context.Context, body QuerySearchAttribute, "+
+			"opts ...RequestOption) (APIError, error) {",
+	)
+	require.Contains(
+		t,
+		source,
+		"func (c *Client) ThingsDelete(ctx context.Context, params ThingsDeleteParams, "+
+			"opts ...RequestOption) error {",
+	)
+	require.Contains(
+		t,
+		source,
+		"No authentication middleware; configured credentials are still sent.",
+	)
+	require.Contains(t, source, "return c.do(ctx, http.MethodDelete, ")
+	require.Contains(
+		t,
+		source,
+		"func (c *Client) ThingsEvents(ctx context.Context, params ThingsEventsParams, "+
+			"opts ...RequestOption) (*http.Response, error) {",
+	)
+	require.Contains(t, source, "return c.stream(ctx, http.MethodGet, ")
+
+	_, err = renderGoClient([]RouteInfo{{
+		Method:       http.MethodGet,
+		Path:         "/api/anonymous",
+		OperationID:  "anonymous.get",
+		OutputSchema: struct{ Name string }{},
+	}})
+	require.ErrorContains(t, err, "anonymous.get response")
+}
+
+func TestGeneratedClientsAreUpToDate(t *testing.T) {
+	routes, err := collectRoutes(api.RouteGroups)
+	require.NoError(t, err)
+
+	for path, render := range map[string]func([]RouteInfo) ([]byte, error){
+		"../" + goClientOutputPath:         renderGoClient,
+		"../" + typeScriptClientOutputPath: renderTypeScriptClient,
+	} {
+		want, err := render(routes)
+		require.NoError(t, err)
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			string(want),
+			string(got),
+			"%s is stale, run `go run ./generate_client` from pkg/",
+			path,
+		)
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+)
+
+// typeScriptClientOutputPath is relative to pkg/. The client types come from
+// client-types.generated.ts, which the webapp builds from the OpenAPI file.
+const typeScriptClientOutputPath = "../webapp/src/lib/credimi-client/client.ts"
+
+const typeScriptClientPreamble = `import type PocketBase from 'pocketbase';
+import type { SendOptions } from 'pocketbase';
+
+import type { operations } from './client-types.generated';
+
+type JsonContent<T> = T extends { content: { 'application/json': infer C } } ? C : void;
+type ResponseOf<Op> = Op extends { responses: { 200: infer R } } ? JsonContent<R> : void;
+type BodyOf<Op> = Op extends { requestBody: { content: { 'application/json': infer B } } }
+	? B
+	: never;
+type PathOf<Op> = Op extends { parameters: { path: infer P } } ? P : never;
+type QueryOf<Op> = Op extends { parameters: { query?: infer Q } } ? NonNullable<Q> : never;
+
+function streamEvents(
+	pb: PocketBase,
+	path: string,
+	query: Record<string, unknown> = {},
+	init: RequestInit = {}
+): Promise<Response> {
+	const url = new URL(pb.buildURL(path));
+	for (const [key, value] of Object.entries(query)) {
+		if (value !== undefined && value !== null && value !== '') {
+			url.searchParams.set(key, String(value));
+		}
+	}
+	const headers = new Headers(init.headers);
+	headers.set('Accept', 'text/event-stream');
+	if (pb.authStore.token) headers.set('Authorization', pb.authStore.token);
+	return fetch(url, { ...init, headers });
+}
+
+`
+
+func renderTypeScriptClient(routes []RouteInfo) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("// SPDX-FileCopyrightText: 2026 Forkbomb BV\n//\n")
+	out.WriteString("// SPDX-License-Identifier: AGPL-3.0-or-later\n\n")
+	out.WriteString("// " + generatedFileHeader + "\n\n")
+	out.WriteString(typeScriptClientPreamble)
+	out.WriteString("export function createCredimiClient(pb: PocketBase) {\n\treturn {\n")
+	for i, route := range routes {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		writeTypeScriptOperation(&out, route)
+	}
+	out.WriteString("\t};\n}\n\n")
+	out.WriteString("export type CredimiClient = ReturnType<typeof createCredimiClient>;\n")
+	return out.Bytes(), nil
+}
+
+func writeTypeScriptOperation(w *bytes.Buffer, route RouteInfo) {
+	op := fmt.Sprintf("operations[%s]", typeScriptString(route.OperationID))
+	streams := route.ResponseContentType == routing.ContentTypeEventStream
+	goOp := goClientOperation{Route: route}
+
+	w.WriteString("\t\t/**\n")
+	fmt.Fprintf(w, "\t\t * %s %s\n", route.Method, route.Path)
+	if description := firstNonEmpty(route.Description, route.Summary); description != "" {
+		fmt.Fprintf(w, "\t\t *\n\t\t * %s\n", sanitizeComment(description))
+	}
+	fmt.Fprintf(w, "\t\t * %s\n", goOp.authText())
+	w.WriteString("\t\t */\n")
+
+	var args []string
+	if len(route.PathParams) > 0 {
+		args = append(args, fmt.Sprintf("path: PathOf<%s>", op))
+	}
+	hasQuery := !route.HasInputBody && len(route.QuerySearchAttributes) > 0
+	if route.HasInputBody {
+		args = append(args, fmt.Sprintf("body: BodyOf<%s>", op))
+	}
+	if hasQuery {
+		args = append(args, fmt.Sprintf("query?: QueryOf<%s>", op))
+	}
+	if streams {
+		args = append(args, "init?: RequestInit")
+	} else {
+		args = append(args, "options?: SendOptions")
+	}
+	fmt.Fprintf(w, "\t\t%s: (\n", lowerFirst(exportName(route.OperationID)))
+	for _, arg := range args {
+		fmt.Fprintf(w, "\t\t\t%s,\n", arg)
+	}
+	w.WriteString("\t\t) =>\n")
+
+	path := typeScriptPath(route.Path)
+	if streams {
+		queryArg := "{}"
+		if hasQuery {
+			queryArg = "query"
+		}
+		fmt.Fprintf(w, "\t\t\tstreamEvents(pb, %s, %s, init),\n", path, queryArg)
+		return
+	}
+
+	result := fmt.Sprintf("ResponseOf<%s>", op)
+	if route.NoContent {
+		result = "void"
+	}
+	fields := []string{"...options", fmt.Sprintf("method: '%s'", route.Method)}
+	if route.HasInputBody {
+		fields = append(fields, "body")
+	}
+	if hasQuery {
+		fields = append(fields, "query: { ...options?.query, ...query }")
+	}
+	fmt.Fprintf(w, "\t\t\tpb.send<%s>(%s, { %s }),\n", result, path, strings.Join(fields, ", "))
+}
+
+// typeScriptPath renders the route path as a template literal that encodes
+// each path parameter.
+func typeScriptPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	hasParams := false
+	for i, segment := range segments {
+		if isPathParam(segment) {
+			name := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+			segments[i] = fmt.Sprintf("${encodeURIComponent(path[%s])}", typeScriptString(name))
+			hasParams = true
+		}
+	}
+	joined := "/" + strings.Join(segments, "/")
+	if !hasParams {
+		return typeScriptString(joined)
+	}
+	return "`" + joined + "`"
+}
+
+func typeScriptString(value string) string {
+	quoted := strconv.Quote(value)
+	quoted = strings.ReplaceAll(quoted[1:len(quoted)-1], "'", `\'`)
+	return "'" + quoted + "'"
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScriptPath(t *testing.T) {
+	require.Equal(t, "'/api/things'", typeScriptPath("/api/things"))
+	require.Equal(
+		t,
+		"`/api/things/${encodeURIComponent(path['thing_id'])}/runs`",
+		typeScriptPath("/api/things/{thing_id}/runs"),
+	)
+	require.Equal(t, `'it\'s'`, typeScriptString("it's"))
+	require.Equal(t, "thingsCreate", lowerFirst(exportName("things.create")))
+}
+
+func TestRenderTypeScriptClient(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:       http.MethodPost,
+			Path:         "/api/things",
+			OperationID:  "things.create",
+			HasInputBody: true,
+			AuthHeaders:  testAuthHeaders,
+			Permission:   "things:write",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/things/{id}",
+			OperationID: "things.get",
+			PathParams:  []string{"id"},
+			QuerySearchAttributes: []routing.QuerySearchAttribute{
+				{Name: "expand"},
+			},
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/things/{id}",
+			OperationID: "things.delete",
+			PathParams:  []string{"id"},
+			NoContent:   true,
+		},
+		{
+			Method:              http.MethodGet,
+			Path:                "/api/things/{id}/events",
+			OperationID:         "things.events",
+			PathParams:          []string{"id"},
+			ResponseContentType: routing.ContentTypeEventStream,
+		},
+	}
+
+	rendered, err := renderTypeScriptClient(routes)
+	require.NoError(t, err)
+	source := string(rendered)
+	require.Contains(t, source, "// "+generatedFileHeader)
+	require.Contains(t, source, "export function createCredimiClient(pb: PocketBase) {")
+	require.Contains(
+		t,
+		source,
+		"\t\tthingsCreate: (\n\t\t\tbody: BodyOf<operations['things.create']>,\n"+
+			"\t\t\toptions?: SendOptions,\n\t\t) =>\n"+
+			"\t\t\tpb.send<ResponseOf<operations['things.create']>>('/api/things', "+
+			"{ ...options, method: 'POST', body }),\n",
+	)
+	require.Contains(t, source, "Restricted API keys need the things:write permission.")
+	require.Contains(t, source, "query?: QueryOf<operations['things.get']>,")
+	require.Contains(t, source, "query: { ...options?.query, ...query }")
+	require.Contains(t, source, "pb.send<void>(`/api/things/${encodeURIComponent(path['id'])}`")
+	require.Contains(
+		t,
+		source,
+		"streamEvents(pb, `/api/things/${encodeURIComponent(path['id'])}/events`, {}, init),",
+	)
+	require.Contains(
+		t,
+		source,
+		"export type CredimiClient = ReturnType<typeof createCredimiClient>;",
+	)
+}
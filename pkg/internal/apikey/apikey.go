@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package apikey defines the format of Credimi API keys. A key is
+// cred_<id>_<secret>: the id is public and indexed in the api_keys
+// collection, so a key is found with a single query, and only the bcrypt
+// hash of the secret is stored. Keys issued before the prefix have no id:
+// the first time one matches the remaining legacy records, its SHA-256
+// fingerprint is stored, so that later lookups use that index too.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	Prefix = "cred_"
+
+	// IDLength is the length of the public identifier.
+	IDLength = 12
+
+	// FieldKeyID and FieldLastUsedAt are the api_keys fields holding the
+	// public identifier and the time of the last successful authentication.
+	FieldKeyID      = "key_id"
+	FieldLastUsedAt = "last_used_at"
+
+	// FieldLegacyFingerprint holds the LegacyFingerprint of a key issued
+	// before the prefix, once it has been used.
+	FieldLegacyFingerprint = "legacy_fingerprint"
+
+	// LegacyFilter selects the keys issued before the prefix that have not
+	// been fingerprinted yet.
+	LegacyFilter = FieldKeyID + " = '' && " + FieldLegacyFingerprint + " = ''"
+
+	// LastUsedResolution bounds how often last_used_at is written, so a
+	// busy key does not update its record on every request.
+	LastUsedResolution = time.Minute
+)
+
+const idAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// NewID returns a random public identifier.
+func NewID() (string, error) {
+	buf := make([]byte, IDLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key id: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = idAlphabet[int(b)%len(idAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// Format joins an identifier and a secret into an API key.
+func Format(id, secret string) string {
+	return Prefix + id + "_" + secret
+}
+
+// Parse splits a prefixed API key into its identifier and secret. It
+// reports false for legacy keys and malformed prefixed keys.
+func Parse(key string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, Prefix)
+	if !found || len(rest) < IDLength+2 || rest[IDLength] != '_' {
+		return "", "", false
+	}
+	id = rest[:IDLength]
+	for _, c := range id {
+		if !strings.ContainsRune(idAlphabet, c) {
+			return "", "", false
+		}
+	}
+	return id, rest[IDLength+1:], true
+}
+
+// LegacyFingerprint returns the indexed fingerprint of a key issued before
+// the prefix. It only locates the record: the key is still compared with
+// the stored hash.
+func LegacyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPrefixed reports whether the key looks like a prefixed key, even a
+// malformed one, so that it is never matched against legacy records.
+func IsPrefixed(key string) bool {
+	return strings.HasPrefix(key, Prefix)
+}
+
+// ShouldTouch reports whether last_used_at, last written at lastUsed,
+// should be updated at now.
+func ShouldTouch(lastUsed, now time.Time) bool {
+	return lastUsed.IsZero() || now.Sub(lastUsed) >= LastUsedResolution
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apikey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDAndParse(t *testing.T) {
+	id, err := NewID()
+	require.NoError(t, err)
+	require.Len(t, id, IDLength)
+
+	secret := "c2VjcmV0_with-url_safe=="
+	key := Format(id, secret)
+	require.True(t, IsPrefixed(key))
+	require.LessOrEqual(t, len(Format(id, "0123456789012345678901234567890123456789012=")), 72)
+
+	parsedID, parsedSecret, ok := Parse(key)
+	require.True(t, ok)
+	require.Equal(t, id, parsedID)
+	require.Equal(t, secret, parsedSecret)
+}
+
+func TestParseRejectsMalformedKeys(t *testing.T) {
+	for name, key := range map[string]string{
+		"legacy":          "bGVnYWN5LWtleQ==",
+		"empty secret":    "cred_abcdefghijkl_",
+		"short id":        "cred_abc_secret",
+		"missing sep":     "cred_abcdefghijklsecret",
+		"uppercase id":    "cred_ABCDEFGHIJKL_secret",
+		"only the prefix": "cred_",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, ok := Parse(key)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestShouldTouch(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.True(t, ShouldTouch(time.Time{}, now))
+	require.False(t, ShouldTouch(now.Add(-30*time.Second), now))
+	require.True(t, ShouldTouch(now.Add(-LastUsedResolution), now))
+}
@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apikey
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Permission is what a route requires from a restricted API key, declared
+// with routing.RouteDefinition.Permission.
+type Permission string
+
+const (
+	PermissionAPIKeysWrite      Permission = "apikeys:write"
+	PermissionAuditRead         Permission = "audit:read"
+	PermissionConfigRead        Permission = "config:read"
+	PermissionConfigWrite       Permission = "config:write"
+	PermissionIntegrationsRun   Permission = "integrations:run"
+	PermissionIssuersWrite      Permission = "issuers:write"
+	PermissionOrganizationsRead Permission = "organizations:read"
+	PermissionPipelinesRead     Permission = "pipelines:read"
+	PermissionPipelinesRun      Permission = "pipelines:run"
+	PermissionResultsRead       Permission = "results:read"
+	PermissionResultsWrite      Permission = "results:write"
+	PermissionRunnersHeartbeat  Permission = "runners:heartbeat"
+	PermissionRunnersRead       Permission = "runners:read"
+	PermissionRunnersWrite      Permission = "runners:write"
+	PermissionSchedulesRead     Permission = "schedules:read"
+	PermissionSchedulesWrite    Permission = "schedules:write"
+	PermissionTemplatesRead     Permission = "templates:read"
+	PermissionTrustedListsWrite Permission = "trusted-lists:write"
+	PermissionVerifiersWrite    Permission = "verifiers:write"
+	PermissionWalletsRead       Permission = "wallets:read"
+	PermissionWalletsWrite      Permission = "wallets:write"
+	PermissionWebhooksRead      Permission = "webhooks:read"
+	PermissionWebhooksWrite     Permission = "webhooks:write"
+	PermissionWorkflowsRead     Permission = "workflows:read"
+	PermissionWorkflowsRun      Permission = "workflows:run"
+)
+
+// Permissions lists every known permission.
+var Permissions = []Permission{
+	PermissionAPIKeysWrite,
+	PermissionAuditRead,
+	PermissionConfigRead,
+	PermissionConfigWrite,
+	PermissionIntegrationsRun,
+	PermissionIssuersWrite,
+	PermissionOrganizationsRead,
+	PermissionPipelinesRead,
+	PermissionPipelinesRun,
+	PermissionResultsRead,
+	PermissionResultsWrite,
+	PermissionRunnersHeartbeat,
+	PermissionRunnersRead,
+	PermissionRunnersWrite,
+	PermissionSchedulesRead,
+	PermissionSchedulesWrite,
+	PermissionTemplatesRead,
+	PermissionTrustedListsWrite,
+	PermissionVerifiersWrite,
+	PermissionWalletsRead,
+	PermissionWalletsWrite,
+	PermissionWebhooksRead,
+	PermissionWebhooksWrite,
+	PermissionWorkflowsRead,
+	PermissionWorkflowsRun,
+}
+
+// Resource names the records, by id, a key can be restricted to.
+type Resource string
+
+const (
+	ResourcePipelines Resource = "pipelines"
+	ResourceWallets   Resource = "wallets"
+	ResourceRunners   Resource = "runners"
+)
+
+// Resources lists every resource a key can be restricted to.
+var Resources = []Resource{ResourcePipelines, ResourceWallets, ResourceRunners}
+
+const (
+	FieldPermissions = "permissions"
+	FieldResources   = "resources"
+	FieldIPRanges    = "ip_ranges"
+)
+
+// Grant restricts what an API key may do. The zero Grant is unrestricted,
+// which is how keys created before permissions existed keep working. A key
+// with permissions may only call routes declaring one of them; routes that
+// declare none are closed to it.
+type Grant struct {
+	Permissions []Permission          `json:"permissions,omitempty"`
+	Resources   map[Resource][]string `json:"resources,omitempty"`
+	IPRanges    []string              `json:"ip_ranges,omitempty"`
+}
+
+// Validate rejects unknown permissions and resources, and IP ranges that
+// are neither a CIDR prefix nor a single address.
+func (g Grant) Validate() error {
+	for _, permission := range g.Permissions {
+		if !slices.Contains(Permissions, permission) {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	for resource := range g.Resources {
+		if !slices.Contains(Resources, resource) {
+			return fmt.Errorf("unknown resource %q", resource)
+		}
+	}
+	for _, ipRange := range g.IPRanges {
+		if _, err := parseIPRange(ipRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllowsPermission reports whether a route declaring permission may be
+// called. An empty permission is a route that declares none.
+func (g Grant) AllowsPermission(permission Permission) bool {
+	if len(g.Permissions) == 0 {
+		return true
+	}
+	return permission != "" && slices.Contains(g.Permissions, permission)
+}
+
+// AllowsResource reports whether the record id of resource may be used.
+func (g Grant) AllowsResource(resource Resource, id string) bool {
+	ids, restricted := g.Resources[resource]
+	return !restricted || slices.Contains(ids, id)
+}
+
+// AllowsIP reports whether a request from ip may use the key. Unparsable
+// addresses are refused when the key has IP ranges.
+func (g Grant) AllowsIP(ip string) bool {
+	if len(g.IPRanges) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, ipRange := range g.IPRanges {
+		prefix, err := parseIPRange(ipRange)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether other grants nothing beyond g, so that a
+// restricted key can only create keys at most as powerful as itself.
+func (g Grant) Covers(other Grant) bool {
+	if len(g.Permissions) > 0 {
+		if len(other.Permissions) == 0 {
+			return false
+		}
+		for _, permission := range other.Permissions {
+			if !slices.Contains(g.Permissions, permission) {
+				return false
+			}
+		}
+	}
+	for resource, ids := range g.Resources {
+		otherIDs, restricted := other.Resources[resource]
+		if !restricted {
+			return false
+		}
+		for _, id := range otherIDs {
+			if !slices.Contains(ids, id) {
+				return false
+			}
+		}
+	}
+	if len(g.IPRanges) > 0 {
+		if len(other.IPRanges) == 0 {
+			return false
+		}
+		for _, ipRange := range other.IPRanges {
+			if !slices.Contains(g.IPRanges, ipRange) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Apply stores the grant on an api_keys record.
+func (g Grant) Apply(record *core.Record) {
+	record.Set(FieldPermissions, g.Permissions)
+	record.Set(FieldResources, g.Resources)
+	record.Set(FieldIPRanges, g.IPRanges)
+}
+
+// GrantFromRecord reads the grant of an api_keys record.
+func GrantFromRecord(record *core.Record) (Grant, error) {
+	var grant Grant
+	if err := unmarshalField(record, FieldPermissions, &grant.Permissions); err != nil {
+		return Grant{}, err
+	}
+	if err := unmarshalField(record, FieldResources, &grant.Resources); err != nil {
+		return Grant{}, err
+	}
+	if err := unmarshalField(record, FieldIPRanges, &grant.IPRanges); err != nil {
+		return Grant{}, err
+	}
+	return grant, nil
+}
+
+func unmarshalField(record *core.Record, field string, target any) error {
+	raw := strings.TrimSpace(record.GetString(field))
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), target); err != nil {
+		return fmt.Errorf("invalid API key %s: %w", field, err)
+	}
+	return nil
+}
+
+func parseIPRange(ipRange string) (netip.Prefix, error) {
+	ipRange = strings.TrimSpace(ipRange)
+	if !strings.Contains(ipRange, "/") {
+		addr, err := netip.ParseAddr(ipRange)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP range %q", ipRange)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(ipRange)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP range %q", ipRange)
+	}
+	return prefix.Masked(), nil
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apikey
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantValidate(t *testing.T) {
+	require.NoError(t, Grant{}.Validate())
+	require.NoError(t, Grant{
+		Permissions: []Permission{PermissionPipelinesRun},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p1"}},
+		IPRanges:    []string{"10.0.0.0/8", "192.168.1.4", "2001:db8::/32"},
+	}.Validate())
+
+	require.Error(t, Grant{Permissions: []Permission{"pipelines:delete"}}.Validate())
+	require.Error(t, Grant{Resources: map[Resource][]string{"secrets": {"s1"}}}.Validate())
+	require.Error(t, Grant{IPRanges: []string{"not-an-ip"}}.Validate())
+}
+
+func TestGrantAllowsPermission(t *testing.T) {
+	require.True(t, Grant{}.AllowsPermission(PermissionPipelinesRun))
+	require.True(t, Grant{}.AllowsPermission(""))
+
+	grant := Grant{Permissions: []Permission{PermissionPipelinesRun}}
+	require.True(t, grant.AllowsPermission(PermissionPipelinesRun))
+	require.False(t, grant.AllowsPermission(PermissionPipelinesRead))
+	require.False(t, grant.AllowsPermission(""))
+}
+
+func TestGrantAllowsResource(t *testing.T) {
+	grant := Grant{Resources: map[Resource][]string{ResourcePipelines: {"p1"}}}
+	require.True(t, grant.AllowsResource(ResourcePipelines, "p1"))
+	require.False(t, grant.AllowsResource(ResourcePipelines, "p2"))
+	require.True(t, grant.AllowsResource(ResourceWallets, "w1"))
+}
+
+func TestGrantAllowsIP(t *testing.T) {
+	require.True(t, Grant{}.AllowsIP("203.0.113.7"))
+
+	grant := Grant{IPRanges: []string{"10.0.0.0/8", "192.168.1.4", "2001:db8::/32"}}
+	require.True(t, grant.AllowsIP("10.1.2.3"))
+	require.True(t, grant.AllowsIP("192.168.1.4"))
+	require.True(t, grant.AllowsIP("::ffff:10.1.2.3"))
+	require.True(t, grant.AllowsIP("2001:db8::1"))
+	require.False(t, grant.AllowsIP("192.168.1.5"))
+	require.False(t, grant.AllowsIP("garbage"))
+}
+
+func TestGrantCovers(t *testing.T) {
+	caller := Grant{
+		Permissions: []Permission{PermissionPipelinesRun, PermissionResultsRead},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p1", "p2"}},
+		IPRanges:    []string{"10.0.0.0/8"},
+	}
+
+	require.True(t, Grant{}.Covers(caller))
+	require.True(t, caller.Covers(Grant{
+		Permissions: []Permission{PermissionResultsRead},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p1"}},
+		IPRanges:    []string{"10.0.0.0/8"},
+	}))
+
+	require.False(t, caller.Covers(Grant{}))
+	require.False(t, caller.Covers(Grant{
+		Permissions: []Permission{PermissionWalletsWrite},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p1"}},
+		IPRanges:    []string{"10.0.0.0/8"},
+	}))
+	require.False(t, caller.Covers(Grant{
+		Permissions: []Permission{PermissionResultsRead},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p3"}},
+		IPRanges:    []string{"10.0.0.0/8"},
+	}))
+	require.False(t, caller.Covers(Grant{
+		Permissions: []Permission{PermissionResultsRead},
+		Resources:   map[Resource][]string{ResourcePipelines: {"p1"}},
+	}))
+}
+
+func TestGrantFromRecord(t *testing.T) {
+	collection := core.NewBaseCollection("api_keys")
+	collection.Fields.Add(
+		&core.JSONField{Name: FieldPermissions},
+		&core.JSONField{Name: FieldResources},
+		&core.JSONField{Name: FieldIPRanges},
+	)
+
+	record := core.NewRecord(collection)
+	empty, err := GrantFromRecord(record)
+	require.NoError(t, err)
+	require.Equal(t, Grant{}, empty)
+
+	grant := Grant{
+		Permissions: []Permission{PermissionRunnersHeartbeat},
+		Resources:   map[Resource][]string{ResourceRunners: {"r1"}},
+		IPRanges:    []string{"10.0.0.1"},
+	}
+	grant.Apply(record)
+
+	read, err := GrantFromRecord(record)
+	require.NoError(t, err)
+	require.Equal(t, grant, read)
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Collection is the collection holding the API keys.
+const Collection = "api_keys"
+
+// Store is the part of the app used to look keys up and record their use.
+type Store interface {
+	FindFirstRecordByData(collectionNameOrId, key string, value any) (*core.Record, error)
+	FindRecordsByFilter(
+		collectionNameOrId, filter, sort string,
+		limit, offset int,
+	) ([]*core.Record, error)
+	Save(record *core.Record) error
+}
+
+// CoreStore adapts a core.App to Store.
+func CoreStore(app core.App) Store {
+	return coreStore{app: app}
+}
+
+type coreStore struct {
+	app core.App
+}
+
+func (s coreStore) FindFirstRecordByData(
+	collectionNameOrId, key string,
+	value any,
+) (*core.Record, error) {
+	return s.app.FindFirstRecordByData(collectionNameOrId, key, value)
+}
+
+func (s coreStore) FindRecordsByFilter(
+	collectionNameOrId, filter, sort string,
+	limit, offset int,
+) ([]*core.Record, error) {
+	return s.app.FindRecordsByFilter(collectionNameOrId, filter, sort, limit, offset)
+}
+
+func (s coreStore) Save(record *core.Record) error {
+	return s.app.Save(record)
+}
+
+// CompareFunc returns nil when key matches the stored hash.
+type CompareFunc func(hash, key string) error
+
+// MatchFunc returns the record of records whose stored hash matches key.
+type MatchFunc func(records []*core.Record, key string) (*core.Record, error)
+
+// Find returns the record of an API key. A prefixed key is looked up by its
+// indexed identifier and the hash of its secret is compared once. A legacy
+// key is looked up by its fingerprint, and otherwise matched against the
+// records that have no identifier nor fingerprint yet; the first match
+// stores the fingerprint so that the key is not matched again. compare
+// defaults to bcrypt and match to comparing each legacy record with
+// compare.
+func Find(
+	store Store,
+	key string,
+	compare CompareFunc,
+	match MatchFunc,
+) (*core.Record, *apierror.APIError) {
+	invalid := apierror.New(
+		http.StatusUnauthorized,
+		"request.validation",
+		"invalid_api_key",
+		"Invalid API key provided",
+	)
+	if compare == nil {
+		compare = compareBcrypt
+	}
+
+	if IsPrefixed(key) {
+		keyID, secret, ok := Parse(key)
+		if !ok {
+			return nil, invalid
+		}
+		record, err := store.FindFirstRecordByData(Collection, FieldKeyID, keyID)
+		if err != nil || record == nil {
+			return nil, invalid
+		}
+		if err := compare(record.GetString("key"), secret); err != nil {
+			return nil, invalid
+		}
+		return record, nil
+	}
+
+	fingerprint := LegacyFingerprint(key)
+	record, err := store.FindFirstRecordByData(Collection, FieldLegacyFingerprint, fingerprint)
+	if err == nil && record != nil {
+		if err := compare(record.GetString("key"), key); err != nil {
+			return nil, invalid
+		}
+		return record, nil
+	}
+
+	records, err := store.FindRecordsByFilter(Collection, LegacyFilter, "", 0, 0)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_find_api_key_records",
+			err.Error(),
+		)
+	}
+	if match == nil {
+		match = func(records []*core.Record, key string) (*core.Record, error) {
+			return matchRecord(records, key, compare), nil
+		}
+	}
+	record, err = match(records, key)
+	if err != nil || record == nil {
+		return nil, invalid
+	}
+	// Failing to store the fingerprint only keeps the key on the scan.
+	record.Set(FieldLegacyFingerprint, fingerprint)
+	_ = store.Save(record)
+	return record, nil
+}
+
+// TouchLastUsed records the authentication time at most once per
+// LastUsedResolution. Failing to save it does not fail the request.
+func TouchLastUsed(store Store, record *core.Record) {
+	now := types.NowDateTime()
+	if !ShouldTouch(record.GetDateTime(FieldLastUsedAt).Time(), now.Time()) {
+		return
+	}
+	record.Set(FieldLastUsedAt, now)
+	_ = store.Save(record)
+}
+
+func compareBcrypt(hash, key string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(key))
+}
+
+func matchRecord(records []*core.Record, key string, compare CompareFunc) *core.Record {
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		hash := record.GetString("key")
+		if hash == "" {
+			continue
+		}
+		if err := compare(hash, key); err == nil {
+			return record
+		}
+	}
+	return nil
+}
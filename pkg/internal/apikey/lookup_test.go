@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeStore struct {
+	byKeyID       map[string]*core.Record
+	byFingerprint map[string]*core.Record
+	legacy        []*core.Record
+	err           error
+	saved         int
+	filters       []string
+	lookedUp      []string
+}
+
+func (s *fakeStore) FindFirstRecordByData(_, key string, value any) (*core.Record, error) {
+	records := s.byKeyID
+	if key == FieldLegacyFingerprint {
+		records = s.byFingerprint
+	} else {
+		s.lookedUp = append(s.lookedUp, value.(string))
+	}
+	record, ok := records[value.(string)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return record, nil
+}
+
+func (s *fakeStore) FindRecordsByFilter(_, filter, _ string, _, _ int) ([]*core.Record, error) {
+	s.filters = append(s.filters, filter)
+	return s.legacy, s.err
+}
+
+func (s *fakeStore) Save(record *core.Record) error {
+	s.saved++
+	if fingerprint := record.GetString(FieldLegacyFingerprint); fingerprint != "" {
+		if s.byFingerprint == nil {
+			s.byFingerprint = map[string]*core.Record{}
+		}
+		s.byFingerprint[fingerprint] = record
+	}
+	return nil
+}
+
+func newKeyRecord(t *testing.T, secret string) *core.Record {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.MinCost)
+	require.NoError(t, err)
+	record := core.NewRecord(core.NewBaseCollection(Collection))
+	record.Set("key", string(hash))
+	return record
+}
+
+func TestFind(t *testing.T) {
+	prefixed := newKeyRecord(t, "secret")
+	legacy := newKeyRecord(t, "legacy-key")
+	store := &fakeStore{
+		byKeyID: map[string]*core.Record{"abcdefghijkl": prefixed},
+		legacy:  []*core.Record{nil, newKeyRecord(t, "other"), legacy},
+	}
+
+	record, apiErr := Find(store, Format("abcdefghijkl", "secret"), nil, nil)
+	require.Nil(t, apiErr)
+	require.Same(t, prefixed, record)
+	require.Empty(t, store.filters)
+
+	record, apiErr = Find(store, "legacy-key", nil, nil)
+	require.Nil(t, apiErr)
+	require.Same(t, legacy, record)
+	require.Equal(t, []string{LegacyFilter}, store.filters)
+
+	for _, key := range []string{
+		Format("abcdefghijkl", "wrong"),
+		Format("mnopqrstuvwx", "secret"),
+		"cred_malformed",
+		"unknown-legacy-key",
+	} {
+		_, apiErr = Find(store, key, nil, nil)
+		require.NotNil(t, apiErr, key)
+		require.Equal(t, http.StatusUnauthorized, apiErr.Code)
+		require.Equal(t, "invalid_api_key", apiErr.Reason)
+	}
+	require.Equal(t, []string{"abcdefghijkl", "abcdefghijkl", "mnopqrstuvwx"}, store.lookedUp)
+
+	store.err = errors.New("database is locked")
+	_, apiErr = Find(store, "unknown-legacy-key", nil, nil)
+	require.Equal(t, http.StatusInternalServerError, apiErr.Code)
+}
+
+func TestFindWithCustomMatchers(t *testing.T) {
+	prefixed := newKeyRecord(t, "secret")
+	legacy := newKeyRecord(t, "legacy-key")
+	store := &fakeStore{
+		byKeyID: map[string]*core.Record{"abcdefghijkl": prefixed},
+		legacy:  []*core.Record{legacy},
+	}
+	var compared []string
+	compare := func(_, key string) error {
+		compared = append(compared, key)
+		return nil
+	}
+	match := func(records []*core.Record, key string) (*core.Record, error) {
+		require.Equal(t, "legacy-key", key)
+		return records[0], nil
+	}
+
+	record, apiErr := Find(store, Format("abcdefghijkl", "anything"), compare, match)
+	require.Nil(t, apiErr)
+	require.Same(t, prefixed, record)
+	require.Equal(t, []string{"anything"}, compared)
+
+	record, apiErr = Find(store, "legacy-key", compare, match)
+	require.Nil(t, apiErr)
+	require.Same(t, legacy, record)
+}
+
+func TestFindFingerprintsLegacyKeys(t *testing.T) {
+	legacy := newKeyRecord(t, "legacy-key")
+	store := &fakeStore{legacy: []*core.Record{newKeyRecord(t, "other"), legacy}}
+	var compared int
+	compare := func(hash, key string) error {
+		compared++
+		return compareBcrypt(hash, key)
+	}
+
+	record, apiErr := Find(store, "legacy-key", compare, nil)
+	require.Nil(t, apiErr)
+	require.Same(t, legacy, record)
+	require.Equal(t, LegacyFingerprint("legacy-key"), legacy.GetString(FieldLegacyFingerprint))
+	require.Equal(t, 1, store.saved)
+	require.Len(t, store.filters, 1)
+
+	// The fingerprinted key is found by its index and compared once, without
+	// scanning the legacy records again.
+	compared = 0
+	record, apiErr = Find(store, "legacy-key", compare, nil)
+	require.Nil(t, apiErr)
+	require.Same(t, legacy, record)
+	require.Equal(t, 1, compared)
+	require.Len(t, store.filters, 1)
+
+	// The fingerprint only locates the record: the hash must still match.
+	legacy.Set("key", newKeyRecord(t, "rotated").GetString("key"))
+	_, apiErr = Find(store, "legacy-key", compare, nil)
+	require.NotNil(t, apiErr)
+	require.Equal(t, "invalid_api_key", apiErr.Reason)
+	require.Len(t, store.filters, 1)
+}
+
+func TestTouchLastUsed(t *testing.T) {
+	store := &fakeStore{}
+	record := newKeyRecord(t, "secret")
+
+	TouchLastUsed(store, record)
+	require.Equal(t, 1, store.saved)
+	require.False(t, record.GetDateTime(FieldLastUsedAt).IsZero())
+
+	TouchLastUsed(store, record)
+	require.Equal(t, 1, store.saved)
+
+	stale, err := types.ParseDateTime(time.Now().Add(-2 * LastUsedResolution))
+	require.NoError(t, err)
+	record.Set(FieldLastUsedAt, stale)
+	TouchLastUsed(store, record)
+	require.Equal(t, 2, store.saved)
+}
@@ -6,6 +6,7 @@ package apis
 
 import (
 	"github.com/forkbombeu/credimi/pkg/internal/apis/handlers"
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -16,6 +17,11 @@ var RouteGroups []routing.RouteGroup = []routing.RouteGroup{
 	handlers.SchedulesRoutes,
 	handlers.CustomIntegrationsRoutes,
 	handlers.MobileRunnersPublicRoutes,
+	handlers.ScoreboardTrendRoutes,
+	handlers.ScoreboardInteropRoutes,
+	handlers.WebhookRoutes,
+	handlers.PipelinePublicRoutes,
+	handlers.OrganizationPublicRoutes,
 	// handlers.ScoreboardRoutes,
 }
 
@@ -24,9 +30,12 @@ var RouteGroupsNotExported []routing.RouteGroup = []routing.RouteGroup{
 	handlers.TemplateRoutes,
 	handlers.IssuersRoutes,
 	handlers.IssuerTemporalInternalRoutes,
+	handlers.TrustedListRoutes,
+	handlers.TrustedListTemporalInternalRoutes,
 	handlers.CredentialTemporalInternalRoutes,
 	handlers.WalletRoutes,
 	handlers.WalletTemporalInternalRoutes,
+	handlers.VerifierRoutes,
 	handlers.VerifierTemporalInternalRoutes,
 	handlers.DeepLinkRoutes,
 	handlers.WorkflowListingRoutes,
@@ -36,14 +45,22 @@ var RouteGroupsNotExported []routing.RouteGroup = []routing.RouteGroup{
 	handlers.ConformanceCheckRoutes,
 	handlers.OrganizationRoutes,
 	handlers.OrganizationTemporalInternalRoutes,
+	handlers.WebhookTemporalInternalRoutes,
 	// handlers.ScoreboardPublicRoutes,
 	handlers.CloneRecord,
 	handlers.MobileRunnerRegistrationRoutes,
 	handlers.MobileRunnerLifecycleRoutes,
 	handlers.MobileRunnersTemporalInternalRoutes,
+	handlers.MetricsRoutes,
+	handlers.ReferenceRoutes,
+	handlers.ReferenceWellKnownRoutes,
 }
 
 func RegisterMyRoutes(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.Bind(metrics.APIMiddleware())
+		return se.Next()
+	})
 	for _, group := range RouteGroups {
 		group.Add(app)
 	}
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/pocketbase/pocketbase/core"
@@ -22,6 +24,7 @@ var ApiKeyRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/generate",
+			Permission:     apikey.PermissionAPIKeysWrite,
 			OperationID:    "apiKey.generate",
 			Handler:        GenerateApiKey,
 			RequestSchema:  GenerateApiKeyRequest{},
@@ -33,6 +36,19 @@ var ApiKeyRoutes = routing.RouteGroup{
 				{Func: middlewares.ErrorHandlingMiddleware},
 			},
 		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/rotate",
+			OperationID:    "apiKey.rotate",
+			Handler:        RotateApiKey,
+			ResponseSchema: GenerateApiKeyResponse{},
+			Description: "Replace the API key sent in Credimi-Api-Key with a new one. " +
+				"Keys issued before the cred_ prefix are migrated to it.",
+			Summary: "Rotate API Key",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				{Func: middlewares.ErrorHandlingMiddleware},
+			},
+		},
 		{
 			Method:         http.MethodGet,
 			Path:           "/authenticate",
@@ -64,6 +80,11 @@ var ApiKeyRoutes = routing.RouteGroup{
 
 type GenerateApiKeyRequest struct {
 	Name string `json:"name" validate:"required"`
+	// Permissions, Resources and IPRanges restrict the key. A key without
+	// permissions can call every route its owner can.
+	Permissions []apikey.Permission          `json:"permissions,omitempty"`
+	Resources   map[apikey.Resource][]string `json:"resources,omitempty"`
+	IPRanges    []string                     `json:"ip_ranges,omitempty"`
 }
 type GenerateApiKeyResponse struct {
 	ApiKey string `json:"api_key"`
@@ -78,8 +99,11 @@ type AuthenticateInternalAdminAPIKeyResponse struct {
 }
 
 type APIKeyGenerationService interface {
-	GenerateApiKey(userID, name string) (string, error)
-	GenerateInternalAdminAPIKey(superuserID, name string) (string, error)
+	GenerateApiKeyWithGrant(userID, name string, grant apikey.Grant) (string, error)
+	GenerateInternalAdminAPIKeyWithGrant(
+		superuserID, name string,
+		grant apikey.Grant,
+	) (string, error)
 }
 
 func GenerateApiKey() func(e *core.RequestEvent) error {
@@ -103,8 +127,22 @@ func GenerateApiKey() func(e *core.RequestEvent) error {
 			)
 		}
 
+		grant := apikey.Grant{
+			Permissions: input.Permissions,
+			Resources:   input.Resources,
+			IPRanges:    input.IPRanges,
+		}
+		if callerGrant, ok := middlewares.APIKeyGrant(e); ok && !callerGrant.Covers(grant) {
+			return apierror.New(
+				http.StatusForbidden,
+				"request.validation",
+				"api_key_grant_exceeds_caller",
+				"an API key cannot generate a key with more access than its own",
+			)
+		}
+
 		service := NewApiKeyService(NewAppAdapter(e.App))
-		apiKey, err := generateAPIKeyForPrincipal(service, e.Auth, input.Name)
+		apiKey, err := generateAPIKeyForPrincipal(service, e.Auth, input.Name, grant)
 		if err != nil {
 			apiErr := &apierror.APIError{}
 			if errors.As(err, &apiErr) {
@@ -112,6 +150,14 @@ func GenerateApiKey() func(e *core.RequestEvent) error {
 			}
 			return err
 		}
+		if record := findFormattedAPIKeyRecord(e.App, apiKey); record != nil {
+			recordAudit(e, audit.Entry{
+				Action:       audit.ActionAPIKeyCreated,
+				ResourceType: "api_keys",
+				ResourceID:   record.Id,
+				Changes:      audit.Diff(nil, record),
+			})
+		}
 
 		return e.JSON(200, map[string]string{"api_key": apiKey})
 	}
@@ -121,6 +167,7 @@ func generateAPIKeyForPrincipal(
 	service APIKeyGenerationService,
 	auth *core.Record,
 	name string,
+	grant apikey.Grant,
 ) (string, error) {
 	if auth == nil || strings.TrimSpace(auth.Id) == "" {
 		return "", apierror.New(
@@ -138,9 +185,9 @@ func generateAPIKeyForPrincipal(
 
 	switch collectionName {
 	case apiKeyUserCollection:
-		return service.GenerateApiKey(auth.Id, name)
+		return service.GenerateApiKeyWithGrant(auth.Id, name, grant)
 	case apiKeySuperuserCollection:
-		return service.GenerateInternalAdminAPIKey(auth.Id, name)
+		return service.GenerateInternalAdminAPIKeyWithGrant(auth.Id, name, grant)
 	default:
 		return "", apierror.New(
 			http.StatusForbidden,
@@ -186,6 +233,36 @@ func AuthenticateApiKey() func(e *core.RequestEvent) error {
 	}
 }
 
+func RotateApiKey() func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		apiKey := strings.TrimSpace(e.Request.Header.Get(APIKeyHeaderName))
+		service := NewApiKeyService(NewAppAdapter(e.App))
+		rotated, err := service.RotateApiKey(apiKey, e.RealIP())
+		if err != nil {
+			apiErr := &apierror.APIError{}
+			if errors.As(err, &apiErr) {
+				return apiErr
+			}
+			return err
+		}
+		if record := findFormattedAPIKeyRecord(e.App, rotated); record != nil {
+			// Rotation is authenticated by the key itself, not by the request.
+			recordAudit(e, audit.Entry{
+				Action:       audit.ActionAPIKeyRotated,
+				ResourceType: "api_keys",
+				ResourceID:   record.Id,
+				Actor: &audit.Actor{
+					Type:   audit.ActorAPIKey,
+					ID:     record.GetString("user"),
+					APIKey: record.Id,
+				},
+			})
+		}
+
+		return e.JSON(http.StatusOK, GenerateApiKeyResponse{ApiKey: rotated})
+	}
+}
+
 func AuthenticateInternalAdminAPIKey() func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		return e.JSON(http.StatusOK, AuthenticateInternalAdminAPIKeyResponse{
@@ -194,6 +271,20 @@ func AuthenticateInternalAdminAPIKey() func(e *core.RequestEvent) error {
 	}
 }
 
+// findFormattedAPIKeyRecord returns the api_keys record of a key issued
+// with apikey.Format, or nil.
+func findFormattedAPIKeyRecord(app core.App, key string) *core.Record {
+	keyID, _, ok := apikey.Parse(key)
+	if !ok {
+		return nil
+	}
+	record, err := app.FindFirstRecordByData("api_keys", apikey.FieldKeyID, keyID)
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
 type HasAuthToken interface {
 	NewAuthToken() (string, error)
 }
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// filterAPIKeyResources drops the records of resource that the request API
+// key is restricted away from, for routes listing several of them.
+func filterAPIKeyResources(
+	e *core.RequestEvent,
+	resource apikey.Resource,
+	records []*core.Record,
+) []*core.Record {
+	grant, ok := middlewares.APIKeyGrant(e)
+	if !ok {
+		return records
+	}
+	return slices.DeleteFunc(records, func(record *core.Record) bool {
+		return !grant.AllowsResource(resource, record.Id)
+	})
+}
+
+// filterAPIKeySchedules drops the schedules whose pipeline the request API
+// key is restricted away from. A key restricted to some pipelines does not
+// see the schedules without a schedules record.
+func filterAPIKeySchedules(
+	e *core.RequestEvent,
+	schedules []*ScheduleInfoSummary,
+) ([]*ScheduleInfoSummary, *apierror.APIError) {
+	grant, ok := middlewares.APIKeyGrant(e)
+	if !ok {
+		return schedules, nil
+	}
+	if _, restricted := grant.Resources[apikey.ResourcePipelines]; !restricted {
+		return schedules, nil
+	}
+	organization, err := pbutils.GetUserOrganization(e.App, e.Auth.Id)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"organization",
+			"failed to get user organization",
+			err.Error(),
+		)
+	}
+	records, err := e.App.FindRecordsByFilter(
+		"schedules",
+		"owner = {:owner}",
+		"",
+		-1,
+		0,
+		dbx.Params{"owner": organization.Id},
+	)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"schedule",
+			"failed to get schedules",
+			err.Error(),
+		)
+	}
+	pipelineBySchedule := make(map[string]string, len(records))
+	for _, record := range records {
+		pipelineBySchedule[record.GetString("temporal_schedule_id")] = record.GetString("pipeline")
+	}
+	return slices.DeleteFunc(schedules, func(schedule *ScheduleInfoSummary) bool {
+		pipelineID, ok := pipelineBySchedule[schedule.ID]
+		return !ok || !grant.AllowsResource(apikey.ResourcePipelines, pipelineID)
+	}), nil
+}
+
+// restrictedAPIKey reports whether the request API key is restricted to
+// some records, of any resource.
+func restrictedAPIKey(e *core.RequestEvent) bool {
+	grant, ok := middlewares.APIKeyGrant(e)
+	return ok && len(grant.Resources) > 0
+}
@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -46,6 +48,7 @@ type App interface {
 		limit, offset int,
 	) ([]*core.Record, error)
 	FindRecordById(collectionNameOrId, recordId string) (*core.Record, error)
+	FindFirstRecordByData(collectionNameOrId, key string, value any) (*core.Record, error)
 }
 
 type AppAdapter struct {
@@ -78,6 +81,14 @@ func (a *AppAdapter) FindRecordById(collectionNameOrId, recordId string) (*core.
 	return a.coreApp.FindRecordById(collectionNameOrId, recordId)
 }
 
+func (a *AppAdapter) FindFirstRecordByData(
+	collectionNameOrId,
+	key string,
+	value any,
+) (*core.Record, error) {
+	return a.coreApp.FindFirstRecordByData(collectionNameOrId, key, value)
+}
+
 type KeyGenerator interface {
 	GenerateKeyBytes() ([]byte, error)
 	EncodeKey(keyBytes []byte) string
@@ -189,11 +200,27 @@ func NewApiKeyServiceWithDependencies(
 }
 
 func (s *ApiKeyService) GenerateApiKey(userID, name string) (string, error) {
-	return s.generateScopedAPIKey(userID, "", name, ApiKeyScopeUser)
+	return s.GenerateApiKeyWithGrant(userID, name, apikey.Grant{})
 }
 
 func (s *ApiKeyService) GenerateInternalAdminAPIKey(superuserID, name string) (string, error) {
-	return s.generateScopedAPIKey("", superuserID, name, ApiKeyScopeInternalAdmin)
+	return s.GenerateInternalAdminAPIKeyWithGrant(superuserID, name, apikey.Grant{})
+}
+
+// GenerateApiKeyWithGrant generates a user key restricted by grant. The
+// zero grant generates an unrestricted key.
+func (s *ApiKeyService) GenerateApiKeyWithGrant(
+	userID, name string,
+	grant apikey.Grant,
+) (string, error) {
+	return s.generateScopedAPIKey(userID, "", name, ApiKeyScopeUser, grant)
+}
+
+func (s *ApiKeyService) GenerateInternalAdminAPIKeyWithGrant(
+	superuserID, name string,
+	grant apikey.Grant,
+) (string, error) {
+	return s.generateScopedAPIKey("", superuserID, name, ApiKeyScopeInternalAdmin, grant)
 }
 
 func (s *ApiKeyService) generateScopedAPIKey(
@@ -201,6 +228,7 @@ func (s *ApiKeyService) generateScopedAPIKey(
 	superuserID string,
 	name string,
 	scope ApiKeyScope,
+	grant apikey.Grant,
 ) (string, error) {
 	if name == "" {
 		return "", apierror.New(
@@ -235,6 +263,14 @@ func (s *ApiKeyService) generateScopedAPIKey(
 			err.Error(),
 		)
 	}
+	if err := grant.Validate(); err != nil {
+		return "", apierror.New(
+			http.StatusBadRequest,
+			"request.validation",
+			"invalid_api_key_grant",
+			err.Error(),
+		)
+	}
 
 	apiKeyBytes, err := s.keyGenerator.GenerateKeyBytes()
 	if err != nil {
@@ -246,8 +282,8 @@ func (s *ApiKeyService) generateScopedAPIKey(
 		)
 	}
 
-	apiKey := s.keyGenerator.EncodeKey(apiKeyBytes)
-	if apiKey == "" {
+	secret := s.keyGenerator.EncodeKey(apiKeyBytes)
+	if secret == "" {
 		return "", apierror.New(
 			http.StatusInternalServerError,
 			"request.internal_error",
@@ -256,7 +292,17 @@ func (s *ApiKeyService) generateScopedAPIKey(
 		)
 	}
 
-	hashedKey, err := s.keyHasher.HashKey(apiKey)
+	keyID, err := apikey.NewID()
+	if err != nil {
+		return "", apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_generate_api_key",
+			err.Error(),
+		)
+	}
+
+	hashedKey, err := s.keyHasher.HashKey(secret)
 	if err != nil {
 		return "", apierror.New(
 			http.StatusInternalServerError,
@@ -280,9 +326,11 @@ func (s *ApiKeyService) generateScopedAPIKey(
 	record.Set("user", userID)
 	record.Set("superuser", superuserID)
 	record.Set("key", hashedKey)
+	record.Set(apikey.FieldKeyID, keyID)
 	record.Set("name", name)
 	record.Set(apiKeyDefaultScopeFieldName, string(scope))
 	record.Set("revoked", false)
+	grant.Apply(record)
 
 	if err := s.app.Save(record); err != nil {
 		return "", apierror.New(
@@ -293,7 +341,7 @@ func (s *ApiKeyService) generateScopedAPIKey(
 		)
 	}
 
-	return apiKey, nil
+	return apikey.Format(keyID, secret), nil
 }
 
 func (s *ApiKeyService) AuthenticateApiKey(apiKey string) (*core.Record, error) {
@@ -329,43 +377,12 @@ func (s *ApiKeyService) authenticateByScope(
 		)
 	}
 
-	records, err := s.app.FindRecordsByFilter("api_keys", "", "", 0, 0)
-	if err != nil {
-		return nil, apierror.New(
-			http.StatusInternalServerError,
-			"request.internal_error",
-			"failed_to_find_api_key_records",
-			err.Error(),
-		)
-	}
-
-	matchedRecord, err := s.recordRepository.FindMatchingApiKeyRecord(records, apiKey, s.keyHasher)
+	matchedRecord, err := s.findAPIKeyRecord(apiKey)
 	if err != nil {
-		return nil, apierror.New(
-			http.StatusUnauthorized,
-			"request.validation",
-			"invalid_api_key",
-			"Invalid API key provided",
-		)
-	}
-
-	if matchedRecord.GetBool("revoked") {
-		return nil, apierror.New(
-			http.StatusUnauthorized,
-			"request.validation",
-			"revoked_api_key",
-			"API key is revoked",
-		)
+		return nil, err
 	}
-
-	expiresAt := matchedRecord.GetDateTime("expires_at")
-	if !expiresAt.IsZero() && expiresAt.Time().Before(time.Now().UTC()) {
-		return nil, apierror.New(
-			http.StatusUnauthorized,
-			"request.validation",
-			"expired_api_key",
-			"API key is expired",
-		)
+	if err := checkAPIKeyUsable(matchedRecord); err != nil {
+		return nil, err
 	}
 
 	userID := matchedRecord.GetString("user")
@@ -444,9 +461,163 @@ func (s *ApiKeyService) authenticateByScope(
 		)
 	}
 
+	apikey.TouchLastUsed(s.app, matchedRecord)
 	return authRecord, nil
 }
 
+// RotateApiKey replaces the secret of a usable key of any scope and
+// returns the new key. Legacy keys are given an identifier, so rotating is
+// how they move to the prefixed format. The old key stops working. The key
+// must be allowed from clientIP and hold the apikeys:write permission.
+func (s *ApiKeyService) RotateApiKey(apiKey string, clientIP string) (string, error) {
+	if apiKey == "" {
+		return "", apierror.New(
+			http.StatusUnauthorized,
+			"request.validation",
+			"api_key_required",
+			"API key is required for rotation",
+		)
+	}
+
+	record, err := s.findAPIKeyRecord(apiKey)
+	if err != nil {
+		return "", err
+	}
+	if err := checkAPIKeyUsable(record); err != nil {
+		return "", err
+	}
+	if err := checkAPIKeyGrant(record, clientIP, apikey.PermissionAPIKeysWrite); err != nil {
+		return "", err
+	}
+
+	apiKeyBytes, err := s.keyGenerator.GenerateKeyBytes()
+	if err != nil {
+		return "", apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_generate_api_key",
+			err.Error(),
+		)
+	}
+	secret := s.keyGenerator.EncodeKey(apiKeyBytes)
+	if secret == "" {
+		return "", apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_encode_api_key",
+			"failed to encode API key",
+		)
+	}
+	hashedKey, err := s.keyHasher.HashKey(secret)
+	if err != nil {
+		return "", apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_hash_api_key",
+			err.Error(),
+		)
+	}
+
+	keyID := record.GetString(apikey.FieldKeyID)
+	if keyID == "" {
+		if keyID, err = apikey.NewID(); err != nil {
+			return "", apierror.New(
+				http.StatusInternalServerError,
+				"request.internal_error",
+				"failed_to_generate_api_key",
+				err.Error(),
+			)
+		}
+		record.Set(apikey.FieldKeyID, keyID)
+	}
+	record.Set(apikey.FieldLegacyFingerprint, "")
+	record.Set("key", hashedKey)
+	record.Set(apikey.FieldLastUsedAt, types.NowDateTime())
+
+	if err := s.app.Save(record); err != nil {
+		return "", apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"failed_to_update_api_key_record",
+			err.Error(),
+		)
+	}
+
+	return apikey.Format(keyID, secret), nil
+}
+
+// findAPIKeyRecord looks the key up with the service's hasher and
+// record repository.
+func (s *ApiKeyService) findAPIKeyRecord(apiKey string) (*core.Record, error) {
+	record, apiErr := apikey.Find(
+		s.app,
+		apiKey,
+		func(hash, key string) error {
+			return s.keyHasher.CompareHashAndKey(hash, key)
+		},
+		func(records []*core.Record, key string) (*core.Record, error) {
+			return s.recordRepository.FindMatchingApiKeyRecord(records, key, s.keyHasher)
+		},
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return record, nil
+}
+
+func checkAPIKeyUsable(record *core.Record) error {
+	if record.GetBool("revoked") {
+		return apierror.New(
+			http.StatusUnauthorized,
+			"request.validation",
+			"revoked_api_key",
+			"API key is revoked",
+		)
+	}
+
+	expiresAt := record.GetDateTime("expires_at")
+	if !expiresAt.IsZero() && expiresAt.Time().Before(time.Now().UTC()) {
+		return apierror.New(
+			http.StatusUnauthorized,
+			"request.validation",
+			"expired_api_key",
+			"API key is expired",
+		)
+	}
+	return nil
+}
+
+// checkAPIKeyGrant checks that the grant of record allows a request from
+// clientIP needing permission.
+func checkAPIKeyGrant(record *core.Record, clientIP string, permission apikey.Permission) error {
+	grant, err := apikey.GrantFromRecord(record)
+	if err != nil {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"invalid_api_key_grant",
+			err.Error(),
+		)
+	}
+	if !grant.AllowsIP(clientIP) {
+		return apierror.New(
+			http.StatusForbidden,
+			"request.validation",
+			"api_key_ip_not_allowed",
+			"API key is not allowed from this address",
+		)
+	}
+	if !grant.AllowsPermission(permission) {
+		return apierror.New(
+			http.StatusForbidden,
+			"request.validation",
+			"insufficient_api_key_permission",
+			"API key does not have the "+string(permission)+" permission",
+		)
+	}
+	return nil
+}
+
 func validateAPIKeyOwners(userID, superuserID string, scope ApiKeyScope) error {
 	hasUser := userID != ""
 	hasSuperuser := superuserID != ""
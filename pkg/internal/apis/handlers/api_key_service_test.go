@@ -13,7 +13,9 @@ import (
 	"testing"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
@@ -60,6 +62,25 @@ func (m *MockApp) FindRecordById(collectionNameOrId, recordId string) (*core.Rec
 	return args.Get(0).(*core.Record), args.Error(1)
 }
 
+func (m *MockApp) FindFirstRecordByData(
+	collectionNameOrId,
+	key string,
+	value any,
+) (*core.Record, error) {
+	args := m.Called(collectionNameOrId, key, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*core.Record), args.Error(1)
+}
+
+// expectUnfingerprintedLegacyKey makes the lookup of a legacy key fall back
+// to matching the legacy records.
+func expectUnfingerprintedLegacyKey(mockApp *MockApp) {
+	mockApp.On("FindFirstRecordByData", "api_keys", apikey.FieldLegacyFingerprint, mock.Anything).
+		Return(nil, errors.New("no rows"))
+}
+
 type MockKeyGenerator struct {
 	mock.Mock
 }
@@ -355,7 +376,12 @@ func TestApiKeyService_GenerateApiKey_Success(t *testing.T) {
 
 	// Verify
 	assert.NoError(t, err)
-	assert.Equal(t, "encoded-api-key", apiKey)
+	keyID, secret, ok := apikey.Parse(apiKey)
+	assert.True(t, ok)
+	assert.Equal(t, "encoded-api-key", secret)
+	savedRecord := mockApp.Calls[1].Arguments.Get(0).(*core.Record)
+	assert.Equal(t, keyID, savedRecord.GetString(apikey.FieldKeyID))
+	assert.Equal(t, "hashed-key", savedRecord.GetString("key"))
 	mockApp.AssertExpectations(t)
 	mockKeyGen.AssertExpectations(t)
 	mockHasher.AssertExpectations(t)
@@ -531,9 +557,12 @@ func TestApiKeyService_AuthenticateApiKey_Success(t *testing.T) {
 	records := []*core.Record{apiKeyRecord}
 
 	// Setup mocks
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, apiKey, mockHasher).Return(apiKeyRecord, nil)
 	mockApp.On("FindRecordById", "users", "test-user").Return(userRecord, nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
 
 	// Execute
 	result, err := service.AuthenticateApiKey(apiKey)
@@ -542,10 +571,168 @@ func TestApiKeyService_AuthenticateApiKey_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, "test-user", result.GetString("id"))
+	assert.False(t, apiKeyRecord.GetDateTime(apikey.FieldLastUsedAt).IsZero())
 	mockApp.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestApiKeyService_AuthenticateApiKey_PrefixedKeyUsesIndexedLookup(t *testing.T) {
+	t.Parallel()
+
+	mockApp := new(MockApp)
+	mockHasher := new(MockKeyHasher)
+	mockRepo := new(MockRecordRepository)
+
+	service := NewApiKeyServiceWithDependencies(
+		mockApp,
+		new(MockKeyGenerator),
+		mockHasher,
+		mockRepo,
+	)
+
+	apiKeyRecord := createTestRecord(createTestCollection())
+	apiKeyRecord.Set(apikey.FieldKeyID, "abcdefghijkl")
+	apiKeyRecord.Set(apikey.FieldLastUsedAt, types.NowDateTime())
+
+	mockApp.On("FindFirstRecordByData", "api_keys", apikey.FieldKeyID, "abcdefghijkl").
+		Return(apiKeyRecord, nil)
+	mockHasher.On("CompareHashAndKey", "test-hash", "secret").Return(nil)
+	mockApp.On("FindRecordById", "users", "test-user").Return(createTestUserRecord(), nil)
+
+	// last_used_at was just written, so the record is not saved again
+	result, err := service.AuthenticateApiKey(apikey.Format("abcdefghijkl", "secret"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user", result.GetString("id"))
+	mockApp.AssertExpectations(t)
+	mockApp.AssertNotCalled(t, "FindRecordsByFilter")
+	mockApp.AssertNotCalled(t, "Save", mock.Anything)
+	mockRepo.AssertNotCalled(t, "FindMatchingApiKeyRecord")
+}
+
+func TestApiKeyService_AuthenticateApiKey_PrefixedKeyRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		key   string
+		setup func(app *MockApp, hasher *MockKeyHasher)
+	}{
+		{
+			name:  "malformed prefix",
+			key:   "cred_not-an-id",
+			setup: func(*MockApp, *MockKeyHasher) {},
+		},
+		{
+			name: "unknown id",
+			key:  apikey.Format("abcdefghijkl", "secret"),
+			setup: func(app *MockApp, _ *MockKeyHasher) {
+				app.On("FindFirstRecordByData", "api_keys", apikey.FieldKeyID, "abcdefghijkl").
+					Return(nil, errors.New("sql: no rows in result set"))
+			},
+		},
+		{
+			name: "wrong secret",
+			key:  apikey.Format("abcdefghijkl", "wrong"),
+			setup: func(app *MockApp, hasher *MockKeyHasher) {
+				record := createTestRecord(createTestCollection())
+				app.On("FindFirstRecordByData", "api_keys", apikey.FieldKeyID, "abcdefghijkl").
+					Return(record, nil)
+				hasher.On("CompareHashAndKey", "test-hash", "wrong").
+					Return(bcrypt.ErrMismatchedHashAndPassword)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApp := new(MockApp)
+			mockHasher := new(MockKeyHasher)
+			tt.setup(mockApp, mockHasher)
+			service := NewApiKeyServiceWithDependencies(
+				mockApp,
+				new(MockKeyGenerator),
+				mockHasher,
+				new(MockRecordRepository),
+			)
+
+			_, err := service.AuthenticateApiKey(tt.key)
+
+			var apiErr *apierror.APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, http.StatusUnauthorized, apiErr.Code)
+			assert.Equal(t, "invalid_api_key", apiErr.Reason)
+			mockApp.AssertNotCalled(t, "FindRecordsByFilter")
+		})
+	}
+}
+
+func TestApiKeyService_RotateApiKey_MigratesLegacyKey(t *testing.T) {
+	t.Parallel()
+
+	mockApp := new(MockApp)
+	mockKeyGen := new(MockKeyGenerator)
+	mockHasher := new(MockKeyHasher)
+	mockRepo := new(MockRecordRepository)
+
+	service := NewApiKeyServiceWithDependencies(mockApp, mockKeyGen, mockHasher, mockRepo)
+
+	apiKeyRecord := createTestRecord(createTestCollection())
+	records := []*core.Record{apiKeyRecord}
+	keyBytes := []byte("new-key-bytes")
+
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
+	mockRepo.On("FindMatchingApiKeyRecord", records, "legacy-key", mockHasher).
+		Return(apiKeyRecord, nil)
+	mockKeyGen.On("GenerateKeyBytes").Return(keyBytes, nil)
+	mockKeyGen.On("EncodeKey", keyBytes).Return("new-secret")
+	mockHasher.On("HashKey", "new-secret").Return("new-hash", nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
+
+	rotated, err := service.RotateApiKey("legacy-key", "192.0.2.1")
+
+	assert.NoError(t, err)
+	keyID, secret, ok := apikey.Parse(rotated)
+	assert.True(t, ok)
+	assert.Equal(t, "new-secret", secret)
+	assert.Equal(t, keyID, apiKeyRecord.GetString(apikey.FieldKeyID))
+	assert.Equal(t, "new-hash", apiKeyRecord.GetString("key"))
+	assert.Empty(t, apiKeyRecord.GetString(apikey.FieldLegacyFingerprint))
+	mockApp.AssertExpectations(t)
+}
+
+func TestApiKeyService_RotateApiKey_RejectsRevokedKey(t *testing.T) {
+	t.Parallel()
+
+	mockApp := new(MockApp)
+	mockKeyGen := new(MockKeyGenerator)
+	mockHasher := new(MockKeyHasher)
+
+	service := NewApiKeyServiceWithDependencies(
+		mockApp,
+		mockKeyGen,
+		mockHasher,
+		new(MockRecordRepository),
+	)
+
+	apiKeyRecord := createTestRecord(createTestCollection())
+	apiKeyRecord.Set(apikey.FieldKeyID, "abcdefghijkl")
+	apiKeyRecord.Set("revoked", true)
+	mockApp.On("FindFirstRecordByData", "api_keys", apikey.FieldKeyID, "abcdefghijkl").
+		Return(apiKeyRecord, nil)
+	mockHasher.On("CompareHashAndKey", "test-hash", "secret").Return(nil)
+
+	_, err := service.RotateApiKey(apikey.Format("abcdefghijkl", "secret"), "192.0.2.1")
+
+	var apiErr *apierror.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "revoked_api_key", apiErr.Reason)
+	mockKeyGen.AssertNotCalled(t, "GenerateKeyBytes")
+	mockApp.AssertNotCalled(t, "Save", mock.Anything)
+}
+
 func TestApiKeyService_AuthenticateApiKey_EmptyApiKey(t *testing.T) {
 	t.Parallel()
 
@@ -566,7 +753,8 @@ func TestApiKeyService_AuthenticateApiKey_FindRecordsFails(t *testing.T) {
 	mockApp := new(MockApp)
 	service := NewApiKeyService(mockApp)
 
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
 		Return(nil, errors.New("database error"))
 
 	_, err := service.AuthenticateApiKey("test-api-key")
@@ -586,7 +774,9 @@ func TestApiKeyService_AuthenticateApiKey_NoMatchingRecord(t *testing.T) {
 	service := NewApiKeyServiceWithDependencies(mockApp, nil, nil, mockRepo)
 
 	records := []*core.Record{}
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, "test-api-key", mock.Anything).
 		Return(nil, errors.New("no matching record"))
 
@@ -613,9 +803,12 @@ func TestApiKeyService_AuthenticateApiKey_EmptyUserId(t *testing.T) {
 
 	records := []*core.Record{apiKeyRecord}
 
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, "test-api-key", mock.Anything).
 		Return(apiKeyRecord, nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
 
 	_, err := service.AuthenticateApiKey("test-api-key")
 
@@ -640,9 +833,12 @@ func TestApiKeyService_AuthenticateApiKey_UserNotFound(t *testing.T) {
 
 	records := []*core.Record{apiKeyRecord}
 
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, "test-api-key", mock.Anything).
 		Return(apiKeyRecord, nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
 	mockApp.On("FindRecordById", "users", "test-user").Return(nil, errors.New("user not found"))
 
 	_, err := service.AuthenticateApiKey("test-api-key")
@@ -668,9 +864,12 @@ func TestApiKeyService_AuthenticateApiKey_UserRecordNil(t *testing.T) {
 
 	records := []*core.Record{apiKeyRecord}
 
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, "test-api-key", mock.Anything).
 		Return(apiKeyRecord, nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
 	mockApp.On("FindRecordById", "users", "test-user").Return(nil, nil)
 
 	_, err := service.AuthenticateApiKey("test-api-key")
@@ -697,9 +896,12 @@ func TestApiKeyService_AuthenticateInternalAdminAPIKey_RejectsBlankScopeUserKey(
 
 	records := []*core.Record{apiKeyRecord}
 
-	mockApp.On("FindRecordsByFilter", "api_keys", "", "", 0, 0).Return(records, nil)
+	expectUnfingerprintedLegacyKey(mockApp)
+	mockApp.On("FindRecordsByFilter", "api_keys", apikey.LegacyFilter, "", 0, 0).
+		Return(records, nil)
 	mockRepo.On("FindMatchingApiKeyRecord", records, "test-api-key", mock.Anything).
 		Return(apiKeyRecord, nil)
+	mockApp.On("Save", apiKeyRecord).Return(nil)
 
 	_, err := service.AuthenticateInternalAdminAPIKey("test-api-key")
 
@@ -6,6 +6,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +16,7 @@ import (
 	"unsafe"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
@@ -47,24 +49,31 @@ type apiKeyGenerationServiceStub struct {
 	lastUserID           string
 	lastSuperuserID      string
 	lastGeneratedKeyName string
+	lastGrant            apikey.Grant
 }
 
-func (s *apiKeyGenerationServiceStub) GenerateApiKey(userID, name string) (string, error) {
+func (s *apiKeyGenerationServiceStub) GenerateApiKeyWithGrant(
+	userID, name string,
+	grant apikey.Grant,
+) (string, error) {
 	s.userCalls++
 	s.lastUserID = userID
 	s.lastGeneratedKeyName = name
+	s.lastGrant = grant
 	if s.userErr != nil {
 		return "", s.userErr
 	}
 	return s.userKey, nil
 }
 
-func (s *apiKeyGenerationServiceStub) GenerateInternalAdminAPIKey(
+func (s *apiKeyGenerationServiceStub) GenerateInternalAdminAPIKeyWithGrant(
 	superuserID, name string,
+	grant apikey.Grant,
 ) (string, error) {
 	s.internalAdminCalls++
 	s.lastSuperuserID = superuserID
 	s.lastGeneratedKeyName = name
+	s.lastGrant = grant
 	if s.internalAdminErr != nil {
 		return "", s.internalAdminErr
 	}
@@ -114,9 +123,11 @@ func TestGenerateAPIKeyForPrincipalUser(t *testing.T) {
 	auth := core.NewRecord(core.NewAuthCollection("users"))
 	auth.Id = "user-1"
 
-	key, err := generateAPIKeyForPrincipal(stub, auth, "key-name")
+	grant := apikey.Grant{Permissions: []apikey.Permission{apikey.PermissionPipelinesRun}}
+	key, err := generateAPIKeyForPrincipal(stub, auth, "key-name", grant)
 	require.NoError(t, err)
 	require.Equal(t, "user-key", key)
+	require.Equal(t, grant, stub.lastGrant)
 	require.Equal(t, 1, stub.userCalls)
 	require.Equal(t, 0, stub.internalAdminCalls)
 	require.Equal(t, "user-1", stub.lastUserID)
@@ -130,7 +141,7 @@ func TestGenerateAPIKeyForPrincipalSuperuser(t *testing.T) {
 	auth := core.NewRecord(core.NewAuthCollection("_superusers"))
 	auth.Id = "superuser-1"
 
-	key, err := generateAPIKeyForPrincipal(stub, auth, "key-name")
+	key, err := generateAPIKeyForPrincipal(stub, auth, "key-name", apikey.Grant{})
 	require.NoError(t, err)
 	require.Equal(t, "internal-key", key)
 	require.Equal(t, 0, stub.userCalls)
@@ -146,7 +157,7 @@ func TestGenerateAPIKeyForPrincipalUnsupportedCollection(t *testing.T) {
 	auth := core.NewRecord(core.NewAuthCollection("admins"))
 	auth.Id = "admin-1"
 
-	_, err := generateAPIKeyForPrincipal(stub, auth, "key-name")
+	_, err := generateAPIKeyForPrincipal(stub, auth, "key-name", apikey.Grant{})
 	require.Error(t, err)
 
 	var apiErr *apierror.APIError
@@ -253,6 +264,158 @@ func TestAuthenticateInternalAdminAPIKey(t *testing.T) {
 	})
 }
 
+func TestRotateApiKey(t *testing.T) {
+	app, err := tests.NewTestApp(apiKeyHandlerTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "legacy-rotate-key",
+		UserID:    user.Id,
+		Scope:     "user",
+	})
+
+	rotate := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/apikey/rotate", nil)
+		req.Header.Set("Credimi-Api-Key", key)
+		rec := httptest.NewRecorder()
+		e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+		requireHandlerErrorHandled(t, rec, RotateApiKey()(e))
+		return rec
+	}
+
+	rec := rotate("legacy-rotate-key")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response GenerateApiKeyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	keyID, _, ok := apikey.Parse(response.ApiKey)
+	require.True(t, ok)
+
+	record, err := app.FindFirstRecordByData("api_keys", apikey.FieldKeyID, keyID)
+	require.NoError(t, err)
+	require.Equal(t, user.Id, record.GetString("user"))
+
+	service := NewApiKeyService(NewAppAdapter(app))
+	principal, err := service.AuthenticateUserAPIKey(response.ApiKey)
+	require.NoError(t, err)
+	require.Equal(t, user.Id, principal.Id)
+
+	rec = rotate("legacy-rotate-key")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid_api_key")
+
+	rec = rotate(response.ApiKey)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var second GenerateApiKeyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &second))
+	secondID, _, ok := apikey.Parse(second.ApiKey)
+	require.True(t, ok)
+	require.Equal(t, keyID, secondID)
+	require.NotEqual(t, response.ApiKey, second.ApiKey)
+}
+
+func TestRotateApiKeyChecksTheGrant(t *testing.T) {
+	app, err := tests.NewTestApp(apiKeyHandlerTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "ip-restricted-rotate-key",
+		UserID:    user.Id,
+		Scope:     "user",
+		Grant:     apikey.Grant{IPRanges: []string{"192.0.2.0/24"}},
+	})
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "read-only-rotate-key",
+		UserID:    user.Id,
+		Scope:     "user",
+		Grant: apikey.Grant{
+			Permissions: []apikey.Permission{apikey.PermissionPipelinesRead},
+		},
+	})
+
+	rotate := func(key string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/apikey/rotate", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Credimi-Api-Key", key)
+		rec := httptest.NewRecorder()
+		e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+		requireHandlerErrorHandled(t, rec, RotateApiKey()(e))
+		return rec
+	}
+
+	rec := rotate("ip-restricted-rotate-key", "198.51.100.7:1234")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "api_key_ip_not_allowed")
+
+	rec = rotate("read-only-rotate-key", "192.0.2.7:1234")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "insufficient_api_key_permission")
+
+	rec = rotate("ip-restricted-rotate-key", "192.0.2.7:1234")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestGenerateApiKeyRejectsGrantBeyondCaller(t *testing.T) {
+	app, err := tests.NewTestApp(apiKeyHandlerTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "restricted-generate-key",
+		UserID:    user.Id,
+		Scope:     "user",
+		Grant: apikey.Grant{
+			Permissions: []apikey.Permission{
+				apikey.PermissionAPIKeysWrite,
+				apikey.PermissionPipelinesRead,
+			},
+		},
+	})
+
+	generate := func(input GenerateApiKeyRequest) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/apikey/generate", nil)
+		req = req.WithContext(
+			context.WithValue(req.Context(), middlewares.ValidatedInputKey, input),
+		)
+		req.Header.Set("Credimi-Api-Key", "restricted-generate-key")
+		rec := httptest.NewRecorder()
+		e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+		setHandlerNext(e, func() error { return nil })
+		permission := middlewares.RequireAPIKeyPermission(apikey.PermissionAPIKeysWrite)
+		require.NoError(t, permission.Func(e))
+		require.NoError(t, middlewares.RequireAuthOrAPIKey().Func(e))
+		requireHandlerErrorHandled(t, rec, GenerateApiKey()(e))
+		return rec
+	}
+
+	rec := generate(GenerateApiKeyRequest{Name: "unrestricted"})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "api_key_grant_exceeds_caller")
+
+	rec = generate(GenerateApiKeyRequest{
+		Name:        "read-only",
+		Permissions: []apikey.Permission{apikey.PermissionPipelinesRead},
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response GenerateApiKeyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	keyID, _, ok := apikey.Parse(response.ApiKey)
+	require.True(t, ok)
+
+	record, err := app.FindFirstRecordByData("api_keys", apikey.FieldKeyID, keyID)
+	require.NoError(t, err)
+	grant, err := apikey.GrantFromRecord(record)
+	require.NoError(t, err)
+	require.Equal(t, []apikey.Permission{apikey.PermissionPipelinesRead}, grant.Permissions)
+}
+
 func setHandlerNext(e *core.RequestEvent, fn func() error) {
 	eventField := reflect.ValueOf(e).Elem().FieldByName("Event")
 	hookEvent := eventField.FieldByName("Event")
@@ -268,6 +431,7 @@ type handlerAPIKeySeed struct {
 	Scope       string
 	Revoked     bool
 	ExpiresAt   *time.Time
+	Grant       apikey.Grant
 }
 
 func seedHandlerAPIKey(t testing.TB, app *tests.TestApp, seed handlerAPIKeySeed) {
@@ -285,8 +449,33 @@ func seedHandlerAPIKey(t testing.TB, app *tests.TestApp, seed handlerAPIKeySeed)
 	record.Set("superuser", seed.SuperuserID)
 	record.Set("key_type", seed.Scope)
 	record.Set("revoked", seed.Revoked)
+	seed.Grant.Apply(record)
 	if seed.ExpiresAt != nil {
 		record.Set("expires_at", seed.ExpiresAt.UTC().Format("2006-01-02 15:04:05.000Z"))
 	}
 	require.NoError(t, app.Save(record))
 }
+
+// restrictedAPIKeyEvent authenticates req with a new API key of userID
+// holding grant.
+func restrictedAPIKeyEvent(
+	t testing.TB,
+	app *tests.TestApp,
+	userID string,
+	grant apikey.Grant,
+	req *http.Request,
+) (*core.RequestEvent, *httptest.ResponseRecorder) {
+	t.Helper()
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "restricted-list-key",
+		UserID:    userID,
+		Scope:     "user",
+		Grant:     grant,
+	})
+	req.Header.Set("Credimi-Api-Key", "restricted-list-key")
+	rec := httptest.NewRecorder()
+	e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+	setHandlerNext(e, func() error { return nil })
+	require.NoError(t, middlewares.RequireAuthOrAPIKey().Func(e))
+	return e, rec
+}
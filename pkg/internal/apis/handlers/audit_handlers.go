@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 500
+	// auditLogExportLimit bounds an export; narrow the filters to export
+	// older entries.
+	auditLogExportLimit = 10000
+
+	auditExportJSON = "json"
+	auditExportCSV  = "csv"
+)
+
+type AuditLogResponse struct {
+	Items []audit.Log `json:"items"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+	Total int         `json:"total"`
+}
+
+func HandleListMyAuditLog() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID, apiErr := auditLogOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		filter, apiErr := parseAuditLogFilter(e)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		limit, page := parsePageParams(e, auditLogDefaultLimit, 0)
+		limit = min(limit, auditLogMaxLimit)
+		logs, total, err := audit.Find(e.App, orgID, filter, limit, page*limit)
+		if err != nil {
+			return auditLogReadError(err)
+		}
+
+		return e.JSON(http.StatusOK, AuditLogResponse{
+			Items: logs,
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		})
+	}
+}
+
+func HandleExportMyAuditLog() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID, apiErr := auditLogOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		exportFormat := queryOrDefault(e.Request.URL.Query().Get("format"), auditExportJSON)
+		if exportFormat != auditExportJSON && exportFormat != auditExportCSV {
+			return apierror.New(
+				http.StatusBadRequest,
+				"format",
+				"invalid format",
+				"format must be json or csv",
+			)
+		}
+		filter, apiErr := parseAuditLogFilter(e)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		logs, _, err := audit.Find(e.App, orgID, filter, auditLogExportLimit, 0)
+		if err != nil {
+			return auditLogReadError(err)
+		}
+
+		filename := "audit-log." + exportFormat
+		e.Response.Header().Set(
+			"Content-Disposition",
+			`attachment; filename="`+filename+`"`,
+		)
+		if exportFormat == auditExportCSV {
+			var body bytes.Buffer
+			if err := audit.WriteCSV(&body, logs); err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"audit",
+					"failed_to_encode_audit_log",
+					err.Error(),
+				)
+			}
+			return e.Blob(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+		}
+		return e.JSON(http.StatusOK, logs)
+	}
+}
+
+// auditLogOrganization returns the organization of the caller when their
+// role lets them read the audit log.
+func auditLogOrganization(e *core.RequestEvent) (string, *apierror.APIError) {
+	membership, err := orgrole.Find(e.App, e.Auth.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", auditLogReadError(err)
+	}
+	if err != nil || !orgrole.Allows(membership.Role, apikey.PermissionAuditRead) {
+		return "", apierror.New(
+			http.StatusForbidden,
+			"audit",
+			"organization_admin_required",
+			"only organization admins can read the audit log",
+		)
+	}
+	return membership.Organization, nil
+}
+
+func parseAuditLogFilter(e *core.RequestEvent) (audit.Filter, *apierror.APIError) {
+	query := e.Request.URL.Query()
+	filter := audit.Filter{
+		Action:       strings.TrimSpace(query.Get("action")),
+		ActorID:      strings.TrimSpace(query.Get("actor")),
+		ResourceType: strings.TrimSpace(query.Get("resource_type")),
+		ResourceID:   strings.TrimSpace(query.Get("resource_id")),
+	}
+	for name, target := range map[string]*time.Time{
+		"since": &filter.Since,
+		"until": &filter.Until,
+	} {
+		value := strings.TrimSpace(query.Get(name))
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return audit.Filter{}, apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_"+name,
+				name+" must be an RFC 3339 timestamp",
+			)
+		}
+		*target = parsed
+	}
+	return filter, nil
+}
+
+func auditLogReadError(err error) *apierror.APIError {
+	return apierror.New(
+		http.StatusInternalServerError,
+		"audit",
+		"failed_to_read_audit_log",
+		err.Error(),
+	)
+}
+
+// recordAudit appends entry to the audit log of the organization. The
+// audited action already happened, so failures are only logged.
+func recordAudit(e *core.RequestEvent, entry audit.Entry) {
+	if err := audit.Record(e, entry); err != nil {
+		log.Printf(
+			"failed to record audit entry for %s %s: %v",
+			entry.Action,
+			entry.ResourceID,
+			err,
+		)
+	}
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogHandlers(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+
+	seedAuditLog := func(t testing.TB, app *tests.TestApp) {
+		collection, err := app.FindCollectionByNameOrId(audit.Collection)
+		require.NoError(t, err)
+		for _, entry := range []struct{ action, resource string }{
+			{audit.ActionPipelinePublished, "pipelines"},
+			{audit.ActionAPIKeyRevoked, "api_keys"},
+		} {
+			record := core.NewRecord(collection)
+			record.Set("owner", orgID)
+			record.Set("action", entry.action)
+			record.Set("actor_type", audit.ActorUser)
+			record.Set("actor_id", userRecord.Id)
+			record.Set("resource_type", entry.resource)
+			record.Set("resource_id", "r1")
+			require.NoError(t, app.Save(record))
+		}
+	}
+	auditApp := func(t testing.TB) *tests.TestApp {
+		app := setupOrganizationApp(t)
+		seedAuditLog(t, app)
+		return app
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "list the audit log filtered by action prefix",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/audit-log?action=pipeline.",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{audit.ActionPipelinePublished},
+			TestAppFactory:  auditApp,
+			AfterTestFunc: func(t testing.TB, _ *tests.TestApp, res *http.Response) {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				var payload AuditLogResponse
+				require.NoError(t, json.Unmarshal(body, &payload))
+				require.Equal(t, 1, payload.Total)
+				require.Equal(t, auditLogDefaultLimit, payload.Limit)
+				require.Len(t, payload.Items, 1)
+				require.Equal(t, orgID, payload.Items[0].Organization)
+			},
+		},
+		{
+			Name:           "export the audit log as CSV",
+			Method:         http.MethodGet,
+			URL:            "/api/organizations/my/audit-log/export?format=csv",
+			Headers:        headers,
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				"created,action,actor_type",
+				audit.ActionPipelinePublished,
+				audit.ActionAPIKeyRevoked,
+			},
+			TestAppFactory: auditApp,
+		},
+		{
+			Name:            "reject an invalid since",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/audit-log?since=yesterday",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_since"},
+			TestAppFactory:  auditApp,
+		},
+		{
+			Name:            "reject an invalid export format",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/audit-log/export?format=xml",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"format must be json or csv"},
+			TestAppFactory:  auditApp,
+		},
+		{
+			Name:            "reject members that are not admins",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/audit-log",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusForbidden,
+			ExpectedContent: []string{"organization_role_not_allowed"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := auditApp(t)
+				member, err := app.FindFirstRecordByData("orgRoles", "name", "member")
+				require.NoError(t, err)
+				authorization, err := app.FindFirstRecordByData(
+					"orgAuthorizations",
+					"user",
+					userRecord.Id,
+				)
+				require.NoError(t, err)
+				authorization.Set("role", member.Id)
+				require.NoError(t, app.Save(authorization))
+				return app
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// authorizePipelineAccess checks that the caller may use pipeline with
+// permission.
+func authorizePipelineAccess(
+	e *core.RequestEvent,
+	pipeline *core.Record,
+	permission apikey.Permission,
+) *apierror.APIError {
+	return authorizeOrganizationRecord(e, pipeline, apikey.ResourcePipelines, permission)
+}
+
+// authorizeWalletAccess checks that the caller may use wallet with
+// permission.
+func authorizeWalletAccess(
+	e *core.RequestEvent,
+	wallet *core.Record,
+	permission apikey.Permission,
+) *apierror.APIError {
+	return authorizeOrganizationRecord(e, wallet, apikey.ResourceWallets, permission)
+}
+
+// authorizeRunnerAccess checks that the caller may use runner with
+// permission.
+func authorizeRunnerAccess(
+	e *core.RequestEvent,
+	runner *core.Record,
+	permission apikey.Permission,
+) *apierror.APIError {
+	return authorizeOrganizationRecord(e, runner, apikey.ResourceRunners, permission)
+}
+
+// authorizeScheduleAccess checks that the caller may act on the Temporal
+// schedule scheduleID with permission. Schedules live in the namespace of
+// their organization; the schedules record, when there is one, ties them to
+// the pipeline an API key may be restricted to. A key restricted to some
+// pipelines may not act on a schedule without one.
+func authorizeScheduleAccess(
+	e *core.RequestEvent,
+	scheduleID string,
+	permission apikey.Permission,
+) *apierror.APIError {
+	if isInternalAdminPrincipal(e.Auth) {
+		return nil
+	}
+	membership, apiErr := callerMembership(e)
+	if apiErr != nil {
+		return apiErr
+	}
+	schedule, err := e.App.FindFirstRecordByFilter(
+		"schedules",
+		"temporal_schedule_id = {:sid} && owner = {:owner}",
+		dbx.Params{"sid": scheduleID, "owner": membership.Organization},
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if grant, ok := middlewares.APIKeyGrant(e); ok {
+			if _, restricted := grant.Resources[apikey.ResourcePipelines]; restricted {
+				return apierror.New(
+					http.StatusForbidden,
+					"request.validation",
+					"api_key_resource_not_allowed",
+					"API key is not allowed to access schedule "+scheduleID,
+				)
+			}
+		}
+	case err != nil:
+		return apierror.New(
+			http.StatusInternalServerError,
+			"schedule",
+			"failed to get schedule",
+			err.Error(),
+		)
+	default:
+		if apiErr := middlewares.RequireAPIKeyResource(
+			e,
+			apikey.ResourcePipelines,
+			schedule.GetString("pipeline"),
+		); apiErr != nil {
+			return apiErr
+		}
+	}
+	return middlewares.OrganizationRoleError(membership.Role, permission)
+}
+
+// authorizeOrganizationRecord checks that the request API key is not
+// restricted to other resources, and that record belongs to the caller
+// organization, or is published and permission only reads or runs it.
+func authorizeOrganizationRecord(
+	e *core.RequestEvent,
+	record *core.Record,
+	resource apikey.Resource,
+	permission apikey.Permission,
+) *apierror.APIError {
+	if isInternalAdminPrincipal(e.Auth) {
+		return nil
+	}
+	if apiErr := middlewares.RequireAPIKeyResource(e, resource, record.Id); apiErr != nil {
+		return apiErr
+	}
+	shared := record.GetBool("published") && usableWhenPublished(permission)
+	return authorizeOwnerAccess(e, record.GetString("owner"), shared, permission)
+}
+
+// authorizeOwnerAccess checks that ownerID is the caller organization,
+// unless shared, and that the caller role grants permission.
+func authorizeOwnerAccess(
+	e *core.RequestEvent,
+	ownerID string,
+	shared bool,
+	permission apikey.Permission,
+) *apierror.APIError {
+	if isInternalAdminPrincipal(e.Auth) {
+		return nil
+	}
+	if ownerID == "" {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"owner",
+			"owner missing",
+			"record owner is required",
+		)
+	}
+
+	membership, apiErr := callerMembership(e)
+	if apiErr != nil {
+		return apiErr
+	}
+	if membership.Organization != ownerID && !shared {
+		return apierror.New(
+			http.StatusForbidden,
+			"authorization",
+			"forbidden",
+			"record does not belong to the authenticated user's organization",
+		)
+	}
+	return middlewares.OrganizationRoleError(membership.Role, permission)
+}
+
+func callerMembership(e *core.RequestEvent) (orgrole.Membership, *apierror.APIError) {
+	if e.Auth == nil {
+		return orgrole.Membership{}, apierror.New(
+			http.StatusUnauthorized,
+			"auth",
+			"authentication required",
+			"user not authenticated",
+		)
+	}
+	membership, err := orgrole.Find(e.App, e.Auth.Id)
+	if err != nil {
+		return orgrole.Membership{}, apierror.New(
+			http.StatusInternalServerError,
+			"organization",
+			"failed to get user organization",
+			err.Error(),
+		)
+	}
+	return membership, nil
+}
+
+// usableWhenPublished reports whether other organizations may use a
+// published record with permission.
+func usableWhenPublished(permission apikey.Permission) bool {
+	return strings.HasSuffix(string(permission), ":read") ||
+		strings.HasSuffix(string(permission), ":run")
+}
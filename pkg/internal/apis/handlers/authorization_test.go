@@ -0,0 +1,381 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+)
+
+// setUserOrganizationRole gives userID the role in their organization.
+func setUserOrganizationRole(t testing.TB, app core.App, userID string, role orgrole.Role) {
+	t.Helper()
+
+	roleRecord, err := app.FindFirstRecordByData("orgRoles", "name", string(role))
+	require.NoError(t, err)
+	authorization, err := app.FindFirstRecordByData("orgAuthorizations", "user", userID)
+	require.NoError(t, err)
+	authorization.Set("role", roleRecord.Id)
+	require.NoError(t, app.Save(authorization))
+}
+
+// withStubHandlers returns group with every handler answering 204 and no
+// request validation, so that only the route middlewares decide.
+func withStubHandlers(group routing.RouteGroup) *routing.RouteGroup {
+	routes := make([]routing.RouteDefinition, len(group.Routes))
+	for i, route := range group.Routes {
+		route.RequestSchema = nil
+		route.Handler = func() func(*core.RequestEvent) error {
+			return func(e *core.RequestEvent) error {
+				return e.NoContent(http.StatusNoContent)
+			}
+		}
+		routes[i] = route
+	}
+	group.Routes = routes
+	return &group
+}
+
+// seedRoleTestRecords saves a record of orgID in each collection whose record
+// API rules follow the role matrix, under the id recordURL expects.
+func seedRoleTestRecords(t testing.TB, app core.App, orgID string) {
+	t.Helper()
+
+	records := []struct {
+		collection string
+		fields     map[string]any
+	}{
+		{"pipelines", map[string]any{
+			"name":            "role-pipeline",
+			"canonified_name": "role-pipeline",
+			"description":     "role test pipeline",
+			"yaml":            "name: role\nsteps: []\n",
+		}},
+		{"wallets", map[string]any{"name": "role-wallet"}},
+		{"mobile_runners", map[string]any{
+			"name": "role-runner",
+			"ip":   "https://role.example.test",
+			"type": "android_phone",
+		}},
+		{"schedules", map[string]any{"temporal_schedule_id": "role-schedule"}},
+	}
+	for _, seed := range records {
+		collection, err := app.FindCollectionByNameOrId(seed.collection)
+		require.NoError(t, err)
+		record := core.NewRecord(collection)
+		record.Id = roleTestRecordID(seed.collection)
+		record.Set("owner", orgID)
+		for key, value := range seed.fields {
+			record.Set(key, value)
+		}
+		require.NoError(t, app.Save(record))
+	}
+}
+
+func roleTestRecordID(collection string) string {
+	return (strings.ReplaceAll(collection, "_", "") + "000000000000000")[:15]
+}
+
+func recordURL(collection string) string {
+	return "/api/collections/" + collection + "/records/" + roleTestRecordID(collection)
+}
+
+func TestOrganizationRolesAcrossRouteGroups(t *testing.T) {
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	writers := []orgrole.Role{orgrole.Owner, orgrole.Admin, orgrole.Member}
+	// The record API rules keep owners as the only baseline writers.
+	recordWriters := []orgrole.Role{orgrole.Owner}
+	routes := []struct {
+		group   string
+		method  string
+		url     string
+		allowed []orgrole.Role
+	}{
+		{"pipelines", http.MethodGet, "/api/pipeline/list-executions", orgrole.Roles},
+		{
+			"pipelines",
+			http.MethodPost,
+			"/api/pipeline/queue",
+			append(writers, orgrole.PipelineAuthor),
+		},
+		{"wallets", http.MethodPost, "/api/wallet/get-installer-md5-or-etag", orgrole.Roles},
+		{
+			"wallets",
+			http.MethodPost,
+			"/api/wallet/start-check",
+			append(writers, orgrole.PipelineAuthor),
+		},
+		{"runners", http.MethodGet, "/api/mobile-runners", orgrole.Roles},
+		{
+			"runners",
+			http.MethodPost,
+			"/api/mobile-runner/lifecycle/heartbeat",
+			append(writers, orgrole.RunnerOperator),
+		},
+		{"schedules", http.MethodGet, "/api/my/schedules", orgrole.Roles},
+		{
+			"schedules",
+			http.MethodPost,
+			"/api/my/schedules/start",
+			append(writers, orgrole.PipelineAuthor),
+		},
+		{
+			"records",
+			http.MethodPatch,
+			recordURL("pipelines"),
+			append(recordWriters, orgrole.PipelineAuthor),
+		},
+		{
+			"records",
+			http.MethodPatch,
+			recordURL("wallets"),
+			append(recordWriters, orgrole.PipelineAuthor),
+		},
+		{
+			"records",
+			http.MethodPatch,
+			recordURL("mobile_runners"),
+			append(recordWriters, orgrole.RunnerOperator),
+		},
+		{
+			"records",
+			http.MethodDelete,
+			recordURL("schedules"),
+			append(recordWriters, orgrole.PipelineAuthor),
+		},
+		{"retention", http.MethodPost, "/api/pipeline/retention/delete-files", nil},
+		{
+			"audit",
+			http.MethodGet,
+			"/api/organizations/my/audit-log",
+			[]orgrole.Role{orgrole.Owner, orgrole.Admin},
+		},
+	}
+
+	for _, role := range orgrole.Roles {
+		for _, route := range routes {
+			expectedStatus := http.StatusForbidden
+			switch {
+			case route.group == "records" && !slices.Contains(route.allowed, role):
+				// The record API hides the records its rules refuse.
+				expectedStatus = http.StatusNotFound
+			case route.group == "records" && route.method == http.MethodPatch:
+				expectedStatus = http.StatusOK
+			case slices.Contains(route.allowed, role):
+				expectedStatus = http.StatusNoContent
+			case route.group == "retention":
+				expectedStatus = http.StatusUnauthorized
+			}
+			scenario := tests.ApiScenario{
+				Name:           string(role) + " " + route.method + " " + route.url,
+				Method:         route.method,
+				URL:            route.url,
+				Headers:        headers,
+				ExpectedStatus: expectedStatus,
+				TestAppFactory: func(t testing.TB) *tests.TestApp {
+					app, err := tests.NewTestApp(testDataDir)
+					require.NoError(t, err)
+					for _, group := range []routing.RouteGroup{
+						PipelineRoutes,
+						PipelinePublicRoutes,
+						PipelineTemporalInternalRoutes,
+						WalletRoutes,
+						WalletTemporalInternalRoutes,
+						MobileRunnersPublicRoutes,
+						MobileRunnerLifecycleRoutes,
+						SchedulesRoutes,
+						OrganizationRoutes,
+						OrganizationPublicRoutes,
+					} {
+						withStubHandlers(group).Add(app)
+					}
+					setUserOrganizationRole(t, app, userRecord.Id, role)
+					seedRoleTestRecords(t, app, orgID)
+					return app
+				},
+			}
+			switch expectedStatus {
+			case http.StatusForbidden:
+				scenario.ExpectedContent = []string{"organization_role_not_allowed"}
+			case http.StatusUnauthorized:
+				scenario.ExpectedContent = []string{"api_key_required"}
+			case http.StatusOK:
+				scenario.ExpectedContent = []string{`"owner":"` + orgID + `"`}
+			case http.StatusNotFound:
+				scenario.ExpectedContent = []string{`"status":404`}
+			}
+			scenario.Test(t)
+		}
+	}
+}
+
+func TestAuthorizeOrganizationRecord(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	canonify.RegisterCanonifyHooks(app)
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	otherOrg := createOtherWalletAPKOrganization(t, app)
+
+	own := createWalletAPITestPipelineNamed(t, app, orgID, "own-pipeline", "name: own", false)
+	published := createWalletAPITestPipelineNamed(
+		t,
+		app,
+		otherOrg.Id,
+		"published-pipeline",
+		"name: published",
+		true,
+	)
+	private := createWalletAPITestPipelineNamed(
+		t,
+		app,
+		otherOrg.Id,
+		"private-pipeline",
+		"name: private",
+		false,
+	)
+
+	newEvent := func(auth *core.Record) *core.RequestEvent {
+		return &core.RequestEvent{
+			App:  app,
+			Auth: auth,
+			Event: router.Event{
+				Request:  httptest.NewRequest(http.MethodPost, "/", nil),
+				Response: httptest.NewRecorder(),
+			},
+		}
+	}
+	requireStatus := func(
+		t *testing.T,
+		expected int,
+		pipeline *core.Record,
+		permission apikey.Permission,
+	) {
+		t.Helper()
+		apiErr := authorizePipelineAccess(newEvent(user), pipeline, permission)
+		if expected == http.StatusOK {
+			require.Nil(t, apiErr)
+			return
+		}
+		require.NotNil(t, apiErr)
+		require.Equal(t, expected, apiErr.Code)
+	}
+
+	t.Run("owners use their pipelines and published ones", func(t *testing.T) {
+		setUserOrganizationRole(t, app, user.Id, orgrole.Owner)
+		requireStatus(t, http.StatusOK, own, apikey.PermissionPipelinesRun)
+		requireStatus(t, http.StatusOK, published, apikey.PermissionPipelinesRun)
+		requireStatus(t, http.StatusOK, published, apikey.PermissionPipelinesRead)
+		requireStatus(t, http.StatusForbidden, published, apikey.PermissionSchedulesWrite)
+		requireStatus(t, http.StatusForbidden, private, apikey.PermissionPipelinesRead)
+	})
+
+	t.Run("viewers only read", func(t *testing.T) {
+		setUserOrganizationRole(t, app, user.Id, orgrole.Viewer)
+		requireStatus(t, http.StatusOK, own, apikey.PermissionPipelinesRead)
+		requireStatus(t, http.StatusOK, published, apikey.PermissionPipelinesRead)
+		requireStatus(t, http.StatusForbidden, own, apikey.PermissionPipelinesRun)
+	})
+
+	t.Run("runner operators manage runners but not pipelines", func(t *testing.T) {
+		setUserOrganizationRole(t, app, user.Id, orgrole.RunnerOperator)
+		requireStatus(t, http.StatusForbidden, own, apikey.PermissionPipelinesRun)
+
+		createMobileRunnerRecord(t, app, orgID, "operated-runner", "https://runner.example", false)
+		runner, err := app.FindFirstRecordByData("mobile_runners", "name", "operated-runner")
+		require.NoError(t, err)
+		require.Nil(t, authorizeRunnerAccess(
+			newEvent(user),
+			runner,
+			apikey.PermissionRunnersHeartbeat,
+		))
+	})
+
+	t.Run("internal admins are not bound to an organization", func(t *testing.T) {
+		superuser, err := app.FindAuthRecordByEmail("_superusers", "admin@example.org")
+		require.NoError(t, err)
+		require.Nil(t, authorizePipelineAccess(
+			newEvent(superuser),
+			private,
+			apikey.PermissionPipelinesRun,
+		))
+	})
+}
+
+func TestAuthorizeScheduleAccess(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	event := &core.RequestEvent{App: app, Auth: user}
+
+	setUserOrganizationRole(t, app, user.Id, orgrole.PipelineAuthor)
+	require.Nil(t, authorizeScheduleAccess(event, "schedule-1", apikey.PermissionSchedulesWrite))
+
+	setUserOrganizationRole(t, app, user.Id, orgrole.Viewer)
+	apiErr := authorizeScheduleAccess(event, "schedule-1", apikey.PermissionSchedulesWrite)
+	require.NotNil(t, apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.Code)
+	require.Nil(t, authorizeScheduleAccess(event, "schedule-1", apikey.PermissionSchedulesRead))
+}
+
+func TestAuthorizeScheduleAccessWithRestrictedAPIKey(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	setUserOrganizationRole(t, app, user.Id, orgrole.PipelineAuthor)
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "restricted-schedule-key",
+		UserID:    user.Id,
+		Scope:     "user",
+		Grant: apikey.Grant{
+			Resources: map[apikey.Resource][]string{
+				apikey.ResourcePipelines: {"pipeline-1"},
+			},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/my/schedules/schedule-1/pause", nil)
+	req.Header.Set("Credimi-Api-Key", "restricted-schedule-key")
+	e := &core.RequestEvent{
+		App:   app,
+		Event: router.Event{Request: req, Response: httptest.NewRecorder()},
+	}
+	setHandlerNext(e, func() error { return nil })
+	require.NoError(t, middlewares.RequireAuthOrAPIKey().Func(e))
+
+	apiErr := authorizeScheduleAccess(e, "schedule-1", apikey.PermissionSchedulesWrite)
+	require.NotNil(t, apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.Code)
+	require.Equal(t, "api_key_resource_not_allowed", apiErr.Reason)
+}
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
@@ -35,29 +36,38 @@ var ConformanceRoutes routing.RouteGroup = routing.RouteGroup{
 	BaseURL: "/api/compliance",
 	Routes: []routing.RouteDefinition{
 		{
-			Method:  http.MethodGet,
-			Path:    "/checks/{workflowId}/{runId}",
-			Handler: HandleGetWorkflow,
+			Method:     http.MethodGet,
+			Path:       "/checks/{workflowId}/{runId}",
+			Permission: apikey.PermissionWorkflowsRead,
+			Handler:    HandleGetWorkflow,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "/checks/{workflowId}/{runId}/result",
-			Handler: HandleGetWorkflowResult,
+			Method:     http.MethodGet,
+			Path:       "/checks/{workflowId}/{runId}/result",
+			Permission: apikey.PermissionResultsRead,
+			Handler:    HandleGetWorkflowResult,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "/checks/{workflowId}/{runId}/history",
-			Handler: HandleGetWorkflowsHistory,
+			Method:     http.MethodGet,
+			Path:       "/checks/{workflowId}/{runId}/history",
+			Permission: apikey.PermissionWorkflowsRead,
+			Handler:    HandleGetWorkflowsHistory,
 		},
 		{
 			Method:        http.MethodPost,
 			Path:          "/{protocol}/{version}/save-variables-and-start",
+			Permission:    apikey.PermissionWorkflowsRun,
 			Handler:       HandleSaveVariablesAndStart,
 			RequestSchema: SaveVariablesAndStartRequestInput{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
 		{
 			Method:        http.MethodPost,
 			Path:          "/send-temporal-signal",
+			Permission:    apikey.PermissionWorkflowsRun,
 			Handler:       HandleSendTemporalSignal,
 			RequestSchema: HandleSendTemporalSignalInput{},
 		},
@@ -83,9 +93,10 @@ var ConformanceRoutes routing.RouteGroup = routing.RouteGroup{
 			ExcludedMiddlewares: []string{middlewares.RequireAuthOrAPIKeyMiddlewareID},
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "/deeplink/{workflowId}/{runId}",
-			Handler: HandleDeeplink,
+			Method:     http.MethodGet,
+			Path:       "/deeplink/{workflowId}/{runId}",
+			Permission: apikey.PermissionWorkflowsRead,
+			Handler:    HandleDeeplink,
 		},
 	},
 	Middlewares: []*hook.Handler[*core.RequestEvent]{
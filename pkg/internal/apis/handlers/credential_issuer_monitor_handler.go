@@ -0,0 +1,435 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/issuerdrift"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	issuerMetadataMonitorScheduleID      = "credential-issuers-metadata-monitor-schedule"
+	issuerMetadataMonitorDefaultInterval = 1
+	credentialIssuerRevisionsCollection  = "credential_issuer_revisions"
+)
+
+var issuerMetadataMonitorTemporalClient = temporalclient.GetTemporalClientWithNamespace
+
+var issuerMetadataMonitorTriggerOptions = client.ScheduleTriggerOptions{
+	Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+}
+
+// issuerMetadataNotifiedRoles are the organization roles emailed about
+// breaking metadata changes.
+var issuerMetadataNotifiedRoles = []string{"owner", "admin"}
+
+type ScheduleIssuerMetadataMonitorRequest struct {
+	IntervalDays int `json:"interval_days" validate:"omitempty,min=1"`
+}
+
+type ScheduleIssuerMetadataMonitorResponse struct {
+	Message           string `json:"message"`
+	ScheduleID        string `json:"schedule_id"`
+	WorkflowNamespace string `json:"workflowNamespace"`
+}
+
+type DeleteIssuerMetadataMonitorResponse struct {
+	Success           bool   `json:"success"`
+	Message           string `json:"message"`
+	ScheduleID        string `json:"schedule_id"`
+	WorkflowNamespace string `json:"workflowNamespace"`
+}
+
+type StoreCredentialIssuerRevisionRequest struct {
+	IssuerID string         `json:"issuerID" validate:"required"`
+	Metadata map[string]any `json:"metadata" validate:"required"`
+}
+
+// HandleScheduleIssuerMetadataMonitor upserts the schedule re-fetching the
+// metadata of the caller's organization credential issuers and runs it now.
+func HandleScheduleIssuerMetadataMonitor() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		input, err := routing.GetValidatedInput[ScheduleIssuerMetadataMonitorRequest](e)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		intervalDays := input.IntervalDays
+		if intervalDays == 0 {
+			intervalDays = issuerMetadataMonitorDefaultInterval
+		}
+
+		organization, err := pbutils.GetUserOrganizationID(e.App, e.Auth.Id)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get user organization",
+				err.Error(),
+			)
+		}
+		namespace, err := pbutils.GetOrganizationCanonifiedName(e.App, organization)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get organization canonified name",
+				err.Error(),
+			)
+		}
+
+		issuerSchema, apiErr := credentialIssuerReadSchemaFile(
+			utils.GetEnvironmentVariable("ROOT_DIR") + "/" + workflows.CredentialIssuerSchemaPath,
+		)
+		if apiErr != nil {
+			return apiErr
+		}
+		workflowInput := workflowengine.WorkflowInput{
+			Config: map[string]any{
+				"app_url":       e.App.Settings().Meta.AppURL,
+				"issuer_schema": issuerSchema,
+				"orgID":         organization,
+			},
+		}
+
+		c, err := issuerMetadataMonitorTemporalClient(namespace)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"temporal",
+				"failed to create temporal client",
+				err.Error(),
+			)
+		}
+
+		ctx := e.Request.Context()
+		_, err = c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+			ID:      issuerMetadataMonitorScheduleID,
+			Spec:    buildIssuerMetadataMonitorScheduleSpec(intervalDays),
+			Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+			Action:  buildIssuerMetadataMonitorScheduleAction(workflowInput),
+		})
+		handle := c.ScheduleClient().GetHandle(ctx, issuerMetadataMonitorScheduleID)
+		if err != nil && isScheduleAlreadyExistsError(err) {
+			err = handle.Update(ctx, client.ScheduleUpdateOptions{
+				DoUpdate: func(client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+					spec := buildIssuerMetadataMonitorScheduleSpec(intervalDays)
+					return &client.ScheduleUpdate{Schedule: &client.Schedule{
+						Spec: &spec,
+						Policy: &client.SchedulePolicies{
+							Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+						},
+						State:  &client.ScheduleState{},
+						Action: buildIssuerMetadataMonitorScheduleAction(workflowInput),
+					}}, nil
+				},
+			})
+		}
+		if err == nil {
+			err = handle.Trigger(ctx, issuerMetadataMonitorTriggerOptions)
+		}
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"schedule",
+				"failed to upsert issuer metadata monitor schedule",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, ScheduleIssuerMetadataMonitorResponse{
+			Message: fmt.Sprintf(
+				"Issuer metadata monitor triggered now and scheduled every %d day(s)",
+				intervalDays,
+			),
+			ScheduleID:        issuerMetadataMonitorScheduleID,
+			WorkflowNamespace: namespace,
+		})
+	}
+}
+
+// HandleDeleteIssuerMetadataMonitor removes the metadata monitor schedule of
+// the caller's organization.
+func HandleDeleteIssuerMetadataMonitor() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		namespace, err := pbutils.GetUserOrganizationCanonifiedName(e.App, e.Auth.Id)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get organization canonified name",
+				err.Error(),
+			)
+		}
+
+		c, err := issuerMetadataMonitorTemporalClient(namespace)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"temporal",
+				"failed to create temporal client",
+				err.Error(),
+			)
+		}
+
+		ctx := e.Request.Context()
+		handle := c.ScheduleClient().GetHandle(ctx, issuerMetadataMonitorScheduleID)
+		if err := handle.Delete(ctx); err != nil {
+			var notFound *serviceerror.NotFound
+			if errors.As(err, &notFound) {
+				return apierror.New(
+					http.StatusNotFound,
+					"schedule",
+					"issuer metadata monitor schedule not found",
+					err.Error(),
+				)
+			}
+			return apierror.New(
+				http.StatusInternalServerError,
+				"schedule",
+				"failed to delete issuer metadata monitor schedule",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, DeleteIssuerMetadataMonitorResponse{
+			Success:           true,
+			Message:           "Issuer metadata monitor schedule deleted successfully",
+			ScheduleID:        issuerMetadataMonitorScheduleID,
+			WorkflowNamespace: namespace,
+		})
+	}
+}
+
+// HandleCredentialIssuerMonitorTargets lists the credential issuers of an
+// organization whose metadata the monitor snapshots.
+func HandleCredentialIssuerMonitorTargets() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID := strings.TrimSpace(e.Request.URL.Query().Get("orgID"))
+		if orgID == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"credential_issuers",
+				"missing organization",
+				"orgID is required",
+			)
+		}
+
+		records, err := e.App.FindRecordsByFilter(
+			"credential_issuers",
+			"owner = {:owner} && url != ''",
+			"created",
+			0,
+			0,
+			dbx.Params{"owner": orgID},
+		)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"credential_issuers",
+				"failed to list credential issuers",
+				err.Error(),
+			)
+		}
+
+		response := workflows.IssuerMetadataMonitorTargets{
+			Issuers: make([]workflows.IssuerMetadataMonitorTarget, 0, len(records)),
+		}
+		for _, record := range records {
+			response.Issuers = append(response.Issuers, workflows.IssuerMetadataMonitorTarget{
+				ID:  record.Id,
+				URL: record.GetString("url"),
+			})
+		}
+		return e.JSON(http.StatusOK, response)
+	}
+}
+
+// HandleCredentialIssuerStoreRevision stores a metadata snapshot as a new
+// revision when it differs from the latest one, together with the diff.
+func HandleCredentialIssuerStoreRevision() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		input, err := routing.GetValidatedInput[StoreCredentialIssuerRevisionRequest](e)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+
+		issuer, err := e.App.FindRecordById("credential_issuers", input.IssuerID)
+		if err != nil {
+			return apierror.New(
+				http.StatusNotFound,
+				"credential_issuers",
+				"credential issuer not found",
+				err.Error(),
+			)
+		}
+
+		revision, err := storeCredentialIssuerRevision(e.App, issuer, input.Metadata)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"credential_issuers",
+				"failed to store credential issuer revision",
+				err.Error(),
+			)
+		}
+		return e.JSON(http.StatusOK, revision)
+	}
+}
+
+func storeCredentialIssuerRevision(
+	app core.App,
+	issuer *core.Record,
+	metadata map[string]any,
+) (workflows.IssuerMetadataRevision, error) {
+	collection, err := app.FindCollectionByNameOrId(credentialIssuerRevisionsCollection)
+	if err != nil {
+		return workflows.IssuerMetadataRevision{}, err
+	}
+	hash, err := issuerdrift.Hash(metadata)
+	if err != nil {
+		return workflows.IssuerMetadataRevision{}, err
+	}
+
+	previous := map[string]any{}
+	latestRevision := 0
+	latest, err := app.FindRecordsByFilter(
+		collection,
+		"credential_issuer = {:issuer}",
+		"-revision",
+		1,
+		0,
+		dbx.Params{"issuer": issuer.Id},
+	)
+	if err != nil {
+		return workflows.IssuerMetadataRevision{}, err
+	}
+	if len(latest) > 0 {
+		latestRevision = latest[0].GetInt("revision")
+		if latest[0].GetString("metadata_hash") == hash {
+			return workflows.IssuerMetadataRevision{
+				Revision: latestRevision,
+				Diff:     issuerdrift.Diff{Changes: []issuerdrift.Change{}},
+			}, nil
+		}
+		if err := latest[0].UnmarshalJSONField("metadata", &previous); err != nil {
+			return workflows.IssuerMetadataRevision{}, err
+		}
+	}
+
+	// The first snapshot is the baseline, not a change.
+	diff := issuerdrift.Diff{Changes: []issuerdrift.Change{}}
+	if latestRevision > 0 {
+		diff = issuerdrift.Compute(previous, metadata)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("owner", issuer.GetString("owner"))
+	record.Set("credential_issuer", issuer.Id)
+	record.Set("revision", latestRevision+1)
+	record.Set("metadata_hash", hash)
+	record.Set("metadata", metadata)
+	record.Set("diff", diff)
+	record.Set("breaking", diff.Breaking)
+	if err := app.Save(record); err != nil {
+		return workflows.IssuerMetadataRevision{}, err
+	}
+
+	result := workflows.IssuerMetadataRevision{
+		Changed:  true,
+		Revision: latestRevision + 1,
+		Breaking: diff.Breaking,
+		Diff:     diff,
+	}
+	if diff.Breaking {
+		result.Recipients, err = organizationNotificationEmails(app, issuer.GetString("owner"))
+		if err != nil {
+			return workflows.IssuerMetadataRevision{}, err
+		}
+	}
+	return result, nil
+}
+
+// organizationNotificationEmails returns the emails of the organization
+// members holding one of issuerMetadataNotifiedRoles.
+func organizationNotificationEmails(app core.App, orgID string) ([]string, error) {
+	roleFilters := make([]string, 0, len(issuerMetadataNotifiedRoles))
+	params := dbx.Params{"organization": orgID}
+	for i, role := range issuerMetadataNotifiedRoles {
+		name := fmt.Sprintf("role%d", i)
+		roleFilters = append(roleFilters, fmt.Sprintf("role.name = {:%s}", name))
+		params[name] = role
+	}
+	authorizations, err := app.FindRecordsByFilter(
+		"orgAuthorizations",
+		"organization = {:organization} && ("+strings.Join(roleFilters, " || ")+")",
+		"created",
+		0,
+		0,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := []string{}
+	for _, authorization := range authorizations {
+		user, err := app.FindRecordById("users", authorization.GetString("user"))
+		if err != nil {
+			continue
+		}
+		if email := user.Email(); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+func buildIssuerMetadataMonitorScheduleSpec(intervalDays int) client.ScheduleSpec {
+	return client.ScheduleSpec{
+		Intervals: []client.ScheduleIntervalSpec{{
+			Every: time.Duration(intervalDays) * 24 * time.Hour,
+		}},
+	}
+}
+
+func buildIssuerMetadataMonitorScheduleAction(
+	input workflowengine.WorkflowInput,
+) *client.ScheduleWorkflowAction {
+	return &client.ScheduleWorkflowAction{
+		ID:        "Issuer-Metadata-Monitor-Scheduled",
+		Workflow:  workflows.IssuerMetadataMonitorWorkflowName,
+		TaskQueue: workflows.IssuerMetadataMonitorTaskQueue,
+		Args: []interface{}{
+			input,
+		},
+	}
+}
@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/issuerdrift"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func ensureCredentialIssuerRevisionsCollection(t testing.TB, app *tests.TestApp) {
+	t.Helper()
+
+	if _, err := app.FindCollectionByNameOrId(credentialIssuerRevisionsCollection); err == nil {
+		return
+	}
+	orgs, err := app.FindCollectionByNameOrId("organizations")
+	require.NoError(t, err)
+	issuers, err := app.FindCollectionByNameOrId("credential_issuers")
+	require.NoError(t, err)
+
+	coll := core.NewBaseCollection(credentialIssuerRevisionsCollection)
+	coll.Fields.Add(
+		&core.RelationField{Name: "owner", CollectionId: orgs.Id, MaxSelect: 1},
+		&core.RelationField{Name: "credential_issuer", CollectionId: issuers.Id, MaxSelect: 1},
+		&core.NumberField{Name: "revision", OnlyInt: true},
+		&core.TextField{Name: "metadata_hash"},
+		&core.JSONField{Name: "metadata"},
+		&core.JSONField{Name: "diff"},
+		&core.BoolField{Name: "breaking"},
+	)
+	require.NoError(t, app.Save(coll))
+}
+
+func monitoredIssuerMetadata(algs ...any) map[string]any {
+	return map[string]any{
+		"credential_issuer":   "https://issuer.example",
+		"credential_endpoint": "https://issuer.example/credential",
+		"credential_configurations_supported": map[string]any{
+			"pid": map[string]any{
+				"format": "dc+sd-jwt",
+				"credential_signing_alg_values_supported": algs,
+			},
+		},
+	}
+}
+
+func TestHandleScheduleIssuerMetadataMonitor(t *testing.T) {
+	t.Setenv("ROOT_DIR", "../../../..")
+
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	app.Settings().Meta.AppURL = "https://credimi.test"
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := pbutils.GetUserOrganizationID(app, authRecord.Id)
+	require.NoError(t, err)
+	orgName, err := pbutils.GetOrganizationCanonifiedName(app, orgID)
+	require.NoError(t, err)
+
+	origRead := credentialIssuerReadSchemaFile
+	origTemporalClient := issuerMetadataMonitorTemporalClient
+	t.Cleanup(func() {
+		credentialIssuerReadSchemaFile = origRead
+		issuerMetadataMonitorTemporalClient = origTemporalClient
+	})
+	credentialIssuerReadSchemaFile = func(string) (string, *apierror.APIError) {
+		return `{"type":"object"}`, nil
+	}
+
+	serve := func(t *testing.T, scheduleClient *fakeScheduleClient) *httptest.ResponseRecorder {
+		mockClient := &temporalmocks.Client{}
+		mockClient.On("ScheduleClient").Return(scheduleClient)
+		issuerMetadataMonitorTemporalClient = func(namespace string) (client.Client, error) {
+			require.Equal(t, orgName, namespace)
+			return mockClient, nil
+		}
+
+		rec := httptest.NewRecorder()
+		err := HandleScheduleIssuerMetadataMonitor()(&core.RequestEvent{
+			App:  app,
+			Auth: authRecord,
+			Event: router.Event{
+				Request: httptest.NewRequest(
+					http.MethodPost,
+					"/api/credentials_issuers/monitor",
+					bytes.NewBufferString(`{}`),
+				),
+				Response: rec,
+			},
+		})
+		require.NoError(t, err)
+		return rec
+	}
+
+	t.Run("creates and triggers the schedule", func(t *testing.T) {
+		mockHandle := &temporalmocks.ScheduleHandle{}
+		mockHandle.On("Trigger", mock.Anything, issuerMetadataMonitorTriggerOptions).
+			Return(nil).
+			Once()
+		scheduleClient := &fakeScheduleClient{handle: mockHandle}
+
+		rec := serve(t, scheduleClient)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response ScheduleIssuerMetadataMonitorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		require.Equal(t, issuerMetadataMonitorScheduleID, response.ScheduleID)
+		require.Equal(t, orgName, response.WorkflowNamespace)
+		require.Contains(t, response.Message, "every 1 day(s)")
+
+		require.Len(t, scheduleClient.createdOptions, 1)
+		opts := scheduleClient.createdOptions[0]
+		require.Equal(t, issuerMetadataMonitorScheduleID, opts.ID)
+		require.Equal(t, 24*time.Hour, opts.Spec.Intervals[0].Every)
+		action, ok := opts.Action.(*client.ScheduleWorkflowAction)
+		require.True(t, ok)
+		require.Equal(t, workflows.IssuerMetadataMonitorWorkflowName, action.Workflow)
+		require.Equal(t, workflows.IssuerMetadataMonitorTaskQueue, action.TaskQueue)
+		input, ok := action.Args[0].(workflowengine.WorkflowInput)
+		require.True(t, ok)
+		require.Equal(t, orgID, input.Config["orgID"])
+		require.Equal(t, `{"type":"object"}`, input.Config["issuer_schema"])
+		require.Equal(t, "https://credimi.test", input.Config["app_url"])
+		mockHandle.AssertExpectations(t)
+	})
+
+	t.Run("updates an existing schedule", func(t *testing.T) {
+		mockHandle := &temporalmocks.ScheduleHandle{}
+		var updateOptions client.ScheduleUpdateOptions
+		mockHandle.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			updateOptions = args.Get(1).(client.ScheduleUpdateOptions)
+		}).Return(nil).Once()
+		mockHandle.On("Trigger", mock.Anything, issuerMetadataMonitorTriggerOptions).
+			Return(nil).
+			Once()
+		scheduleClient := &fakeScheduleClient{
+			createErr: serviceerror.NewAlreadyExists("schedule exists"),
+			handle:    mockHandle,
+		}
+
+		rec := serve(t, scheduleClient)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		update, err := updateOptions.DoUpdate(client.ScheduleUpdateInput{})
+		require.NoError(t, err)
+		require.Equal(t, 24*time.Hour, update.Schedule.Spec.Intervals[0].Every)
+		action, ok := update.Schedule.Action.(*client.ScheduleWorkflowAction)
+		require.True(t, ok)
+		require.Equal(t, workflows.IssuerMetadataMonitorTaskQueue, action.TaskQueue)
+		mockHandle.AssertExpectations(t)
+	})
+}
+
+func TestHandleDeleteIssuerMetadataMonitor(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	origTemporalClient := issuerMetadataMonitorTemporalClient
+	t.Cleanup(func() { issuerMetadataMonitorTemporalClient = origTemporalClient })
+
+	deleteSchedule := func(deleteErr error) (*httptest.ResponseRecorder, error) {
+		mockHandle := &temporalmocks.ScheduleHandle{}
+		mockHandle.On("Delete", mock.Anything).Return(deleteErr).Once()
+		mockClient := &temporalmocks.Client{}
+		mockClient.On("ScheduleClient").Return(&fakeScheduleClient{handle: mockHandle})
+		issuerMetadataMonitorTemporalClient = func(string) (client.Client, error) {
+			return mockClient, nil
+		}
+
+		rec := httptest.NewRecorder()
+		err := HandleDeleteIssuerMetadataMonitor()(&core.RequestEvent{
+			App:  app,
+			Auth: authRecord,
+			Event: router.Event{
+				Request: httptest.NewRequest(
+					http.MethodDelete,
+					"/api/credentials_issuers/monitor",
+					nil,
+				),
+				Response: rec,
+			},
+		})
+		return rec, err
+	}
+
+	rec, err := deleteSchedule(nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response DeleteIssuerMetadataMonitorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.True(t, response.Success)
+
+	_, err = deleteSchedule(&serviceerror.NotFound{Message: "missing"})
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.Code)
+}
+
+func TestCredentialIssuerRevisionRoutes(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	IssuerTemporalInternalRoutes.Add(app)
+	seedInternalAdminKey(t, app)
+	ensureCredentialIssuerRevisionsCollection(t, app)
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	issuersColl, err := app.FindCollectionByNameOrId("credential_issuers")
+	require.NoError(t, err)
+	issuer := core.NewRecord(issuersColl)
+	issuer.Set("owner", orgID)
+	issuer.Set("url", "https://issuer.example")
+	issuer.Set("name", "Monitored issuer")
+	require.NoError(t, app.Save(issuer))
+
+	baseRouter, err := apis.NewRouter(app)
+	require.NoError(t, err)
+	serveEvent := &core.ServeEvent{App: app, Router: baseRouter}
+	err = app.OnServe().Trigger(serveEvent, func(e *core.ServeEvent) error {
+		mux, err := e.Router.BuildMux()
+		require.NoError(t, err)
+
+		do := func(method, target string, body any) *httptest.ResponseRecorder {
+			var reader *bytes.Reader
+			if body != nil {
+				encoded, err := json.Marshal(body)
+				require.NoError(t, err)
+				reader = bytes.NewReader(encoded)
+			} else {
+				reader = bytes.NewReader(nil)
+			}
+			req := httptest.NewRequest(method, target, reader)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Credimi-Api-Key", "internal-test-api-key")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec
+		}
+		storeRevision := func(metadata map[string]any) workflows.IssuerMetadataRevision {
+			rec := do(http.MethodPost, "/api/credentials_issuers/revisions", map[string]any{
+				"issuerID": issuer.Id,
+				"metadata": metadata,
+			})
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var revision workflows.IssuerMetadataRevision
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&revision))
+			return revision
+		}
+
+		rec := do(http.MethodGet, "/api/credentials_issuers/monitor-targets?orgID="+orgID, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var targets workflows.IssuerMetadataMonitorTargets
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&targets))
+		require.Contains(t, targets.Issuers, workflows.IssuerMetadataMonitorTarget{
+			ID:  issuer.Id,
+			URL: "https://issuer.example",
+		})
+
+		rec = do(http.MethodGet, "/api/credentials_issuers/monitor-targets", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		baseline := storeRevision(monitoredIssuerMetadata("ES256", "ES384"))
+		require.True(t, baseline.Changed)
+		require.Equal(t, 1, baseline.Revision)
+		require.False(t, baseline.Breaking)
+		require.Empty(t, baseline.Diff.Changes)
+
+		unchanged := storeRevision(monitoredIssuerMetadata("ES256", "ES384"))
+		require.False(t, unchanged.Changed)
+		require.Equal(t, 1, unchanged.Revision)
+
+		additive := storeRevision(monitoredIssuerMetadata("ES256", "ES384", "EdDSA"))
+		require.True(t, additive.Changed)
+		require.Equal(t, 2, additive.Revision)
+		require.False(t, additive.Breaking)
+		require.Empty(t, additive.Recipients)
+
+		breaking := storeRevision(monitoredIssuerMetadata("ES256"))
+		require.True(t, breaking.Changed)
+		require.Equal(t, 3, breaking.Revision)
+		require.True(t, breaking.Breaking)
+		require.Contains(t, breaking.Recipients, "userA@example.org")
+		require.Len(t, breaking.Diff.Changes, 2)
+		require.Equal(t, issuerdrift.KindRemoved, breaking.Diff.Changes[1].Kind)
+
+		rec = do(http.MethodPost, "/api/credentials_issuers/revisions", map[string]any{
+			"issuerID": "missing",
+			"metadata": map[string]any{},
+		})
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		return nil
+	})
+	require.NoError(t, err)
+
+	records, err := app.FindAllRecords(credentialIssuerRevisionsCollection)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for _, record := range records {
+		require.Equal(t, orgID, record.GetString("owner"))
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
@@ -43,15 +44,37 @@ var IssuersRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:        http.MethodPost,
 			Path:          "/start-check",
+			Permission:    apikey.PermissionIssuersWrite,
 			Handler:       HandleCredentialIssuerStartCheck,
 			RequestSchema: IssuerURL{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+			},
 		},
 		{
 			Method:        http.MethodPost,
 			Path:          "/import-fides",
+			Permission:    apikey.PermissionIssuersWrite,
 			Handler:       HandleCredentialIssuerImportFides,
 			RequestSchema: ImportFidesCredentialIssuersRequest{},
 		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/monitor",
+			Permission:     apikey.PermissionIssuersWrite,
+			Handler:        HandleScheduleIssuerMetadataMonitor,
+			RequestSchema:  ScheduleIssuerMetadataMonitorRequest{},
+			ResponseSchema: ScheduleIssuerMetadataMonitorResponse{},
+			Description:    "Schedule the metadata drift monitor for the organization credential issuers",
+		},
+		{
+			Method:         http.MethodDelete,
+			Path:           "/monitor",
+			Permission:     apikey.PermissionIssuersWrite,
+			Handler:        HandleDeleteIssuerMetadataMonitor,
+			ResponseSchema: DeleteIssuerMetadataMonitorResponse{},
+			Description:    "Delete the metadata drift monitor schedule",
+		},
 	},
 }
 
@@ -100,6 +123,25 @@ var IssuerTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 				middlewares.RequireInternalAdminAPIKey(),
 			},
 		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/monitor-targets",
+			Handler:        HandleCredentialIssuerMonitorTargets,
+			ResponseSchema: workflows.IssuerMetadataMonitorTargets{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/revisions",
+			Handler:        HandleCredentialIssuerStoreRevision,
+			RequestSchema:  StoreCredentialIssuerRevisionRequest{},
+			ResponseSchema: workflows.IssuerMetadataRevision{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
 	},
 }
 
@@ -117,10 +159,11 @@ type StoreOrUpdateCredentialsRequest struct {
 }
 
 type StoreOrUpdateCredentialIssuerRequest struct {
-	URL   string `json:"url"`
-	OrgID string `json:"orgID"`
-	Name  string `json:"name,omitempty"`
-	Logo  string `json:"logo,omitempty"`
+	URL            string         `json:"url"`
+	OrgID          string         `json:"orgID"`
+	Name           string         `json:"name,omitempty"`
+	Logo           string         `json:"logo,omitempty"`
+	SignedMetadata map[string]any `json:"signed_metadata,omitempty"`
 }
 
 type ImportFidesCredentialIssuersRequest struct {
@@ -348,6 +391,9 @@ func HandleCredentialIssuerStartCheck() func(*core.RequestEvent) error {
 		record.Set("name", issuerName)
 		record.Set("logo_url", logo)
 		record.Set("workflow_url", workflowURL)
+		if signedMetadata, ok := issuerResult["signedMetadata"].(map[string]any); ok {
+			setSignedMetadataVerification(record, signedMetadata)
+		}
 		if err := e.App.Save(record); err != nil {
 			return apierror.New(
 				http.StatusInternalServerError,
@@ -798,6 +844,9 @@ func HandleCredentialIssuerStoreOrUpdate() func(*core.RequestEvent) error {
 		if body.Logo != "" {
 			record.Set("logo_url", body.Logo)
 		}
+		if body.SignedMetadata != nil {
+			setSignedMetadataVerification(record, body.SignedMetadata)
+		}
 
 		if err := e.App.Save(record); err != nil {
 			return apierror.New(
@@ -814,6 +863,14 @@ func HandleCredentialIssuerStoreOrUpdate() func(*core.RequestEvent) error {
 	}
 }
 
+// setSignedMetadataVerification records the outcome of verifying the issuer's
+// signed_metadata on the credential issuer record.
+func setSignedMetadataVerification(record *core.Record, verification map[string]any) {
+	status, _ := verification["status"].(string)
+	record.SetIfFieldExists("signed_metadata_status", status)
+	record.SetIfFieldExists("signed_metadata_verification", verification)
+}
+
 func checkWellKnownEndpoints(ctx context.Context, baseURL string) error {
 	cleanURL := strings.TrimSpace(baseURL)
 	if !strings.HasPrefix(cleanURL, "https://") && !strings.HasPrefix(cleanURL, "http://") {
@@ -861,24 +918,6 @@ func isCredentialIssuerWellKnownURL(rawURL string) bool {
 		strings.HasSuffix(rawURL, wellKnownPath)
 }
 
-func isPrivateIP(ip net.IP) bool {
-	privateBlocks := []*net.IPNet{
-		// IPv4 private ranges
-		{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
-		{IP: net.IPv4(172, 16, 0, 0), Mask: net.CIDRMask(12, 32)},
-		{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(16, 32)},
-		// IPv6 loopback and link-local
-		{IP: net.ParseIP("::1"), Mask: net.CIDRMask(128, 128)},
-		{IP: net.ParseIP("fe80::"), Mask: net.CIDRMask(10, 128)},
-	}
-	for _, block := range privateBlocks {
-		if block.Contains(ip) {
-			return true
-		}
-	}
-	return ip.IsLoopback()
-}
-
 func checkEndpointExists(ctx context.Context, urlToCheck string) error {
 	parsedURL, err := url.Parse(urlToCheck)
 	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
@@ -894,7 +933,7 @@ func checkEndpointExists(ctx context.Context, urlToCheck string) error {
 		return fmt.Errorf("could not resolve host: %w", err)
 	}
 	for _, addr := range ips {
-		if isPrivateIP(addr.IP) {
+		if utils.IsPrivateIP(addr.IP) {
 			return fmt.Errorf("refusing to connect to private/internal IP: %s", addr.IP)
 		}
 	}
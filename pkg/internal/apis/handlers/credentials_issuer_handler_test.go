@@ -9,7 +9,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -1236,13 +1235,6 @@ func TestHandleCredentialIssuerStoreOrUpdateUpdatesExistingRecord(t *testing.T)
 	require.Equal(t, "https://new.logo", updated.GetString("logo_url"))
 }
 
-func TestIsPrivateIP(t *testing.T) {
-	require.True(t, isPrivateIP(net.IPv4(10, 0, 0, 1)))
-	require.True(t, isPrivateIP(net.IPv4(192, 168, 1, 1)))
-	require.True(t, isPrivateIP(net.ParseIP("::1")))
-	require.False(t, isPrivateIP(net.IPv4(8, 8, 8, 8)))
-}
-
 func TestCheckEndpointExistsInvalidURL(t *testing.T) {
 	err := checkEndpointExists(context.Background(), "://bad")
 	require.Error(t, err)
@@ -1387,3 +1379,81 @@ func TestParseCredentialDisplay(t *testing.T) {
 		})
 	}
 }
+
+func addSignedMetadataFields(t testing.TB, app core.App) {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId("credential_issuers")
+	require.NoError(t, err)
+	collection.Fields.Add(&core.SelectField{
+		Name:      "signed_metadata_status",
+		MaxSelect: 1,
+		Values:    []string{"absent", "verified", "invalid"},
+	})
+	collection.Fields.Add(&core.JSONField{Name: "signed_metadata_verification"})
+	require.NoError(t, app.Save(collection))
+}
+
+func TestHandleCredentialIssuerStoreOrUpdateRecordsSignedMetadata(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addSignedMetadataFields(t, app)
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(map[string]any{
+		"url":   "https://signed.example.com",
+		"orgID": orgID,
+		"name":  "Signed Issuer",
+		"signed_metadata": map[string]any{
+			"status":     "invalid",
+			"error":      "x5c chain is not trusted",
+			"checked_at": "2026-05-04T10:00:00Z",
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/api/credentials_issuers/store-or-update",
+		bytes.NewBuffer(payload),
+	)
+	rec := httptest.NewRecorder()
+
+	err = HandleCredentialIssuerStoreOrUpdate()(&core.RequestEvent{
+		App: app,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	record, err := app.FindFirstRecordByFilter(
+		"credential_issuers",
+		"url = {:url}",
+		map[string]any{"url": "https://signed.example.com"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "invalid", record.GetString("signed_metadata_status"))
+
+	var verification map[string]any
+	require.NoError(t, record.UnmarshalJSONField("signed_metadata_verification", &verification))
+	require.Equal(t, "x5c chain is not trusted", verification["error"])
+}
+
+func TestSetSignedMetadataVerificationWithoutFields(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("credential_issuers")
+	require.NoError(t, err)
+	record := core.NewRecord(collection)
+
+	setSignedMetadataVerification(record, map[string]any{"status": "verified"})
+	require.Nil(t, record.Get("signed_metadata_status"))
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
@@ -25,13 +26,19 @@ var CustomIntegrationsRoutes routing.RouteGroup = routing.RouteGroup{
 	BaseURL: "/api/custom-integrations",
 	Routes: []routing.RouteDefinition{
 		{
-			Method:        http.MethodPost,
-			Path:          "/run",
-			Handler:       HandleRunCustomIntegration,
-			RequestSchema: RunCustomIntegrationRequestInput{},
-			OperationID:   "custom-integration.run",
-			Description:   "Run a custom integration",
-			Summary:       "Run a custom integration",
+			Method:         http.MethodPost,
+			Path:           "/run",
+			Permission:     apikey.PermissionIntegrationsRun,
+			Handler:        HandleRunCustomIntegration,
+			RequestSchema:  RunCustomIntegrationRequestInput{},
+			ResponseSchema: workflowengine.WorkflowResult{},
+			OperationID:    "custom-integration.run",
+			Description:    "Run a custom integration",
+			Summary:        "Run a custom integration",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
 	},
 	Middlewares: []*hook.Handler[*core.RequestEvent]{
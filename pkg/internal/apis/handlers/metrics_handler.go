@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+var MetricsRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/metrics",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "",
+			Handler:     HandleMetrics,
+			Middlewares: []*hook.Handler[*core.RequestEvent]{middlewares.RequireInternalAdminAPIKey()},
+			Description: "Prometheus/OpenMetrics exposition of Credimi metrics",
+		},
+	},
+}
+
+// HandleMetrics serves the metrics registry in the Prometheus text format, or
+// in OpenMetrics when the scraper negotiates it through the Accept header.
+func HandleMetrics() func(*core.RequestEvent) error {
+	handler := metrics.Handler()
+	return func(e *core.RequestEvent) error {
+		handler.ServeHTTP(e.Response, e.Request)
+		return nil
+	}
+}
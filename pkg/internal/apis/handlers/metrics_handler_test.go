@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMetricsApp(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.Bind(metrics.APIMiddleware())
+		return se.Next()
+	})
+	MetricsRoutes.Add(app)
+	seedInternalAdminKey(t, app)
+	return app
+}
+
+func TestMetricsRoute(t *testing.T) {
+	metrics.IncWorkerRestart("metrics-route-test-queue")
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing api key",
+			Method:          http.MethodGet,
+			URL:             "/metrics",
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{`"reason":"api_key_required"`},
+			TestAppFactory:  setupMetricsApp,
+		},
+		{
+			Name:   "internal admin api key",
+			Method: http.MethodGet,
+			URL:    "/metrics",
+			Headers: map[string]string{
+				"Credimi-Api-Key": "internal-test-api-key",
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`credimi_worker_restarts_total{task_queue="metrics-route-test-queue"} 1`,
+				`credimi_api_requests_total{method="GET",route="GET /metrics",status="401"}`,
+				`go_goroutines`,
+			},
+			TestAppFactory: setupMetricsApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -14,10 +14,11 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/mobilerunnerlifecycle"
-	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
@@ -57,6 +58,7 @@ var MobileRunnerLifecycleRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/resume",
+			Permission:     apikey.PermissionRunnersHeartbeat,
 			Handler:        HandleMobileRunnerLifecycleResume,
 			RequestSchema:  MobileRunnerLifecycleRequest{},
 			ResponseSchema: MobileRunnerLifecycleResponse{},
@@ -67,6 +69,7 @@ var MobileRunnerLifecycleRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/heartbeat",
+			Permission:     apikey.PermissionRunnersHeartbeat,
 			Handler:        HandleMobileRunnerLifecycleHeartbeat,
 			RequestSchema:  MobileRunnerLifecycleRequest{},
 			ResponseSchema: MobileRunnerLifecycleResponse{},
@@ -77,6 +80,7 @@ var MobileRunnerLifecycleRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/pause",
+			Permission:     apikey.PermissionRunnersHeartbeat,
 			Handler:        HandleMobileRunnerLifecyclePause,
 			RequestSchema:  MobileRunnerLifecycleRequest{},
 			ResponseSchema: MobileRunnerLifecycleResponse{},
@@ -99,13 +103,14 @@ func HandleMobileRunnerLifecycleResume() func(*core.RequestEvent) error {
 			)
 		}
 
-		record, runnerID, apiErr := resolveLifecycleRunner(e.App, e.Auth, input.RunnerID)
+		record, runnerID, apiErr := resolveLifecycleRunner(e, input.RunnerID)
 		if apiErr != nil {
 			return apiErr
 		}
 
 		now := mobileRunnerLifecycleNow()
 		setRunnerHeartbeat(record, true, now)
+		changes := audit.Diff(record.Original(), record)
 		if err := e.App.Save(record); err != nil {
 			return apierror.New(
 				http.StatusInternalServerError,
@@ -146,6 +151,8 @@ func HandleMobileRunnerLifecycleResume() func(*core.RequestEvent) error {
 			)
 		}
 
+		recordRunnerLifecycleAudit(e, record, audit.ActionRunnerResumed, changes, input.Reason)
+
 		return e.JSON(http.StatusOK, lifecycleResponse(runnerID, true))
 	}
 }
@@ -162,7 +169,7 @@ func HandleMobileRunnerLifecycleHeartbeat() func(*core.RequestEvent) error {
 			)
 		}
 
-		record, runnerID, apiErr := resolveLifecycleRunner(e.App, e.Auth, input.RunnerID)
+		record, runnerID, apiErr := resolveLifecycleRunner(e, input.RunnerID)
 		if apiErr != nil {
 			return apiErr
 		}
@@ -202,12 +209,13 @@ func HandleMobileRunnerLifecyclePause() func(*core.RequestEvent) error {
 			)
 		}
 
-		record, runnerID, apiErr := resolveLifecycleRunner(e.App, e.Auth, input.RunnerID)
+		record, runnerID, apiErr := resolveLifecycleRunner(e, input.RunnerID)
 		if apiErr != nil {
 			return apiErr
 		}
 
 		record.Set("online", false)
+		changes := audit.Diff(record.Original(), record)
 		if err := e.App.Save(record); err != nil {
 			return apierror.New(
 				http.StatusInternalServerError,
@@ -238,13 +246,34 @@ func HandleMobileRunnerLifecyclePause() func(*core.RequestEvent) error {
 			)
 		}
 
+		recordRunnerLifecycleAudit(e, record, audit.ActionRunnerPaused, changes, input.Reason)
+
 		return e.JSON(http.StatusOK, lifecycleResponse(runnerID, false))
 	}
 }
 
+func recordRunnerLifecycleAudit(
+	e *core.RequestEvent,
+	record *core.Record,
+	action string,
+	changes map[string]audit.Change,
+	reason string,
+) {
+	entry := audit.Entry{
+		Organization: record.GetString("owner"),
+		Action:       action,
+		ResourceType: record.Collection().Name,
+		ResourceID:   record.Id,
+		Changes:      changes,
+	}
+	if reason != "" {
+		entry.Details = map[string]any{"reason": reason}
+	}
+	recordAudit(e, entry)
+}
+
 func resolveLifecycleRunner(
-	app core.App,
-	auth *core.Record,
+	e *core.RequestEvent,
 	runnerID string,
 ) (*core.Record, string, *apierror.APIError) {
 	normalizedRunnerID := canonify.NormalizePath(runnerID)
@@ -257,7 +286,7 @@ func resolveLifecycleRunner(
 		)
 	}
 
-	record, err := canonify.Resolve(app, normalizedRunnerID)
+	record, err := canonify.Resolve(e.App, normalizedRunnerID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, "", apierror.New(
@@ -283,27 +312,15 @@ func resolveLifecycleRunner(
 		)
 	}
 
-	if !isSuperuserAuth(auth) {
-		orgID, err := pbutils.GetUserOrganizationID(app, auth.Id)
-		if err != nil {
-			return nil, "", apierror.New(
-				http.StatusInternalServerError,
-				"organization",
-				"failed_to_find_user_organization",
-				err.Error(),
-			)
-		}
-		if record.GetString("owner") != orgID {
-			return nil, "", apierror.New(
-				http.StatusForbidden,
-				"runner_id",
-				"runner_owner_mismatch",
-				"runner_id does not belong to the authenticated organization",
-			)
-		}
+	if apiErr := authorizeRunnerAccess(
+		e,
+		record,
+		apikey.PermissionRunnersHeartbeat,
+	); apiErr != nil {
+		return nil, "", apiErr
 	}
 
-	canonicalRunnerID, err := mobileRunnerIdentifier(app, record)
+	canonicalRunnerID, err := mobileRunnerIdentifier(e.App, record)
 	if err != nil {
 		return nil, "", apierror.New(
 			http.StatusInternalServerError,
@@ -14,6 +14,7 @@ import (
 	"github.com/forkbombeu/credimi/pkg/internal/mobilerunnerlifecycle"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.temporal.io/api/serviceerror"
@@ -327,7 +328,10 @@ func TestResolveLifecycleRunnerAllowsSuperuser(t *testing.T) {
 	otherOrg := createOtherWalletAPKOrganization(t, app)
 	createMobileRunnerRecord(t, app, otherOrg.Id, "admin-runner", "https://runner.example", false)
 
-	record, runnerID, apiErr := resolveLifecycleRunner(app, superuser, "/other-org/admin-runner")
+	record, runnerID, apiErr := resolveLifecycleRunner(
+		&core.RequestEvent{App: app, Auth: superuser},
+		"/other-org/admin-runner",
+	)
 	require.Nil(t, apiErr)
 	require.Equal(t, "admin-runner", record.GetString("name"))
 	require.Equal(t, "other-org/admin-runner", runnerID)
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
@@ -31,6 +32,7 @@ var MobileRunnerRegistrationRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/preview-id",
+			Permission:     apikey.PermissionRunnersWrite,
 			Handler:        HandlePreviewMobileRunnerID,
 			RequestSchema:  PreviewMobileRunnerIDRequest{},
 			ResponseSchema: PreviewMobileRunnerIDResponse{},
@@ -41,6 +43,7 @@ var MobileRunnerRegistrationRoutes = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "",
+			Permission:     apikey.PermissionRunnersWrite,
 			Handler:        HandleUpsertMobileRunner,
 			RequestSchema:  UpsertMobileRunnerRequest{},
 			ResponseSchema: UpsertMobileRunnerResponse{},
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
@@ -84,6 +85,7 @@ var MobileRunnersPublicRoutes routing.RouteGroup = routing.RouteGroup{
 			Method:         http.MethodGet,
 			Path:           "",
 			OperationID:    "listMobileRunners",
+			Permission:     apikey.PermissionRunnersRead,
 			Handler:        HandleListMobileRunners,
 			ResponseSchema: ListMobileRunnersPublicResponseSchema{},
 			Summary:        "List available mobile runners",
@@ -180,6 +182,7 @@ func HandleListMobileRunners() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		records = filterAPIKeyResources(e, apikey.ResourceRunners, records)
 
 		response := ListMobileRunnersPublicResponseSchema{
 			Runners: make([]MobileRunnerListItem, 0, len(records)),
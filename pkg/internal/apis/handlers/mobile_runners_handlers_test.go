@@ -13,6 +13,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
@@ -541,6 +542,44 @@ func TestListMobileRunners(t *testing.T) {
 		require.NotContains(t, raw["runners"][0], "devices")
 		require.NotContains(t, raw["runners"][0], "type")
 	})
+
+	t.Run("restricted key sees only its runners", func(t *testing.T) {
+		app := setupMobileRunnerApp(t)
+		defer app.Cleanup()
+
+		user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+		require.NoError(t, err)
+		userOrgID, err := pbutils.GetUserOrganizationID(app, user.Id)
+		require.NoError(t, err)
+
+		createMobileRunnerRecord(t, app, userOrgID, "granted", "granted-runner", false)
+		createMobileRunnerRecord(t, app, userOrgID, "not-granted", "other-runner", false)
+		granted, err := app.FindFirstRecordByData("mobile_runners", "name", "granted")
+		require.NoError(t, err)
+
+		originalHealth := checkMobileRunnerHealth
+		checkMobileRunnerHealth = func(_ context.Context, _ string) (bool, []MobileRunnerHealthDevice, error) {
+			return false, nil, nil
+		}
+		t.Cleanup(func() {
+			checkMobileRunnerHealth = originalHealth
+		})
+
+		event, rec := restrictedAPIKeyEvent(t, app, user.Id, apikey.Grant{
+			Resources: map[apikey.Resource][]string{
+				apikey.ResourceRunners: {granted.Id},
+			},
+		}, httptest.NewRequest(http.MethodGet, "/api/mobile-runners?view=selector", nil))
+
+		err = HandleListMobileRunners()(event)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response ListMobileRunnersPublicResponseSchema
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Runners, 1)
+		require.Equal(t, "granted", response.Runners[0].Name)
+	})
 }
 
 func createMobileRunnerRecord(
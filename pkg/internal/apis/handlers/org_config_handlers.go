@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/orgconfig"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+var orgConfigImportQueryAttributes = []routing.QuerySearchAttribute{
+	{Name: "dry_run", Description: "Report what the import would do without changing anything"},
+	{Name: "on_conflict", Description: "Existing resources: fail (default), skip or overwrite"},
+}
+
+// HandleExportMyOrganizationConfig returns the configuration of the caller
+// organization as an orgconfig archive.
+func HandleExportMyOrganizationConfig() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		org, apiErr := orgConfigOrganization(e, apikey.PermissionConfigRead)
+		if apiErr != nil {
+			return apiErr
+		}
+		archive, err := orgconfig.Export(e.App, org.Id, time.Now())
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"config",
+				"failed_to_export_configuration",
+				err.Error(),
+			)
+		}
+		var body bytes.Buffer
+		if err := archive.Write(&body); err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"config",
+				"failed_to_encode_configuration",
+				err.Error(),
+			)
+		}
+
+		filename := archive.Manifest.Organization + "-config.zip"
+		e.Response.Header().Set(
+			"Content-Disposition",
+			`attachment; filename="`+filename+`"`,
+		)
+		return e.Blob(http.StatusOK, "application/zip", body.Bytes())
+	}
+}
+
+// HandleImportMyOrganizationConfig imports the archive in the request body
+// into the caller organization. A blocked import, i.e. one with conflicts or
+// invalid resources, changes nothing and answers 409 with its report.
+func HandleImportMyOrganizationConfig() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		query := e.Request.URL.Query()
+		dryRun, err := strconv.ParseBool(queryOrDefault(query.Get("dry_run"), "false"))
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"dry_run",
+				"invalid_dry_run",
+				"dry_run must be a boolean",
+			)
+		}
+		policy, err := orgconfig.ParseConflictPolicy(query.Get("on_conflict"))
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"on_conflict",
+				"invalid_on_conflict",
+				err.Error(),
+			)
+		}
+		org, apiErr := orgConfigOrganization(e, apikey.PermissionConfigWrite)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		data, err := io.ReadAll(io.LimitReader(e.Request.Body, orgconfig.MaxArchiveBytes+1))
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"config",
+				"failed_to_read_archive",
+				err.Error(),
+			)
+		}
+		if len(data) > orgconfig.MaxArchiveBytes {
+			return apierror.New(
+				http.StatusRequestEntityTooLarge,
+				"config",
+				"archive_too_large",
+				fmt.Sprintf("archive is larger than %d bytes", orgconfig.MaxArchiveBytes),
+			)
+		}
+		archive, err := orgconfig.Read(data)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"config",
+				"invalid_archive",
+				err.Error(),
+			)
+		}
+
+		report, err := orgconfig.Import(e.App, org.Id, archive, orgconfig.Options{
+			DryRun:        dryRun,
+			OnConflict:    policy,
+			StartSchedule: importedScheduleStarter(e, org),
+		})
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"config",
+				"failed_to_import_configuration",
+				err.Error(),
+			)
+		}
+
+		if report.Applied {
+			recordAudit(e, audit.Entry{
+				Organization: org.Id,
+				Action:       audit.ActionConfigImported,
+				ResourceType: "organizations",
+				ResourceID:   org.Id,
+				Details: map[string]any{
+					"source":      archive.Manifest.Organization,
+					"on_conflict": string(policy),
+					"counts":      report.Counts,
+				},
+			})
+		}
+		if !report.DryRun && report.Blocked() {
+			return e.JSON(http.StatusConflict, report)
+		}
+		return e.JSON(http.StatusOK, report)
+	}
+}
+
+// orgConfigOrganization returns the organization of the caller when their
+// role grants permission.
+func orgConfigOrganization(
+	e *core.RequestEvent,
+	permission apikey.Permission,
+) (*core.Record, *apierror.APIError) {
+	membership, err := orgrole.Find(e.App, e.Auth.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"organization",
+			"failed_to_get_organization",
+			err.Error(),
+		)
+	}
+	if err != nil || !orgrole.Allows(membership.Role, permission) {
+		return nil, apierror.New(
+			http.StatusForbidden,
+			"config",
+			"organization_role_not_allowed",
+			"your organization role does not grant "+string(permission),
+		)
+	}
+	org, err := e.App.FindRecordById("organizations", membership.Organization)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"organization",
+			"failed_to_get_organization",
+			err.Error(),
+		)
+	}
+	return org, nil
+}
+
+// importedScheduleStarter starts the schedules of an import as the caller,
+// like HandleStartSchedule does.
+func importedScheduleStarter(e *core.RequestEvent, org *core.Record) orgconfig.ScheduleStarter {
+	namespace := org.GetString("canonified_name")
+	config := buildPipelineQueueConfig(
+		e,
+		namespace,
+		e.Auth.GetString("name"),
+		e.Auth.GetString("email"),
+	)
+	return func(pipeline *core.Record, rawMode map[string]any) (string, error) {
+		encoded, err := json.Marshal(rawMode)
+		if err != nil {
+			return "", fmt.Errorf("invalid schedule mode: %w", err)
+		}
+		var mode workflowengine.ScheduleMode
+		if err := json.Unmarshal(encoded, &mode); err != nil {
+			return "", fmt.Errorf("invalid schedule mode: %w", err)
+		}
+		if err := validateScheduleMode(&mode); err != nil {
+			return "", fmt.Errorf("invalid schedule mode: %w", err)
+		}
+		identifier, err := canonify.BuildPath(
+			e.App,
+			pipeline,
+			canonify.CanonifyPaths["pipelines"],
+			"",
+		)
+		if err != nil {
+			return "", err
+		}
+		info, err := startScheduledPipelineWithOptions(
+			identifier,
+			pipeline.GetString("name"),
+			namespace,
+			config,
+			mode,
+			e.Auth.GetString("Timezone"),
+			"",
+			org.GetInt("max_pipelines_in_queue"),
+		)
+		if err != nil {
+			return "", err
+		}
+		return info.ScheduleID, nil
+	}
+}
@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/forkbombeu/credimi/pkg/internal/orgconfig"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationConfigHandlers(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	source := &orgconfig.Archive{
+		Manifest: orgconfig.Manifest{
+			Version:      orgconfig.Version,
+			Organization: "source-org",
+			Resources: []orgconfig.Resource{{
+				Kind:   "custom_checks",
+				Path:   "source-org/imported-check",
+				Fields: map[string]any{"name": "Imported Check", "yaml": "steps: []"},
+			}},
+		},
+	}
+	var archive bytes.Buffer
+	require.NoError(t, source.Write(&archive))
+	body := func() io.Reader { return bytes.NewReader(archive.Bytes()) }
+
+	importedCheck := func(app core.App) (*core.Record, error) {
+		return app.FindFirstRecordByFilter(
+			"custom_checks",
+			"owner = {:owner} && canonified_name = 'imported-check'",
+			map[string]any{"owner": orgID},
+		)
+	}
+	withExistingCheck := func(t testing.TB) *tests.TestApp {
+		app := setupOrganizationApp(t)
+		collection, err := app.FindCollectionByNameOrId("custom_checks")
+		require.NoError(t, err)
+		record := core.NewRecord(collection)
+		record.Set("owner", orgID)
+		record.Set("name", "Imported Check")
+		record.Set("canonified_name", "imported-check")
+		record.Set("yaml", "steps: [old]")
+		require.NoError(t, app.Save(record))
+		return app
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "export the configuration as a zip archive",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/config/export",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{orgconfig.ManifestName},
+			TestAppFactory:  setupOrganizationApp,
+			AfterTestFunc: func(t testing.TB, _ *tests.TestApp, res *http.Response) {
+				require.Equal(
+					t,
+					`attachment; filename="usera-s-organization-config.zip"`,
+					res.Header.Get("Content-Disposition"),
+				)
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				exported, err := orgconfig.Read(data)
+				require.NoError(t, err)
+				require.Equal(t, "usera-s-organization", exported.Manifest.Organization)
+			},
+		},
+		{
+			Name:           "dry run reports the resources to create",
+			Method:         http.MethodPost,
+			URL:            "/api/organizations/my/config/import?dry_run=true",
+			Headers:        headers,
+			Body:           body(),
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"dry_run":true`,
+				`"applied":false`,
+				`"path":"usera-s-organization/imported-check"`,
+				`"action":"create"`,
+			},
+			TestAppFactory: setupOrganizationApp,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				_, err := importedCheck(app)
+				require.Error(t, err)
+			},
+		},
+		{
+			Name:            "import the archive and record it in the audit log",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import",
+			Headers:         headers,
+			Body:            body(),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"applied":true`},
+			TestAppFactory:  setupOrganizationApp,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				record, err := importedCheck(app)
+				require.NoError(t, err)
+				require.Equal(t, "steps: []", record.GetString("yaml"))
+
+				entry, err := app.FindFirstRecordByData(
+					audit.Collection,
+					"action",
+					audit.ActionConfigImported,
+				)
+				require.NoError(t, err)
+				require.Equal(t, orgID, entry.GetString("owner"))
+				var details map[string]any
+				require.NoError(t, json.Unmarshal([]byte(entry.GetString("details")), &details))
+				require.Equal(t, "source-org", details["source"])
+			},
+		},
+		{
+			Name:            "refuse to overwrite existing resources by default",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import",
+			Headers:         headers,
+			Body:            body(),
+			ExpectedStatus:  http.StatusConflict,
+			ExpectedContent: []string{`"action":"conflict"`, `"applied":false`},
+			TestAppFactory:  withExistingCheck,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				record, err := importedCheck(app)
+				require.NoError(t, err)
+				require.Equal(t, "steps: [old]", record.GetString("yaml"))
+			},
+		},
+		{
+			Name:            "overwrite existing resources when asked",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import?on_conflict=overwrite",
+			Headers:         headers,
+			Body:            body(),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"action":"update"`},
+			TestAppFactory:  withExistingCheck,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				record, err := importedCheck(app)
+				require.NoError(t, err)
+				require.Equal(t, "steps: []", record.GetString("yaml"))
+			},
+		},
+		{
+			Name:            "reject an invalid archive",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import",
+			Headers:         headers,
+			Body:            bytes.NewReader([]byte("not a zip")),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_archive"},
+			TestAppFactory:  setupOrganizationApp,
+		},
+		{
+			Name:            "reject an unknown conflict policy",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import?on_conflict=merge",
+			Headers:         headers,
+			Body:            body(),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_on_conflict"},
+			TestAppFactory:  setupOrganizationApp,
+		},
+		{
+			Name:            "reject imports by members that are not admins",
+			Method:          http.MethodPost,
+			URL:             "/api/organizations/my/config/import",
+			Headers:         headers,
+			Body:            body(),
+			ExpectedStatus:  http.StatusForbidden,
+			ExpectedContent: []string{"organization_role_not_allowed"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupOrganizationApp(t)
+				member, err := app.FindFirstRecordByData("orgRoles", "name", "member")
+				require.NoError(t, err)
+				authorization, err := app.FindFirstRecordByData(
+					"orgAuthorizations",
+					"user",
+					userRecord.Id,
+				)
+				require.NoError(t, err)
+				authorization.Set("role", member.Id)
+				require.NoError(t, app.Save(authorization))
+				return app
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
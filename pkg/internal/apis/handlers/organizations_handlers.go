@@ -9,7 +9,10 @@ import (
 	"sort"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/orgconfig"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/pocketbase/dbx"
@@ -17,7 +20,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/hook"
 )
 
-var OrganizationRoutes routing.RouteGroup = routing.RouteGroup{
+var OrganizationPublicRoutes routing.RouteGroup = routing.RouteGroup{
 	BaseURL:                "/api/organizations",
 	AuthenticationRequired: true,
 	Middlewares: []*hook.Handler[*core.RequestEvent]{
@@ -25,19 +28,90 @@ var OrganizationRoutes routing.RouteGroup = routing.RouteGroup{
 	},
 	Routes: []routing.RouteDefinition{
 		{
-			Method:      http.MethodGet,
-			Path:        "/my",
-			Handler:     HandleGetMyOrganization,
-			Description: "Get the current user's organization info",
+			Method:         http.MethodGet,
+			Path:           "/my",
+			OperationID:    "organization.get",
+			Permission:     apikey.PermissionOrganizationsRead,
+			Handler:        HandleGetMyOrganization,
+			ResponseSchema: map[string]any{},
+			Description:    "Get the current user's organization info",
 		},
+	},
+}
+
+var OrganizationRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/organizations",
+	AuthenticationRequired: true,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
 		{
 			Method:      http.MethodGet,
 			Path:        "/visible-namespaces",
+			Permission:  apikey.PermissionOrganizationsRead,
 			Handler:     HandleGetVisibleOrganizationNamespaces,
 			Description: "Get the caller organization namespace plus all published organization namespaces",
 		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/my/quota",
+			Permission:     apikey.PermissionOrganizationsRead,
+			Handler:        HandleGetMyOrganizationQuota,
+			ResponseSchema: OrganizationQuotaResponse{},
+			Description:    "Get the quota limits and current usage of the caller organization",
+		},
+		{
+			Method:                http.MethodGet,
+			Path:                  "/my/audit-log",
+			Permission:            apikey.PermissionAuditRead,
+			Handler:               HandleListMyAuditLog,
+			ResponseSchema:        AuditLogResponse{},
+			QuerySearchAttributes: auditLogQueryAttributes,
+			Description:           "List the audit log of the caller organization (admins only)",
+		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/my/audit-log/export",
+			Permission:     apikey.PermissionAuditRead,
+			Handler:        HandleExportMyAuditLog,
+			ResponseSchema: []audit.Log{},
+			QuerySearchAttributes: append([]routing.QuerySearchAttribute{{
+				Name:        "format",
+				Description: "Export format: json (default) or csv",
+			}}, auditLogQueryAttributes...),
+			Description: "Export the audit log of the caller organization (admins only)",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/my/config/export",
+			Permission:  apikey.PermissionConfigRead,
+			Handler:     HandleExportMyOrganizationConfig,
+			Description: "Export the configuration of the caller organization as a zip archive",
+		},
+		{
+			Method:                http.MethodPost,
+			Path:                  "/my/config/import",
+			Permission:            apikey.PermissionConfigWrite,
+			Handler:               HandleImportMyOrganizationConfig,
+			ResponseSchema:        orgconfig.Report{},
+			QuerySearchAttributes: orgConfigImportQueryAttributes,
+			Description:           "Import a configuration archive into the caller organization",
+		},
 	},
 }
+
+var auditLogQueryAttributes = []routing.QuerySearchAttribute{
+	{Name: "action", Description: "Action, or action prefix ending with a dot, e.g. pipeline."},
+	{Name: "actor", Description: "User or API key id of the actor"},
+	{Name: "resource_type", Description: "Collection of the resource, e.g. pipelines"},
+	{Name: "resource_id", Description: "Id of the resource"},
+	{Name: "since", Description: "RFC 3339 timestamp of the oldest entry"},
+	{Name: "until", Description: "RFC 3339 timestamp after the newest entry"},
+	{Name: "limit", Description: "Entries per page (default 50, max 500)"},
+	{Name: "page", Description: "Zero-based page number"},
+}
+
 var OrganizationTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 	BaseURL:                "/api/organizations",
 	AuthenticationRequired: false,
@@ -54,6 +128,35 @@ var OrganizationTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 				middlewares.RequireInternalAdminAPIKey(),
 			},
 		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/{organization}/quota",
+			Handler:        HandleGetOrganizationQuota,
+			ResponseSchema: OrganizationQuotaResponse{},
+			Description:    "Get the quota limits and usage of an organization (internal use)",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+		{
+			Method:        http.MethodPost,
+			Path:          "/{organization}/quota/runs",
+			Handler:       HandleReserveOrganizationRun,
+			RequestSchema: OrganizationRunInput{},
+			Description:   "Reserve one of the concurrent runs of an organization for a pipeline run (internal use)",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/{organization}/quota/runs/{workflowId}",
+			Handler:     HandleReleaseOrganizationRun,
+			Description: "Release the concurrent run of a pipeline run (internal use)",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
 	},
 }
 
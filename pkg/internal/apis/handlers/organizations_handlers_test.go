@@ -23,6 +23,7 @@ func setupOrganizationApp(t testing.TB) *tests.TestApp {
 	ensureOrganizationPublishedField(t, app)
 	canonify.RegisterCanonifyHooks(app)
 	OrganizationRoutes.Add(app)
+	OrganizationPublicRoutes.Add(app)
 	return app
 }
 
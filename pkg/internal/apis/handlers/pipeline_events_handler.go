@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// PipelineEventsDone is the SSE event sent once the run has finished.
+const PipelineEventsDone = "done"
+
+var (
+	pipelineEventsPollInterval = time.Second
+	// pipelineEventsKeepAlive is how long a quiet stream waits before sending
+	// a comment line, so proxies do not drop the connection.
+	pipelineEventsKeepAlive = 15 * time.Second
+)
+
+// PipelineEventsDoneData is the payload of the final "done" event.
+type PipelineEventsDoneData struct {
+	Result string `json:"result"`
+}
+
+// HandleStreamPipelineExecutionEvents streams the progress of one pipeline
+// run as server-sent events. Each event id is the progress sequence number, so
+// clients resume with Last-Event-ID (or ?after=) after a reconnect.
+func HandleStreamPipelineExecutionEvents() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		resolved, apiErr := resolvePipelineExecution(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		after, apiErr := pipelineEventsCursor(e.Request)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		ctx := e.Request.Context()
+		snapshot, err := queryPipelineProgress(e, resolved, after)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadGateway,
+				"workflow",
+				"unable to query pipeline progress",
+				err.Error(),
+			)
+		}
+
+		header := e.Response.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		e.Response.WriteHeader(http.StatusOK)
+		controller := http.NewResponseController(e.Response)
+
+		lastWrite := time.Now()
+		for {
+			for _, event := range snapshot.Events {
+				if err := writePipelineEvent(e.Response, event); err != nil {
+					return nil
+				}
+				after = event.Seq
+				lastWrite = time.Now()
+			}
+			if snapshot.Next > after {
+				after = snapshot.Next
+			}
+			if snapshot.Done {
+				_ = writeSSE(
+					e.Response,
+					strconv.Itoa(after),
+					PipelineEventsDone,
+					PipelineEventsDoneData{Result: snapshot.Result},
+				)
+				_ = controller.Flush()
+				return nil
+			}
+			if time.Since(lastWrite) >= pipelineEventsKeepAlive {
+				if _, err := io.WriteString(e.Response, ": keep-alive\n\n"); err != nil {
+					return nil
+				}
+				lastWrite = time.Now()
+			}
+			if err := controller.Flush(); err != nil {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(pipelineEventsPollInterval):
+			}
+
+			snapshot, err = queryPipelineProgress(e, resolved, after)
+			if err != nil {
+				_ = writeSSE(e.Response, "", "error", map[string]string{"message": err.Error()})
+				_ = controller.Flush()
+				return nil
+			}
+		}
+	}
+}
+
+func pipelineEventsCursor(r *http.Request) (int, *apierror.APIError) {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("after"))
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	after, err := strconv.Atoi(raw)
+	if err != nil || after < 0 {
+		return 0, apierror.New(
+			http.StatusBadRequest,
+			"after",
+			"invalid event cursor",
+			"Last-Event-ID and after must be a non-negative integer",
+		)
+	}
+	return after, nil
+}
+
+func queryPipelineProgress(
+	e *core.RequestEvent,
+	resolved *resolvedPipelineExecution,
+	after int,
+) (pipeline.ProgressSnapshot, error) {
+	var snapshot pipeline.ProgressSnapshot
+	encoded, err := resolved.Client.QueryWorkflow(
+		e.Request.Context(),
+		resolved.Ref.WorkflowID,
+		resolved.Ref.RunID,
+		pipeline.PipelineProgressQuery,
+		after,
+	)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := encoded.Get(&snapshot); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+func writePipelineEvent(w io.Writer, event pipeline.ProgressEvent) error {
+	return writeSSE(w, strconv.Itoa(event.Seq), event.Type, event)
+}
+
+func writeSSE(w io.Writer, id string, event string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", event, encoded)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
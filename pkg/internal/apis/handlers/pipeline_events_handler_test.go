@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+type progressSnapshotEncodedValue struct {
+	snapshot pipeline.ProgressSnapshot
+}
+
+func (v progressSnapshotEncodedValue) HasValue() bool {
+	return true
+}
+
+func (v progressSnapshotEncodedValue) Get(valuePtr interface{}) error {
+	encoded, err := json.Marshal(v.snapshot)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, valuePtr)
+}
+
+func setupPipelineEventsTest(
+	t *testing.T,
+	lastEventID string,
+) (*core.RequestEvent, *httptest.ResponseRecorder, *temporalmocks.Client) {
+	t.Helper()
+
+	app := setupPipelineStartApp(t)
+	t.Cleanup(app.Cleanup)
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	organization, err := pbutils.GetUserOrganization(app, authRecord.Id)
+	require.NoError(t, err)
+	pipelineRecord := createPipelineExecutionTestPipeline(t, app, organization.Id)
+	pipelineIdentifier := pipelineIdentifierForTest(t, app, pipelineRecord)
+
+	mockClient := &temporalmocks.Client{}
+	mockClient.On("DescribeWorkflowExecution", mock.Anything, "wf-1", "run-1").
+		Return(&workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: buildPipelineExecutionInfo(
+				"wf-1",
+				"run-1",
+				pipelineIdentifier,
+			),
+		}, nil).
+		Once()
+	originalTemporalClient := pipelineTemporalClient
+	originalInterval := pipelineEventsPollInterval
+	t.Cleanup(func() {
+		pipelineTemporalClient = originalTemporalClient
+		pipelineEventsPollInterval = originalInterval
+	})
+	pipelineTemporalClient = func(string) (client.Client, error) { return mockClient, nil }
+	pipelineEventsPollInterval = time.Millisecond
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetPathValue("id", pipelineRecord.Id)
+	req.SetPathValue("workflow_id", "wf-1")
+	req.SetPathValue("run_id", "run-1")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	return &core.RequestEvent{
+		App:  app,
+		Auth: authRecord,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	}, rec, mockClient
+}
+
+func expectPipelineProgressQuery(
+	mockClient *temporalmocks.Client,
+	after int,
+	snapshot pipeline.ProgressSnapshot,
+	err error,
+) {
+	var value converter.EncodedValue
+	if err == nil {
+		value = progressSnapshotEncodedValue{snapshot: snapshot}
+	}
+	mockClient.On(
+		"QueryWorkflow",
+		mock.Anything,
+		"wf-1",
+		"run-1",
+		pipeline.PipelineProgressQuery,
+		after,
+	).Return(value, err).Once()
+}
+
+func TestHandleStreamPipelineExecutionEvents(t *testing.T) {
+	e, rec, mockClient := setupPipelineEventsTest(t, "")
+	expectPipelineProgressQuery(mockClient, 0, pipeline.ProgressSnapshot{
+		Events: []pipeline.ProgressEvent{
+			{Seq: 1, Type: pipeline.ProgressEventStepStarted, StepID: "login"},
+			{Seq: 2, Type: pipeline.ProgressEventWarning, Message: "slow runner"},
+		},
+		Next: 2,
+	}, nil)
+	expectPipelineProgressQuery(mockClient, 2, pipeline.ProgressSnapshot{
+		Events: []pipeline.ProgressEvent{{
+			Seq:    3,
+			Type:   pipeline.ProgressEventStepFinished,
+			StepID: "login",
+			Status: pipeline.ProgressStepSuccess,
+		}},
+		Next:   3,
+		Done:   true,
+		Result: "success",
+	}, nil)
+
+	require.NoError(t, HandleStreamPipelineExecutionEvents()(e))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	require.Contains(t, body, "id: 1\nevent: step.started\ndata: {\"seq\":1,")
+	require.Contains(t, body, "id: 2\nevent: warning\n")
+	require.Contains(t, body, "id: 3\nevent: step.finished\n")
+	require.Contains(t, body, "id: 3\nevent: done\ndata: {\"result\":\"success\"}\n\n")
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleStreamPipelineExecutionEventsResumesFromLastEventID(t *testing.T) {
+	e, rec, mockClient := setupPipelineEventsTest(t, "7")
+	expectPipelineProgressQuery(mockClient, 7, pipeline.ProgressSnapshot{
+		Events: []pipeline.ProgressEvent{},
+		Next:   7,
+		Done:   true,
+		Result: "failed",
+	}, nil)
+
+	require.NoError(t, HandleStreamPipelineExecutionEvents()(e))
+	require.Equal(t, "id: 7\nevent: done\ndata: {\"result\":\"failed\"}\n\n", rec.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleStreamPipelineExecutionEventsRejectsInvalidCursor(t *testing.T) {
+	e, rec, _ := setupPipelineEventsTest(t, "soon")
+
+	err := HandleStreamPipelineExecutionEvents()(e)
+	requireHandlerErrorHandled(t, rec, err)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid event cursor")
+}
+
+func TestHandleStreamPipelineExecutionEventsReportsQueryErrors(t *testing.T) {
+	e, rec, mockClient := setupPipelineEventsTest(t, "")
+	expectPipelineProgressQuery(
+		mockClient,
+		0,
+		pipeline.ProgressSnapshot{},
+		errors.New("unknown queryType"),
+	)
+
+	err := HandleStreamPipelineExecutionEvents()(e)
+	requireHandlerErrorHandled(t, rec, err)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Contains(t, rec.Body.String(), "unable to query pipeline progress")
+	mockClient.AssertExpectations(t)
+}
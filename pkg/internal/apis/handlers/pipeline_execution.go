@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	InternalPipeline "github.com/forkbombeu/credimi/pkg/internal/pipeline"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
@@ -37,6 +39,19 @@ type PipelineExecuteResponse struct {
 
 func HandlePipelineExecute() func(*core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
+		// The YAML runs without a pipeline record, so a key restricted to
+		// some pipelines may not use this endpoint.
+		if grant, ok := middlewares.APIKeyGrant(e); ok {
+			if _, restricted := grant.Resources[apikey.ResourcePipelines]; restricted {
+				return apierror.New(
+					http.StatusForbidden,
+					"request.validation",
+					"api_key_resource_not_allowed",
+					"API key restricted to some pipelines cannot execute inline YAML",
+				)
+			}
+		}
+
 		// 1. Read request body
 		bodyBytes, err := io.ReadAll(e.Request.Body)
 		if err != nil {
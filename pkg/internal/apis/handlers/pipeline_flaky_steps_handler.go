@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	pipelineStepOutcomesCollection = "pipeline_step_outcomes"
+
+	// flakyStepsHistoryLimit bounds how many of the latest step outcomes of a
+	// pipeline are scanned when detecting flaky steps.
+	flakyStepsHistoryLimit = 1000
+)
+
+type PipelineStepOutcomesInput struct {
+	PipelineIdentifier string                         `json:"pipeline_identifier"`
+	WorkflowID         string                         `json:"workflow_id"`
+	RunID              string                         `json:"run_id"`
+	Outcomes           []pipelineinternal.StepOutcome `json:"outcomes"`
+	// Result is the final result of the run, reported when the run ends.
+	Result string `json:"result,omitempty"`
+}
+
+type PipelineStepOutcomesResponse struct {
+	Stored int `json:"stored"`
+}
+
+type PipelineFlakyStepsResponse struct {
+	FlakySteps []pipelineinternal.FlakyStep `json:"flaky_steps"`
+}
+
+func HandleRecordPipelineStepOutcomes() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		input, err := routing.GetValidatedInput[PipelineStepOutcomesInput](e)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(input.WorkflowID) == "" || strings.TrimSpace(input.RunID) == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"workflow_id",
+				"workflow_id and run_id are required",
+				"missing workflow_id or run_id",
+			)
+		}
+
+		pipelineRecord, err := canonify.Resolve(e.App, input.PipelineIdentifier)
+		if err != nil {
+			return apierror.New(
+				http.StatusNotFound,
+				"pipeline_identifier",
+				"pipeline not found",
+				err.Error(),
+			)
+		}
+
+		coll, err := e.App.FindCollectionByNameOrId(pipelineStepOutcomesCollection)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"collection",
+				"failed to get collection",
+				err.Error(),
+			)
+		}
+
+		stored := 0
+		for _, outcome := range input.Outcomes {
+			if strings.TrimSpace(outcome.StepID) == "" {
+				continue
+			}
+			if outcome.Outcome != pipelineinternal.StepOutcomeSuccess &&
+				outcome.Outcome != pipelineinternal.StepOutcomeFailed {
+				return apierror.New(
+					http.StatusBadRequest,
+					"outcome",
+					"invalid step outcome",
+					"outcome must be success or failed",
+				)
+			}
+
+			record, err := e.App.FindFirstRecordByFilter(
+				coll,
+				"workflow_id = {:workflow_id} && run_id = {:run_id} && step_id = {:step_id}",
+				dbx.Params{
+					"workflow_id": input.WorkflowID,
+					"run_id":      input.RunID,
+					"step_id":     outcome.StepID,
+				},
+			)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					return apierror.New(
+						http.StatusInternalServerError,
+						"pipeline",
+						"failed to lookup step outcome",
+						err.Error(),
+					)
+				}
+				record = core.NewRecord(coll)
+			}
+
+			record.Set("owner", pipelineRecord.GetString("owner"))
+			record.Set("pipeline", pipelineRecord.Id)
+			record.Set("workflow_id", input.WorkflowID)
+			record.Set("run_id", input.RunID)
+			record.Set("step_id", outcome.StepID)
+			record.Set("use", outcome.Use)
+			record.Set("outcome", outcome.Outcome)
+			record.Set("attempts", outcome.Attempts)
+			record.Set("quarantined", outcome.Quarantined)
+			record.Set("yaml_hash", outcome.YAMLHash)
+			record.Set("wallet_version", outcome.WalletVersion)
+			if err := e.App.Save(record); err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"pipeline",
+					"failed to save step outcome",
+					err.Error(),
+				)
+			}
+			stored++
+		}
+
+		emitPipelineFinishedWebhooks(e.App, pipelineRecord, input)
+
+		return e.JSON(http.StatusOK, PipelineStepOutcomesResponse{Stored: stored})
+	}
+}
+
+func HandleGetPipelineFlakySteps() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		pipelineIdentifier := e.Request.URL.Query().Get("pipeline_identifier")
+		if pipelineIdentifier == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"pipeline_identifier",
+				"pipeline_identifier is required",
+				"missing pipeline_identifier",
+			)
+		}
+
+		pipelineRecord, err := canonify.Resolve(e.App, pipelineIdentifier)
+		if err != nil {
+			return apierror.New(
+				http.StatusNotFound,
+				"pipeline_identifier",
+				"pipeline not found",
+				err.Error(),
+			)
+		}
+
+		flaky, err := findPipelineFlakySteps(
+			e.App,
+			pipelineRecord.Id,
+			e.Request.URL.Query().Get("yaml_hash"),
+		)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"pipeline",
+				"failed to detect flaky steps",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, PipelineFlakyStepsResponse{FlakySteps: flaky})
+	}
+}
+
+// findPipelineFlakySteps runs flaky-step detection over the latest recorded
+// outcomes of a pipeline, optionally restricted to one YAML revision. It
+// returns no steps when outcomes are not being recorded.
+func findPipelineFlakySteps(
+	app core.App,
+	pipelineID string,
+	yamlHash string,
+) ([]pipelineinternal.FlakyStep, error) {
+	coll, err := app.FindCollectionByNameOrId(pipelineStepOutcomesCollection)
+	if err != nil {
+		return []pipelineinternal.FlakyStep{}, nil
+	}
+
+	filter := "pipeline = {:pipeline}"
+	params := dbx.Params{"pipeline": pipelineID}
+	if yamlHash != "" {
+		filter += " && yaml_hash = {:yaml_hash}"
+		params["yaml_hash"] = yamlHash
+	}
+	records, err := app.FindRecordsByFilter(
+		coll,
+		filter,
+		"-created,-@rowid",
+		flakyStepsHistoryLimit,
+		0,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]pipelineinternal.StepOutcome, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		outcomes = append(outcomes, pipelineinternal.StepOutcome{
+			StepID:        record.GetString("step_id"),
+			Use:           record.GetString("use"),
+			Outcome:       record.GetString("outcome"),
+			Attempts:      record.GetInt("attempts"),
+			Quarantined:   record.GetBool("quarantined"),
+			YAMLHash:      record.GetString("yaml_hash"),
+			WalletVersion: record.GetString("wallet_version"),
+		})
+	}
+	return pipelineinternal.DetectFlakySteps(outcomes), nil
+}
+
+// emitPipelineFinishedWebhooks emits a step.failed event for every failed
+// step of a finished run, followed by its pipeline.finished event. The
+// events go to the organization that ran the pipeline.
+func emitPipelineFinishedWebhooks(
+	app core.App,
+	pipelineRecord *core.Record,
+	input PipelineStepOutcomesInput,
+) {
+	if input.Result == "" {
+		return
+	}
+	orgID := pipelineRecord.GetString("owner")
+	resultID := ""
+	result, err := app.FindFirstRecordByFilter(
+		"pipeline_results",
+		"workflow_id = {:workflow_id} && run_id = {:run_id}",
+		dbx.Params{"workflow_id": input.WorkflowID, "run_id": input.RunID},
+	)
+	if err == nil {
+		orgID = result.GetString("owner")
+		resultID = result.Id
+	}
+
+	failedSteps := []string{}
+	for _, outcome := range input.Outcomes {
+		if outcome.Outcome != pipelineinternal.StepOutcomeFailed {
+			continue
+		}
+		failedSteps = append(failedSteps, outcome.StepID)
+		emitWebhookEvent(app, orgID, webhook.EventStepFailed, map[string]any{
+			"pipeline_id": pipelineRecord.Id,
+			"result_id":   resultID,
+			"workflow_id": input.WorkflowID,
+			"run_id":      input.RunID,
+			"step_id":     outcome.StepID,
+			"use":         outcome.Use,
+			"attempts":    outcome.Attempts,
+			"quarantined": outcome.Quarantined,
+		})
+	}
+	emitWebhookEvent(app, orgID, webhook.EventPipelineFinished, map[string]any{
+		"pipeline_id":  pipelineRecord.Id,
+		"result_id":    resultID,
+		"workflow_id":  input.WorkflowID,
+		"run_id":       input.RunID,
+		"result":       input.Result,
+		"failed_steps": failedSteps,
+	})
+}
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func ensurePipelineStepOutcomesCollection(t testing.TB, app *tests.TestApp) *core.Collection {
+	t.Helper()
+
+	if coll, err := app.FindCollectionByNameOrId(pipelineStepOutcomesCollection); err == nil {
+		return coll
+	}
+	orgs, err := app.FindCollectionByNameOrId("organizations")
+	require.NoError(t, err)
+	pipelines, err := app.FindCollectionByNameOrId("pipelines")
+	require.NoError(t, err)
+
+	coll := core.NewBaseCollection(pipelineStepOutcomesCollection)
+	coll.Fields.Add(
+		&core.RelationField{Name: "owner", CollectionId: orgs.Id, MaxSelect: 1, Required: true},
+		&core.RelationField{Name: "pipeline", CollectionId: pipelines.Id, MaxSelect: 1, Required: true},
+		&core.TextField{Name: "workflow_id"},
+		&core.TextField{Name: "run_id"},
+		&core.TextField{Name: "step_id", Required: true},
+		&core.TextField{Name: "use"},
+		&core.SelectField{Name: "outcome", Values: []string{"success", "failed"}, MaxSelect: 1},
+		&core.NumberField{Name: "attempts"},
+		&core.BoolField{Name: "quarantined"},
+		&core.TextField{Name: "yaml_hash"},
+		&core.TextField{Name: "wallet_version"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	require.NoError(t, app.Save(coll))
+	return coll
+}
+
+func setupFlakyStepsApp(t testing.TB) *tests.TestApp {
+	app := setupPipelineApp(t)
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	createPipelineRecord(t, app, orgID, "pipeline123")
+	ensurePipelineStepOutcomesCollection(t, app)
+	return app
+}
+
+func seedStepOutcomes(t testing.TB, app *tests.TestApp, stepID string, outcomes ...string) {
+	t.Helper()
+
+	coll := ensurePipelineStepOutcomesCollection(t, app)
+	pipelineRecord, err := app.FindFirstRecordByData("pipelines", "name", "pipeline123")
+	require.NoError(t, err)
+	for i, outcome := range outcomes {
+		record := core.NewRecord(coll)
+		record.Set("owner", pipelineRecord.GetString("owner"))
+		record.Set("pipeline", pipelineRecord.Id)
+		record.Set("workflow_id", "wf-"+stepID)
+		record.Set("run_id", "run-"+strings.Repeat("x", i+1))
+		record.Set("step_id", stepID)
+		record.Set("use", "mobile-automation")
+		record.Set("outcome", outcome)
+		record.Set("yaml_hash", "hash-1")
+		require.NoError(t, app.Save(record))
+	}
+}
+
+func TestRecordPipelineStepOutcomes(t *testing.T) {
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "missing api key",
+			Method: http.MethodPost,
+			URL:    "/api/pipeline/step-outcomes",
+			Body: strings.NewReader(
+				`{"pipeline_identifier":"usera-s-organization/pipeline123","workflow_id":"wf","run_id":"run","outcomes":[]}`,
+			),
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{`"reason":"api_key_required"`},
+			TestAppFactory:  setupFlakyStepsApp,
+		},
+		{
+			Name:   "unknown pipeline",
+			Method: http.MethodPost,
+			URL:    "/api/pipeline/step-outcomes",
+			Body: strings.NewReader(
+				`{"pipeline_identifier":"usera-s-organization/missing","workflow_id":"wf","run_id":"run","outcomes":[]}`,
+			),
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"pipeline not found"`},
+			TestAppFactory:  setupFlakyStepsApp,
+		},
+		{
+			Name:   "invalid outcome",
+			Method: http.MethodPost,
+			URL:    "/api/pipeline/step-outcomes",
+			Body: strings.NewReader(
+				`{"pipeline_identifier":"usera-s-organization/pipeline123","workflow_id":"wf","run_id":"run",` +
+					`"outcomes":[{"step_id":"login","use":"mobile-automation","outcome":"skipped"}]}`,
+			),
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"invalid step outcome"`},
+			TestAppFactory:  setupFlakyStepsApp,
+		},
+		{
+			Name:   "stores outcomes and is idempotent per step",
+			Method: http.MethodPost,
+			URL:    "/api/pipeline/step-outcomes",
+			Body: strings.NewReader(
+				`{"pipeline_identifier":"usera-s-organization/pipeline123","workflow_id":"wf","run_id":"run",` +
+					`"outcomes":[` +
+					`{"step_id":"login","use":"mobile-automation","outcome":"failed","attempts":2,"quarantined":true,"yaml_hash":"hash-1","wallet_version":"v1"},` +
+					`{"step_id":"login","use":"mobile-automation","outcome":"failed","attempts":3,"yaml_hash":"hash-1","wallet_version":"v1"},` +
+					`{"step_id":"offer","use":"credential-offer","outcome":"success","yaml_hash":"hash-1"}` +
+					`]}`,
+			),
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"stored":3`},
+			TestAppFactory:  setupFlakyStepsApp,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				records, err := app.FindRecordsByFilter(
+					pipelineStepOutcomesCollection,
+					"workflow_id = {:workflow_id}",
+					"step_id",
+					0,
+					0,
+					dbx.Params{"workflow_id": "wf"},
+				)
+				require.NoError(t, err)
+				require.Len(t, records, 2)
+
+				login := records[0]
+				require.Equal(t, "login", login.GetString("step_id"))
+				require.Equal(t, "failed", login.GetString("outcome"))
+				require.Equal(t, 3, login.GetInt("attempts"))
+				require.False(t, login.GetBool("quarantined"))
+				require.Equal(t, "v1", login.GetString("wallet_version"))
+				require.NotEmpty(t, login.GetString("owner"))
+			},
+		},
+		{
+			Name:   "emits step and pipeline webhooks when the run ended",
+			Method: http.MethodPost,
+			URL:    "/api/pipeline/step-outcomes",
+			Body: strings.NewReader(
+				`{"pipeline_identifier":"usera-s-organization/pipeline123","workflow_id":"wf","run_id":"run",` +
+					`"result":"failed","outcomes":[` +
+					`{"step_id":"login","use":"mobile-automation","outcome":"failed"},` +
+					`{"step_id":"offer","use":"credential-offer","outcome":"success"}` +
+					`]}`,
+			),
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"stored":2`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupFlakyStepsApp(t)
+				orgID, err := getOrgIDfromName("userA's organization")
+				require.NoError(t, err)
+				record := seedWebhook(t, app, orgID, "https://example.org/hooks")
+				record.Set("events", []webhook.Event{
+					webhook.EventStepFailed,
+					webhook.EventPipelineFinished,
+				})
+				require.NoError(t, app.Save(record))
+				return app
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				deliveries, err := app.FindRecordsByFilter(
+					webhook.DeliveryCollection,
+					"webhook = {:webhook}",
+					"event",
+					0,
+					0,
+					dbx.Params{"webhook": testWebhookID},
+				)
+				require.NoError(t, err)
+				require.Len(t, deliveries, 2)
+				require.Equal(t, "pipeline.finished", deliveries[0].GetString("event"))
+				require.Equal(t, "step.failed", deliveries[1].GetString("event"))
+
+				var payload webhook.Payload
+				require.NoError(t, deliveries[0].UnmarshalJSONField("payload", &payload))
+				data, ok := payload.Data.(map[string]any)
+				require.True(t, ok)
+				require.Equal(t, "failed", data["result"])
+				require.Equal(t, []any{"login"}, data["failed_steps"])
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestGetPipelineFlakySteps(t *testing.T) {
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing pipeline_identifier",
+			Method:          http.MethodGet,
+			URL:             "/api/pipeline/flaky-steps",
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"pipeline_identifier is required"`},
+			TestAppFactory:  setupFlakyStepsApp,
+		},
+		{
+			Name:            "no history",
+			Method:          http.MethodGet,
+			URL:             "/api/pipeline/flaky-steps?pipeline_identifier=usera-s-organization/pipeline123",
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"flaky_steps":[]`},
+			TestAppFactory:  setupFlakyStepsApp,
+		},
+		{
+			Name:           "flags alternating step only",
+			Method:         http.MethodGet,
+			URL:            "/api/pipeline/flaky-steps?pipeline_identifier=usera-s-organization/pipeline123&yaml_hash=hash-1",
+			Headers:        map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"step_id":"login"`,
+				`"runs":4`,
+				`"failures":2`,
+				`"flips":3`,
+				`"score":1`,
+			},
+			NotExpectedContent: []string{`"step_id":"offer"`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupFlakyStepsApp(t)
+				seedStepOutcomes(t, app, "login", "success", "failed", "success", "failed")
+				seedStepOutcomes(t, app, "offer", "success", "success", "success", "success", "failed")
+				return app
+			},
+		},
+		{
+			Name:            "other yaml revision has no flaky steps",
+			Method:          http.MethodGet,
+			URL:             "/api/pipeline/flaky-steps?pipeline_identifier=usera-s-organization/pipeline123&yaml_hash=hash-2",
+			Headers:         map[string]string{"Credimi-Api-Key": "internal-test-api-key"},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"flaky_steps":[]`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupFlakyStepsApp(t)
+				seedStepOutcomes(t, app, "login", "success", "failed", "success", "failed")
+				return app
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -14,12 +14,14 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
 	"github.com/pocketbase/dbx"
@@ -27,9 +29,10 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
+	"go.temporal.io/sdk/client"
 )
 
-var PipelineRoutes routing.RouteGroup = routing.RouteGroup{
+var PipelinePublicRoutes routing.RouteGroup = routing.RouteGroup{
 	BaseURL:                "/api/pipeline",
 	AuthenticationRequired: true,
 	Middlewares: []*hook.Handler[*core.RequestEvent]{
@@ -37,71 +40,129 @@ var PipelineRoutes routing.RouteGroup = routing.RouteGroup{
 	},
 	Routes: []routing.RouteDefinition{
 		{
-			Method:        http.MethodPost,
-			Path:          "/queue",
-			Handler:       HandlePipelineQueueEnqueue,
-			RequestSchema: PipelineQueueInput{},
-			Description:   "Queue a pipeline workflow for the runner semaphore",
+			Method:         http.MethodPost,
+			Path:           "/queue",
+			OperationID:    "pipeline.queue",
+			Permission:     apikey.PermissionPipelinesRun,
+			Handler:        HandlePipelineQueueEnqueue,
+			RequestSchema:  PipelineQueueInput{},
+			ResponseSchema: PipelineQueueResponse{},
+			Description:    "Queue a pipeline workflow for the runner semaphore",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/queue/{ticket}",
+			OperationID:    "pipeline.queueStatus",
+			Permission:     apikey.PermissionPipelinesRead,
+			Handler:        HandlePipelineQueueStatus,
+			ResponseSchema: PipelineQueueResponse{},
+			Description:    "Get queued pipeline status by ticket",
+		},
+		{
+			Method:         http.MethodDelete,
+			Path:           "/queue/{ticket}",
+			OperationID:    "pipeline.queueCancel",
+			Permission:     apikey.PermissionPipelinesRun,
+			Handler:        HandlePipelineQueueCancel,
+			ResponseSchema: PipelineQueueResponse{},
+			Description:    "Cancel a queued pipeline ticket",
+		},
+		{
+			Method:              http.MethodGet,
+			Path:                "/executions/{id}/{workflow_id}/{run_id}/events",
+			OperationID:         "pipeline.executionEvents",
+			Permission:          apikey.PermissionResultsRead,
+			Handler:             HandleStreamPipelineExecutionEvents,
+			ResponseSchema:      pipeline.ProgressEvent{},
+			ResponseContentType: routing.ContentTypeEventStream,
+			QuerySearchAttributes: []routing.QuerySearchAttribute{
+				{
+					Name:        "after",
+					Description: "Resume after this event sequence number; Last-Event-ID wins",
+				},
+			},
+			Description: "Stream pipeline execution progress as server-sent events",
+		},
+	},
+}
+
+var PipelineRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/pipeline",
+	AuthenticationRequired: true,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
 		{
 			Method:         http.MethodPost,
 			Path:           "/run-wallet-apk",
+			Permission:     apikey.PermissionPipelinesRun,
 			Handler:        HandlePipelineRunWalletAPK,
 			ResponseSchema: PipelineRunWalletAPKResponse{},
 			Description:    "Create a temporary wallet APK version and queue a one-off pipeline run",
 			Middlewares: []*hook.Handler[*core.RequestEvent]{
 				apis.BodyLimit(1000 << 20),
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
 			},
 		},
 		{
 			Method:         http.MethodPost,
 			Path:           "/run-issuer",
+			Permission:     apikey.PermissionPipelinesRun,
 			Handler:        HandlePipelineRunIssuer,
 			ResponseSchema: PipelineRunIssuerResponse{},
 			Description:    "Create temporary issuer credentials and queue a one-off pipeline run",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
 		{
 			Method:         http.MethodPost,
 			Path:           "/run-verifier",
+			Permission:     apikey.PermissionPipelinesRun,
 			Handler:        HandlePipelineRunVerifier,
 			ResponseSchema: PipelineRunVerifierResponse{},
 			Description:    "Create temporary verifier use cases and queue a one-off pipeline run",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
 		{
-			Method:      http.MethodGet,
-			Path:        "/queue/{ticket}",
-			Handler:     HandlePipelineQueueStatus,
-			Description: "Get queued pipeline status by ticket",
-		},
-		{
-			Method:      http.MethodDelete,
-			Path:        "/queue/{ticket}",
-			Handler:     HandlePipelineQueueCancel,
-			Description: "Cancel a queued pipeline ticket",
-		},
-		{
-			Method:  http.MethodGet,
-			Path:    "/list-executions",
-			Handler: HandleListPipelineExecutionOverview,
+			Method:     http.MethodGet,
+			Path:       "/list-executions",
+			Permission: apikey.PermissionResultsRead,
+			Handler:    HandleListPipelineExecutionOverview,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "/list-executions/{id}",
-			Handler: HandleListPipelineExecutionHistory,
+			Method:     http.MethodGet,
+			Path:       "/list-executions/{id}",
+			Permission: apikey.PermissionResultsRead,
+			Handler:    HandleListPipelineExecutionHistory,
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/executions/{id}/{workflow_id}/{run_id}",
+			Permission:  apikey.PermissionResultsRead,
 			Handler:     HandleGetPipelineExecution,
 			Description: "Get one pipeline execution with its child workflows",
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/execute",
+			Permission:  apikey.PermissionPipelinesRun,
 			Handler:     HandlePipelineExecute,
 			Description: "Execute a pipeline synchronously and wait for result",
 			Middlewares: []*hook.Handler[*core.RequestEvent]{
 				middlewares.OptionalAuthOrAPIKey(),
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
 			},
 			ExcludedMiddlewares: []string{
 				middlewares.RequireAuthOrAPIKeyMiddlewareID,
@@ -120,6 +181,7 @@ var PipelineTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:      http.MethodPost,
 			Path:        "/store-step-screenshots",
+			Permission:  apikey.PermissionResultsWrite,
 			Handler:     HandleStorePipelineStepScreenshots,
 			Description: "Store Maestro screenshots produced by one pipeline step",
 			Middlewares: []*hook.Handler[*core.RequestEvent]{
@@ -136,6 +198,27 @@ var PipelineTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 				middlewares.RequireInternalAdminAPIKey(),
 			},
 		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/step-outcomes",
+			Handler:        HandleRecordPipelineStepOutcomes,
+			RequestSchema:  PipelineStepOutcomesInput{},
+			ResponseSchema: PipelineStepOutcomesResponse{},
+			Description:    "Record the step outcomes of a pipeline run",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/flaky-steps",
+			Handler:        HandleGetPipelineFlakySteps,
+			ResponseSchema: PipelineFlakyStepsResponse{},
+			Description:    "Get the steps of a pipeline flagged as flaky by their outcome history",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
 		{
 			Method:         http.MethodPost,
 			Path:           "/mobile-flow",
@@ -290,6 +373,8 @@ type PipelineResultInput struct {
 	WorkflowID string `json:"workflow_id"`
 	RunID      string `json:"run_id"`
 	Type       string `json:"type,omitempty"`
+	// TicketID is the run ticket granted to start a queued run.
+	TicketID string `json:"ticket_id,omitempty"`
 }
 
 type PipelineResultEvidenceInput struct {
@@ -560,6 +645,15 @@ func HandleSetPipelineExecutionResults() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		if input.TicketID != "" {
+			emitWebhookEvent(e.App, owner.Id, webhook.EventRunTicketGranted, map[string]any{
+				"ticket_id":   input.TicketID,
+				"pipeline_id": pipeline.Id,
+				"result_id":   record.Id,
+				"workflow_id": input.WorkflowID,
+				"run_id":      input.RunID,
+			})
+		}
 		return e.JSON(http.StatusOK, record.FieldsData())
 	}
 }
@@ -592,7 +686,15 @@ func resolvePipelineExecutionScope(
 			"missing pipeline ID in path parameter",
 		)
 	}
-
+	// Checked before the lookup, so that a restricted key learns nothing
+	// about the other pipelines.
+	if apiErr := middlewares.RequireAPIKeyResource(
+		e,
+		apikey.ResourcePipelines,
+		pipelineID,
+	); apiErr != nil {
+		return nil, apiErr
+	}
 	organization, err := pbutils.GetUserOrganization(e.App, e.Auth.Id)
 	if err != nil {
 		return nil, apierror.New(
@@ -632,6 +734,13 @@ func resolvePipelineExecutionScope(
 	}
 
 	pipelineRecord := pipelineRecords[0]
+	if apiErr := authorizePipelineAccess(
+		e,
+		pipelineRecord,
+		apikey.PermissionResultsRead,
+	); apiErr != nil {
+		return nil, apiErr
+	}
 	pipelinePath, err := canonify.BuildPath(
 		e.App,
 		pipelineRecord,
@@ -788,76 +897,108 @@ func listPipelineExecutionHistoryPage(
 	return summaries, nil
 }
 
-// HandleGetPipelineExecution returns one exact pipeline execution and its child workflows.
-func HandleGetPipelineExecution() func(*core.RequestEvent) error {
-	return func(e *core.RequestEvent) error {
-		scope, apiErr := resolvePipelineExecutionScope(
-			e,
-			e.Request.PathValue("id"),
+// resolvedPipelineExecution is a pipeline run the caller is allowed to read.
+// Result is nil when the run has no pipeline_results record for the pipeline.
+type resolvedPipelineExecution struct {
+	Scope     *pipelineExecutionScope
+	Client    client.Client
+	Execution *WorkflowExecution
+	Ref       workflowExecutionRef
+	Result    *core.Record
+}
+
+// resolvePipelineExecution checks that the {workflow_id}/{run_id} path values
+// name a pipeline workflow run of the {id} pipeline visible to the caller.
+func resolvePipelineExecution(
+	e *core.RequestEvent,
+) (*resolvedPipelineExecution, *apierror.APIError) {
+	scope, apiErr := resolvePipelineExecutionScope(
+		e,
+		e.Request.PathValue("id"),
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	workflowID := strings.TrimSpace(e.Request.PathValue("workflow_id"))
+	runID := strings.TrimSpace(e.Request.PathValue("run_id"))
+	if workflowID == "" || runID == "" {
+		return nil, apierror.New(
+			http.StatusBadRequest,
+			"workflow",
+			"workflow ID and run ID are required",
+			"missing workflow_id or run_id path parameter",
 		)
-		if apiErr != nil {
-			return apiErr
-		}
+	}
 
-		workflowID := strings.TrimSpace(e.Request.PathValue("workflow_id"))
-		runID := strings.TrimSpace(e.Request.PathValue("run_id"))
-		if workflowID == "" || runID == "" {
-			return apierror.New(
-				http.StatusBadRequest,
-				"workflow",
-				"workflow ID and run ID are required",
-				"missing workflow_id or run_id path parameter",
-			)
-		}
+	temporalClient, err := pipelineTemporalClient(scope.Namespace)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"temporal",
+			"unable to create temporal client",
+			err.Error(),
+		)
+	}
 
-		temporalClient, err := pipelineTemporalClient(scope.Namespace)
-		if err != nil {
-			return apierror.New(
-				http.StatusInternalServerError,
-				"temporal",
-				"unable to create temporal client",
-				err.Error(),
-			)
-		}
+	execution, apiErr := describeWorkflowExecution(
+		e.Request.Context(),
+		temporalClient,
+		workflowID,
+		runID,
+	)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if execution.Type.Name != pipeline.NewPipelineWorkflow().Name() {
+		return nil, pipelineExecutionNotFound()
+	}
 
-		execution, apiErr := describeWorkflowExecution(
-			e.Request.Context(),
-			temporalClient,
-			workflowID,
-			runID,
+	ref := workflowExecutionRef{WorkflowID: workflowID, RunID: runID}
+	resultRecords, err := fetchPipelineResultRecords(
+		e.App,
+		scope.Organization.Id,
+		[]workflowExecutionRef{ref},
+	)
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusInternalServerError,
+			"database",
+			"failed to fetch pipeline results",
+			err.Error(),
 		)
+	}
+	resultRecord := resultRecords[ref]
+	identifier := pipelineIdentifierFromSearchAttributes(execution.SearchAttributes)
+	matchesSearchAttribute := identifier == scope.PipelineIdentifier
+	matchesResultRecord := resultRecord != nil &&
+		resultRecord.GetString("pipeline") == scope.Pipeline.Id
+	if !matchesSearchAttribute && !matchesResultRecord {
+		return nil, pipelineExecutionNotFound()
+	}
+	if !matchesResultRecord {
+		resultRecord = nil
+	}
+
+	return &resolvedPipelineExecution{
+		Scope:     scope,
+		Client:    temporalClient,
+		Execution: execution,
+		Ref:       ref,
+		Result:    resultRecord,
+	}, nil
+}
+
+// HandleGetPipelineExecution returns one exact pipeline execution and its child workflows.
+func HandleGetPipelineExecution() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		resolved, apiErr := resolvePipelineExecution(e)
 		if apiErr != nil {
 			return apiErr
 		}
-		if execution.Type.Name != pipeline.NewPipelineWorkflow().Name() {
-			return pipelineExecutionNotFound()
-		}
-
-		ref := workflowExecutionRef{WorkflowID: workflowID, RunID: runID}
-		resultRecords, err := fetchPipelineResultRecords(
-			e.App,
-			scope.Organization.Id,
-			[]workflowExecutionRef{ref},
-		)
-		if err != nil {
-			return apierror.New(
-				http.StatusInternalServerError,
-				"database",
-				"failed to fetch pipeline results",
-				err.Error(),
-			)
-		}
-		resultRecord := resultRecords[ref]
-		identifier := pipelineIdentifierFromSearchAttributes(execution.SearchAttributes)
-		matchesSearchAttribute := identifier == scope.PipelineIdentifier
-		matchesResultRecord := resultRecord != nil &&
-			resultRecord.GetString("pipeline") == scope.Pipeline.Id
-		if !matchesSearchAttribute && !matchesResultRecord {
-			return pipelineExecutionNotFound()
-		}
-		if !matchesResultRecord {
-			resultRecord = nil
-		}
+		scope := resolved.Scope
+		temporalClient := resolved.Client
+		ref := resolved.Ref
 
 		childrenByParent, err := getChildWorkflowsByParents(
 			e.Request.Context(),
@@ -883,9 +1024,9 @@ func HandleGetPipelineExecution() func(*core.RequestEvent) error {
 			e.Request.Context(),
 			scope.Pipeline,
 			scope.PipelineIdentifier,
-			execution,
+			resolved.Execution,
 			childrenByParent[ref],
-			resultRecord,
+			resolved.Result,
 		)
 		if err != nil {
 			return apierror.New(
@@ -1018,6 +1159,7 @@ func HandleListPipelineExecutionOverview() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		pipelineRecords = filterAPIKeyResources(e, apikey.ResourcePipelines, pipelineRecords)
 
 		if len(pipelineRecords) == 0 {
 			return e.JSON(http.StatusOK, map[string][]*WorkflowExecutionSummary{})
@@ -14,9 +14,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
@@ -52,6 +55,7 @@ func setupPipelineStartApp(t testing.TB) *tests.TestApp {
 
 	canonify.RegisterCanonifyHooks(app)
 	PipelineRoutes.Add(app)
+	PipelinePublicRoutes.Add(app)
 
 	return app
 }
@@ -205,6 +209,9 @@ func TestSetPipelineExecutionResultsIdempotent(t *testing.T) {
 	)
 	record.Set("yaml", "example-yaml-content")
 	require.NoError(t, app.Save(record))
+	hook := seedWebhook(t, app, orgID, "https://example.org/hooks")
+	hook.Set("events", []webhook.Event{webhook.EventRunTicketGranted})
+	require.NoError(t, app.Save(hook))
 
 	baseRouter, err := apis.NewRouter(app)
 	require.NoError(t, err)
@@ -214,7 +221,9 @@ func TestSetPipelineExecutionResultsIdempotent(t *testing.T) {
 		mux, err := e.Router.BuildMux()
 		require.NoError(t, err)
 
-		body := `{"owner":"usera-s-organization","pipeline_id":"usera-s-organization/pipeline123","workflow_id":"workflow-xyz","run_id":"run-001"}`
+		body := `{"owner":"usera-s-organization",` +
+			`"pipeline_id":"usera-s-organization/pipeline123",` +
+			`"workflow_id":"workflow-xyz","run_id":"run-001","ticket_id":"ticket-1"}`
 		for i := 0; i < 2; i++ {
 			req := httptest.NewRequest(
 				http.MethodPost,
@@ -242,6 +251,13 @@ func TestSetPipelineExecutionResultsIdempotent(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, records, 1)
 
+		granted, err := app.FindAllRecords(
+			webhook.DeliveryCollection,
+			dbx.HashExp{"event": string(webhook.EventRunTicketGranted)},
+		)
+		require.NoError(t, err)
+		require.Len(t, granted, 1)
+
 		return nil
 	})
 	require.NoError(t, serveErr)
@@ -1642,3 +1658,142 @@ func TestBuildChildWorkflowParentQuery(t *testing.T) {
 		query,
 	)
 }
+
+func TestPipelineExecutionRoutesEnforceAPIKeyResources(t *testing.T) {
+	app := setupPipelineStartApp(t)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	organization, err := pbutils.GetUserOrganization(app, authRecord.Id)
+	require.NoError(t, err)
+	allowed := createPipelineExecutionTestPipeline(t, app, organization.Id)
+	collection, err := app.FindCollectionByNameOrId("pipelines")
+	require.NoError(t, err)
+	other := core.NewRecord(collection)
+	other.Set("owner", organization.Id)
+	other.Set("name", "Other pipeline")
+	other.Set("canonified_name", "other-pipeline")
+	other.Set("description", "Not granted to the key")
+	other.Set("yaml", "name: Other pipeline")
+	require.NoError(t, app.Save(other))
+
+	seedHandlerAPIKey(t, app, handlerAPIKeySeed{
+		Plaintext: "restricted-pipeline-key",
+		UserID:    authRecord.Id,
+		Scope:     "user",
+		Grant: apikey.Grant{
+			Resources: map[apikey.Resource][]string{
+				apikey.ResourcePipelines: {allowed.Id},
+			},
+		},
+	})
+	call := func(
+		handler func() func(*core.RequestEvent) error,
+		req *http.Request,
+	) *httptest.ResponseRecorder {
+		t.Helper()
+		req.Header.Set("Credimi-Api-Key", "restricted-pipeline-key")
+		rec := httptest.NewRecorder()
+		e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+		setHandlerNext(e, func() error { return nil })
+		require.NoError(t, middlewares.RequireAuthOrAPIKey().Func(e))
+		requireHandlerErrorHandled(t, rec, handler()(e))
+		return rec
+	}
+
+	t.Run("overview lists only the granted pipelines", func(t *testing.T) {
+		originalListQueued := pipelineListQueuedRuns
+		originalTemporalClient := pipelineTemporalClient
+		t.Cleanup(func() {
+			pipelineListQueuedRuns = originalListQueued
+			pipelineTemporalClient = originalTemporalClient
+		})
+		pipelineListQueuedRuns = func(
+			context.Context,
+			string,
+		) (map[string]QueuedPipelineRunAggregate, error) {
+			return map[string]QueuedPipelineRunAggregate{}, nil
+		}
+		mockClient := &temporalmocks.Client{}
+		mockClient.On("ListWorkflow", mock.Anything, mock.Anything).
+			Return(&workflowservice.ListWorkflowExecutionsResponse{
+				Executions: []*workflow.WorkflowExecutionInfo{
+					buildPipelineExecutionInfo(
+						"wf-allowed",
+						"run-1",
+						pipelineIdentifierForTest(t, app, allowed),
+					),
+					buildPipelineExecutionInfo(
+						"wf-other",
+						"run-1",
+						pipelineIdentifierForTest(t, app, other),
+					),
+				},
+			}, nil)
+		mockClient.On(
+			"GetWorkflowHistory",
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			false,
+			enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT,
+		).Return(&fakeHistoryIterator{events: []*historypb.HistoryEvent{}}, nil).Maybe()
+		pipelineTemporalClient = func(string) (client.Client, error) { return mockClient, nil }
+
+		rec := call(
+			HandleListPipelineExecutionOverview,
+			httptest.NewRequest(http.MethodGet, "/api/pipeline/list-executions", nil),
+		)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response map[string][]pipelineWorkflowSummary
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Contains(t, response, allowed.Id)
+		require.NotContains(t, response, other.Id)
+	})
+
+	executionRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", other.Id)
+		req.SetPathValue("workflow_id", "wf-other")
+		req.SetPathValue("run_id", "run-1")
+		return req
+	}
+	refused := []struct {
+		name    string
+		handler func() func(*core.RequestEvent) error
+		req     *http.Request
+	}{
+		{
+			name:    "history of another pipeline",
+			handler: HandleListPipelineExecutionHistory,
+			req:     executionRequest(),
+		},
+		{
+			name:    "execution of another pipeline",
+			handler: HandleGetPipelineExecution,
+			req:     executionRequest(),
+		},
+		{
+			name:    "events of another pipeline",
+			handler: HandleStreamPipelineExecutionEvents,
+			req:     executionRequest(),
+		},
+		{
+			name:    "inline YAML execution",
+			handler: HandlePipelineExecute,
+			req: httptest.NewRequest(
+				http.MethodPost,
+				"/api/pipeline/execute",
+				strings.NewReader("name: inline\nsteps: []\n"),
+			),
+		},
+	}
+	for _, tc := range refused {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := call(tc.handler, tc.req)
+			require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+			require.Contains(t, rec.Body.String(), "api_key_resource_not_allowed")
+		})
+	}
+}
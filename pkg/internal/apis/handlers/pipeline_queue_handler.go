@@ -14,12 +14,16 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/forkbombeu/credimi/pkg/internal/runqueue"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
@@ -173,6 +177,16 @@ func enqueuePipelineRun(
 	e *core.RequestEvent,
 	runContext pipelineQueueRunContext,
 ) (PipelineQueueResponse, *apierror.APIError) {
+	if runContext.pipelineRecord != nil {
+		if apiErr := authorizePipelineAccess(
+			e,
+			runContext.pipelineRecord,
+			apikey.PermissionPipelinesRun,
+		); apiErr != nil {
+			return PipelineQueueResponse{}, apiErr
+		}
+	}
+
 	namespace := runContext.organizationRecord.GetString("canonified_name")
 	if namespace == "" {
 		return PipelineQueueResponse{}, apierror.New(
@@ -387,6 +401,18 @@ func enqueuePipelineRun(
 		e.App.Settings().Meta.AppURL,
 		runContext.pipelineIdentifier,
 	)
+	emitWebhookEvent(
+		e.App,
+		runContext.organizationRecord.Id,
+		webhook.EventRunTicketQueued,
+		map[string]any{
+			"ticket_id":   ticketID,
+			"pipeline_id": runContext.pipelineRecord.Id,
+			"runner_ids":  runnerIDs,
+			"status":      response.Status,
+			"position":    position,
+		},
+	)
 	return response, nil
 }
 
@@ -632,6 +658,10 @@ func startPipelineFromQueue(
 	setPipelineRunType(record, coll, runType)
 
 	result, err = startPipelineWorkflow(yaml, config, memo, pipelineIdentifier)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return result, middlewares.QuotaExceeded(e, exceeded)
+	}
 	if err != nil {
 		return result, apierror.New(
 			http.StatusInternalServerError,
@@ -13,6 +13,7 @@ import (
 
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
 	"github.com/pocketbase/dbx"
@@ -39,6 +40,7 @@ func setupPipelineQueueApp(t testing.TB) *tests.TestApp {
 
 	canonify.RegisterCanonifyHooks(app)
 	PipelineRoutes.Add(app)
+	PipelinePublicRoutes.Add(app)
 
 	return app
 }
@@ -457,6 +459,85 @@ func TestPipelineQueueEnqueue_StartsNonRunnerPipeline(t *testing.T) {
 	require.Equal(t, false, capturedMemo[pipelineinternal.PublishedMemoKey])
 }
 
+func TestPipelineQueueEnqueue_EnforcesRunQuotas(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+
+	origStart := startPipelineWorkflow
+	t.Cleanup(func() {
+		startPipelineWorkflow = origStart
+	})
+	var startErr error
+	startPipelineWorkflow = func(
+		yaml string,
+		config map[string]any,
+		memo map[string]any,
+		pipelineIdentifier string,
+	) (workflowengine.WorkflowResult, error) {
+		if startErr != nil {
+			return workflowengine.WorkflowResult{}, startErr
+		}
+		return workflowengine.WorkflowResult{
+			WorkflowID:    "wf-quota",
+			WorkflowRunID: "run-quota",
+		}, nil
+	}
+
+	nonRunnerYaml := "name: test\nsteps: []\n"
+	app := setupPipelineQueueAppWithPipeline(t, orgID, nonRunnerYaml)
+	defer app.Cleanup()
+	orgRecord, err := app.FindRecordById("organizations", orgID)
+	require.NoError(t, err)
+	orgRecord.Set(quota.FieldConcurrentRuns, 4)
+	orgRecord.Set(quota.FieldRunsPerDay, 1)
+	require.NoError(t, app.Save(orgRecord))
+
+	baseRouter, err := apis.NewRouter(app)
+	require.NoError(t, err)
+
+	serveEvent := &core.ServeEvent{App: app, Router: baseRouter}
+	serveErr := app.OnServe().Trigger(serveEvent, func(e *core.ServeEvent) error {
+		mux, err := e.Router.BuildMux()
+		require.NoError(t, err)
+
+		enqueue := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/api/pipeline/queue",
+				jsonBody(map[string]any{
+					"pipeline_identifier": "usera-s-organization/pipeline123",
+					"yaml":                nonRunnerYaml,
+				}),
+			)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("content-type", "application/json")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec
+		}
+
+		startErr = &quota.ExceededError{Quota: quota.ConcurrentRuns, Limit: 4, Used: 4}
+		rec := enqueue()
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Contains(t, rec.Body.String(), "concurrent_runs quota exceeded")
+
+		startErr = nil
+		rec = enqueue()
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = enqueue()
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Contains(t, rec.Body.String(), "runs_per_day quota exceeded")
+		require.NotEmpty(t, rec.Header().Get("Retry-After"))
+		return nil
+	})
+	require.NoError(t, serveErr)
+}
+
 func TestPipelineQueueStatusReturnsRunURL(t *testing.T) {
 	userRecord, err := getUserRecordFromName("userA")
 	require.NoError(t, err)
@@ -8,11 +8,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
@@ -75,6 +78,15 @@ type DeletePipelineResultFilesResponse struct {
 	RecordsWithFiles int                      `json:"records_with_files"`
 	UpdatedRecords   int                      `json:"updated_records"`
 	DeletedFiles     PipelineResultFileCounts `json:"deleted_files"`
+
+	// cleared holds the results cleared per owner organization, for the
+	// audit log.
+	cleared map[string]*retentionClearedResults
+}
+
+type retentionClearedResults struct {
+	Results []string                 `json:"results"`
+	Files   PipelineResultFileCounts `json:"files"`
 }
 
 type SchedulePipelineRetentionRequest struct {
@@ -128,6 +140,20 @@ func HandleDeletePipelineResultFiles() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		for _, owner := range slices.Sorted(maps.Keys(response.cleared)) {
+			cleared := response.cleared[owner]
+			recordAudit(e, audit.Entry{
+				Organization: owner,
+				Action:       audit.ActionRetentionFilesDeleted,
+				ResourceType: "pipeline_results",
+				Details: map[string]any{
+					"older_than_days": input.OlderThanDays,
+					"cutoff":          response.Cutoff,
+					"results":         cleared.Results,
+					"deleted_files":   cleared.Files,
+				},
+			})
+		}
 
 		return e.JSON(http.StatusOK, response)
 	}
@@ -421,6 +447,19 @@ func deletePipelineResultFilesOlderThan(
 				return response, fmt.Errorf("save pipeline_result %s: %w", record.Id, err)
 			}
 			response.UpdatedRecords++
+
+			if response.cleared == nil {
+				response.cleared = map[string]*retentionClearedResults{}
+			}
+			owner := record.GetString("owner")
+			if response.cleared[owner] == nil {
+				response.cleared[owner] = &retentionClearedResults{Results: []string{}}
+			}
+			response.cleared[owner].Results = append(response.cleared[owner].Results, record.Id)
+			response.cleared[owner].Files = addPipelineResultFileCounts(
+				response.cleared[owner].Files,
+				counts,
+			)
 		}
 
 		if stop {
@@ -209,6 +209,12 @@ func TestDeletePipelineResultFilesClearsReport(t *testing.T) {
 	require.Equal(t, 1, response.UpdatedRecords)
 	require.Equal(t, 1, response.DeletedFiles.Report)
 	require.Equal(t, 1, response.DeletedFiles.Total)
+	require.Equal(t, map[string]*retentionClearedResults{
+		oldRecord.GetString("owner"): {
+			Results: []string{oldRecord.Id},
+			Files:   PipelineResultFileCounts{Report: 1, Total: 1},
+		},
+	}, response.cleared)
 
 	reloaded, err := app.FindRecordById("pipeline_results", oldRecord.Id)
 	require.NoError(t, err)
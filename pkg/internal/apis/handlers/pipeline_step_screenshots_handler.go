@@ -59,6 +59,9 @@ func HandleStorePipelineStepScreenshots() func(*core.RequestEvent) error {
 		); apiErr != nil {
 			return apiErr
 		}
+		if apiErr := checkStorageQuota(e, resultRecord.GetString("owner")); apiErr != nil {
+			return apiErr
+		}
 
 		filenames, urls, apiErr := storePipelineStepScreenshotFiles(
 			e,
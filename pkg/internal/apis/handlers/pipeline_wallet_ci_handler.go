@@ -16,7 +16,9 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
@@ -310,6 +312,13 @@ func resolvePipelineRunWalletAPKContext(
 	if apiErr != nil {
 		return pipelineRunWalletAPKContext{}, apiErr
 	}
+	if apiErr := middlewares.RequireAPIKeyResource(
+		e,
+		apikey.ResourceWallets,
+		walletRecord.Id,
+	); apiErr != nil {
+		return pipelineRunWalletAPKContext{}, apiErr
+	}
 	apkFile, apiErr := resolvePipelineRunWalletAPKFile(e.Request.Context(), input)
 	if apiErr != nil {
 		return pipelineRunWalletAPKContext{}, apiErr
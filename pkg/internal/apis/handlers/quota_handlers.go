@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/pocketbase/pocketbase/core"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+)
+
+// quotaTemporalClient is stubbed in unit tests.
+var quotaTemporalClient = temporalclient.GetTemporalClientWithNamespace
+
+type OrganizationQuotaResponse struct {
+	Organization string       `json:"organization"`
+	Limits       quota.Limits `json:"limits"`
+	Usage        quota.Usage  `json:"usage"`
+}
+
+func HandleGetMyOrganizationQuota() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgRecord, err := pbutils.GetUserOrganization(e.App, e.Auth.Id)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organizations",
+				"unable to get user organization",
+				err.Error(),
+			)
+		}
+		return writeOrganizationQuota(e, orgRecord)
+	}
+}
+
+// HandleGetOrganizationQuota answers with the quota of the organization
+// named by id or by namespace, which is how the runner queue looks it up.
+func HandleGetOrganizationQuota() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgRecord, apiErr := findQuotaOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		return writeOrganizationQuota(e, orgRecord)
+	}
+}
+
+// findQuotaOrganization returns the organization named by id or by
+// namespace in the organization path value.
+func findQuotaOrganization(e *core.RequestEvent) (*core.Record, *apierror.APIError) {
+	organization := e.Request.PathValue("organization")
+	orgRecord, err := e.App.FindRecordById("organizations", organization)
+	if errors.Is(err, sql.ErrNoRows) {
+		orgRecord, err = e.App.FindFirstRecordByData("organizations", "canonified_name", organization)
+	}
+	if err != nil {
+		return nil, apierror.New(
+			http.StatusNotFound,
+			"organizations",
+			"organization not found",
+			err.Error(),
+		)
+	}
+	return orgRecord, nil
+}
+
+func writeOrganizationQuota(e *core.RequestEvent, orgRecord *core.Record) error {
+	usage, err := organizationQuotaUsage(e.App, orgRecord, time.Now())
+	if err != nil {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"quota",
+			"failed_to_read_quota_usage",
+			err.Error(),
+		)
+	}
+	return e.JSON(http.StatusOK, OrganizationQuotaResponse{
+		Organization: orgRecord.Id,
+		Limits:       quota.LimitsFromRecord(orgRecord),
+		Usage:        usage,
+	})
+}
+
+func organizationQuotaUsage(
+	app core.App,
+	orgRecord *core.Record,
+	now time.Time,
+) (quota.Usage, error) {
+	usage := quota.Usage{RequestsThisMinute: quota.Requests.Used(orgRecord.Id, now)}
+
+	var err error
+	if usage.RunsToday, err = quota.RunsToday(app, orgRecord.Id, now); err != nil {
+		return quota.Usage{}, err
+	}
+	if usage.StorageBytes, err = quota.StorageBytes(app, orgRecord.Id); err != nil {
+		return quota.Usage{}, err
+	}
+	if usage.ConcurrentRuns, err = quota.RunningRuns(app, orgRecord.Id); err != nil {
+		return quota.Usage{}, err
+	}
+	return usage, nil
+}
+
+// quotaRunGrace gives a run that just reserved its concurrent run the time
+// to start before its workflow is looked up in Temporal.
+const quotaRunGrace = time.Minute
+
+type OrganizationRunInput struct {
+	WorkflowID string `json:"workflow_id" validate:"required"`
+}
+
+// HandleReserveOrganizationRun reserves one of the concurrent runs of the
+// organization for a pipeline run about to start, or confirms the
+// reservation of a run that already holds one.
+func HandleReserveOrganizationRun() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		input, err := routing.GetValidatedInput[OrganizationRunInput](e)
+		if err != nil {
+			return err
+		}
+		orgRecord, apiErr := findQuotaOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+
+		err = reserveOrganizationRun(e.Request.Context(), e.App, orgRecord, input.WorkflowID)
+		var exceeded *quota.ExceededError
+		if errors.As(err, &exceeded) {
+			return middlewares.QuotaExceeded(e, exceeded)
+		}
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"quota",
+				"failed_to_reserve_run",
+				err.Error(),
+			)
+		}
+		return e.JSON(http.StatusOK, map[string]string{"workflow_id": input.WorkflowID})
+	}
+}
+
+// HandleReleaseOrganizationRun gives back the concurrent run of a pipeline
+// run that ended or could not start.
+func HandleReleaseOrganizationRun() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgRecord, apiErr := findQuotaOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		workflowID := e.Request.PathValue("workflowId")
+		if err := quota.ReleaseConcurrentRun(e.App, orgRecord.Id, workflowID); err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"quota",
+				"failed_to_release_run",
+				err.Error(),
+			)
+		}
+		return e.JSON(http.StatusOK, map[string]string{"workflow_id": workflowID})
+	}
+}
+
+// reserveOrganizationRun reserves a concurrent run for workflowID. When the
+// quota is exceeded, the runs whose workflow ended without releasing theirs
+// are released before trying once more.
+func reserveOrganizationRun(
+	ctx context.Context,
+	app core.App,
+	orgRecord *core.Record,
+	workflowID string,
+) error {
+	limits := quota.LimitsFromRecord(orgRecord)
+	err := quota.ReserveConcurrentRun(app, orgRecord.Id, workflowID, limits)
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return err
+	}
+
+	c, err := quotaTemporalClient(orgRecord.GetString("canonified_name"))
+	if err != nil {
+		return err
+	}
+	running := func(workflowID string) (bool, error) {
+		resp, err := c.DescribeWorkflowExecution(ctx, workflowID, "")
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return resp.GetWorkflowExecutionInfo().GetStatus() ==
+			enums.WORKFLOW_EXECUTION_STATUS_RUNNING, nil
+	}
+	before := time.Now().Add(-quotaRunGrace)
+	if err := quota.PruneConcurrentRuns(app, orgRecord.Id, before, running); err != nil {
+		return err
+	}
+	return quota.ReserveConcurrentRun(app, orgRecord.Id, workflowID, limits)
+}
+
+// checkStorageQuota refuses new result files once the organization owning
+// them uses all of its storage.
+func checkStorageQuota(e *core.RequestEvent, ownerID string) *apierror.APIError {
+	if ownerID == "" {
+		return nil
+	}
+	orgRecord, err := e.App.FindRecordById("organizations", ownerID)
+	if err != nil {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"organization",
+			"unable to get organization record",
+			err.Error(),
+		)
+	}
+	limits := quota.LimitsFromRecord(orgRecord)
+	if limits.StorageMB == 0 {
+		return nil
+	}
+
+	used, err := quota.StorageBytes(e.App, ownerID)
+	if err != nil {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"quota",
+			"failed_to_read_quota_usage",
+			err.Error(),
+		)
+	}
+	var exceeded *quota.ExceededError
+	if errors.As(limits.CheckStorage(used), &exceeded) {
+		return middlewares.QuotaExceeded(e, exceeded)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+// stubQuotaTemporalClient reports every workflow as ended with status.
+func stubQuotaTemporalClient(t *testing.T, status enums.WorkflowExecutionStatus) {
+	t.Helper()
+
+	original := quotaTemporalClient
+	t.Cleanup(func() {
+		quotaTemporalClient = original
+	})
+	mockClient := temporalmocks.NewClient(t)
+	mockClient.On("DescribeWorkflowExecution", mock.Anything, mock.Anything, "").
+		Return(&workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{Status: status},
+		}, nil).
+		Maybe()
+	quotaTemporalClient = func(string) (client.Client, error) {
+		return mockClient, nil
+	}
+}
+
+func TestOrganizationQuotaHandlers(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+
+	setupQuotaOrganization := func(app *tests.TestApp) {
+		orgRecord, err := app.FindRecordById("organizations", orgID)
+		require.NoError(t, err)
+		orgRecord.Set(quota.FieldConcurrentRuns, 3)
+		orgRecord.Set(quota.FieldRunsPerDay, 20)
+		require.NoError(t, app.Save(orgRecord))
+		require.NoError(t, quota.RecordRun(app, orgID, time.Now()))
+		for _, workflowID := range []string{"Pipeline-1", "Pipeline-2"} {
+			require.NoError(t, quota.ReserveConcurrentRun(app, orgID, workflowID, quota.Limits{}))
+		}
+	}
+	requireQuotaResponse := func(t testing.TB, _ *tests.TestApp, res *http.Response) {
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		var payload OrganizationQuotaResponse
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, orgID, payload.Organization)
+		require.Equal(t, quota.Limits{ConcurrentRuns: 3, RunsPerDay: 20}, payload.Limits)
+		require.Equal(t, int64(2), payload.Usage.ConcurrentRuns)
+		require.Equal(t, int64(1), payload.Usage.RunsToday)
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "get my organization quota",
+			Method: http.MethodGet,
+			URL:    "/api/organizations/my/quota",
+			Headers: map[string]string{
+				"Authorization": "Bearer " + token,
+			},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"limits", "usage"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupOrganizationApp(t)
+				setupQuotaOrganization(app)
+				return app
+			},
+			AfterTestFunc: requireQuotaResponse,
+		},
+		{
+			Name:   "get an organization quota with the internal API key",
+			Method: http.MethodGet,
+			URL:    "/api/organizations/" + orgID + "/quota",
+			Headers: map[string]string{
+				"Credimi-Api-Key": "internal-test-api-key",
+			},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"limits", "usage"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupOrganizationPublicApp(t)
+				setupQuotaOrganization(app)
+				return app
+			},
+			AfterTestFunc: requireQuotaResponse,
+		},
+		{
+			Name:   "get an organization quota by namespace with the internal API key",
+			Method: http.MethodGet,
+			URL:    "/api/organizations/usera-s-organization/quota",
+			Headers: map[string]string{
+				"Credimi-Api-Key": "internal-test-api-key",
+			},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"organization":"` + orgID + `"`, "limits"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := setupOrganizationPublicApp(t)
+				setupQuotaOrganization(app)
+				return app
+			},
+			AfterTestFunc: requireQuotaResponse,
+		},
+		{
+			Name:            "get an organization quota without API key",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/" + orgID + "/quota",
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{"api_key_required"},
+			TestAppFactory:  setupOrganizationPublicApp,
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestOrganizationRunsHandlers(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	setupRunsApp := func(t testing.TB, reserved ...string) *tests.TestApp {
+		app := setupOrganizationPublicApp(t)
+		orgRecord, err := app.FindRecordById("organizations", orgID)
+		require.NoError(t, err)
+		orgRecord.Set(quota.FieldConcurrentRuns, 2)
+		require.NoError(t, app.Save(orgRecord))
+		for _, workflowID := range reserved {
+			require.NoError(t, quota.ReserveConcurrentRun(app, orgID, workflowID, quota.Limits{}))
+		}
+		return app
+	}
+	requireRunning := func(want int64) func(testing.TB, *tests.TestApp, *http.Response) {
+		return func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+			running, err := quota.RunningRuns(app, orgID)
+			require.NoError(t, err)
+			require.Equal(t, want, running)
+		}
+	}
+	headers := map[string]string{"Credimi-Api-Key": "internal-test-api-key"}
+	runsURL := "/api/organizations/usera-s-organization/quota/runs"
+
+	stubQuotaTemporalClient(t, enums.WORKFLOW_EXECUTION_STATUS_RUNNING)
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "reserve a run",
+			Method:          http.MethodPost,
+			URL:             runsURL,
+			Headers:         headers,
+			Body:            jsonBody(map[string]any{"workflow_id": "Pipeline-2"}),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"workflow_id":"Pipeline-2"`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				return setupRunsApp(t, "Pipeline-1")
+			},
+			AfterTestFunc: requireRunning(2),
+		},
+		{
+			Name:            "confirm a reserved run when the quota is full",
+			Method:          http.MethodPost,
+			URL:             runsURL,
+			Headers:         headers,
+			Body:            jsonBody(map[string]any{"workflow_id": "Pipeline-2"}),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"workflow_id":"Pipeline-2"`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				return setupRunsApp(t, "Pipeline-1", "Pipeline-2")
+			},
+			AfterTestFunc: requireRunning(2),
+		},
+		{
+			Name:            "refuse a run when the quota is full",
+			Method:          http.MethodPost,
+			URL:             runsURL,
+			Headers:         headers,
+			Body:            jsonBody(map[string]any{"workflow_id": "Pipeline-3"}),
+			ExpectedStatus:  http.StatusTooManyRequests,
+			ExpectedContent: []string{"concurrent_runs quota exceeded: 2 of 2 used"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				return setupRunsApp(t, "Pipeline-1", "Pipeline-2")
+			},
+			AfterTestFunc: requireRunning(2),
+		},
+		{
+			Name:            "release a run",
+			Method:          http.MethodDelete,
+			URL:             runsURL + "/Pipeline-1",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"workflow_id":"Pipeline-1"`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				return setupRunsApp(t, "Pipeline-1", "Pipeline-2")
+			},
+			AfterTestFunc: requireRunning(1),
+		},
+		{
+			Name:            "reserve a run without API key",
+			Method:          http.MethodPost,
+			URL:             runsURL,
+			Body:            jsonBody(map[string]any{"workflow_id": "Pipeline-1"}),
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{"api_key_required"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				return setupRunsApp(t)
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+
+	// Runs whose workflow ended without releasing their reservation are
+	// released once they are past the grace period.
+	stubQuotaTemporalClient(t, enums.WORKFLOW_EXECUTION_STATUS_TERMINATED)
+	pruneScenario := tests.ApiScenario{
+		Name:            "reserve a run after releasing ended runs",
+		Method:          http.MethodPost,
+		URL:             runsURL,
+		Headers:         headers,
+		Body:            jsonBody(map[string]any{"workflow_id": "Pipeline-3"}),
+		ExpectedStatus:  http.StatusOK,
+		ExpectedContent: []string{`"workflow_id":"Pipeline-3"`},
+		TestAppFactory: func(t testing.TB) *tests.TestApp {
+			app := setupRunsApp(t, "Pipeline-1", "Pipeline-2")
+			_, err := app.DB().NewQuery(
+				"UPDATE quota_runs SET created = {:created} WHERE workflow_id = 'Pipeline-1'",
+			).Bind(dbx.Params{
+				"created": time.Now().Add(-time.Hour).UTC().Format(types.DefaultDateLayout),
+			}).Execute()
+			require.NoError(t, err)
+			return app
+		},
+		AfterTestFunc: requireRunning(2),
+	}
+	pruneScenario.Test(t)
+}
+
+func TestCheckStorageQuota(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	quota.RegisterStorageHooks(app)
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	newEvent := func() (*core.RequestEvent, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/wallet/store-pipeline-result", nil)
+		return &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}, rec
+	}
+
+	e, _ := newEvent()
+	require.Nil(t, checkStorageQuota(e, orgID))
+
+	orgRecord, err := app.FindRecordById("organizations", orgID)
+	require.NoError(t, err)
+	orgRecord.Set(quota.FieldStorageMB, 1)
+	require.NoError(t, app.Save(orgRecord))
+
+	e, _ = newEvent()
+	require.Nil(t, checkStorageQuota(e, orgID))
+
+	collection, err := app.FindCollectionByNameOrId("pipeline_results")
+	require.NoError(t, err)
+	result := core.NewRecord(collection)
+	result.Set("owner", orgID)
+	result.Set("workflow_id", "wf-storage")
+	result.Set("run_id", "run-storage")
+	video, err := filesystem.NewFileFromBytes(make([]byte, 1<<20), "run_result_video.mp4")
+	require.NoError(t, err)
+	result.Set("video_results", []any{video})
+	require.NoError(t, app.Save(result))
+
+	e, rec := newEvent()
+	apiErr := checkStorageQuota(e, orgID)
+	require.NotNil(t, apiErr)
+	require.Equal(t, http.StatusTooManyRequests, apiErr.Code)
+	require.Equal(t, "quota_exceeded", apiErr.Reason)
+	require.Empty(t, rec.Header().Get("Retry-After"))
+}
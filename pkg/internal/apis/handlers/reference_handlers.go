@@ -0,0 +1,393 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/google/uuid"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"go.temporal.io/api/serviceerror"
+	tclient "go.temporal.io/sdk/client"
+)
+
+var referenceTemporalClient = temporalclient.GetTemporalClientWithNamespace
+
+var referenceKeys = sync.OnceValues(reference.LoadKeys)
+
+const (
+	referenceIssuerPath   = "/{namespace}/issuer/{session}"
+	referenceVerifierPath = "/{namespace}/verifier/{session}"
+
+	contentTypeRequestObject = "application/oauth-authz-req+jwt"
+)
+
+// ReferenceRoutes are the wallet facing endpoints of the built-in reference
+// issuer and verifier. Every session is a workflow, addressed by namespace
+// and workflow id.
+var ReferenceRoutes = routing.RouteGroup{
+	BaseURL:                "/api/reference",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "/ca.pem",
+			Handler:     HandleReferenceRootCertificate,
+			Description: "Root certificate the reference issuer and verifier chain to",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        referenceIssuerPath + "/.well-known/openid-credential-issuer",
+			Handler:     HandleReferenceIssuerMetadata,
+			Description: "Credential issuer metadata of a reference issuer session",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        referenceIssuerPath + "/.well-known/oauth-authorization-server",
+			Handler:     HandleReferenceAuthorizationServerMetadata,
+			Description: "Authorization server metadata of a reference issuer session",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        referenceIssuerPath + "/authorize",
+			Handler:     HandleReferenceAuthorize,
+			Description: "Authorization endpoint of a reference issuer session",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        referenceIssuerPath + "/token",
+			Handler:     HandleReferenceToken,
+			Description: "Token endpoint of a reference issuer session",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        referenceIssuerPath + "/nonce",
+			Handler:     HandleReferenceNonce,
+			Description: "Nonce endpoint of a reference issuer session",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        referenceIssuerPath + "/credential",
+			Handler:     HandleReferenceCredential,
+			Description: "Credential endpoint of a reference issuer session",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        referenceVerifierPath + "/request.jwt",
+			Handler:     HandleReferenceRequestObject,
+			Description: "Signed request object of a reference verifier session",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        referenceVerifierPath + "/request.jwt",
+			Handler:     HandleReferenceRequestObject,
+			Description: "Signed request object of a reference verifier session",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        referenceVerifierPath + "/response",
+			Handler:     HandleReferenceResponse,
+			Description: "direct_post response endpoint of a reference verifier session",
+		},
+	},
+}
+
+// ReferenceWellKnownRoutes serve the issuer metadata at the well-known
+// locations inserted before the path of the issuer identifier.
+var ReferenceWellKnownRoutes = routing.RouteGroup{
+	BaseURL:                "/.well-known",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:  http.MethodGet,
+			Path:    "/openid-credential-issuer/api/reference" + referenceIssuerPath,
+			Handler: HandleReferenceIssuerMetadata,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/oauth-authorization-server/api/reference" + referenceIssuerPath,
+			Handler: HandleReferenceAuthorizationServerMetadata,
+		},
+	},
+}
+
+func HandleReferenceRootCertificate() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		keys, err := referenceKeys()
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"reference",
+				"keys_unavailable",
+				err.Error(),
+			)
+		}
+		return e.Blob(http.StatusOK, "application/x-pem-file", keys.RootPEM())
+	}
+}
+
+func HandleReferenceIssuerMetadata() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		session, err := queryReferenceSession[reference.IssuerSession](e)
+		if err != nil {
+			return err
+		}
+		metadata, err := session.CredentialIssuerMetadata()
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"reference",
+				"invalid_session",
+				err.Error(),
+			)
+		}
+		return e.JSON(http.StatusOK, metadata)
+	}
+}
+
+func HandleReferenceAuthorizationServerMetadata() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		session, err := queryReferenceSession[reference.IssuerSession](e)
+		if err != nil {
+			return err
+		}
+		return e.JSON(http.StatusOK, session.AuthorizationServerMetadata())
+	}
+}
+
+func HandleReferenceAuthorize() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		query := e.Request.URL.Query()
+		req := reference.AuthorizeRequest{
+			ResponseType:        query.Get("response_type"),
+			ClientID:            query.Get("client_id"),
+			RedirectURI:         query.Get("redirect_uri"),
+			State:               query.Get("state"),
+			CodeChallenge:       query.Get("code_challenge"),
+			CodeChallengeMethod: query.Get("code_challenge_method"),
+			IssuerState:         query.Get("issuer_state"),
+		}
+		var response workflows.ReferenceAuthorizeResponse
+		err := updateReferenceSession(e, workflows.ReferenceAuthorizeUpdate, req, &response)
+		if err != nil {
+			return err
+		}
+		if response.Error != nil {
+			return writeReferenceProtocolError(e, response.Error)
+		}
+		return e.Redirect(http.StatusFound, response.Location)
+	}
+}
+
+func HandleReferenceToken() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return writeReferenceProtocolError(e, &reference.ProtocolError{
+				Status:      http.StatusBadRequest,
+				Code:        "invalid_request",
+				Description: err.Error(),
+			})
+		}
+		form := e.Request.PostForm
+		req := reference.TokenRequest{
+			GrantType:         form.Get("grant_type"),
+			PreAuthorizedCode: form.Get("pre-authorized_code"),
+			TxCode:            form.Get("tx_code"),
+			Code:              form.Get("code"),
+			CodeVerifier:      form.Get("code_verifier"),
+			RedirectURI:       form.Get("redirect_uri"),
+		}
+		var response workflows.ReferenceTokenResponse
+		err := updateReferenceSession(e, workflows.ReferenceTokenUpdate, req, &response)
+		if err != nil {
+			return err
+		}
+		if response.Error != nil {
+			return writeReferenceProtocolError(e, response.Error)
+		}
+		e.Response.Header().Set("Cache-Control", "no-store")
+		return e.JSON(http.StatusOK, response.Token)
+	}
+}
+
+func HandleReferenceNonce() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		session, err := queryReferenceSession[reference.IssuerSession](e)
+		if err != nil {
+			return err
+		}
+		e.Response.Header().Set("Cache-Control", "no-store")
+		return e.JSON(http.StatusOK, map[string]any{"c_nonce": session.Secrets.Nonce})
+	}
+}
+
+func HandleReferenceCredential() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		accessToken, ok := strings.CutPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+		if !ok || accessToken == "" {
+			e.Response.Header().Set("WWW-Authenticate", "Bearer")
+			return writeReferenceProtocolError(e, &reference.ProtocolError{
+				Status:      http.StatusUnauthorized,
+				Code:        "invalid_token",
+				Description: "a bearer access token is required",
+			})
+		}
+		var body map[string]any
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return writeReferenceProtocolError(e, &reference.ProtocolError{
+				Status:      http.StatusBadRequest,
+				Code:        "invalid_credential_request",
+				Description: err.Error(),
+			})
+		}
+
+		var response workflows.ReferenceCredentialResponse
+		if err := updateReferenceSession(
+			e,
+			workflows.ReferenceCredentialUpdate,
+			reference.ParseCredentialRequest(accessToken, body),
+			&response,
+		); err != nil {
+			return err
+		}
+		if response.Error != nil {
+			return writeReferenceProtocolError(e, response.Error)
+		}
+		credentials := make([]map[string]any, 0, len(response.Credentials))
+		for _, credential := range response.Credentials {
+			credentials = append(credentials, map[string]any{"credential": credential})
+		}
+		return e.JSON(http.StatusOK, map[string]any{"credentials": credentials})
+	}
+}
+
+func HandleReferenceRequestObject() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		session, err := queryReferenceSession[reference.VerifierSession](e)
+		if err != nil {
+			return err
+		}
+		return e.Blob(http.StatusOK, contentTypeRequestObject, []byte(session.RequestObject))
+	}
+}
+
+func HandleReferenceResponse() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return writeReferenceProtocolError(e, &reference.ProtocolError{
+				Status:      http.StatusBadRequest,
+				Code:        "invalid_request",
+				Description: err.Error(),
+			})
+		}
+		req := workflows.ReferenceResponseRequest{
+			VPToken: e.Request.PostForm.Get("vp_token"),
+			State:   e.Request.PostForm.Get("state"),
+		}
+		var response workflows.ReferenceResponseResult
+		err := updateReferenceSession(e, workflows.ReferenceResponseUpdate, req, &response)
+		if err != nil {
+			return err
+		}
+		if response.Error != nil {
+			return writeReferenceProtocolError(e, response.Error)
+		}
+		return e.JSON(http.StatusOK, map[string]any{})
+	}
+}
+
+// queryReferenceSession reads the session of the workflow in the path.
+func queryReferenceSession[T any](e *core.RequestEvent) (T, error) {
+	var session T
+	client, err := referenceTemporalClient(e.Request.PathValue("namespace"))
+	if err != nil {
+		return session, referenceTemporalError(err)
+	}
+	encoded, err := client.QueryWorkflow(
+		e.Request.Context(),
+		e.Request.PathValue("session"),
+		"",
+		workflows.ReferenceSessionQuery,
+	)
+	if err != nil {
+		return session, referenceTemporalError(err)
+	}
+	if err := encoded.Get(&session); err != nil {
+		return session, referenceTemporalError(err)
+	}
+	return session, nil
+}
+
+// updateReferenceSession sends an endpoint request to the workflow in the
+// path and waits for its answer.
+func updateReferenceSession(e *core.RequestEvent, updateName string, req any, out any) error {
+	client, err := referenceTemporalClient(e.Request.PathValue("namespace"))
+	if err != nil {
+		return referenceTemporalError(err)
+	}
+	handle, err := client.UpdateWorkflow(e.Request.Context(), tclient.UpdateWorkflowOptions{
+		WorkflowID:   e.Request.PathValue("session"),
+		UpdateName:   updateName,
+		UpdateID:     uuid.NewString(),
+		Args:         []any{req},
+		WaitForStage: tclient.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		return referenceTemporalError(err)
+	}
+	if err := handle.Get(e.Request.Context(), out); err != nil {
+		return referenceTemporalError(err)
+	}
+	return nil
+}
+
+func referenceTemporalError(err error) error {
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return apierror.New(
+			http.StatusNotFound,
+			"reference",
+			"session_not_found",
+			"the session does not exist or has expired",
+		)
+	}
+	return apierror.New(
+		http.StatusInternalServerError,
+		"reference",
+		"session_unavailable",
+		err.Error(),
+	)
+}
+
+// writeReferenceProtocolError answers with an OAuth error response, which
+// wallets expect instead of the API error format.
+func writeReferenceProtocolError(e *core.RequestEvent, protoErr *reference.ProtocolError) error {
+	status := protoErr.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	body := map[string]string{"error": protoErr.Code}
+	if protoErr.Description != "" {
+		body["error_description"] = protoErr.Description
+	}
+	return e.JSON(status, body)
+}
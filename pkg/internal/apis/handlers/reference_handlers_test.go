@@ -0,0 +1,343 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+const testReferenceIssuerURL = "https://credimi.example/api/reference/org/issuer/session-1"
+
+type referenceEncodedValue struct {
+	value any
+}
+
+func (v referenceEncodedValue) HasValue() bool { return true }
+
+func (v referenceEncodedValue) Get(valuePtr interface{}) error {
+	data, err := json.Marshal(v.value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, valuePtr)
+}
+
+func stubReferenceTemporalClient(t *testing.T) *temporalmocks.Client {
+	t.Helper()
+	mockClient := temporalmocks.NewClient(t)
+	original := referenceTemporalClient
+	referenceTemporalClient = func(namespace string) (client.Client, error) {
+		require.Equal(t, "org", namespace)
+		return mockClient, nil
+	}
+	t.Cleanup(func() { referenceTemporalClient = original })
+	return mockClient
+}
+
+func expectReferenceQuery(mockClient *temporalmocks.Client, session any) {
+	mockClient.
+		On("QueryWorkflow", mock.Anything, "session-1", "", workflows.ReferenceSessionQuery).
+		Return(referenceEncodedValue{value: session}, nil).
+		Once()
+}
+
+// expectReferenceUpdate answers the update named updateName with response
+// when its request matches.
+func expectReferenceUpdate[T any](
+	t *testing.T,
+	mockClient *temporalmocks.Client,
+	updateName string,
+	match func(T) bool,
+	response any,
+) {
+	t.Helper()
+	handle := temporalmocks.NewWorkflowUpdateHandle(t)
+	handle.
+		On("Get", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, args.Get(1)))
+		}).
+		Return(nil).
+		Once()
+	mockClient.
+		On(
+			"UpdateWorkflow",
+			mock.Anything,
+			mock.MatchedBy(func(options client.UpdateWorkflowOptions) bool {
+				req, ok := options.Args[0].(T)
+				return ok &&
+					options.WorkflowID == "session-1" &&
+					options.UpdateName == updateName &&
+					match(req)
+			}),
+		).
+		Return(handle, nil).
+		Once()
+}
+
+func referenceRequestEvent(
+	method string,
+	target string,
+	body string,
+) (*core.RequestEvent, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if method == http.MethodPost && !strings.HasPrefix(body, "{") {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.SetPathValue("namespace", "org")
+	req.SetPathValue("session", "session-1")
+	rec := httptest.NewRecorder()
+	return &core.RequestEvent{Event: router.Event{Request: req, Response: rec}}, rec
+}
+
+func testReferenceIssuerSession() reference.IssuerSession {
+	return reference.IssuerSession{
+		IssuerURL: testReferenceIssuerURL,
+		Config:    reference.IssuerConfig{Configuration: reference.ConfigurationPID},
+		Secrets:   reference.IssuerSecrets{Nonce: "nonce"},
+	}
+}
+
+func TestHandleReferenceRootCertificate(t *testing.T) {
+	event, rec := referenceRequestEvent(http.MethodGet, "/api/reference/ca.pem", "")
+	require.NoError(t, HandleReferenceRootCertificate()(event))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "BEGIN CERTIFICATE")
+}
+
+func TestHandleReferenceIssuerMetadata(t *testing.T) {
+	mockClient := stubReferenceTemporalClient(t)
+	expectReferenceQuery(mockClient, testReferenceIssuerSession())
+	expectReferenceQuery(mockClient, testReferenceIssuerSession())
+
+	event, rec := referenceRequestEvent(http.MethodGet, "/", "")
+	require.NoError(t, HandleReferenceIssuerMetadata()(event))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &metadata))
+	require.Equal(t, testReferenceIssuerURL, metadata["credential_issuer"])
+	require.Equal(t, testReferenceIssuerURL+"/credential", metadata["credential_endpoint"])
+
+	event, rec = referenceRequestEvent(http.MethodGet, "/", "")
+	require.NoError(t, HandleReferenceAuthorizationServerMetadata()(event))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &metadata))
+	require.Equal(t, testReferenceIssuerURL+"/token", metadata["token_endpoint"])
+}
+
+func TestHandleReferenceNonce(t *testing.T) {
+	mockClient := stubReferenceTemporalClient(t)
+	expectReferenceQuery(mockClient, testReferenceIssuerSession())
+
+	event, rec := referenceRequestEvent(http.MethodPost, "/", "")
+	require.NoError(t, HandleReferenceNonce()(event))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	require.JSONEq(t, `{"c_nonce":"nonce"}`, rec.Body.String())
+}
+
+func TestHandleReferenceSessionNotFound(t *testing.T) {
+	mockClient := stubReferenceTemporalClient(t)
+	mockClient.
+		On("QueryWorkflow", mock.Anything, "session-1", "", workflows.ReferenceSessionQuery).
+		Return(nil, &serviceerror.NotFound{})
+
+	event, _ := referenceRequestEvent(http.MethodGet, "/", "")
+	err := HandleReferenceIssuerMetadata()(event)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.Code)
+}
+
+func TestHandleReferenceAuthorize(t *testing.T) {
+	mockClient := stubReferenceTemporalClient(t)
+	expectReferenceUpdate(
+		t,
+		mockClient,
+		workflows.ReferenceAuthorizeUpdate,
+		func(req reference.AuthorizeRequest) bool {
+			return req.ResponseType == "code" &&
+				req.CodeChallengeMethod == "S256" &&
+				req.IssuerState == "issuer-state"
+		},
+		workflows.ReferenceAuthorizeResponse{Location: "https://wallet.example/cb?code=c"},
+	)
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("code_challenge_method", "S256")
+	query.Set("issuer_state", "issuer-state")
+	event, rec := referenceRequestEvent(http.MethodGet, "/?"+query.Encode(), "")
+	require.NoError(t, HandleReferenceAuthorize()(event))
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://wallet.example/cb?code=c", rec.Header().Get("Location"))
+}
+
+func TestHandleReferenceToken(t *testing.T) {
+	t.Run("forwards the form to the session", func(t *testing.T) {
+		mockClient := stubReferenceTemporalClient(t)
+		expectReferenceUpdate(
+			t,
+			mockClient,
+			workflows.ReferenceTokenUpdate,
+			func(req reference.TokenRequest) bool {
+				return req.GrantType == reference.GrantPreAuthorizedCode &&
+					req.PreAuthorizedCode == "code" &&
+					req.TxCode == "1234"
+			},
+			workflows.ReferenceTokenResponse{Token: &reference.TokenResponse{
+				AccessToken: "token",
+				TokenType:   "Bearer",
+				ExpiresIn:   600,
+			}},
+		)
+
+		form := url.Values{}
+		form.Set("grant_type", reference.GrantPreAuthorizedCode)
+		form.Set("pre-authorized_code", "code")
+		form.Set("tx_code", "1234")
+		event, rec := referenceRequestEvent(http.MethodPost, "/", form.Encode())
+		require.NoError(t, HandleReferenceToken()(event))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		var token map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &token))
+		require.Equal(t, "token", token["access_token"])
+	})
+
+	t.Run("answers protocol errors in OAuth format", func(t *testing.T) {
+		mockClient := stubReferenceTemporalClient(t)
+		expectReferenceUpdate(
+			t,
+			mockClient,
+			workflows.ReferenceTokenUpdate,
+			func(reference.TokenRequest) bool { return true },
+			workflows.ReferenceTokenResponse{Error: &reference.ProtocolError{
+				Status:      http.StatusBadRequest,
+				Code:        "invalid_grant",
+				Description: "wrong tx_code",
+			}},
+		)
+
+		event, rec := referenceRequestEvent(http.MethodPost, "/", "grant_type=x")
+		require.NoError(t, HandleReferenceToken()(event))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.JSONEq(
+			t,
+			`{"error":"invalid_grant","error_description":"wrong tx_code"}`,
+			rec.Body.String(),
+		)
+	})
+}
+
+func TestHandleReferenceCredential(t *testing.T) {
+	t.Run("requires a bearer token", func(t *testing.T) {
+		event, rec := referenceRequestEvent(http.MethodPost, "/", "{}")
+		require.NoError(t, HandleReferenceCredential()(event))
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("returns the issued credentials", func(t *testing.T) {
+		mockClient := stubReferenceTemporalClient(t)
+		expectReferenceUpdate(
+			t,
+			mockClient,
+			workflows.ReferenceCredentialUpdate,
+			func(req reference.CredentialRequest) bool {
+				return req.AccessToken == "token" &&
+					req.CredentialConfiguration == reference.ConfigurationPID &&
+					len(req.Proofs) == 1 && req.Proofs[0] == "proof"
+			},
+			workflows.ReferenceCredentialResponse{Credentials: []string{"credential"}},
+		)
+
+		event, rec := referenceRequestEvent(
+			http.MethodPost,
+			"/",
+			`{"credential_configuration_id":"pid_sd_jwt","proofs":{"jwt":["proof"]}}`,
+		)
+		event.Request.Header.Set("Authorization", "Bearer token")
+		require.NoError(t, HandleReferenceCredential()(event))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"credentials":[{"credential":"credential"}]}`, rec.Body.String())
+	})
+}
+
+func TestHandleReferenceRequestObject(t *testing.T) {
+	mockClient := stubReferenceTemporalClient(t)
+	expectReferenceQuery(mockClient, reference.VerifierSession{RequestObject: "a.b.c"})
+
+	event, rec := referenceRequestEvent(http.MethodGet, "/", "")
+	require.NoError(t, HandleReferenceRequestObject()(event))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, contentTypeRequestObject, rec.Header().Get("Content-Type"))
+	require.Equal(t, "a.b.c", rec.Body.String())
+}
+
+func TestHandleReferenceResponse(t *testing.T) {
+	t.Run("accepts a valid presentation", func(t *testing.T) {
+		mockClient := stubReferenceTemporalClient(t)
+		expectReferenceUpdate(
+			t,
+			mockClient,
+			workflows.ReferenceResponseUpdate,
+			func(req workflows.ReferenceResponseRequest) bool {
+				return req.VPToken == `{"pid":["vp"]}` && req.State == "state"
+			},
+			workflows.ReferenceResponseResult{
+				Result: &reference.VerificationResult{Valid: true},
+			},
+		)
+
+		form := url.Values{}
+		form.Set("vp_token", `{"pid":["vp"]}`)
+		form.Set("state", "state")
+		event, rec := referenceRequestEvent(http.MethodPost, "/", form.Encode())
+		require.NoError(t, HandleReferenceResponse()(event))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("rejects an invalid presentation", func(t *testing.T) {
+		mockClient := stubReferenceTemporalClient(t)
+		expectReferenceUpdate(
+			t,
+			mockClient,
+			workflows.ReferenceResponseUpdate,
+			func(workflows.ReferenceResponseRequest) bool { return true },
+			workflows.ReferenceResponseResult{
+				Result: &reference.VerificationResult{Issues: []string{"nonce"}},
+				Error: &reference.ProtocolError{
+					Status:      http.StatusBadRequest,
+					Code:        "invalid_request",
+					Description: "nonce",
+				},
+			},
+		)
+
+		event, rec := referenceRequestEvent(http.MethodPost, "/", "vp_token=x")
+		require.NoError(t, HandleReferenceResponse()(event))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), "invalid_request")
+	})
+}
@@ -14,11 +14,14 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
@@ -39,6 +42,7 @@ var SchedulesRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/start",
+			Permission:     apikey.PermissionSchedulesWrite,
 			OperationID:    "schedule.start",
 			Handler:        HandleStartSchedule,
 			RequestSchema:  StartScheduleRequest{},
@@ -49,6 +53,7 @@ var SchedulesRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			OperationID:    "schedules.list",
+			Permission:     apikey.PermissionSchedulesRead,
 			Handler:        HandleListMySchedules,
 			ResponseSchema: ListMySchedulesResponse{},
 			Description:    "List all schedules for the authenticated user",
@@ -58,6 +63,7 @@ var SchedulesRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/{scheduleId}/cancel",
+			Permission:     apikey.PermissionSchedulesWrite,
 			OperationID:    "schedule.cancel",
 			Handler:        HandleCancelSchedule,
 			ResponseSchema: CancelScheduleResponse{},
@@ -67,6 +73,7 @@ var SchedulesRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/{scheduleId}/pause",
+			Permission:     apikey.PermissionSchedulesWrite,
 			OperationID:    "schedule.pause",
 			Handler:        HandlePauseSchedule,
 			ResponseSchema: PauseScheduleResponse{},
@@ -76,6 +83,7 @@ var SchedulesRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/{scheduleId}/resume",
+			Permission:     apikey.PermissionSchedulesWrite,
 			OperationID:    "schedule.resume",
 			Handler:        HandleResumeSchedule,
 			ResponseSchema: ResumeScheduleResponse{},
@@ -152,6 +160,13 @@ func HandleStartSchedule() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		if apiErr := authorizePipelineAccess(
+			e,
+			rec,
+			apikey.PermissionPipelinesRun,
+		); apiErr != nil {
+			return apiErr
+		}
 
 		config := buildPipelineQueueConfig(e, namespace, userName, userMail)
 
@@ -208,6 +223,14 @@ func HandleStartSchedule() func(*core.RequestEvent) error {
 			)
 		}
 
+		recordAudit(e, audit.Entry{
+			Organization: orgID,
+			Action:       audit.ActionScheduleStarted,
+			ResourceType: "schedules",
+			ResourceID:   scheduleInfo.ScheduleID,
+			Changes:      audit.Diff(nil, rec),
+		})
+
 		return e.JSON(http.StatusOK, StartScheduleResponse{
 			Message:      "Schedule started successfully",
 			ScheduleID:   scheduleInfo.ScheduleID,
@@ -266,6 +289,10 @@ func HandleListMySchedules() func(*core.RequestEvent) error {
 				err.Error(),
 			)
 		}
+		schedules, apiErr := filterAPIKeySchedules(e, schedules)
+		if apiErr != nil {
+			return apiErr
+		}
 		response := ListMySchedulesResponse{
 			Schedules: schedules,
 		}
@@ -338,6 +365,12 @@ func listScheduledWorkflows(namespace string) ([]*ScheduleInfoSummary, error) {
 
 type scheduleAction func(ctx context.Context, handle client.ScheduleHandle) error
 
+// scheduleAudit is the audit entry of a schedule action.
+type scheduleAudit struct {
+	action  string
+	changes map[string]audit.Change
+}
+
 func HandleCancelSchedule() func(*core.RequestEvent) error {
 	return handleSchedule(
 		func(ctx context.Context, h client.ScheduleHandle) error {
@@ -359,6 +392,7 @@ func HandleCancelSchedule() func(*core.RequestEvent) error {
 			}
 			return deleteScheduleRecord(e.App, scheduleID, orgID)
 		},
+		scheduleAudit{action: audit.ActionScheduleCanceled},
 	)
 }
 func HandlePauseSchedule() func(*core.RequestEvent) error {
@@ -377,7 +411,11 @@ func HandlePauseSchedule() func(*core.RequestEvent) error {
 				Namespace:  namespace,
 			}
 		},
-		nil,
+		emitSchedulePaused,
+		scheduleAudit{
+			action:  audit.ActionSchedulePaused,
+			changes: map[string]audit.Change{"paused": {Before: false, After: true}},
+		},
 	)
 }
 func HandleResumeSchedule() func(*core.RequestEvent) error {
@@ -397,9 +435,35 @@ func HandleResumeSchedule() func(*core.RequestEvent) error {
 			}
 		},
 		nil,
+		scheduleAudit{
+			action:  audit.ActionScheduleResumed,
+			changes: map[string]audit.Change{"paused": {Before: true, After: false}},
+		},
 	)
 }
 
+// emitSchedulePaused emits the schedule.paused event to the organization
+// owning the schedule. Schedules without a local record emit nothing, and
+// the pause never fails because of its event.
+func emitSchedulePaused(e *core.RequestEvent, scheduleID string) error {
+	schedule, err := e.App.FindFirstRecordByFilter(
+		"schedules",
+		"temporal_schedule_id = {:sid}",
+		map[string]any{"sid": scheduleID},
+	)
+	if err != nil {
+		return nil
+	}
+	emitWebhookEvent(e.App, schedule.GetString("owner"), webhook.EventSchedulePaused,
+		map[string]any{
+			"schedule_id": scheduleID,
+			"pipeline_id": schedule.GetString("pipeline"),
+			"paused_by":   e.Auth.Id,
+		},
+	)
+	return nil
+}
+
 func deleteScheduleRecord(
 	app core.App,
 	scheduleID string,
@@ -424,6 +488,7 @@ func handleSchedule(
 	action scheduleAction,
 	makeResponse func(scheduleID, namespace string) any,
 	after func(e *core.RequestEvent, scheduleID string) error,
+	audited scheduleAudit,
 ) func(*core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		authRecord := e.Auth
@@ -445,6 +510,13 @@ func handleSchedule(
 				"missing required parameters",
 			)
 		}
+		if apiErr := authorizeScheduleAccess(
+			e,
+			scheduleID,
+			apikey.PermissionSchedulesWrite,
+		); apiErr != nil {
+			return apiErr
+		}
 
 		namespace, err := pbutils.GetUserOrganizationCanonifiedName(e.App, authRecord.Id)
 		if err != nil {
@@ -498,6 +570,13 @@ func handleSchedule(
 			}
 		}
 
+		recordAudit(e, audit.Entry{
+			Action:       audited.action,
+			ResourceType: "schedules",
+			ResourceID:   scheduleID,
+			Changes:      audited.changes,
+		})
+
 		return e.JSON(http.StatusOK, makeResponse(scheduleID, namespace))
 	}
 }
@@ -15,6 +15,9 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
@@ -471,6 +474,7 @@ func TestHandleScheduleNotFound(t *testing.T) {
 			return map[string]string{"scheduleId": scheduleID, "namespace": namespace}
 		},
 		nil,
+		scheduleAudit{action: audit.ActionScheduleCanceled},
 	)
 
 	err = handler(&core.RequestEvent{
@@ -566,6 +570,70 @@ func TestHandleListMySchedules(t *testing.T) {
 	require.True(t, response.Schedules[0].Paused)
 }
 
+func TestHandleListMySchedulesWithRestrictedAPIKey(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	pipelineRecord := createPipelineExecutionTestPipeline(t, app, orgID)
+	schedulesColl, err := app.FindCollectionByNameOrId("schedules")
+	require.NoError(t, err)
+	for id, pipelineID := range map[string]string{
+		"granted-schedule": pipelineRecord.Id,
+		"other-schedule":   "",
+	} {
+		record := core.NewRecord(schedulesColl)
+		record.Set("temporal_schedule_id", id)
+		record.Set("owner", orgID)
+		record.Set("pipeline", pipelineID)
+		require.NoError(t, app.Save(record))
+	}
+
+	originalClient := scheduleTemporalClient
+	t.Cleanup(func() {
+		scheduleTemporalClient = originalClient
+	})
+
+	iter := temporalmocks.NewScheduleListIterator(t)
+	for _, id := range []string{"granted-schedule", "other-schedule", "unrecorded-schedule"} {
+		iter.On("HasNext").Return(true).Once()
+		iter.On("Next").Return(&client.ScheduleListEntry{
+			ID:           id,
+			Spec:         &client.ScheduleSpec{},
+			WorkflowType: workflow.Type{Name: "Dynamic Pipeline Workflow"},
+			NextActionTimes: []time.Time{
+				time.Date(2026, time.February, 17, 12, 0, 0, 0, time.UTC),
+			},
+		}, nil).Once()
+	}
+	iter.On("HasNext").Return(false).Once()
+
+	mockClient := &temporalmocks.Client{}
+	mockClient.On("ScheduleClient").Return(&fakeScheduleClient{listIter: iter})
+	scheduleTemporalClient = func(namespace string) (client.Client, error) {
+		return mockClient, nil
+	}
+
+	e, rec := restrictedAPIKeyEvent(t, app, authRecord.Id, apikey.Grant{
+		Resources: map[apikey.Resource][]string{
+			apikey.ResourcePipelines: {pipelineRecord.Id},
+		},
+	}, httptest.NewRequest(http.MethodGet, "/api/my/schedules", nil))
+
+	err = HandleListMySchedules()(e)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response ListMySchedulesResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Len(t, response.Schedules, 1)
+	require.Equal(t, "granted-schedule", response.Schedules[0].ID)
+}
+
 func TestHandleCancelScheduleDeletesRecord(t *testing.T) {
 	app, err := tests.NewTestApp(testDataDir)
 	require.NoError(t, err)
@@ -694,6 +762,15 @@ func TestHandlePauseSchedule(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, rec.Code)
 
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	logs, _, err := audit.Find(app, orgID, audit.Filter{ResourceID: "sched-1"}, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, audit.ActionSchedulePaused, logs[0].Action)
+	require.Equal(t, authRecord.Id, logs[0].Actor.ID)
+	require.Equal(t, audit.Change{Before: false, After: true}, logs[0].Changes["paused"])
+
 	handle = temporalmocks.NewScheduleHandle(t)
 	handle.On("Pause", mock.Anything, mock.Anything).
 		Return(&serviceerror.NotFound{Message: "missing"})
@@ -717,6 +794,67 @@ func TestHandlePauseSchedule(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, rec.Code)
 }
 
+func TestHandlePauseScheduleEmitsWebhook(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	schedulesColl, err := app.FindCollectionByNameOrId("schedules")
+	require.NoError(t, err)
+	scheduleRecord := core.NewRecord(schedulesColl)
+	scheduleRecord.Set("temporal_schedule_id", "sched-1")
+	scheduleRecord.Set("owner", orgID)
+	require.NoError(t, app.Save(scheduleRecord))
+	hook := seedWebhook(t, app, orgID, "https://example.org/hooks")
+	hook.Set("events", []webhook.Event{webhook.EventSchedulePaused})
+	require.NoError(t, app.Save(hook))
+
+	originalClient := scheduleTemporalClient
+	t.Cleanup(func() {
+		scheduleTemporalClient = originalClient
+	})
+
+	handle := temporalmocks.NewScheduleHandle(t)
+	handle.On("Pause", mock.Anything, mock.Anything).Return(nil)
+
+	fakeSchedule := &fakeScheduleClient{handle: handle}
+	mockClient := &temporalmocks.Client{}
+	mockClient.On("ScheduleClient").Return(fakeSchedule)
+	scheduleTemporalClient = func(namespace string) (client.Client, error) {
+		return mockClient, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/my/schedules/sched-1/pause", nil)
+	req.SetPathValue("scheduleId", "sched-1")
+	rec := httptest.NewRecorder()
+
+	err = HandlePauseSchedule()(&core.RequestEvent{
+		App:  app,
+		Auth: authRecord,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	delivery, err := app.FindFirstRecordByData(webhook.DeliveryCollection, "webhook", hook.Id)
+	require.NoError(t, err)
+	require.Equal(t, string(webhook.EventSchedulePaused), delivery.GetString("event"))
+	var payload webhook.Payload
+	require.NoError(t, delivery.UnmarshalJSONField("payload", &payload))
+	data, ok := payload.Data.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "sched-1", data["schedule_id"])
+	require.Equal(t, authRecord.Id, data["paused_by"])
+}
+
 func TestHandleResumeSchedule(t *testing.T) {
 	app, err := tests.NewTestApp(testDataDir)
 	require.NoError(t, err)
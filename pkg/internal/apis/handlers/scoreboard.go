@@ -20,6 +20,7 @@ import (
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
 	pipelineresults "github.com/forkbombeu/credimi/pkg/internal/pipeline_results"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/pipeline"
@@ -51,21 +52,22 @@ type StartAggregateScoreboardResponse struct {
 }
 
 type PipelineStatsResponse struct {
-	PipelineID          string             `json:"pipeline_id"`
-	PipelineName        string             `json:"pipeline_name"`
-	PipelineIdentifier  string             `json:"pipeline_identifier"`
-	RunnerTypes         []string           `json:"runner_types"`
-	Runners             []string           `json:"runners"`
-	TotalRuns           int                `json:"total_runs"`
-	TotalSuccesses      int                `json:"total_successes"`
-	SuccessRate         float64            `json:"success_rate"`
-	ManualExecutions    int                `json:"manual_executions"`
-	ScheduledExecutions int                `json:"scheduled_executions"`
-	CIExecutions        int                `json:"ci_executions"`
-	MinExecutionTime    string             `json:"min_execution_time"`
-	FirstExecutionDate  string             `json:"first_execution_date"`
-	LastExecutionDate   string             `json:"last_execution_date"`
-	LastSuccessfulRun   *LastSuccessfulRun `json:"last_successful_run,omitempty"`
+	PipelineID          string                       `json:"pipeline_id"`
+	PipelineName        string                       `json:"pipeline_name"`
+	PipelineIdentifier  string                       `json:"pipeline_identifier"`
+	RunnerTypes         []string                     `json:"runner_types"`
+	Runners             []string                     `json:"runners"`
+	TotalRuns           int                          `json:"total_runs"`
+	TotalSuccesses      int                          `json:"total_successes"`
+	SuccessRate         float64                      `json:"success_rate"`
+	ManualExecutions    int                          `json:"manual_executions"`
+	ScheduledExecutions int                          `json:"scheduled_executions"`
+	CIExecutions        int                          `json:"ci_executions"`
+	MinExecutionTime    string                       `json:"min_execution_time"`
+	FirstExecutionDate  string                       `json:"first_execution_date"`
+	LastExecutionDate   string                       `json:"last_execution_date"`
+	LastSuccessfulRun   *LastSuccessfulRun           `json:"last_successful_run,omitempty"`
+	FlakySteps          []pipelineinternal.FlakyStep `json:"flaky_steps"`
 }
 
 type LastSuccessfulRun struct {
@@ -179,6 +181,17 @@ func HandleSaveScoreboardResults() func(*core.RequestEvent) error {
 			)
 		}
 
+		if _, err := saveScoreboardSnapshot(e.App, req.AggregatedPipelines); err != nil {
+			e.App.Logger().Warn("Failed to save scoreboard snapshot", "error", err)
+		}
+
+		if _, err := webhook.Broadcast(e.App, webhook.EventScoreboardUpdated, map[string]any{
+			"records_count": recordsCount,
+			"pipelines":     len(req.AggregatedPipelines),
+		}); err != nil {
+			e.App.Logger().Warn("Failed to emit scoreboard webhooks", "error", err)
+		}
+
 		message := fmt.Sprintf("Results saved successfully (%d records)", recordsCount)
 		errorMessage := ""
 		if len(saveErrors) > 0 {
@@ -470,6 +483,16 @@ func HandleGetPipelineScoreboard() func(*core.RequestEvent) error {
 				runnerCache,
 			)
 
+			flakySteps, err := findPipelineFlakySteps(e.App, pipelineID, "")
+			if err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"pipeline",
+					"failed to detect flaky steps",
+					err.Error(),
+				)
+			}
+
 			response = append(response, PipelineStatsResponse{
 				PipelineID:   pipelineID,
 				PipelineName: pipelineName,
@@ -490,6 +513,7 @@ func HandleGetPipelineScoreboard() func(*core.RequestEvent) error {
 				FirstExecutionDate:  stats.FirstExecutionDate,
 				LastExecutionDate:   stats.LastExecutionDate,
 				LastSuccessfulRun:   lastSuccessfulRun,
+				FlakySteps:          flakySteps,
 			})
 		}
 		return e.JSON(http.StatusOK, response)
@@ -1146,6 +1170,7 @@ func setBasicFields(record *core.Record, stats workflows.AggregatedPipelineStats
 	record.Set("minimum_running_time", stats.MinExecutionTime)
 	record.Set("first_execution", stats.FirstExecutionDate)
 	record.Set("last_execution_date", stats.LastExecutionDate)
+	record.SetIfFieldExists("flaky_steps", stats.FlakySteps)
 }
 
 func setPipelineRelation(record *core.Record, app core.App, pipelineID string) error {
@@ -0,0 +1,554 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	pipelineresults "github.com/forkbombeu/credimi/pkg/internal/pipeline_results"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	scoreboardCacheCollection = "pipeline_scoreboard_cache"
+
+	InteropCounterpartIssuer   = "issuer"
+	InteropCounterpartVerifier = "verifier"
+
+	InteropStatusPassing = "passing"
+	InteropStatusPartial = "partial"
+	InteropStatusFailing = "failing"
+
+	interopExportJSON = "json"
+	interopExportCSV  = "csv"
+)
+
+var ScoreboardInteropRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/scoreboard",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:         http.MethodGet,
+			Path:           "/interop-matrix",
+			OperationID:    "getScoreboardInteropMatrix",
+			Handler:        HandleGetInteropMatrix,
+			ResponseSchema: InteropMatrixResponse{},
+			Summary:        "Get the interoperability matrix",
+			Description:    "Returns which wallet versions work with which issuers and verifiers, per credential format, with last-known status and evidence links. Use format=csv to download it as CSV.",
+			QuerySearchAttributes: []routing.QuerySearchAttribute{
+				{
+					Name:        "format",
+					Required:    false,
+					Description: "Response format: json (default) or csv",
+				},
+				{
+					Name:        "counterpart_type",
+					Required:    false,
+					Description: "Only return cells for issuers or verifiers",
+				},
+				{
+					Name:        "wallet",
+					Required:    false,
+					Description: "Only return cells for this wallet or wallet version identifier",
+				},
+				{
+					Name:        "credential_format",
+					Required:    false,
+					Description: "Only return cells for this credential format",
+				},
+			},
+		},
+	},
+}
+
+type InteropEvidence struct {
+	Pipeline       string                            `json:"pipeline"`
+	PipelineResult string                            `json:"pipeline_result,omitempty"`
+	WorkflowID     string                            `json:"workflow_id,omitempty"`
+	RunID          string                            `json:"run_id,omitempty"`
+	Results        []pipelineresults.PipelineResults `json:"results"`
+	Report         string                            `json:"report,omitempty"`
+}
+
+type InteropMatrixCell struct {
+	Wallet           string            `json:"wallet"`
+	WalletVersion    string            `json:"wallet_version,omitempty"`
+	CounterpartType  string            `json:"counterpart_type"`
+	Counterpart      string            `json:"counterpart"`
+	CredentialFormat string            `json:"credential_format,omitempty"`
+	Status           string            `json:"status"`
+	TotalRuns        int               `json:"total_runs"`
+	TotalSuccesses   int               `json:"total_successes"`
+	SuccessRate      float64           `json:"success_rate"`
+	LastSuccessAt    string            `json:"last_success_at,omitempty"`
+	Pipelines        []string          `json:"pipelines"`
+	Evidence         []InteropEvidence `json:"evidence"`
+}
+
+type InteropMatrixResponse struct {
+	WalletVersions []string            `json:"wallet_versions"`
+	Issuers        []string            `json:"issuers"`
+	Verifiers      []string            `json:"verifiers"`
+	Cells          []InteropMatrixCell `json:"cells"`
+}
+
+type interopMatrixFilter struct {
+	CounterpartType  string
+	Wallet           string
+	CredentialFormat string
+}
+
+// interopRow is one wallet side of the matrix. Version is empty when the
+// pipeline used a wallet without recording which version was installed.
+type interopRow struct {
+	Wallet  string
+	Version string
+}
+
+type interopColumn struct {
+	Type        string
+	Counterpart string
+	Format      string
+}
+
+func HandleGetInteropMatrix() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		query := e.Request.URL.Query()
+
+		exportFormat := queryOrDefault(query.Get("format"), interopExportJSON)
+		if exportFormat != interopExportJSON && exportFormat != interopExportCSV {
+			return apierror.New(
+				http.StatusBadRequest,
+				"format",
+				"invalid format",
+				"format must be json or csv",
+			)
+		}
+
+		filter := interopMatrixFilter{
+			CounterpartType:  query.Get("counterpart_type"),
+			Wallet:           strings.Trim(query.Get("wallet"), "/"),
+			CredentialFormat: query.Get("credential_format"),
+		}
+		if filter.CounterpartType != "" &&
+			filter.CounterpartType != InteropCounterpartIssuer &&
+			filter.CounterpartType != InteropCounterpartVerifier {
+			return apierror.New(
+				http.StatusBadRequest,
+				"counterpart_type",
+				"invalid counterpart_type",
+				"counterpart_type must be issuer or verifier",
+			)
+		}
+
+		matrix, err := buildInteropMatrix(e.App, filter)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"scoreboard",
+				"failed to build interoperability matrix",
+				err.Error(),
+			)
+		}
+
+		if exportFormat == interopExportCSV {
+			body, err := interopMatrixCSV(matrix)
+			if err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"scoreboard",
+					"failed to encode interoperability matrix",
+					err.Error(),
+				)
+			}
+			e.Response.Header().Set(
+				"Content-Disposition",
+				`attachment; filename="interop-matrix.csv"`,
+			)
+			return e.Blob(http.StatusOK, "text/csv; charset=utf-8", body)
+		}
+
+		return e.JSON(http.StatusOK, matrix)
+	}
+}
+
+// buildInteropMatrix derives the wallet × issuer/verifier × credential format
+// matrix from the scoreboard cache. Every cached pipeline contributes its run
+// totals to each combination its last successful execution exercised.
+func buildInteropMatrix(app core.App, filter interopMatrixFilter) (InteropMatrixResponse, error) {
+	matrix := InteropMatrixResponse{
+		WalletVersions: []string{},
+		Issuers:        []string{},
+		Verifiers:      []string{},
+		Cells:          []InteropMatrixCell{},
+	}
+	if _, err := app.FindCollectionByNameOrId(scoreboardCacheCollection); err != nil {
+		return matrix, nil
+	}
+	records, err := app.FindAllRecords(scoreboardCacheCollection)
+	if err != nil {
+		return matrix, err
+	}
+
+	resolver := newInteropResolver(app)
+	cells := map[string]*InteropMatrixCell{}
+	for _, record := range records {
+		pipelinePath := resolver.path("pipelines", record.GetString("pipeline"))
+		if pipelinePath == "" {
+			continue
+		}
+		evidence, lastSuccessAt := resolver.evidence(
+			pipelinePath,
+			record.GetString("latest_successful_execution"),
+		)
+
+		for _, row := range resolver.rows(record) {
+			if !filter.matchesWallet(row) {
+				continue
+			}
+			for _, column := range resolver.columns(record) {
+				if !filter.matchesColumn(column) {
+					continue
+				}
+				key := strings.Join(
+					[]string{row.Wallet, row.Version, column.Type, column.Counterpart, column.Format},
+					"\x00",
+				)
+				cell, ok := cells[key]
+				if !ok {
+					cell = &InteropMatrixCell{
+						Wallet:           row.Wallet,
+						WalletVersion:    row.Version,
+						CounterpartType:  column.Type,
+						Counterpart:      column.Counterpart,
+						CredentialFormat: column.Format,
+						Pipelines:        []string{},
+						Evidence:         []InteropEvidence{},
+					}
+					cells[key] = cell
+				}
+				cell.TotalRuns += record.GetInt("total_runs")
+				cell.TotalSuccesses += record.GetInt("total_successes")
+				cell.Pipelines = appendUnique(cell.Pipelines, pipelinePath)
+				if evidence != nil {
+					cell.Evidence = append(cell.Evidence, *evidence)
+				}
+				if lastSuccessAt > cell.LastSuccessAt {
+					cell.LastSuccessAt = lastSuccessAt
+				}
+			}
+		}
+	}
+
+	walletVersions := map[string]struct{}{}
+	issuers := map[string]struct{}{}
+	verifiers := map[string]struct{}{}
+	for _, cell := range cells {
+		cell.SuccessRate = percentage(cell.TotalSuccesses, cell.TotalRuns)
+		cell.Status = interopStatus(cell.TotalRuns, cell.TotalSuccesses)
+		sort.Strings(cell.Pipelines)
+
+		walletVersions[interopRowLabel(cell.Wallet, cell.WalletVersion)] = struct{}{}
+		if cell.CounterpartType == InteropCounterpartIssuer {
+			issuers[cell.Counterpart] = struct{}{}
+		} else {
+			verifiers[cell.Counterpart] = struct{}{}
+		}
+		matrix.Cells = append(matrix.Cells, *cell)
+	}
+
+	sort.Slice(matrix.Cells, func(i, j int) bool {
+		a, b := matrix.Cells[i], matrix.Cells[j]
+		if a.Wallet != b.Wallet {
+			return a.Wallet < b.Wallet
+		}
+		if a.WalletVersion != b.WalletVersion {
+			return a.WalletVersion < b.WalletVersion
+		}
+		if a.CounterpartType != b.CounterpartType {
+			return a.CounterpartType < b.CounterpartType
+		}
+		if a.Counterpart != b.Counterpart {
+			return a.Counterpart < b.Counterpart
+		}
+		return a.CredentialFormat < b.CredentialFormat
+	})
+	matrix.WalletVersions = sortedKeys(walletVersions)
+	matrix.Issuers = sortedKeys(issuers)
+	matrix.Verifiers = sortedKeys(verifiers)
+	return matrix, nil
+}
+
+func (f interopMatrixFilter) matchesWallet(row interopRow) bool {
+	return f.Wallet == "" || f.Wallet == row.Wallet || f.Wallet == row.Version
+}
+
+func (f interopMatrixFilter) matchesColumn(column interopColumn) bool {
+	if f.CounterpartType != "" && f.CounterpartType != column.Type {
+		return false
+	}
+	return f.CredentialFormat == "" || f.CredentialFormat == column.Format
+}
+
+func interopStatus(runs, successes int) string {
+	switch {
+	case runs > 0 && successes >= runs:
+		return InteropStatusPassing
+	case successes > 0:
+		return InteropStatusPartial
+	default:
+		return InteropStatusFailing
+	}
+}
+
+func interopRowLabel(wallet, version string) string {
+	if version != "" {
+		return version
+	}
+	return wallet
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := mapKeysToSlice(set)
+	sort.Strings(keys)
+	return keys
+}
+
+func interopMatrixCSV(matrix InteropMatrixResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{
+		"wallet",
+		"wallet_version",
+		"counterpart_type",
+		"counterpart",
+		"credential_format",
+		"status",
+		"total_runs",
+		"total_successes",
+		"success_rate",
+		"last_success_at",
+		"pipelines",
+		"evidence",
+	}); err != nil {
+		return nil, err
+	}
+	for _, cell := range matrix.Cells {
+		var evidence []string
+		for _, item := range cell.Evidence {
+			for _, result := range item.Results {
+				evidence = append(evidence, result.Video)
+			}
+			if item.Report != "" {
+				evidence = append(evidence, item.Report)
+			}
+		}
+		if err := writer.Write([]string{
+			cell.Wallet,
+			cell.WalletVersion,
+			cell.CounterpartType,
+			cell.Counterpart,
+			cell.CredentialFormat,
+			cell.Status,
+			strconv.Itoa(cell.TotalRuns),
+			strconv.Itoa(cell.TotalSuccesses),
+			strconv.FormatFloat(cell.SuccessRate, 'f', -1, 64),
+			cell.LastSuccessAt,
+			strings.Join(cell.Pipelines, " "),
+			strings.Join(evidence, " "),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// interopResolver memoises record lookups and canonified paths, since the
+// same wallets, issuers and verifiers show up across many cached pipelines.
+type interopResolver struct {
+	app     core.App
+	records map[string]*core.Record
+	paths   map[string]string
+}
+
+func newInteropResolver(app core.App) *interopResolver {
+	return &interopResolver{
+		app:     app,
+		records: map[string]*core.Record{},
+		paths:   map[string]string{},
+	}
+}
+
+func (r *interopResolver) record(collection, id string) *core.Record {
+	if id == "" {
+		return nil
+	}
+	key := collection + "/" + id
+	if record, ok := r.records[key]; ok {
+		return record
+	}
+	record, err := r.app.FindRecordById(collection, id)
+	if err != nil {
+		record = nil
+	}
+	r.records[key] = record
+	return record
+}
+
+func (r *interopResolver) path(collection, id string) string {
+	key := collection + "/" + id
+	if path, ok := r.paths[key]; ok {
+		return path
+	}
+	path := ""
+	if record := r.record(collection, id); record != nil {
+		if built, err := canonify.BuildPath(
+			r.app,
+			record,
+			canonify.CanonifyPaths[collection],
+			"",
+		); err == nil {
+			path = built
+		}
+	}
+	r.paths[key] = path
+	return path
+}
+
+func (r *interopResolver) rows(cache *core.Record) []interopRow {
+	var rows []interopRow
+	covered := map[string]bool{}
+	for _, versionID := range cache.GetStringSlice("wallet_versions") {
+		version := r.record("wallet_versions", versionID)
+		if version == nil {
+			continue
+		}
+		walletID := version.GetString("wallet")
+		wallet := r.path("wallets", walletID)
+		versionPath := r.path("wallet_versions", versionID)
+		if wallet == "" || versionPath == "" {
+			continue
+		}
+		covered[walletID] = true
+		rows = append(rows, interopRow{Wallet: wallet, Version: versionPath})
+	}
+	for _, walletID := range cache.GetStringSlice("wallets") {
+		if covered[walletID] {
+			continue
+		}
+		if wallet := r.path("wallets", walletID); wallet != "" {
+			rows = append(rows, interopRow{Wallet: wallet})
+		}
+	}
+	return rows
+}
+
+func (r *interopResolver) columns(cache *core.Record) []interopColumn {
+	credentialIDs := cache.GetStringSlice("credentials")
+	var columns []interopColumn
+
+	for _, issuerID := range cache.GetStringSlice("issuers") {
+		issuer := r.path("credential_issuers", issuerID)
+		if issuer == "" {
+			continue
+		}
+		var formats []string
+		for _, credentialID := range credentialIDs {
+			credential := r.record("credentials", credentialID)
+			if credential != nil && credential.GetString("credential_issuer") == issuerID {
+				formats = appendUniqueNonEmpty(formats, credential.GetString("format"))
+			}
+		}
+		columns = appendInteropColumns(columns, InteropCounterpartIssuer, issuer, formats)
+	}
+
+	for _, verifierID := range cache.GetStringSlice("verifiers") {
+		verifier := r.path("verifiers", verifierID)
+		if verifier == "" {
+			continue
+		}
+		var formats []string
+		for _, useCaseID := range cache.GetStringSlice("use_case_verifications") {
+			useCase := r.record("use_cases_verifications", useCaseID)
+			if useCase == nil || useCase.GetString("verifier") != verifierID {
+				continue
+			}
+			for _, credentialID := range useCase.GetStringSlice("credentials") {
+				if credential := r.record("credentials", credentialID); credential != nil {
+					formats = appendUniqueNonEmpty(formats, credential.GetString("format"))
+				}
+			}
+		}
+		if len(formats) == 0 {
+			if record := r.record("verifiers", verifierID); record != nil {
+				for _, format := range record.GetStringSlice("format") {
+					formats = appendUniqueNonEmpty(formats, format)
+				}
+			}
+		}
+		columns = appendInteropColumns(columns, InteropCounterpartVerifier, verifier, formats)
+	}
+	return columns
+}
+
+// evidence returns the artifacts of the latest successful execution of a
+// cached pipeline and when it was recorded.
+func (r *interopResolver) evidence(pipeline, resultID string) (*InteropEvidence, string) {
+	result := r.record("pipeline_results", resultID)
+	if result == nil {
+		return nil, ""
+	}
+	artifacts := pipelineresults.BuildPipelineExecutionArtifacts(r.app, result)
+	evidence := &InteropEvidence{
+		Pipeline:       pipeline,
+		PipelineResult: r.path("pipeline_results", resultID),
+		WorkflowID:     result.GetString("workflow_id"),
+		RunID:          result.GetString("run_id"),
+		Results:        artifacts.Results,
+		Report:         artifacts.Report,
+	}
+	return evidence, result.GetDateTime("created").Time().UTC().Format(time.RFC3339)
+}
+
+func appendInteropColumns(
+	columns []interopColumn,
+	counterpartType string,
+	counterpart string,
+	formats []string,
+) []interopColumn {
+	if len(formats) == 0 {
+		formats = []string{""}
+	}
+	for _, format := range formats {
+		columns = append(columns, interopColumn{
+			Type:        counterpartType,
+			Counterpart: counterpart,
+			Format:      format,
+		})
+	}
+	return columns
+}
+
+func appendUniqueNonEmpty(slice []string, item string) []string {
+	if item == "" {
+		return slice
+	}
+	return appendUnique(slice, item)
+}
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+type interopFixture struct {
+	Wallet     string
+	VersionOne string
+	VersionTwo string
+	Issuer     string
+	Verifier   string
+}
+
+func saveInteropRecord(
+	t testing.TB,
+	app *tests.TestApp,
+	collection string,
+	fields map[string]any,
+) *core.Record {
+	t.Helper()
+
+	coll, err := app.FindCollectionByNameOrId(collection)
+	require.NoError(t, err)
+	record := core.NewRecord(coll)
+	for key, value := range fields {
+		record.Set(key, value)
+	}
+	require.NoError(t, app.Save(record))
+	return record
+}
+
+func saveInteropCache(t testing.TB, app *tests.TestApp, fields map[string]any) {
+	t.Helper()
+
+	fields["first_execution"] = "2026-03-01 10:00:00.000Z"
+	saveInteropRecord(t, app, scoreboardCacheCollection, fields)
+}
+
+// seedInteropMatrix caches four pipelines: two exercising wallet version "one"
+// against the issuer, one exercising "two" against the verifier through a use
+// case and one that only recorded the wallet.
+func seedInteropMatrix(t testing.TB, app *tests.TestApp) interopFixture {
+	t.Helper()
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	wallet := saveInteropRecord(t, app, "wallets", map[string]any{
+		"owner": orgID,
+		"name":  "Acme Wallet",
+	})
+	versionOne := saveInteropRecord(t, app, "wallet_versions", map[string]any{
+		"owner":  orgID,
+		"wallet": wallet.Id,
+		"tag":    "one",
+	})
+	versionTwo := saveInteropRecord(t, app, "wallet_versions", map[string]any{
+		"owner":  orgID,
+		"wallet": wallet.Id,
+		"tag":    "two",
+	})
+	issuer := saveInteropRecord(t, app, "credential_issuers", map[string]any{
+		"owner": orgID,
+		"name":  "Acme Issuer",
+		"url":   "https://issuer.example",
+	})
+	pid := saveInteropRecord(t, app, "credentials", map[string]any{
+		"owner":             orgID,
+		"credential_issuer": issuer.Id,
+		"name":              "pid",
+		"format":            "dc+sd-jwt",
+	})
+	mdl := saveInteropRecord(t, app, "credentials", map[string]any{
+		"owner":             orgID,
+		"credential_issuer": issuer.Id,
+		"name":              "mdl",
+		"format":            "mso_mdoc",
+	})
+	verifier := saveInteropRecord(t, app, "verifiers", map[string]any{
+		"owner":                         orgID,
+		"name":                          "Acme Verifier",
+		"url":                           "https://verifier.example",
+		"standard_and_version":          "testsuite/draft-01",
+		"format":                        []string{"SD-JWT"},
+		"signing_algorithms":            []string{"ES256"},
+		"cryptographic_binding_methods": []string{"jwk"},
+		"description":                   "example description",
+	})
+	useCase := saveInteropRecord(t, app, "use_cases_verifications", map[string]any{
+		"owner":       orgID,
+		"verifier":    verifier.Id,
+		"name":        "login",
+		"yaml":        "example code",
+		"credentials": []string{pid.Id},
+	})
+
+	pipelineOne := createPipelineRecord(t, app, orgID, "issue-all")
+	createPipelineResult(t, app, orgID, pipelineOne.Id, "wf-issue", "run-issue")
+	resultID, err := findPipelineResult(app, "wf-issue", "run-issue")
+	require.NoError(t, err)
+
+	saveInteropCache(t, app, map[string]any{
+		"pipeline":                    pipelineOne.Id,
+		"total_runs":                  10,
+		"total_successes":             10,
+		"wallets":                     []string{wallet.Id},
+		"wallet_versions":             []string{versionOne.Id},
+		"issuers":                     []string{issuer.Id},
+		"credentials":                 []string{pid.Id, mdl.Id},
+		"latest_successful_execution": resultID,
+	})
+	saveInteropCache(t, app, map[string]any{
+		"pipeline":        createPipelineRecord(t, app, orgID, "issue-pid").Id,
+		"total_runs":      5,
+		"total_successes": 0,
+		"wallets":         []string{wallet.Id},
+		"wallet_versions": []string{versionOne.Id},
+		"issuers":         []string{issuer.Id},
+		"credentials":     []string{pid.Id},
+	})
+	saveInteropCache(t, app, map[string]any{
+		"pipeline":               createPipelineRecord(t, app, orgID, "present").Id,
+		"total_runs":             4,
+		"total_successes":        1,
+		"wallets":                []string{wallet.Id},
+		"wallet_versions":        []string{versionTwo.Id},
+		"verifiers":              []string{verifier.Id},
+		"use_case_verifications": []string{useCase.Id},
+	})
+	saveInteropCache(t, app, map[string]any{
+		"pipeline":        createPipelineRecord(t, app, orgID, "external").Id,
+		"total_runs":      2,
+		"total_successes": 0,
+		"wallets":         []string{wallet.Id},
+		"verifiers":       []string{verifier.Id},
+	})
+
+	walletPath := "usera-s-organization/" + wallet.GetString("canonified_name")
+	return interopFixture{
+		Wallet:     walletPath,
+		VersionOne: walletPath + "/" + versionOne.GetString("canonified_tag"),
+		VersionTwo: walletPath + "/" + versionTwo.GetString("canonified_tag"),
+		Issuer:     "usera-s-organization/" + issuer.GetString("canonified_name"),
+		Verifier:   "usera-s-organization/" + verifier.GetString("canonified_name"),
+	}
+}
+
+func setupInteropMatrixApp(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	canonify.RegisterCanonifyHooks(app)
+	ScoreboardInteropRoutes.Add(app)
+	seedInteropMatrix(t, app)
+	return app
+}
+
+func TestBuildInteropMatrix(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	canonify.RegisterCanonifyHooks(app)
+
+	empty, err := buildInteropMatrix(app, interopMatrixFilter{})
+	require.NoError(t, err)
+	require.Empty(t, empty.Cells)
+
+	fixture := seedInteropMatrix(t, app)
+	matrix, err := buildInteropMatrix(app, interopMatrixFilter{})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{fixture.Wallet, fixture.VersionOne, fixture.VersionTwo}, matrix.WalletVersions)
+	require.Equal(t, []string{fixture.Issuer}, matrix.Issuers)
+	require.Equal(t, []string{fixture.Verifier}, matrix.Verifiers)
+	require.Len(t, matrix.Cells, 4)
+
+	walletOnly := matrix.Cells[0]
+	require.Equal(t, fixture.Wallet, walletOnly.Wallet)
+	require.Empty(t, walletOnly.WalletVersion)
+	require.Equal(t, InteropCounterpartVerifier, walletOnly.CounterpartType)
+	require.Equal(t, "SD-JWT", walletOnly.CredentialFormat, "falls back to the verifier formats")
+	require.Equal(t, InteropStatusFailing, walletOnly.Status)
+	require.Empty(t, walletOnly.Evidence)
+
+	sdJWT := matrix.Cells[1]
+	require.Equal(t, fixture.VersionOne, sdJWT.WalletVersion)
+	require.Equal(t, fixture.Issuer, sdJWT.Counterpart)
+	require.Equal(t, "dc+sd-jwt", sdJWT.CredentialFormat)
+	require.Equal(t, 15, sdJWT.TotalRuns)
+	require.Equal(t, 10, sdJWT.TotalSuccesses)
+	require.Equal(t, 66.67, sdJWT.SuccessRate)
+	require.Equal(t, InteropStatusPartial, sdJWT.Status)
+	require.Equal(t, []string{
+		"usera-s-organization/issue-all",
+		"usera-s-organization/issue-pid",
+	}, sdJWT.Pipelines)
+	require.Len(t, sdJWT.Evidence, 1)
+	require.Equal(t, "wf-issue", sdJWT.Evidence[0].WorkflowID)
+	require.Equal(t, "run-issue", sdJWT.Evidence[0].RunID)
+	require.NotEmpty(t, sdJWT.LastSuccessAt)
+
+	mdoc := matrix.Cells[2]
+	require.Equal(t, "mso_mdoc", mdoc.CredentialFormat)
+	require.Equal(t, InteropStatusPassing, mdoc.Status)
+
+	verifier := matrix.Cells[3]
+	require.Equal(t, fixture.VersionTwo, verifier.WalletVersion)
+	require.Equal(t, "dc+sd-jwt", verifier.CredentialFormat, "uses the use case credentials")
+	require.Equal(t, InteropStatusPartial, verifier.Status)
+
+	filtered, err := buildInteropMatrix(app, interopMatrixFilter{
+		CounterpartType: InteropCounterpartIssuer,
+		Wallet:          fixture.VersionOne,
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered.Cells, 2)
+	require.Empty(t, filtered.Verifiers)
+
+	filtered, err = buildInteropMatrix(app, interopMatrixFilter{CredentialFormat: "dc+sd-jwt"})
+	require.NoError(t, err)
+	require.Len(t, filtered.Cells, 2)
+}
+
+func TestInteropMatrixCSV(t *testing.T) {
+	body, err := interopMatrixCSV(InteropMatrixResponse{
+		Cells: []InteropMatrixCell{
+			{
+				Wallet:           "org/wallet",
+				WalletVersion:    "org/wallet/v1",
+				CounterpartType:  InteropCounterpartIssuer,
+				Counterpart:      "org/issuer",
+				CredentialFormat: "dc+sd-jwt",
+				Status:           InteropStatusPartial,
+				TotalRuns:        3,
+				TotalSuccesses:   2,
+				SuccessRate:      66.67,
+				Pipelines:        []string{"org/a", "org/b"},
+				Evidence: []InteropEvidence{
+					{Report: "https://credimi.test/report.pdf"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "wallet,wallet_version,counterpart_type"))
+	require.Equal(
+		t,
+		"org/wallet,org/wallet/v1,issuer,org/issuer,dc+sd-jwt,partial,3,2,66.67,,org/a org/b,https://credimi.test/report.pdf",
+		lines[1],
+	)
+}
+
+func TestInteropStatus(t *testing.T) {
+	require.Equal(t, InteropStatusPassing, interopStatus(3, 3))
+	require.Equal(t, InteropStatusPartial, interopStatus(3, 1))
+	require.Equal(t, InteropStatusFailing, interopStatus(3, 0))
+	require.Equal(t, InteropStatusFailing, interopStatus(0, 0))
+}
+
+func TestGetInteropMatrix(t *testing.T) {
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "invalid format",
+			Method:          http.MethodGet,
+			URL:             "/api/scoreboard/interop-matrix?format=xml",
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"invalid format"`},
+			TestAppFactory:  setupInteropMatrixApp,
+		},
+		{
+			Name:            "invalid counterpart type",
+			Method:          http.MethodGet,
+			URL:             "/api/scoreboard/interop-matrix?counterpart_type=wallet",
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"invalid counterpart_type"`},
+			TestAppFactory:  setupInteropMatrixApp,
+		},
+		{
+			Name:           "json matrix",
+			Method:         http.MethodGet,
+			URL:            "/api/scoreboard/interop-matrix",
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"issuers":["usera-s-organization/acme-issuer"]`,
+				`"verifiers":["usera-s-organization/acme-verifier"]`,
+				`"credential_format":"mso_mdoc"`,
+				`"status":"passing"`,
+				`"workflow_id":"wf-issue"`,
+			},
+			TestAppFactory: setupInteropMatrixApp,
+		},
+		{
+			Name:           "csv export filtered to verifiers",
+			Method:         http.MethodGet,
+			URL:            "/api/scoreboard/interop-matrix?format=csv&counterpart_type=verifier",
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				"wallet,wallet_version,counterpart_type,counterpart,credential_format",
+				"usera-s-organization/acme-wallet,,verifier,usera-s-organization/acme-verifier,SD-JWT,failing,2,0,0",
+			},
+			NotExpectedContent: []string{"acme-issuer"},
+			TestAppFactory:     setupInteropMatrixApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
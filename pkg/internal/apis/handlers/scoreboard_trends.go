@@ -0,0 +1,460 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	scoreboardSnapshotsCollection = "pipeline_scoreboard_snapshots"
+
+	// scoreboardSnapshotInterval is the minimum distance between two snapshots:
+	// the aggregate scoreboard may be refreshed every few minutes, trends only
+	// need a point per hour.
+	scoreboardSnapshotInterval = time.Hour
+
+	// scoreboardRegressionThreshold is the drop, in success rate points,
+	// between the last two periods of a series that flags a regression.
+	scoreboardRegressionThreshold = 10.0
+
+	TrendDimensionPipeline      = "pipeline"
+	TrendDimensionWallet        = "wallet"
+	TrendDimensionWalletVersion = "wallet_version"
+	TrendDimensionIssuer        = "issuer"
+	TrendDimensionVerifier      = "verifier"
+
+	TrendBucketDay  = "day"
+	TrendBucketWeek = "week"
+)
+
+var scoreboardTrendWindows = map[string]time.Duration{
+	"7d":   7 * 24 * time.Hour,
+	"30d":  30 * 24 * time.Hour,
+	"90d":  90 * 24 * time.Hour,
+	"365d": 365 * 24 * time.Hour,
+}
+
+var scoreboardNow = time.Now
+
+var ScoreboardTrendRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/scoreboard",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:         http.MethodGet,
+			Path:           "/trends",
+			OperationID:    "getScoreboardTrends",
+			Handler:        HandleGetScoreboardTrends,
+			ResponseSchema: ScoreboardTrendsResponse{},
+			Summary:        "Get scoreboard trends",
+			Description:    "Returns success rate, run count and duration trends built from periodic scoreboard snapshots.",
+			QuerySearchAttributes: []routing.QuerySearchAttribute{
+				{
+					Name:        "dimension",
+					Required:    false,
+					Description: "Group series by pipeline (default), wallet, wallet_version, issuer or verifier",
+				},
+				{
+					Name:        "window",
+					Required:    false,
+					Description: "Time window: 7d, 30d (default), 90d or 365d",
+				},
+				{
+					Name:        "bucket",
+					Required:    false,
+					Description: "Point granularity: day (default) or week",
+				},
+				{
+					Name:        "key",
+					Required:    false,
+					Description: "Only return the series with this key (pipeline ID or wallet, wallet version, issuer or verifier identifier)",
+				},
+			},
+		},
+	},
+}
+
+type ScoreboardTrendPoint struct {
+	Date                string   `json:"date"`
+	TotalRuns           int      `json:"total_runs"`
+	TotalSuccesses      int      `json:"total_successes"`
+	SuccessRate         float64  `json:"success_rate"`
+	Runs                int      `json:"runs"`
+	PeriodSuccessRate   *float64 `json:"period_success_rate,omitempty"`
+	MinExecutionSeconds float64  `json:"min_execution_seconds,omitempty"`
+}
+
+type ScoreboardTrendSeries struct {
+	Key              string                 `json:"key"`
+	Label            string                 `json:"label,omitempty"`
+	Points           []ScoreboardTrendPoint `json:"points"`
+	SuccessRateDelta *float64               `json:"success_rate_delta,omitempty"`
+	Regression       bool                   `json:"regression"`
+}
+
+type ScoreboardTrendsResponse struct {
+	Dimension string                  `json:"dimension"`
+	Window    string                  `json:"window"`
+	Bucket    string                  `json:"bucket"`
+	From      string                  `json:"from"`
+	To        string                  `json:"to"`
+	Series    []ScoreboardTrendSeries `json:"series"`
+}
+
+type scoreboardSnapshot struct {
+	PipelineID          string
+	PipelineName        string
+	At                  time.Time
+	TotalRuns           int
+	TotalSuccesses      int
+	MinExecutionSeconds float64
+	Wallets             []string
+	WalletVersions      []string
+	Issuers             []string
+	Verifiers           []string
+}
+
+// saveScoreboardSnapshot appends one snapshot per aggregated pipeline, unless
+// the latest snapshot is younger than scoreboardSnapshotInterval. It returns
+// the number of stored snapshots.
+func saveScoreboardSnapshot(
+	app core.App,
+	pipelines []workflows.AggregatedPipelineStats,
+) (int, error) {
+	coll, err := app.FindCollectionByNameOrId(scoreboardSnapshotsCollection)
+	if err != nil {
+		return 0, nil
+	}
+
+	now := scoreboardNow().UTC()
+	latest, err := app.FindRecordsByFilter(coll, "", "-snapshot_at", 1, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find latest snapshot: %w", err)
+	}
+	if len(latest) > 0 &&
+		now.Sub(latest[0].GetDateTime("snapshot_at").Time()) < scoreboardSnapshotInterval {
+		return 0, nil
+	}
+
+	snapshotAt, err := types.ParseDateTime(now)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var saveErrors []string
+	for _, stats := range pipelines {
+		record := core.NewRecord(coll)
+		record.Set("pipeline", stats.PipelineID)
+		record.Set("pipeline_name", stats.PipelineName)
+		record.Set("snapshot_at", snapshotAt)
+		record.Set("total_runs", stats.TotalRuns)
+		record.Set("total_successes", stats.TotalSuccesses)
+		record.Set("success_rate", stats.SuccessRate)
+		record.Set("min_execution_seconds", durationStringSeconds(stats.MinExecutionTime))
+		if stats.LastExecution != nil {
+			record.Set("wallets", nonNilStrings(stats.LastExecution.WalletUsed))
+			record.Set("wallet_versions", nonNilStrings(stats.LastExecution.WalletVersionUsed))
+			record.Set("issuers", nonNilStrings(stats.LastExecution.Issuers))
+			record.Set("verifiers", nonNilStrings(stats.LastExecution.Verifiers))
+		}
+		if err := app.Save(record); err != nil {
+			saveErrors = append(saveErrors, fmt.Sprintf("pipeline %s: %v", stats.PipelineID, err))
+			continue
+		}
+		count++
+	}
+	if len(saveErrors) > 0 {
+		return count, fmt.Errorf("failed to save snapshots: %s", strings.Join(saveErrors, "; "))
+	}
+	return count, nil
+}
+
+func HandleGetScoreboardTrends() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		query := e.Request.URL.Query()
+		dimension := queryOrDefault(query.Get("dimension"), TrendDimensionPipeline)
+		window := queryOrDefault(query.Get("window"), "30d")
+		bucket := queryOrDefault(query.Get("bucket"), TrendBucketDay)
+		key := strings.TrimSpace(query.Get("key"))
+
+		switch dimension {
+		case TrendDimensionPipeline, TrendDimensionWallet, TrendDimensionWalletVersion,
+			TrendDimensionIssuer, TrendDimensionVerifier:
+		default:
+			return apierror.New(
+				http.StatusBadRequest,
+				"dimension",
+				"invalid dimension",
+				"dimension must be one of pipeline, wallet, wallet_version, issuer or verifier",
+			)
+		}
+		windowDuration, ok := scoreboardTrendWindows[window]
+		if !ok {
+			return apierror.New(
+				http.StatusBadRequest,
+				"window",
+				"invalid window",
+				"window must be one of 7d, 30d, 90d or 365d",
+			)
+		}
+		if bucket != TrendBucketDay && bucket != TrendBucketWeek {
+			return apierror.New(
+				http.StatusBadRequest,
+				"bucket",
+				"invalid bucket",
+				"bucket must be day or week",
+			)
+		}
+
+		to := scoreboardNow().UTC()
+		from := to.Add(-windowDuration)
+		snapshots, err := findScoreboardSnapshots(e.App, from)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"scoreboard",
+				"failed to load scoreboard snapshots",
+				err.Error(),
+			)
+		}
+
+		series := buildScoreboardTrends(snapshots, dimension, bucket)
+		if key != "" {
+			filtered := []ScoreboardTrendSeries{}
+			for _, s := range series {
+				if s.Key == key {
+					filtered = append(filtered, s)
+				}
+			}
+			series = filtered
+		}
+
+		return e.JSON(http.StatusOK, ScoreboardTrendsResponse{
+			Dimension: dimension,
+			Window:    window,
+			Bucket:    bucket,
+			From:      from.Format(time.RFC3339),
+			To:        to.Format(time.RFC3339),
+			Series:    series,
+		})
+	}
+}
+
+func findScoreboardSnapshots(app core.App, from time.Time) ([]scoreboardSnapshot, error) {
+	coll, err := app.FindCollectionByNameOrId(scoreboardSnapshotsCollection)
+	if err != nil {
+		return nil, nil
+	}
+	records, err := app.FindRecordsByFilter(
+		coll,
+		"snapshot_at >= {:from}",
+		"snapshot_at",
+		0,
+		0,
+		dbx.Params{"from": from.Format(types.DefaultDateLayout)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]scoreboardSnapshot, 0, len(records))
+	for _, record := range records {
+		snapshots = append(snapshots, scoreboardSnapshot{
+			PipelineID:          record.GetString("pipeline"),
+			PipelineName:        record.GetString("pipeline_name"),
+			At:                  record.GetDateTime("snapshot_at").Time(),
+			TotalRuns:           record.GetInt("total_runs"),
+			TotalSuccesses:      record.GetInt("total_successes"),
+			MinExecutionSeconds: record.GetFloat("min_execution_seconds"),
+			Wallets:             jsonStringSlice(record, "wallets"),
+			WalletVersions:      jsonStringSlice(record, "wallet_versions"),
+			Issuers:             jsonStringSlice(record, "issuers"),
+			Verifiers:           jsonStringSlice(record, "verifiers"),
+		})
+	}
+	return snapshots, nil
+}
+
+// buildScoreboardTrends keeps the latest snapshot of each pipeline per bucket,
+// sums them per dimension key and derives the runs and success rate of each
+// period from the difference with the previous bucket.
+func buildScoreboardTrends(
+	snapshots []scoreboardSnapshot,
+	dimension string,
+	bucket string,
+) []ScoreboardTrendSeries {
+	type bucketKey struct {
+		date       string
+		pipelineID string
+	}
+	latest := map[bucketKey]scoreboardSnapshot{}
+	for _, snapshot := range snapshots {
+		k := bucketKey{trendBucketStart(snapshot.At, bucket), snapshot.PipelineID}
+		if current, ok := latest[k]; !ok || snapshot.At.After(current.At) {
+			latest[k] = snapshot
+		}
+	}
+
+	type seriesAcc struct {
+		label  string
+		points map[string]*ScoreboardTrendPoint
+	}
+	bySeries := map[string]*seriesAcc{}
+	for k, snapshot := range latest {
+		for _, seriesKey := range snapshotDimensionKeys(snapshot, dimension) {
+			acc, ok := bySeries[seriesKey]
+			if !ok {
+				acc = &seriesAcc{points: map[string]*ScoreboardTrendPoint{}}
+				bySeries[seriesKey] = acc
+			}
+			if dimension == TrendDimensionPipeline {
+				acc.label = snapshot.PipelineName
+			}
+			point, ok := acc.points[k.date]
+			if !ok {
+				point = &ScoreboardTrendPoint{Date: k.date}
+				acc.points[k.date] = point
+			}
+			point.TotalRuns += snapshot.TotalRuns
+			point.TotalSuccesses += snapshot.TotalSuccesses
+			if snapshot.MinExecutionSeconds > 0 &&
+				(point.MinExecutionSeconds == 0 || snapshot.MinExecutionSeconds < point.MinExecutionSeconds) {
+				point.MinExecutionSeconds = snapshot.MinExecutionSeconds
+			}
+		}
+	}
+
+	series := make([]ScoreboardTrendSeries, 0, len(bySeries))
+	for seriesKey, acc := range bySeries {
+		dates := make([]string, 0, len(acc.points))
+		for date := range acc.points {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+
+		s := ScoreboardTrendSeries{Key: seriesKey, Label: acc.label}
+		var previous *ScoreboardTrendPoint
+		for _, date := range dates {
+			point := acc.points[date]
+			point.SuccessRate = percentage(point.TotalSuccesses, point.TotalRuns)
+			if previous != nil {
+				runs := point.TotalRuns - previous.TotalRuns
+				successes := point.TotalSuccesses - previous.TotalSuccesses
+				if runs > 0 && successes >= 0 {
+					point.Runs = runs
+					rate := percentage(successes, runs)
+					point.PeriodSuccessRate = &rate
+				}
+			}
+			s.Points = append(s.Points, *point)
+			previous = point
+		}
+		s.SuccessRateDelta, s.Regression = trendRegression(s.Points)
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Key < series[j].Key
+	})
+	return series
+}
+
+// trendRegression compares the success rate of the last two periods that had
+// runs.
+func trendRegression(points []ScoreboardTrendPoint) (*float64, bool) {
+	var rates []float64
+	for _, point := range points {
+		if point.PeriodSuccessRate != nil {
+			rates = append(rates, *point.PeriodSuccessRate)
+		}
+	}
+	if len(rates) < 2 {
+		return nil, false
+	}
+	delta := math.Round((rates[len(rates)-1]-rates[len(rates)-2])*100) / 100
+	return &delta, delta <= -scoreboardRegressionThreshold
+}
+
+func snapshotDimensionKeys(snapshot scoreboardSnapshot, dimension string) []string {
+	switch dimension {
+	case TrendDimensionWallet:
+		return snapshot.Wallets
+	case TrendDimensionWalletVersion:
+		return snapshot.WalletVersions
+	case TrendDimensionIssuer:
+		return snapshot.Issuers
+	case TrendDimensionVerifier:
+		return snapshot.Verifiers
+	default:
+		return []string{snapshot.PipelineID}
+	}
+}
+
+func trendBucketStart(t time.Time, bucket string) string {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == TrendBucketWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day.Format(time.DateOnly)
+}
+
+func percentage(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+// durationStringSeconds parses the durations produced by formatDurationString.
+func durationStringSeconds(value string) float64 {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+	return d.Seconds()
+}
+
+func jsonStringSlice(record *core.Record, field string) []string {
+	var values []string
+	if err := record.UnmarshalJSONField(field, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func queryOrDefault(value string, fallback string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
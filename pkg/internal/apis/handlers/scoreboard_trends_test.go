@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+var trendsTestNow = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+func withScoreboardNow(t testing.TB, now time.Time) {
+	t.Helper()
+	orig := scoreboardNow
+	scoreboardNow = func() time.Time { return now }
+	t.Cleanup(func() { scoreboardNow = orig })
+}
+
+func ensureScoreboardSnapshotsCollection(t testing.TB, app *tests.TestApp) *core.Collection {
+	t.Helper()
+
+	if coll, err := app.FindCollectionByNameOrId(scoreboardSnapshotsCollection); err == nil {
+		return coll
+	}
+	pipelines, err := app.FindCollectionByNameOrId("pipelines")
+	require.NoError(t, err)
+
+	coll := core.NewBaseCollection(scoreboardSnapshotsCollection)
+	coll.ListRule = new(string)
+	coll.ViewRule = new(string)
+	coll.Fields.Add(
+		&core.RelationField{Name: "pipeline", CollectionId: pipelines.Id, MaxSelect: 1, Required: true},
+		&core.TextField{Name: "pipeline_name"},
+		&core.DateField{Name: "snapshot_at", Required: true},
+		&core.NumberField{Name: "total_runs"},
+		&core.NumberField{Name: "total_successes"},
+		&core.NumberField{Name: "success_rate"},
+		&core.NumberField{Name: "min_execution_seconds"},
+		&core.JSONField{Name: "wallets"},
+		&core.JSONField{Name: "wallet_versions"},
+		&core.JSONField{Name: "issuers"},
+		&core.JSONField{Name: "verifiers"},
+	)
+	require.NoError(t, app.Save(coll))
+	return coll
+}
+
+func aggregatedStats(pipelineID string, runs, successes int, walletVersion string) workflows.AggregatedPipelineStats {
+	return workflows.AggregatedPipelineStats{
+		PipelineID:       pipelineID,
+		PipelineName:     "pipeline123",
+		TotalRuns:        runs,
+		TotalSuccesses:   successes,
+		MinExecutionTime: "1m30s",
+		LastExecution: &workflows.LatestExecutionDetails{
+			WalletUsed:        []string{"org/wallet"},
+			WalletVersionUsed: []string{walletVersion},
+		},
+	}
+}
+
+func TestSaveScoreboardSnapshotIsThrottled(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	canonify.RegisterCanonifyHooks(app)
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	pipelineRecord := createPipelineRecord(t, app, orgID, "pipeline123")
+
+	count, err := saveScoreboardSnapshot(app, nil)
+	require.NoError(t, err)
+	require.Zero(t, count, "no snapshots without the collection")
+
+	ensureScoreboardSnapshotsCollection(t, app)
+	stats := []workflows.AggregatedPipelineStats{
+		aggregatedStats(pipelineRecord.Id, 10, 9, "org/wallet/v1"),
+	}
+
+	withScoreboardNow(t, trendsTestNow)
+	count, err = saveScoreboardSnapshot(app, stats)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	withScoreboardNow(t, trendsTestNow.Add(10*time.Minute))
+	count, err = saveScoreboardSnapshot(app, stats)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	withScoreboardNow(t, trendsTestNow.Add(scoreboardSnapshotInterval))
+	count, err = saveScoreboardSnapshot(app, stats)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	records, err := app.FindAllRecords(scoreboardSnapshotsCollection)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, 90.0, records[0].GetFloat("min_execution_seconds"))
+	require.Equal(t, []string{"org/wallet/v1"}, jsonStringSlice(records[0], "wallet_versions"))
+}
+
+func TestBuildScoreboardTrends(t *testing.T) {
+	day := func(d int, hour int) time.Time {
+		return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	snapshots := []scoreboardSnapshot{
+		{PipelineID: "p1", PipelineName: "one", At: day(16, 8), TotalRuns: 5, TotalSuccesses: 5, WalletVersions: []string{"w/v1"}},
+		// Later snapshot of the same day wins.
+		{PipelineID: "p1", PipelineName: "one", At: day(16, 20), TotalRuns: 10, TotalSuccesses: 10, MinExecutionSeconds: 40, WalletVersions: []string{"w/v1"}},
+		{PipelineID: "p2", PipelineName: "two", At: day(16, 20), TotalRuns: 10, TotalSuccesses: 8, MinExecutionSeconds: 30, WalletVersions: []string{"w/v1"}},
+		{PipelineID: "p1", PipelineName: "one", At: day(17, 20), TotalRuns: 20, TotalSuccesses: 20, WalletVersions: []string{"w/v1"}},
+		{PipelineID: "p2", PipelineName: "two", At: day(17, 20), TotalRuns: 20, TotalSuccesses: 18, WalletVersions: []string{"w/v1"}},
+		// New wallet version ships and p1 starts failing.
+		{PipelineID: "p1", PipelineName: "one", At: day(18, 20), TotalRuns: 30, TotalSuccesses: 22, WalletVersions: []string{"w/v2"}},
+	}
+
+	byPipeline := buildScoreboardTrends(snapshots, TrendDimensionPipeline, TrendBucketDay)
+	require.Len(t, byPipeline, 2)
+	p1 := byPipeline[0]
+	require.Equal(t, "p1", p1.Key)
+	require.Equal(t, "one", p1.Label)
+	require.Len(t, p1.Points, 3)
+	require.Equal(t, "2026-03-16", p1.Points[0].Date)
+	require.Equal(t, 10, p1.Points[0].TotalRuns)
+	require.Equal(t, 40.0, p1.Points[0].MinExecutionSeconds)
+	require.Nil(t, p1.Points[0].PeriodSuccessRate)
+	require.Equal(t, 10, p1.Points[1].Runs)
+	require.Equal(t, 100.0, *p1.Points[1].PeriodSuccessRate)
+	require.Equal(t, 10, p1.Points[2].Runs)
+	require.Equal(t, 20.0, *p1.Points[2].PeriodSuccessRate)
+	require.Equal(t, 73.33, p1.Points[2].SuccessRate)
+	require.Equal(t, -80.0, *p1.SuccessRateDelta)
+	require.True(t, p1.Regression)
+	require.False(t, byPipeline[1].Regression)
+
+	byVersion := buildScoreboardTrends(snapshots, TrendDimensionWalletVersion, TrendBucketDay)
+	require.Len(t, byVersion, 2)
+	require.Equal(t, "w/v1", byVersion[0].Key)
+	require.Len(t, byVersion[0].Points, 2)
+	require.Equal(t, 20, byVersion[0].Points[0].TotalRuns)
+	require.Equal(t, 30.0, byVersion[0].Points[0].MinExecutionSeconds)
+	require.Equal(t, 20, byVersion[0].Points[1].Runs)
+	require.Equal(t, 100.0, *byVersion[0].Points[1].PeriodSuccessRate)
+	require.Equal(t, 95.0, byVersion[0].Points[1].SuccessRate)
+	require.Equal(t, "w/v2", byVersion[1].Key)
+
+	byWeek := buildScoreboardTrends(snapshots, TrendDimensionPipeline, TrendBucketWeek)
+	require.Len(t, byWeek[0].Points, 1)
+	require.Equal(t, "2026-03-16", byWeek[0].Points[0].Date)
+	require.Equal(t, 30, byWeek[0].Points[0].TotalRuns)
+}
+
+func TestScoreboardTrendHelpers(t *testing.T) {
+	require.Equal(t, "2026-03-16", trendBucketStart(time.Date(2026, 3, 22, 23, 0, 0, 0, time.UTC), TrendBucketWeek))
+	require.Equal(t, "2026-03-22", trendBucketStart(time.Date(2026, 3, 22, 23, 0, 0, 0, time.UTC), TrendBucketDay))
+	require.Equal(t, 45.0, durationStringSeconds("45s"))
+	require.Equal(t, 3723.0, durationStringSeconds("1h2m3s"))
+	require.Zero(t, durationStringSeconds(""))
+	require.Equal(t, 66.67, percentage(2, 3))
+	require.Zero(t, percentage(1, 0))
+}
+
+func setupScoreboardTrendsApp(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	canonify.RegisterCanonifyHooks(app)
+	ScoreboardTrendRoutes.Add(app)
+
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	pipelineRecord := createPipelineRecord(t, app, orgID, "pipeline123")
+	ensureScoreboardSnapshotsCollection(t, app)
+
+	for i, stats := range []workflows.AggregatedPipelineStats{
+		aggregatedStats(pipelineRecord.Id, 10, 10, "org/wallet/v1"),
+		aggregatedStats(pipelineRecord.Id, 20, 20, "org/wallet/v1"),
+		aggregatedStats(pipelineRecord.Id, 30, 21, "org/wallet/v2"),
+	} {
+		withScoreboardNow(t, trendsTestNow.AddDate(0, 0, i-2))
+		_, err := saveScoreboardSnapshot(app, []workflows.AggregatedPipelineStats{stats})
+		require.NoError(t, err)
+	}
+	withScoreboardNow(t, trendsTestNow)
+	return app
+}
+
+func TestGetScoreboardTrends(t *testing.T) {
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "invalid dimension",
+			Method:          http.MethodGet,
+			URL:             "/api/scoreboard/trends?dimension=runner",
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"invalid dimension"`},
+			TestAppFactory:  setupScoreboardTrendsApp,
+		},
+		{
+			Name:            "invalid window",
+			Method:          http.MethodGet,
+			URL:             "/api/scoreboard/trends?window=2d",
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"invalid window"`},
+			TestAppFactory:  setupScoreboardTrendsApp,
+		},
+		{
+			Name:           "pipeline trends",
+			Method:         http.MethodGet,
+			URL:            "/api/scoreboard/trends",
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"dimension":"pipeline"`,
+				`"window":"30d"`,
+				`"label":"pipeline123"`,
+				`"date":"2026-03-18"`,
+				`"date":"2026-03-20"`,
+				`"period_success_rate":10`,
+				`"success_rate_delta":-90`,
+				`"regression":true`,
+				`"min_execution_seconds":90`,
+			},
+			TestAppFactory: setupScoreboardTrendsApp,
+		},
+		{
+			Name:           "wallet version trends filtered by key",
+			Method:         http.MethodGet,
+			URL:            "/api/scoreboard/trends?dimension=wallet_version&key=org/wallet/v2",
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"key":"org/wallet/v2"`,
+				`"total_runs":30`,
+			},
+			NotExpectedContent: []string{`"key":"org/wallet/v1"`},
+			TestAppFactory:     setupScoreboardTrendsApp,
+		},
+		{
+			Name:            "window excludes older snapshots",
+			Method:          http.MethodGet,
+			URL:             "/api/scoreboard/trends?window=7d&bucket=week",
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"bucket":"week"`, `"date":"2026-03-16"`, `"total_runs":30`},
+			TestAppFactory:  setupScoreboardTrendsApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
 	engine "github.com/forkbombeu/credimi/pkg/templateengine"
@@ -35,6 +36,7 @@ var TemplateRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:        http.MethodPost,
 			Path:          "/placeholders",
+			Permission:    apikey.PermissionTemplatesRead,
 			Handler:       HandlePlaceholdersByFilenames,
 			RequestSchema: GetPlaceholdersByFilenamesRequestInput{},
 		},
@@ -0,0 +1,454 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	trustedListImportScheduleID = "trusted-list-import-schedule"
+	trustedListDefaultLOTLURL   = "https://ec.europa.eu/tools/lotl/eu-lotl.xml"
+	trustedListProvenanceSource = "eu_trusted_list"
+)
+
+var TrustedListRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/trusted-lists",
+	AuthenticationRequired: true,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:        http.MethodPost,
+			Path:          "/import",
+			Permission:    apikey.PermissionTrustedListsWrite,
+			Handler:       HandleTrustedListImport,
+			RequestSchema: ImportTrustedListRequest{},
+			Description:   "Import credential issuers and verifiers from the EU trusted lists",
+		},
+	},
+}
+
+var TrustedListTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/trusted-lists",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:         http.MethodPost,
+			Path:           "/store-entity",
+			Handler:        HandleTrustedListStoreEntity,
+			RequestSchema:  workflows.StoreTrustedListEntityRequest{},
+			ResponseSchema: workflows.StoreTrustedListEntityResponse{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+	},
+}
+
+var (
+	trustedListImportStartWorkflow = func(
+		namespace string,
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		w := workflows.NewTrustedListImportWorkflow()
+		return w.Start(namespace, input)
+	}
+	trustedListImportTemporalClient = temporalclient.GetTemporalClientWithNamespace
+)
+
+var trustedListImportScheduleTriggerOptions = client.ScheduleTriggerOptions{
+	Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+}
+
+// ImportTrustedListRequest configures a trusted list import. The LOTL URL
+// and signing certificates default to TRUSTED_LIST_LOTL_URL and the PEM
+// file at TRUSTED_LIST_LOTL_CERTIFICATES_FILE.
+type ImportTrustedListRequest struct {
+	IntervalDays     int      `json:"interval_days"     validate:"omitempty,min=1"`
+	LOTLURL          string   `json:"lotl_url"          validate:"omitempty,url"`
+	LOTLCertificates []string `json:"lotl_certificates"`
+	Territories      []string `json:"territories"`
+}
+
+// HandleTrustedListImport starts the trusted list import for the caller's
+// organization, or schedules it every interval_days days and runs it now.
+func HandleTrustedListImport() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if e.Auth == nil {
+			return apierror.New(
+				http.StatusUnauthorized,
+				"trusted_lists",
+				"authentication required",
+				"authenticated user or user API key is required",
+			)
+		}
+
+		req, err := decodeImportTrustedListRequest(e.Request)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		certificates, err := trustedListLOTLCertificates(req.LOTLCertificates)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"trusted_lists",
+				"invalid LOTL certificates",
+				err.Error(),
+			)
+		}
+
+		organization, err := pbutils.GetUserOrganizationID(e.App, e.Auth.Id)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get user organization",
+				err.Error(),
+			)
+		}
+		orgName, err := pbutils.GetOrganizationCanonifiedName(e.App, organization)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get organization canonified name",
+				err.Error(),
+			)
+		}
+
+		lotlURL := req.LOTLURL
+		if lotlURL == "" {
+			lotlURL = utils.GetEnvironmentVariable(
+				"TRUSTED_LIST_LOTL_URL",
+				trustedListDefaultLOTLURL,
+			)
+		}
+		config := map[string]any{
+			"app_url":           e.App.Settings().Meta.AppURL,
+			"orgID":             organization,
+			"lotl_url":          lotlURL,
+			"lotl_certificates": certificates,
+		}
+		if len(req.Territories) > 0 {
+			config["territories"] = req.Territories
+		}
+		workflowInput := workflowengine.WorkflowInput{Config: config}
+
+		if req.IntervalDays > 0 {
+			result, err := scheduleTrustedListImport(
+				e.Request.Context(),
+				orgName,
+				workflowInput,
+				req.IntervalDays,
+			)
+			if err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"schedule",
+					"failed to schedule trusted list import",
+					err.Error(),
+				)
+			}
+			return e.JSON(http.StatusOK, result)
+		}
+
+		result, err := trustedListImportStartWorkflow(orgName, workflowInput)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"workflow",
+				"failed to start trusted list import",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"workflow_id":     result.WorkflowID,
+			"workflow_run_id": result.WorkflowRunID,
+			"workflow_url": utils.JoinURL(
+				e.App.Settings().Meta.AppURL,
+				"my",
+				"tests",
+				"runs",
+				result.WorkflowID,
+				result.WorkflowRunID,
+			),
+		})
+	}
+}
+
+func decodeImportTrustedListRequest(req *http.Request) (ImportTrustedListRequest, error) {
+	var input ImportTrustedListRequest
+	if req == nil || req.Body == nil || req.ContentLength == 0 {
+		return input, nil
+	}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		return ImportTrustedListRequest{}, err
+	}
+	if input.IntervalDays < 0 {
+		return ImportTrustedListRequest{}, fmt.Errorf(
+			"interval_days must be greater than or equal to 1",
+		)
+	}
+	if input.LOTLURL != "" && !strings.HasPrefix(input.LOTLURL, "https://") &&
+		!strings.HasPrefix(input.LOTLURL, "http://") {
+		return ImportTrustedListRequest{}, fmt.Errorf("lotl_url must be an http(s) URL")
+	}
+	return input, nil
+}
+
+// trustedListLOTLCertificates returns the certificates pinning the LOTL
+// signer: the requested ones, or the PEM file configured for the instance.
+func trustedListLOTLCertificates(requested []string) ([]string, error) {
+	certificates := requested
+	if len(certificates) == 0 {
+		path := utils.GetEnvironmentVariable("TRUSTED_LIST_LOTL_CERTIFICATES_FILE")
+		if path == "" {
+			return nil, errors.New(
+				"lotl_certificates is required when TRUSTED_LIST_LOTL_CERTIFICATES_FILE is not set",
+			)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read LOTL certificates: %w", err)
+		}
+		certificates = []string{string(data)}
+	}
+	if _, err := trustedlist.ParseTrustedCertificates(certificates); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+func scheduleTrustedListImport(
+	ctx context.Context,
+	namespace string,
+	input workflowengine.WorkflowInput,
+	intervalDays int,
+) (map[string]any, error) {
+	c, err := trustedListImportTemporalClient(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporal client: %w", err)
+	}
+
+	_, err = c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:      trustedListImportScheduleID,
+		Spec:    buildTrustedListImportScheduleSpec(intervalDays),
+		Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+		Action:  buildTrustedListImportScheduleAction(input),
+	})
+	handle := c.ScheduleClient().GetHandle(ctx, trustedListImportScheduleID)
+	if err != nil && isScheduleAlreadyExistsError(err) {
+		err = handle.Update(ctx, client.ScheduleUpdateOptions{
+			DoUpdate: func(client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+				spec := buildTrustedListImportScheduleSpec(intervalDays)
+				return &client.ScheduleUpdate{Schedule: &client.Schedule{
+					Spec: &spec,
+					Policy: &client.SchedulePolicies{
+						Overlap: enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+					},
+					State:  &client.ScheduleState{},
+					Action: buildTrustedListImportScheduleAction(input),
+				}}, nil
+			},
+		})
+	}
+	if err == nil {
+		err = handle.Trigger(ctx, trustedListImportScheduleTriggerOptions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert trusted list import schedule: %w", err)
+	}
+
+	return map[string]any{
+		"message": fmt.Sprintf(
+			"Trusted list import triggered now and scheduled every %d day(s)",
+			intervalDays,
+		),
+		"schedule_id":       trustedListImportScheduleID,
+		"workflowNamespace": namespace,
+	}, nil
+}
+
+func buildTrustedListImportScheduleSpec(intervalDays int) client.ScheduleSpec {
+	return client.ScheduleSpec{
+		Intervals: []client.ScheduleIntervalSpec{{
+			Every: time.Duration(intervalDays) * 24 * time.Hour,
+		}},
+	}
+}
+
+func buildTrustedListImportScheduleAction(
+	input workflowengine.WorkflowInput,
+) *client.ScheduleWorkflowAction {
+	return &client.ScheduleWorkflowAction{
+		ID:        "Trusted-List-Import-Scheduled",
+		Workflow:  workflows.TrustedListImportWorkflowName,
+		TaskQueue: workflows.TrustedListImportTaskQueue,
+		Args: []interface{}{
+			input,
+		},
+	}
+}
+
+// HandleTrustedListStoreEntity creates or updates the credential issuer or
+// verifier record of a trusted list entity, keyed by URL and owner. Records
+// the organization created by hand keep their name and description.
+func HandleTrustedListStoreEntity() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body workflows.StoreTrustedListEntityRequest
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid JSON body",
+				err.Error(),
+			)
+		}
+		if strings.TrimSpace(body.OrgID) == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"trusted_lists",
+				"missing organization",
+				"orgID is required",
+			)
+		}
+		entityURL := body.Entity.URL()
+		if entityURL == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"trusted_lists",
+				"missing entity URL",
+				"the entity has no http(s) supply point or information URI",
+			)
+		}
+
+		collectionName := "verifiers"
+		switch {
+		case body.Entity.Role.IsIssuer():
+			collectionName = "credential_issuers"
+		case body.Entity.Role != trustedlist.RoleRelyingParty:
+			return apierror.New(
+				http.StatusBadRequest,
+				"trusted_lists",
+				"unsupported entity role",
+				fmt.Sprintf("role %q cannot be imported", body.Entity.Role),
+			)
+		}
+
+		collection, err := e.App.FindCollectionByNameOrId(collectionName)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"trusted_lists",
+				"failed to find collection",
+				err.Error(),
+			)
+		}
+
+		created := false
+		record, err := e.App.FindFirstRecordByFilter(
+			collection,
+			"url = {:url} && owner = {:owner}",
+			map[string]any{
+				"url":   entityURL,
+				"owner": body.OrgID,
+			},
+		)
+		if err != nil {
+			created = true
+			record = core.NewRecord(collection)
+			record.Set("url", entityURL)
+			record.Set("owner", body.OrgID)
+			record.Set("imported", true)
+		}
+		if created || record.GetBool("imported") {
+			record.Set("name", trustedListEntityName(body.Entity))
+			if collectionName == "verifiers" {
+				record.Set("description", fmt.Sprintf(
+					"%s registered as relying party in the %s trusted list",
+					trustedListEntityName(body.Entity),
+					body.List.Territory,
+				))
+			}
+		}
+		record.SetIfFieldExists("provenance", trustedListProvenance(body))
+
+		if err := e.App.Save(record); err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"trusted_lists",
+				"failed to save trusted list entity",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, workflows.StoreTrustedListEntityResponse{
+			Collection: collectionName,
+			ID:         record.Id,
+			Created:    created,
+		})
+	}
+}
+
+func trustedListEntityName(entity trustedlist.Entity) string {
+	if entity.TradeName != "" {
+		return entity.TradeName
+	}
+	if entity.ProviderName != "" {
+		return entity.ProviderName
+	}
+	return entity.ServiceName
+}
+
+func trustedListProvenance(body workflows.StoreTrustedListEntityRequest) map[string]any {
+	certificates := body.Entity.Certificates
+	if certificates == nil {
+		certificates = []trustedlist.Certificate{}
+	}
+	return map[string]any{
+		"source":        trustedListProvenanceSource,
+		"lotl_url":      body.LOTLURL,
+		"trusted_list":  body.List,
+		"role":          body.Entity.Role,
+		"provider_name": body.Entity.ProviderName,
+		"service_name":  body.Entity.ServiceName,
+		"service_type":  body.Entity.ServiceType,
+		"status":        body.Entity.Status,
+		"certificates":  certificates,
+	}
+}
@@ -0,0 +1,501 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func newTrustedListTestCertificateDER(t testing.TB) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "EU LOTL signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+func callTrustedListImport(
+	t testing.TB,
+	app core.App,
+	auth *core.Record,
+	body string,
+) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/api/trusted-lists/import",
+		bytes.NewBufferString(body),
+	)
+	rec := httptest.NewRecorder()
+	err := HandleTrustedListImport()(&core.RequestEvent{
+		App:  app,
+		Auth: auth,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	return rec, err
+}
+
+func TestHandleTrustedListImportStartsWorkflow(t *testing.T) {
+	t.Setenv("TRUSTED_LIST_LOTL_URL", "")
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := pbutils.GetUserOrganizationID(app, authRecord.Id)
+	require.NoError(t, err)
+	orgName, err := pbutils.GetOrganizationCanonifiedName(app, orgID)
+	require.NoError(t, err)
+
+	origStart := trustedListImportStartWorkflow
+	t.Cleanup(func() { trustedListImportStartWorkflow = origStart })
+	var capturedNamespace string
+	var capturedInput workflowengine.WorkflowInput
+	trustedListImportStartWorkflow = func(
+		namespace string,
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		capturedNamespace = namespace
+		capturedInput = input
+		return workflowengine.WorkflowResult{
+			WorkflowID:    "tl-wf",
+			WorkflowRunID: "tl-run",
+		}, nil
+	}
+
+	certificate := base64.StdEncoding.EncodeToString(newTrustedListTestCertificateDER(t))
+	body, err := json.Marshal(map[string]any{
+		"lotl_certificates": []string{certificate},
+		"territories":       []string{"IT", "DE"},
+	})
+	require.NoError(t, err)
+
+	rec, err := callTrustedListImport(t, app, authRecord, string(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var payload map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	require.Equal(t, "tl-wf", payload["workflow_id"])
+	require.Equal(t, "tl-run", payload["workflow_run_id"])
+	require.Equal(t, orgName, capturedNamespace)
+	require.Equal(t, orgID, capturedInput.Config["orgID"])
+	require.Equal(t, trustedListDefaultLOTLURL, capturedInput.Config["lotl_url"])
+	require.Equal(t, []string{certificate}, capturedInput.Config["lotl_certificates"])
+	require.Equal(t, []string{"IT", "DE"}, capturedInput.Config["territories"])
+}
+
+func TestHandleTrustedListImportCertificatesFromFile(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	certificates := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: newTrustedListTestCertificateDER(t),
+	})
+	path := filepath.Join(t.TempDir(), "lotl.pem")
+	require.NoError(t, os.WriteFile(path, certificates, 0o600))
+	t.Setenv("TRUSTED_LIST_LOTL_CERTIFICATES_FILE", path)
+	t.Setenv("TRUSTED_LIST_LOTL_URL", "https://lotl.example/eu-lotl.xml")
+
+	origStart := trustedListImportStartWorkflow
+	t.Cleanup(func() { trustedListImportStartWorkflow = origStart })
+	var capturedInput workflowengine.WorkflowInput
+	trustedListImportStartWorkflow = func(
+		_ string,
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		capturedInput = input
+		return workflowengine.WorkflowResult{WorkflowID: "tl-wf"}, nil
+	}
+
+	rec, err := callTrustedListImport(t, app, authRecord, "")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "https://lotl.example/eu-lotl.xml", capturedInput.Config["lotl_url"])
+	require.Equal(
+		t,
+		[]string{string(certificates)},
+		capturedInput.Config["lotl_certificates"],
+	)
+	require.NotContains(t, capturedInput.Config, "territories")
+}
+
+func TestHandleTrustedListImportRejectsInvalidRequests(t *testing.T) {
+	t.Setenv("TRUSTED_LIST_LOTL_CERTIFICATES_FILE", "")
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	origStart := trustedListImportStartWorkflow
+	t.Cleanup(func() { trustedListImportStartWorkflow = origStart })
+	trustedListImportStartWorkflow = func(
+		string,
+		workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		return workflowengine.WorkflowResult{}, errors.New("workflow should not start")
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "no certificates", want: "TRUSTED_LIST_LOTL_CERTIFICATES_FILE"},
+		{
+			name: "malformed certificate",
+			body: `{"lotl_certificates":["not-a-certificate"]}`,
+			want: "invalid LOTL certificates",
+		},
+		{name: "negative interval", body: `{"interval_days":-1}`, want: "interval_days"},
+		{name: "invalid url", body: `{"lotl_url":"ftp://lotl.example"}`, want: "lotl_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTrustedListImport(t, app, authRecord, tt.body)
+			var apiErr *apierror.APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.Code)
+			require.Contains(t, apiErr.Reason+apiErr.Message, tt.want)
+		})
+	}
+
+	_, err = callTrustedListImport(t, app, nil, "")
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.Code)
+}
+
+func TestHandleTrustedListImportSchedule(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := pbutils.GetUserOrganizationID(app, authRecord.Id)
+	require.NoError(t, err)
+	orgName, err := pbutils.GetOrganizationCanonifiedName(app, orgID)
+	require.NoError(t, err)
+
+	origStart := trustedListImportStartWorkflow
+	origTemporalClient := trustedListImportTemporalClient
+	t.Cleanup(func() {
+		trustedListImportStartWorkflow = origStart
+		trustedListImportTemporalClient = origTemporalClient
+	})
+	trustedListImportStartWorkflow = func(
+		string,
+		workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		return workflowengine.WorkflowResult{}, errors.New("immediate start should not be called")
+	}
+
+	mockHandle := &temporalmocks.ScheduleHandle{}
+	mockHandle.On("Trigger", mock.Anything, trustedListImportScheduleTriggerOptions).
+		Return(nil).
+		Once()
+	mockClient := &temporalmocks.Client{}
+	mockScheduleClient := &fakeScheduleClient{handle: mockHandle}
+	mockClient.On("ScheduleClient").Return(mockScheduleClient)
+	trustedListImportTemporalClient = func(namespace string) (client.Client, error) {
+		require.Equal(t, orgName, namespace)
+		return mockClient, nil
+	}
+
+	certificate := base64.StdEncoding.EncodeToString(newTrustedListTestCertificateDER(t))
+	rec, err := callTrustedListImport(
+		t,
+		app,
+		authRecord,
+		`{"interval_days":7,"lotl_certificates":["`+certificate+`"]}`,
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var payload map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	require.Equal(t, trustedListImportScheduleID, payload["schedule_id"])
+	require.Equal(t, orgName, payload["workflowNamespace"])
+
+	require.Len(t, mockScheduleClient.createdOptions, 1)
+	options := mockScheduleClient.createdOptions[0]
+	require.Equal(t, trustedListImportScheduleID, options.ID)
+	require.Equal(t, 7*24*time.Hour, options.Spec.Intervals[0].Every)
+	action, ok := options.Action.(*client.ScheduleWorkflowAction)
+	require.True(t, ok)
+	require.Equal(t, workflows.TrustedListImportWorkflowName, action.Workflow)
+	require.Equal(t, workflows.TrustedListImportTaskQueue, action.TaskQueue)
+	mockHandle.AssertExpectations(t)
+}
+
+// addTrustedListFields mirrors the trusted list import migrations, which the
+// test database predates.
+func addTrustedListFields(t testing.TB, app core.App) {
+	t.Helper()
+
+	issuers, err := app.FindCollectionByNameOrId("credential_issuers")
+	require.NoError(t, err)
+	issuers.Fields.Add(&core.JSONField{Name: "provenance"})
+	require.NoError(t, app.Save(issuers))
+
+	verifiers, err := app.FindCollectionByNameOrId("verifiers")
+	require.NoError(t, err)
+	for _, name := range []string{
+		"standard_and_version",
+		"format",
+		"signing_algorithms",
+		"cryptographic_binding_methods",
+	} {
+		switch field := verifiers.Fields.GetByName(name).(type) {
+		case *core.TextField:
+			field.Required = false
+		case *core.SelectField:
+			field.Required = false
+		}
+	}
+	verifiers.Fields.Add(&core.BoolField{Name: "imported"}, &core.JSONField{Name: "provenance"})
+	require.NoError(t, app.Save(verifiers))
+}
+
+func callTrustedListStoreEntity(
+	t testing.TB,
+	app core.App,
+	request workflows.StoreTrustedListEntityRequest,
+) (workflows.StoreTrustedListEntityResponse, error) {
+	t.Helper()
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/api/trusted-lists/store-entity",
+		bytes.NewBuffer(body),
+	)
+	rec := httptest.NewRecorder()
+	err = HandleTrustedListStoreEntity()(&core.RequestEvent{
+		App: app,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	var response workflows.StoreTrustedListEntityResponse
+	if err == nil {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	}
+	return response, err
+}
+
+func trustedListStoreRequest(
+	t testing.TB,
+	role trustedlist.Role,
+	url string,
+) workflows.StoreTrustedListEntityRequest {
+	t.Helper()
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	return workflows.StoreTrustedListEntityRequest{
+		OrgID:   orgID,
+		LOTLURL: "https://lotl.example/eu-lotl.xml",
+		List: workflows.TrustedListSource{
+			URL:            "https://it.example/tl.xml",
+			Territory:      "IT",
+			SequenceNumber: 12,
+		},
+		Entity: trustedlist.Entity{
+			Role:         role,
+			ProviderName: "Istituto Poligrafico",
+			ServiceName:  "PID issuance",
+			ServiceType:  "http://uri.etsi.org/TrstSvc/Svctype/EUDIW/PID",
+			Status:       "http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/granted",
+			SupplyPoints: []string{"urn:example", url},
+			Certificates: []trustedlist.Certificate{{
+				Subject: "CN=PID signer",
+				SHA256:  "abcd",
+			}},
+		},
+	}
+}
+
+func TestHandleTrustedListStoreEntityIssuer(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := trustedListStoreRequest(t, trustedlist.RolePIDProvider, "https://pid.example.it")
+	response, err := callTrustedListStoreEntity(t, app, request)
+	require.NoError(t, err)
+	require.Equal(t, "credential_issuers", response.Collection)
+	require.True(t, response.Created)
+
+	record, err := app.FindRecordById("credential_issuers", response.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https://pid.example.it", record.GetString("url"))
+	require.Equal(t, request.OrgID, record.GetString("owner"))
+	require.Equal(t, "Istituto Poligrafico", record.GetString("name"))
+	require.True(t, record.GetBool("imported"))
+
+	var provenance map[string]any
+	require.NoError(t, record.UnmarshalJSONField("provenance", &provenance))
+	require.Equal(t, trustedListProvenanceSource, provenance["source"])
+	require.Equal(t, "https://lotl.example/eu-lotl.xml", provenance["lotl_url"])
+	require.Equal(t, string(trustedlist.RolePIDProvider), provenance["role"])
+	require.Equal(t, "IT", provenance["trusted_list"].(map[string]any)["territory"])
+	require.Len(t, provenance["certificates"], 1)
+
+	request.Entity.TradeName = "IPZS"
+	request.List.SequenceNumber = 13
+	updated, err := callTrustedListStoreEntity(t, app, request)
+	require.NoError(t, err)
+	require.Equal(t, response.ID, updated.ID)
+	require.False(t, updated.Created)
+
+	record, err = app.FindRecordById("credential_issuers", response.ID)
+	require.NoError(t, err)
+	require.Equal(t, "IPZS", record.GetString("name"))
+	require.NoError(t, record.UnmarshalJSONField("provenance", &provenance))
+	require.Equal(
+		t,
+		float64(13),
+		provenance["trusted_list"].(map[string]any)["sequence_number"],
+	)
+}
+
+func TestHandleTrustedListStoreEntityVerifier(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := trustedListStoreRequest(t, trustedlist.RoleRelyingParty, "https://bank.example.it")
+	request.Entity.ProviderName = "Banca Esempio"
+	response, err := callTrustedListStoreEntity(t, app, request)
+	require.NoError(t, err)
+	require.Equal(t, "verifiers", response.Collection)
+	require.True(t, response.Created)
+
+	record, err := app.FindRecordById("verifiers", response.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Banca Esempio", record.GetString("name"))
+	require.Equal(
+		t,
+		"Banca Esempio registered as relying party in the IT trusted list",
+		record.GetString("description"),
+	)
+	require.True(t, record.GetBool("imported"))
+	var provenance map[string]any
+	require.NoError(t, record.UnmarshalJSONField("provenance", &provenance))
+	require.Equal(t, string(trustedlist.RoleRelyingParty), provenance["role"])
+}
+
+func TestHandleTrustedListStoreEntityKeepsManualRecords(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := trustedListStoreRequest(t, trustedlist.RoleRelyingParty, "https://bank.example.it")
+	collection, err := app.FindCollectionByNameOrId("verifiers")
+	require.NoError(t, err)
+	existing := core.NewRecord(collection)
+	existing.Set("owner", request.OrgID)
+	existing.Set("url", "https://bank.example.it")
+	existing.Set("name", "Our bank verifier")
+	existing.Set("description", "Configured by hand")
+	require.NoError(t, app.Save(existing))
+
+	response, err := callTrustedListStoreEntity(t, app, request)
+	require.NoError(t, err)
+	require.Equal(t, existing.Id, response.ID)
+	require.False(t, response.Created)
+
+	record, err := app.FindRecordById("verifiers", existing.Id)
+	require.NoError(t, err)
+	require.Equal(t, "Our bank verifier", record.GetString("name"))
+	require.Equal(t, "Configured by hand", record.GetString("description"))
+	require.False(t, record.GetBool("imported"))
+	require.NotEmpty(t, record.GetString("provenance"))
+}
+
+func TestHandleTrustedListStoreEntityRejectsInvalidEntities(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	noURL := trustedListStoreRequest(t, trustedlist.RolePIDProvider, "")
+	noURL.Entity.SupplyPoints = nil
+	unknownRole := trustedListStoreRequest(t, trustedlist.Role("qtsp"), "https://qtsp.example")
+	noOrg := trustedListStoreRequest(t, trustedlist.RolePIDProvider, "https://pid.example")
+	noOrg.OrgID = ""
+
+	tests := []struct {
+		name    string
+		request workflows.StoreTrustedListEntityRequest
+		want    string
+	}{
+		{name: "no url", request: noURL, want: "missing entity URL"},
+		{name: "unknown role", request: unknownRole, want: "unsupported entity role"},
+		{name: "no organization", request: noOrg, want: "missing organization"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTrustedListStoreEntity(t, app, tt.request)
+			var apiErr *apierror.APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.Code)
+			require.Equal(t, tt.want, apiErr.Reason)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
 )
@@ -39,6 +40,13 @@ var VerifierTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 			Path:    "/temp-use-case/{record}",
 			Handler: HandleDeleteTempUseCaseVerification,
 		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/store-imported",
+			Handler:        HandleVerifierStoreImported,
+			RequestSchema:  workflows.StoreImportedVerifierRequest{},
+			ResponseSchema: workflows.StoreImportedVerifierResponse{},
+		},
 	},
 }
 
@@ -0,0 +1,516 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"gopkg.in/yaml.v3"
+)
+
+const verifierImportProvenanceSource = "openid4vp_request"
+
+var VerifierRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/verifier",
+	AuthenticationRequired: true,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:        http.MethodPost,
+			Path:          "/import",
+			Permission:    apikey.PermissionVerifiersWrite,
+			Handler:       HandleVerifierImport,
+			RequestSchema: ImportVerifierRequest{},
+			Description:   "Import a verifier from its OpenID4VP authorization request",
+		},
+	},
+}
+
+var verifierImportStartWorkflow = func(
+	namespace string,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	w := workflows.NewVerifierImportWorkflow()
+	return w.Start(namespace, input)
+}
+
+// verifierFormats maps OpenID4VP credential formats to the values of the
+// verifiers format field.
+var verifierFormats = map[string]string{
+	vprequest.FormatSDJWT:       "SD-JWT",
+	vprequest.FormatSDJWTLegacy: "SD-JWT",
+	vprequest.FormatMDoc:        "mDOC",
+	vprequest.FormatJWTVCJSON:   "W3C-VC",
+	vprequest.FormatJWTVCJSONLD: "W3C-VC",
+	vprequest.FormatLDPVC:       "W3C-VC",
+	vprequest.FormatJWTVC:       "W3C-VC",
+	vprequest.FormatJWTVP:       "W3C-VC",
+	vprequest.FormatJWTVPJSON:   "W3C-VC",
+	vprequest.FormatLDPVP:       "W3C-VC",
+}
+
+// ImportVerifierRequest names the verifier to import: an OpenID4VP
+// authorization request URL or a verifier endpoint that leads to one.
+type ImportVerifierRequest struct {
+	URL string `json:"url" validate:"required"`
+}
+
+// HandleVerifierImport starts the verifier import for the caller's
+// organization.
+func HandleVerifierImport() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if e.Auth == nil {
+			return apierror.New(
+				http.StatusUnauthorized,
+				"verifiers",
+				"authentication required",
+				"authenticated user or user API key is required",
+			)
+		}
+
+		var req ImportVerifierRequest
+		if err := json.NewDecoder(e.Request.Body).Decode(&req); err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		if err := validateVerifierImportURL(req.URL); err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+
+		organization, err := pbutils.GetUserOrganizationID(e.App, e.Auth.Id)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get user organization",
+				err.Error(),
+			)
+		}
+		orgName, err := pbutils.GetOrganizationCanonifiedName(e.App, organization)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"organization",
+				"failed to get organization canonified name",
+				err.Error(),
+			)
+		}
+
+		result, err := verifierImportStartWorkflow(orgName, workflowengine.WorkflowInput{
+			Config: map[string]any{
+				"app_url":      e.App.Settings().Meta.AppURL,
+				"orgID":        organization,
+				"verifier_url": strings.TrimSpace(req.URL),
+			},
+		})
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"workflow",
+				"failed to start verifier import",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"workflow_id":     result.WorkflowID,
+			"workflow_run_id": result.WorkflowRunID,
+			"workflow_url": utils.JoinURL(
+				e.App.Settings().Meta.AppURL,
+				"my",
+				"tests",
+				"runs",
+				result.WorkflowID,
+				result.WorkflowRunID,
+			),
+		})
+	}
+}
+
+func validateVerifierImportURL(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if parsed.Scheme == "http" || parsed.Scheme == "https" ||
+		vprequest.IsAuthorizationRequest(parsed) {
+		return nil
+	}
+	return fmt.Errorf("url must be an http(s) URL or an OpenID4VP authorization request")
+}
+
+// HandleVerifierStoreImported creates or updates the verifier of a
+// validated authorization request, keyed by URL and owner, and the use
+// case verification that replays the request. Records the organization
+// created by hand keep their name and description.
+func HandleVerifierStoreImported() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body workflows.StoreImportedVerifierRequest
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid JSON body",
+				err.Error(),
+			)
+		}
+		if strings.TrimSpace(body.OrgID) == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"verifiers",
+				"missing organization",
+				"orgID is required",
+			)
+		}
+		verifierURL := importedVerifierURL(body)
+		if verifierURL == "" || body.Request.URL == "" {
+			return apierror.New(
+				http.StatusBadRequest,
+				"verifiers",
+				"missing verifier URL",
+				"the request has no http(s) URL identifying the verifier",
+			)
+		}
+		if !body.Report.Valid {
+			return apierror.New(
+				http.StatusBadRequest,
+				"verifiers",
+				"invalid verifier request",
+				fmt.Sprintf("the request has %d validation issues", len(body.Report.Issues)),
+			)
+		}
+
+		verifier, created, err := storeImportedVerifier(e.App, verifierURL, body)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"verifiers",
+				"failed to save verifier",
+				err.Error(),
+			)
+		}
+		useCase, err := storeImportedUseCase(e.App, body, verifier)
+		if err != nil {
+			return apierror.New(
+				http.StatusInternalServerError,
+				"verifiers",
+				"failed to save use case verification",
+				err.Error(),
+			)
+		}
+
+		return e.JSON(http.StatusOK, workflows.StoreImportedVerifierResponse{
+			VerifierID: verifier.Id,
+			UseCaseID:  useCase.Id,
+			Created:    created,
+		})
+	}
+}
+
+func storeImportedVerifier(
+	app core.App,
+	verifierURL string,
+	body workflows.StoreImportedVerifierRequest,
+) (*core.Record, bool, error) {
+	collection, err := app.FindCollectionByNameOrId("verifiers")
+	if err != nil {
+		return nil, false, err
+	}
+
+	created := false
+	record, err := app.FindFirstRecordByFilter(
+		collection,
+		"url = {:url} && owner = {:owner}",
+		map[string]any{
+			"url":   verifierURL,
+			"owner": body.OrgID,
+		},
+	)
+	if err != nil {
+		created = true
+		record = core.NewRecord(collection)
+		record.Set("url", verifierURL)
+		record.Set("owner", body.OrgID)
+		record.Set("imported", true)
+	}
+	if created || record.GetBool("imported") {
+		name := importedVerifierName(body)
+		record.Set("name", name)
+		record.Set("description", fmt.Sprintf(
+			"%s imported from its OpenID4VP %s authorization request",
+			name,
+			body.Report.Version,
+		))
+		record.Set("standard_and_version", "openid4vp_verifier/"+body.Report.Version)
+		record.Set("format", importedVerifierFormats(body.Report.Formats))
+		record.Set("signing_algorithms", importedVerifierAlgorithms(
+			collection,
+			body.Report.Algorithms,
+		))
+		record.Set("cryptographic_binding_methods", importedVerifierBindingMethods(
+			body.Report.Formats,
+		))
+	}
+	record.SetIfFieldExists("provenance", map[string]any{
+		"source":           verifierImportProvenanceSource,
+		"url":              body.URL,
+		"request_source":   body.Request.Source,
+		"version":          body.Report.Version,
+		"client_id":        body.Report.ClientID,
+		"client_id_scheme": body.Report.ClientIDScheme,
+		"signed":           body.Report.Signed,
+		"response_mode":    body.Report.ResponseMode,
+		"encrypted":        body.Report.Encrypted,
+		"formats":          body.Report.Formats,
+		"query":            body.Report.Query,
+	})
+
+	if err := app.Save(record); err != nil {
+		return nil, false, err
+	}
+	return record, created, nil
+}
+
+// storeImportedUseCase creates or updates the use case verification of the
+// request, keyed by verifier and name.
+func storeImportedUseCase(
+	app core.App,
+	body workflows.StoreImportedVerifierRequest,
+	verifier *core.Record,
+) (*core.Record, error) {
+	collection, err := app.FindCollectionByNameOrId("use_cases_verifications")
+	if err != nil {
+		return nil, err
+	}
+	code, err := importedUseCaseYAML(body.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	name := importedUseCaseName(body)
+	record, err := app.FindFirstRecordByFilter(
+		collection,
+		"verifier = {:verifier} && owner = {:owner} && name = {:name}",
+		map[string]any{
+			"verifier": verifier.Id,
+			"owner":    body.OrgID,
+			"name":     name,
+		},
+	)
+	if err != nil {
+		record = core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("owner", body.OrgID)
+		record.Set("verifier", verifier.Id)
+	}
+	record.Set("description", importedUseCaseDescription(body))
+	record.Set("yaml", code)
+	if query, ok := body.Request.Parameters["dcql_query"]; ok {
+		encoded, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		record.Set("dcql_query", string(encoded))
+	}
+
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// importedVerifierURL returns the URL the verifier is stored under: the
+// imported endpoint, or for bare authorization requests the origin of the
+// verifier endpoints they name.
+func importedVerifierURL(body workflows.StoreImportedVerifierRequest) string {
+	candidates := []string{
+		body.URL,
+		body.Request.Parameter("response_uri"),
+		body.Request.RequestURI,
+		body.Request.Parameter("redirect_uri"),
+	}
+	for i, candidate := range candidates {
+		parsed, err := url.Parse(strings.TrimSpace(candidate))
+		if err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			continue
+		}
+		if i == 0 {
+			return parsed.String()
+		}
+		return parsed.Scheme + "://" + parsed.Host
+	}
+	return ""
+}
+
+func importedVerifierName(body workflows.StoreImportedVerifierRequest) string {
+	if metadata, ok := body.Request.Parameters["client_metadata"].(map[string]any); ok {
+		if name, ok := metadata["client_name"].(string); ok && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name)
+		}
+	}
+	if parsed, err := url.Parse(importedVerifierURL(body)); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return body.Report.ClientID
+}
+
+func importedVerifierFormats(formats []string) []string {
+	values := []string{}
+	for _, format := range formats {
+		value, ok := verifierFormats[format]
+		if ok && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// importedVerifierAlgorithms keeps the algorithms the signing_algorithms
+// field can hold.
+func importedVerifierAlgorithms(collection *core.Collection, algorithms []string) []string {
+	field, ok := collection.Fields.GetByName("signing_algorithms").(*core.SelectField)
+	if !ok {
+		return algorithms
+	}
+	values := []string{}
+	for _, algorithm := range algorithms {
+		if slices.Contains(field.Values, algorithm) && len(values) < field.MaxSelect {
+			values = append(values, algorithm)
+		}
+	}
+	return values
+}
+
+// importedVerifierBindingMethods derives the holder binding methods from
+// the requested formats: mdocs bind COSE keys, the other formats JWKs.
+func importedVerifierBindingMethods(formats []string) []string {
+	methods := []string{}
+	for _, format := range formats {
+		method := "jwk"
+		if format == vprequest.FormatMDoc {
+			method = "cose_key"
+		}
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func importedUseCaseName(body workflows.StoreImportedVerifierRequest) string {
+	if definition, ok := body.Request.Parameters["presentation_definition"].(map[string]any); ok {
+		if name, ok := definition["name"].(string); ok && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name)
+		}
+	}
+	if len(body.Report.Credentials) > 0 {
+		return "Present " + strings.Join(body.Report.Credentials, ", ")
+	}
+	return "Present " + body.Report.Query
+}
+
+func importedUseCaseDescription(body workflows.StoreImportedVerifierRequest) string {
+	if definition, ok := body.Request.Parameters["presentation_definition"].(map[string]any); ok {
+		if purpose, ok := definition["purpose"].(string); ok && strings.TrimSpace(purpose) != "" {
+			return strings.TrimSpace(purpose)
+		}
+	}
+	return fmt.Sprintf("Imported from the OpenID4VP authorization request at %s", body.URL)
+}
+
+type importedUseCaseStep struct {
+	Name   string         `yaml:"name"`
+	HTTP   map[string]any `yaml:"http,omitempty"`
+	Plugin map[string]any `yaml:"plugin,omitempty"`
+}
+
+// importedUseCaseYAML returns the StepCI workflow that captures the
+// deeplink of the use case. Verifiers that answer their endpoint with a
+// fresh authorization request are asked again on every run; otherwise the
+// imported request is replayed, with its request_uri resolved by the wallet.
+func importedUseCaseYAML(request vprequest.Request) (string, error) {
+	var step importedUseCaseStep
+	switch request.Source {
+	case vprequest.SourceBody:
+		step = importedUseCaseStep{
+			Name: "Get the authorization request",
+			HTTP: map[string]any{
+				"url":    request.Endpoint,
+				"method": http.MethodGet,
+				"check":  map[string]any{"status": http.StatusOK},
+				"captures": map[string]any{
+					"deeplink": map[string]any{"body": true},
+				},
+			},
+		}
+	case vprequest.SourceRedirect:
+		step = importedUseCaseStep{
+			Name: "Get the authorization request",
+			HTTP: map[string]any{
+				"url":             request.Endpoint,
+				"method":          http.MethodGet,
+				"followRedirects": false,
+				"captures": map[string]any{
+					"deeplink": map[string]any{"header": "location"},
+				},
+			},
+		}
+	default:
+		step = importedUseCaseStep{
+			Name: "Authorization request",
+			Plugin: map[string]any{
+				"id": "capture-plugin",
+				"params": map[string]any{
+					"values": map[string]any{"deeplink": request.URL},
+				},
+			},
+		}
+	}
+
+	encoded, err := yaml.Marshal(map[string]any{
+		"version": "1.1",
+		"tests": map[string]any{
+			"openid4vp_verifier": map[string]any{
+				"steps": []importedUseCaseStep{step},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func callVerifierImport(
+	t testing.TB,
+	app core.App,
+	auth *core.Record,
+	body string,
+) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/api/verifier/import",
+		bytes.NewBufferString(body),
+	)
+	rec := httptest.NewRecorder()
+	err := HandleVerifierImport()(&core.RequestEvent{
+		App:  app,
+		Auth: auth,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	return rec, err
+}
+
+func TestHandleVerifierImportStartsWorkflow(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	orgID, err := pbutils.GetUserOrganizationID(app, authRecord.Id)
+	require.NoError(t, err)
+	orgName, err := pbutils.GetOrganizationCanonifiedName(app, orgID)
+	require.NoError(t, err)
+
+	origStart := verifierImportStartWorkflow
+	t.Cleanup(func() { verifierImportStartWorkflow = origStart })
+	var capturedNamespace string
+	var capturedInput workflowengine.WorkflowInput
+	verifierImportStartWorkflow = func(
+		namespace string,
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		capturedNamespace = namespace
+		capturedInput = input
+		return workflowengine.WorkflowResult{
+			WorkflowID:    "vi-wf",
+			WorkflowRunID: "vi-run",
+		}, nil
+	}
+
+	rec, err := callVerifierImport(
+		t,
+		app,
+		authRecord,
+		`{"url": "openid4vp://?client_id=verifier.example&request_uri=https%3A%2F%2Fverifier.example%2Fr"}`,
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var payload map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	require.Equal(t, "vi-wf", payload["workflow_id"])
+	require.Equal(t, "vi-run", payload["workflow_run_id"])
+	require.Equal(t, orgName, capturedNamespace)
+	require.Equal(t, orgID, capturedInput.Config["orgID"])
+	require.Equal(
+		t,
+		"openid4vp://?client_id=verifier.example&request_uri=https%3A%2F%2Fverifier.example%2Fr",
+		capturedInput.Config["verifier_url"],
+	)
+}
+
+func TestHandleVerifierImportRejectsInvalidRequests(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	_, err = callVerifierImport(t, app, nil, `{"url": "https://verifier.example"}`)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.Code)
+
+	for _, body := range []string{`{}`, `{"url": "ftp://verifier.example"}`, `{`} {
+		_, err := callVerifierImport(t, app, authRecord, body)
+		require.ErrorAs(t, err, &apiErr, body)
+		require.Equal(t, http.StatusBadRequest, apiErr.Code, body)
+	}
+}
+
+func callVerifierStoreImported(
+	t testing.TB,
+	app core.App,
+	request workflows.StoreImportedVerifierRequest,
+) (workflows.StoreImportedVerifierResponse, error) {
+	t.Helper()
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/api/verifier/store-imported",
+		bytes.NewBuffer(body),
+	)
+	rec := httptest.NewRecorder()
+	err = HandleVerifierStoreImported()(&core.RequestEvent{
+		App: app,
+		Event: router.Event{
+			Request:  req,
+			Response: rec,
+		},
+	})
+	var response workflows.StoreImportedVerifierResponse
+	if err == nil {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	}
+	return response, err
+}
+
+func verifierStoreImportedRequest(t testing.TB) workflows.StoreImportedVerifierRequest {
+	t.Helper()
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	parameters := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"client_id": "x509_san_dns:verifier.example",
+		"response_uri": "https://verifier.example/response",
+		"client_metadata": {"client_name": "Example Verifier"},
+		"dcql_query": {"credentials": [{"id": "pid", "format": "dc+sd-jwt"}]}
+	}`), &parameters))
+	return workflows.StoreImportedVerifierRequest{
+		OrgID: orgID,
+		URL:   "https://verifier.example/start",
+		Request: vprequest.Request{
+			Source:     vprequest.SourceBody,
+			Endpoint:   "https://verifier.example/start",
+			URL:        "openid4vp://?client_id=x509_san_dns%3Averifier.example",
+			Parameters: parameters,
+		},
+		Report: vprequest.Report{
+			Valid:          true,
+			Version:        vprequest.Version10,
+			ClientID:       "verifier.example",
+			ClientIDScheme: "x509_san_dns",
+			Signed:         true,
+			ResponseMode:   vprequest.ResponseModeDirectPost,
+			Formats:        []string{vprequest.FormatSDJWT, vprequest.FormatMDoc},
+			Algorithms:     []string{"ES256", "ES384"},
+			Query:          vprequest.QueryDCQL,
+			Credentials:    []string{"pid"},
+		},
+	}
+}
+
+func TestHandleVerifierStoreImported(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := verifierStoreImportedRequest(t)
+	response, err := callVerifierStoreImported(t, app, request)
+	require.NoError(t, err)
+	require.True(t, response.Created)
+
+	verifier, err := app.FindRecordById("verifiers", response.VerifierID)
+	require.NoError(t, err)
+	require.Equal(t, "https://verifier.example/start", verifier.GetString("url"))
+	require.Equal(t, request.OrgID, verifier.GetString("owner"))
+	require.Equal(t, "Example Verifier", verifier.GetString("name"))
+	require.Equal(t, "openid4vp_verifier/1.0", verifier.GetString("standard_and_version"))
+	require.Equal(t, []string{"SD-JWT", "mDOC"}, verifier.GetStringSlice("format"))
+	require.Equal(t, []string{"ES256"}, verifier.GetStringSlice("signing_algorithms"))
+	require.Equal(
+		t,
+		[]string{"jwk", "cose_key"},
+		verifier.GetStringSlice("cryptographic_binding_methods"),
+	)
+	require.True(t, verifier.GetBool("imported"))
+	var provenance map[string]any
+	require.NoError(t, verifier.UnmarshalJSONField("provenance", &provenance))
+	require.Equal(t, verifierImportProvenanceSource, provenance["source"])
+	require.Equal(t, "x509_san_dns", provenance["client_id_scheme"])
+
+	useCase, err := app.FindRecordById("use_cases_verifications", response.UseCaseID)
+	require.NoError(t, err)
+	require.Equal(t, "Present pid", useCase.GetString("name"))
+	require.Equal(t, verifier.Id, useCase.GetString("verifier"))
+	require.JSONEq(
+		t,
+		`{"credentials": [{"id": "pid", "format": "dc+sd-jwt"}]}`,
+		useCase.GetString("dcql_query"),
+	)
+	var code struct {
+		Tests map[string]struct {
+			Steps []struct {
+				HTTP struct {
+					URL      string                    `yaml:"url"`
+					Captures map[string]map[string]any `yaml:"captures"`
+				} `yaml:"http"`
+			} `yaml:"steps"`
+		} `yaml:"tests"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(useCase.GetString("yaml")), &code))
+	step := code.Tests["openid4vp_verifier"].Steps[0]
+	require.Equal(t, "https://verifier.example/start", step.HTTP.URL)
+	require.Equal(t, true, step.HTTP.Captures["deeplink"]["body"])
+
+	updated, err := callVerifierStoreImported(t, app, request)
+	require.NoError(t, err)
+	require.False(t, updated.Created)
+	require.Equal(t, response.VerifierID, updated.VerifierID)
+	require.Equal(t, response.UseCaseID, updated.UseCaseID)
+}
+
+func TestHandleVerifierStoreImportedAuthorizationRequest(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := verifierStoreImportedRequest(t)
+	request.URL = request.Request.URL
+	request.Request.Source = vprequest.SourceURL
+	request.Request.Endpoint = ""
+	delete(request.Request.Parameters, "client_metadata")
+	response, err := callVerifierStoreImported(t, app, request)
+	require.NoError(t, err)
+
+	verifier, err := app.FindRecordById("verifiers", response.VerifierID)
+	require.NoError(t, err)
+	require.Equal(t, "https://verifier.example", verifier.GetString("url"))
+	require.Equal(t, "verifier.example", verifier.GetString("name"))
+
+	useCase, err := app.FindRecordById("use_cases_verifications", response.UseCaseID)
+	require.NoError(t, err)
+	require.Contains(t, useCase.GetString("yaml"), "id: capture-plugin")
+	require.Contains(t, useCase.GetString("yaml"), "deeplink: openid4vp://?client_id=")
+}
+
+func TestHandleVerifierStoreImportedKeepsManualRecords(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	request := verifierStoreImportedRequest(t)
+	collection, err := app.FindCollectionByNameOrId("verifiers")
+	require.NoError(t, err)
+	existing := core.NewRecord(collection)
+	existing.Set("owner", request.OrgID)
+	existing.Set("url", request.URL)
+	existing.Set("name", "Our verifier")
+	existing.Set("description", "Configured by hand")
+	require.NoError(t, app.Save(existing))
+
+	response, err := callVerifierStoreImported(t, app, request)
+	require.NoError(t, err)
+	require.Equal(t, existing.Id, response.VerifierID)
+	require.False(t, response.Created)
+
+	record, err := app.FindRecordById("verifiers", existing.Id)
+	require.NoError(t, err)
+	require.Equal(t, "Our verifier", record.GetString("name"))
+	require.Equal(t, "Configured by hand", record.GetString("description"))
+	require.NotEmpty(t, record.GetString("provenance"))
+}
+
+func TestHandleVerifierStoreImportedRejectsInvalidRequests(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	addTrustedListFields(t, app)
+
+	noOrg := verifierStoreImportedRequest(t)
+	noOrg.OrgID = ""
+	noURL := verifierStoreImportedRequest(t)
+	noURL.URL = "openid4vp://?client_id=verifier.example"
+	noURL.Request.Parameters = map[string]any{}
+	invalid := verifierStoreImportedRequest(t)
+	invalid.Report.Valid = false
+
+	tests := []struct {
+		name    string
+		request workflows.StoreImportedVerifierRequest
+		want    string
+	}{
+		{name: "no organization", request: noOrg, want: "missing organization"},
+		{name: "no url", request: noURL, want: "missing verifier URL"},
+		{name: "invalid request", request: invalid, want: "invalid verifier request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callVerifierStoreImported(t, app, tt.request)
+			var apiErr *apierror.APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.Code)
+			require.Equal(t, tt.want, apiErr.Reason)
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
@@ -59,8 +60,12 @@ var WalletRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:        http.MethodPost,
 			Path:          "/start-check",
+			Permission:    apikey.PermissionWalletsWrite,
 			Handler:       HandleWalletStartCheck,
 			RequestSchema: WalletURL{},
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+			},
 		},
 	},
 }
@@ -74,6 +79,7 @@ var WalletTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/get-installer-md5-or-etag",
+			Permission:     apikey.PermissionWalletsRead,
 			Handler:        HandleWalletGetInstallerMD5OrETag,
 			RequestSchema:  WalletInstallerMD5OrETagRequest{},
 			ResponseSchema: WalletInstallerMD5OrETagResponse{},
@@ -82,9 +88,10 @@ var WalletTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
 			},
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "/store-pipeline-result",
-			Handler: HandleWalletStorePipelineResult,
+			Method:     http.MethodPost,
+			Path:       "/store-pipeline-result",
+			Permission: apikey.PermissionResultsWrite,
+			Handler:    HandleWalletStorePipelineResult,
 			Middlewares: []*hook.Handler[*core.RequestEvent]{
 				middlewares.RequireInternalAdminOrAuth(),
 				apis.BodyLimit(500 << 20),
@@ -470,6 +477,9 @@ func HandleWalletStorePipelineResult() func(*core.RequestEvent) error {
 		); apiErr != nil {
 			return apiErr
 		}
+		if apiErr := checkStorageQuota(e, resultRecord.GetString("owner")); apiErr != nil {
+			return apiErr
+		}
 
 		versionName := strings.ReplaceAll(
 			strings.Trim(runnerIdentifier, "/"),
@@ -516,40 +526,6 @@ func HandleWalletStorePipelineResult() func(*core.RequestEvent) error {
 	}
 }
 
-func authorizeOwnerAccess(e *core.RequestEvent, ownerID string) *apierror.APIError {
-	if isInternalAdminPrincipal(e.Auth) {
-		return nil
-	}
-	if ownerID == "" {
-		return apierror.New(
-			http.StatusInternalServerError,
-			"owner",
-			"owner missing",
-			"record owner is required",
-		)
-	}
-
-	allowed, err := belongsToAuthenticatedOrganization(e, ownerID)
-	if err != nil {
-		return apierror.New(
-			http.StatusInternalServerError,
-			"organization",
-			"failed to get user organization",
-			err.Error(),
-		)
-	}
-	if !allowed {
-		return apierror.New(
-			http.StatusForbidden,
-			"authorization",
-			"forbidden",
-			"record does not belong to the authenticated user's organization",
-		)
-	}
-
-	return nil
-}
-
 func authorizePipelineResultStoreAccess(
 	e *core.RequestEvent,
 	ownerID string,
@@ -664,20 +640,15 @@ func authorizeWalletInstallerAccess(
 				err.Error(),
 			)
 		}
-		if walletRecord.GetBool("published") {
-			return nil
-		}
+		return authorizeWalletAccess(e, walletRecord, apikey.PermissionWalletsRead)
 	}
 
-	return authorizeOwnerAccess(e, versionRecord.GetString("owner"))
-}
-
-func belongsToAuthenticatedOrganization(e *core.RequestEvent, ownerID string) (bool, error) {
-	userOrgID, err := pbutils.GetUserOrganizationID(e.App, e.Auth.Id)
-	if err != nil {
-		return false, err
-	}
-	return userOrgID == ownerID, nil
+	return authorizeOwnerAccess(
+		e,
+		versionRecord.GetString("owner"),
+		false,
+		apikey.PermissionWalletsRead,
+	)
 }
 
 func isInternalAdminPrincipal(auth *core.Record) bool {
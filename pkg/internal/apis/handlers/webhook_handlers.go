@@ -0,0 +1,670 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/forkbombeu/credimi/pkg/internal/routing"
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	webhookDeliveriesDefaultLimit = 50
+	webhookDeliveriesMaxLimit     = 500
+)
+
+var WebhookRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/organizations/my/webhooks",
+	AuthenticationRequired: true,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:         http.MethodGet,
+			Path:           "",
+			OperationID:    "webhooks.list",
+			Permission:     apikey.PermissionWebhooksRead,
+			Handler:        HandleListWebhooks,
+			ResponseSchema: []WebhookResponse{},
+			Description:    "List the webhooks of the caller organization",
+		},
+		{
+			Method:         http.MethodPost,
+			Path:           "",
+			OperationID:    "webhooks.create",
+			Permission:     apikey.PermissionWebhooksWrite,
+			Handler:        HandleCreateWebhook,
+			RequestSchema:  CreateWebhookRequest{},
+			ResponseSchema: WebhookSecretResponse{},
+			Description: "Subscribe a URL to events of the caller organization. " +
+				"The signing secret is only returned here and on rotation.",
+		},
+		{
+			Method:         http.MethodPatch,
+			Path:           "/{id}",
+			OperationID:    "webhooks.update",
+			Permission:     apikey.PermissionWebhooksWrite,
+			Handler:        HandleUpdateWebhook,
+			RequestSchema:  UpdateWebhookRequest{},
+			ResponseSchema: WebhookResponse{},
+			Description:    "Change the URL, events or state of a webhook",
+		},
+		{
+			Method:         http.MethodDelete,
+			Path:           "/{id}",
+			OperationID:    "webhooks.delete",
+			Permission:     apikey.PermissionWebhooksWrite,
+			Handler:        HandleDeleteWebhook,
+			ResponseSchema: routing.NoContent{},
+			Description:    "Delete a webhook; its deliveries are kept",
+		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/{id}/rotate-secret",
+			OperationID:    "webhooks.rotateSecret",
+			Permission:     apikey.PermissionWebhooksWrite,
+			Handler:        HandleRotateWebhookSecret,
+			ResponseSchema: WebhookSecretResponse{},
+			Description:    "Replace the signing secret of a webhook",
+		},
+		{
+			Method:                http.MethodGet,
+			Path:                  "/deliveries",
+			OperationID:           "webhooks.listDeliveries",
+			Permission:            apikey.PermissionWebhooksRead,
+			Handler:               HandleListWebhookDeliveries,
+			ResponseSchema:        WebhookDeliveriesResponse{},
+			QuerySearchAttributes: webhookDeliveryQueryAttributes,
+			Description:           "List the deliveries of the caller organization, newest first",
+		},
+		{
+			Method:         http.MethodGet,
+			Path:           "/deliveries/{id}",
+			OperationID:    "webhooks.getDelivery",
+			Permission:     apikey.PermissionWebhooksRead,
+			Handler:        HandleGetWebhookDelivery,
+			ResponseSchema: WebhookDeliveryResponse{},
+			Description:    "Get a webhook delivery with its payload and last response",
+		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/deliveries/{id}/redeliver",
+			OperationID:    "webhooks.redeliver",
+			Permission:     apikey.PermissionWebhooksWrite,
+			Handler:        HandleRedeliverWebhookDelivery,
+			ResponseSchema: WebhookDeliveryResponse{},
+			Description:    "Send the payload of a delivery again, as a new delivery",
+		},
+	},
+}
+
+var WebhookTemporalInternalRoutes routing.RouteGroup = routing.RouteGroup{
+	BaseURL:                "/api/webhooks",
+	AuthenticationRequired: false,
+	Middlewares: []*hook.Handler[*core.RequestEvent]{
+		{Func: middlewares.ErrorHandlingMiddleware},
+	},
+	Routes: []routing.RouteDefinition{
+		{
+			Method:         http.MethodPost,
+			Path:           "/deliveries/{id}/attempt",
+			Handler:        HandleAttemptWebhookDelivery,
+			ResponseSchema: webhook.Result{},
+			Description: "Send a pending webhook delivery; answers 502 when the " +
+				"receiver did not accept it, so that the attempt is retried",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+		{
+			Method:         http.MethodPost,
+			Path:           "/deliveries/{id}/fail",
+			Handler:        HandleFailWebhookDelivery,
+			RequestSchema:  FailWebhookDeliveryRequest{},
+			ResponseSchema: webhook.Result{},
+			Description:    "Give up on a webhook delivery whose retries are exhausted",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.RequireInternalAdminAPIKey(),
+			},
+		},
+	},
+}
+
+var webhookDeliveryQueryAttributes = []routing.QuerySearchAttribute{
+	{Name: "webhook", Description: "Id of the webhook"},
+	{Name: "event", Description: "Event, e.g. pipeline.finished"},
+	{Name: "status", Description: "pending, succeeded or failed"},
+	{Name: "page", Description: "Page number, starting from 0"},
+	{Name: "limit", Description: "Deliveries per page (default 50, max 500)"},
+}
+
+type CreateWebhookRequest struct {
+	Name   string          `json:"name"   validate:"required,max=100"`
+	URL    string          `json:"url"    validate:"required"`
+	Events []webhook.Event `json:"events" validate:"required,min=1"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	Name    *string         `json:"name,omitempty"    validate:"omitempty,min=1,max=100"`
+	URL     *string         `json:"url,omitempty"`
+	Events  []webhook.Event `json:"events,omitempty"`
+	Enabled *bool           `json:"enabled,omitempty"`
+}
+
+type FailWebhookDeliveryRequest struct {
+	Reason string `json:"reason"`
+}
+
+type WebhookResponse struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	URL     string          `json:"url"`
+	Events  []webhook.Event `json:"events"`
+	Enabled bool            `json:"enabled"`
+	Created string          `json:"created"`
+	Updated string          `json:"updated"`
+}
+
+type WebhookSecretResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	Webhook        string          `json:"webhook"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+	RedeliveryOf   string          `json:"redelivery_of,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Created        string          `json:"created"`
+	Updated        string          `json:"updated"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Items []WebhookDeliveryResponse `json:"items"`
+	Page  int                       `json:"page"`
+	Limit int                       `json:"limit"`
+	Total int                       `json:"total"`
+}
+
+func HandleListWebhooks() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID, apiErr := webhookOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		records, err := e.App.FindRecordsByFilter(
+			webhook.Collection,
+			"owner = {:owner}",
+			"created",
+			-1,
+			0,
+			dbx.Params{"owner": orgID},
+		)
+		if err != nil {
+			return webhookReadError(err)
+		}
+		items := make([]WebhookResponse, 0, len(records))
+		for _, record := range records {
+			items = append(items, webhookResponse(record))
+		}
+		return e.JSON(http.StatusOK, items)
+	}
+}
+
+func HandleCreateWebhook() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID, apiErr := webhookOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		input, err := routing.GetValidatedInput[CreateWebhookRequest](e)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		if apiErr := validateWebhookURL(e.Request.Context(), input.URL); apiErr != nil {
+			return apiErr
+		}
+		if apiErr := validateWebhookEvents(input.Events); apiErr != nil {
+			return apiErr
+		}
+
+		collection, err := e.App.FindCachedCollectionByNameOrId(webhook.Collection)
+		if err != nil {
+			return webhookWriteError(err)
+		}
+		record := core.NewRecord(collection)
+		record.Set("owner", orgID)
+		record.Set("name", strings.TrimSpace(input.Name))
+		record.Set("url", input.URL)
+		record.Set("events", input.Events)
+		record.Set("enabled", input.Enabled == nil || *input.Enabled)
+		secret := webhook.NewSecret()
+		record.Set("secret", secret)
+		if err := e.App.Save(record); err != nil {
+			return webhookWriteError(err)
+		}
+		return e.JSON(http.StatusCreated, WebhookSecretResponse{
+			WebhookResponse: webhookResponse(record),
+			Secret:          secret,
+		})
+	}
+}
+
+func HandleUpdateWebhook() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, apiErr := findOrganizationWebhook(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		input, err := routing.GetValidatedInput[UpdateWebhookRequest](e)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		if input.Name != nil {
+			record.Set("name", strings.TrimSpace(*input.Name))
+		}
+		if input.URL != nil {
+			if apiErr := validateWebhookURL(e.Request.Context(), *input.URL); apiErr != nil {
+				return apiErr
+			}
+			record.Set("url", *input.URL)
+		}
+		if input.Events != nil {
+			if apiErr := validateWebhookEvents(input.Events); apiErr != nil {
+				return apiErr
+			}
+			record.Set("events", input.Events)
+		}
+		if input.Enabled != nil {
+			record.Set("enabled", *input.Enabled)
+		}
+		if err := e.App.Save(record); err != nil {
+			return webhookWriteError(err)
+		}
+		return e.JSON(http.StatusOK, webhookResponse(record))
+	}
+}
+
+func HandleDeleteWebhook() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, apiErr := findOrganizationWebhook(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		if err := e.App.Delete(record); err != nil {
+			return webhookWriteError(err)
+		}
+		return e.NoContent(http.StatusNoContent)
+	}
+}
+
+func HandleRotateWebhookSecret() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, apiErr := findOrganizationWebhook(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		secret := webhook.NewSecret()
+		record.Set("secret", secret)
+		if err := e.App.Save(record); err != nil {
+			return webhookWriteError(err)
+		}
+		return e.JSON(http.StatusOK, WebhookSecretResponse{
+			WebhookResponse: webhookResponse(record),
+			Secret:          secret,
+		})
+	}
+}
+
+func HandleListWebhookDeliveries() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		orgID, apiErr := webhookOrganization(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		query := e.Request.URL.Query()
+		filter := []string{"owner = {:owner}"}
+		params := dbx.Params{"owner": orgID}
+		conditions := dbx.HashExp{"owner": orgID}
+		for _, name := range []string{"webhook", "event", "status"} {
+			if value := strings.TrimSpace(query.Get(name)); value != "" {
+				filter = append(filter, name+" = {:"+name+"}")
+				params[name] = value
+				conditions[name] = value
+			}
+		}
+
+		limit, page := parsePageParams(e, webhookDeliveriesDefaultLimit, 0)
+		limit = min(limit, webhookDeliveriesMaxLimit)
+		records, err := e.App.FindRecordsByFilter(
+			webhook.DeliveryCollection,
+			strings.Join(filter, " && "),
+			"-created",
+			limit,
+			page*limit,
+			params,
+		)
+		if err != nil {
+			return webhookReadError(err)
+		}
+		total, err := e.App.CountRecords(webhook.DeliveryCollection, conditions)
+		if err != nil {
+			return webhookReadError(err)
+		}
+
+		items := make([]WebhookDeliveryResponse, 0, len(records))
+		for _, record := range records {
+			items = append(items, webhookDeliveryResponse(record))
+		}
+		return e.JSON(http.StatusOK, WebhookDeliveriesResponse{
+			Items: items,
+			Page:  page,
+			Limit: limit,
+			Total: int(total),
+		})
+	}
+}
+
+func HandleGetWebhookDelivery() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, apiErr := findOrganizationWebhookDelivery(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		return e.JSON(http.StatusOK, webhookDeliveryResponse(record))
+	}
+}
+
+func HandleRedeliverWebhookDelivery() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, apiErr := findOrganizationWebhookDelivery(e)
+		if apiErr != nil {
+			return apiErr
+		}
+		if record.GetString("webhook") == "" {
+			return apierror.New(
+				http.StatusConflict,
+				"webhook",
+				"webhook_deleted",
+				"the webhook of this delivery was deleted",
+			)
+		}
+		redelivery, err := webhook.Redeliver(e.App, record)
+		if err != nil {
+			return webhookWriteError(err)
+		}
+		return e.JSON(http.StatusAccepted, webhookDeliveryResponse(redelivery))
+	}
+}
+
+func HandleAttemptWebhookDelivery() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		result, err := webhook.Attempt(
+			e.Request.Context(),
+			e.App,
+			e.Request.PathValue("id"),
+		)
+		if err != nil {
+			return webhookDeliveryError(err)
+		}
+		if result.Status == webhook.StatusPending {
+			return apierror.New(
+				http.StatusBadGateway,
+				"webhook",
+				"webhook_delivery_failed",
+				result.Error,
+			)
+		}
+		return e.JSON(http.StatusOK, result)
+	}
+}
+
+func HandleFailWebhookDelivery() func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		input, err := routing.GetValidatedInput[FailWebhookDeliveryRequest](e)
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_request",
+				err.Error(),
+			)
+		}
+		result, err := webhook.MarkFailed(e.App, e.Request.PathValue("id"), input.Reason)
+		if err != nil {
+			return webhookDeliveryError(err)
+		}
+		return e.JSON(http.StatusOK, result)
+	}
+}
+
+// emitWebhookEvent queues the deliveries of event to the webhooks of orgID.
+// The event already happened, so failures are only logged.
+func emitWebhookEvent(app core.App, orgID string, event webhook.Event, data any) {
+	if _, err := webhook.Emit(app, orgID, event, data); err != nil {
+		log.Printf("failed to emit webhook event %s for %s: %v", event, orgID, err)
+	}
+}
+
+// webhookOrganization returns the organization of the caller.
+func webhookOrganization(e *core.RequestEvent) (string, *apierror.APIError) {
+	membership, err := orgrole.Find(e.App, e.Auth.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", apierror.New(
+			http.StatusForbidden,
+			"webhook",
+			"organization_required",
+			"webhooks belong to an organization",
+		)
+	}
+	if err != nil {
+		return "", webhookReadError(err)
+	}
+	return membership.Organization, nil
+}
+
+func findOrganizationWebhook(e *core.RequestEvent) (*core.Record, *apierror.APIError) {
+	return findOrganizationRecord(e, webhook.Collection, "webhook not found")
+}
+
+func findOrganizationWebhookDelivery(e *core.RequestEvent) (*core.Record, *apierror.APIError) {
+	return findOrganizationRecord(e, webhook.DeliveryCollection, "webhook delivery not found")
+}
+
+// findOrganizationRecord returns the record of collection named by the id
+// path parameter, when it belongs to the caller organization.
+func findOrganizationRecord(
+	e *core.RequestEvent,
+	collection string,
+	notFound string,
+) (*core.Record, *apierror.APIError) {
+	orgID, apiErr := webhookOrganization(e)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	record, err := e.App.FindFirstRecordByFilter(
+		collection,
+		"id = {:id} && owner = {:owner}",
+		dbx.Params{"id": e.Request.PathValue("id"), "owner": orgID},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierror.New(http.StatusNotFound, "webhook", "not_found", notFound)
+	}
+	if err != nil {
+		return nil, webhookReadError(err)
+	}
+	return record, nil
+}
+
+// lookupWebhookHost resolves the host of webhook URLs; tests replace it.
+var lookupWebhookHost = net.DefaultResolver.LookupIPAddr
+
+// validateWebhookURL accepts absolute http or https URLs whose host resolves
+// only to public addresses. Deliveries check the address again when dialing,
+// since the host may resolve differently by then.
+func validateWebhookURL(ctx context.Context, raw string) *apierror.APIError {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return apierror.New(
+			http.StatusBadRequest,
+			"request.validation",
+			"invalid_url",
+			"url must be an absolute http or https URL",
+		)
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := lookupWebhookHost(ctx, parsed.Hostname())
+		if err != nil {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_url",
+				"could not resolve the url host: "+err.Error(),
+			)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if utils.IsPrivateIP(ip) {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_url",
+				"url must not point to a private or internal address",
+			)
+		}
+	}
+	return nil
+}
+
+func validateWebhookEvents(events []webhook.Event) *apierror.APIError {
+	if len(events) == 0 {
+		return apierror.New(
+			http.StatusBadRequest,
+			"request.validation",
+			"invalid_events",
+			"at least one event is required",
+		)
+	}
+	for _, event := range events {
+		if !slices.Contains(webhook.Events, event) {
+			return apierror.New(
+				http.StatusBadRequest,
+				"request.validation",
+				"invalid_events",
+				"unknown event "+string(event),
+			)
+		}
+	}
+	return nil
+}
+
+func webhookResponse(record *core.Record) WebhookResponse {
+	return WebhookResponse{
+		ID:      record.Id,
+		Name:    record.GetString("name"),
+		URL:     record.GetString("url"),
+		Events:  webhook.SubscribedEvents(record),
+		Enabled: record.GetBool("enabled"),
+		Created: record.GetString("created"),
+		Updated: record.GetString("updated"),
+	}
+}
+
+func webhookDeliveryResponse(record *core.Record) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             record.Id,
+		Webhook:        record.GetString("webhook"),
+		Event:          record.GetString("event"),
+		Status:         record.GetString("status"),
+		Attempts:       record.GetInt("attempts"),
+		ResponseStatus: record.GetInt("response_status"),
+		ResponseBody:   record.GetString("response_body"),
+		LastError:      record.GetString("last_error"),
+		DeliveredAt:    record.GetString("delivered_at"),
+		RedeliveryOf:   record.GetString("redelivery_of"),
+		Payload:        json.RawMessage(record.GetString("payload")),
+		Created:        record.GetString("created"),
+		Updated:        record.GetString("updated"),
+	}
+}
+
+func webhookReadError(err error) *apierror.APIError {
+	return apierror.New(
+		http.StatusInternalServerError,
+		"webhook",
+		"failed_to_read_webhooks",
+		err.Error(),
+	)
+}
+
+func webhookWriteError(err error) *apierror.APIError {
+	return apierror.New(
+		http.StatusInternalServerError,
+		"webhook",
+		"failed_to_save_webhook",
+		err.Error(),
+	)
+}
+
+func webhookDeliveryError(err error) *apierror.APIError {
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierror.New(
+			http.StatusNotFound,
+			"webhook",
+			"not_found",
+			"webhook delivery not found",
+		)
+	}
+	return apierror.New(
+		http.StatusInternalServerError,
+		"webhook",
+		"failed_to_deliver_webhook",
+		err.Error(),
+	)
+}
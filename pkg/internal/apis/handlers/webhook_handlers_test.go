@@ -0,0 +1,382 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWebhookID         = "whtestwebhook01"
+	testWebhookDeliveryID = "whtestdelivery1"
+)
+
+func seedWebhook(t testing.TB, app core.App, orgID, url string) *core.Record {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId(webhook.Collection)
+	require.NoError(t, err)
+	record := core.NewRecord(collection)
+	record.Id = testWebhookID
+	record.Set("owner", orgID)
+	record.Set("name", "ci")
+	record.Set("url", url)
+	record.Set("secret", "whsec_test")
+	record.Set("events", []webhook.Event{webhook.EventPipelineFinished})
+	record.Set("enabled", true)
+	require.NoError(t, app.Save(record))
+	return record
+}
+
+func seedWebhookDelivery(t testing.TB, app core.App, orgID, webhookID string) *core.Record {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId(webhook.DeliveryCollection)
+	require.NoError(t, err)
+	record := core.NewRecord(collection)
+	record.Id = testWebhookDeliveryID
+	record.Set("owner", orgID)
+	record.Set("webhook", webhookID)
+	record.Set("event", string(webhook.EventPipelineFinished))
+	record.Set("payload", map[string]any{"id": "event-1", "event": "pipeline.finished"})
+	record.Set("status", webhook.StatusPending)
+	require.NoError(t, app.Save(record))
+	return record
+}
+
+// stubWebhookLookup resolves internal.example to a private address and any
+// other host to a public one, so tests do not depend on DNS.
+func stubWebhookLookup(t testing.TB) {
+	t.Helper()
+
+	previous := lookupWebhookHost
+	lookupWebhookHost = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if host == "internal.example" {
+			return []net.IPAddr{{IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return []net.IPAddr{{IP: net.ParseIP("93.184.215.14")}}, nil
+	}
+	t.Cleanup(func() { lookupWebhookHost = previous })
+}
+
+func setWebhookTestRole(t testing.TB, app core.App, userID, role string) {
+	t.Helper()
+
+	roleRecord, err := app.FindFirstRecordByData("orgRoles", "name", role)
+	require.NoError(t, err)
+	authorization, err := app.FindFirstRecordByData("orgAuthorizations", "user", userID)
+	require.NoError(t, err)
+	authorization.Set("role", roleRecord.Id)
+	require.NoError(t, app.Save(authorization))
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+	userRecord, err := getUserRecordFromName("userA")
+	require.NoError(t, err)
+	token, err := userRecord.NewAuthToken()
+	require.NoError(t, err)
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	redeliverURL := "/api/organizations/my/webhooks/deliveries/" +
+		testWebhookDeliveryID + "/redeliver"
+	stubWebhookLookup(t)
+
+	webhookApp := func(t testing.TB) *tests.TestApp {
+		app := setupOrganizationApp(t)
+		WebhookRoutes.Add(app)
+		seedWebhook(t, app, orgID, "https://example.org/hooks")
+		seedWebhookDelivery(t, app, orgID, testWebhookID)
+		return app
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "create a webhook and return its secret once",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"deploys","url":"https://example.org/deploys",` +
+					`"events":["pipeline.started","runner.offline"]}`,
+			),
+			Headers:        headers,
+			ExpectedStatus: http.StatusCreated,
+			ExpectedContent: []string{
+				`"secret":"whsec_`,
+				`"events":["pipeline.started","runner.offline"]`,
+				`"enabled":true`,
+			},
+			TestAppFactory: webhookApp,
+		},
+		{
+			Name:   "reject unknown events",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"x","url":"https://example.org","events":["nope"]}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"nope"},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:   "reject URLs to loopback addresses",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"x","url":"http://127.0.0.1:8090/api","events":["step.failed"]}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_url", "private or internal address"},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:   "reject URLs to the cloud metadata address",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"x","url":"http://169.254.169.254/latest","events":["step.failed"]}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_url"},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:   "reject updates to hosts resolving to private addresses",
+			Method: http.MethodPatch,
+			URL:    "/api/organizations/my/webhooks/" + testWebhookID,
+			Body: strings.NewReader(
+				`{"url":"https://internal.example/hooks"}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"invalid_url"},
+			TestAppFactory:  webhookApp,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, _ *http.Response) {
+				record, err := app.FindRecordById(webhook.Collection, testWebhookID)
+				require.NoError(t, err)
+				require.Equal(t, "https://example.org/hooks", record.GetString("url"))
+			},
+		},
+		{
+			Name:   "reject URLs that are not http",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"x","url":"ftp://example.org","events":["step.failed"]}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{"url"},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:               "list webhooks without their secret",
+			Method:             http.MethodGet,
+			URL:                "/api/organizations/my/webhooks",
+			Headers:            headers,
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"url":"https://example.org/hooks"`},
+			NotExpectedContent: []string{"whsec_test"},
+			TestAppFactory:     webhookApp,
+		},
+		{
+			Name:            "disable a webhook",
+			Method:          http.MethodPatch,
+			URL:             "/api/organizations/my/webhooks/" + testWebhookID,
+			Body:            strings.NewReader(`{"enabled":false}`),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"enabled":false`},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:           "redeliver a delivery as a new pending delivery",
+			Method:         http.MethodPost,
+			URL:            redeliverURL,
+			Headers:        headers,
+			ExpectedStatus: http.StatusAccepted,
+			ExpectedContent: []string{
+				`"redelivery_of":"` + testWebhookDeliveryID + `"`,
+				`"status":"pending"`,
+			},
+			TestAppFactory: webhookApp,
+		},
+		{
+			Name:            "refuse to redeliver to a deleted webhook",
+			Method:          http.MethodPost,
+			URL:             redeliverURL,
+			Headers:         headers,
+			ExpectedStatus:  http.StatusConflict,
+			ExpectedContent: []string{"webhook_deleted"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := webhookApp(t)
+				record, err := app.FindRecordById(webhook.Collection, testWebhookID)
+				require.NoError(t, err)
+				require.NoError(t, app.Delete(record))
+				return app
+			},
+		},
+		{
+			Name:            "answer 404 for webhooks of other organizations",
+			Method:          http.MethodDelete,
+			URL:             "/api/organizations/my/webhooks/missing",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{"webhook not found"},
+			TestAppFactory:  webhookApp,
+		},
+		{
+			Name:            "list deliveries filtered by status",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/webhooks/deliveries?status=pending",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"event":"pipeline.finished"`},
+			TestAppFactory:  webhookApp,
+			AfterTestFunc: func(t testing.TB, _ *tests.TestApp, res *http.Response) {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				var payload WebhookDeliveriesResponse
+				require.NoError(t, json.Unmarshal(body, &payload))
+				require.Equal(t, 1, payload.Total)
+				require.Equal(t, webhookDeliveriesDefaultLimit, payload.Limit)
+				require.Len(t, payload.Items, 1)
+				require.Equal(t, testWebhookDeliveryID, payload.Items[0].ID)
+				require.JSONEq(t,
+					`{"id":"event-1","event":"pipeline.finished"}`,
+					string(payload.Items[0].Payload),
+				)
+			},
+		},
+		{
+			Name:   "reject webhook changes from viewers",
+			Method: http.MethodPost,
+			URL:    "/api/organizations/my/webhooks",
+			Body: strings.NewReader(
+				`{"name":"x","url":"https://example.org","events":["step.failed"]}`,
+			),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusForbidden,
+			ExpectedContent: []string{"organization_role_not_allowed"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := webhookApp(t)
+				setWebhookTestRole(t, app, userRecord.Id, "viewer")
+				return app
+			},
+		},
+		{
+			Name:            "let viewers read deliveries",
+			Method:          http.MethodGet,
+			URL:             "/api/organizations/my/webhooks/deliveries",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"total":1`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app := webhookApp(t)
+				setWebhookTestRole(t, app, userRecord.Id, "viewer")
+				return app
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestAttemptWebhookDelivery(t *testing.T) {
+	orgID, err := getOrgIDfromName("userA's organization")
+	require.NoError(t, err)
+
+	accept := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NotEmpty(t, r.Header.Get(webhook.HeaderSignature))
+		if accept {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	// The receiver listens on loopback, which the delivery client refuses.
+	previousClient := webhook.HTTPClient
+	webhook.HTTPClient = receiver.Client()
+	defer func() { webhook.HTTPClient = previousClient }()
+
+	attemptApp := func(t testing.TB) *tests.TestApp {
+		app, err := tests.NewTestApp(testDataDir)
+		require.NoError(t, err)
+		WebhookTemporalInternalRoutes.Add(app)
+		seedInternalAdminKey(t, app)
+		seedWebhook(t, app, orgID, receiver.URL)
+		seedWebhookDelivery(t, app, orgID, testWebhookID)
+		return app
+	}
+	headers := map[string]string{"Credimi-Api-Key": "internal-test-api-key"}
+	deliveryURL := "/api/webhooks/deliveries/" + testWebhookDeliveryID
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "deliver to a receiver that accepts the event",
+			Method:          http.MethodPost,
+			URL:             deliveryURL + "/attempt",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"status":"succeeded"`, `"response_status":204`},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				accept = true
+				return attemptApp(t)
+			},
+		},
+		{
+			Name:            "answer 502 while the receiver rejects the event",
+			Method:          http.MethodPost,
+			URL:             deliveryURL + "/attempt",
+			Headers:         headers,
+			ExpectedStatus:  http.StatusBadGateway,
+			ExpectedContent: []string{"receiver answered 500"},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				accept = false
+				return attemptApp(t)
+			},
+		},
+		{
+			Name:            "mark a delivery failed",
+			Method:          http.MethodPost,
+			URL:             deliveryURL + "/fail",
+			Body:            strings.NewReader(`{"reason":"delivery retries exhausted"}`),
+			Headers:         headers,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"status":"failed"`, "delivery retries exhausted"},
+			TestAppFactory:  attemptApp,
+		},
+		{
+			Name:            "require the internal API key",
+			Method:          http.MethodPost,
+			URL:             deliveryURL + "/attempt",
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{"api_key_required"},
+			TestAppFactory:  attemptApp,
+		},
+	}
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
@@ -44,6 +46,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/{workflowId}/runs",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflowRuns.list",
 			Handler:        HandleListMyWorkflowRuns,
 			ResponseSchema: ListMyWorkflowRunsResponse{},
@@ -53,6 +56,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/{workflowId}/runs/{runId}",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflowRun.get",
 			Handler:        HandleGetMyWorkflowRun,
 			ResponseSchema: GetMyWorkflowRunResponse{},
@@ -62,6 +66,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/{workflowId}/runs/{runId}/history",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflowRun.history",
 			Handler:        HandleGetMyWorkflowRunHistory,
 			ResponseSchema: GetMyWorkflowRunHistoryResponse{},
@@ -71,16 +76,22 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/{workflowId}/runs/{runId}/rerun",
+			Permission:     apikey.PermissionWorkflowsRun,
 			OperationID:    "workflowRun.rerun",
 			Handler:        HandleRerunMyWorkflow,
 			RequestSchema:  ReRunWorkflowRequest{},
 			ResponseSchema: ReRunWorkflowResponse{},
 			Description:    "Re-run a specific workflow run",
 			Summary:        "Re-run a specific workflow run",
+			Middlewares: []*hook.Handler[*core.RequestEvent]{
+				middlewares.Idempotent(),
+				middlewares.RequireRunQuota(),
+			},
 		},
 		{
 			Method:         http.MethodPost,
 			Path:           "/{workflowId}/runs/{runId}/cancel",
+			Permission:     apikey.PermissionWorkflowsRun,
 			OperationID:    "workflowRun.cancel",
 			Handler:        HandleCancelMyWorkflowRun,
 			ResponseSchema: CancelMyWorkflowRunResponse{},
@@ -90,6 +101,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/{workflowId}/runs/{runId}/export",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflowRun.export",
 			Handler:        HandleExportMyWorkflowRun,
 			ResponseSchema: ExportMyWorkflowRunResponse{},
@@ -99,6 +111,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/{workflowId}/runs/{runId}/logs",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflowRun.logs",
 			Handler:        HandleMyWorkflowLogs,
 			ResponseSchema: WorkflowLogsResponse{},
@@ -115,6 +128,7 @@ var WorkflowsRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodPost,
 			Path:           "/{workflowId}/runs/{runId}/terminate",
+			Permission:     apikey.PermissionWorkflowsRun,
 			OperationID:    "workflowRun.terminate",
 			Handler:        HandleTerminateMyWorkflowRun,
 			ResponseSchema: TerminateMyWorkflowRunResponse{},
@@ -134,6 +148,7 @@ var WorkflowListingRoutes routing.RouteGroup = routing.RouteGroup{
 		{
 			Method:         http.MethodGet,
 			Path:           "/list-workflows",
+			Permission:     apikey.PermissionWorkflowsRead,
 			OperationID:    "workflows.list",
 			Handler:        HandleListMyWorkflows,
 			ResponseSchema: ListMyWorkflowsResponse{},
@@ -179,6 +194,14 @@ func HandleListMyWorkflows() func(*core.RequestEvent) error {
 			)
 		}
 
+		// These workflows are not tied to the records an API key can be
+		// restricted to, so a restricted key does not see them.
+		if restrictedAPIKey(e) {
+			return e.JSON(http.StatusOK, ListMyWorkflowsResponse{
+				Executions: []*WorkflowExecutionSummary{},
+			})
+		}
+
 		limit, page := parsePageParams(e, 20, 0)
 		itemOffset := page * limit
 
@@ -817,6 +840,21 @@ func HandleRerunMyWorkflow() func(*core.RequestEvent) error {
 			)
 		}
 
+		details := map[string]any{
+			"run_id":          runID,
+			"new_workflow_id": result.WorkflowID,
+			"new_run_id":      result.WorkflowRunID,
+		}
+		if len(req.Config) > 0 {
+			details["config"] = req.Config
+		}
+		recordAudit(e, audit.Entry{
+			Action:       audit.ActionWorkflowRerun,
+			ResourceType: "workflows",
+			ResourceID:   workflowID,
+			Details:      details,
+		})
+
 		return e.JSON(http.StatusOK, map[string]any{
 			"workflow_id": result.WorkflowID,
 			"run_id":      result.WorkflowRunID,
@@ -886,6 +924,13 @@ func HandleCancelMyWorkflowRun() func(*core.RequestEvent) error {
 			)
 		}
 
+		recordAudit(e, audit.Entry{
+			Action:       audit.ActionWorkflowCanceled,
+			ResourceType: "workflows",
+			ResourceID:   workflowID,
+			Details:      map[string]any{"run_id": runID},
+		})
+
 		return e.JSON(http.StatusOK, map[string]any{
 			"message":    "Workflow execution canceled successfully",
 			"workflowId": workflowID,
@@ -1249,6 +1294,13 @@ func HandleTerminateMyWorkflowRun() func(*core.RequestEvent) error {
 			)
 		}
 
+		recordAudit(e, audit.Entry{
+			Action:       audit.ActionWorkflowTerminated,
+			ResourceType: "workflows",
+			ResourceID:   workflowID,
+			Details:      map[string]any{"run_id": runID},
+		})
+
 		return e.JSON(http.StatusOK, map[string]any{
 			"message":    "Workflow execution terminated successfully",
 			"workflowId": workflowID,
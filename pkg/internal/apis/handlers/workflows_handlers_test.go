@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/apis"
@@ -1530,6 +1531,38 @@ func TestHandleListMyWorkflowsStatusFilterQuery(t *testing.T) {
 	require.Contains(t, capturedQuery, "or")
 }
 
+func TestHandleListMyWorkflowsWithRestrictedAPIKey(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	authRecord, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	origClient := listWorkflowsTemporalClient
+	t.Cleanup(func() {
+		listWorkflowsTemporalClient = origClient
+	})
+	listWorkflowsTemporalClient = func(namespace string) (client.Client, error) {
+		t.Fatal("restricted key must not list workflows")
+		return nil, nil
+	}
+
+	e, rec := restrictedAPIKeyEvent(t, app, authRecord.Id, apikey.Grant{
+		Resources: map[apikey.Resource][]string{
+			apikey.ResourceRunners: {"runner-1"},
+		},
+	}, httptest.NewRequest(http.MethodGet, "/api/list-workflows", nil))
+
+	err = HandleListMyWorkflows()(e)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp ListMyWorkflowsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Empty(t, resp.Executions)
+}
+
 func TestHandleListMyWorkflowsPagination(t *testing.T) {
 	app, err := tests.NewTestApp(testDataDir)
 	require.NoError(t, err)
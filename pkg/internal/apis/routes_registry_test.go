@@ -5,6 +5,7 @@
 package apis
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tests"
@@ -20,3 +21,24 @@ func TestRegisterMyRoutes(t *testing.T) {
 		RegisterMyRoutes(app)
 	})
 }
+
+// TestAuthRoutesDeclarePermission walks every registered route group: a
+// route requiring auth without a permission would be closed to every role
+// below admin.
+func TestAuthRoutesDeclarePermission(t *testing.T) {
+	for _, group := range append(slices.Clone(RouteGroups), RouteGroupsNotExported...) {
+		for _, route := range group.Routes {
+			if !route.RequiresAuth(group.AuthenticationRequired) {
+				continue
+			}
+			require.NotEmpty(
+				t,
+				route.Permission,
+				"%s %s%s requires auth but declares no permission",
+				route.Method,
+				group.BaseURL,
+				route.Path,
+			)
+		}
+	}
+}
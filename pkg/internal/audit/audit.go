@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package audit records the key actions taken in an organization in the
+// append-only audit_logs collection: who acted, from which address, on
+// which resource, and how the resource changed.
+package audit
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const Collection = "audit_logs"
+
+// Actions recorded in the audit log.
+const (
+	ActionAPIKeyCreated = "api_key.created"
+	ActionAPIKeyRotated = "api_key.rotated"
+	ActionAPIKeyRevoked = "api_key.revoked"
+	ActionAPIKeyUpdated = "api_key.updated"
+	ActionAPIKeyDeleted = "api_key.deleted"
+
+	ActionConfigImported = "config.imported"
+
+	ActionPipelineCreated     = "pipeline.created"
+	ActionPipelineUpdated     = "pipeline.updated"
+	ActionPipelinePublished   = "pipeline.published"
+	ActionPipelineUnpublished = "pipeline.unpublished"
+	ActionPipelineDeleted     = "pipeline.deleted"
+
+	ActionScheduleStarted  = "schedule.started"
+	ActionSchedulePaused   = "schedule.paused"
+	ActionScheduleResumed  = "schedule.resumed"
+	ActionScheduleCanceled = "schedule.canceled"
+
+	ActionRunnerPaused  = "runner.paused"
+	ActionRunnerResumed = "runner.resumed"
+
+	ActionRetentionFilesDeleted = "retention.files_deleted"
+
+	ActionWorkflowRerun      = "workflow.rerun"
+	ActionWorkflowCanceled   = "workflow.canceled"
+	ActionWorkflowTerminated = "workflow.terminated"
+)
+
+// Actor types.
+const (
+	ActorUser      = "user"
+	ActorAPIKey    = "api_key"
+	ActorSuperuser = "superuser"
+	ActorAnonymous = "anonymous"
+)
+
+// redacted replaces the values of secret fields in recorded changes.
+const redacted = "[redacted]"
+
+// secretFields are never recorded in clear, whatever the collection.
+var secretFields = map[string]bool{
+	"key":      true,
+	"password": true,
+	"tokenKey": true,
+}
+
+// Actor is who performed an action. APIKey is the api_keys record used to
+// authenticate, if any; ID is then the owner of the key.
+type Actor struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	Email  string `json:"email,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// Change is the value of a field before and after an action.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Entry is an action to record. Organization defaults to the organization
+// of the actor, and Actor to the one authenticated on the request.
+type Entry struct {
+	Organization string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Actor        *Actor
+	Changes      map[string]Change
+	Details      map[string]any
+}
+
+// ActorFromRequest returns the actor authenticated on the request.
+func ActorFromRequest(e *core.RequestEvent) Actor {
+	if e.Auth == nil {
+		return Actor{Type: ActorAnonymous}
+	}
+	actor := Actor{Type: ActorUser, ID: e.Auth.Id, Email: e.Auth.Email()}
+	if e.Auth.IsSuperuser() {
+		actor.Type = ActorSuperuser
+	}
+	if keyID := middlewares.APIKeyID(e); keyID != "" {
+		actor.Type = ActorAPIKey
+		actor.APIKey = keyID
+	}
+	return actor
+}
+
+// Record appends entry to the audit log with the address of the request.
+// Entries that resolve to no organization are not recorded, since the log
+// is kept per organization.
+func Record(e *core.RequestEvent, entry Entry) error {
+	actor := ActorFromRequest(e)
+	if entry.Actor != nil {
+		actor = *entry.Actor
+	}
+
+	orgID := entry.Organization
+	if orgID == "" && actor.ID != "" && actor.Type != ActorSuperuser {
+		orgID, _ = pbutils.GetUserOrganizationID(e.App, actor.ID)
+	}
+	if orgID == "" {
+		return nil
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(Collection)
+	if err != nil {
+		return err
+	}
+	record := core.NewRecord(collection)
+	record.Set("owner", orgID)
+	record.Set("action", entry.Action)
+	record.Set("actor_type", actor.Type)
+	record.Set("actor_id", actor.ID)
+	record.Set("actor_email", actor.Email)
+	record.Set("api_key", actor.APIKey)
+	record.Set("ip", e.RealIP())
+	record.Set("resource_type", entry.ResourceType)
+	record.Set("resource_id", entry.ResourceID)
+	if len(entry.Changes) > 0 {
+		record.Set("changes", entry.Changes)
+	}
+	if len(entry.Details) > 0 {
+		record.Set("details", entry.Details)
+	}
+	if err := e.App.Save(record); err != nil {
+		return fmt.Errorf("failed to record %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// Diff returns the fields that differ between before and after. A nil
+// before records a creation and a nil after a deletion. Hidden fields and
+// the autodates are skipped, secret fields are redacted.
+func Diff(before, after *core.Record) map[string]Change {
+	source := after
+	if source == nil {
+		source = before
+	}
+	if source == nil {
+		return nil
+	}
+
+	changes := map[string]Change{}
+	for _, field := range source.Collection().Fields {
+		name := field.GetName()
+		if field.GetHidden() || field.Type() == core.FieldTypeAutodate ||
+			field.Type() == core.FieldTypePassword || name == "id" {
+			continue
+		}
+		var beforeValue, afterValue any
+		if before != nil {
+			beforeValue = before.Get(name)
+		}
+		if after != nil {
+			afterValue = after.Get(name)
+		}
+		if reflect.DeepEqual(beforeValue, afterValue) ||
+			isEmpty(beforeValue) && isEmpty(afterValue) {
+			continue
+		}
+		if secretFields[name] {
+			beforeValue, afterValue = redactedValue(beforeValue), redactedValue(afterValue)
+		}
+		changes[name] = Change{Before: beforeValue, After: afterValue}
+	}
+	return changes
+}
+
+func redactedValue(value any) any {
+	if isEmpty(value) {
+		return nil
+	}
+	return redacted
+}
+
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
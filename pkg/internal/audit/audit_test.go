@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+)
+
+const auditTestDataDir = "../../../test_pb_data"
+
+func TestDiff(t *testing.T) {
+	collection := core.NewBaseCollection("api_keys")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "key"},
+		&core.BoolField{Name: "revoked"},
+		&core.TextField{Name: "internal", Hidden: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+
+	before := core.NewRecord(collection)
+	before.Id = "key1"
+	before.Set("name", "ci")
+	before.Set("key", "hash-1")
+	before.Set("internal", "a")
+
+	require.Equal(t, map[string]Change{
+		"name": {Before: nil, After: "ci"},
+		"key":  {Before: nil, After: redacted},
+	}, Diff(nil, before))
+
+	after := before.Clone()
+	after.Set("key", "hash-2")
+	after.Set("revoked", true)
+	after.Set("internal", "b")
+	require.Equal(t, map[string]Change{
+		"key":     {Before: redacted, After: redacted},
+		"revoked": {Before: false, After: true},
+	}, Diff(before, after))
+
+	require.Equal(t, map[string]Change{
+		"name":    {Before: "ci", After: nil},
+		"key":     {Before: redacted, After: nil},
+		"revoked": {Before: true, After: nil},
+	}, Diff(after, nil))
+
+	require.Nil(t, Diff(nil, nil))
+}
+
+func TestRecordAndFind(t *testing.T) {
+	app, err := tests.NewTestApp(auditTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	other, err := app.FindAuthRecordByEmail("users", "userB@example.org")
+	require.NoError(t, err)
+
+	newEvent := func(auth *core.Record) *core.RequestEvent {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "192.0.2.10:5000"
+		return &core.RequestEvent{
+			App:   app,
+			Auth:  auth,
+			Event: router.Event{Request: req, Response: httptest.NewRecorder()},
+		}
+	}
+
+	require.NoError(t, Record(newEvent(user), Entry{
+		Action:       ActionPipelineUpdated,
+		ResourceType: "pipelines",
+		ResourceID:   "p1",
+		Changes:      map[string]Change{"name": {Before: "a", After: "b"}},
+	}))
+	require.NoError(t, Record(newEvent(user), Entry{
+		Action:       ActionScheduleStarted,
+		ResourceType: "schedules",
+		ResourceID:   "s1",
+		Actor:        &Actor{Type: ActorAPIKey, ID: user.Id, APIKey: "k1"},
+		Details:      map[string]any{"mode": "daily"},
+	}))
+	require.NoError(t, Record(newEvent(other), Entry{
+		Action:       ActionPipelineDeleted,
+		ResourceType: "pipelines",
+		ResourceID:   "p2",
+	}))
+	// Anonymous actions without an organization are not recorded.
+	require.NoError(t, Record(newEvent(nil), Entry{Action: ActionPipelineDeleted}))
+
+	authorization, err := app.FindFirstRecordByData("orgAuthorizations", "user", user.Id)
+	require.NoError(t, err)
+	orgID := authorization.GetString("organization")
+
+	logs, total, err := Find(app, orgID, Filter{}, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Len(t, logs, 2)
+
+	logs, total, err = Find(app, orgID, Filter{Action: "pipeline."}, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	log := logs[0]
+	require.Equal(t, ActionPipelineUpdated, log.Action)
+	require.Equal(t, orgID, log.Organization)
+	require.Equal(t, Actor{Type: ActorUser, ID: user.Id, Email: user.Email()}, log.Actor)
+	require.Equal(t, "192.0.2.10", log.IP)
+	require.Equal(t, map[string]Change{"name": {Before: "a", After: "b"}}, log.Changes)
+
+	logs, _, err = Find(app, orgID, Filter{ActorID: "k1"}, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, ActionScheduleStarted, logs[0].Action)
+	require.Equal(t, map[string]any{"mode": "daily"}, logs[0].Details)
+
+	_, total, err = Find(app, orgID, Filter{ResourceType: "schedules", ResourceID: "s1"}, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	_, total, err = Find(app, orgID, Filter{Since: time.Now().Add(time.Hour)}, 0, 0)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	_, total, err = Find(app, orgID, Filter{Until: time.Now().Add(time.Hour)}, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var body bytes.Buffer
+	require.NoError(t, WriteCSV(&body, []Log{{
+		Action:       ActionAPIKeyRevoked,
+		Actor:        Actor{Type: ActorUser, ID: "u1", Email: "u@example.org"},
+		IP:           "192.0.2.10",
+		ResourceType: "api_keys",
+		ResourceID:   "k1",
+		Changes:      map[string]Change{"revoked": {Before: false, After: true}},
+		Created:      "2026-03-01T10:00:00Z",
+	}}))
+
+	rows, err := csv.NewReader(&body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{csvHeader, {
+		"2026-03-01T10:00:00Z",
+		ActionAPIKeyRevoked,
+		ActorUser,
+		"u1",
+		"u@example.org",
+		"",
+		"192.0.2.10",
+		"api_keys",
+		"k1",
+		`{"revoked":{"before":false,"after":true}}`,
+		"",
+	}}, rows)
+}
@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Filter selects audit entries of an organization. Empty fields match
+// everything; an Action ending with "." matches every action with that
+// prefix, e.g. "pipeline.".
+type Filter struct {
+	Action       string
+	ActorID      string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+}
+
+// Log is an audit entry as returned by the API.
+type Log struct {
+	ID           string            `json:"id"`
+	Organization string            `json:"organization"`
+	Action       string            `json:"action"`
+	Actor        Actor             `json:"actor"`
+	IP           string            `json:"ip"`
+	ResourceType string            `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	Changes      map[string]Change `json:"changes,omitempty"`
+	Details      map[string]any    `json:"details,omitempty"`
+	Created      string            `json:"created"`
+}
+
+// Find returns the entries of the organization matching filter, newest
+// first, with the total number of matches.
+func Find(app core.App, orgID string, filter Filter, limit, offset int) ([]Log, int, error) {
+	expression := filter.expression(orgID)
+
+	var total int
+	err := app.RecordQuery(Collection).
+		Select("count(*)").
+		AndWhere(expression).
+		Row(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := []*core.Record{}
+	query := app.RecordQuery(Collection).
+		AndWhere(expression).
+		OrderBy("created DESC", "id DESC").
+		Offset(int64(offset))
+	if limit > 0 {
+		query = query.Limit(int64(limit))
+	}
+	if err := query.All(&records); err != nil {
+		return nil, 0, err
+	}
+
+	logs := make([]Log, 0, len(records))
+	for _, record := range records {
+		logs = append(logs, FromRecord(record))
+	}
+	return logs, total, nil
+}
+
+func (f Filter) expression(orgID string) dbx.Expression {
+	conditions := []dbx.Expression{dbx.HashExp{"owner": orgID}}
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			conditions = append(conditions, dbx.Like("action", f.Action).Match(false, true))
+		} else {
+			conditions = append(conditions, dbx.HashExp{"action": f.Action})
+		}
+	}
+	if f.ActorID != "" {
+		conditions = append(conditions, dbx.Or(
+			dbx.HashExp{"actor_id": f.ActorID},
+			dbx.HashExp{"api_key": f.ActorID},
+		))
+	}
+	if f.ResourceType != "" {
+		conditions = append(conditions, dbx.HashExp{"resource_type": f.ResourceType})
+	}
+	if f.ResourceID != "" {
+		conditions = append(conditions, dbx.HashExp{"resource_id": f.ResourceID})
+	}
+	if !f.Since.IsZero() {
+		since, _ := types.ParseDateTime(f.Since)
+		conditions = append(
+			conditions,
+			dbx.NewExp("created >= {:since}", dbx.Params{"since": since.String()}),
+		)
+	}
+	if !f.Until.IsZero() {
+		until, _ := types.ParseDateTime(f.Until)
+		conditions = append(
+			conditions,
+			dbx.NewExp("created < {:until}", dbx.Params{"until": until.String()}),
+		)
+	}
+	return dbx.And(conditions...)
+}
+
+// FromRecord converts an audit_logs record.
+func FromRecord(record *core.Record) Log {
+	log := Log{
+		ID:           record.Id,
+		Organization: record.GetString("owner"),
+		Action:       record.GetString("action"),
+		Actor: Actor{
+			Type:   record.GetString("actor_type"),
+			ID:     record.GetString("actor_id"),
+			Email:  record.GetString("actor_email"),
+			APIKey: record.GetString("api_key"),
+		},
+		IP:           record.GetString("ip"),
+		ResourceType: record.GetString("resource_type"),
+		ResourceID:   record.GetString("resource_id"),
+		Created:      record.GetDateTime("created").Time().UTC().Format(time.RFC3339),
+	}
+	_ = record.UnmarshalJSONField("changes", &log.Changes)
+	_ = record.UnmarshalJSONField("details", &log.Details)
+	return log
+}
+
+var csvHeader = []string{
+	"created",
+	"action",
+	"actor_type",
+	"actor_id",
+	"actor_email",
+	"api_key",
+	"ip",
+	"resource_type",
+	"resource_id",
+	"changes",
+	"details",
+}
+
+// WriteCSV writes logs as CSV, with changes and details as JSON cells.
+func WriteCSV(w io.Writer, logs []Log) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, log := range logs {
+		changes, err := jsonCell(log.Changes)
+		if err != nil {
+			return err
+		}
+		details, err := jsonCell(log.Details)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write([]string{
+			log.Created,
+			log.Action,
+			log.Actor.Type,
+			log.Actor.ID,
+			log.Actor.Email,
+			log.Actor.APIKey,
+			log.IP,
+			log.ResourceType,
+			log.ResourceID,
+			changes,
+			details,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func jsonCell[T any](value map[string]T) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
@@ -170,45 +170,63 @@ func Resolve(app core.App, path string) (*core.Record, error) {
 			continue
 		}
 
-		chain, err := getPathChain(collection)
-		if err != nil {
+		rec, err := resolveSegments(app, collection, segments)
+		if errors.Is(err, sql.ErrNoRows) {
 			continue
 		}
+		if err != nil {
+			return nil, err
+		}
+		return rec, nil
+	}
 
-		var rec *core.Record
-		var parentID string
-		match := true
+	return nil, sql.ErrNoRows
+}
 
-		for i, col := range chain {
-			tpl := CanonifyPaths[col]
+// ResolveIn resolves path to a record of collection only, for callers that
+// know what a path points to: paths of different collections may be equal.
+func ResolveIn(app core.App, collection, path string) (*core.Record, error) {
+	tpl, ok := CanonifyPaths[collection]
+	if !ok {
+		return nil, fmt.Errorf("no path template for collection %s", collection)
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if tpl.PathLength != len(segments) {
+		return nil, sql.ErrNoRows
+	}
+	return resolveSegments(app, collection, segments)
+}
 
-			filter := fmt.Sprintf("%s = {:value}", tpl.CanonifiedField)
-			params := map[string]any{"value": segments[i]}
+// resolveSegments walks the parents of collection down to the record named
+// by segments, or returns sql.ErrNoRows.
+func resolveSegments(app core.App, collection string, segments []string) (*core.Record, error) {
+	chain, err := getPathChain(collection)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
 
-			if i > 0 {
-				filter += fmt.Sprintf(" && %s = {:parentID}", tpl.Parent.Field)
-				params["parentID"] = parentID
-			}
+	var rec *core.Record
+	var parentID string
+	for i, col := range chain {
+		tpl := CanonifyPaths[col]
 
-			r, err := app.FindFirstRecordByFilter(col, filter, params)
-			if err != nil {
-				if !errors.Is(err, sql.ErrNoRows) {
-					return nil, err
-				}
-				match = false
-				break
-			}
+		filter := fmt.Sprintf("%s = {:value}", tpl.CanonifiedField)
+		params := map[string]any{"value": segments[i]}
 
-			rec = r
-			parentID = rec.Id
+		if i > 0 {
+			filter += fmt.Sprintf(" && %s = {:parentID}", tpl.Parent.Field)
+			params["parentID"] = parentID
 		}
 
-		if match {
-			return rec, nil
+		r, err := app.FindFirstRecordByFilter(col, filter, params)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	return nil, sql.ErrNoRows
+		rec = r
+		parentID = rec.Id
+	}
+	return rec, nil
 }
 
 func Validate(app core.App, path string) (*core.Record, error) {
@@ -4,6 +4,7 @@
 package canonify
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/core"
@@ -325,6 +326,42 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestResolveIn(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err, "failed to create test app")
+	defer app.Cleanup()
+
+	createRecordAndSave(t, app, RecordSetup{
+		Collection: "organizations",
+		Fields: map[string]any{
+			"id":              "orgdid123456789",
+			"name":            "OrgD",
+			"canonified_name": "orgd",
+		},
+	})
+	createRecordAndSave(t, app, RecordSetup{
+		Collection: "credential_issuers",
+		Fields: map[string]any{
+			"id":              "issuerzid123456",
+			"name":            "Shared",
+			"canonified_name": "shared",
+			"url":             "https://shared.example",
+			"owner":           "orgdid123456789",
+		},
+	})
+
+	got, err := ResolveIn(app, "credential_issuers", "/orgd/shared")
+	require.NoError(t, err)
+	require.Equal(t, "issuerzid123456", got.Id)
+
+	_, err = ResolveIn(app, "wallets", "orgd/shared")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = ResolveIn(app, "credential_issuers", "orgd/shared/extra")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = ResolveIn(app, "unknown", "orgd/shared")
+	require.Error(t, err)
+}
+
 func TestNormalizePath(t *testing.T) {
 	require.Equal(t, "tenant/pipeline", NormalizePath(" /tenant/pipeline"))
 	require.Equal(t, "tenant/pipeline", NormalizePath("tenant/pipeline"))
@@ -0,0 +1,438 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package cbor implements the subset of CBOR (RFC 8949) needed by COSE,
+// CWT and ISO mdoc structures.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Tag is a tagged CBOR data item.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// Tag numbers used by COSE and ISO mdoc structures.
+const (
+	TagDateTime    = 0
+	TagEncodedCBOR = 24
+	TagFullDate    = 1004
+)
+
+const maxDepth = 64
+
+var errTruncated = errors.New("cbor: unexpected end of data")
+
+// Decode decodes a single data item spanning the whole of data. Integers
+// decode to int64, byte strings to []byte, text to string, arrays to []any and
+// maps to map[any]any.
+func Decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("cbor: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return value, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	if d.pos >= len(d.data) {
+		return nil, errTruncated
+	}
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+	if info == 31 {
+		return d.decodeIndefinite(major, depth)
+	}
+	argument, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+		return int64(argument), nil
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+		return -1 - int64(argument), nil
+	case 2, 3:
+		raw, err := d.read(argument)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case 4:
+		if argument > uint64(len(d.data)-d.pos) {
+			return nil, errTruncated
+		}
+		items := make([]any, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if argument > uint64(len(d.data)-d.pos) {
+			return nil, errTruncated
+		}
+		items := make(map[any]any, argument)
+		for i := uint64(0); i < argument; i++ {
+			if err := d.decodeEntry(items, depth); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: argument, Content: content}, nil
+	}
+}
+
+func (d *decoder) decodeEntry(items map[any]any, depth int) error {
+	key, err := d.decode(depth + 1)
+	if err != nil {
+		return err
+	}
+	switch key.(type) {
+	case int64, string:
+	default:
+		return fmt.Errorf("cbor: unsupported map key type %T", key)
+	}
+	value, err := d.decode(depth + 1)
+	if err != nil {
+		return err
+	}
+	items[key] = value
+	return nil
+}
+
+func (d *decoder) decodeIndefinite(major byte, depth int) (any, error) {
+	switch major {
+	case 2, 3:
+		var buf bytes.Buffer
+		for !d.atBreak() {
+			chunk, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch c := chunk.(type) {
+			case []byte:
+				if major != 2 {
+					return nil, errors.New("cbor: invalid text string chunk")
+				}
+				buf.Write(c)
+			case string:
+				if major != 3 {
+					return nil, errors.New("cbor: invalid byte string chunk")
+				}
+				buf.WriteString(c)
+			default:
+				return nil, errors.New("cbor: invalid string chunk")
+			}
+		}
+		if major == 3 {
+			return buf.String(), nil
+		}
+		return buf.Bytes(), nil
+	case 4:
+		items := []any{}
+		for !d.atBreak() {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		items := map[any]any{}
+		for !d.atBreak() {
+			if err := d.decodeEntry(items, depth); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("cbor: indefinite length for major type %d", major)
+	}
+}
+
+// atBreak consumes the break stop code ending an indefinite length item.
+func (d *decoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *decoder) decodeSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		raw, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return float64(halfToFloat(binary.BigEndian.Uint16(raw))), nil
+	case 26:
+		raw, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), nil
+	case 27:
+		raw, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	}
+}
+
+func (d *decoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.read(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.read(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.read(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.read(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	default:
+		return 0, fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return raw, nil
+}
+
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exponent := uint32(h>>10) & 0x1f
+	mantissa := uint32(h & 0x3ff)
+	switch exponent {
+	case 0:
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			return -value
+		}
+		return value
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+112)<<23 | mantissa<<13)
+	}
+}
+
+// Encode encodes value with definite lengths and map keys in
+// length-first canonical order, as COSE requires for Sig_structure.
+func Encode(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func write(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case int:
+		writeInt(buf, int64(v))
+	case int64:
+		writeInt(buf, v)
+	case uint64:
+		writeHead(buf, 0, v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			writeInt(buf, int64(v))
+			return nil
+		}
+		buf.WriteByte(0xfb)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case []byte:
+		writeHead(buf, 2, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		writeHead(buf, 3, uint64(len(v)))
+		buf.WriteString(v)
+	case []any:
+		writeHead(buf, 4, uint64(len(v)))
+		for _, item := range v {
+			if err := write(buf, item); err != nil {
+				return err
+			}
+		}
+	case []string:
+		writeHead(buf, 4, uint64(len(v)))
+		for _, item := range v {
+			writeHead(buf, 3, uint64(len(item)))
+			buf.WriteString(item)
+		}
+	case map[string]any:
+		items := make(map[any]any, len(v))
+		for key, item := range v {
+			items[key] = item
+		}
+		return write(buf, items)
+	case map[any]any:
+		type entry struct{ key, value []byte }
+		entries := make([]entry, 0, len(v))
+		for key, item := range v {
+			encodedKey, err := Encode(key)
+			if err != nil {
+				return err
+			}
+			encodedValue, err := Encode(item)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{encodedKey, encodedValue})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if len(entries[i].key) != len(entries[j].key) {
+				return len(entries[i].key) < len(entries[j].key)
+			}
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		writeHead(buf, 5, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			buf.Write(e.value)
+		}
+	case Tag:
+		writeHead(buf, 6, v.Number)
+		return write(buf, v.Content)
+	default:
+		return fmt.Errorf("cbor: cannot encode %T", value)
+	}
+	return nil
+}
+
+func writeInt(buf *bytes.Buffer, v int64) {
+	if v >= 0 {
+		writeHead(buf, 0, uint64(v))
+		return
+	}
+	writeHead(buf, 1, uint64(-1-v))
+}
+
+func writeHead(buf *bytes.Buffer, major byte, argument uint64) {
+	head := major << 5
+	switch {
+	case argument < 24:
+		buf.WriteByte(head | byte(argument))
+	case argument <= math.MaxUint8:
+		buf.Write([]byte{head | 24, byte(argument)})
+	case argument <= math.MaxUint16:
+		buf.WriteByte(head | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(argument))
+	case argument <= math.MaxUint32:
+		buf.WriteByte(head | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(argument))
+	default:
+		buf.WriteByte(head | 27)
+		_ = binary.Write(buf, binary.BigEndian, argument)
+	}
+}
+
+// Int returns an integer item as int64.
+func Int(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// Lookup returns the value of a map entry, accepting integer or text keys.
+func Lookup(m map[any]any, key any) (any, bool) {
+	if k, ok := key.(int); ok {
+		key = int64(k)
+	}
+	value, ok := m[key]
+	return value, ok
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cbor
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	// Examples from RFC 8949, Appendix A.
+	tests := []struct {
+		hex  string
+		want any
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"1903e8", int64(1000)},
+		{"1a000f4240", int64(1000000)},
+		{"20", int64(-1)},
+		{"3903e7", int64(-1000)},
+		{"f90001", 5.960464477539063e-08},
+		{"f93c00", 1.0},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"83010203", []any{int64(1), int64(2), int64(3)}},
+		{"a201020304", map[any]any{int64(1): int64(2), int64(3): int64(4)}},
+		{"a26161016162820203", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"c11a514b67b0", Tag{Number: 1, Content: int64(1363896240)}},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{
+			"9f018202039f0405ffff",
+			[]any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}},
+		},
+		{"bf61610161629f0203ffff", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			require.NoError(t, err)
+			got, err := Decode(data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := map[string]string{
+		"truncated":         "1903",
+		"trailing bytes":    "0000",
+		"truncated array":   "830102",
+		"invalid info":      "1c",
+		"float map key":     "a1f93c0001",
+		"huge length":       "5b7fffffffffffffff",
+		"mixed chunks":      "5f6161ff",
+		"unsupported value": "f8ff",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := hex.DecodeString(input)
+			require.NoError(t, err)
+			_, err = Decode(data)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	value := Tag{Number: 18, Content: []any{
+		[]byte{0xa1, 0x01, 0x26},
+		map[any]any{int64(4): []byte("kid"), int64(-1): "negative", "z": true, "aa": nil},
+		[]byte("payload"),
+		int64(1 << 40),
+	}}
+	encoded, err := Encode(value)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, value, decoded)
+
+	// Map keys are sorted length first, then bytewise.
+	encoded, err = Encode(map[any]any{"aa": int64(1), int64(-1): int64(2), "b": int64(3)})
+	require.NoError(t, err)
+	require.Equal(t, "a3200261620362616101", hex.EncodeToString(encoded))
+}
+
+func TestEncodeJSONValues(t *testing.T) {
+	encoded, err := Encode(map[string]any{
+		"age":    float64(42),
+		"height": 1.5,
+		"names":  []string{"a"},
+	})
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, map[any]any{
+		"age":    int64(42),
+		"height": 1.5,
+		"names":  []any{"a"},
+	}, decoded)
+}
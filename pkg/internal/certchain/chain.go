@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package certchain validates the X.509 certificate chains issuers and
+// verifiers present in x5c headers: trust, validity, key usage, binding to
+// the client identifier and revocation through CRLs.
+package certchain
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+)
+
+// Issue is a problem found in a chain. Path locates it, starting with the
+// index of the certificate in the chain.
+type Issue struct {
+	Field   string   `json:"field"`
+	Path    []string `json:"path"`
+	Message string   `json:"message"`
+}
+
+// Report is the outcome of a chain validation.
+type Report struct {
+	Valid          bool                      `json:"valid"`
+	ClientIDScheme string                    `json:"client_id_scheme,omitempty"`
+	ClientID       string                    `json:"client_id,omitempty"`
+	Chain          []trustedlist.Certificate `json:"chain"`
+	TrustAnchor    *trustedlist.Certificate  `json:"trust_anchor,omitempty"`
+	Revocation     []Revocation              `json:"revocation,omitempty"`
+	Issues         []Issue                   `json:"issues"`
+}
+
+// Options are the expectations on a chain beyond its trust.
+type Options struct {
+	// ClientID is the client identifier the leaf must be bound to, either
+	// prefixed with its scheme or paired with ClientIDScheme.
+	ClientID       string
+	ClientIDScheme string
+	// ExtKeyUsages are the OIDs the leaf must list as extended key usages.
+	ExtKeyUsages []string
+}
+
+// Validator validates chains against Roots. The zero value trusts the
+// system roots and does not check revocation.
+type Validator struct {
+	Roots []*x509.Certificate
+	// SystemRoots adds the system roots to Roots. They are used anyway when
+	// Roots is empty.
+	SystemRoots     bool
+	CheckRevocation bool
+	HTTPClient      *http.Client
+	Now             func() time.Time
+}
+
+// ParseX5C decodes the base64 DER certificates of an x5c header.
+func ParseX5C(values []string) ([]*x509.Certificate, error) {
+	if len(values) == 0 {
+		return nil, errors.New("x5c is empty")
+	}
+	chain := make([]*x509.Certificate, 0, len(values))
+	for i, value := range values {
+		der, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: %w", i, err)
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: %w", i, err)
+		}
+		chain = append(chain, certificate)
+	}
+	return chain, nil
+}
+
+// ParseJWSX5C decodes the x5c header of a compact JWS, such as a signed
+// request object or credential.
+func ParseJWSX5C(jws string) ([]*x509.Certificate, error) {
+	encoded, _, ok := strings.Cut(strings.TrimSpace(jws), ".")
+	if !ok {
+		return nil, errors.New("not a compact JWS")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("JWS header: %w", err)
+	}
+	var header struct {
+		X5C []string `json:"x5c"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("JWS header: %w", err)
+	}
+	if len(header.X5C) == 0 {
+		return nil, errors.New("JWS header has no x5c")
+	}
+	return ParseX5C(header.X5C)
+}
+
+// ParsePEM decodes a PEM bundle, leaf first.
+func ParsePEM(bundle string) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", len(chain), err)
+		}
+		chain = append(chain, certificate)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return chain, nil
+}
+
+// Validate checks chain, leaf first. Every problem is reported as an issue;
+// the chain is valid when there is none.
+func (v *Validator) Validate(
+	ctx context.Context,
+	chain []*x509.Certificate,
+	opts Options,
+) Report {
+	report := Report{Chain: []trustedlist.Certificate{}, Issues: []Issue{}}
+	issue := func(index int, attribute, format string, args ...any) {
+		path := []string{strconv.Itoa(index)}
+		if attribute != "" {
+			path = append(path, attribute)
+		}
+		report.Issues = append(report.Issues, Issue{
+			Field:   strings.Join(path, "."),
+			Path:    path,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	if len(chain) == 0 {
+		issue(0, "", "the chain has no certificates")
+		return report
+	}
+	for _, certificate := range chain {
+		report.Chain = append(report.Chain, trustedlist.NewCertificate(certificate))
+	}
+	now := v.now()
+
+	expired := false
+	for i, certificate := range chain {
+		switch {
+		case now.Before(certificate.NotBefore):
+			issue(i, "validity", "certificate is not valid before %s",
+				certificate.NotBefore.UTC().Format(time.RFC3339))
+			expired = true
+		case now.After(certificate.NotAfter):
+			issue(i, "validity", "certificate expired on %s",
+				certificate.NotAfter.UTC().Format(time.RFC3339))
+			expired = true
+		}
+		if i+1 < len(chain) {
+			parent := chain[i+1]
+			err := parent.CheckSignature(
+				certificate.SignatureAlgorithm,
+				certificate.RawTBSCertificate,
+				certificate.Signature,
+			)
+			if err != nil {
+				issue(i, "issuer", "certificate is not signed by the next one: %v", err)
+			}
+		}
+	}
+	checkKeyUsage(chain, opts.ExtKeyUsages, issue)
+
+	verified, err := v.verify(chain, now)
+	switch {
+	case err != nil && !(expired && isExpired(err)):
+		issue(0, "trust", "chain does not lead to a trust anchor: %v", err)
+	case err == nil:
+		anchor := trustedlist.NewCertificate(verified[len(verified)-1])
+		report.TrustAnchor = &anchor
+		if v.CheckRevocation {
+			report.Revocation = v.checkRevocation(ctx, verified, now, issue)
+		}
+	}
+
+	if opts.ClientID != "" {
+		scheme, clientID := SplitClientID(opts.ClientID, opts.ClientIDScheme)
+		report.ClientIDScheme, report.ClientID = scheme, clientID
+		if err := CheckClientID(chain[0], scheme, clientID); err != nil {
+			issue(0, "subjectAltName", "%v", err)
+		}
+	}
+
+	report.Valid = len(report.Issues) == 0
+	return report
+}
+
+// verify builds a chain from the leaf to a root. A leaf that is itself a
+// root, as listed in trusted lists, is accepted as is.
+func (v *Validator) verify(chain []*x509.Certificate, now time.Time) ([]*x509.Certificate, error) {
+	var roots *x509.CertPool
+	if len(v.Roots) == 0 || v.SystemRoots {
+		var err error
+		if roots, err = x509.SystemCertPool(); err != nil {
+			roots = x509.NewCertPool()
+		}
+	} else {
+		roots = x509.NewCertPool()
+	}
+	for _, root := range v.Roots {
+		if root.Equal(chain[0]) {
+			return []*x509.Certificate{root}, nil
+		}
+		roots.AddCert(root)
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+	chains, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chains[0], nil
+}
+
+func isExpired(err error) bool {
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &invalid) && invalid.Reason == x509.Expired
+}
+
+func (v *Validator) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
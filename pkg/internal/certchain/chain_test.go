@@ -0,0 +1,317 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// newTestCertificate issues a certificate from template, self-signed when
+// parent is nil.
+func newTestCertificate(
+	t testing.TB,
+	template *x509.Certificate,
+	parent *testCertificate,
+) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	if template.SerialNumber == nil {
+		template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	}
+	if template.NotBefore.IsZero() {
+		template.NotBefore = testNow.Add(-24 * time.Hour)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = testNow.Add(365 * 24 * time.Hour)
+	}
+	issuer, signer := template, crypto.Signer(key)
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCertificate{cert: cert, key: key}
+}
+
+func caTemplate(name string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+}
+
+func leafTemplate(dnsNames ...string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Relying Party"},
+		DNSNames:              dnsNames,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+	}
+}
+
+// testPKI is a root, an intermediate CA and a leaf for verifier.example.
+type testPKI struct {
+	root, intermediate, leaf *testCertificate
+}
+
+func newTestPKI(t testing.TB, leaf *x509.Certificate) testPKI {
+	t.Helper()
+	root := newTestCertificate(t, caTemplate("Root CA"), nil)
+	intermediate := newTestCertificate(t, caTemplate("Intermediate CA"), root)
+	if leaf == nil {
+		leaf = leafTemplate("verifier.example")
+	}
+	return testPKI{
+		root:         root,
+		intermediate: intermediate,
+		leaf:         newTestCertificate(t, leaf, intermediate),
+	}
+}
+
+func (p testPKI) chain() []*x509.Certificate {
+	return []*x509.Certificate{p.leaf.cert, p.intermediate.cert}
+}
+
+func (p testPKI) validator() *Validator {
+	return &Validator{
+		Roots: []*x509.Certificate{p.root.cert},
+		Now:   func() time.Time { return testNow },
+	}
+}
+
+func issueMessages(report Report) string {
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Field+": "+issue.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestValidate(t *testing.T) {
+	pki := newTestPKI(t, nil)
+	report := pki.validator().Validate(context.Background(), pki.chain(), Options{
+		ClientID: "x509_san_dns:verifier.example",
+	})
+	require.True(t, report.Valid, issueMessages(report))
+	require.Empty(t, report.Issues)
+	require.Equal(t, SchemeX509SanDNS, report.ClientIDScheme)
+	require.Equal(t, "verifier.example", report.ClientID)
+	require.Len(t, report.Chain, 2)
+	require.Equal(t, "CN=Relying Party", report.Chain[0].Subject)
+	require.Equal(t, "CN=Root CA", report.TrustAnchor.Subject)
+	require.Nil(t, report.Revocation)
+
+	encoded, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"issues":[]`)
+}
+
+func TestValidateTrustedLeaf(t *testing.T) {
+	// Trusted lists often publish the access certificate of the relying
+	// party itself.
+	pki := newTestPKI(t, nil)
+	validator := pki.validator()
+	validator.Roots = []*x509.Certificate{pki.leaf.cert}
+	report := validator.Validate(context.Background(), pki.chain()[:1], Options{})
+	require.True(t, report.Valid, issueMessages(report))
+	require.Equal(t, "CN=Relying Party", report.TrustAnchor.Subject)
+}
+
+func TestValidateIssues(t *testing.T) {
+	tests := []struct {
+		name  string
+		leaf  *x509.Certificate
+		chain func(testPKI) []*x509.Certificate
+		opts  Options
+		roots func(testPKI) []*x509.Certificate
+		want  []string
+	}{
+		{
+			name: "untrusted root",
+			roots: func(testPKI) []*x509.Certificate {
+				other := newTestCertificate(t, caTemplate("Other CA"), nil)
+				return []*x509.Certificate{other.cert}
+			},
+			want: []string{"0.trust: chain does not lead to a trust anchor"},
+		},
+		{
+			name: "expired leaf",
+			leaf: func() *x509.Certificate {
+				leaf := leafTemplate("verifier.example")
+				leaf.NotAfter = testNow.Add(-time.Hour)
+				return leaf
+			}(),
+			want: []string{"0.validity: certificate expired on 2026-03-01T11:00:00Z"},
+		},
+		{
+			name: "not yet valid leaf",
+			leaf: func() *x509.Certificate {
+				leaf := leafTemplate("verifier.example")
+				leaf.NotBefore = testNow.Add(time.Hour)
+				return leaf
+			}(),
+			want: []string{"0.validity: certificate is not valid before"},
+		},
+		{
+			name: "wrong order",
+			chain: func(p testPKI) []*x509.Certificate {
+				return []*x509.Certificate{p.leaf.cert, p.root.cert, p.intermediate.cert}
+			},
+			want: []string{
+				"0.issuer: certificate is not signed by the next one",
+				"1.issuer: certificate is not signed by the next one",
+			},
+		},
+		{
+			name: "leaf without digitalSignature",
+			leaf: func() *x509.Certificate {
+				leaf := leafTemplate("verifier.example")
+				leaf.KeyUsage = x509.KeyUsageKeyEncipherment
+				return leaf
+			}(),
+			want: []string{
+				"0.keyUsage: leaf certificate keyUsage does not include digitalSignature",
+			},
+		},
+		{
+			name: "leaf without keyUsage",
+			leaf: func() *x509.Certificate {
+				leaf := leafTemplate("verifier.example")
+				leaf.KeyUsage = 0
+				return leaf
+			}(),
+			want: []string{"0.keyUsage: leaf certificate has no keyUsage extension"},
+		},
+		{
+			name: "missing extended key usage",
+			opts: Options{ExtKeyUsages: []string{"1.0.18013.5.1.6"}},
+			want: []string{
+				"0.extKeyUsage: leaf certificate extKeyUsage does not include 1.0.18013.5.1.6",
+			},
+		},
+		{
+			name: "DNS name mismatch",
+			opts: Options{ClientID: "other.example", ClientIDScheme: SchemeX509SanDNS},
+			want: []string{
+				`0.subjectAltName: client_id "other.example" is not a dNSName`,
+			},
+		},
+		{
+			name: "hash mismatch",
+			opts: Options{ClientID: "x509_hash:abc"},
+			want: []string{`0.subjectAltName: client_id "abc" does not match the leaf hash`},
+		},
+		{
+			name: "client_id without scheme",
+			opts: Options{ClientID: "verifier.example"},
+			want: []string{"has no x509 client identifier scheme"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pki := newTestPKI(t, tt.leaf)
+			validator := pki.validator()
+			if tt.roots != nil {
+				validator.Roots = tt.roots(pki)
+			}
+			chain := pki.chain()
+			if tt.chain != nil {
+				chain = tt.chain(pki)
+			}
+			report := validator.Validate(context.Background(), chain, tt.opts)
+			require.False(t, report.Valid)
+			require.Len(t, report.Issues, len(tt.want), issueMessages(report))
+			for _, want := range tt.want {
+				require.Contains(t, issueMessages(report), want)
+			}
+		})
+	}
+}
+
+func TestValidateIntermediateNotCA(t *testing.T) {
+	root := newTestCertificate(t, caTemplate("Root CA"), nil)
+	notCA := leafTemplate("intermediate.example")
+	intermediate := newTestCertificate(t, notCA, root)
+	leaf := newTestCertificate(t, leafTemplate("verifier.example"), intermediate)
+	validator := &Validator{
+		Roots: []*x509.Certificate{root.cert},
+		Now:   func() time.Time { return testNow },
+	}
+	report := validator.Validate(
+		context.Background(),
+		[]*x509.Certificate{leaf.cert, intermediate.cert},
+		Options{},
+	)
+	require.False(t, report.Valid)
+	messages := issueMessages(report)
+	require.Contains(t, messages, "1.basicConstraints: certificate issues others but is not a CA")
+	require.Contains(t, messages, "1.keyUsage: CA certificate keyUsage does not include")
+	require.Contains(t, messages, "0.trust:")
+}
+
+func TestParse(t *testing.T) {
+	pki := newTestPKI(t, nil)
+	x5c := []string{
+		base64.StdEncoding.EncodeToString(pki.leaf.cert.Raw),
+		base64.StdEncoding.EncodeToString(pki.intermediate.cert.Raw),
+	}
+	chain, err := ParseX5C(x5c)
+	require.NoError(t, err)
+	require.Equal(t, pki.chain(), chain)
+
+	header, err := json.Marshal(map[string]any{"alg": "ES256", "x5c": x5c})
+	require.NoError(t, err)
+	jws := base64.RawURLEncoding.EncodeToString(header) + ".e30.c2ln"
+	chain, err = ParseJWSX5C(jws)
+	require.NoError(t, err)
+	require.Equal(t, pki.chain(), chain)
+
+	var bundle strings.Builder
+	for _, certificate := range pki.chain() {
+		require.NoError(t, pem.Encode(&bundle, &pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: certificate.Raw,
+		}))
+	}
+	chain, err = ParsePEM(bundle.String())
+	require.NoError(t, err)
+	require.Equal(t, pki.chain(), chain)
+
+	_, err = ParseX5C([]string{x5c[0], "AAAA"})
+	require.ErrorContains(t, err, "x5c[1]")
+	_, err = ParseX5C(nil)
+	require.ErrorContains(t, err, "x5c is empty")
+	_, err = ParseJWSX5C("e30.e30.c2ln")
+	require.ErrorContains(t, err, "no x5c")
+	_, err = ParsePEM("certificate")
+	require.ErrorContains(t, err, "no PEM certificates")
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Client identifier schemes of OpenID for Verifiable Presentations that
+// bind the client to its certificate.
+const (
+	SchemeX509SanDNS = "x509_san_dns"
+	SchemeX509SanURI = "x509_san_uri"
+	SchemeX509Hash   = "x509_hash"
+)
+
+var schemes = []string{SchemeX509SanDNS, SchemeX509SanURI, SchemeX509Hash}
+
+// SplitClientID returns the scheme and the identifier of a client_id,
+// either prefixed as in OpenID4VP 1.0 or paired with the client_id_scheme
+// of earlier drafts.
+func SplitClientID(clientID, scheme string) (string, string) {
+	for _, prefixed := range schemes {
+		if id, ok := strings.CutPrefix(clientID, prefixed+":"); ok {
+			return prefixed, id
+		}
+	}
+	return scheme, clientID
+}
+
+// CheckClientID checks that leaf is bound to clientID under scheme.
+func CheckClientID(leaf *x509.Certificate, scheme, clientID string) error {
+	switch scheme {
+	case SchemeX509SanDNS:
+		for _, name := range leaf.DNSNames {
+			if strings.EqualFold(name, clientID) {
+				return nil
+			}
+		}
+		return fmt.Errorf(
+			"client_id %q is not a dNSName subject alternative name of the leaf, which has %v",
+			clientID,
+			leaf.DNSNames,
+		)
+	case SchemeX509SanURI:
+		for _, uri := range leaf.URIs {
+			if uri.String() == clientID {
+				return nil
+			}
+		}
+		return fmt.Errorf(
+			"client_id %q is not a URI subject alternative name of the leaf",
+			clientID,
+		)
+	case SchemeX509Hash:
+		if hash := LeafHash(leaf); hash != clientID {
+			return fmt.Errorf("client_id %q does not match the leaf hash %q", clientID, hash)
+		}
+		return nil
+	case "":
+		return fmt.Errorf("client_id %q has no x509 client identifier scheme", clientID)
+	default:
+		return fmt.Errorf("unsupported client identifier scheme %q", scheme)
+	}
+}
+
+// LeafHash is the x509_hash client identifier of leaf: the base64url
+// encoded SHA-256 hash of its DER encoding.
+func LeafHash(leaf *x509.Certificate) string {
+	hash := sha256.Sum256(leaf.Raw)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitClientID(t *testing.T) {
+	tests := []struct {
+		clientID, scheme string
+		wantScheme       string
+		wantID           string
+	}{
+		{"x509_san_dns:verifier.example", "", SchemeX509SanDNS, "verifier.example"},
+		{"x509_hash:abc", "", SchemeX509Hash, "abc"},
+		{"verifier.example", SchemeX509SanDNS, SchemeX509SanDNS, "verifier.example"},
+		{"https://rp.example/cb", SchemeX509SanURI, SchemeX509SanURI, "https://rp.example/cb"},
+		{"redirect_uri:https://verifier.example", "", "", "redirect_uri:https://verifier.example"},
+	}
+	for _, tt := range tests {
+		scheme, id := SplitClientID(tt.clientID, tt.scheme)
+		require.Equal(t, tt.wantScheme, scheme, tt.clientID)
+		require.Equal(t, tt.wantID, id, tt.clientID)
+	}
+}
+
+func TestCheckClientID(t *testing.T) {
+	template := leafTemplate("verifier.example", "www.verifier.example")
+	callback, err := url.Parse("https://verifier.example/cb")
+	require.NoError(t, err)
+	template.URIs = []*url.URL{callback}
+	leaf := newTestCertificate(t, template, nil).cert
+
+	hash := sha256.Sum256(leaf.Raw)
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(hash[:]), LeafHash(leaf))
+
+	require.NoError(t, CheckClientID(leaf, SchemeX509SanDNS, "WWW.verifier.example"))
+	require.NoError(t, CheckClientID(leaf, SchemeX509SanURI, "https://verifier.example/cb"))
+	require.NoError(t, CheckClientID(leaf, SchemeX509Hash, LeafHash(leaf)))
+
+	require.ErrorContains(
+		t,
+		CheckClientID(leaf, SchemeX509SanDNS, "other.example"),
+		"[verifier.example www.verifier.example]",
+	)
+	require.ErrorContains(
+		t,
+		CheckClientID(leaf, SchemeX509SanURI, "https://verifier.example"),
+		"not a URI subject alternative name",
+	)
+	require.ErrorContains(
+		t,
+		CheckClientID(leaf, "verifier_attestation", "verifier.example"),
+		`unsupported client identifier scheme "verifier_attestation"`,
+	)
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Revocation statuses.
+const (
+	StatusGood    = "good"
+	StatusRevoked = "revoked"
+	StatusUnknown = "unknown"
+)
+
+const maxCRLSize = 10 << 20
+
+// Revocation is the CRL status of a certificate of the chain.
+type Revocation struct {
+	Index     int        `json:"index"`
+	Status    string     `json:"status"`
+	CRL       string     `json:"crl,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// checkRevocation looks up every certificate of the verified chain but the
+// trust anchor in the CRLs it points to. Certificates without CRL
+// distribution points are not reported.
+func (v *Validator) checkRevocation(
+	ctx context.Context,
+	verified []*x509.Certificate,
+	now time.Time,
+	issue func(index int, attribute, format string, args ...any),
+) []Revocation {
+	var statuses []Revocation
+	for i := 0; i+1 < len(verified); i++ {
+		certificate, issuer := verified[i], verified[i+1]
+		var points []string
+		for _, point := range certificate.CRLDistributionPoints {
+			if strings.HasPrefix(point, "http://") || strings.HasPrefix(point, "https://") {
+				points = append(points, point)
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+
+		status := Revocation{Index: i, Status: StatusUnknown}
+		var errs []error
+		for _, point := range points {
+			status.CRL = point
+			revokedAt, err := v.lookupCRL(ctx, point, certificate, issuer, now)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if revokedAt != nil {
+				status.Status, status.RevokedAt = StatusRevoked, revokedAt
+			} else {
+				status.Status = StatusGood
+			}
+			break
+		}
+		switch status.Status {
+		case StatusRevoked:
+			issue(i, "revocation", "certificate was revoked on %s according to %s",
+				status.RevokedAt.UTC().Format(time.RFC3339), status.CRL)
+		case StatusUnknown:
+			status.Error = errors.Join(errs...).Error()
+			issue(i, "revocation", "revocation status cannot be determined: %s", status.Error)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// lookupCRL downloads and verifies the CRL at url and returns when
+// certificate was revoked, or nil.
+func (v *Validator) lookupCRL(
+	ctx context.Context,
+	url string,
+	certificate, issuer *x509.Certificate,
+	now time.Time,
+) (*time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch CRL %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch CRL %s: unexpected status %d", url, resp.StatusCode)
+	}
+	der, err := io.ReadAll(io.LimitReader(resp.Body, maxCRLSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch CRL %s: %w", url, err)
+	}
+	if len(der) > maxCRLSize {
+		return nil, fmt.Errorf("CRL %s exceeds %d bytes", url, maxCRLSize)
+	}
+
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse CRL %s: %w", url, err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("CRL %s is not signed by the certificate issuer: %w", url, err)
+	}
+	if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+		return nil, fmt.Errorf(
+			"CRL %s is stale, next update was due on %s",
+			url,
+			crl.NextUpdate.UTC().Format(time.RFC3339),
+		)
+	}
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(certificate.SerialNumber) == 0 {
+			revokedAt := entry.RevocationTime
+			return &revokedAt, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// crlServer serves CRLs by path.
+type crlServer struct {
+	*httptest.Server
+	crls map[string][]byte
+}
+
+func newCRLServer(t testing.TB) *crlServer {
+	t.Helper()
+	s := &crlServer{crls: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crl, ok := s.crls[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pkix-crl")
+		_, _ = w.Write(crl)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *crlServer) publish(
+	t testing.TB,
+	path string,
+	issuer *testCertificate,
+	nextUpdate time.Time,
+	revoked ...*big.Int,
+) {
+	t.Helper()
+	list := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: testNow.Add(-time.Hour),
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range revoked {
+		list.RevokedCertificateEntries = append(
+			list.RevokedCertificateEntries,
+			x509.RevocationListEntry{
+				SerialNumber:   serial,
+				RevocationTime: testNow.Add(-30 * time.Minute),
+			},
+		)
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, list, issuer.cert, issuer.key)
+	require.NoError(t, err)
+	s.crls[path] = crl
+}
+
+func TestValidateRevocation(t *testing.T) {
+	server := newCRLServer(t)
+	leafTemplate := leafTemplate("verifier.example")
+	leafTemplate.CRLDistributionPoints = []string{server.URL + "/intermediate.crl"}
+	pki := newTestPKI(t, leafTemplate)
+	validator := pki.validator()
+	validator.CheckRevocation = true
+	validator.HTTPClient = server.Client()
+	nextUpdate := testNow.Add(time.Hour)
+
+	t.Run("good", func(t *testing.T) {
+		server.publish(t, "/intermediate.crl", pki.intermediate, nextUpdate, big.NewInt(1))
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.True(t, report.Valid, issueMessages(report))
+		require.Equal(t, []Revocation{{
+			Index:  0,
+			Status: StatusGood,
+			CRL:    server.URL + "/intermediate.crl",
+		}}, report.Revocation)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		server.publish(
+			t,
+			"/intermediate.crl",
+			pki.intermediate,
+			nextUpdate,
+			pki.leaf.cert.SerialNumber,
+		)
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.False(t, report.Valid)
+		require.Equal(t, StatusRevoked, report.Revocation[0].Status)
+		require.Contains(
+			t,
+			issueMessages(report),
+			"0.revocation: certificate was revoked on 2026-03-01T11:30:00Z",
+		)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		server.publish(t, "/intermediate.crl", pki.intermediate, testNow.Add(-time.Minute))
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.False(t, report.Valid)
+		require.Equal(t, StatusUnknown, report.Revocation[0].Status)
+		require.Contains(t, report.Revocation[0].Error, "is stale")
+	})
+
+	t.Run("signed by another CA", func(t *testing.T) {
+		server.publish(t, "/intermediate.crl", pki.root, nextUpdate)
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.False(t, report.Valid)
+		require.Contains(t, issueMessages(report), "is not signed by the certificate issuer")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		delete(server.crls, "/intermediate.crl")
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.False(t, report.Valid)
+		require.Contains(t, issueMessages(report), "unexpected status 404")
+	})
+
+	t.Run("not checked", func(t *testing.T) {
+		validator := pki.validator()
+		report := validator.Validate(context.Background(), pki.chain(), Options{})
+		require.True(t, report.Valid, issueMessages(report))
+		require.Nil(t, report.Revocation)
+	})
+}
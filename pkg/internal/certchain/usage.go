@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package certchain
+
+import (
+	"crypto/x509"
+	"slices"
+)
+
+// extKeyUsageOIDs maps the extended key usages the x509 package knows to
+// their OIDs; the others are kept in UnknownExtKeyUsage.
+var extKeyUsageOIDs = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageServerAuth:      "1.3.6.1.5.5.7.3.1",
+	x509.ExtKeyUsageClientAuth:      "1.3.6.1.5.5.7.3.2",
+	x509.ExtKeyUsageCodeSigning:     "1.3.6.1.5.5.7.3.3",
+	x509.ExtKeyUsageEmailProtection: "1.3.6.1.5.5.7.3.4",
+	x509.ExtKeyUsageTimeStamping:    "1.3.6.1.5.5.7.3.8",
+	x509.ExtKeyUsageOCSPSigning:     "1.3.6.1.5.5.7.3.9",
+}
+
+// checkKeyUsage requires a leaf that signs, not a CA, and CA certificates
+// above it.
+func checkKeyUsage(
+	chain []*x509.Certificate,
+	required []string,
+	issue func(index int, attribute, format string, args ...any),
+) {
+	leaf := chain[0]
+	if leaf.BasicConstraintsValid && leaf.IsCA {
+		issue(0, "basicConstraints", "leaf certificate is a CA certificate")
+	}
+	switch {
+	case leaf.KeyUsage == 0:
+		issue(0, "keyUsage", "leaf certificate has no keyUsage extension")
+	case leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0:
+		issue(0, "keyUsage", "leaf certificate keyUsage does not include digitalSignature")
+	}
+	oids := leafExtKeyUsageOIDs(leaf)
+	for _, oid := range required {
+		if !slices.Contains(oids, oid) {
+			issue(0, "extKeyUsage", "leaf certificate extKeyUsage does not include %s", oid)
+		}
+	}
+
+	for i, certificate := range chain[1:] {
+		if !certificate.BasicConstraintsValid || !certificate.IsCA {
+			issue(i+1, "basicConstraints", "certificate issues others but is not a CA")
+		}
+		if certificate.KeyUsage != 0 && certificate.KeyUsage&x509.KeyUsageCertSign == 0 {
+			issue(i+1, "keyUsage", "CA certificate keyUsage does not include keyCertSign")
+		}
+	}
+}
+
+func leafExtKeyUsageOIDs(leaf *x509.Certificate) []string {
+	var oids []string
+	for _, usage := range leaf.ExtKeyUsage {
+		if oid, ok := extKeyUsageOIDs[usage]; ok {
+			oids = append(oids, oid)
+		}
+	}
+	for _, oid := range leaf.UnknownExtKeyUsage {
+		oids = append(oids, oid.String())
+	}
+	return oids
+}
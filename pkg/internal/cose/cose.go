@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package cose implements COSE_Sign1 (RFC 9052) signing and verification
+// together with the JOSE style raw signatures shared with JWS.
+package cose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+)
+
+// COSE header labels (RFC 9052, RFC 9360).
+const (
+	HeaderAlg     = 1
+	HeaderKid     = 4
+	HeaderTyp     = 16
+	HeaderX5Chain = 33
+)
+
+const (
+	TagSign1 = 18
+	TagCWT   = 61
+)
+
+// Algorithms maps COSE algorithm identifiers to their JOSE names.
+var Algorithms = map[int64]string{
+	-7:   "ES256",
+	-35:  "ES384",
+	-36:  "ES512",
+	-8:   "EdDSA",
+	-37:  "PS256",
+	-38:  "PS384",
+	-39:  "PS512",
+	-257: "RS256",
+	-258: "RS384",
+	-259: "RS512",
+}
+
+// Sign1 is a decoded COSE_Sign1 structure.
+type Sign1 struct {
+	ProtectedRaw []byte
+	Protected    map[any]any
+	Unprotected  map[any]any
+	Payload      []byte
+	Signature    []byte
+}
+
+// Parse decodes a COSE_Sign1, optionally tagged as CWT and/or
+// COSE_Sign1.
+func Parse(data []byte) (*Sign1, error) {
+	item, err := cbor.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return FromItem(item)
+}
+
+// FromItem converts a decoded CBOR item to a COSE_Sign1.
+func FromItem(item any) (*Sign1, error) {
+	for {
+		tag, ok := item.(cbor.Tag)
+		if !ok {
+			break
+		}
+		if tag.Number != TagSign1 && tag.Number != TagCWT {
+			return nil, fmt.Errorf("unexpected CBOR tag %d", tag.Number)
+		}
+		item = tag.Content
+	}
+	parts, ok := item.([]any)
+	if !ok || len(parts) != 4 {
+		return nil, errors.New("COSE_Sign1 must be an array of four items")
+	}
+	protectedRaw, ok := parts[0].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 protected header must be a byte string")
+	}
+	protected := map[any]any{}
+	if len(protectedRaw) > 0 {
+		decoded, err := cbor.Decode(protectedRaw)
+		if err != nil {
+			return nil, fmt.Errorf("protected header: %w", err)
+		}
+		if protected, ok = decoded.(map[any]any); !ok {
+			return nil, errors.New("COSE_Sign1 protected header must be a map")
+		}
+	}
+	unprotected, ok := parts[1].(map[any]any)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 unprotected header must be a map")
+	}
+	payload, ok := parts[2].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 has a detached or invalid payload")
+	}
+	signature, ok := parts[3].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 signature must be a byte string")
+	}
+	return &Sign1{
+		ProtectedRaw: protectedRaw,
+		Protected:    protected,
+		Unprotected:  unprotected,
+		Payload:      payload,
+		Signature:    signature,
+	}, nil
+}
+
+// Header returns a header parameter, preferring the protected bucket.
+func (s *Sign1) Header(label int) (any, bool) {
+	if value, ok := cbor.Lookup(s.Protected, label); ok {
+		return value, true
+	}
+	return cbor.Lookup(s.Unprotected, label)
+}
+
+// Algorithm returns the JOSE name of the protected alg header.
+func (s *Sign1) Algorithm() (string, error) {
+	value, ok := cbor.Lookup(s.Protected, HeaderAlg)
+	if !ok {
+		return "", errors.New("COSE_Sign1 has no protected alg header")
+	}
+	id, ok := cbor.Int(value)
+	if !ok {
+		return "", fmt.Errorf("unsupported COSE algorithm %v", value)
+	}
+	name, ok := Algorithms[id]
+	if !ok {
+		return "", fmt.Errorf("unsupported COSE algorithm %d", id)
+	}
+	return name, nil
+}
+
+// Kid returns the key identifier header.
+func (s *Sign1) Kid() string {
+	value, _ := s.Header(HeaderKid)
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// Typ returns the content type header.
+func (s *Sign1) Typ() string {
+	value, _ := s.Header(HeaderTyp)
+	typ, _ := value.(string)
+	return typ
+}
+
+// X5Chain returns the certificates of the x5chain header, leaf first.
+func (s *Sign1) X5Chain() ([]*x509.Certificate, error) {
+	value, ok := s.Header(HeaderX5Chain)
+	if !ok {
+		return nil, nil
+	}
+	var ders [][]byte
+	switch v := value.(type) {
+	case []byte:
+		ders = [][]byte{v}
+	case []any:
+		for _, item := range v {
+			der, ok := item.([]byte)
+			if !ok {
+				return nil, errors.New("x5chain entries must be byte strings")
+			}
+			ders = append(ders, der)
+		}
+	default:
+		return nil, errors.New("invalid x5chain header")
+	}
+	certificates := make([]*x509.Certificate, 0, len(ders))
+	for _, der := range ders {
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5chain: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
+
+// Verify checks the signature over the Sig_structure with key.
+func (s *Sign1) Verify(key crypto.PublicKey) error {
+	algorithm, err := s.Algorithm()
+	if err != nil {
+		return err
+	}
+	toBeSigned, err := cbor.Encode([]any{"Signature1", s.ProtectedRaw, []byte{}, s.Payload})
+	if err != nil {
+		return err
+	}
+	return VerifySignature(algorithm, key, toBeSigned, s.Signature)
+}
+
+// VerifySignature checks a JOSE/COSE style signature, where ECDSA
+// signatures are the fixed size concatenation of r and s.
+func VerifySignature(algorithm string, key crypto.PublicKey, data, signature []byte) error {
+	var hash crypto.Hash
+	switch algorithm {
+	case "ES256", "PS256", "RS256":
+		hash = crypto.SHA256
+	case "ES384", "PS384", "RS384":
+		hash = crypto.SHA384
+	case "ES512", "PS512", "RS512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an Ed25519 key, got %T", algorithm, key)
+		}
+		if !ed25519.Verify(pub, data, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write(data)
+	digest := hasher.Sum(nil)
+
+	switch algorithm[:2] {
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an EC key, got %T", algorithm, key)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an RSA key, got %T", algorithm, key)
+		}
+		options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+		if err := rsa.VerifyPSS(pub, hash, digest, signature, options); err != nil {
+			return errors.New("invalid signature")
+		}
+	default:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an RSA key, got %T", algorithm, key)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		algorithm string
+		key       crypto.Signer
+	}{
+		{"ES256", ecKey},
+		{"EdDSA", edKey},
+		{"PS256", rsaKey},
+		{"RS256", rsaKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			signed, err := Sign(
+				tt.algorithm,
+				tt.key,
+				map[any]any{int64(HeaderTyp): "application/test"},
+				map[any]any{int64(HeaderKid): []byte("key-1")},
+				[]byte("payload"),
+			)
+			require.NoError(t, err)
+
+			encoded, err := cbor.Encode(cbor.Tag{Number: TagSign1, Content: signed.Array(false)})
+			require.NoError(t, err)
+			parsed, err := Parse(encoded)
+			require.NoError(t, err)
+			require.Equal(t, "application/test", parsed.Typ())
+			require.Equal(t, "key-1", parsed.Kid())
+			algorithm, err := parsed.Algorithm()
+			require.NoError(t, err)
+			require.Equal(t, tt.algorithm, algorithm)
+			require.NoError(t, parsed.Verify(tt.key.Public()))
+
+			parsed.Payload = []byte("tampered")
+			require.Error(t, parsed.Verify(tt.key.Public()))
+		})
+	}
+}
+
+func TestDetachedPayload(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signed, err := Sign("ES256", key, nil, nil, []byte("detached"))
+	require.NoError(t, err)
+
+	encoded, err := cbor.Encode(signed.Array(true))
+	require.NoError(t, err)
+	item, err := cbor.Decode(encoded)
+	require.NoError(t, err)
+
+	_, err = FromItem(item)
+	require.Error(t, err)
+
+	parsed, err := FromDetachedItem(item, []byte("detached"))
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify(key.Public()))
+
+	parsed, err = FromDetachedItem(item, []byte("other"))
+	require.NoError(t, err)
+	require.Error(t, parsed.Verify(key.Public()))
+}
+
+func TestSignDataRejectsMismatchedKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = SignData("EdDSA", key, []byte("data"))
+	require.Error(t, err)
+	_, err = SignData("RS256", key, []byte("data"))
+	require.Error(t, err)
+	_, err = SignData("none", key, []byte("data"))
+	require.Error(t, err)
+
+	signature, err := SignData("ES256", key, []byte("data"))
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	require.NoError(t, VerifySignature("ES256", &key.PublicKey, []byte("data"), signature))
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+)
+
+// AlgorithmID returns the COSE identifier of a JOSE algorithm name.
+func AlgorithmID(name string) (int64, bool) {
+	for id, algorithm := range Algorithms {
+		if algorithm == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Sign creates a COSE_Sign1 over payload. The alg header is added to the
+// protected bucket.
+func Sign(
+	algorithm string,
+	key crypto.Signer,
+	protected map[any]any,
+	unprotected map[any]any,
+	payload []byte,
+) (*Sign1, error) {
+	id, ok := AlgorithmID(algorithm)
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	headers := map[any]any{int64(HeaderAlg): id}
+	for label, value := range protected {
+		headers[label] = value
+	}
+	protectedRaw, err := cbor.Encode(headers)
+	if err != nil {
+		return nil, err
+	}
+	if unprotected == nil {
+		unprotected = map[any]any{}
+	}
+	toBeSigned, err := cbor.Encode([]any{"Signature1", protectedRaw, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := SignData(algorithm, key, toBeSigned)
+	if err != nil {
+		return nil, err
+	}
+	return &Sign1{
+		ProtectedRaw: protectedRaw,
+		Protected:    headers,
+		Unprotected:  unprotected,
+		Payload:      payload,
+		Signature:    signature,
+	}, nil
+}
+
+// Array returns the untagged COSE_Sign1 array. A detached signature
+// encodes its payload as nil.
+func (s *Sign1) Array(detached bool) []any {
+	var payload any = s.Payload
+	if detached {
+		payload = nil
+	}
+	return []any{s.ProtectedRaw, s.Unprotected, payload, s.Signature}
+}
+
+// FromDetachedItem converts a decoded COSE_Sign1 whose payload is carried
+// separately, as in mdoc device signatures.
+func FromDetachedItem(item any, payload []byte) (*Sign1, error) {
+	parts, ok := item.([]any)
+	if !ok || len(parts) != 4 {
+		return nil, errors.New("COSE_Sign1 must be an array of four items")
+	}
+	if parts[2] != nil {
+		return nil, errors.New("COSE_Sign1 payload is not detached")
+	}
+	attached := append([]any{}, parts...)
+	attached[2] = payload
+	return FromItem(attached)
+}
+
+// SignData produces a JOSE/COSE style signature, where ECDSA signatures are
+// the fixed size concatenation of r and s.
+func SignData(algorithm string, key crypto.Signer, data []byte) ([]byte, error) {
+	var hash crypto.Hash
+	switch algorithm {
+	case "ES256", "PS256", "RS256":
+		hash = crypto.SHA256
+	case "ES384", "PS384", "RS384":
+		hash = crypto.SHA384
+	case "ES512", "PS512", "RS512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		if _, ok := key.Public().(ed25519.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an Ed25519 key, got %T", algorithm, key)
+		}
+		return key.Sign(rand.Reader, data, crypto.Hash(0))
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write(data)
+	digest := hasher.Sum(nil)
+
+	switch algorithm[:2] {
+	case "ES":
+		pub, ok := key.Public().(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an EC key, got %T", algorithm, key)
+		}
+		der, err := key.Sign(rand.Reader, digest, hash)
+		if err != nil {
+			return nil, err
+		}
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(der, &sig); err != nil {
+			return nil, err
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		sig.R.FillBytes(signature[:size])
+		sig.S.FillBytes(signature[size:])
+		return signature, nil
+	case "PS":
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an RSA key, got %T", algorithm, key)
+		}
+		return key.Sign(rand.Reader, digest, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       hash,
+		})
+	default:
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an RSA key, got %T", algorithm, key)
+		}
+		return key.Sign(rand.Reader, digest, hash)
+	}
+}
@@ -0,0 +1,435 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DID Core JSON-LD contexts.
+const (
+	ContextDIDv1  = "https://www.w3.org/ns/did/v1"
+	ContextDIDv11 = "https://www.w3.org/ns/did/v1.1"
+)
+
+// Verification relationships of DID Core.
+const (
+	RelationshipAuthentication       = "authentication"
+	RelationshipAssertionMethod      = "assertionMethod"
+	RelationshipKeyAgreement         = "keyAgreement"
+	RelationshipCapabilityInvocation = "capabilityInvocation"
+	RelationshipCapabilityDelegation = "capabilityDelegation"
+)
+
+var relationships = []string{
+	RelationshipAuthentication,
+	RelationshipAssertionMethod,
+	RelationshipKeyAgreement,
+	RelationshipCapabilityInvocation,
+	RelationshipCapabilityDelegation,
+}
+
+// Public key representations of verification methods.
+const (
+	KeyFormatJWK       = "publicKeyJwk"
+	KeyFormatMultibase = "publicKeyMultibase"
+	KeyFormatBase58    = "publicKeyBase58"
+)
+
+var keyFormats = []string{KeyFormatJWK, KeyFormatMultibase, KeyFormatBase58}
+
+// methodTypes lists, for the verification method types known to Credimi, the
+// accepted key representations and, when the type implies one, the key type.
+var methodTypes = map[string]struct {
+	formats []string
+	keyType string
+}{
+	"JsonWebKey2020": {formats: []string{KeyFormatJWK}},
+	"JsonWebKey":     {formats: []string{KeyFormatJWK}},
+	"Multikey":       {formats: []string{KeyFormatMultibase}},
+	"Ed25519VerificationKey2018": {
+		formats: []string{KeyFormatBase58},
+		keyType: KeyTypeEd25519,
+	},
+	"Ed25519VerificationKey2020": {
+		formats: []string{KeyFormatMultibase},
+		keyType: KeyTypeEd25519,
+	},
+	"X25519KeyAgreementKey2019": {
+		formats: []string{KeyFormatBase58},
+		keyType: KeyTypeX25519,
+	},
+	"X25519KeyAgreementKey2020": {
+		formats: []string{KeyFormatMultibase},
+		keyType: KeyTypeX25519,
+	},
+	"EcdsaSecp256k1VerificationKey2019": {
+		formats: []string{KeyFormatJWK},
+		keyType: KeyTypeSecp256k1,
+	},
+}
+
+// Report is the outcome of the validation of a DID document.
+type Report struct {
+	Valid               bool           `json:"valid"`
+	Errors              []string       `json:"errors,omitempty"`
+	Warnings            []string       `json:"warnings,omitempty"`
+	VerificationMethods []MethodReport `json:"verification_methods"`
+}
+
+// MethodReport describes a verification method and the relationships it is
+// used for.
+type MethodReport struct {
+	ID            string   `json:"id"`
+	Type          string   `json:"type"`
+	Controller    string   `json:"controller"`
+	KeyFormat     string   `json:"key_format,omitempty"`
+	KeyType       string   `json:"key_type,omitempty"`
+	Relationships []string `json:"relationships,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// Validate checks that document is a well formed DID Core document for did:
+// its identifier, contexts, controllers, verification methods, the keys they
+// publish, the relationships referring to them and its services.
+func Validate(did string, document map[string]any) Report {
+	v := &validator{did: did, methods: map[string]int{}}
+	v.validate(document)
+	v.report.Valid = len(v.report.Errors) == 0
+	if v.report.VerificationMethods == nil {
+		v.report.VerificationMethods = []MethodReport{}
+	}
+	return v.report
+}
+
+type validator struct {
+	did     string
+	report  Report
+	methods map[string]int
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.report.Errors = append(v.report.Errors, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) warnf(format string, args ...any) {
+	v.report.Warnings = append(v.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) validate(document map[string]any) {
+	switch id, _ := document["id"].(string); {
+	case id == "":
+		v.errorf("document has no id")
+	case id != v.did:
+		v.errorf("document id %q does not match %q", id, v.did)
+	}
+	v.validateContext(document["@context"])
+	if controller, ok := document["controller"]; ok {
+		v.validateControllers(controller)
+	}
+	if aliases, ok := document["alsoKnownAs"]; ok && !isStringArray(aliases) {
+		v.errorf("alsoKnownAs must be an array of strings")
+	}
+
+	if value, ok := document["verificationMethod"]; ok {
+		methods, ok := value.([]any)
+		if !ok {
+			v.errorf("verificationMethod must be an array")
+		}
+		for i, method := range methods {
+			v.validateMethod(method, "", fmt.Sprintf("verificationMethod[%d]", i))
+		}
+	}
+	// Embedded methods are collected first, as references may point to a
+	// method embedded in another relationship.
+	references := map[string][]any{}
+	for _, relationship := range relationships {
+		value, ok := document[relationship]
+		if !ok {
+			continue
+		}
+		entries, ok := value.([]any)
+		if !ok {
+			v.errorf("%s must be an array", relationship)
+			continue
+		}
+		references[relationship] = entries
+		for i, entry := range entries {
+			path := fmt.Sprintf("%s[%d]", relationship, i)
+			switch entry := entry.(type) {
+			case string:
+			case map[string]any:
+				v.validateMethod(entry, relationship, path)
+			default:
+				v.errorf("%s must be a DID URL or a verification method", path)
+			}
+		}
+	}
+	for _, relationship := range relationships {
+		for i, entry := range references[relationship] {
+			if reference, ok := entry.(string); ok {
+				path := fmt.Sprintf("%s[%d]", relationship, i)
+				v.validateReference(reference, relationship, path)
+			}
+		}
+	}
+	if len(v.report.VerificationMethods) == 0 {
+		v.warnf("document has no verification methods")
+	}
+	v.validateRelationshipKeys()
+
+	if value, ok := document["service"]; ok {
+		services, ok := value.([]any)
+		if !ok {
+			v.errorf("service must be an array")
+		}
+		for i, service := range services {
+			v.validateService(service, fmt.Sprintf("service[%d]", i))
+		}
+	}
+}
+
+func (v *validator) validateContext(context any) {
+	var first any = context
+	switch c := context.(type) {
+	case nil:
+		// Plain JSON documents do not need a context.
+		return
+	case []any:
+		if len(c) == 0 {
+			v.errorf("@context is empty")
+			return
+		}
+		first = c[0]
+	}
+	if first != ContextDIDv1 && first != ContextDIDv11 {
+		v.errorf("@context must start with %q", ContextDIDv1)
+	}
+}
+
+func (v *validator) validateControllers(controller any) {
+	controllers := []any{controller}
+	if list, ok := controller.([]any); ok {
+		controllers = list
+	}
+	for _, item := range controllers {
+		if value, ok := item.(string); !ok || !IsDID(value) {
+			v.errorf("controller %v is not a DID", item)
+		}
+	}
+}
+
+func (v *validator) validateReference(reference, relationship, path string) {
+	id := v.absolute(reference)
+	if index, ok := v.methods[id]; ok {
+		method := &v.report.VerificationMethods[index]
+		method.Relationships = append(method.Relationships, relationship)
+		return
+	}
+	if strings.HasPrefix(id, v.did+"#") {
+		v.errorf("%s refers to the unknown verification method %q", path, reference)
+		return
+	}
+	v.warnf("%s refers to the external verification method %q, which is not checked", path, id)
+}
+
+func (v *validator) validateMethod(value any, relationship, path string) {
+	method, ok := value.(map[string]any)
+	if !ok {
+		v.errorf("%s must be an object", path)
+		return
+	}
+	id, _ := method["id"].(string)
+	report := MethodReport{ID: v.absolute(id)}
+	report.Type, _ = method["type"].(string)
+	report.Controller, _ = method["controller"].(string)
+	if relationship != "" {
+		report.Relationships = []string{relationship}
+	}
+
+	var problems []string
+	switch {
+	case id == "":
+		problems = append(problems, "it has no id")
+	case !IsDID(strings.SplitN(report.ID, "#", 2)[0]):
+		problems = append(problems, fmt.Sprintf("id %q is not a DID URL", id))
+	case v.hasMethod(report.ID):
+		problems = append(problems, fmt.Sprintf("id %q is used more than once", report.ID))
+	}
+	if report.Type == "" {
+		problems = append(problems, "it has no type")
+	}
+	if !IsDID(report.Controller) {
+		problems = append(problems, "its controller is not a DID")
+	}
+	if format, keyType, err := v.methodKey(method, report.Type); err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		report.KeyFormat, report.KeyType = format, keyType
+	}
+	report.Error = strings.Join(problems, "; ")
+	for _, problem := range problems {
+		v.errorf("%s: %s", path, problem)
+	}
+
+	if id != "" && !v.hasMethod(report.ID) {
+		v.methods[report.ID] = len(v.report.VerificationMethods)
+	}
+	v.report.VerificationMethods = append(v.report.VerificationMethods, report)
+}
+
+func (v *validator) hasMethod(id string) bool {
+	_, ok := v.methods[id]
+	return ok
+}
+
+// methodKey decodes the public key of a verification method and checks it
+// matches the method type.
+func (v *validator) methodKey(method map[string]any, methodType string) (string, string, error) {
+	var present []string
+	for _, format := range keyFormats {
+		if _, ok := method[format]; ok {
+			present = append(present, format)
+		}
+	}
+	switch len(present) {
+	case 0:
+		return "", "", fmt.Errorf("it has no public key")
+	case 1:
+	default:
+		return "", "", fmt.Errorf("it has several public keys: %s", strings.Join(present, ", "))
+	}
+	format := present[0]
+
+	known, isKnown := methodTypes[methodType]
+	if !isKnown && methodType != "" {
+		v.warnf("verification method type %q is not known, only its key is checked", methodType)
+	}
+	if isKnown && !slices.Contains(known.formats, format) {
+		return "", "", fmt.Errorf(
+			"%s keys must be given as %s",
+			methodType,
+			strings.Join(known.formats, " or "),
+		)
+	}
+
+	var key PublicKey
+	var err error
+	switch format {
+	case KeyFormatJWK:
+		jwk, ok := method[format].(map[string]any)
+		if !ok {
+			return "", "", fmt.Errorf("%s must be an object", format)
+		}
+		key, err = ParseJWK(jwk)
+	case KeyFormatMultibase:
+		encoded, _ := method[format].(string)
+		key, err = DecodeMultikey(encoded)
+	case KeyFormatBase58:
+		encoded, _ := method[format].(string)
+		key, err = decodeBase58Key(encoded, known.keyType)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", format, err)
+	}
+	if known.keyType != "" && key.Type != known.keyType {
+		return "", "", fmt.Errorf(
+			"%s requires a %s key, got %s",
+			methodType,
+			known.keyType,
+			key.Type,
+		)
+	}
+	return format, key.Type, nil
+}
+
+// decodeBase58Key decodes the raw keys of the 2018 and 2019 suites.
+func decodeBase58Key(encoded, keyType string) (PublicKey, error) {
+	raw, err := DecodeBase58(encoded)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	switch keyType {
+	case KeyTypeEd25519:
+		if len(raw) != ed25519.PublicKeySize {
+			return PublicKey{}, fmt.Errorf("invalid Ed25519 key size")
+		}
+		return PublicKey{Type: KeyTypeEd25519, Key: ed25519.PublicKey(raw)}, nil
+	case KeyTypeX25519:
+		key, err := ecdh.X25519().NewPublicKey(raw)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("invalid X25519 key: %w", err)
+		}
+		return PublicKey{Type: KeyTypeX25519, Key: key}, nil
+	default:
+		return PublicKey{}, fmt.Errorf("unsupported base58 key")
+	}
+}
+
+// validateRelationshipKeys checks that signing relationships use signing
+// keys and key agreement uses X25519 or NIST curve keys.
+func (v *validator) validateRelationshipKeys() {
+	for _, method := range v.report.VerificationMethods {
+		for _, relationship := range method.Relationships {
+			switch {
+			case method.KeyType == KeyTypeX25519 && relationship != RelationshipKeyAgreement:
+				v.errorf("%s: X25519 key %q cannot sign", relationship, method.ID)
+			case method.KeyType == KeyTypeEd25519 && relationship == RelationshipKeyAgreement:
+				v.errorf("%s: Ed25519 key %q cannot be used for key agreement",
+					relationship, method.ID)
+			}
+		}
+	}
+}
+
+func (v *validator) validateService(value any, path string) {
+	service, ok := value.(map[string]any)
+	if !ok {
+		v.errorf("%s must be an object", path)
+		return
+	}
+	if id, _ := service["id"].(string); id == "" {
+		v.errorf("%s has no id", path)
+	}
+	switch serviceType := service["type"].(type) {
+	case string:
+	case []any:
+		if !isStringArray(serviceType) || len(serviceType) == 0 {
+			v.errorf("%s type must be a string or an array of strings", path)
+		}
+	default:
+		v.errorf("%s has no type", path)
+	}
+	switch service["serviceEndpoint"].(type) {
+	case string, map[string]any, []any:
+	default:
+		v.errorf("%s has no serviceEndpoint", path)
+	}
+}
+
+// absolute resolves a relative DID URL such as "#key-1" against the DID.
+func (v *validator) absolute(id string) string {
+	if strings.HasPrefix(id, "#") {
+		return v.did + id
+	}
+	return id
+}
+
+func isStringArray(value any) bool {
+	items, ok := value.([]any)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
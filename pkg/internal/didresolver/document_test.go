@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDID = "did:web:issuer.example"
+
+// testDocument returns a document with an Ed25519 Multikey signing key, an
+// X25519 key agreement key embedded in keyAgreement and a P-256 JWK.
+func testDocument(t testing.TB) map[string]any {
+	t.Helper()
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edMultikey, err := EncodeMultikey(edPub)
+	require.NoError(t, err)
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	xMultikey, err := EncodeMultikey(xKey.PublicKey())
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	document := map[string]any{
+		"@context": []any{ContextDIDv1, contextMultikey},
+		"id":       testDID,
+		"verificationMethod": []any{
+			map[string]any{
+				"id":                 "#key-1",
+				"type":               "Multikey",
+				"controller":         testDID,
+				"publicKeyMultibase": edMultikey,
+			},
+			map[string]any{
+				"id":           testDID + "#key-2",
+				"type":         "JsonWebKey2020",
+				"controller":   testDID,
+				"publicKeyJwk": jwkMap(t, &ecKey.PublicKey),
+			},
+		},
+		"authentication":  []any{"#key-1"},
+		"assertionMethod": []any{testDID + "#key-1", testDID + "#key-2"},
+		"keyAgreement": []any{map[string]any{
+			"id":                 testDID + "#key-3",
+			"type":               "X25519KeyAgreementKey2020",
+			"controller":         testDID,
+			"publicKeyMultibase": xMultikey,
+		}},
+		"service": []any{map[string]any{
+			"id":              "#issuer",
+			"type":            "OpenID4VCI",
+			"serviceEndpoint": "https://issuer.example",
+		}},
+	}
+	// Validate sees documents as decoded from JSON.
+	encoded, err := json.Marshal(document)
+	require.NoError(t, err)
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return decoded
+}
+
+func TestValidate(t *testing.T) {
+	report := Validate(testDID, testDocument(t))
+	require.True(t, report.Valid, report.Errors)
+	require.Empty(t, report.Warnings)
+	require.Equal(t, []MethodReport{
+		{
+			ID:         testDID + "#key-1",
+			Type:       "Multikey",
+			Controller: testDID,
+			KeyFormat:  KeyFormatMultibase,
+			KeyType:    KeyTypeEd25519,
+			Relationships: []string{
+				RelationshipAuthentication,
+				RelationshipAssertionMethod,
+			},
+		},
+		{
+			ID:            testDID + "#key-2",
+			Type:          "JsonWebKey2020",
+			Controller:    testDID,
+			KeyFormat:     KeyFormatJWK,
+			KeyType:       KeyTypeP256,
+			Relationships: []string{RelationshipAssertionMethod},
+		},
+		{
+			ID:            testDID + "#key-3",
+			Type:          "X25519KeyAgreementKey2020",
+			Controller:    testDID,
+			KeyFormat:     KeyFormatMultibase,
+			KeyType:       KeyTypeX25519,
+			Relationships: []string{RelationshipKeyAgreement},
+		},
+	}, report.VerificationMethods)
+}
+
+func TestValidateErrors(t *testing.T) {
+	methods := func(document map[string]any) []any {
+		return document["verificationMethod"].([]any)
+	}
+	method := func(document map[string]any, i int) map[string]any {
+		return methods(document)[i].(map[string]any)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(map[string]any)
+		want   string
+	}{
+		{
+			name:   "other id",
+			mutate: func(d map[string]any) { d["id"] = "did:web:other.example" },
+			want:   `document id "did:web:other.example" does not match`,
+		},
+		{
+			name:   "no id",
+			mutate: func(d map[string]any) { delete(d, "id") },
+			want:   "document has no id",
+		},
+		{
+			name:   "wrong context",
+			mutate: func(d map[string]any) { d["@context"] = []any{contextMultikey} },
+			want:   "@context must start with",
+		},
+		{
+			name:   "controller",
+			mutate: func(d map[string]any) { d["controller"] = []any{"https://issuer.example"} },
+			want:   "controller https://issuer.example is not a DID",
+		},
+		{
+			name:   "method without key",
+			mutate: func(d map[string]any) { delete(method(d, 0), "publicKeyMultibase") },
+			want:   "verificationMethod[0]: it has no public key",
+		},
+		{
+			name:   "two keys",
+			mutate: func(d map[string]any) { method(d, 0)["publicKeyBase58"] = "abc" },
+			want:   "it has several public keys",
+		},
+		{
+			name:   "format of the type",
+			mutate: func(d map[string]any) { method(d, 1)["type"] = "Multikey" },
+			want:   "Multikey keys must be given as publicKeyMultibase",
+		},
+		{
+			name:   "key of the type",
+			mutate: func(d map[string]any) { method(d, 0)["type"] = "Ed25519VerificationKey2020" },
+			want:   "",
+		},
+		{
+			name: "key type mismatch",
+			mutate: func(d map[string]any) {
+				keyAgreement := d["keyAgreement"].([]any)[0].(map[string]any)
+				keyAgreement["type"] = "Ed25519VerificationKey2020"
+			},
+			want: "Ed25519VerificationKey2020 requires a Ed25519 key, got X25519",
+		},
+		{
+			name: "private key",
+			mutate: func(d map[string]any) {
+				method(d, 1)["publicKeyJwk"].(map[string]any)["d"] = "x"
+			},
+			want: `private member "d"`,
+		},
+		{
+			name:   "duplicate id",
+			mutate: func(d map[string]any) { method(d, 1)["id"] = "#key-1" },
+			want:   "is used more than once",
+		},
+		{
+			name:   "bad controller",
+			mutate: func(d map[string]any) { method(d, 0)["controller"] = "issuer" },
+			want:   "its controller is not a DID",
+		},
+		{
+			name:   "unknown reference",
+			mutate: func(d map[string]any) { d["authentication"] = []any{"#key-9"} },
+			want:   `unknown verification method "#key-9"`,
+		},
+		{
+			name:   "signing with X25519",
+			mutate: func(d map[string]any) { d["authentication"] = []any{"#key-3"} },
+			want:   `X25519 key "did:web:issuer.example#key-3" cannot sign`,
+		},
+		{
+			name:   "key agreement with Ed25519",
+			mutate: func(d map[string]any) { d["keyAgreement"] = []any{"#key-1"} },
+			want:   "cannot be used for key agreement",
+		},
+		{
+			name: "service without endpoint",
+			mutate: func(d map[string]any) {
+				delete(d["service"].([]any)[0].(map[string]any), "serviceEndpoint")
+			},
+			want: "service[0] has no serviceEndpoint",
+		},
+		{
+			name:   "relationship not an array",
+			mutate: func(d map[string]any) { d["assertionMethod"] = "#key-1" },
+			want:   "assertionMethod must be an array",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := testDocument(t)
+			tt.mutate(document)
+			report := Validate(testDID, document)
+			if tt.want == "" {
+				require.True(t, report.Valid, report.Errors)
+				return
+			}
+			require.False(t, report.Valid)
+			require.Contains(t, joinErrors(report), tt.want)
+		})
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	document := testDocument(t)
+	document["verificationMethod"].([]any)[1].(map[string]any)["type"] = "P256Key2099"
+	document["capabilityInvocation"] = []any{"did:web:controller.example#key-1"}
+	report := Validate(testDID, document)
+	require.True(t, report.Valid, report.Errors)
+	require.Len(t, report.Warnings, 2)
+	require.Contains(t, report.Warnings[0], `type "P256Key2099" is not known`)
+	require.Contains(t, report.Warnings[1], "external verification method")
+
+	report = Validate(testDID, map[string]any{"id": testDID})
+	require.True(t, report.Valid)
+	require.Equal(t, []string{"document has no verification methods"}, report.Warnings)
+	require.NotNil(t, report.VerificationMethods)
+}
+
+func joinErrors(report Report) string {
+	return strings.Join(report.Errors, "\n")
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto/ecdh"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+)
+
+// minRSABits is the smallest RSA modulus accepted in a DID document.
+const minRSABits = 2048
+
+// privateJWKMembers must never appear in a published key.
+var privateJWKMembers = []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"}
+
+// ParseJWK decodes a public JWK as found in publicKeyJwk, rejecting keys that
+// carry private material.
+func ParseJWK(jwk map[string]any) (PublicKey, error) {
+	for _, member := range privateJWKMembers {
+		if _, ok := jwk[member]; ok {
+			return PublicKey{}, fmt.Errorf("jwk contains the private member %q", member)
+		}
+	}
+	encoded, err := json.Marshal(jwk)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	var key federation.JWK
+	if err := json.Unmarshal(encoded, &key); err != nil {
+		return PublicKey{}, fmt.Errorf("invalid jwk: %w", err)
+	}
+
+	switch {
+	case key.Kty == "":
+		return PublicKey{}, errors.New("jwk has no kty")
+	case key.Kty == "EC" && key.Crv == KeyTypeSecp256k1:
+		for name, value := range map[string]string{"x": key.X, "y": key.Y} {
+			coordinate, err := base64.RawURLEncoding.DecodeString(value)
+			if err != nil || len(coordinate) != 32 {
+				return PublicKey{}, fmt.Errorf("invalid secp256k1 %s coordinate", name)
+			}
+		}
+		return PublicKey{Type: KeyTypeSecp256k1}, nil
+	case key.Kty == "OKP" && key.Crv == KeyTypeX25519:
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("x: %w", err)
+		}
+		public, err := ecdh.X25519().NewPublicKey(x)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("invalid X25519 key: %w", err)
+		}
+		return PublicKey{Type: KeyTypeX25519, Key: public}, nil
+	}
+
+	public, err := key.PublicKey()
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid jwk: %w", err)
+	}
+	if rsaKey, ok := public.(*rsa.PublicKey); ok {
+		if rsaKey.N.BitLen() < minRSABits {
+			return PublicKey{}, fmt.Errorf(
+				"RSA key has %d bits, at least %d are required",
+				rsaKey.N.BitLen(),
+				minRSABits,
+			)
+		}
+		return PublicKey{Type: KeyTypeRSA, Key: public}, nil
+	}
+	if key.Kty == "OKP" {
+		return PublicKey{Type: KeyTypeEd25519, Key: public}, nil
+	}
+	return PublicKey{Type: key.Crv, Key: public}, nil
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/stretchr/testify/require"
+)
+
+func jwkMap(t testing.TB, key any) map[string]any {
+	t.Helper()
+	jwk, err := federation.NewJWK("", key)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(jwk)
+	require.NoError(t, err)
+	out := map[string]any{}
+	require.NoError(t, json.Unmarshal(encoded, &out))
+	return out
+}
+
+func TestParseJWK(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	key, err := ParseJWK(jwkMap(t, &ecKey.PublicKey))
+	require.NoError(t, err)
+	require.Equal(t, KeyTypeP384, key.Type)
+	require.Equal(t, &ecKey.PublicKey, key.Key)
+
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err = ParseJWK(map[string]any{
+		"kty": "OKP",
+		"crv": "X25519",
+		"x":   base64.RawURLEncoding.EncodeToString(xKey.PublicKey().Bytes()),
+	})
+	require.NoError(t, err)
+	require.Equal(t, KeyTypeX25519, key.Type)
+
+	coordinate := base64.RawURLEncoding.EncodeToString(make([]byte, 32))
+	key, err = ParseJWK(map[string]any{
+		"kty": "EC",
+		"crv": "secp256k1",
+		"x":   coordinate,
+		"y":   coordinate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, KeyTypeSecp256k1, key.Type)
+	require.Nil(t, key.Key)
+}
+
+func TestParseJWKErrors(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	private := jwkMap(t, &ecKey.PublicKey)
+	private["d"] = base64.RawURLEncoding.EncodeToString(ecKey.D.Bytes())
+
+	offCurve := jwkMap(t, &ecKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		jwk  map[string]any
+		want string
+	}{
+		"private key":  {private, `private member "d"`},
+		"off curve":    {offCurve, "not on curve"},
+		"weak rsa":     {jwkMap(t, &rsaKey.PublicKey), "RSA key has 1024 bits"},
+		"no kty":       {map[string]any{"crv": "P-256"}, "no kty"},
+		"unknown kty":  {map[string]any{"kty": "oct"}, `unsupported key type "oct"`},
+		"bad k1 point": {map[string]any{"kty": "EC", "crv": "secp256k1", "x": "AA"}, "secp256k1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseJWK(tt.jwk)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Key types, named after the JOSE curves.
+const (
+	KeyTypeEd25519   = "Ed25519"
+	KeyTypeX25519    = "X25519"
+	KeyTypeP256      = "P-256"
+	KeyTypeP384      = "P-384"
+	KeyTypeP521      = "P-521"
+	KeyTypeSecp256k1 = "secp256k1"
+	KeyTypeRSA       = "RSA"
+)
+
+// Multicodec codes of public keys.
+const (
+	codecEd25519   = 0xed
+	codecX25519    = 0xec
+	codecSecp256k1 = 0xe7
+	codecP256      = 0x1200
+	codecP384      = 0x1201
+	codecP521      = 0x1202
+	codecJWKJCS    = 0xeb51
+)
+
+// PublicKey is a decoded verification method key. Key is nil for secp256k1,
+// which the standard library does not implement: such keys are only checked
+// for their encoding.
+type PublicKey struct {
+	Type string
+	Key  crypto.PublicKey
+}
+
+// DecodeMultikey decodes a base58btc multibase, multicodec prefixed public
+// key as used by did:key and Multikey verification methods.
+func DecodeMultikey(value string) (PublicKey, error) {
+	if !strings.HasPrefix(value, "z") {
+		return PublicKey{}, errors.New("multikey must be base58btc encoded")
+	}
+	raw, err := DecodeBase58(value[1:])
+	if err != nil {
+		return PublicKey{}, err
+	}
+	codec, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return PublicKey{}, errors.New("invalid multicodec prefix")
+	}
+	data := raw[n:]
+	switch codec {
+	case codecEd25519:
+		if len(data) != ed25519.PublicKeySize {
+			return PublicKey{}, errors.New("invalid Ed25519 key size")
+		}
+		return PublicKey{Type: KeyTypeEd25519, Key: ed25519.PublicKey(data)}, nil
+	case codecX25519:
+		key, err := ecdh.X25519().NewPublicKey(data)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("invalid X25519 key: %w", err)
+		}
+		return PublicKey{Type: KeyTypeX25519, Key: key}, nil
+	case codecP256:
+		return unmarshalCompressed(KeyTypeP256, elliptic.P256(), data)
+	case codecP384:
+		return unmarshalCompressed(KeyTypeP384, elliptic.P384(), data)
+	case codecP521:
+		return unmarshalCompressed(KeyTypeP521, elliptic.P521(), data)
+	case codecSecp256k1:
+		if len(data) != 33 || (data[0] != 0x02 && data[0] != 0x03) {
+			return PublicKey{}, errors.New("invalid compressed secp256k1 key")
+		}
+		return PublicKey{Type: KeyTypeSecp256k1}, nil
+	case codecJWKJCS:
+		jwk := map[string]any{}
+		if err := json.Unmarshal(data, &jwk); err != nil {
+			return PublicKey{}, fmt.Errorf("invalid jwk_jcs-pub key: %w", err)
+		}
+		return ParseJWK(jwk)
+	default:
+		return PublicKey{}, fmt.Errorf("unsupported multicodec key type 0x%x", codec)
+	}
+}
+
+// multikeyJWK returns the JWK a jwk_jcs-pub multikey embeds.
+func multikeyJWK(value string) (map[string]any, bool) {
+	raw, err := DecodeBase58(strings.TrimPrefix(value, "z"))
+	if err != nil {
+		return nil, false
+	}
+	codec, n := binary.Uvarint(raw)
+	if n <= 0 || codec != codecJWKJCS {
+		return nil, false
+	}
+	jwk := map[string]any{}
+	if err := json.Unmarshal(raw[n:], &jwk); err != nil {
+		return nil, false
+	}
+	return jwk, true
+}
+
+// EncodeMultikey encodes an Ed25519, X25519 or NIST curve public key as a
+// base58btc multikey.
+func EncodeMultikey(key crypto.PublicKey) (string, error) {
+	var codec uint64
+	var data []byte
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		codec, data = codecEd25519, k
+	case *ecdh.PublicKey:
+		if k.Curve() != ecdh.X25519() {
+			return "", errors.New("only X25519 ECDH keys are supported")
+		}
+		codec, data = codecX25519, k.Bytes()
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			codec = codecP256
+		case elliptic.P384():
+			codec = codecP384
+		case elliptic.P521():
+			codec = codecP521
+		default:
+			return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+		data = elliptic.MarshalCompressed(k.Curve, k.X, k.Y)
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", key)
+	}
+	return "z" + EncodeBase58(append(binary.AppendUvarint(nil, codec), data...)), nil
+}
+
+func unmarshalCompressed(keyType string, curve elliptic.Curve, data []byte) (PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		return PublicKey{}, fmt.Errorf("invalid compressed %s point", keyType)
+	}
+	return PublicKey{Type: keyType, Key: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}}, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// DecodeBase58 decodes the Bitcoin base58 alphabet used by base58btc
+// multibase values.
+func DecodeBase58(value string) ([]byte, error) {
+	number := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range value {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		number.Mul(number, radix)
+		number.Add(number, big.NewInt(int64(digit)))
+	}
+	zeros := 0
+	for zeros < len(value) && value[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), number.Bytes()...), nil
+}
+
+// EncodeBase58 encodes data with the Bitcoin base58 alphabet.
+func EncodeBase58(data []byte) string {
+	number := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	var out []byte
+	for number.Sign() > 0 {
+		mod := new(big.Int)
+		number.DivMod(number, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
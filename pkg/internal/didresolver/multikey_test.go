@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultikeyRoundTrip(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys := map[string]crypto.PublicKey{
+		KeyTypeEd25519: edPub,
+		KeyTypeX25519:  xKey.PublicKey(),
+	}
+	for keyType, curve := range map[string]elliptic.Curve{
+		KeyTypeP256: elliptic.P256(),
+		KeyTypeP384: elliptic.P384(),
+		KeyTypeP521: elliptic.P521(),
+	} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		keys[keyType] = &key.PublicKey
+	}
+
+	for keyType, key := range keys {
+		t.Run(keyType, func(t *testing.T) {
+			encoded, err := EncodeMultikey(key)
+			require.NoError(t, err)
+			decoded, err := DecodeMultikey(encoded)
+			require.NoError(t, err)
+			require.Equal(t, keyType, decoded.Type)
+			require.Equal(t, key, decoded.Key)
+		})
+	}
+}
+
+func TestDecodeMultikeyVectors(t *testing.T) {
+	// Test vectors of the did:key specification.
+	tests := map[string]string{
+		"z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp":  KeyTypeEd25519,
+		"z6LSeu9HkTHSfLLeUs2nnzUSNedgDUevfNQgQjQC23ZCit6F":  KeyTypeX25519,
+		"zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169": KeyTypeP256,
+		"zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme": KeyTypeSecp256k1,
+	}
+	for value, keyType := range tests {
+		t.Run(keyType, func(t *testing.T) {
+			key, err := DecodeMultikey(value)
+			require.NoError(t, err)
+			require.Equal(t, keyType, key.Type)
+		})
+	}
+}
+
+func TestDecodeMultikeyErrors(t *testing.T) {
+	unknownCodec := "z" + EncodeBase58(binary.AppendUvarint(nil, 0x1205))
+	shortKey := "z" + EncodeBase58([]byte{0xed, 0x01, 1, 2, 3})
+	badPoint := "z" + EncodeBase58(append([]byte{0x80, 0x24}, make([]byte, 33)...))
+	tests := map[string]string{
+		"mAbc":       "base58btc",
+		"z0OIl":      "invalid base58 character",
+		unknownCodec: "unsupported multicodec",
+		shortKey:     "invalid Ed25519 key size",
+		badPoint:     "invalid compressed",
+	}
+	for value, want := range tests {
+		t.Run(want, func(t *testing.T) {
+			_, err := DecodeMultikey(value)
+			require.ErrorContains(t, err, want)
+		})
+	}
+}
+
+func TestBase58(t *testing.T) {
+	for _, data := range [][]byte{
+		{},
+		{0, 0, 1},
+		[]byte("Hello World!"),
+	} {
+		decoded, err := DecodeBase58(EncodeBase58(data))
+		require.NoError(t, err)
+		require.Equal(t, data, decoded)
+	}
+	require.Equal(t, "2NEpo7TZRRrLZSi2U", EncodeBase58([]byte("Hello World!")))
+}
@@ -0,0 +1,389 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package didresolver resolves did:web, did:jwk, did:key and did:ebsi DIDs and
+// validates the DID documents they lead to.
+package didresolver
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DID methods supported by the resolver.
+const (
+	MethodWeb  = "web"
+	MethodJWK  = "jwk"
+	MethodKey  = "key"
+	MethodEBSI = "ebsi"
+)
+
+// DefaultEBSIRegistryURL is the EBSI DID Registry API did:ebsi identifiers
+// are resolved against.
+const DefaultEBSIRegistryURL = "https://api-pilot.ebsi.eu/did-registry/v5/identifiers"
+
+// DID document media types.
+const (
+	ContentTypeDIDJSON   = "application/did+json"
+	ContentTypeDIDLDJSON = "application/did+ld+json"
+)
+
+// Error codes of the DID Resolution specification.
+const (
+	ErrorInvalidDID                 = "invalidDid"
+	ErrorNotFound                   = "notFound"
+	ErrorMethodNotSupported         = "methodNotSupported"
+	ErrorRepresentationNotSupported = "representationNotSupported"
+	ErrorInternal                   = "internalError"
+)
+
+const (
+	contextJWS2020  = "https://w3id.org/security/suites/jws-2020/v1"
+	contextMultikey = "https://w3id.org/security/multikey/v1"
+)
+
+const maxDocumentSize = 1 << 20
+
+var didPattern = regexp.MustCompile(
+	`^did:[a-z0-9]+:(?:(?:[A-Za-z0-9._-]|%[0-9A-Fa-f]{2})*:)*(?:[A-Za-z0-9._-]|%[0-9A-Fa-f]{2})+$`,
+)
+
+// IsDID reports whether value is a DID, without path, query or fragment.
+func IsDID(value string) bool {
+	return didPattern.MatchString(value)
+}
+
+// ResolutionError is a failed resolution, with its DID Resolution error code.
+type ResolutionError struct {
+	DID  string
+	Code string
+	Err  error
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("resolve %s: %s: %v", e.DID, e.Code, e.Err)
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// Resolution is a DID resolution result.
+type Resolution struct {
+	DIDDocument        map[string]any     `json:"didDocument"`
+	DocumentMetadata   map[string]any     `json:"didDocumentMetadata"`
+	ResolutionMetadata ResolutionMetadata `json:"didResolutionMetadata"`
+}
+
+// ResolutionMetadata describes how a DID document was obtained.
+type ResolutionMetadata struct {
+	ContentType string    `json:"contentType"`
+	Method      string    `json:"method"`
+	DocumentURL string    `json:"documentUrl,omitempty"`
+	Retrieved   time.Time `json:"retrieved"`
+}
+
+// Resolver resolves DIDs. The zero value uses http.DefaultClient and the EBSI
+// pilot registry.
+type Resolver struct {
+	HTTPClient *http.Client
+	// EBSIRegistryURL is the identifiers endpoint of the EBSI DID Registry
+	// API, to which the DID is appended.
+	EBSIRegistryURL string
+	Now             func() time.Time
+}
+
+// Resolve returns the DID document of did. It does not validate it, see
+// Validate.
+func (r *Resolver) Resolve(ctx context.Context, did string) (*Resolution, error) {
+	if !IsDID(did) {
+		return nil, &ResolutionError{
+			DID:  did,
+			Code: ErrorInvalidDID,
+			Err:  errors.New("invalid syntax"),
+		}
+	}
+	parts := strings.SplitN(did, ":", 3)
+	method, id := parts[1], parts[2]
+
+	resolution := &Resolution{
+		DocumentMetadata:   map[string]any{},
+		ResolutionMetadata: ResolutionMetadata{Method: method, Retrieved: r.now()},
+	}
+	var err error
+	switch method {
+	case MethodJWK:
+		resolution.DIDDocument, err = jwkDocument(did, id)
+	case MethodKey:
+		resolution.DIDDocument, err = keyDocument(did, id)
+	case MethodWeb:
+		var documentURL string
+		if documentURL, err = webDocumentURL(id); err == nil {
+			err = r.fetch(ctx, did, documentURL, resolution)
+		}
+	case MethodEBSI:
+		if err = validateEBSIIdentifier(id); err == nil {
+			registry := r.EBSIRegistryURL
+			if registry == "" {
+				registry = DefaultEBSIRegistryURL
+			}
+			documentURL := strings.TrimRight(registry, "/") + "/" + url.PathEscape(did)
+			err = r.fetch(ctx, did, documentURL, resolution)
+		}
+	default:
+		return nil, &ResolutionError{
+			DID:  did,
+			Code: ErrorMethodNotSupported,
+			Err:  fmt.Errorf("did:%s is not supported", method),
+		}
+	}
+	if err != nil {
+		var resolutionErr *ResolutionError
+		if errors.As(err, &resolutionErr) {
+			return nil, err
+		}
+		return nil, &ResolutionError{DID: did, Code: ErrorInvalidDID, Err: err}
+	}
+	if resolution.ResolutionMetadata.ContentType == "" {
+		resolution.ResolutionMetadata.ContentType = ContentTypeDIDLDJSON
+	}
+	return resolution, nil
+}
+
+// StaticKey returns the DID and public key of a did:jwk or did:key URL, whose
+// document is derived from the identifier itself.
+func StaticKey(didURL string) (string, crypto.PublicKey, error) {
+	did, _, _ := strings.Cut(didURL, "#")
+	var key PublicKey
+	switch {
+	case strings.HasPrefix(did, "did:jwk:"):
+		jwk, err := decodeDIDJWK(strings.TrimPrefix(did, "did:jwk:"))
+		if err == nil {
+			key, err = ParseJWK(jwk)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("did:jwk: %w", err)
+		}
+	case strings.HasPrefix(did, "did:key:"):
+		var err error
+		if key, err = DecodeMultikey(strings.TrimPrefix(did, "did:key:")); err != nil {
+			return "", nil, fmt.Errorf("did:key: %w", err)
+		}
+	default:
+		return "", nil, fmt.Errorf("unsupported verification method %q", didURL)
+	}
+	if key.Key == nil {
+		return "", nil, fmt.Errorf("%s keys are not supported", key.Type)
+	}
+	return did, key.Key, nil
+}
+
+func decodeDIDJWK(id string) (map[string]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+	jwk := map[string]any{}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return nil, err
+	}
+	return jwk, nil
+}
+
+// jwkDocument derives a did:jwk document. The relationships follow the key
+// use, leaving out those the key type cannot serve.
+func jwkDocument(did, id string) (map[string]any, error) {
+	jwk, err := decodeDIDJWK(id)
+	if err != nil {
+		return nil, fmt.Errorf("did:jwk: %w", err)
+	}
+	key, err := ParseJWK(jwk)
+	if err != nil {
+		return nil, fmt.Errorf("did:jwk: %w", err)
+	}
+	use, _ := jwk["use"].(string)
+	document := staticDocument(did, []any{ContextDIDv1, contextJWS2020}, map[string]any{
+		"id":           did + "#0",
+		"type":         "JsonWebKey2020",
+		"controller":   did,
+		"publicKeyJwk": jwk,
+	}, key.Type, use)
+	return document, nil
+}
+
+// keyDocument derives a did:key document. jwk_jcs-pub keys, used by EBSI for
+// natural persons, are published as JsonWebKey2020 methods.
+func keyDocument(did, id string) (map[string]any, error) {
+	key, err := DecodeMultikey(id)
+	if err != nil {
+		return nil, fmt.Errorf("did:key: %w", err)
+	}
+	if jwk, ok := multikeyJWK(id); ok {
+		return staticDocument(did, []any{ContextDIDv1, contextJWS2020}, map[string]any{
+			"id":           did + "#" + id,
+			"type":         "JsonWebKey2020",
+			"controller":   did,
+			"publicKeyJwk": jwk,
+		}, key.Type, ""), nil
+	}
+	return staticDocument(did, []any{ContextDIDv1, contextMultikey}, map[string]any{
+		"id":                 did + "#" + id,
+		"type":               "Multikey",
+		"controller":         did,
+		"publicKeyMultibase": id,
+	}, key.Type, ""), nil
+}
+
+func staticDocument(
+	did string,
+	contexts []any,
+	method map[string]any,
+	keyType, use string,
+) map[string]any {
+	document := map[string]any{
+		"@context":           contexts,
+		"id":                 did,
+		"verificationMethod": []any{method},
+	}
+	reference := []any{method["id"]}
+	if use != "enc" && keyType != KeyTypeX25519 {
+		document[RelationshipAuthentication] = reference
+		document[RelationshipAssertionMethod] = reference
+		document[RelationshipCapabilityInvocation] = reference
+		document[RelationshipCapabilityDelegation] = reference
+	}
+	if use != "sig" && keyType != KeyTypeEd25519 {
+		document[RelationshipKeyAgreement] = reference
+	}
+	return document
+}
+
+// webDocumentURL maps a did:web identifier to the URL of its document.
+func webDocumentURL(id string) (string, error) {
+	segments := strings.Split(id, ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("did:web: invalid host %q", segments[0])
+	}
+	path := "/.well-known"
+	if len(segments) > 1 {
+		path = ""
+		for _, segment := range segments[1:] {
+			decoded, err := url.PathUnescape(segment)
+			if err != nil || decoded == "" || strings.Contains(decoded, "/") {
+				return "", fmt.Errorf("did:web: invalid path segment %q", segment)
+			}
+			path += "/" + url.PathEscape(decoded)
+		}
+	}
+	return "https://" + host + path + "/did.json", nil
+}
+
+// validateEBSIIdentifier checks the method specific identifier of a legal
+// entity: "z" and the base58btc encoding of a version byte 0x01 followed by
+// 16 random bytes.
+func validateEBSIIdentifier(id string) error {
+	if !strings.HasPrefix(id, "z") {
+		return errors.New("did:ebsi: identifier must be base58btc encoded")
+	}
+	raw, err := DecodeBase58(id[1:])
+	if err != nil {
+		return fmt.Errorf("did:ebsi: %w", err)
+	}
+	if len(raw) != 17 || raw[0] != 0x01 {
+		return errors.New("did:ebsi: identifier must be version 1 with 16 bytes")
+	}
+	return nil
+}
+
+// fetch downloads a DID document. Responses in the DID Resolution result
+// format, as served by universal resolvers, are unwrapped.
+func (r *Resolver) fetch(
+	ctx context.Context,
+	did, documentURL string,
+	resolution *Resolution,
+) error {
+	resolution.ResolutionMetadata.DocumentURL = documentURL
+	internal := func(err error) error {
+		return &ResolutionError{DID: did, Code: ErrorInternal, Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return internal(err)
+	}
+	req.Header.Set(
+		"Accept",
+		ContentTypeDIDLDJSON+", "+ContentTypeDIDJSON+", application/ld+json, application/json",
+	)
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return internal(err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return &ResolutionError{
+			DID:  did,
+			Code: ErrorNotFound,
+			Err:  fmt.Errorf("%s returned status %d", documentURL, resp.StatusCode),
+		}
+	case resp.StatusCode != http.StatusOK:
+		return internal(fmt.Errorf("%s returned status %d", documentURL, resp.StatusCode))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return internal(err)
+	}
+	if len(body) > maxDocumentSize {
+		return internal(errors.New("DID document exceeds 1MB"))
+	}
+
+	document := map[string]any{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return &ResolutionError{
+			DID:  did,
+			Code: ErrorRepresentationNotSupported,
+			Err:  fmt.Errorf("DID document is not a JSON object: %w", err),
+		}
+	}
+	if wrapped, ok := document["didDocument"].(map[string]any); ok {
+		if metadata, ok := document["didDocumentMetadata"].(map[string]any); ok {
+			resolution.DocumentMetadata = metadata
+		}
+		document = wrapped
+	}
+	resolution.DIDDocument = document
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case contentType == ContentTypeDIDJSON || contentType == ContentTypeDIDLDJSON:
+		resolution.ResolutionMetadata.ContentType = contentType
+	case document["@context"] == nil:
+		resolution.ResolutionMetadata.ContentType = ContentTypeDIDJSON
+	}
+	return nil
+}
+
+func (r *Resolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,263 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package didresolver
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func didJWK(t testing.TB, jwk map[string]any) string {
+	t.Helper()
+	encoded, err := json.Marshal(jwk)
+	require.NoError(t, err)
+	return "did:jwk:" + base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func requireResolutionError(t testing.TB, err error, code string) {
+	t.Helper()
+	var resolutionErr *ResolutionError
+	require.True(t, errors.As(err, &resolutionErr), err)
+	require.Equal(t, code, resolutionErr.Code, err)
+}
+
+func TestResolveJWK(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	did := didJWK(t, jwkMap(t, &ecKey.PublicKey))
+
+	resolver := &Resolver{}
+	resolution, err := resolver.Resolve(context.Background(), did)
+	require.NoError(t, err)
+	require.Equal(t, MethodJWK, resolution.ResolutionMetadata.Method)
+	require.Equal(t, ContentTypeDIDLDJSON, resolution.ResolutionMetadata.ContentType)
+	require.Empty(t, resolution.ResolutionMetadata.DocumentURL)
+	for _, relationship := range relationships {
+		require.Equal(t, []any{did + "#0"}, resolution.DIDDocument[relationship], relationship)
+	}
+	report := Validate(did, normalize(t, resolution.DIDDocument))
+	require.True(t, report.Valid, report.Errors)
+	require.Equal(t, KeyTypeP256, report.VerificationMethods[0].KeyType)
+
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	did = didJWK(t, map[string]any{
+		"kty": "OKP",
+		"crv": "X25519",
+		"use": "enc",
+		"x":   base64.RawURLEncoding.EncodeToString(xKey.PublicKey().Bytes()),
+	})
+	resolution, err = resolver.Resolve(context.Background(), did)
+	require.NoError(t, err)
+	require.Equal(t, []any{did + "#0"}, resolution.DIDDocument[RelationshipKeyAgreement])
+	require.NotContains(t, resolution.DIDDocument, RelationshipAssertionMethod)
+	report = Validate(did, normalize(t, resolution.DIDDocument))
+	require.True(t, report.Valid, report.Errors)
+}
+
+func TestResolveKey(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	multikey, err := EncodeMultikey(edPub)
+	require.NoError(t, err)
+	did := "did:key:" + multikey
+
+	resolver := &Resolver{}
+	resolution, err := resolver.Resolve(context.Background(), did)
+	require.NoError(t, err)
+	method := resolution.DIDDocument["verificationMethod"].([]any)[0].(map[string]any)
+	require.Equal(t, "Multikey", method["type"])
+	require.Equal(t, multikey, method["publicKeyMultibase"])
+	require.NotContains(t, resolution.DIDDocument, RelationshipKeyAgreement)
+	report := Validate(did, normalize(t, resolution.DIDDocument))
+	require.True(t, report.Valid, report.Errors)
+
+	// jwk_jcs-pub, as used by EBSI natural persons.
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := json.Marshal(jwkMap(t, &ecKey.PublicKey))
+	require.NoError(t, err)
+	multikey = "z" + EncodeBase58(append(binary.AppendUvarint(nil, codecJWKJCS), jwk...))
+	did = "did:key:" + multikey
+	resolution, err = resolver.Resolve(context.Background(), did)
+	require.NoError(t, err)
+	method = resolution.DIDDocument["verificationMethod"].([]any)[0].(map[string]any)
+	require.Equal(t, "JsonWebKey2020", method["type"])
+	report = Validate(did, normalize(t, resolution.DIDDocument))
+	require.True(t, report.Valid, report.Errors)
+	require.Equal(t, KeyTypeP256, report.VerificationMethods[0].KeyType)
+}
+
+func TestResolveWeb(t *testing.T) {
+	var served []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = append(served, r.URL.Path)
+		if r.URL.Path == "/missing/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/text/did.json" {
+			_, _ = w.Write([]byte("not json"))
+			return
+		}
+		did := "did:web:" + strings.ReplaceAll(r.Host, ":", "%3A")
+		if path := strings.TrimSuffix(r.URL.Path, "/did.json"); path != "/.well-known" {
+			did += strings.ReplaceAll(path, "/", ":")
+		}
+		document := map[string]any{"@context": []any{ContextDIDv1}, "id": did}
+		w.Header().Set("Content-Type", ContentTypeDIDJSON)
+		_ = json.NewEncoder(w).Encode(document)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host := strings.ReplaceAll(serverURL.Host, ":", "%3A")
+	resolver := &Resolver{
+		HTTPClient: server.Client(),
+		Now:        func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	resolve := func(did string) (*Resolution, error) {
+		return resolver.Resolve(context.Background(), did)
+	}
+
+	did := "did:web:" + host
+	resolution, err := resolve(did)
+	require.NoError(t, err)
+	require.Equal(t, did, resolution.DIDDocument["id"])
+	require.Equal(t, ResolutionMetadata{
+		ContentType: ContentTypeDIDJSON,
+		Method:      MethodWeb,
+		DocumentURL: server.URL + "/.well-known/did.json",
+		Retrieved:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, resolution.ResolutionMetadata)
+
+	did = "did:web:" + host + ":user:alice"
+	resolution, err = resolve(did)
+	require.NoError(t, err)
+	require.Equal(t, did, resolution.DIDDocument["id"])
+	require.Equal(t, []string{"/.well-known/did.json", "/user/alice/did.json"}, served)
+
+	_, err = resolve("did:web:" + host + ":missing")
+	requireResolutionError(t, err, ErrorNotFound)
+	_, err = resolve("did:web:" + host + ":text")
+	requireResolutionError(t, err, ErrorRepresentationNotSupported)
+}
+
+func TestResolveEBSI(t *testing.T) {
+	did := "did:ebsi:z" + EncodeBase58(append([]byte{0x01}, make([]byte, 16)...))
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identifiers/"+did {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"didDocument": map[string]any{
+				"@context": []any{ContextDIDv1},
+				"id":       did,
+			},
+			"didDocumentMetadata": map[string]any{"versionId": "1"},
+		})
+	}))
+	defer registry.Close()
+
+	resolver := &Resolver{EBSIRegistryURL: registry.URL + "/identifiers/"}
+	resolution, err := resolver.Resolve(context.Background(), did)
+	require.NoError(t, err)
+	require.Equal(t, did, resolution.DIDDocument["id"])
+	require.Equal(t, map[string]any{"versionId": "1"}, resolution.DocumentMetadata)
+	require.Equal(t, registry.URL+"/identifiers/"+did, resolution.ResolutionMetadata.DocumentURL)
+	require.Equal(t, ContentTypeDIDLDJSON, resolution.ResolutionMetadata.ContentType)
+
+	other := "did:ebsi:z" + EncodeBase58(append([]byte{0x01, 0x01}, make([]byte, 15)...))
+	_, err = resolver.Resolve(context.Background(), other)
+	requireResolutionError(t, err, ErrorNotFound)
+
+	_, err = resolver.Resolve(context.Background(), "did:ebsi:zabc")
+	requireResolutionError(t, err, ErrorInvalidDID)
+}
+
+func TestResolveErrors(t *testing.T) {
+	resolver := &Resolver{}
+	tests := map[string]string{
+		"did:web":                     ErrorInvalidDID,
+		"did:web:issuer.example#key":  ErrorInvalidDID,
+		"did:example:123":             ErrorMethodNotSupported,
+		"did:jwk:not-a-jwk":           ErrorInvalidDID,
+		"did:key:z6MkiTBz1ymuepAQ4HE": ErrorInvalidDID,
+	}
+	for did, code := range tests {
+		t.Run(did, func(t *testing.T) {
+			_, err := resolver.Resolve(context.Background(), did)
+			requireResolutionError(t, err, code)
+		})
+	}
+}
+
+func TestWebDocumentURL(t *testing.T) {
+	tests := map[string]string{
+		"issuer.example":              "https://issuer.example/.well-known/did.json",
+		"issuer.example%3A8443":       "https://issuer.example:8443/.well-known/did.json",
+		"issuer.example:user:alice":   "https://issuer.example/user/alice/did.json",
+		"issuer.example:a%20b":        "https://issuer.example/a%20b/did.json",
+		"issuer.example%2Fpath":       "",
+		"issuer.example::alice":       "",
+		"issuer.example:user%2Falice": "",
+		"user%40issuer.example:alice": "",
+	}
+	for id, want := range tests {
+		t.Run(id, func(t *testing.T) {
+			got, err := webDocumentURL(id)
+			if want == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestStaticKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	did := didJWK(t, jwkMap(t, &ecKey.PublicKey))
+	gotDID, key, err := StaticKey(did + "#0")
+	require.NoError(t, err)
+	require.Equal(t, did, gotDID)
+	require.Equal(t, &ecKey.PublicKey, key)
+
+	_, _, err = StaticKey("did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme")
+	require.ErrorContains(t, err, "secp256k1 keys are not supported")
+	_, _, err = StaticKey("did:web:issuer.example#key-1")
+	require.ErrorContains(t, err, "unsupported verification method")
+}
+
+// normalize round-trips a derived document through JSON, as Validate expects.
+func normalize(t testing.TB, document map[string]any) map[string]any {
+	t.Helper()
+	encoded, err := json.Marshal(document)
+	require.NoError(t, err)
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return decoded
+}
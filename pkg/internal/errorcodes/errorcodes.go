@@ -57,6 +57,21 @@ var Codes = map[string]Code{
 	OpenID4VCIIssuerCheckFailed:    {"CRE313", "OID4VCI issuer check failed"},
 	DockerCommandExecutionFailed:   {"CRE311", "Docker command execution failed"},
 	MobileRunnerBusy:               {"CRE312", "Mobile runner busy"},
+	FederationTrustChainFailed:     {"CRE314", "OpenID Federation trust chain verification failed"},
+	TrustedListParseFailed:         {"CRE315", "Failed to parse trusted list"},
+	TrustedListSignatureInvalid:    {"CRE316", "Trusted list signature verification failed"},
+	StatusListInvalid:              {"CRE317", "Invalid credential status list"},
+	StatusListSignatureInvalid:     {"CRE318", "Status list signature verification failed"},
+	CredentialStatusMismatch:       {"CRE319", "Credential status differs from the expected one"},
+	DIDResolutionFailed:            {"CRE320", "Failed to resolve DID"},
+	DIDDocumentInvalid:             {"CRE321", "Invalid DID document"},
+	CertificateChainInvalid:        {"CRE322", "Certificate chain validation failed"},
+	VerifierRequestFetchFailed:     {"CRE323", "Failed to fetch the verifier request"},
+	VerifierRequestInvalid:         {"CRE324", "Invalid verifier authorization request"},
+	ReferenceServiceFailed:         {"CRE325", "Reference service operation failed"},
+	ReferencePresentationInvalid:   {"CRE326", "Presentation rejected by the reference verifier"},
+	ProtocolFuzzSetupFailed:        {"CRE327", "Protocol fuzzing could not reach a valid baseline"},
+	ProtocolFuzzFailed:             {"CRE328", "Server accepted or mishandled mutated requests"},
 	ReadFromReaderFailed:           {"CRE901", "Failed to read from reader"},
 	CopyFromReaderFailed:           {"CRE902", "Failed to copy from reader"},
 	MkdirFailed:                    {"CRE903", "Failed to create a new folder"},
@@ -111,6 +126,21 @@ const (
 	OpenID4VCIIssuerCheckFailed    = "CRE313"
 	DockerCommandExecutionFailed   = "CRE311"
 	MobileRunnerBusy               = "CRE312"
+	FederationTrustChainFailed     = "CRE314"
+	TrustedListParseFailed         = "CRE315"
+	TrustedListSignatureInvalid    = "CRE316"
+	StatusListInvalid              = "CRE317"
+	StatusListSignatureInvalid     = "CRE318"
+	CredentialStatusMismatch       = "CRE319"
+	DIDResolutionFailed            = "CRE320"
+	DIDDocumentInvalid             = "CRE321"
+	CertificateChainInvalid        = "CRE322"
+	VerifierRequestFetchFailed     = "CRE323"
+	VerifierRequestInvalid         = "CRE324"
+	ReferenceServiceFailed         = "CRE325"
+	ReferencePresentationInvalid   = "CRE326"
+	ProtocolFuzzSetupFailed        = "CRE327"
+	ProtocolFuzzFailed             = "CRE328"
 	ReadFromReaderFailed           = "CRE901"
 	CopyFromReaderFailed           = "CRE902"
 	MkdirFailed                    = "CRE903"
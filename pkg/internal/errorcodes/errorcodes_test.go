@@ -55,6 +55,21 @@ var allCodes = []string{
 	UnexpectedHTTPStatusCode,
 	DockerCommandExecutionFailed,
 	MobileRunnerBusy,
+	FederationTrustChainFailed,
+	TrustedListParseFailed,
+	TrustedListSignatureInvalid,
+	StatusListInvalid,
+	StatusListSignatureInvalid,
+	CredentialStatusMismatch,
+	DIDResolutionFailed,
+	DIDDocumentInvalid,
+	CertificateChainInvalid,
+	VerifierRequestFetchFailed,
+	VerifierRequestInvalid,
+	ReferenceServiceFailed,
+	ReferencePresentationInvalid,
+	ProtocolFuzzSetupFailed,
+	ProtocolFuzzFailed,
 	OpenID4VCIIssuerCheckFailed,
 	ReadFromReaderFailed,
 	CopyFromReaderFailed,
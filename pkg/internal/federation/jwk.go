@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package federation resolves OpenID Federation trust chains: it fetches entity
+// configurations and subordinate statements, verifies their signatures up to a
+// configured trust anchor, applies metadata policies and checks trust marks.
+package federation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the subset of RFC 7517 needed to verify federation statements.
+type JWK struct {
+	Kty string `json:"kty"           yaml:"kty"`
+	Kid string `json:"kid,omitempty" yaml:"kid,omitempty"`
+	Use string `json:"use,omitempty" yaml:"use,omitempty"`
+	Alg string `json:"alg,omitempty" yaml:"alg,omitempty"`
+	Crv string `json:"crv,omitempty" yaml:"crv,omitempty"`
+	X   string `json:"x,omitempty"   yaml:"x,omitempty"`
+	Y   string `json:"y,omitempty"   yaml:"y,omitempty"`
+	N   string `json:"n,omitempty"   yaml:"n,omitempty"`
+	E   string `json:"e,omitempty"   yaml:"e,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set as carried in the jwks claim.
+type JWKSet struct {
+	Keys []JWK `json:"keys" yaml:"keys"`
+}
+
+// Find returns the key with the given kid. Without a kid it only succeeds
+// when the set holds a single key.
+func (s *JWKSet) Find(kid string) (*JWK, error) {
+	if s == nil || len(s.Keys) == 0 {
+		return nil, errors.New("empty jwks")
+	}
+	if kid == "" {
+		if len(s.Keys) == 1 {
+			return &s.Keys[0], nil
+		}
+		return nil, errors.New("token has no kid and jwks holds several keys")
+	}
+	for i := range s.Keys {
+		if s.Keys[i].Kid == kid {
+			return &s.Keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no key with kid %q", kid)
+}
+
+// PublicKey decodes the JWK into a crypto public key.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "EC":
+		curve, err := ecCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// NewJWK encodes a public key as a JWK with the given kid.
+func NewJWK(kid string, key crypto.PublicKey) (JWK, error) {
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Kid: kid,
+			Crv: pub.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+func ecCurve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", name)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing value")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
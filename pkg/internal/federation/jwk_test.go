@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKRoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, pub := range []any{&ecKey.PublicKey, &rsaKey.PublicKey, edPub} {
+		jwk, err := NewJWK("k1", pub)
+		require.NoError(t, err)
+		decoded, err := jwk.PublicKey()
+		require.NoError(t, err)
+		require.Equal(t, pub, decoded)
+	}
+}
+
+func TestJWKInvalid(t *testing.T) {
+	_, err := JWK{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"}.PublicKey()
+	require.ErrorContains(t, err, "not on curve")
+
+	_, err = JWK{Kty: "oct"}.PublicKey()
+	require.ErrorContains(t, err, "unsupported key type")
+
+	_, err = JWK{Kty: "OKP", Crv: "X25519"}.PublicKey()
+	require.ErrorContains(t, err, "unsupported OKP curve")
+}
+
+func TestJWKSetFind(t *testing.T) {
+	set := &JWKSet{Keys: []JWK{{Kty: "EC", Kid: "a"}, {Kty: "EC", Kid: "b"}}}
+
+	key, err := set.Find("b")
+	require.NoError(t, err)
+	require.Equal(t, "b", key.Kid)
+
+	_, err = set.Find("")
+	require.ErrorContains(t, err, "several keys")
+
+	_, err = set.Find("c")
+	require.ErrorContains(t, err, `no key with kid "c"`)
+
+	single := &JWKSet{Keys: []JWK{{Kty: "EC", Kid: "a"}}}
+	key, err = single.Find("")
+	require.NoError(t, err)
+	require.Equal(t, "a", key.Kid)
+
+	_, err = (*JWKSet)(nil).Find("a")
+	require.ErrorContains(t, err, "empty jwks")
+}
@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Metadata policy operators, in the order they are applied.
+const (
+	PolicyValue      = "value"
+	PolicyAdd        = "add"
+	PolicyDefault    = "default"
+	PolicyOneOf      = "one_of"
+	PolicySubsetOf   = "subset_of"
+	PolicySupersetOf = "superset_of"
+	PolicyEssential  = "essential"
+)
+
+var policyOperatorOrder = []string{
+	PolicyValue,
+	PolicyAdd,
+	PolicyDefault,
+	PolicyOneOf,
+	PolicySubsetOf,
+	PolicySupersetOf,
+	PolicyEssential,
+}
+
+// MetadataPolicy maps metadata parameters to their policy operators.
+type MetadataPolicy map[string]map[string]any
+
+// MergePolicies combines the policies of a trust chain, superior first, as
+// described in OpenID Federation section 6.1.3. Conflicting operators make
+// the chain invalid.
+func MergePolicies(policies ...MetadataPolicy) (MetadataPolicy, error) {
+	merged := MetadataPolicy{}
+	for _, policy := range policies {
+		for claim, operators := range policy {
+			current, ok := merged[claim]
+			if !ok {
+				current = map[string]any{}
+				merged[claim] = current
+			}
+			for operator, value := range operators {
+				combined, err := mergeOperator(operator, current[operator], value, ok)
+				if err != nil {
+					return nil, fmt.Errorf("metadata policy for %q: %w", claim, err)
+				}
+				current[operator] = combined
+			}
+			if err := checkOperatorCombination(current); err != nil {
+				return nil, fmt.Errorf("metadata policy for %q: %w", claim, err)
+			}
+		}
+	}
+	return merged, nil
+}
+
+func mergeOperator(operator string, existing, value any, hadClaim bool) (any, error) {
+	if !hadClaim || existing == nil {
+		if operator == PolicyEssential {
+			essential, _ := value.(bool)
+			return essential, nil
+		}
+		return value, nil
+	}
+	switch operator {
+	case PolicyValue, PolicyDefault:
+		if !reflect.DeepEqual(existing, value) {
+			return nil, fmt.Errorf("conflicting %s values", operator)
+		}
+		return existing, nil
+	case PolicyAdd, PolicySupersetOf:
+		return union(toSlice(existing), toSlice(value)), nil
+	case PolicyOneOf, PolicySubsetOf:
+		intersection := intersect(toSlice(existing), toSlice(value))
+		if operator == PolicyOneOf && len(intersection) == 0 {
+			return nil, fmt.Errorf("%s has no common values", operator)
+		}
+		return intersection, nil
+	case PolicyEssential:
+		a, _ := existing.(bool)
+		b, _ := value.(bool)
+		return a || b, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+}
+
+func checkOperatorCombination(operators map[string]any) error {
+	if subset, ok := operators[PolicySubsetOf]; ok {
+		if superset, ok := operators[PolicySupersetOf]; ok &&
+			!containsAll(toSlice(subset), toSlice(superset)) {
+			return fmt.Errorf("superset_of is not a subset of subset_of")
+		}
+		if add, ok := operators[PolicyAdd]; ok && !containsAll(toSlice(subset), toSlice(add)) {
+			return fmt.Errorf("add values are not allowed by subset_of")
+		}
+	}
+	if oneOf, ok := operators[PolicyOneOf]; ok {
+		if def, ok := operators[PolicyDefault]; ok && !containsAll(toSlice(oneOf), []any{def}) {
+			return fmt.Errorf("default is not one of one_of")
+		}
+	}
+	return nil
+}
+
+// ApplyPolicy applies a merged policy to metadata and returns the resolved
+// metadata. The input map is not modified.
+func ApplyPolicy(metadata map[string]any, policy MetadataPolicy) (map[string]any, error) {
+	resolved := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		resolved[key] = value
+	}
+
+	claims := make([]string, 0, len(policy))
+	for claim := range policy {
+		claims = append(claims, claim)
+	}
+	sort.Strings(claims)
+
+	for _, claim := range claims {
+		operators := policy[claim]
+		for _, operator := range policyOperatorOrder {
+			arg, ok := operators[operator]
+			if !ok {
+				continue
+			}
+			value, present := resolved[claim]
+			switch operator {
+			case PolicyValue:
+				if arg == nil {
+					delete(resolved, claim)
+				} else {
+					resolved[claim] = arg
+				}
+			case PolicyAdd:
+				resolved[claim] = union(toSlice(value), toSlice(arg))
+			case PolicyDefault:
+				if !present || value == nil {
+					resolved[claim] = arg
+				}
+			case PolicyOneOf:
+				if present && !containsAll(toSlice(arg), []any{value}) {
+					return nil, fmt.Errorf("%s: value %v is not one of %v", claim, value, arg)
+				}
+			case PolicySubsetOf:
+				if present {
+					subset := intersect(toSlice(value), toSlice(arg))
+					if len(subset) == 0 {
+						delete(resolved, claim)
+					} else {
+						resolved[claim] = subset
+					}
+				}
+			case PolicySupersetOf:
+				if present && !containsAll(toSlice(value), toSlice(arg)) {
+					return nil, fmt.Errorf("%s: %v is not a superset of %v", claim, value, arg)
+				}
+			case PolicyEssential:
+				if essential, _ := arg.(bool); essential {
+					if _, ok := resolved[claim]; !ok {
+						return nil, fmt.Errorf("%s: essential parameter is missing", claim)
+					}
+				}
+			}
+		}
+	}
+	return resolved, nil
+}
+
+func toSlice(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	case []string:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			out = append(out, item)
+		}
+		return out
+	default:
+		return []any{v}
+	}
+}
+
+func union(a, b []any) []any {
+	out := make([]any, 0, len(a)+len(b))
+	out = append(out, a...)
+	for _, item := range b {
+		if !containsAll(out, []any{item}) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func intersect(a, b []any) []any {
+	out := []any{}
+	for _, item := range a {
+		if containsAll(b, []any{item}) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func containsAll(haystack, needles []any) bool {
+	for _, needle := range needles {
+		found := false
+		for _, item := range haystack {
+			if reflect.DeepEqual(item, needle) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePolicies(t *testing.T) {
+	merged, err := MergePolicies(
+		MetadataPolicy{
+			"grant_types": {"subset_of": []any{"authorization_code", "pre-authorized_code"}},
+			"scopes":      {"add": []any{"openid"}},
+			"display":     {"essential": false},
+		},
+		MetadataPolicy{
+			"grant_types": {"subset_of": []any{"pre-authorized_code", "refresh_token"}},
+			"scopes":      {"add": []any{"pid"}},
+			"display":     {"essential": true},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []any{"pre-authorized_code"}, merged["grant_types"]["subset_of"])
+	require.Equal(t, []any{"openid", "pid"}, merged["scopes"]["add"])
+	require.Equal(t, true, merged["display"]["essential"])
+}
+
+func TestMergePoliciesConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []MetadataPolicy
+		errMsg   string
+	}{
+		{
+			name: "different values",
+			policies: []MetadataPolicy{
+				{"alg": {"value": "ES256"}},
+				{"alg": {"value": "RS256"}},
+			},
+			errMsg: "conflicting value values",
+		},
+		{
+			name: "disjoint one_of",
+			policies: []MetadataPolicy{
+				{"alg": {"one_of": []any{"ES256"}}},
+				{"alg": {"one_of": []any{"RS256"}}},
+			},
+			errMsg: "one_of has no common values",
+		},
+		{
+			name: "default outside one_of",
+			policies: []MetadataPolicy{
+				{"alg": {"one_of": []any{"ES256"}}},
+				{"alg": {"default": "RS256"}},
+			},
+			errMsg: "default is not one of one_of",
+		},
+		{
+			name: "add outside subset_of",
+			policies: []MetadataPolicy{
+				{"scopes": {"subset_of": []any{"openid"}}},
+				{"scopes": {"add": []any{"pid"}}},
+			},
+			errMsg: "add values are not allowed by subset_of",
+		},
+		{
+			name: "unknown operator",
+			policies: []MetadataPolicy{
+				{"alg": {"regexp": "^ES"}},
+				{"alg": {"regexp": "^RS"}},
+			},
+			errMsg: "unsupported operator",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := MergePolicies(tc.policies...)
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	metadata := map[string]any{
+		"algs":     []any{"ES256", "RS256"},
+		"format":   "dc+sd-jwt",
+		"obsolete": "x",
+	}
+	resolved, err := ApplyPolicy(metadata, MetadataPolicy{
+		"algs":     {"subset_of": []any{"ES256"}, "superset_of": []any{"ES256"}},
+		"format":   {"one_of": []any{"dc+sd-jwt", "mso_mdoc"}},
+		"obsolete": {"value": nil},
+		"batch":    {"default": float64(5)},
+		"scopes":   {"add": []any{"openid"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"algs":   []any{"ES256"},
+		"format": "dc+sd-jwt",
+		"batch":  float64(5),
+		"scopes": []any{"openid"},
+	}, resolved)
+	require.Equal(t, "x", metadata["obsolete"], "input metadata must not be modified")
+}
+
+func TestApplyPolicyViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy MetadataPolicy
+		errMsg string
+	}{
+		{
+			name:   "one_of",
+			policy: MetadataPolicy{"format": {"one_of": []any{"mso_mdoc"}}},
+			errMsg: "is not one of",
+		},
+		{
+			name:   "superset_of",
+			policy: MetadataPolicy{"algs": {"superset_of": []any{"ES384"}}},
+			errMsg: "is not a superset of",
+		},
+		{
+			name:   "essential",
+			policy: MetadataPolicy{"display": {"essential": true}},
+			errMsg: "essential parameter is missing",
+		},
+		{
+			name: "subset_of removes claim before essential",
+			policy: MetadataPolicy{
+				"algs": {"subset_of": []any{"EdDSA"}, "essential": true},
+			},
+			errMsg: "essential parameter is missing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ApplyPolicy(map[string]any{
+				"algs":   []any{"ES256"},
+				"format": "dc+sd-jwt",
+			}, tc.policy)
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
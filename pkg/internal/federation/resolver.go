@@ -0,0 +1,529 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultMaxPathLength bounds how many superiors are walked above the
+	// leaf before giving up on reaching a trust anchor.
+	DefaultMaxPathLength = 5
+
+	wellKnownPath            = "/.well-known/openid-federation"
+	maxStatementSize         = 1 << 20
+	statementKindConfig      = "entity_configuration"
+	statementKindSubordinate = "subordinate_statement"
+)
+
+// TrustAnchor is a federation entity trusted by configuration. When JWKS is
+// set, the anchor's entity configuration must be signed with one of its keys.
+type TrustAnchor struct {
+	EntityID string  `json:"entity_id"      yaml:"entity_id"      validate:"required"`
+	JWKS     *JWKSet `json:"jwks,omitempty" yaml:"jwks,omitempty"`
+}
+
+// ChainStatement describes one verified statement of a trust chain.
+type ChainStatement struct {
+	Kind      string `json:"kind"`
+	Issuer    string `json:"issuer"`
+	Subject   string `json:"subject"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// TrustMarkResult is the outcome of checking one trust mark of the leaf.
+type TrustMarkResult struct {
+	Type      string `json:"type"`
+	Issuer    string `json:"issuer,omitempty"`
+	Valid     bool   `json:"valid"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ChainReport explains how an entity was resolved.
+type ChainReport struct {
+	EntityID    string            `json:"entity_id"`
+	TrustAnchor string            `json:"trust_anchor"`
+	Statements  []ChainStatement  `json:"statements"`
+	ExpiresAt   string            `json:"expires_at"`
+	TrustMarks  []TrustMarkResult `json:"trust_marks"`
+	// Failures lists the authority paths that were tried and rejected before
+	// a valid chain was found.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Resolution is the resolved metadata of an entity plus how it was obtained.
+type Resolution struct {
+	EntityType string         `json:"entity_type"`
+	Metadata   map[string]any `json:"metadata"`
+	Report     ChainReport    `json:"report"`
+}
+
+// ResolutionError is returned when no valid trust chain can be built.
+type ResolutionError struct {
+	EntityID string
+	Failures []string
+}
+
+func (e *ResolutionError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("no trust chain for %s", e.EntityID)
+	}
+	return fmt.Sprintf(
+		"no trust chain for %s: %s",
+		e.EntityID,
+		strings.Join(e.Failures, "; "),
+	)
+}
+
+// Resolver builds and verifies trust chains to a set of trust anchors.
+type Resolver struct {
+	HTTPClient         *http.Client
+	TrustAnchors       []TrustAnchor
+	MaxPathLength      int
+	RequiredTrustMarks []string
+	Now                func() time.Time
+}
+
+// NewResolver returns a resolver for the given trust anchors with default
+// limits.
+func NewResolver(anchors []TrustAnchor) *Resolver {
+	return &Resolver{
+		HTTPClient:    &http.Client{Timeout: 30 * time.Second},
+		TrustAnchors:  anchors,
+		MaxPathLength: DefaultMaxPathLength,
+		Now:           time.Now,
+	}
+}
+
+type resolveState struct {
+	failures []string
+	configs  map[string]*EntityStatement
+}
+
+// Resolve builds a trust chain from entityID to one of the trust anchors,
+// applies the chain's metadata policies to the metadata of entityType and
+// checks the leaf's trust marks.
+func (r *Resolver) Resolve(
+	ctx context.Context,
+	entityID string,
+	entityType string,
+) (*Resolution, error) {
+	entityID = strings.TrimRight(strings.TrimSpace(entityID), "/")
+	if len(r.TrustAnchors) == 0 {
+		return nil, errors.New("no trust anchors configured")
+	}
+
+	state := &resolveState{configs: map[string]*EntityStatement{}}
+	leaf, err := r.entityConfiguration(ctx, state, entityID)
+	if err != nil {
+		return nil, &ResolutionError{
+			EntityID: entityID,
+			Failures: []string{fmt.Sprintf("%s: %v", entityID, err)},
+		}
+	}
+
+	var chain []*EntityStatement
+	var anchor *TrustAnchor
+	if anchor = r.anchor(entityID); anchor != nil {
+		if err := r.verifyAnchor(leaf, anchor); err != nil {
+			return nil, &ResolutionError{
+				EntityID: entityID,
+				Failures: []string{fmt.Sprintf("%s: %v", entityID, err)},
+			}
+		}
+	} else {
+		chain, anchor = r.walk(ctx, state, leaf, []string{entityID})
+		if anchor == nil {
+			return nil, &ResolutionError{EntityID: entityID, Failures: state.failures}
+		}
+	}
+
+	metadata, err := r.resolveMetadata(leaf, chain, entityType)
+	if err != nil {
+		return nil, &ResolutionError{
+			EntityID: entityID,
+			Failures: append(state.failures, err.Error()),
+		}
+	}
+
+	statements := append([]*EntityStatement{leaf}, chain...)
+	report := ChainReport{
+		EntityID:    entityID,
+		TrustAnchor: anchor.EntityID,
+		Statements:  make([]ChainStatement, 0, len(statements)),
+		Failures:    state.failures,
+	}
+	expiry := leaf.Expiry()
+	for _, statement := range statements {
+		kind := statementKindSubordinate
+		if statement.IsEntityConfiguration() {
+			kind = statementKindConfig
+		}
+		report.Statements = append(report.Statements, ChainStatement{
+			Kind:      kind,
+			Issuer:    statement.Issuer,
+			Subject:   statement.Subject,
+			ExpiresAt: statement.Expiry().Format(time.RFC3339),
+		})
+		if statement.Expiry().Before(expiry) {
+			expiry = statement.Expiry()
+		}
+	}
+	report.ExpiresAt = expiry.Format(time.RFC3339)
+
+	anchorConfig := statements[len(statements)-1]
+	report.TrustMarks = r.checkTrustMarks(ctx, state, leaf, anchorConfig)
+	if missing := r.missingTrustMarks(report.TrustMarks); len(missing) > 0 {
+		return nil, &ResolutionError{
+			EntityID: entityID,
+			Failures: append(
+				state.failures,
+				"missing valid trust marks: "+strings.Join(missing, ", "),
+			),
+		}
+	}
+
+	return &Resolution{EntityType: entityType, Metadata: metadata, Report: report}, nil
+}
+
+// walk follows the authority hints of current depth-first and returns the
+// subordinate statements up to and including the trust anchor's entity
+// configuration.
+func (r *Resolver) walk(
+	ctx context.Context,
+	state *resolveState,
+	current *EntityStatement,
+	path []string,
+) ([]*EntityStatement, *TrustAnchor) {
+	if len(current.AuthorityHints) == 0 {
+		state.failures = append(
+			state.failures,
+			fmt.Sprintf("%s: no authority_hints", strings.Join(path, " -> ")),
+		)
+		return nil, nil
+	}
+	for _, hint := range current.AuthorityHints {
+		hint = strings.TrimRight(hint, "/")
+		hintPath := append(append([]string{}, path...), hint)
+		fail := func(err error) {
+			state.failures = append(
+				state.failures,
+				fmt.Sprintf("%s: %v", strings.Join(hintPath, " -> "), err),
+			)
+		}
+		if containsString(path, hint) {
+			fail(errors.New("authority loop"))
+			continue
+		}
+		if len(path) > r.maxPathLength() {
+			fail(errors.New("maximum path length exceeded"))
+			continue
+		}
+
+		superior, err := r.entityConfiguration(ctx, state, hint)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		subordinate, err := r.subordinateStatement(ctx, superior, current.Subject)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		// The superior vouches for the keys the subordinate signs with.
+		if _, err := VerifyStatement(current.Raw, subordinate.JWKS, r.now()); err != nil {
+			fail(fmt.Errorf("entity configuration of %s not signed with keys from %s: %w",
+				current.Subject, hint, err))
+			continue
+		}
+
+		if anchor := r.anchor(hint); anchor != nil {
+			if err := r.verifyAnchor(superior, anchor); err != nil {
+				fail(err)
+				continue
+			}
+			return []*EntityStatement{subordinate, superior}, anchor
+		}
+
+		rest, anchor := r.walk(ctx, state, superior, hintPath)
+		if anchor != nil {
+			return append([]*EntityStatement{subordinate}, rest...), anchor
+		}
+	}
+	return nil, nil
+}
+
+// resolveMetadata applies the subordinate statement's metadata overrides and
+// the merged metadata policies of the chain to the leaf metadata.
+func (r *Resolver) resolveMetadata(
+	leaf *EntityStatement,
+	chain []*EntityStatement,
+	entityType string,
+) (map[string]any, error) {
+	metadata := map[string]any{}
+	for key, value := range leaf.MetadataFor(entityType) {
+		metadata[key] = value
+	}
+	if len(chain) > 0 {
+		for key, value := range chain[0].MetadataFor(entityType) {
+			metadata[key] = value
+		}
+	}
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("%s publishes no %s metadata", leaf.Subject, entityType)
+	}
+
+	// Policies are merged from the trust anchor down to the leaf's superior.
+	var policies []MetadataPolicy
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].IsEntityConfiguration() {
+			continue
+		}
+		if policy := chain[i].MetadataPolicy[entityType]; policy != nil {
+			policies = append(policies, MetadataPolicy(policy))
+		}
+	}
+	merged, err := MergePolicies(policies...)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyPolicy(metadata, merged)
+}
+
+func (r *Resolver) checkTrustMarks(
+	ctx context.Context,
+	state *resolveState,
+	leaf *EntityStatement,
+	anchor *EntityStatement,
+) []TrustMarkResult {
+	results := make([]TrustMarkResult, 0, len(leaf.TrustMarks))
+	for _, claim := range leaf.TrustMarks {
+		result := TrustMarkResult{Type: claim.Type()}
+		if err := r.verifyTrustMark(ctx, state, leaf.Subject, claim, anchor, &result); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Valid = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (r *Resolver) verifyTrustMark(
+	ctx context.Context,
+	state *resolveState,
+	subject string,
+	claim TrustMarkClaim,
+	anchor *EntityStatement,
+	result *TrustMarkResult,
+) error {
+	unverified := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(claim.TrustMark, unverified); err != nil {
+		return err
+	}
+	issuer, _ := unverified["iss"].(string)
+	result.Issuer = issuer
+	if issuer == "" {
+		return errors.New("trust mark has no iss")
+	}
+	allowed, restricted := anchor.TrustMarkIssuers[claim.Type()]
+	if restricted && !containsString(allowed, issuer) {
+		return fmt.Errorf("%s is not a trust mark issuer for %s", issuer, claim.Type())
+	}
+
+	issuerConfig, err := r.entityConfiguration(ctx, state, issuer)
+	if err != nil {
+		return fmt.Errorf("trust mark issuer: %w", err)
+	}
+	claims, err := verifyJWS(claim.TrustMark, TrustMarkType, issuerConfig.JWKS, r.now(), false)
+	if err != nil {
+		return err
+	}
+	if sub, _ := claims["sub"].(string); strings.TrimRight(sub, "/") != subject {
+		return fmt.Errorf("trust mark subject %q does not match %s", sub, subject)
+	}
+	markType, _ := claims["trust_mark_type"].(string)
+	if markType == "" {
+		markType, _ = claims["id"].(string)
+	}
+	if markType != claim.Type() {
+		return fmt.Errorf("trust mark type %q does not match %q", markType, claim.Type())
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = exp.UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+func (r *Resolver) missingTrustMarks(results []TrustMarkResult) []string {
+	var missing []string
+	for _, required := range r.RequiredTrustMarks {
+		found := false
+		for _, result := range results {
+			if result.Valid && result.Type == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// entityConfiguration fetches and self-verifies the entity configuration of
+// entityID.
+func (r *Resolver) entityConfiguration(
+	ctx context.Context,
+	state *resolveState,
+	entityID string,
+) (*EntityStatement, error) {
+	if config, ok := state.configs[entityID]; ok {
+		return config, nil
+	}
+	raw, err := r.fetch(ctx, entityID+wellKnownPath)
+	if err != nil {
+		return nil, err
+	}
+	unverified, err := ParseUnverified(raw)
+	if err != nil {
+		return nil, fmt.Errorf("entity configuration: %w", err)
+	}
+	config, err := VerifyStatement(raw, unverified.JWKS, r.now())
+	if err != nil {
+		return nil, fmt.Errorf("entity configuration signature: %w", err)
+	}
+	if !config.IsEntityConfiguration() || strings.TrimRight(config.Subject, "/") != entityID {
+		return nil, fmt.Errorf(
+			"entity configuration iss/sub %q/%q do not match %s",
+			config.Issuer,
+			config.Subject,
+			entityID,
+		)
+	}
+	state.configs[entityID] = config
+	return config, nil
+}
+
+func (r *Resolver) subordinateStatement(
+	ctx context.Context,
+	superior *EntityStatement,
+	subject string,
+) (*EntityStatement, error) {
+	endpoint := superior.FetchEndpoint()
+	if endpoint == "" {
+		return nil, errors.New("superior has no federation_fetch_endpoint")
+	}
+	fetchURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid federation_fetch_endpoint: %w", err)
+	}
+	query := fetchURL.Query()
+	query.Set("sub", subject)
+	fetchURL.RawQuery = query.Encode()
+
+	raw, err := r.fetch(ctx, fetchURL.String())
+	if err != nil {
+		return nil, err
+	}
+	statement, err := VerifyStatement(raw, superior.JWKS, r.now())
+	if err != nil {
+		return nil, fmt.Errorf("subordinate statement signature: %w", err)
+	}
+	if statement.Issuer != superior.Subject || statement.Subject != subject {
+		return nil, fmt.Errorf(
+			"subordinate statement iss/sub %q/%q do not match %s/%s",
+			statement.Issuer,
+			statement.Subject,
+			superior.Subject,
+			subject,
+		)
+	}
+	return statement, nil
+}
+
+func (r *Resolver) verifyAnchor(config *EntityStatement, anchor *TrustAnchor) error {
+	if anchor.JWKS == nil {
+		return nil
+	}
+	if _, err := VerifyStatement(config.Raw, anchor.JWKS, r.now()); err != nil {
+		return fmt.Errorf(
+			"trust anchor %s not signed with configured keys: %w",
+			anchor.EntityID,
+			err,
+		)
+	}
+	return nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/entity-statement+jwt")
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s returned %d", target, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatementSize))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (r *Resolver) anchor(entityID string) *TrustAnchor {
+	for i := range r.TrustAnchors {
+		if strings.TrimRight(r.TrustAnchors[i].EntityID, "/") == entityID {
+			return &r.TrustAnchors[i]
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) maxPathLength() int {
+	if r.MaxPathLength > 0 {
+		return r.MaxPathLength
+	}
+	return DefaultMaxPathLength
+}
+
+func (r *Resolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var federationTestNow = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+type testEntity struct {
+	name           string
+	key            *ecdsa.PrivateKey
+	authorityHints []string
+	metadata       map[string]any
+	// subordinates maps a subordinate entity ID to extra subordinate
+	// statement claims (metadata_policy, metadata).
+	subordinates     map[string]map[string]any
+	trustMarks       []any
+	trustMarkIssuers map[string][]string
+}
+
+type testFederation struct {
+	t        *testing.T
+	server   *httptest.Server
+	entities map[string]*testEntity
+}
+
+func newTestFederation(t *testing.T) *testFederation {
+	t.Helper()
+
+	fed := &testFederation{t: t, entities: map[string]*testEntity{}}
+	fed.server = httptest.NewServer(http.HandlerFunc(fed.serve))
+	t.Cleanup(fed.server.Close)
+	return fed
+}
+
+func (f *testFederation) id(name string) string {
+	return f.server.URL + "/" + name
+}
+
+func (f *testFederation) add(name string) *testEntity {
+	f.t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(f.t, err)
+	entity := &testEntity{
+		name:         name,
+		key:          key,
+		metadata:     map[string]any{},
+		subordinates: map[string]map[string]any{},
+	}
+	f.entities[name] = entity
+	return entity
+}
+
+func (f *testFederation) jwks(entity *testEntity) map[string]any {
+	f.t.Helper()
+
+	jwk, err := NewJWK(entity.name, &entity.key.PublicKey)
+	require.NoError(f.t, err)
+	return map[string]any{"keys": []any{jwk}}
+}
+
+func (f *testFederation) sign(entity *testEntity, typ string, claims jwt.MapClaims) string {
+	f.t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = entity.name
+	signed, err := token.SignedString(entity.key)
+	require.NoError(f.t, err)
+	return signed
+}
+
+func (f *testFederation) trustMark(issuer *testEntity, subject, markType string) string {
+	return f.sign(issuer, TrustMarkType, jwt.MapClaims{
+		"iss":             f.id(issuer.name),
+		"sub":             f.id(subject),
+		"trust_mark_type": markType,
+		"iat":             federationTestNow.Unix(),
+		"exp":             federationTestNow.Add(time.Hour).Unix(),
+	})
+}
+
+func (f *testFederation) serve(w http.ResponseWriter, r *http.Request) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	entity, ok := f.entities[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	claims := jwt.MapClaims{
+		"iat": federationTestNow.Unix(),
+		"exp": federationTestNow.Add(24 * time.Hour).Unix(),
+	}
+
+	switch "/" + rest {
+	case wellKnownPath:
+		claims["iss"] = f.id(name)
+		claims["sub"] = f.id(name)
+		claims["jwks"] = f.jwks(entity)
+		metadata := map[string]any{}
+		for key, value := range entity.metadata {
+			metadata[key] = value
+		}
+		if len(entity.subordinates) > 0 {
+			metadata[EntityTypeFederationEntity] = map[string]any{
+				"federation_fetch_endpoint": f.id(name) + "/fetch",
+			}
+		}
+		claims["metadata"] = metadata
+		if len(entity.authorityHints) > 0 {
+			hints := make([]string, 0, len(entity.authorityHints))
+			for _, hint := range entity.authorityHints {
+				hints = append(hints, f.id(hint))
+			}
+			claims["authority_hints"] = hints
+		}
+		if len(entity.trustMarks) > 0 {
+			claims["trust_marks"] = entity.trustMarks
+		}
+		if len(entity.trustMarkIssuers) > 0 {
+			claims["trust_mark_issuers"] = entity.trustMarkIssuers
+		}
+	case "/fetch":
+		subject := r.URL.Query().Get("sub")
+		subName := strings.TrimPrefix(subject, f.server.URL+"/")
+		extra, ok := entity.subordinates[subName]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		claims["iss"] = f.id(name)
+		claims["sub"] = subject
+		claims["jwks"] = f.jwks(f.entities[subName])
+		for key, value := range extra {
+			claims[key] = value
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/entity-statement+jwt")
+	_, _ = w.Write([]byte(f.sign(entity, EntityStatementType, claims)))
+}
+
+func (f *testFederation) resolver(anchors ...string) *Resolver {
+	trustAnchors := make([]TrustAnchor, 0, len(anchors))
+	for _, anchor := range anchors {
+		trustAnchors = append(trustAnchors, TrustAnchor{EntityID: f.id(anchor)})
+	}
+	resolver := NewResolver(trustAnchors)
+	resolver.HTTPClient = f.server.Client()
+	resolver.Now = func() time.Time { return federationTestNow }
+	return resolver
+}
+
+// newThreeLevelFederation builds anchor -> intermediate -> issuer, with a
+// trust mark issuer registered at the anchor.
+func newThreeLevelFederation(t *testing.T) *testFederation {
+	fed := newTestFederation(t)
+
+	anchor := fed.add("anchor")
+	intermediate := fed.add("intermediate")
+	issuer := fed.add("issuer")
+	marks := fed.add("marks")
+
+	anchor.subordinates["intermediate"] = map[string]any{
+		"metadata_policy": map[string]any{
+			EntityTypeCredentialIssuer: map[string]any{
+				"credential_signing_alg_values_supported": map[string]any{
+					"subset_of": []any{"ES256", "ES384"},
+				},
+			},
+		},
+	}
+	anchor.subordinates["marks"] = map[string]any{}
+	anchor.trustMarkIssuers = map[string][]string{
+		"https://trust.example/eudi-issuer": {fed.id("marks")},
+	}
+
+	intermediate.authorityHints = []string{"anchor"}
+	intermediate.subordinates["issuer"] = map[string]any{
+		"metadata_policy": map[string]any{
+			EntityTypeCredentialIssuer: map[string]any{
+				"display": map[string]any{"essential": true},
+				"batch_size": map[string]any{
+					"default": float64(10),
+				},
+			},
+		},
+	}
+
+	issuer.authorityHints = []string{"intermediate"}
+	issuer.metadata[EntityTypeCredentialIssuer] = map[string]any{
+		"credential_issuer":                       fed.id("issuer"),
+		"credential_signing_alg_values_supported": []any{"ES256", "RS256"},
+		"display": []any{map[string]any{"name": "Issuer"}},
+	}
+	issuer.trustMarks = []any{
+		map[string]any{
+			"trust_mark_type": "https://trust.example/eudi-issuer",
+			"trust_mark":      fed.trustMark(marks, "issuer", "https://trust.example/eudi-issuer"),
+		},
+	}
+
+	marks.authorityHints = []string{"anchor"}
+	return fed
+}
+
+func TestResolveTrustChain(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+
+	resolution, err := fed.resolver("anchor").Resolve(
+		context.Background(),
+		fed.id("issuer"),
+		EntityTypeCredentialIssuer,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, fed.id("anchor"), resolution.Report.TrustAnchor)
+	require.Len(t, resolution.Report.Statements, 4)
+	require.Equal(t, statementKindConfig, resolution.Report.Statements[0].Kind)
+	require.Equal(t, fed.id("intermediate"), resolution.Report.Statements[1].Issuer)
+	require.Equal(t, fed.id("issuer"), resolution.Report.Statements[1].Subject)
+	require.Equal(t, statementKindSubordinate, resolution.Report.Statements[2].Kind)
+	require.Equal(t, fed.id("anchor"), resolution.Report.Statements[3].Subject)
+	require.Equal(t, "2026-03-21T12:00:00Z", resolution.Report.ExpiresAt)
+	require.Empty(t, resolution.Report.Failures)
+
+	// subset_of from the anchor drops RS256, default from the intermediate
+	// fills batch_size.
+	require.Equal(
+		t,
+		[]any{"ES256"},
+		resolution.Metadata["credential_signing_alg_values_supported"],
+	)
+	require.Equal(t, float64(10), resolution.Metadata["batch_size"])
+
+	require.Len(t, resolution.Report.TrustMarks, 1)
+	require.True(t, resolution.Report.TrustMarks[0].Valid, resolution.Report.TrustMarks[0].Error)
+	require.Equal(t, fed.id("marks"), resolution.Report.TrustMarks[0].Issuer)
+}
+
+func TestResolveTrustChainRequiredTrustMarks(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+	resolver := fed.resolver("anchor")
+	resolver.RequiredTrustMarks = []string{"https://trust.example/eudi-issuer"}
+
+	_, err := resolver.Resolve(context.Background(), fed.id("issuer"), EntityTypeCredentialIssuer)
+	require.NoError(t, err)
+
+	fed.entities["anchor"].trustMarkIssuers = map[string][]string{
+		"https://trust.example/eudi-issuer": {"https://someone-else.example"},
+	}
+	_, err = resolver.Resolve(context.Background(), fed.id("issuer"), EntityTypeCredentialIssuer)
+	require.ErrorContains(t, err, "missing valid trust marks")
+}
+
+func TestResolveTrustChainUnknownAnchor(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+	fed.add("other-anchor")
+
+	_, err := fed.resolver("other-anchor").Resolve(
+		context.Background(),
+		fed.id("issuer"),
+		EntityTypeCredentialIssuer,
+	)
+	var resolutionErr *ResolutionError
+	require.True(t, errors.As(err, &resolutionErr))
+	require.Contains(t, resolutionErr.Error(), "no authority_hints")
+}
+
+func TestResolveTrustChainRejectsKeyNotVouchedBySuperior(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+	stranger := fed.add("stranger")
+	// The intermediate publishes the stranger's keys for the issuer, so the
+	// issuer's own entity configuration no longer matches.
+	fed.entities["intermediate"].subordinates["issuer"]["jwks"] = fed.jwks(stranger)
+
+	resolver := fed.resolver("anchor")
+	_, err := resolver.Resolve(context.Background(), fed.id("issuer"), EntityTypeCredentialIssuer)
+	require.ErrorContains(t, err, "not signed with keys from")
+}
+
+func TestResolveTrustChainPinnedAnchorKeys(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+	stranger := fed.add("stranger")
+
+	jwk, err := NewJWK("anchor", &stranger.key.PublicKey)
+	require.NoError(t, err)
+	resolver := fed.resolver("anchor")
+	resolver.TrustAnchors[0].JWKS = &JWKSet{Keys: []JWK{jwk}}
+
+	_, err = resolver.Resolve(context.Background(), fed.id("issuer"), EntityTypeCredentialIssuer)
+	require.ErrorContains(t, err, "not signed with configured keys")
+}
+
+func TestResolveTrustChainExpiredStatement(t *testing.T) {
+	fed := newThreeLevelFederation(t)
+	resolver := fed.resolver("anchor")
+	resolver.Now = func() time.Time { return federationTestNow.Add(48 * time.Hour) }
+
+	_, err := resolver.Resolve(context.Background(), fed.id("issuer"), EntityTypeCredentialIssuer)
+	require.ErrorContains(t, err, "token is expired")
+}
+
+func TestResolveTrustAnchorItself(t *testing.T) {
+	fed := newTestFederation(t)
+	anchor := fed.add("anchor")
+	anchor.metadata[EntityTypeCredentialIssuer] = map[string]any{
+		"credential_issuer": fed.id("anchor"),
+	}
+
+	resolution, err := fed.resolver("anchor").Resolve(
+		context.Background(),
+		fed.id("anchor")+"/",
+		EntityTypeCredentialIssuer,
+	)
+	require.NoError(t, err)
+	require.Len(t, resolution.Report.Statements, 1)
+	require.Equal(t, fed.id("anchor"), resolution.Metadata["credential_issuer"])
+}
+
+func TestResolveWithoutTrustAnchors(t *testing.T) {
+	_, err := NewResolver(nil).Resolve(context.Background(), "https://issuer.example", "")
+	require.ErrorContains(t, err, "no trust anchors")
+}
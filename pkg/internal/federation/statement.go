@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package federation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	EntityStatementType = "entity-statement+jwt"
+	TrustMarkType       = "trust-mark+jwt"
+
+	EntityTypeCredentialIssuer = "openid_credential_issuer"
+	EntityTypeFederationEntity = "federation_entity"
+)
+
+var signingMethods = []string{
+	"ES256", "ES384", "ES512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"EdDSA",
+}
+
+// EntityStatement holds the claims of an entity configuration (iss == sub)
+// or of a subordinate statement issued by a superior about its subject.
+type EntityStatement struct {
+	Issuer           string                               `json:"iss"`
+	Subject          string                               `json:"sub"`
+	IssuedAt         int64                                `json:"iat"`
+	ExpiresAt        int64                                `json:"exp"`
+	JWKS             *JWKSet                              `json:"jwks,omitempty"`
+	AuthorityHints   []string                             `json:"authority_hints,omitempty"`
+	Metadata         map[string]map[string]any            `json:"metadata,omitempty"`
+	MetadataPolicy   map[string]map[string]map[string]any `json:"metadata_policy,omitempty"`
+	TrustMarks       []TrustMarkClaim                     `json:"trust_marks,omitempty"`
+	TrustMarkIssuers map[string][]string                  `json:"trust_mark_issuers,omitempty"`
+
+	// Raw is the compact JWS the statement was decoded from.
+	Raw string `json:"-"`
+}
+
+// TrustMarkClaim is an entry of the trust_marks claim. Older drafts name the
+// trust mark type "id".
+type TrustMarkClaim struct {
+	TrustMarkType string `json:"trust_mark_type,omitempty"`
+	ID            string `json:"id,omitempty"`
+	TrustMark     string `json:"trust_mark"`
+}
+
+// Type returns the trust mark type, falling back to the legacy id.
+func (c TrustMarkClaim) Type() string {
+	if c.TrustMarkType != "" {
+		return c.TrustMarkType
+	}
+	return c.ID
+}
+
+// IsEntityConfiguration reports whether the statement is self-issued.
+func (s *EntityStatement) IsEntityConfiguration() bool {
+	return s.Issuer != "" && s.Issuer == s.Subject
+}
+
+// Expiry returns exp as a time.
+func (s *EntityStatement) Expiry() time.Time {
+	return time.Unix(s.ExpiresAt, 0).UTC()
+}
+
+// MetadataFor returns the metadata published for entityType.
+func (s *EntityStatement) MetadataFor(entityType string) map[string]any {
+	if s.Metadata == nil {
+		return nil
+	}
+	return s.Metadata[entityType]
+}
+
+// FetchEndpoint returns the federation_fetch_endpoint of a superior.
+func (s *EntityStatement) FetchEndpoint() string {
+	endpoint, _ := s.MetadataFor(EntityTypeFederationEntity)["federation_fetch_endpoint"].(string)
+	return endpoint
+}
+
+// ParseUnverified decodes the statement without checking its signature. It is
+// only used to read the jwks of an entity configuration before verifying it
+// with those same keys.
+func ParseUnverified(raw string) (*EntityStatement, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, claims); err != nil {
+		return nil, err
+	}
+	return statementFromClaims(raw, claims)
+}
+
+// VerifyStatement checks the signature of raw against jwks, the typ header and
+// the exp claim, and decodes the claims.
+func VerifyStatement(raw string, jwks *JWKSet, now time.Time) (*EntityStatement, error) {
+	claims, err := verifyJWS(raw, EntityStatementType, jwks, now, true)
+	if err != nil {
+		return nil, err
+	}
+	return statementFromClaims(raw, claims)
+}
+
+// verifyJWS checks the signature, typ header and exp of a federation JWT.
+// Entity statements must expire; trust marks may be issued without exp.
+func verifyJWS(
+	raw string,
+	typ string,
+	jwks *JWKSet,
+	now time.Time,
+	requireExp bool,
+) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	}
+	if requireExp {
+		options = append(options, jwt.WithExpirationRequired())
+	}
+	keyFunc := func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := jwks.Find(kid)
+		if err != nil {
+			return nil, err
+		}
+		return key.PublicKey()
+	}
+	token, err := jwt.NewParser(options...).ParseWithClaims(raw, claims, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if got, _ := token.Header["typ"].(string); got != typ {
+		return nil, fmt.Errorf("unexpected typ %q, want %q", got, typ)
+	}
+	return claims, nil
+}
+
+func statementFromClaims(raw string, claims jwt.MapClaims) (*EntityStatement, error) {
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	statement := &EntityStatement{}
+	if err := json.Unmarshal(encoded, statement); err != nil {
+		return nil, fmt.Errorf("invalid entity statement claims: %w", err)
+	}
+	if statement.Issuer == "" || statement.Subject == "" {
+		return nil, errors.New("entity statement is missing iss or sub")
+	}
+	statement.Raw = raw
+	return statement, nil
+}
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package idempotency stores the Idempotency-Key of run-starting requests
+// with the response they produced, so that a retried request gets the
+// original response instead of starting another run.
+package idempotency
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection holds one record per caller and key.
+const Collection = "idempotency_keys"
+
+const (
+	// Header is the request header carrying the key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from a stored key.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	MaxKeyLength = 255
+	// MaxResponseBytes is the largest response body stored for replay.
+	// Requests with a larger response are not idempotent.
+	MaxResponseBytes = 1 << 20
+)
+
+const (
+	StatusInFlight  = "in_flight"
+	StatusCompleted = "completed"
+)
+
+const (
+	// WindowEnv sets how long a completed key is replayed, as a Go duration.
+	WindowEnv     = "CREDIMI_IDEMPOTENCY_KEY_WINDOW"
+	DefaultWindow = 24 * time.Hour
+
+	// InFlightTimeout is how long a key stays claimed by a request that never
+	// finished, e.g. because the server restarted, before a retry may claim it.
+	InFlightTimeout = 15 * time.Minute
+)
+
+// Window returns how long completed keys are kept.
+func Window() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(WindowEnv))
+	if raw == "" {
+		return DefaultWindow
+	}
+	window, err := time.ParseDuration(raw)
+	if err != nil || window <= 0 {
+		log.Printf("[WARN] Invalid %s value %q (using %s)", WindowEnv, raw, DefaultWindow)
+		return DefaultWindow
+	}
+	return window
+}
+
+// Response is a stored response, replayed on retries.
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Claim reserves key for caller. It returns the new in-flight record, or the
+// record of an earlier request with the same key when that one is still in
+// flight or completed within the window. Expired records are replaced.
+func Claim(app core.App, caller, key string, now time.Time) (*core.Record, *core.Record, error) {
+	var claimed, existing *core.Record
+	err := app.RunInTransaction(func(txApp core.App) error {
+		record, err := find(txApp, caller, key)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return err
+		case record.GetDateTime("expires").Time().After(now):
+			existing = record
+			return nil
+		default:
+			if err := txApp.Delete(record); err != nil {
+				return err
+			}
+		}
+
+		collection, err := txApp.FindCollectionByNameOrId(Collection)
+		if err != nil {
+			return err
+		}
+		claimed = core.NewRecord(collection)
+		claimed.Set("caller", caller)
+		claimed.Set("key", key)
+		claimed.Set("status", StatusInFlight)
+		claimed.Set("expires", now.Add(InFlightTimeout))
+		return txApp.Save(claimed)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return claimed, existing, nil
+}
+
+// Complete stores the response of the request holding record, together with
+// the ticket and workflow ids it returned, for the configured window.
+func Complete(
+	app core.App,
+	record *core.Record,
+	fingerprint string,
+	resp Response,
+	now time.Time,
+) error {
+	record.Set("status", StatusCompleted)
+	record.Set("fingerprint", fingerprint)
+	record.Set("response_status", resp.Status)
+	record.Set("response_content_type", resp.ContentType)
+	record.Set("response_body", string(resp.Body))
+	record.Set("expires", now.Add(Window()))
+
+	var refs struct {
+		TicketID   string `json:"ticket_id"`
+		WorkflowID string `json:"workflow_id"`
+		RunID      string `json:"run_id"`
+	}
+	if json.Unmarshal(resp.Body, &refs) == nil {
+		record.Set("ticket_id", refs.TicketID)
+		record.Set("workflow_id", refs.WorkflowID)
+		record.Set("run_id", refs.RunID)
+	}
+	return app.Save(record)
+}
+
+// Release frees the key of a request that failed, so it can be retried.
+func Release(app core.App, record *core.Record) error {
+	return app.Delete(record)
+}
+
+// Reload returns the current state of record, or sql.ErrNoRows once the
+// request holding it has released it.
+func Reload(app core.App, record *core.Record) (*core.Record, error) {
+	return app.FindRecordById(Collection, record.Id)
+}
+
+// StoredResponse returns the response stored by Complete.
+func StoredResponse(record *core.Record) Response {
+	return Response{
+		Status:      record.GetInt("response_status"),
+		ContentType: record.GetString("response_content_type"),
+		Body:        []byte(record.GetString("response_body")),
+	}
+}
+
+// DeleteExpired removes the keys expired at now.
+func DeleteExpired(app core.App, now time.Time) (int64, error) {
+	expires, err := types.ParseDateTime(now)
+	if err != nil {
+		return 0, err
+	}
+	result, err := app.DB().
+		Delete(Collection, dbx.NewExp("expires <= {:now}", dbx.Params{"now": expires.String()})).
+		Execute()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func find(app core.App, caller, key string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		Collection,
+		"caller = {:caller} && key = {:key}",
+		dbx.Params{"caller": caller, "key": key},
+	)
+}
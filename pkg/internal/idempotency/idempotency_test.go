@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package idempotency
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+const idempotencyTestDataDir = "../../../test_pb_data"
+
+func newIdempotencyTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app, err := tests.NewTestApp(idempotencyTestDataDir)
+	require.NoError(t, err)
+	t.Cleanup(app.Cleanup)
+	return app
+}
+
+func TestWindow(t *testing.T) {
+	t.Setenv(WindowEnv, "")
+	require.Equal(t, DefaultWindow, Window())
+
+	t.Setenv(WindowEnv, "90m")
+	require.Equal(t, 90*time.Minute, Window())
+
+	t.Setenv(WindowEnv, "soon")
+	require.Equal(t, DefaultWindow, Window())
+
+	t.Setenv(WindowEnv, "-1h")
+	require.Equal(t, DefaultWindow, Window())
+}
+
+func TestClaimCompleteAndReplay(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	t.Setenv(WindowEnv, "1h")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	claimed, existing, err := Claim(app, "user-1", "ci-42", now)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+	require.Equal(t, StatusInFlight, claimed.GetString("status"))
+	require.Equal(t, now.Add(InFlightTimeout), claimed.GetDateTime("expires").Time())
+
+	claimed2, existing, err := Claim(app, "user-1", "ci-42", now.Add(time.Second))
+	require.NoError(t, err)
+	require.Nil(t, claimed2)
+	require.Equal(t, claimed.Id, existing.Id)
+
+	other, existing, err := Claim(app, "user-2", "ci-42", now)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+	require.NotNil(t, other)
+
+	body := []byte(`{"ticket_id":"ticket-1","workflow_id":"wf-1","run_id":"run-1"}`)
+	require.NoError(t, Complete(app, claimed, "abc", Response{
+		Status:      http.StatusOK,
+		ContentType: "application/json",
+		Body:        body,
+	}, now))
+
+	reloaded, err := Reload(app, claimed)
+	require.NoError(t, err)
+	require.Equal(t, StatusCompleted, reloaded.GetString("status"))
+	require.Equal(t, "abc", reloaded.GetString("fingerprint"))
+	require.Equal(t, "ticket-1", reloaded.GetString("ticket_id"))
+	require.Equal(t, "wf-1", reloaded.GetString("workflow_id"))
+	require.Equal(t, "run-1", reloaded.GetString("run_id"))
+	require.Equal(t, now.Add(time.Hour), reloaded.GetDateTime("expires").Time())
+	require.Equal(t, Response{
+		Status:      http.StatusOK,
+		ContentType: "application/json",
+		Body:        body,
+	}, StoredResponse(reloaded))
+}
+
+func TestClaimReplacesExpiredKeys(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	abandoned, _, err := Claim(app, "user-1", "ci-42", now)
+	require.NoError(t, err)
+
+	claimed, existing, err := Claim(app, "user-1", "ci-42", now.Add(InFlightTimeout))
+	require.NoError(t, err)
+	require.Nil(t, existing)
+	require.NotEqual(t, abandoned.Id, claimed.Id)
+
+	_, err = Reload(app, abandoned)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestReleaseFreesKey(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	claimed, _, err := Claim(app, "user-1", "ci-42", now)
+	require.NoError(t, err)
+	require.NoError(t, Release(app, claimed))
+
+	_, err = Reload(app, claimed)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	claimed, existing, err := Claim(app, "user-1", "ci-42", now)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+	require.NotNil(t, claimed)
+}
+
+func TestDeleteExpired(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_, _, err := Claim(app, "user-1", "old", now)
+	require.NoError(t, err)
+	fresh, _, err := Claim(app, "user-1", "fresh", now.Add(time.Hour))
+	require.NoError(t, err)
+
+	deleted, err := DeleteExpired(app, now.Add(InFlightTimeout))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = Reload(app, fresh)
+	require.NoError(t, err)
+}
@@ -0,0 +1,380 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package issuerdrift computes structured differences between two snapshots
+// of OpenID4VCI credential issuer metadata.
+package issuerdrift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const credentialConfigurationsKey = "credential_configurations_supported"
+
+// Category groups a change by the part of the metadata it touches.
+type Category string
+
+const (
+	CategoryCredentialConfiguration Category = "credential_configuration"
+	CategoryEndpoint                Category = "endpoint"
+	CategoryAlgorithm               Category = "algorithm"
+	CategoryMetadata                Category = "metadata"
+)
+
+// Kind tells whether a value was added, removed or modified.
+type Kind string
+
+const (
+	KindAdded    Kind = "added"
+	KindRemoved  Kind = "removed"
+	KindModified Kind = "modified"
+)
+
+// Change is a single difference between two metadata snapshots. Path is the
+// slash separated location of the value; for algorithm changes it is the
+// list holding the algorithm, and Old or New is the algorithm itself.
+type Change struct {
+	Category Category `json:"category"`
+	Kind     Kind     `json:"kind"`
+	Path     string   `json:"path"`
+	Old      any      `json:"old,omitempty"`
+	New      any      `json:"new,omitempty"`
+	Breaking bool     `json:"breaking"`
+}
+
+// Diff lists the changes between two snapshots. Breaking is set when at
+// least one change can break wallets relying on the previous metadata.
+type Diff struct {
+	Changes  []Change `json:"changes"`
+	Breaking bool     `json:"breaking"`
+}
+
+// Empty reports whether the snapshots are equivalent.
+func (d Diff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Summary is a one line description of the diff, e.g. for notifications.
+func (d Diff) Summary() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	breaking := 0
+	for _, change := range d.Changes {
+		if change.Breaking {
+			breaking++
+		}
+	}
+	return fmt.Sprintf("%d change(s), %d breaking", len(d.Changes), breaking)
+}
+
+// endpointKeys are the metadata parameters wallets call or trust directly.
+var endpointKeys = map[string]bool{
+	"credential_issuer":            true,
+	"authorization_servers":        true,
+	"credential_endpoint":          true,
+	"nonce_endpoint":               true,
+	"deferred_credential_endpoint": true,
+	"notification_endpoint":        true,
+	"batch_credential_endpoint":    true,
+}
+
+// ignoredKeys change on every fetch without changing the metadata content.
+var ignoredKeys = map[string]bool{
+	"signed_metadata": true,
+}
+
+// Compute returns the changes needed to go from previous to current.
+func Compute(previous, current map[string]any) Diff {
+	var changes []Change
+
+	for _, key := range unionKeys(previous, current) {
+		if ignoredKeys[key] {
+			continue
+		}
+		oldValue, inOld := previous[key]
+		newValue, inNew := current[key]
+		switch {
+		case key == credentialConfigurationsKey:
+			changes = append(
+				changes,
+				diffCredentialConfigurations(asMap(oldValue), asMap(newValue))...,
+			)
+		case endpointKeys[key]:
+			if change, ok := diffValue(CategoryEndpoint, key, oldValue, inOld, newValue, inNew); ok {
+				// Moving or dropping an endpoint breaks existing wallets,
+				// advertising a new one does not.
+				change.Breaking = change.Kind != KindAdded
+				changes = append(changes, change)
+			}
+		case isAlgorithmKey(key):
+			changes = append(changes, diffAlgorithms(key, oldValue, newValue)...)
+		default:
+			if change, ok := diffValue(CategoryMetadata, key, oldValue, inOld, newValue, inNew); ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+
+	diff := Diff{Changes: changes}
+	for _, change := range changes {
+		if change.Breaking {
+			diff.Breaking = true
+			break
+		}
+	}
+	return diff
+}
+
+// Hash returns a stable digest of the metadata, ignoring the keys that
+// change on every fetch.
+func Hash(metadata map[string]any) (string, error) {
+	filtered := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		if !ignoredKeys[key] {
+			filtered[key] = value
+		}
+	}
+	// encoding/json sorts map keys, which makes the encoding canonical.
+	encoded, err := json.Marshal(filtered)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func diffCredentialConfigurations(previous, current map[string]any) []Change {
+	var changes []Change
+	for _, id := range unionKeys(previous, current) {
+		path := credentialConfigurationsKey + "/" + id
+		oldConfig, inOld := previous[id]
+		newConfig, inNew := current[id]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{
+				Category: CategoryCredentialConfiguration,
+				Kind:     KindAdded,
+				Path:     path,
+			})
+		case !inNew:
+			changes = append(changes, Change{
+				Category: CategoryCredentialConfiguration,
+				Kind:     KindRemoved,
+				Path:     path,
+				Breaking: true,
+			})
+		default:
+			changes = append(changes, diffCredentialConfiguration(
+				path,
+				asMap(oldConfig),
+				asMap(newConfig),
+			)...)
+		}
+	}
+	return changes
+}
+
+func diffCredentialConfiguration(path string, previous, current map[string]any) []Change {
+	var changes []Change
+	for _, key := range unionKeys(previous, current) {
+		oldValue, inOld := previous[key]
+		newValue, inNew := current[key]
+		fieldPath := path + "/" + key
+		switch {
+		case isAlgorithmKey(key):
+			changes = append(changes, diffAlgorithms(fieldPath, oldValue, newValue)...)
+		case key == "proof_types_supported":
+			changes = append(changes, diffProofTypes(
+				fieldPath,
+				asMap(oldValue),
+				asMap(newValue),
+			)...)
+		default:
+			change, ok := diffValue(
+				CategoryCredentialConfiguration,
+				fieldPath,
+				oldValue,
+				inOld,
+				newValue,
+				inNew,
+			)
+			if ok {
+				// A wallet cannot keep requesting a credential whose
+				// format or type changed under the same identifier.
+				change.Breaking = key == "format" || key == "vct" || key == "doctype"
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+func diffProofTypes(path string, previous, current map[string]any) []Change {
+	var changes []Change
+	for _, proofType := range unionKeys(previous, current) {
+		proofPath := path + "/" + proofType
+		oldProof, inOld := previous[proofType]
+		newProof, inNew := current[proofType]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{
+				Category: CategoryAlgorithm,
+				Kind:     KindAdded,
+				Path:     proofPath,
+			})
+		case !inNew:
+			changes = append(changes, Change{
+				Category: CategoryAlgorithm,
+				Kind:     KindRemoved,
+				Path:     proofPath,
+				Breaking: true,
+			})
+		default:
+			oldFields := asMap(oldProof)
+			newFields := asMap(newProof)
+			for _, key := range unionKeys(oldFields, newFields) {
+				if isAlgorithmKey(key) {
+					changes = append(changes, diffAlgorithms(
+						proofPath+"/"+key,
+						oldFields[key],
+						newFields[key],
+					)...)
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// diffAlgorithms compares two lists of supported values member by member.
+// Dropping a value is breaking because wallets may depend on it.
+func diffAlgorithms(path string, previous, current any) []Change {
+	oldValues := asStringSet(previous)
+	newValues := asStringSet(current)
+
+	var changes []Change
+	for _, value := range sortedKeys(oldValues) {
+		if !newValues[value] {
+			changes = append(changes, Change{
+				Category: CategoryAlgorithm,
+				Kind:     KindRemoved,
+				Path:     path,
+				Old:      value,
+				Breaking: true,
+			})
+		}
+	}
+	for _, value := range sortedKeys(newValues) {
+		if !oldValues[value] {
+			changes = append(changes, Change{
+				Category: CategoryAlgorithm,
+				Kind:     KindAdded,
+				Path:     path,
+				New:      value,
+			})
+		}
+	}
+	return changes
+}
+
+func diffValue(
+	category Category,
+	path string,
+	oldValue any,
+	inOld bool,
+	newValue any,
+	inNew bool,
+) (Change, bool) {
+	switch {
+	case !inOld && !inNew:
+		return Change{}, false
+	case !inOld:
+		return Change{Category: category, Kind: KindAdded, Path: path, New: newValue}, true
+	case !inNew:
+		return Change{Category: category, Kind: KindRemoved, Path: path, Old: oldValue}, true
+	case equal(oldValue, newValue):
+		return Change{}, false
+	default:
+		return Change{
+			Category: category,
+			Kind:     KindModified,
+			Path:     path,
+			Old:      oldValue,
+			New:      newValue,
+		}, true
+	}
+}
+
+// isAlgorithmKey matches parameters such as
+// credential_signing_alg_values_supported or
+// cryptographic_binding_methods_supported.
+func isAlgorithmKey(key string) bool {
+	return strings.HasSuffix(key, "_alg_values_supported") ||
+		strings.HasSuffix(key, "_enc_values_supported") ||
+		key == "cryptographic_binding_methods_supported"
+}
+
+// equal compares JSON values, treating numbers of any Go type alike.
+func equal(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(left) == string(right)
+}
+
+func asMap(value any) map[string]any {
+	m, _ := value.(map[string]any)
+	return m
+}
+
+func asStringSet(value any) map[string]bool {
+	set := map[string]bool{}
+	switch values := value.(type) {
+	case []any:
+		for _, v := range values {
+			set[fmt.Sprint(v)] = true
+		}
+	case []string:
+		for _, v := range values {
+			set[v] = true
+		}
+	}
+	return set
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return sortedKeys(keys)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
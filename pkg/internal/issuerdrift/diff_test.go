@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package issuerdrift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadata() map[string]any {
+	return map[string]any{
+		"credential_issuer":   "https://issuer.example",
+		"credential_endpoint": "https://issuer.example/credential",
+		"display":             []any{map[string]any{"name": "Issuer"}},
+		"signed_metadata":     "eyJ...",
+		"credential_configurations_supported": map[string]any{
+			"pid": map[string]any{
+				"format": "dc+sd-jwt",
+				"vct":    "urn:eu.europa.ec.eudi:pid:1",
+				"credential_signing_alg_values_supported": []any{"ES256", "ES384"},
+				"proof_types_supported": map[string]any{
+					"jwt": map[string]any{
+						"proof_signing_alg_values_supported": []any{"ES256"},
+					},
+				},
+			},
+			"mdl": map[string]any{
+				"format":  "mso_mdoc",
+				"doctype": "org.iso.18013.5.1.mDL",
+			},
+		},
+	}
+}
+
+func TestComputeNoChanges(t *testing.T) {
+	current := testMetadata()
+	current["signed_metadata"] = "eyJ.other"
+
+	diff := Compute(testMetadata(), current)
+	require.True(t, diff.Empty())
+	require.False(t, diff.Breaking)
+	require.Equal(t, "no changes", diff.Summary())
+}
+
+func TestComputeCredentialConfigurations(t *testing.T) {
+	current := testMetadata()
+	configs := current["credential_configurations_supported"].(map[string]any)
+	delete(configs, "mdl")
+	configs["ehic"] = map[string]any{"format": "dc+sd-jwt"}
+
+	diff := Compute(testMetadata(), current)
+	require.True(t, diff.Breaking)
+	require.Equal(t, []Change{
+		{
+			Category: CategoryCredentialConfiguration,
+			Kind:     KindAdded,
+			Path:     "credential_configurations_supported/ehic",
+		},
+		{
+			Category: CategoryCredentialConfiguration,
+			Kind:     KindRemoved,
+			Path:     "credential_configurations_supported/mdl",
+			Breaking: true,
+		},
+	}, diff.Changes)
+	require.Equal(t, "2 change(s), 1 breaking", diff.Summary())
+}
+
+func TestComputeEndpoints(t *testing.T) {
+	current := testMetadata()
+	current["credential_endpoint"] = "https://issuer.example/v2/credential"
+	current["nonce_endpoint"] = "https://issuer.example/nonce"
+
+	diff := Compute(testMetadata(), current)
+	require.True(t, diff.Breaking)
+	require.Len(t, diff.Changes, 2)
+	require.Equal(t, Change{
+		Category: CategoryEndpoint,
+		Kind:     KindModified,
+		Path:     "credential_endpoint",
+		Old:      "https://issuer.example/credential",
+		New:      "https://issuer.example/v2/credential",
+		Breaking: true,
+	}, diff.Changes[0])
+	require.Equal(t, CategoryEndpoint, diff.Changes[1].Category)
+	require.Equal(t, KindAdded, diff.Changes[1].Kind)
+	require.False(t, diff.Changes[1].Breaking)
+}
+
+func TestComputeAlgorithms(t *testing.T) {
+	current := testMetadata()
+	pid := current["credential_configurations_supported"].(map[string]any)["pid"].(map[string]any)
+	pid["credential_signing_alg_values_supported"] = []any{"ES256", "EdDSA"}
+	pid["proof_types_supported"].(map[string]any)["jwt"] = map[string]any{
+		"proof_signing_alg_values_supported": []any{"ES256", "ES512"},
+	}
+
+	diff := Compute(testMetadata(), current)
+	require.True(t, diff.Breaking)
+
+	algPath := "credential_configurations_supported/pid/credential_signing_alg_values_supported"
+	proofPath := "credential_configurations_supported/pid/proof_types_supported/jwt/" +
+		"proof_signing_alg_values_supported"
+	require.Equal(t, []Change{
+		{Category: CategoryAlgorithm, Kind: KindAdded, Path: algPath, New: "EdDSA"},
+		{Category: CategoryAlgorithm, Kind: KindRemoved, Path: algPath, Old: "ES384", Breaking: true},
+		{Category: CategoryAlgorithm, Kind: KindAdded, Path: proofPath, New: "ES512"},
+	}, diff.Changes)
+}
+
+func TestComputeFormatAndMetadata(t *testing.T) {
+	current := testMetadata()
+	mdl := current["credential_configurations_supported"].(map[string]any)["mdl"].(map[string]any)
+	mdl["format"] = "dc+sd-jwt"
+	current["display"] = []any{map[string]any{"name": "Renamed"}}
+
+	diff := Compute(testMetadata(), current)
+	require.True(t, diff.Breaking)
+	require.Len(t, diff.Changes, 2)
+	require.Equal(t, "credential_configurations_supported/mdl/format", diff.Changes[0].Path)
+	require.True(t, diff.Changes[0].Breaking)
+	require.Equal(t, "display", diff.Changes[1].Path)
+	require.Equal(t, CategoryMetadata, diff.Changes[1].Category)
+	require.False(t, diff.Changes[1].Breaking)
+}
+
+func TestComputeNumbers(t *testing.T) {
+	diff := Compute(
+		map[string]any{"batch_credential_issuance": map[string]any{"batch_size": 2}},
+		map[string]any{"batch_credential_issuance": map[string]any{"batch_size": float64(2)}},
+	)
+	require.True(t, diff.Empty())
+}
+
+func TestHash(t *testing.T) {
+	first, err := Hash(testMetadata())
+	require.NoError(t, err)
+
+	other := testMetadata()
+	other["signed_metadata"] = "eyJ.other"
+	second, err := Hash(other)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	other["credential_endpoint"] = "https://issuer.example/v2/credential"
+	third, err := Hash(other)
+	require.NoError(t, err)
+	require.NotEqual(t, first, third)
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+)
+
+// NewWorkerInterceptor records execution counts and latencies for every
+// activity run by a worker, labelled by activity type and outcome.
+func NewWorkerInterceptor() interceptor.WorkerInterceptor {
+	return &workerInterceptor{}
+}
+
+type workerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (w *workerInterceptor) InterceptActivity(
+	_ context.Context,
+	next interceptor.ActivityInboundInterceptor,
+) interceptor.ActivityInboundInterceptor {
+	i := &activityInboundInterceptor{}
+	i.Next = next
+	return i
+}
+
+type activityInboundInterceptor struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (a *activityInboundInterceptor) ExecuteActivity(
+	ctx context.Context,
+	in *interceptor.ExecuteActivityInput,
+) (any, error) {
+	start := time.Now()
+	result, err := a.Next.ExecuteActivity(ctx, in)
+
+	handler := activity.GetMetricsHandler(ctx).WithTags(map[string]string{
+		"outcome": Outcome(err),
+	})
+	handler.Counter(ActivityExecutionsTotal).Inc(1)
+	handler.Timer(ActivityDurationSeconds).Record(time.Since(start))
+
+	return result, err
+}
+
+// Outcome classifies a Temporal error into the outcome label used by
+// pipeline, step and activity metrics.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case temporal.IsCanceledError(err):
+		return OutcomeCanceled
+	case temporal.IsTimeoutError(err):
+		return OutcomeTimeout
+	default:
+		return OutcomeFailed
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package metrics exposes Credimi's Prometheus/OpenMetrics instrumentation.
+// It owns a single registry shared by the HTTP API, the Temporal clients and
+// workers (through TemporalHandler) and the /metrics endpoint.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	APIMetricsMiddlewareID = "credimiAPIMetrics"
+
+	// Metric names emitted from workflow and activity code through the Temporal
+	// metrics handler, so that replayed workflow tasks are not double counted.
+	PipelineRunsTotal             = "credimi_pipeline_runs_total"
+	PipelineDurationSeconds       = "credimi_pipeline_duration_seconds"
+	PipelineStepsTotal            = "credimi_pipeline_steps_total"
+	PipelineStepDurationSeconds   = "credimi_pipeline_step_duration_seconds"
+	ActivityExecutionsTotal       = "credimi_activity_executions_total"
+	ActivityDurationSeconds       = "credimi_activity_duration_seconds"
+	SemaphoreQueueDepth           = "credimi_semaphore_queue_depth"
+	SemaphoreSlotsUsed            = "credimi_semaphore_slots_used"
+	SemaphoreCapacity             = "credimi_semaphore_capacity"
+	SemaphoreStartingTickets      = "credimi_semaphore_starting_tickets"
+	SemaphoreStuckStartingTickets = "credimi_semaphore_stuck_starting_tickets"
+	SemaphorePaused               = "credimi_semaphore_paused"
+
+	// Metric names registered directly on Registry.
+	WorkerRestartsTotal       = "credimi_worker_restarts_total"
+	APIRequestsTotal          = "credimi_api_requests_total"
+	APIRequestDurationSeconds = "credimi_api_request_duration_seconds"
+
+	OutcomeSuccess  = "success"
+	OutcomeFailed   = "failed"
+	OutcomeCanceled = "canceled"
+	OutcomeTimeout  = "timeout"
+
+	unmatchedRoutePattern = "unmatched"
+)
+
+// DurationBuckets covers both sub-second API calls and pipelines running for hours.
+var DurationBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+	30, 60, 120, 300, 600, 1800, 3600, 7200,
+}
+
+var (
+	// Registry is the registry served on /metrics.
+	Registry = prometheus.NewRegistry()
+
+	workerRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: WorkerRestartsTotal,
+			Help: "Temporal worker restarts after a retryable error, by task queue.",
+		},
+		[]string{"task_queue"},
+	)
+
+	apiRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: APIRequestsTotal,
+			Help: "HTTP API requests handled, by method, route and status code.",
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    APIRequestDurationSeconds,
+			Help:    "HTTP API request latency, by method and route.",
+			Buckets: DurationBuckets,
+		},
+		[]string{"method", "route"},
+	)
+
+	temporalHandler = NewTemporalHandler(Registry)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		workerRestarts,
+		apiRequests,
+		apiRequestDuration,
+	)
+}
+
+// Handler returns the OpenMetrics/Prometheus text exposition handler for Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+		Registry:          Registry,
+	})
+}
+
+// Temporal returns the shared Temporal metrics handler backed by Registry.
+func Temporal() *TemporalHandler {
+	return temporalHandler
+}
+
+// IncWorkerRestart counts a worker restart for the given task queue.
+func IncWorkerRestart(taskQueue string) {
+	workerRestarts.WithLabelValues(taskQueue).Inc()
+}
+
+// APIMiddleware records request counts and latencies for every routed API call.
+// Routes are labelled by their registered pattern to keep cardinality bounded.
+func APIMiddleware() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id: APIMetricsMiddlewareID,
+		Func: func(e *core.RequestEvent) error {
+			start := time.Now()
+			err := e.Next()
+			ObserveAPIRequest(e, err, time.Since(start))
+			return err
+		},
+	}
+}
+
+// ObserveAPIRequest records a single API request outcome.
+func ObserveAPIRequest(e *core.RequestEvent, err error, elapsed time.Duration) {
+	route := e.Request.Pattern
+	if route == "" {
+		route = unmatchedRoutePattern
+	}
+	status := e.Status()
+	if err != nil {
+		status = statusFromError(err, status)
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	method := e.Request.Method
+	apiRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	apiRequestDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+func statusFromError(err error, fallback int) int {
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) && apiErr.Code > 0 {
+		return apiErr.Code
+	}
+	var routerErr *router.ApiError
+	if errors.As(err, &routerErr) && routerErr.Status > 0 {
+		return routerErr.Status
+	}
+	if fallback >= http.StatusBadRequest {
+		return fallback
+	}
+	return http.StatusInternalServerError
+}
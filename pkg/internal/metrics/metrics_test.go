@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestTemporalHandlerSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	handler := NewTemporalHandler(registry)
+
+	tagged := handler.WithTags(map[string]string{"outcome": "success", "task-queue": "q"})
+	tagged.Counter(PipelineRunsTotal).Inc(2)
+	tagged.Counter(PipelineRunsTotal).Inc(1)
+	tagged.Counter(PipelineRunsTotal).Inc(-1)
+	handler.WithTags(map[string]string{"runner_id": "r1"}).Gauge(SemaphoreQueueDepth).Update(4)
+	handler.Timer("temporal.request.latency").Record(1500 * time.Millisecond)
+
+	expected := `
+# HELP credimi_pipeline_runs_total credimi_pipeline_runs_total reported through the Temporal metrics handler.
+# TYPE credimi_pipeline_runs_total counter
+credimi_pipeline_runs_total{outcome="success",task_queue="q"} 3
+# HELP credimi_semaphore_queue_depth credimi_semaphore_queue_depth reported through the Temporal metrics handler.
+# TYPE credimi_semaphore_queue_depth gauge
+credimi_semaphore_queue_depth{runner_id="r1"} 4
+`
+	require.NoError(t, testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		PipelineRunsTotal,
+		SemaphoreQueueDepth,
+	))
+
+	count, err := testutil.GatherAndCount(registry, "temporal_request_latency")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestTemporalHandlerWithTagsDoesNotMutateParent(t *testing.T) {
+	handler := NewTemporalHandler(prometheus.NewRegistry())
+	child := handler.WithTags(map[string]string{"a": "1"}).(*TemporalHandler)
+
+	require.Empty(t, handler.tags)
+	require.Equal(t, map[string]string{"a": "1"}, child.tags)
+}
+
+func TestOutcome(t *testing.T) {
+	require.Equal(t, OutcomeSuccess, Outcome(nil))
+	require.Equal(t, OutcomeCanceled, Outcome(temporal.NewCanceledError()))
+	require.Equal(t, OutcomeTimeout, Outcome(temporal.NewTimeoutError(0, nil)))
+	require.Equal(t, OutcomeFailed, Outcome(errors.New("boom")))
+}
+
+func TestStatusFromError(t *testing.T) {
+	apiErr := apierror.New(http.StatusNotFound, "test", "missing", "not found")
+	require.Equal(t, http.StatusNotFound, statusFromError(apiErr, 0))
+	require.Equal(
+		t,
+		http.StatusForbidden,
+		statusFromError(router.NewForbiddenError("", nil), 0),
+	)
+	require.Equal(t, http.StatusBadGateway, statusFromError(errors.New("x"), http.StatusBadGateway))
+	require.Equal(t, http.StatusInternalServerError, statusFromError(errors.New("x"), http.StatusOK))
+}
+
+func TestIncWorkerRestart(t *testing.T) {
+	before := testutil.ToFloat64(workerRestarts.WithLabelValues("restart-test-queue"))
+	IncWorkerRestart("restart-test-queue")
+	require.Equal(
+		t,
+		before+1,
+		testutil.ToFloat64(workerRestarts.WithLabelValues("restart-test-queue")),
+	)
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+)
+
+// TemporalHandler adapts Registry to the Temporal SDK metrics handler interface.
+// It is set on every Temporal client, so SDK metrics (task latencies, poller
+// counts, ...) and metrics emitted through workflow.GetMetricsHandler or
+// activity.GetMetricsHandler end up in the same exposition.
+//
+// The SDK creates metrics lazily with free-form tags, so series are created on
+// first use and exposed through an unchecked collector.
+type TemporalHandler struct {
+	tags  map[string]string
+	store *temporalSeriesStore
+}
+
+var _ client.MetricsHandler = (*TemporalHandler)(nil)
+
+type temporalSeriesStore struct {
+	mu     sync.Mutex
+	series map[string]prometheus.Metric
+}
+
+// NewTemporalHandler creates a handler whose series are collected by registerer.
+func NewTemporalHandler(registerer prometheus.Registerer) *TemporalHandler {
+	store := &temporalSeriesStore{series: map[string]prometheus.Metric{}}
+	registerer.MustRegister(store)
+	return &TemporalHandler{tags: map[string]string{}, store: store}
+}
+
+// WithTags implements client.MetricsHandler.
+func (h *TemporalHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for key, value := range h.tags {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[sanitizeMetricName(key)] = value
+	}
+	return &TemporalHandler{tags: merged, store: h.store}
+}
+
+// Counter implements client.MetricsHandler.
+func (h *TemporalHandler) Counter(name string) client.MetricsCounter {
+	counter := h.store.getOrCreate(name, h.tags, func(opts metricOpts) prometheus.Metric {
+		return prometheus.NewCounter(prometheus.CounterOpts(opts))
+	}).(prometheus.Counter)
+	return counterFunc(func(delta int64) {
+		if delta > 0 {
+			counter.Add(float64(delta))
+		}
+	})
+}
+
+// Gauge implements client.MetricsHandler.
+func (h *TemporalHandler) Gauge(name string) client.MetricsGauge {
+	gauge := h.store.getOrCreate(name, h.tags, func(opts metricOpts) prometheus.Metric {
+		return prometheus.NewGauge(prometheus.GaugeOpts(opts))
+	}).(prometheus.Gauge)
+	return gaugeFunc(gauge.Set)
+}
+
+// Timer implements client.MetricsHandler. Durations are recorded in seconds.
+func (h *TemporalHandler) Timer(name string) client.MetricsTimer {
+	histogram := h.store.getOrCreate(name, h.tags, func(opts metricOpts) prometheus.Metric {
+		return prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+			Buckets:     DurationBuckets,
+		})
+	}).(prometheus.Histogram)
+	return timerFunc(func(d time.Duration) {
+		histogram.Observe(d.Seconds())
+	})
+}
+
+// Describe implements prometheus.Collector. Nothing is described up front,
+// which makes the store an unchecked collector.
+func (s *temporalSeriesStore) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (s *temporalSeriesStore) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, metric := range s.series {
+		ch <- metric
+	}
+}
+
+type metricOpts prometheus.Opts
+
+func (s *temporalSeriesStore) getOrCreate(
+	name string,
+	tags map[string]string,
+	build func(metricOpts) prometheus.Metric,
+) prometheus.Metric {
+	name = sanitizeMetricName(name)
+	key := seriesKey(name, tags)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if metric, ok := s.series[key]; ok {
+		return metric
+	}
+	labels := make(prometheus.Labels, len(tags))
+	for label, value := range tags {
+		labels[label] = value
+	}
+	metric := build(metricOpts{
+		Name:        name,
+		Help:        fmt.Sprintf("%s reported through the Temporal metrics handler.", name),
+		ConstLabels: labels,
+	})
+	s.series[key] = metric
+	return metric
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, key := range keys {
+		b.WriteByte('|')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(tags[key])
+	}
+	return b.String()
+}
+
+// sanitizeMetricName maps any character outside [a-zA-Z0-9_] to an underscore,
+// which keeps SDK names such as "temporal_request" untouched.
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+type counterFunc func(int64)
+
+func (f counterFunc) Inc(delta int64) { f(delta) }
+
+type gaugeFunc func(float64)
+
+func (f gaugeFunc) Update(value float64) { f(value) }
+
+type timerFunc func(time.Duration)
+
+func (f timerFunc) Record(d time.Duration) { f(d) }
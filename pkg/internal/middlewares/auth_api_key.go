@@ -10,16 +10,24 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const (
 	RequireAuthOrAPIKeyMiddlewareID        = "requireAuthOrAPIKey"
 	RequireInternalAdminAPIKeyMiddlewareID = "requireInternalAdminAPIKey"
 	RequireInternalAdminOrAuthMiddlewareID = "requireInternalAdminOrAuth"
+	APIKeyPermissionMiddlewareID           = "apiKeyPermission"
+
+	// apiKeyPermissionPriority runs the permission declaration before the
+	// group and route auth middlewares, which have the default priority.
+	apiKeyPermissionPriority = -1
+	apiKeyPermissionStoreKey = "credimi.apiKeyPermission"
+	apiKeyGrantStoreKey      = "credimi.apiKeyGrant"
+	apiKeyIDStoreKey         = "credimi.apiKeyID"
 
 	apiKeyHeaderName         = "Credimi-Api-Key"
 	apiKeyScopeFieldName     = "key_type"
@@ -46,11 +54,9 @@ func RequireAuthOrAPIKey() *hook.Handler[*core.RequestEvent] {
 					)
 				}
 
-				principal, apiErr := authenticateAPIKeyByScope(e.App, apiKey, apiKeyScopeUser)
-				if apiErr != nil {
+				if apiErr := authenticateAPIKeyRequest(e, apiKey, apiKeyScopeUser); apiErr != nil {
 					return apiErr
 				}
-				e.Auth = principal
 
 				return e.Next()
 			}
@@ -78,11 +84,10 @@ func RequireInternalAdminAPIKey() *hook.Handler[*core.RequestEvent] {
 				)
 			}
 
-			principal, apiErr := authenticateAPIKeyByScope(e.App, apiKey, apiKeyScopeInternalAdmin)
+			apiErr := authenticateAPIKeyRequest(e, apiKey, apiKeyScopeInternalAdmin)
 			if apiErr != nil {
 				return apiErr
 			}
-			e.Auth = principal
 
 			return e.Next()
 		},
@@ -96,12 +101,15 @@ func RequireInternalAdminOrAuth() *hook.Handler[*core.RequestEvent] {
 		Func: func(e *core.RequestEvent) error {
 			apiKey := strings.TrimSpace(e.Request.Header.Get(apiKeyHeaderName))
 			if apiKey != "" {
-				principal, apiErr := authenticateAPIKeyByScope(
+				principal, key, apiErr := authenticateAPIKey(
 					e.App,
 					apiKey,
 					apiKeyScopeInternalAdmin,
 				)
 				if apiErr == nil {
+					if apiErr := authorizeAPIKeyRequest(e, key); apiErr != nil {
+						return apiErr
+					}
 					e.Auth = principal
 					return e.Next()
 				}
@@ -117,28 +125,24 @@ func authenticateAPIKeyByScope(
 	apiKey string,
 	requiredScope string,
 ) (*core.Record, *apierror.APIError) {
-	records, err := app.FindRecordsByFilter("api_keys", "", "", 0, 0)
-	if err != nil {
-		return nil, apierror.New(
-			http.StatusInternalServerError,
-			"request.internal_error",
-			"failed_to_find_api_key_records",
-			err.Error(),
-		)
-	}
+	principal, _, apiErr := authenticateAPIKey(app, apiKey, requiredScope)
+	return principal, apiErr
+}
 
-	matched := findMatchingAPIKeyRecord(records, apiKey)
-	if matched == nil {
-		return nil, apierror.New(
-			http.StatusUnauthorized,
-			"request.validation",
-			"invalid_api_key",
-			"Invalid API key provided",
-		)
+// authenticateAPIKey returns the principal of the key and the api_keys
+// record itself.
+func authenticateAPIKey(
+	app core.App,
+	apiKey string,
+	requiredScope string,
+) (*core.Record, *core.Record, *apierror.APIError) {
+	matched, apiErr := apikey.Find(apikey.CoreStore(app), apiKey, nil, nil)
+	if apiErr != nil {
+		return nil, nil, apiErr
 	}
 
 	if matched.GetBool("revoked") {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusUnauthorized,
 			"request.validation",
 			"revoked_api_key",
@@ -148,7 +152,7 @@ func authenticateAPIKeyByScope(
 
 	expiresAt := matched.GetDateTime("expires_at")
 	if !expiresAt.IsZero() && expiresAt.Time().Before(time.Now().UTC()) {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusUnauthorized,
 			"request.validation",
 			"expired_api_key",
@@ -161,7 +165,7 @@ func authenticateAPIKeyByScope(
 	hasUser := strings.TrimSpace(userID) != ""
 	hasSuperuser := strings.TrimSpace(superuserID) != ""
 	if hasUser == hasSuperuser && (requiredScope != apiKeyScopeInternalAdmin || !hasSuperuser) {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusUnauthorized,
 			"request.validation",
 			"invalid_api_key_owner",
@@ -178,7 +182,7 @@ func authenticateAPIKeyByScope(
 		}
 	}
 	if scope != requiredScope {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusForbidden,
 			"request.validation",
 			"insufficient_api_key_scope",
@@ -202,7 +206,7 @@ func authenticateAPIKeyByScope(
 		}
 	}
 	if err != nil {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusInternalServerError,
 			"request.internal_error",
 			"failed_to_find_principal",
@@ -215,7 +219,7 @@ func authenticateAPIKeyByScope(
 		}
 	}
 	if principal == nil {
-		return nil, apierror.New(
+		return nil, nil, apierror.New(
 			http.StatusUnauthorized,
 			"request.validation",
 			"principal_not_found",
@@ -223,23 +227,8 @@ func authenticateAPIKeyByScope(
 		)
 	}
 
-	return principal, nil
-}
-
-func findMatchingAPIKeyRecord(records []*core.Record, apiKey string) *core.Record {
-	for _, record := range records {
-		if record == nil {
-			continue
-		}
-		hash := record.GetString("key")
-		if hash == "" {
-			continue
-		}
-		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(apiKey)); err == nil {
-			return record
-		}
-	}
-	return nil
+	apikey.TouchLastUsed(apikey.CoreStore(app), matched)
+	return principal, matched, nil
 }
 
 // OptionalAuthOrAPIKey authenticates a Credimi-Api-Key when present,
@@ -256,13 +245,113 @@ func OptionalAuthOrAPIKey() *hook.Handler[*core.RequestEvent] {
 				return e.Next()
 			}
 
-			principal, apiErr := authenticateAPIKeyByScope(e.App, apiKey, apiKeyScopeUser)
-			if apiErr != nil {
+			if apiErr := authenticateAPIKeyRequest(e, apiKey, apiKeyScopeUser); apiErr != nil {
 				return apiErr
 			}
-			e.Auth = principal
 
 			return e.Next()
 		},
 	}
 }
+
+// RequireAPIKeyPermission declares the permission a restricted API key
+// needs for the route. The auth middlewares enforce it; Bearer tokens and
+// unrestricted keys are not affected.
+func RequireAPIKeyPermission(permission apikey.Permission) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       APIKeyPermissionMiddlewareID,
+		Priority: apiKeyPermissionPriority,
+		Func: func(e *core.RequestEvent) error {
+			e.Set(apiKeyPermissionStoreKey, permission)
+			return e.Next()
+		},
+	}
+}
+
+// RequireAPIKeyResource refuses a record outside the resources the request
+// API key is restricted to. Requests not made with an API key pass.
+func RequireAPIKeyResource(
+	e *core.RequestEvent,
+	resource apikey.Resource,
+	id string,
+) *apierror.APIError {
+	grant, ok := e.Get(apiKeyGrantStoreKey).(apikey.Grant)
+	if !ok || grant.AllowsResource(resource, id) {
+		return nil
+	}
+	return apierror.New(
+		http.StatusForbidden,
+		"request.validation",
+		"api_key_resource_not_allowed",
+		"API key is not allowed to access "+string(resource)+" "+id,
+	)
+}
+
+// APIKeyGrant returns the grant of the API key that authenticated the
+// request, and false when the request was not made with an API key.
+func APIKeyGrant(e *core.RequestEvent) (apikey.Grant, bool) {
+	grant, ok := e.Get(apiKeyGrantStoreKey).(apikey.Grant)
+	return grant, ok
+}
+
+// APIKeyID returns the id of the api_keys record that authenticated the
+// request, and an empty string when the request was not made with an API key.
+func APIKeyID(e *core.RequestEvent) string {
+	id, _ := e.Get(apiKeyIDStoreKey).(string)
+	return id
+}
+
+func authenticateAPIKeyRequest(
+	e *core.RequestEvent,
+	apiKey string,
+	requiredScope string,
+) *apierror.APIError {
+	principal, key, apiErr := authenticateAPIKey(e.App, apiKey, requiredScope)
+	if apiErr != nil {
+		return apiErr
+	}
+	if apiErr := authorizeAPIKeyRequest(e, key); apiErr != nil {
+		return apiErr
+	}
+	e.Auth = principal
+	return nil
+}
+
+// authorizeAPIKeyRequest checks the grant of the key against the client
+// address and the permission declared by the route, and keeps the grant on
+// the request for RequireAPIKeyResource.
+func authorizeAPIKeyRequest(e *core.RequestEvent, key *core.Record) *apierror.APIError {
+	grant, err := apikey.GrantFromRecord(key)
+	if err != nil {
+		return apierror.New(
+			http.StatusInternalServerError,
+			"request.internal_error",
+			"invalid_api_key_grant",
+			err.Error(),
+		)
+	}
+	if !grant.AllowsIP(e.RealIP()) {
+		return apierror.New(
+			http.StatusForbidden,
+			"request.validation",
+			"api_key_ip_not_allowed",
+			"API key is not allowed from this address",
+		)
+	}
+	permission, _ := e.Get(apiKeyPermissionStoreKey).(apikey.Permission)
+	if !grant.AllowsPermission(permission) {
+		message := "API key does not have the permission required by this route"
+		if permission != "" {
+			message = "API key does not have the " + string(permission) + " permission"
+		}
+		return apierror.New(
+			http.StatusForbidden,
+			"request.validation",
+			"insufficient_api_key_permission",
+			message,
+		)
+	}
+	e.Set(apiKeyGrantStoreKey, grant)
+	e.Set(apiKeyIDStoreKey, key.Id)
+	return nil
+}
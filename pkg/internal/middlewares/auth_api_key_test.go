@@ -12,6 +12,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/router"
@@ -162,6 +164,147 @@ func TestRequireInternalAdminAPIKey(t *testing.T) {
 	})
 }
 
+func TestAPIKeyPrefixedLookup(t *testing.T) {
+	app, err := tests.NewTestApp(middlewareTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	record := createAPIKeyRecord(t, app, apiKeyRecordInput{
+		Plaintext: "prefixed-secret",
+		KeyID:     "abcdefghijkl",
+		UserID:    user.Id,
+		Scope:     apiKeyScopeUser,
+	})
+
+	authenticate := func(key string) (*core.Record, *apierror.APIError) {
+		return authenticateAPIKeyByScope(app, key, apiKeyScopeUser)
+	}
+
+	t.Run("authenticates and records last use", func(t *testing.T) {
+		principal, apiErr := authenticate(apikey.Format("abcdefghijkl", "prefixed-secret"))
+		require.Nil(t, apiErr)
+		require.Equal(t, user.Id, principal.Id)
+
+		stored, err := app.FindRecordById("api_keys", record.Id)
+		require.NoError(t, err)
+		lastUsed := stored.GetDateTime(apikey.FieldLastUsedAt)
+		require.False(t, lastUsed.IsZero())
+		require.WithinDuration(t, time.Now(), lastUsed.Time(), time.Minute)
+	})
+
+	for name, key := range map[string]string{
+		"wrong secret":          apikey.Format("abcdefghijkl", "other-secret"),
+		"unknown id":            apikey.Format("zzzzzzzzzzzz", "prefixed-secret"),
+		"secret without the id": "prefixed-secret",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, apiErr := authenticate(key)
+			require.NotNil(t, apiErr)
+			require.Equal(t, http.StatusUnauthorized, apiErr.Code)
+			require.Equal(t, "invalid_api_key", apiErr.Reason)
+		})
+	}
+}
+
+func TestAPIKeyGrantEnforcement(t *testing.T) {
+	app, err := tests.NewTestApp(middlewareTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	createAPIKeyRecord(t, app, apiKeyRecordInput{
+		Plaintext: "restricted-secret",
+		KeyID:     "restricted01",
+		UserID:    user.Id,
+		Scope:     apiKeyScopeUser,
+		Grant: apikey.Grant{
+			Permissions: []apikey.Permission{apikey.PermissionPipelinesRun},
+			Resources:   map[apikey.Resource][]string{apikey.ResourcePipelines: {"p1"}},
+			IPRanges:    []string{"192.0.2.0/24"},
+		},
+	})
+	restrictedKey := apikey.Format("restricted01", "restricted-secret")
+
+	newEvent := func(remoteAddr string, permission apikey.Permission) *core.RequestEvent {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(apiKeyHeaderName, restrictedKey)
+		e := &core.RequestEvent{
+			App:   app,
+			Event: router.Event{Request: req, Response: httptest.NewRecorder()},
+		}
+		if permission != "" {
+			setNext(e, func() error { return nil })
+			require.NoError(t, RequireAPIKeyPermission(permission).Func(e))
+		}
+		setNext(e, func() error { return nil })
+		return e
+	}
+
+	t.Run("allows the granted permission and records the grant", func(t *testing.T) {
+		e := newEvent("192.0.2.10:5000", apikey.PermissionPipelinesRun)
+		require.NoError(t, RequireAuthOrAPIKey().Func(e))
+		require.Equal(t, user.Id, e.Auth.Id)
+
+		grant, ok := APIKeyGrant(e)
+		require.True(t, ok)
+		require.Equal(t, []apikey.Permission{apikey.PermissionPipelinesRun}, grant.Permissions)
+		require.Nil(t, RequireAPIKeyResource(e, apikey.ResourcePipelines, "p1"))
+
+		key, err := app.FindFirstRecordByData("api_keys", apikey.FieldKeyID, "restricted01")
+		require.NoError(t, err)
+		require.Equal(t, key.Id, APIKeyID(e))
+
+		apiErr := RequireAPIKeyResource(e, apikey.ResourcePipelines, "p2")
+		require.NotNil(t, apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.Code)
+		require.Equal(t, "api_key_resource_not_allowed", apiErr.Reason)
+	})
+
+	for name, tc := range map[string]struct {
+		remoteAddr string
+		permission apikey.Permission
+		reason     string
+	}{
+		"other permission": {
+			"192.0.2.10:5000", apikey.PermissionWalletsWrite, "insufficient_api_key_permission",
+		},
+		"undeclared permission": {
+			"192.0.2.10:5000", "", "insufficient_api_key_permission",
+		},
+		"address outside the ranges": {
+			"198.51.100.7:5000", apikey.PermissionPipelinesRun, "api_key_ip_not_allowed",
+		},
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			e := newEvent(tc.remoteAddr, tc.permission)
+			err := RequireAuthOrAPIKey().Func(e)
+			var apiErr *apierror.APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusForbidden, apiErr.Code)
+			require.Equal(t, tc.reason, apiErr.Reason)
+			require.Nil(t, e.Auth)
+		})
+	}
+
+	t.Run("unrestricted keys and bearer requests skip resource checks", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		e := &core.RequestEvent{
+			App:   app,
+			Event: router.Event{Request: req, Response: httptest.NewRecorder()},
+		}
+		_, ok := APIKeyGrant(e)
+		require.False(t, ok)
+		require.Empty(t, APIKeyID(e))
+		require.Nil(t, RequireAPIKeyResource(e, apikey.ResourcePipelines, "p2"))
+	})
+}
+
 func TestOptionalAuthOrAPIKey(t *testing.T) {
 	app, err := tests.NewTestApp(middlewareTestDataDir)
 	require.NoError(t, err)
@@ -280,15 +423,22 @@ func requireAPIErrorResponseFromReader(
 }
 
 type apiKeyRecordInput struct {
+	// Plaintext is the secret of the key when KeyID is set.
 	Plaintext   string
+	KeyID       string
 	UserID      string
 	SuperuserID string
 	Scope       string
 	Revoked     bool
 	ExpiresAt   *time.Time
+	Grant       apikey.Grant
 }
 
-func createAPIKeyRecord(t *testing.T, app *tests.TestApp, input apiKeyRecordInput) {
+func createAPIKeyRecord(
+	t *testing.T,
+	app *tests.TestApp,
+	input apiKeyRecordInput,
+) *core.Record {
 	t.Helper()
 
 	coll, err := app.FindCollectionByNameOrId("api_keys")
@@ -300,13 +450,16 @@ func createAPIKeyRecord(t *testing.T, app *tests.TestApp, input apiKeyRecordInpu
 	record := core.NewRecord(coll)
 	record.Set("name", "test-key")
 	record.Set("key", string(hash))
+	record.Set(apikey.FieldKeyID, input.KeyID)
 	record.Set("user", input.UserID)
 	record.Set("superuser", input.SuperuserID)
 	record.Set("key_type", input.Scope)
 	record.Set("revoked", input.Revoked)
+	input.Grant.Apply(record)
 	if input.ExpiresAt != nil {
 		record.Set("expires_at", input.ExpiresAt.UTC().Format("2006-01-02 15:04:05.000Z"))
 	}
 
 	require.NoError(t, app.Save(record))
+	return record
 }
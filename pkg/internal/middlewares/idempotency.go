@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const IdempotentMiddlewareID = "idempotent"
+
+var (
+	// idempotencyNow is the clock of the idempotency middleware, replaced in
+	// tests.
+	idempotencyNow = time.Now
+	// idempotencyPollInterval is how often a retry checks on the request that
+	// holds its key.
+	idempotencyPollInterval = 250 * time.Millisecond
+	// idempotencyWait bounds how long a retry waits for that request.
+	idempotencyWait = 2 * time.Minute
+)
+
+// Idempotent makes a run-starting route honor the Idempotency-Key header:
+// the first request with a key runs and its successful response is stored,
+// later requests with the same key get that response back, waiting for it
+// while the first request is in flight. Failed requests release the key.
+// It runs before RequireRunQuota, so replays do not count as runs.
+func Idempotent() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       IdempotentMiddlewareID,
+		Priority: idempotencyPriority,
+		Func: func(e *core.RequestEvent) error {
+			key := strings.TrimSpace(e.Request.Header.Get(idempotency.Header))
+			if key == "" || e.Auth == nil {
+				return e.Next()
+			}
+			if len(key) > idempotency.MaxKeyLength {
+				return apierror.New(
+					http.StatusBadRequest,
+					"idempotency",
+					"invalid_idempotency_key",
+					"Idempotency-Key must be at most "+
+						strconv.Itoa(idempotency.MaxKeyLength)+" characters",
+				)
+			}
+
+			deadline := idempotencyNow().Add(idempotencyWait)
+			for {
+				claimed, existing, err := idempotency.Claim(
+					e.App,
+					e.Auth.Id,
+					key,
+					idempotencyNow(),
+				)
+				if err != nil {
+					return idempotencyStoreError(err)
+				}
+				if claimed != nil {
+					return runIdempotent(e, claimed)
+				}
+				if existing.GetString("status") == idempotency.StatusCompleted {
+					return replayIdempotent(e, existing)
+				}
+
+				if !idempotencyNow().Before(deadline) {
+					e.Response.Header().Set(
+						"Retry-After",
+						strconv.Itoa(int(idempotencyPollInterval.Seconds())+1),
+					)
+					return apierror.New(
+						http.StatusConflict,
+						"idempotency",
+						"idempotency_key_in_flight",
+						"a request with this Idempotency-Key is still in progress",
+					)
+				}
+				select {
+				case <-e.Request.Context().Done():
+					return e.Request.Context().Err()
+				case <-time.After(idempotencyPollInterval):
+				}
+			}
+		},
+	}
+}
+
+// runIdempotent runs the route for the request holding the key, and stores
+// its response when it succeeds. A response that cannot be stored releases
+// the key, so retries run the route again instead of waiting on a key that
+// is never completed.
+func runIdempotent(e *core.RequestEvent, record *core.Record) error {
+	fingerprint, err := fingerprintRequest(e)
+	if err != nil {
+		if releaseErr := idempotency.Release(e.App, record); releaseErr != nil {
+			log.Printf("failed to release idempotency key %s: %v", record.Id, releaseErr)
+		}
+		return err
+	}
+
+	recorder := &idempotencyRecorder{ResponseWriter: e.Response}
+	e.Response = recorder
+	err = e.Next()
+	e.Response = recorder.ResponseWriter
+
+	status := recorder.statusCode()
+	if err != nil || status < 200 || status > 299 || recorder.overflow {
+		if releaseErr := idempotency.Release(e.App, record); releaseErr != nil {
+			log.Printf("failed to release idempotency key %s: %v", record.Id, releaseErr)
+		}
+		return err
+	}
+
+	resp := idempotency.Response{
+		Status:      status,
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	}
+	err = idempotency.Complete(e.App, record, fingerprint, resp, idempotencyNow())
+	if err != nil {
+		log.Printf("failed to store idempotency key %s: %v", record.Id, err)
+		if releaseErr := idempotency.Release(e.App, record); releaseErr != nil {
+			log.Printf("failed to release idempotency key %s: %v", record.Id, releaseErr)
+		}
+	}
+	return nil
+}
+
+// replayIdempotent answers with the response stored for the key, after
+// checking that the retry is the same request.
+func replayIdempotent(e *core.RequestEvent, record *core.Record) error {
+	fingerprint, err := fingerprintRequest(e)
+	if err != nil {
+		return err
+	}
+	if fingerprint != record.GetString("fingerprint") {
+		return apierror.New(
+			http.StatusUnprocessableEntity,
+			"idempotency",
+			"idempotency_key_reused",
+			"Idempotency-Key was already used for a different request",
+		)
+	}
+
+	resp := idempotency.StoredResponse(record)
+	header := e.Response.Header()
+	header.Set(idempotency.ReplayedHeader, "true")
+	if resp.ContentType != "" {
+		header.Set("Content-Type", resp.ContentType)
+	}
+	e.Response.WriteHeader(resp.Status)
+	_, err = e.Response.Write(resp.Body)
+	return err
+}
+
+// fingerprintRequest reads the request body once and hashes it with the
+// method, path and query, putting a fresh reader over the same bytes back on the
+// request for the route.
+func fingerprintRequest(e *core.RequestEvent) (string, error) {
+	body, err := io.ReadAll(e.Request.Body)
+	if err != nil {
+		return "", apierror.New(
+			http.StatusBadRequest,
+			"idempotency",
+			"failed_to_read_request",
+			err.Error(),
+		)
+	}
+	e.Request.Body = &router.RereadableReadCloser{
+		ReadCloser: io.NopCloser(bytes.NewReader(body)),
+	}
+
+	h := newRequestFingerprint(e.Request)
+	_, _ = h.Write(body)
+	return fingerprintHex(h), nil
+}
+
+// newRequestFingerprint starts the hash identifying a request: its method,
+// path, query and, once written, its body.
+func newRequestFingerprint(r *http.Request) hash.Hash {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n")
+	return h
+}
+
+func fingerprintHex(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func idempotencyStoreError(err error) *apierror.APIError {
+	return apierror.New(
+		http.StatusInternalServerError,
+		"idempotency",
+		"failed_to_store_idempotency_key",
+		err.Error(),
+	)
+}
+
+// idempotencyRecorder passes the response through and keeps a copy of it.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if !r.overflow {
+		if r.body.Len()+len(p) > idempotency.MaxResponseBytes {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *idempotencyRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
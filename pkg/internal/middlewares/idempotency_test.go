@@ -0,0 +1,288 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotentRequestEvent(
+	app *tests.TestApp,
+	auth *core.Record,
+	key string,
+	body string,
+	next func(e *core.RequestEvent) error,
+) (*core.RequestEvent, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/api/pipeline/queue", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotency.Header, key)
+	}
+	rec := httptest.NewRecorder()
+	e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+	e.Auth = auth
+	setNext(e, func() error { return next(e) })
+	return e, rec
+}
+
+// queueTicket is a route that reads the request and answers with a ticket,
+// counting its calls.
+func queueTicket(calls *int) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		*calls++
+		if _, err := io.ReadAll(e.Request.Body); err != nil {
+			return err
+		}
+		return e.JSON(http.StatusOK, map[string]any{"ticket_id": "ticket-1", "status": "queued"})
+	}
+}
+
+func TestIdempotentReplaysCompletedRequests(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(idempotency.ReplayedHeader))
+	original := rec.Body.String()
+
+	e, rec = newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Equal(t, original, rec.Body.String())
+
+	record, err := app.FindFirstRecordByData(idempotency.Collection, "key", "ci-1")
+	require.NoError(t, err)
+	require.Equal(t, user.Id, record.GetString("caller"))
+	require.Equal(t, "ticket-1", record.GetString("ticket_id"))
+}
+
+func TestIdempotentReplaysRereadableBodies(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	// The router wraps every request body in a RereadableReadCloser, which
+	// rewinds at EOF; the body must still be hashed only once.
+	rereadable := func(e *core.RequestEvent) {
+		e.Request.Body = &router.RereadableReadCloser{ReadCloser: e.Request.Body}
+	}
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, queueTicket(&calls))
+	rereadable(e)
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, http.StatusOK, rec.Code)
+	original := rec.Body.String()
+
+	e, rec = newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, queueTicket(&calls))
+	rereadable(e)
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, original, rec.Body.String())
+}
+
+func TestIdempotentPassesTheBodyToTheRoute(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	var read []string
+	e, _ := newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, func(e *core.RequestEvent) error {
+		for range 2 {
+			body, err := io.ReadAll(e.Request.Body)
+			if err != nil {
+				return err
+			}
+			read = append(read, string(body))
+		}
+		return e.NoContent(http.StatusNoContent)
+	})
+	e.Request.Body = &router.RereadableReadCloser{ReadCloser: e.Request.Body}
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, []string{`{"yaml":"a"}`, `{"yaml":"a"}`}, read)
+}
+
+func TestIdempotentRejectsReusedKeys(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	calls := 0
+	e, _ := newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"a"}`, queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+
+	e, _ = newIdempotentRequestEvent(app, user, "ci-1", `{"yaml":"b"}`, queueTicket(&calls))
+	err := Idempotent().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.Code)
+	require.Equal(t, "idempotency_key_reused", apiErr.Reason)
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotentRejectsReusedKeysWithAnotherQuery(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	calls := 0
+	e, _ := newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	e.Request.URL.RawQuery = "runner=a"
+	require.NoError(t, Idempotent().Func(e))
+
+	e, _ = newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	e.Request.URL.RawQuery = "runner=b"
+	err := Idempotent().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.Code)
+	require.Equal(t, "idempotency_key_reused", apiErr.Reason)
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotentReleasesFailedRequests(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	e, _ := newIdempotentRequestEvent(app, user, "ci-1", "{}", func(*core.RequestEvent) error {
+		return errors.New("start failed")
+	})
+	require.Error(t, Idempotent().Func(e))
+
+	e, _ = newIdempotentRequestEvent(app, user, "ci-1", "{}", func(e *core.RequestEvent) error {
+		return e.JSON(http.StatusBadRequest, map[string]any{"message": "invalid"})
+	})
+	require.NoError(t, Idempotent().Func(e))
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, 1, calls)
+	require.Empty(t, rec.Header().Get(idempotency.ReplayedHeader))
+}
+
+func TestIdempotentReleasesUnstoredResponses(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	app.OnRecordUpdate(idempotency.Collection).BindFunc(func(*core.RecordEvent) error {
+		return errors.New("store failed")
+	})
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	_, err := app.FindFirstRecordByData(idempotency.Collection, "key", "ci-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	e, rec = newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	require.Equal(t, 2, calls)
+	require.Empty(t, rec.Header().Get(idempotency.ReplayedHeader))
+}
+
+func TestIdempotentRunsBeforeRunQuota(t *testing.T) {
+	require.Greater(t, Idempotent().Priority, 0, "after the auth middlewares")
+	require.Less(t, Idempotent().Priority, RequireRunQuota().Priority)
+}
+
+func TestIdempotentWaitsForInFlightRequests(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	originalPoll := idempotencyPollInterval
+	idempotencyPollInterval = time.Millisecond
+	defer func() { idempotencyPollInterval = originalPoll }()
+
+	claimed, _, err := idempotency.Claim(app, user.Id, "ci-1", time.Now())
+	require.NoError(t, err)
+	first, _ := newIdempotentRequestEvent(app, user, "ci-1", "{}", nil)
+	fingerprint := newRequestFingerprint(first.Request)
+	_, _ = io.WriteString(fingerprint, "{}")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(20 * time.Millisecond)
+		_ = idempotency.Complete(app, claimed, fingerprintHex(fingerprint), idempotency.Response{
+			Status:      http.StatusOK,
+			ContentType: "application/json",
+			Body:        []byte(`{"ticket_id":"ticket-1"}`),
+		}, time.Now())
+	}()
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	require.NoError(t, Idempotent().Func(e))
+	<-done
+	require.Zero(t, calls)
+	require.Equal(t, "true", rec.Header().Get(idempotency.ReplayedHeader))
+	require.JSONEq(t, `{"ticket_id":"ticket-1"}`, rec.Body.String())
+}
+
+func TestIdempotentGivesUpOnLongInFlightRequests(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	originalWait := idempotencyWait
+	idempotencyWait = 0
+	defer func() { idempotencyWait = originalWait }()
+
+	_, _, err := idempotency.Claim(app, user.Id, "ci-1", time.Now())
+	require.NoError(t, err)
+
+	calls := 0
+	e, rec := newIdempotentRequestEvent(app, user, "ci-1", "{}", queueTicket(&calls))
+	err = Idempotent().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusConflict, apiErr.Code)
+	require.Equal(t, "idempotency_key_in_flight", apiErr.Reason)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.Zero(t, calls)
+}
+
+func TestIdempotentWithoutKey(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	calls := 0
+	for range 2 {
+		e, _ := newIdempotentRequestEvent(app, user, "", "{}", queueTicket(&calls))
+		require.NoError(t, Idempotent().Func(e))
+	}
+	require.Equal(t, 2, calls)
+
+	e, _ := newIdempotentRequestEvent(
+		app,
+		user,
+		strings.Repeat("k", idempotency.MaxKeyLength+1),
+		"{}",
+		queueTicket(&calls),
+	)
+	err := Idempotent().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.Code)
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	OrganizationRoleMiddlewareID = "organizationRole"
+
+	// organizationRolePriority runs the role check after the auth
+	// middlewares, which have the default priority, so the user is known.
+	organizationRolePriority = 1
+)
+
+// RequireOrganizationRole refuses users whose organization role does not
+// grant permission. Anonymous requests, superusers and users without an
+// organization are left to the other middlewares and the handler.
+func RequireOrganizationRole(permission apikey.Permission) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       OrganizationRoleMiddlewareID,
+		Priority: organizationRolePriority,
+		Func: func(e *core.RequestEvent) error {
+			if e.Auth == nil || e.Auth.Collection() == nil ||
+				e.Auth.Collection().Name != userOwnerTable {
+				return e.Next()
+			}
+			membership, err := orgrole.Find(e.App, e.Auth.Id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return e.Next()
+			}
+			if err != nil {
+				return apierror.New(
+					http.StatusInternalServerError,
+					"organization",
+					"failed_to_read_organization_role",
+					err.Error(),
+				)
+			}
+			if apiErr := OrganizationRoleError(membership.Role, permission); apiErr != nil {
+				return apiErr
+			}
+			return e.Next()
+		},
+	}
+}
+
+// OrganizationRoleError returns the error refusing role a route declaring
+// permission, or nil when the role grants it.
+func OrganizationRoleError(role orgrole.Role, permission apikey.Permission) *apierror.APIError {
+	if orgrole.Allows(role, permission) {
+		return nil
+	}
+	return apierror.New(
+		http.StatusForbidden,
+		"authorization",
+		"organization_role_not_allowed",
+		"organization role "+string(role)+" does not grant "+string(permission),
+	)
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/forkbombeu/credimi/pkg/internal/orgrole"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireOrganizationRole(t *testing.T) {
+	app, user, _ := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	viewer, err := app.FindFirstRecordByData("orgRoles", "name", string(orgrole.Viewer))
+	require.NoError(t, err)
+	authorization, err := app.FindFirstRecordByData("orgAuthorizations", "user", user.Id)
+	require.NoError(t, err)
+	authorization.Set("role", viewer.Id)
+	require.NoError(t, app.Save(authorization))
+
+	e, _ := newQuotaRequestEvent(app, user)
+	require.NoError(t, RequireOrganizationRole(apikey.PermissionPipelinesRead).Func(e))
+
+	e, _ = newQuotaRequestEvent(app, user)
+	err = RequireOrganizationRole(apikey.PermissionPipelinesRun).Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.Code)
+	require.Equal(t, "organization_role_not_allowed", apiErr.Reason)
+
+	superuser, err := app.FindAuthRecordByEmail("_superusers", "admin@example.org")
+	require.NoError(t, err)
+	e, _ = newQuotaRequestEvent(app, superuser)
+	require.NoError(t, RequireOrganizationRole(apikey.PermissionPipelinesRun).Func(e))
+
+	anonymous, _ := newQuotaRequestEvent(app, nil)
+	require.NoError(t, RequireOrganizationRole(apikey.PermissionPipelinesRun).Func(anonymous))
+}
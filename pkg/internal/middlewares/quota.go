@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	OrganizationRateLimitMiddlewareID = "organizationRateLimit"
+	RequireRunQuotaMiddlewareID       = "requireRunQuota"
+
+	// idempotencyPriority runs Idempotent after the auth middlewares, which
+	// have the default priority, so the caller is known, and before the run
+	// quota, so replays are not counted as runs.
+	idempotencyPriority = 1
+	// runQuotaPriority runs the run quota after the auth middlewares, so the
+	// organization is known, and after Idempotent.
+	runQuotaPriority = 2
+)
+
+// quotaNow is the clock of the quota middlewares, replaced in tests.
+var quotaNow = time.Now
+
+// OrganizationRateLimit refuses requests beyond the requests per minute of
+// the caller organization. Anonymous requests, superusers and users without
+// an organization are not limited. Requests are counted by quota.Requests in
+// the memory of this instance, so the limit applies per server instance
+// rather than across a deployment.
+func OrganizationRateLimit() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id: OrganizationRateLimitMiddlewareID,
+		Func: func(e *core.RequestEvent) error {
+			org, apiErr := quotaOrganization(e)
+			if apiErr != nil {
+				return apiErr
+			}
+			limits := quota.LimitsFromRecord(org)
+			if limits.RequestsPerMinute == 0 {
+				return e.Next()
+			}
+
+			now := quotaNow()
+			allowed, retryAfter := quota.Requests.Allow(org.Id, limits.RequestsPerMinute, now)
+			if !allowed {
+				return QuotaExceeded(e, &quota.ExceededError{
+					Quota:      quota.RequestsPerMinute,
+					Limit:      int64(limits.RequestsPerMinute),
+					Used:       int64(quota.Requests.Used(org.Id, now)),
+					RetryAfter: retryAfter,
+				})
+			}
+			return e.Next()
+		},
+	}
+}
+
+// RequireRunQuota refuses to start a run beyond the runs per day of the
+// caller organization. The run is counted before the route runs, so that
+// concurrent requests cannot all pass the check, and given back when the
+// route fails.
+func RequireRunQuota() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       RequireRunQuotaMiddlewareID,
+		Priority: runQuotaPriority,
+		Func: func(e *core.RequestEvent) error {
+			org, apiErr := quotaOrganization(e)
+			if apiErr != nil {
+				return apiErr
+			}
+			if org == nil {
+				return e.Next()
+			}
+
+			now := quotaNow()
+			limits := quota.LimitsFromRecord(org)
+			err := quota.ReserveRun(e.App, org.Id, limits, now)
+			var exceeded *quota.ExceededError
+			if errors.As(err, &exceeded) {
+				return QuotaExceeded(e, exceeded)
+			}
+			if err != nil {
+				return quotaUsageError(err)
+			}
+
+			if err := e.Next(); err != nil {
+				if releaseErr := quota.ReleaseRun(e.App, org.Id, now); releaseErr != nil {
+					log.Printf("failed to release run of organization %s: %v", org.Id, releaseErr)
+				}
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+// QuotaExceeded returns the 429 error for an exceeded quota, with a
+// Retry-After header when waiting frees the quota.
+func QuotaExceeded(e *core.RequestEvent, exceeded *quota.ExceededError) *apierror.APIError {
+	if exceeded.RetryAfter > 0 {
+		seconds := int(math.Ceil(exceeded.RetryAfter.Seconds()))
+		e.Response.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return apierror.New(
+		http.StatusTooManyRequests,
+		"quota",
+		"quota_exceeded",
+		exceeded.Error(),
+	)
+}
+
+// quotaOrganization returns the organization of the authenticated user, or
+// nil when the request has none to charge.
+func quotaOrganization(e *core.RequestEvent) (*core.Record, *apierror.APIError) {
+	if e.Auth == nil || e.Auth.Collection() == nil || e.Auth.Collection().Name != "users" {
+		return nil, nil
+	}
+	org, err := pbutils.GetUserOrganization(e.App, e.Auth.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, quotaUsageError(err)
+	}
+	return org, nil
+}
+
+func quotaUsageError(err error) *apierror.APIError {
+	return apierror.New(
+		http.StatusInternalServerError,
+		"quota",
+		"failed_to_read_quota_usage",
+		err.Error(),
+	)
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apierror"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRateLimit(t *testing.T) {
+	app, user, org := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	org.Set(quota.FieldRequestsPerMinute, 2)
+	require.NoError(t, app.Save(org))
+
+	originalRequests, originalNow := quota.Requests, quotaNow
+	quota.Requests = quota.NewLimiter()
+	quotaNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 50, 0, time.UTC) }
+	defer func() { quota.Requests, quotaNow = originalRequests, originalNow }()
+
+	for range 2 {
+		e, _ := newQuotaRequestEvent(app, user)
+		require.NoError(t, OrganizationRateLimit().Func(e))
+	}
+
+	e, rec := newQuotaRequestEvent(app, user)
+	err := OrganizationRateLimit().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusTooManyRequests, apiErr.Code)
+	require.Equal(t, "quota_exceeded", apiErr.Reason)
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	anonymous, _ := newQuotaRequestEvent(app, nil)
+	require.NoError(t, OrganizationRateLimit().Func(anonymous))
+}
+
+func TestRequireRunQuota(t *testing.T) {
+	app, user, org := newQuotaTestApp(t)
+	defer app.Cleanup()
+
+	org.Set(quota.FieldRunsPerDay, 1)
+	require.NoError(t, app.Save(org))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := quotaNow
+	quotaNow = func() time.Time { return now }
+	defer func() { quotaNow = originalNow }()
+
+	e, _ := newQuotaRequestEvent(app, user)
+	setNext(e, func() error { return errors.New("start failed") })
+	require.Error(t, RequireRunQuota().Func(e))
+	runs, err := quota.RunsToday(app, org.Id, now)
+	require.NoError(t, err)
+	require.Zero(t, runs)
+
+	e, _ = newQuotaRequestEvent(app, user)
+	require.NoError(t, RequireRunQuota().Func(e))
+	runs, err = quota.RunsToday(app, org.Id, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), runs)
+
+	e, rec := newQuotaRequestEvent(app, user)
+	nextCalled := false
+	setNext(e, func() error {
+		nextCalled = true
+		return nil
+	})
+	err = RequireRunQuota().Func(e)
+	var apiErr *apierror.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusTooManyRequests, apiErr.Code)
+	require.Contains(t, apiErr.Message, "runs_per_day")
+	require.False(t, nextCalled)
+	require.Equal(t, "43200", rec.Header().Get("Retry-After"))
+}
+
+func newQuotaTestApp(t *testing.T) (*tests.TestApp, *core.Record, *core.Record) {
+	t.Helper()
+
+	app, err := tests.NewTestApp(middlewareTestDataDir)
+	require.NoError(t, err)
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	org, err := pbutils.GetUserOrganization(app, user.Id)
+	require.NoError(t, err)
+	return app, user, org
+}
+
+func newQuotaRequestEvent(
+	app *tests.TestApp,
+	auth *core.Record,
+) (*core.RequestEvent, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	e := &core.RequestEvent{App: app, Event: router.Event{Request: req, Response: rec}}
+	e.Auth = auth
+	setNext(e, func() error { return nil })
+	return e, rec
+}
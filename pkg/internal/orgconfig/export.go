@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package orgconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// relation is an exported relation field, with the collection it points to.
+type relation struct {
+	field      *core.RelationField
+	collection string
+}
+
+// fieldSet is the exported fields of a collection, by how they are stored.
+type fieldSet struct {
+	data      []core.Field
+	relations []relation
+	files     []*core.FileField
+}
+
+// exportedFields returns the fields of collection that make its
+// configuration: not the system and owner fields, nor the canonified name,
+// which is the last segment of the path.
+func exportedFields(app core.App, k kind, collection *core.Collection) (fieldSet, error) {
+	var set fieldSet
+	canonified := canonify.CanonifyPaths[k.collection].CanonifiedField
+	for _, field := range collection.Fields {
+		name := field.GetName()
+		if field.GetSystem() || name == "owner" || name == canonified ||
+			slices.Contains(k.omit, name) {
+			continue
+		}
+		switch f := field.(type) {
+		case *core.AutodateField, *core.PasswordField:
+		case *core.FileField:
+			set.files = append(set.files, f)
+		case *core.RelationField:
+			target, err := app.FindCachedCollectionByNameOrId(f.CollectionId)
+			if err != nil {
+				return fieldSet{}, fmt.Errorf("failed to find collection of %s: %w", name, err)
+			}
+			// Only records with a path can be referenced across instances.
+			if _, ok := canonify.CanonifyPaths[target.Name]; ok {
+				set.relations = append(set.relations, relation{field: f, collection: target.Name})
+			}
+		default:
+			set.data = append(set.data, field)
+		}
+	}
+	return set, nil
+}
+
+// Export returns the configuration of the organization orgID.
+func Export(app core.App, orgID string, now time.Time) (*Archive, error) {
+	org, err := app.FindRecordById("organizations", orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organization: %w", err)
+	}
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:      Version,
+			Organization: org.GetString("canonified_name"),
+			ExportedAt:   now.UTC().Format(time.RFC3339),
+		},
+		Files: map[string][]byte{},
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open filesystem: %w", err)
+	}
+	defer fsys.Close()
+
+	for _, k := range kinds {
+		collection, err := app.FindCollectionByNameOrId(k.collection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find collection %s: %w", k.collection, err)
+		}
+		fields, err := exportedFields(app, k, collection)
+		if err != nil {
+			return nil, err
+		}
+		records, err := app.FindRecordsByFilter(
+			collection,
+			"owner = {:owner}",
+			"created",
+			0,
+			0,
+			dbx.Params{"owner": orgID},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", k.collection, err)
+		}
+
+		resources := make([]Resource, 0, len(records))
+		for _, record := range records {
+			res, err := exportRecord(app, fsys, archive, k, fields, record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s %s: %w", k.collection, record.Id, err)
+			}
+			resources = append(resources, res)
+		}
+		slices.SortStableFunc(resources, func(a, b Resource) int {
+			return strings.Compare(a.key(), b.key())
+		})
+		archive.Manifest.Resources = append(archive.Manifest.Resources, resources...)
+	}
+	return archive, nil
+}
+
+func exportRecord(
+	app core.App,
+	fsys *filesystem.System,
+	archive *Archive,
+	k kind,
+	fields fieldSet,
+	record *core.Record,
+) (Resource, error) {
+	res := Resource{Kind: k.collection}
+	if tpl, ok := canonify.CanonifyPaths[k.collection]; ok {
+		p, err := canonify.BuildPath(app, record, tpl, "")
+		if err != nil {
+			return Resource{}, err
+		}
+		res.Path = p
+	}
+
+	for _, field := range fields.data {
+		if value, ok := exportValue(record.Get(field.GetName())); ok {
+			if res.Fields == nil {
+				res.Fields = map[string]any{}
+			}
+			res.Fields[field.GetName()] = value
+		}
+	}
+
+	for _, rel := range fields.relations {
+		var paths []string
+		for _, id := range record.GetStringSlice(rel.field.Name) {
+			related, err := app.FindRecordById(rel.collection, id)
+			if err != nil {
+				// A dangling relation is not part of the configuration.
+				continue
+			}
+			p, err := canonify.BuildPath(app, related, canonify.CanonifyPaths[rel.collection], "")
+			if err != nil {
+				return Resource{}, err
+			}
+			paths = append(paths, p)
+		}
+		if len(paths) > 0 {
+			if res.Refs == nil {
+				res.Refs = map[string][]string{}
+			}
+			res.Refs[rel.field.Name] = paths
+		}
+	}
+
+	for _, field := range fields.files {
+		var entries []string
+		for _, name := range record.GetStringSlice(field.Name) {
+			content, err := readFile(fsys, record.BaseFilesPath()+"/"+name)
+			if err != nil {
+				return Resource{}, fmt.Errorf("failed to read file %s: %w", name, err)
+			}
+			entry := filePath(res.Path, field.Name, name)
+			archive.Files[entry] = content
+			entries = append(entries, entry)
+		}
+		if len(entries) > 0 {
+			if res.Files == nil {
+				res.Files = map[string][]string{}
+			}
+			res.Files[field.Name] = entries
+		}
+	}
+	return res, nil
+}
+
+// key orders resources of a kind: by path, or by pipeline for schedules.
+func (r Resource) key() string {
+	if r.Path != "" {
+		return r.Path
+	}
+	return strings.Join(r.Refs["pipeline"], ",")
+}
+
+// exportValue converts a field value to plain YAML, reporting false for
+// empty values, which are left out of the archive.
+func exportValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, v != ""
+	case bool:
+		return v, v
+	case float64:
+		return v, v != 0
+	case int:
+		return v, v != 0
+	case []string:
+		return v, len(v) > 0
+	case types.JSONRaw:
+		if len(v) == 0 {
+			return nil, false
+		}
+		var decoded any
+		if err := json.Unmarshal(v, &decoded); err != nil || decoded == nil {
+			return nil, false
+		}
+		return decoded, true
+	case types.DateTime:
+		return v.String(), !v.IsZero()
+	default:
+		return v, true
+	}
+}
+
+func readFile(fsys *filesystem.System, key string) ([]byte, error) {
+	r, err := fsys.GetFile(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,630 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package orgconfig
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"gopkg.in/yaml.v3"
+)
+
+// ConflictPolicy is what an import does with resources whose path already
+// exists in the organization.
+type ConflictPolicy string
+
+const (
+	// ConflictFail reports the conflicts and imports nothing.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip keeps the existing resources.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing resources with the archived
+	// ones. Existing schedules are kept.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// ParseConflictPolicy parses a policy name, defaulting to ConflictFail.
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(strings.TrimSpace(value)); policy {
+	case "":
+		return ConflictFail, nil
+	case ConflictFail, ConflictSkip, ConflictOverwrite:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q: must be fail, skip or overwrite", value)
+	}
+}
+
+// Actions of the items of a Report. In a dry run, they are what the import
+// would do.
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionSkip     = "skip"
+	ActionConflict = "conflict"
+	ActionError    = "error"
+	// ActionFailed marks schedules that could not be started once the
+	// records were imported.
+	ActionFailed = "failed"
+)
+
+// Item is the outcome of the import of a resource. Path is the path in the
+// importing organization, or the pipeline path for schedules.
+type Item struct {
+	Kind     string   `json:"kind"`
+	Path     string   `json:"path"`
+	Action   string   `json:"action"`
+	Message  string   `json:"message,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Report is the outcome of an import. Applied is false for dry runs and for
+// imports blocked by conflicts or errors, which change nothing.
+type Report struct {
+	Organization string         `json:"organization"`
+	DryRun       bool           `json:"dry_run"`
+	Applied      bool           `json:"applied"`
+	Counts       map[string]int `json:"counts"`
+	Items        []Item         `json:"items"`
+}
+
+// Blocked reports whether conflicts or errors prevent the import.
+func (r Report) Blocked() bool {
+	return r.Counts[ActionConflict] > 0 || r.Counts[ActionError] > 0
+}
+
+func (r *Report) count() {
+	r.Counts = map[string]int{}
+	for _, item := range r.Items {
+		r.Counts[item.Action]++
+	}
+}
+
+// ScheduleStarter starts the Temporal schedule of an imported schedule of
+// pipeline, returning its id.
+type ScheduleStarter func(pipeline *core.Record, mode map[string]any) (string, error)
+
+type Options struct {
+	DryRun     bool
+	OnConflict ConflictPolicy
+	// StartSchedule starts imported schedules. Without it, schedules are
+	// skipped.
+	StartSchedule ScheduleStarter
+}
+
+// importer imports an archive exported by the source organization into the
+// target one.
+type importer struct {
+	app     core.App
+	orgID   string
+	source  string
+	target  string
+	archive *Archive
+	opts    Options
+
+	// planned holds the collection and path of the resources the archive
+	// creates or keeps, and records the records they ended up as.
+	planned map[string]bool
+	records map[string]*core.Record
+}
+
+// step is the import of a resource.
+type step struct {
+	res    Resource
+	kind   kind
+	fields fieldSet
+	item   *Item
+
+	collection *core.Collection
+	existing   *core.Record
+	// refs holds the paths of Resource.Refs in the target organization.
+	refs map[string][]string
+	// yaml is the yaml field with its paths moved to the target organization.
+	yaml string
+}
+
+// Import imports archive into the organization orgID. Resources are created
+// in a single transaction, so either all of them are imported or none is;
+// schedules are started afterwards.
+func Import(app core.App, orgID string, archive *Archive, opts Options) (Report, error) {
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictFail
+	}
+	org, err := app.FindRecordById("organizations", orgID)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to find organization: %w", err)
+	}
+	imp := &importer{
+		app:     app,
+		orgID:   orgID,
+		source:  archive.Manifest.Organization,
+		target:  org.GetString("canonified_name"),
+		archive: archive,
+		opts:    opts,
+		planned: map[string]bool{},
+		records: map[string]*core.Record{},
+	}
+
+	steps, err := imp.plan()
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{Organization: imp.target, DryRun: opts.DryRun}
+	collect := func() {
+		report.Items = report.Items[:0]
+		for _, s := range steps {
+			report.Items = append(report.Items, *s.item)
+		}
+		report.count()
+	}
+	collect()
+	if opts.DryRun || report.Blocked() {
+		return report, nil
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for _, s := range steps {
+			if s.kind.collection == scheduleKind {
+				continue
+			}
+			if err := imp.apply(txApp, s); err != nil {
+				return fmt.Errorf("failed to import %s %s: %w", s.res.Kind, s.item.Path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	report.Applied = true
+
+	for _, s := range steps {
+		if s.kind.collection == scheduleKind && s.item.Action == ActionCreate {
+			imp.startSchedule(s)
+		}
+	}
+	collect()
+	return report, nil
+}
+
+func (imp *importer) plan() ([]*step, error) {
+	resources := slices.Clone(imp.archive.Manifest.Resources)
+	slices.SortStableFunc(resources, func(a, b Resource) int {
+		return kindIndex(a.Kind) - kindIndex(b.Kind)
+	})
+
+	collections := map[string]*core.Collection{}
+	fieldSets := map[string]fieldSet{}
+	steps := make([]*step, 0, len(resources))
+	for _, res := range resources {
+		s := &step{res: res, item: &Item{Kind: res.Kind, Path: imp.rebase(res.Path)}}
+		steps = append(steps, s)
+
+		k, ok := findKind(res.Kind)
+		if !ok {
+			s.fail("unknown kind %q", res.Kind)
+			continue
+		}
+		s.kind = k
+		if _, ok := collections[k.collection]; !ok {
+			collection, err := imp.app.FindCollectionByNameOrId(k.collection)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find collection %s: %w", k.collection, err)
+			}
+			fields, err := exportedFields(imp.app, k, collection)
+			if err != nil {
+				return nil, err
+			}
+			collections[k.collection] = collection
+			fieldSets[k.collection] = fields
+		}
+		s.collection = collections[k.collection]
+		s.fields = fieldSets[k.collection]
+
+		s.refs = map[string][]string{}
+		for field, paths := range res.Refs {
+			for _, p := range paths {
+				s.refs[field] = append(s.refs[field], imp.rebase(p))
+			}
+		}
+		s.warnUnknownFields()
+
+		if k.collection == scheduleKind {
+			imp.planSchedule(s)
+			continue
+		}
+		if err := imp.planRecord(s); err != nil {
+			return nil, err
+		}
+	}
+	return steps, nil
+}
+
+func (imp *importer) planRecord(s *step) error {
+	tpl := canonify.CanonifyPaths[s.kind.collection]
+	if !strings.HasPrefix(s.res.Path, imp.source+"/") {
+		s.fail("path is outside the exported organization %s", imp.source)
+		return nil
+	}
+	if got := len(strings.Split(s.item.Path, "/")); got != tpl.PathLength {
+		s.fail("path must have %d segments, not %d", tpl.PathLength, got)
+		return nil
+	}
+	if tpl.Parent != nil && tpl.Parent.Collection != "organizations" {
+		parent := path.Dir(s.item.Path)
+		if refs := s.refs[tpl.Parent.Field]; len(refs) != 1 || refs[0] != parent {
+			s.fail("refs.%s must be the parent path %s", tpl.Parent.Field, parent)
+			return nil
+		}
+	}
+	if !imp.planRefs(s) {
+		return nil
+	}
+
+	if s.kind.rebaseYAML {
+		doc, _ := s.res.Fields["yaml"].(string)
+		var refs []string
+		s.yaml, refs = imp.rebaseYAML(doc)
+		for _, ref := range refs {
+			if !imp.pathExists(ref) {
+				s.item.Warnings = append(s.item.Warnings, fmt.Sprintf(
+					"yaml references %s, which does not exist in %s",
+					ref,
+					imp.target,
+				))
+			}
+		}
+	}
+
+	existing, err := canonify.ResolveIn(imp.app, s.kind.collection, s.item.Path)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		s.item.Action = ActionCreate
+	case err != nil:
+		return fmt.Errorf("failed to resolve %s: %w", s.item.Path, err)
+	default:
+		s.existing = existing
+		s.item.Action = map[ConflictPolicy]string{
+			ConflictFail:      ActionConflict,
+			ConflictSkip:      ActionSkip,
+			ConflictOverwrite: ActionUpdate,
+		}[imp.opts.OnConflict]
+		if s.item.Action == ActionConflict {
+			s.item.Message = "already exists"
+		}
+	}
+	imp.planned[plannedKey(s.kind.collection, s.item.Path)] = true
+	return nil
+}
+
+// planRefs checks that every reference is imported or exists, reporting
+// false when one does not.
+func (imp *importer) planRefs(s *step) bool {
+	for _, rel := range s.fields.relations {
+		refs := s.refs[rel.field.Name]
+		if len(refs) > 1 && !rel.field.IsMultiple() {
+			s.fail("refs.%s has %d paths but takes one", rel.field.Name, len(refs))
+			return false
+		}
+		for _, ref := range refs {
+			if !imp.resolvable(rel.collection, ref) {
+				s.fail("unresolved reference %s: %s", rel.field.Name, ref)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (imp *importer) planSchedule(s *step) {
+	pipelines := s.refs["pipeline"]
+	if len(pipelines) != 1 {
+		s.fail("schedule must reference one pipeline")
+		return
+	}
+	s.item.Path = pipelines[0]
+	if _, ok := s.res.Fields["mode"].(map[string]any); !ok {
+		s.fail("schedule has no mode")
+		return
+	}
+	if !imp.planRefs(s) {
+		return
+	}
+	s.item.Action = ActionCreate
+
+	pipeline, err := canonify.ResolveIn(imp.app, "pipelines", s.item.Path)
+	if err != nil {
+		return
+	}
+	existing, err := imp.app.FindRecordsByFilter(
+		scheduleKind,
+		"pipeline = {:pipeline}",
+		"",
+		0,
+		0,
+		dbx.Params{"pipeline": pipeline.Id},
+	)
+	if err != nil {
+		s.fail("failed to list schedules: %v", err)
+		return
+	}
+	mode := normalizedJSON(s.res.Fields["mode"])
+	for _, record := range existing {
+		if normalizedJSON(record.Get("mode")) != mode {
+			continue
+		}
+		if imp.opts.OnConflict == ConflictFail {
+			s.item.Action = ActionConflict
+			s.item.Message = "an identical schedule already exists"
+		} else {
+			s.item.Action = ActionSkip
+			s.item.Message = "an identical schedule already exists"
+		}
+		return
+	}
+}
+
+func (imp *importer) apply(txApp core.App, s *step) error {
+	key := plannedKey(s.kind.collection, s.item.Path)
+	if s.item.Action == ActionSkip {
+		imp.records[key] = s.existing
+		return nil
+	}
+
+	record := s.existing
+	if record == nil {
+		record = core.NewRecord(s.collection)
+	}
+	tpl := canonify.CanonifyPaths[s.kind.collection]
+	record.Set("owner", imp.orgID)
+	record.Set(tpl.CanonifiedField, path.Base(s.item.Path))
+
+	for _, field := range s.fields.data {
+		value := s.res.Fields[field.GetName()]
+		if s.kind.rebaseYAML && field.GetName() == "yaml" {
+			value = s.yaml
+		}
+		record.Set(field.GetName(), value)
+	}
+	for _, rel := range s.fields.relations {
+		var ids []string
+		for _, ref := range s.refs[rel.field.Name] {
+			related, err := imp.resolve(txApp, rel.collection, ref)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", ref, err)
+			}
+			ids = append(ids, related.Id)
+		}
+		setValues(record, rel.field.Name, rel.field.IsMultiple(), ids)
+	}
+	for _, field := range s.fields.files {
+		var files []*filesystem.File
+		for _, entry := range s.res.Files[field.Name] {
+			file, err := filesystem.NewFileFromBytes(imp.archive.Files[entry], path.Base(entry))
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", entry, err)
+			}
+			files = append(files, file)
+		}
+		setValues(record, field.Name, field.IsMultiple(), files)
+	}
+
+	if err := txApp.Save(record); err != nil {
+		return err
+	}
+	imp.records[key] = record
+	if actual, err := canonify.BuildPath(txApp, record, tpl, ""); err == nil &&
+		actual != s.item.Path {
+		s.item.Message = "imported as " + actual
+	}
+	return nil
+}
+
+func (imp *importer) startSchedule(s *step) {
+	if imp.opts.StartSchedule == nil {
+		s.item.Action = ActionSkip
+		s.item.Message = "schedules are not started by this import"
+		return
+	}
+	pipeline, err := imp.resolve(imp.app, "pipelines", s.item.Path)
+	if err != nil {
+		s.item.Action = ActionFailed
+		s.item.Message = err.Error()
+		return
+	}
+	mode, _ := s.res.Fields["mode"].(map[string]any)
+	scheduleID, err := imp.opts.StartSchedule(pipeline, mode)
+	if err != nil {
+		s.item.Action = ActionFailed
+		s.item.Message = err.Error()
+		return
+	}
+
+	record := core.NewRecord(s.collection)
+	record.Set("temporal_schedule_id", scheduleID)
+	record.Set("pipeline", pipeline.Id)
+	record.Set("owner", imp.orgID)
+	record.Set("mode", mode)
+	if err := imp.app.Save(record); err != nil {
+		s.item.Action = ActionFailed
+		s.item.Message = fmt.Sprintf("schedule %s started but not saved: %v", scheduleID, err)
+	}
+}
+
+// rebase moves a path of the source organization to the target one.
+func (imp *importer) rebase(p string) string {
+	p = canonify.NormalizePath(p)
+	if p == imp.source {
+		return imp.target
+	}
+	if rest, ok := strings.CutPrefix(p, imp.source+"/"); ok {
+		return imp.target + "/" + rest
+	}
+	return p
+}
+
+// rebaseYAML moves the paths of the source organization found in the
+// scalars of doc to the target one. It returns the new document and the
+// moved paths; doc is kept as is when no path moves.
+func (imp *importer) rebaseYAML(doc string) (string, []string) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		return doc, nil
+	}
+	var refs []string
+	walkYAML(&root, func(node *yaml.Node) {
+		if node.Kind != yaml.ScalarNode || strings.ContainsAny(node.Value, " \t\n") ||
+			!strings.HasPrefix(node.Value, imp.source+"/") {
+			return
+		}
+		node.Value = imp.rebase(node.Value)
+		refs = append(refs, node.Value)
+	})
+	if len(refs) == 0 || imp.source == imp.target {
+		return doc, refs
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil || enc.Close() != nil {
+		return doc, refs
+	}
+	return buf.String(), refs
+}
+
+func walkYAML(node *yaml.Node, fn func(*yaml.Node)) {
+	fn(node)
+	for _, child := range node.Content {
+		walkYAML(child, fn)
+	}
+}
+
+// resolvable reports whether ref is imported by the archive or exists.
+// Records of other organizations must be published to be referenced.
+func (imp *importer) resolvable(collection, ref string) bool {
+	if imp.planned[plannedKey(collection, ref)] {
+		return true
+	}
+	record, err := canonify.ResolveIn(imp.app, collection, ref)
+	if err != nil {
+		return false
+	}
+	return record.GetString("owner") == imp.orgID || record.GetBool("published")
+}
+
+// pathExists reports whether ref names a record imported by the archive or
+// existing, whatever its collection.
+func (imp *importer) pathExists(ref string) bool {
+	for key := range imp.planned {
+		if strings.HasSuffix(key, "|"+ref) {
+			return true
+		}
+	}
+	_, err := canonify.Resolve(imp.app, ref)
+	return err == nil
+}
+
+// resolve returns the record at ref, imported or existing.
+func (imp *importer) resolve(app core.App, collection, ref string) (*core.Record, error) {
+	if record, ok := imp.records[plannedKey(collection, ref)]; ok {
+		return record, nil
+	}
+	return canonify.ResolveIn(app, collection, ref)
+}
+
+func (s *step) fail(format string, args ...any) {
+	s.item.Action = ActionError
+	s.item.Message = fmt.Sprintf(format, args...)
+}
+
+// warnUnknownFields warns about the fields of the resource the collection
+// does not export, which are ignored.
+func (s *step) warnUnknownFields() {
+	known := map[string]bool{}
+	for _, field := range s.fields.data {
+		known[field.GetName()] = true
+	}
+	for _, rel := range s.fields.relations {
+		known[rel.field.Name] = true
+	}
+	for _, field := range s.fields.files {
+		known[field.Name] = true
+	}
+	var unknown []string
+	for name := range s.res.Fields {
+		if !known[name] {
+			unknown = append(unknown, "fields."+name)
+		}
+	}
+	for name := range s.res.Refs {
+		if !known[name] {
+			unknown = append(unknown, "refs."+name)
+		}
+	}
+	for name := range s.res.Files {
+		if !known[name] {
+			unknown = append(unknown, "files."+name)
+		}
+	}
+	slices.Sort(unknown)
+	for _, name := range unknown {
+		s.item.Warnings = append(s.item.Warnings, "unknown "+name+" ignored")
+	}
+}
+
+func setValues[T any](record *core.Record, field string, multiple bool, values []T) {
+	switch {
+	case multiple:
+		record.Set(field, values)
+	case len(values) == 0:
+		record.Set(field, nil)
+	default:
+		record.Set(field, values[0])
+	}
+}
+
+func plannedKey(collection, p string) string {
+	return collection + "|" + p
+}
+
+func kindIndex(name string) int {
+	for i, k := range kinds {
+		if k.collection == name {
+			return i
+		}
+	}
+	return len(kinds)
+}
+
+// normalizedJSON encodes value, decoding it first when it already is JSON,
+// so that equal values compare equal whatever their key order.
+func normalizedJSON(value any) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return string(raw)
+	}
+	if s, ok := decoded.(string); ok {
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			return string(raw)
+		}
+	}
+	out, _ := json.Marshal(decoded)
+	return string(out)
+}
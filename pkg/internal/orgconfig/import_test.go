@@ -0,0 +1,469 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package orgconfig
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataDir = "../../../test_pb_data"
+
+const (
+	orgAName = "usera-s-organization"
+	orgBName = "userb-s-organization"
+)
+
+const pipelineYAML = `name: Login
+steps:
+  - use: mobile-automation
+    with:
+      action_id: usera-s-organization/demo-wallet/login
+      runner_id: usera-s-organization/pixel
+`
+
+func newTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	t.Cleanup(app.Cleanup)
+	return app
+}
+
+func orgID(t *testing.T, app core.App, canonName string) string {
+	t.Helper()
+	org, err := app.FindFirstRecordByData("organizations", "canonified_name", canonName)
+	require.NoError(t, err)
+	return org.Id
+}
+
+func saveRecord(t *testing.T, app core.App, collection string, fields map[string]any) *core.Record {
+	t.Helper()
+	col, err := app.FindCollectionByNameOrId(collection)
+	require.NoError(t, err)
+	record := core.NewRecord(col)
+	for name, value := range fields {
+		record.Set(name, value)
+	}
+	require.NoError(t, app.Save(record))
+	return record
+}
+
+// seedOrganization fills the organization with one record of every kind.
+func seedOrganization(t *testing.T, app core.App, owner string) {
+	t.Helper()
+	issuer := saveRecord(t, app, "credential_issuers", map[string]any{
+		"owner": owner, "name": "Acme Issuer", "canonified_name": "acme-issuer",
+		"url": "https://issuer.example",
+	})
+	credential := saveRecord(t, app, "credentials", map[string]any{
+		"owner": owner, "name": "PID", "canonified_name": "pid",
+		"credential_issuer": issuer.Id, "format": "dc+sd-jwt", "secrets": "encrypted",
+	})
+	logo, err := filesystem.NewFileFromBytes([]byte("png-bytes"), "logo.png")
+	require.NoError(t, err)
+	wallet := saveRecord(t, app, "wallets", map[string]any{
+		"owner": owner, "name": "Demo Wallet", "canonified_name": "demo-wallet",
+		"logo": logo, "conformance_checks": map[string]any{"suite": "oid4vci"},
+	})
+	saveRecord(t, app, "wallet_actions", map[string]any{
+		"owner": owner, "name": "Login", "canonified_name": "login",
+		"wallet": wallet.Id, "code": "tap login", "category": "onboarding",
+	})
+	verifier := saveRecord(t, app, "verifiers", map[string]any{
+		"owner": owner, "name": "Shop", "canonified_name": "shop",
+		"url": "https://verifier.example", "standard_and_version": "openid4vp-1.0",
+		"format": []string{"SD-JWT"}, "signing_algorithms": []string{"ES256"},
+		"cryptographic_binding_methods": []string{"jwk"}, "description": "A shop",
+	})
+	saveRecord(t, app, "use_cases_verifications", map[string]any{
+		"owner": owner, "name": "Age Check", "canonified_name": "age-check",
+		"verifier": verifier.Id, "yaml": "query: age", "credentials": []string{credential.Id},
+	})
+	saveRecord(t, app, "custom_checks", map[string]any{
+		"owner": owner, "name": "Smoke", "canonified_name": "smoke", "yaml": "steps: []",
+	})
+	pipeline := saveRecord(t, app, "pipelines", map[string]any{
+		"owner": owner, "name": "Login Flow", "canonified_name": "login-flow",
+		"description": "Logs in", "yaml": pipelineYAML,
+	})
+	saveRecord(t, app, "schedules", map[string]any{
+		"owner": owner, "pipeline": pipeline.Id, "temporal_schedule_id": "Schedule_ID-1",
+		"mode": map[string]any{"mode": "daily"},
+	})
+}
+
+func exportedArchive(t *testing.T, app core.App, owner string) *Archive {
+	t.Helper()
+	archive, err := Export(app, owner, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf))
+	read, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	return read
+}
+
+func findResource(t *testing.T, archive *Archive, kind, path string) Resource {
+	t.Helper()
+	for _, res := range archive.Manifest.Resources {
+		if res.Kind == kind && res.key() == path {
+			return res
+		}
+	}
+	require.Failf(t, "resource not found", "%s %s", kind, path)
+	return Resource{}
+}
+
+type startedSchedule struct {
+	pipeline string
+	mode     map[string]any
+}
+
+func stubScheduleStarter(started *[]startedSchedule, err error) ScheduleStarter {
+	return func(pipeline *core.Record, mode map[string]any) (string, error) {
+		*started = append(*started, startedSchedule{pipeline: pipeline.Id, mode: mode})
+		return "Schedule_ID-imported", err
+	}
+}
+
+func TestExport(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	seedOrganization(t, app, orgA)
+
+	archive := exportedArchive(t, app, orgA)
+	require.Equal(t, Version, archive.Manifest.Version)
+	require.Equal(t, orgAName, archive.Manifest.Organization)
+	require.Equal(t, "2026-03-01T12:00:00Z", archive.Manifest.ExportedAt)
+
+	var order []string
+	for _, res := range archive.Manifest.Resources {
+		if len(order) == 0 || order[len(order)-1] != res.Kind {
+			order = append(order, res.Kind)
+		}
+	}
+	require.Equal(t, Kinds(), order)
+
+	credential := findResource(t, archive, "credentials", orgAName+"/acme-issuer/pid")
+	require.Equal(t, "PID", credential.Fields["name"])
+	require.NotContains(t, credential.Fields, "secrets")
+	require.NotContains(t, credential.Fields, "canonified_name")
+	require.Equal(
+		t,
+		[]string{orgAName + "/acme-issuer"},
+		credential.Refs["credential_issuer"],
+	)
+
+	wallet := findResource(t, archive, "wallets", orgAName+"/demo-wallet")
+	require.Equal(t, map[string]any{"suite": "oid4vci"}, wallet.Fields["conformance_checks"])
+	require.Len(t, wallet.Files["logo"], 1)
+	require.Equal(t, []byte("png-bytes"), archive.Files[wallet.Files["logo"][0]])
+
+	useCase := findResource(t, archive, "use_cases_verifications", orgAName+"/shop/age-check")
+	require.Equal(t, []string{orgAName + "/acme-issuer/pid"}, useCase.Refs["credentials"])
+
+	schedule := findResource(t, archive, "schedules", orgAName+"/login-flow")
+	require.Equal(t, map[string]any{"mode": "daily"}, schedule.Fields["mode"])
+	require.NotContains(t, schedule.Fields, "temporal_schedule_id")
+}
+
+func TestImportIntoAnotherOrganization(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	orgB := orgID(t, app, orgBName)
+	seedOrganization(t, app, orgA)
+	archive := exportedArchive(t, app, orgA)
+
+	var started []startedSchedule
+	report, err := Import(app, orgB, archive, Options{
+		StartSchedule: stubScheduleStarter(&started, nil),
+	})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, orgBName, report.Organization)
+	require.Equal(t, map[string]int{ActionCreate: len(archive.Manifest.Resources)}, report.Counts)
+
+	action, err := canonify.ResolveIn(app, "wallet_actions", orgBName+"/demo-wallet/login")
+	require.NoError(t, err)
+	require.Equal(t, orgB, action.GetString("owner"))
+	require.Equal(t, "tap login", action.GetString("code"))
+	wallet, err := canonify.ResolveIn(app, "wallets", orgBName+"/demo-wallet")
+	require.NoError(t, err)
+	require.Equal(t, wallet.Id, action.GetString("wallet"))
+	require.NotEmpty(t, wallet.GetString("logo"))
+
+	useCase, err := canonify.ResolveIn(app, "use_cases_verifications", orgBName+"/shop/age-check")
+	require.NoError(t, err)
+	credential, err := canonify.ResolveIn(app, "credentials", orgBName+"/acme-issuer/pid")
+	require.NoError(t, err)
+	require.Equal(t, []string{credential.Id}, useCase.GetStringSlice("credentials"))
+	require.Empty(t, credential.GetString("secrets"))
+
+	pipeline, err := canonify.ResolveIn(app, "pipelines", orgBName+"/login-flow")
+	require.NoError(t, err)
+	require.Contains(t, pipeline.GetString("yaml"), orgBName+"/demo-wallet/login")
+	require.NotContains(t, pipeline.GetString("yaml"), orgAName)
+
+	var pipelineItem Item
+	for _, item := range report.Items {
+		if item.Kind == "pipelines" {
+			pipelineItem = item
+		}
+	}
+	require.Equal(
+		t,
+		[]string{"yaml references " + orgBName + "/pixel, which does not exist in " + orgBName},
+		pipelineItem.Warnings,
+	)
+
+	require.Equal(
+		t,
+		[]startedSchedule{{pipeline: pipeline.Id, mode: map[string]any{"mode": "daily"}}},
+		started,
+	)
+	schedule, err := app.FindFirstRecordByFilter(
+		"schedules",
+		"pipeline = {:pipeline}",
+		dbx.Params{"pipeline": pipeline.Id},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Schedule_ID-imported", schedule.GetString("temporal_schedule_id"))
+	require.Equal(t, orgB, schedule.GetString("owner"))
+}
+
+func TestImportDryRunChangesNothing(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	orgB := orgID(t, app, orgBName)
+	seedOrganization(t, app, orgA)
+	archive := exportedArchive(t, app, orgA)
+
+	var started []startedSchedule
+	report, err := Import(app, orgB, archive, Options{
+		DryRun:        true,
+		StartSchedule: stubScheduleStarter(&started, nil),
+	})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.False(t, report.Applied)
+	require.Equal(t, len(archive.Manifest.Resources), report.Counts[ActionCreate])
+	require.Empty(t, started)
+
+	_, err = canonify.ResolveIn(app, "wallets", orgBName+"/demo-wallet")
+	require.Error(t, err)
+}
+
+func TestImportConflicts(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	seedOrganization(t, app, orgA)
+	archive := exportedArchive(t, app, orgA)
+
+	wallet := findResource(t, archive, "wallets", orgAName+"/demo-wallet")
+	wallet.Fields["description"] = "from the archive"
+
+	var started []startedSchedule
+	starter := stubScheduleStarter(&started, nil)
+
+	report, err := Import(app, orgA, archive, Options{StartSchedule: starter})
+	require.NoError(t, err)
+	require.False(t, report.Applied)
+	require.True(t, report.Blocked())
+	require.Equal(t, map[string]int{ActionConflict: len(archive.Manifest.Resources)}, report.Counts)
+
+	report, err = Import(app, orgA, archive, Options{
+		OnConflict:    ConflictSkip,
+		StartSchedule: starter,
+	})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, map[string]int{ActionSkip: len(archive.Manifest.Resources)}, report.Counts)
+	existing, err := canonify.ResolveIn(app, "wallets", orgAName+"/demo-wallet")
+	require.NoError(t, err)
+	require.Empty(t, existing.GetString("description"))
+
+	report, err = Import(app, orgA, archive, Options{
+		OnConflict:    ConflictOverwrite,
+		StartSchedule: starter,
+	})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, map[string]int{
+		ActionUpdate: len(archive.Manifest.Resources) - 1,
+		ActionSkip:   1,
+	}, report.Counts)
+	updated, err := canonify.ResolveIn(app, "wallets", orgAName+"/demo-wallet")
+	require.NoError(t, err)
+	require.Equal(t, existing.Id, updated.Id)
+	require.Equal(t, "from the archive", updated.GetString("description"))
+	require.Empty(t, started)
+}
+
+func TestImportReportsInvalidResources(t *testing.T) {
+	app := newTestApp(t)
+	orgB := orgID(t, app, orgBName)
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:      Version,
+			Organization: orgAName,
+			Resources: []Resource{
+				{Kind: "plugins", Path: orgAName + "/x"},
+				{Kind: "wallets", Path: "someone-else/wallet", Fields: map[string]any{"name": "W"}},
+				{
+					Kind:   "wallet_actions",
+					Path:   orgAName + "/missing/login",
+					Fields: map[string]any{"name": "Login", "code": "x", "category": "onboarding"},
+					Refs:   map[string][]string{"wallet": {orgAName + "/missing"}},
+				},
+				{
+					Kind:   "custom_checks",
+					Path:   orgAName + "/check",
+					Fields: map[string]any{"name": "Check", "yaml": "x", "colour": "red"},
+				},
+			},
+		},
+		Files: map[string][]byte{},
+	}
+
+	report, err := Import(app, orgB, archive, Options{})
+	require.NoError(t, err)
+	require.False(t, report.Applied)
+	require.Equal(t, map[string]int{ActionError: 3, ActionCreate: 1}, report.Counts)
+
+	messages := map[string]string{}
+	warnings := map[string][]string{}
+	for _, item := range report.Items {
+		messages[item.Kind] = item.Message
+		warnings[item.Kind] = item.Warnings
+	}
+	require.Equal(t, `unknown kind "plugins"`, messages["plugins"])
+	require.Equal(t, "path is outside the exported organization "+orgAName, messages["wallets"])
+	require.Equal(
+		t,
+		"unresolved reference wallet: "+orgBName+"/missing",
+		messages["wallet_actions"],
+	)
+	require.Equal(t, []string{"unknown fields.colour ignored"}, warnings["custom_checks"])
+
+	_, err = canonify.ResolveIn(app, "custom_checks", orgBName+"/check")
+	require.Error(t, err)
+}
+
+func TestImportReferencesPublishedRecordsOfOtherOrganizations(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	orgB := orgID(t, app, orgBName)
+	issuer := saveRecord(t, app, "credential_issuers", map[string]any{
+		"owner": orgA, "name": "Issuer", "canonified_name": "issuer",
+		"url": "https://issuer.example",
+	})
+	credential := saveRecord(t, app, "credentials", map[string]any{
+		"owner": orgA, "name": "PID", "canonified_name": "pid",
+		"credential_issuer": issuer.Id,
+	})
+	useCase := func(name string) Resource {
+		return Resource{
+			Kind: "use_cases_verifications",
+			Path: orgBName + "/shop/" + name,
+			Fields: map[string]any{
+				"name": name, "yaml": "query: age",
+			},
+			Refs: map[string][]string{
+				"verifier":    {orgBName + "/shop"},
+				"credentials": {orgAName + "/issuer/pid"},
+			},
+		}
+	}
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:      Version,
+			Organization: orgBName,
+			Resources: []Resource{
+				{
+					Kind: "verifiers",
+					Path: orgBName + "/shop",
+					Fields: map[string]any{
+						"name": "Shop", "url": "https://verifier.example",
+						"standard_and_version": "openid4vp-1.0", "format": []string{"SD-JWT"},
+						"signing_algorithms":            []string{"ES256"},
+						"cryptographic_binding_methods": []string{"jwk"},
+						"description":                   "A shop",
+					},
+				},
+				useCase("age"),
+			},
+		},
+	}
+
+	report, err := Import(app, orgB, archive, Options{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, ActionError, report.Items[1].Action)
+
+	credential.Set("published", true)
+	require.NoError(t, app.Save(credential))
+	report, err = Import(app, orgB, archive, Options{})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	imported, err := canonify.ResolveIn(app, "use_cases_verifications", orgBName+"/shop/age")
+	require.NoError(t, err)
+	require.Equal(t, []string{credential.Id}, imported.GetStringSlice("credentials"))
+}
+
+func TestImportReportsSchedulesThatFailToStart(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	orgB := orgID(t, app, orgBName)
+	seedOrganization(t, app, orgA)
+	archive := exportedArchive(t, app, orgA)
+
+	var started []startedSchedule
+	report, err := Import(app, orgB, archive, Options{
+		StartSchedule: stubScheduleStarter(&started, errors.New("temporal is down")),
+	})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, 1, report.Counts[ActionFailed])
+	last := report.Items[len(report.Items)-1]
+	require.Equal(t, "schedules", last.Kind)
+	require.Equal(t, "temporal is down", last.Message)
+
+	_, err = canonify.ResolveIn(app, "pipelines", orgBName+"/login-flow")
+	require.NoError(t, err)
+}
+
+func TestExportedFilesAreReadable(t *testing.T) {
+	app := newTestApp(t)
+	orgA := orgID(t, app, orgAName)
+	seedOrganization(t, app, orgA)
+	orgB := orgID(t, app, orgBName)
+	archive := exportedArchive(t, app, orgA)
+
+	_, err := Import(app, orgB, archive, Options{})
+	require.NoError(t, err)
+
+	wallet, err := canonify.ResolveIn(app, "wallets", orgBName+"/demo-wallet")
+	require.NoError(t, err)
+	fsys, err := app.NewFilesystem()
+	require.NoError(t, err)
+	defer fsys.Close()
+	r, err := fsys.GetFile(wallet.BaseFilesPath() + "/" + wallet.GetString("logo"))
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("png-bytes"), content)
+}
@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package orgconfig exports the configuration of an organization, from its
+// credential issuers to its pipelines and schedules, to a versioned archive,
+// and imports such an archive into another organization. Resources are keyed
+// by their canonify.BuildPath path, so references between them survive the
+// move even though record ids do not.
+package orgconfig
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Version is the archive format written by Export. Import reads this
+	// version and the ones before it.
+	Version = 1
+
+	// ManifestName is the archive entry listing the resources.
+	ManifestName = "credimi.yaml"
+	// filesDir holds the files of the resources, under their path.
+	filesDir = "files"
+
+	// MaxArchiveBytes bounds an archive, compressed or not.
+	MaxArchiveBytes = 32 << 20
+)
+
+// kind is a collection included in the archive.
+type kind struct {
+	collection string
+	// omit lists the fields not exported, e.g. secrets, which are encrypted
+	// with a key of the instance.
+	omit []string
+	// rebaseYAML rewrites, on import, the paths of the source organization
+	// found in the yaml field.
+	rebaseYAML bool
+}
+
+const scheduleKind = "schedules"
+
+// kinds lists the exported collections, each one after those it references.
+var kinds = []kind{
+	{collection: "credential_issuers"},
+	{collection: "credentials", omit: []string{"secrets"}},
+	{collection: "wallets"},
+	{collection: "wallet_actions"},
+	{collection: "verifiers"},
+	{collection: "use_cases_verifications", omit: []string{"secrets"}},
+	{collection: "custom_checks"},
+	{collection: "pipelines", rebaseYAML: true},
+	{collection: scheduleKind, omit: []string{"temporal_schedule_id"}},
+}
+
+// Kinds returns the collections included in an archive, in import order.
+func Kinds() []string {
+	names := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		names = append(names, k.collection)
+	}
+	return names
+}
+
+func findKind(name string) (kind, bool) {
+	for _, k := range kinds {
+		if k.collection == name {
+			return k, true
+		}
+	}
+	return kind{}, false
+}
+
+// Manifest is the content of ManifestName.
+type Manifest struct {
+	Version int `yaml:"version"`
+	// Organization is the canonified name of the exported organization.
+	// Paths starting with it are moved to the organization importing them.
+	Organization string     `yaml:"organization"`
+	ExportedAt   string     `yaml:"exported_at,omitempty"`
+	Resources    []Resource `yaml:"resources"`
+}
+
+// Resource is a record of one of the exported collections. Path is empty
+// for schedules, which have none: they are known by their pipeline.
+type Resource struct {
+	Kind   string         `yaml:"kind"`
+	Path   string         `yaml:"path,omitempty"`
+	Fields map[string]any `yaml:"fields,omitempty"`
+	// Refs holds the paths of the records of each relation field.
+	Refs map[string][]string `yaml:"refs,omitempty"`
+	// Files holds the archive entries of each file field.
+	Files map[string][]string `yaml:"files,omitempty"`
+}
+
+// Archive is a manifest with the files of its resources.
+type Archive struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Write encodes the archive as a zip file.
+func (a *Archive) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest, err := yaml.Marshal(a.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeEntry(zw, ManifestName, manifest); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(a.Files))
+	for name := range a.Files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := writeEntry(zw, name, a.Files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeEntry(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Read decodes an archive written by Write, checking its version and that
+// the files of its resources are included.
+func Read(data []byte) (*Archive, error) {
+	if len(data) > MaxArchiveBytes {
+		return nil, fmt.Errorf("archive is larger than %d bytes", MaxArchiveBytes)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	archive := &Archive{Files: map[string][]byte{}}
+	var manifest []byte
+	remaining := int64(MaxArchiveBytes)
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !validEntryName(entry.Name) {
+			return nil, fmt.Errorf("invalid archive entry %q", entry.Name)
+		}
+		content, err := readEntry(entry, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+		if entry.Name == ManifestName {
+			manifest = content
+			continue
+		}
+		archive.Files[entry.Name] = content
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", ManifestName)
+	}
+
+	if err := yaml.Unmarshal(manifest, &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if v := archive.Manifest.Version; v < 1 || v > Version {
+		return nil, fmt.Errorf("unsupported archive version %d (supported: 1 to %d)", v, Version)
+	}
+	if archive.Manifest.Organization == "" {
+		return nil, fmt.Errorf("%s has no organization", ManifestName)
+	}
+	for _, res := range archive.Manifest.Resources {
+		for _, names := range res.Files {
+			for _, name := range names {
+				if _, ok := archive.Files[name]; !ok {
+					return nil, fmt.Errorf("archive has no file %s for %s", name, res.Path)
+				}
+			}
+		}
+	}
+	return archive, nil
+}
+
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, errors.New("archive content is larger than the limit")
+	}
+	return content, nil
+}
+
+func validEntryName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, "/") && path.Clean(name) == name &&
+		!slices.Contains(strings.Split(name, "/"), "..")
+}
+
+// filePath is the archive entry of a file of the resource at resPath.
+func filePath(resPath, field, name string) string {
+	return path.Join(filesDir, resPath, field, name)
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package orgconfig
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func zipEntries(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:      Version,
+			Organization: "acme",
+			Resources: []Resource{{
+				Kind:   "wallets",
+				Path:   "acme/wallet",
+				Fields: map[string]any{"name": "Wallet", "published": true},
+				Files:  map[string][]string{"logo": {"files/acme/wallet/logo/logo.png"}},
+			}},
+		},
+		Files: map[string][]byte{"files/acme/wallet/logo/logo.png": []byte("png")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf))
+	read, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, archive.Manifest, read.Manifest)
+	require.Equal(t, archive.Files, read.Files)
+}
+
+func TestReadRejectsInvalidArchives(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "not a zip", data: []byte("nope"), wantErr: "invalid archive"},
+		{
+			name:    "no manifest",
+			data:    zipEntries(t, map[string]string{"other.txt": "x"}),
+			wantErr: "archive has no credimi.yaml",
+		},
+		{
+			name: "future version",
+			data: zipEntries(t, map[string]string{
+				ManifestName: "version: 99\norganization: acme\n",
+			}),
+			wantErr: "unsupported archive version 99",
+		},
+		{
+			name:    "no organization",
+			data:    zipEntries(t, map[string]string{ManifestName: "version: 1\n"}),
+			wantErr: "has no organization",
+		},
+		{
+			name: "missing file",
+			data: zipEntries(t, map[string]string{
+				ManifestName: "version: 1\norganization: acme\nresources:\n" +
+					"  - kind: wallets\n    path: acme/w\n    files:\n      logo: [files/x.png]\n",
+			}),
+			wantErr: "archive has no file files/x.png",
+		},
+		{
+			name: "path traversal",
+			data: zipEntries(t, map[string]string{
+				ManifestName:    "version: 1\norganization: acme\n",
+				"../escape.txt": "x",
+			}),
+			wantErr: "invalid archive entry",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Read(tc.data)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, ConflictFail, policy)
+
+	policy, err = ParseConflictPolicy("overwrite")
+	require.NoError(t, err)
+	require.Equal(t, ConflictOverwrite, policy)
+
+	_, err = ParseConflictPolicy("merge")
+	require.Error(t, err)
+}
+
+func TestKindsListsReferencedCollectionsFirst(t *testing.T) {
+	order := Kinds()
+	before := func(a, b string) {
+		t.Helper()
+		require.Less(t, kindIndex(a), kindIndex(b), "%s must come before %s", a, b)
+	}
+	before("credential_issuers", "credentials")
+	before("credentials", "use_cases_verifications")
+	before("wallets", "wallet_actions")
+	before("verifiers", "use_cases_verifications")
+	before("pipelines", "schedules")
+	require.Len(t, order, len(kinds))
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package orgrole decides what the members of an organization may do from
+// their role in orgAuthorizations.
+package orgrole
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Role is the name of an orgRoles record.
+type Role string
+
+const (
+	Owner          Role = "owner"
+	Admin          Role = "admin"
+	PipelineAuthor Role = "pipeline_author"
+	RunnerOperator Role = "runner_operator"
+	Viewer         Role = "viewer"
+	// Member is the role organizations had before the finer roles existed.
+	// It keeps its access to everything but the administration of the
+	// organization.
+	Member Role = "member"
+)
+
+// Roles lists every role, from the most to the least privileged.
+var Roles = []Role{Owner, Admin, Member, PipelineAuthor, RunnerOperator, Viewer}
+
+// pipelineAuthorPermissions are granted to pipeline authors on top of the
+// read permissions.
+var pipelineAuthorPermissions = []apikey.Permission{
+	apikey.PermissionIntegrationsRun,
+	apikey.PermissionIssuersWrite,
+	apikey.PermissionPipelinesRun,
+	apikey.PermissionSchedulesWrite,
+	apikey.PermissionTrustedListsWrite,
+	apikey.PermissionVerifiersWrite,
+	apikey.PermissionWalletsWrite,
+	apikey.PermissionWorkflowsRun,
+}
+
+// runnerOperatorPermissions are granted to runner operators on top of the
+// read permissions.
+var runnerOperatorPermissions = []apikey.Permission{
+	apikey.PermissionResultsWrite,
+	apikey.PermissionRunnersHeartbeat,
+	apikey.PermissionRunnersWrite,
+}
+
+// adminPermissions are only granted to owners and admins.
+var adminPermissions = []apikey.Permission{
+	apikey.PermissionAuditRead,
+	apikey.PermissionConfigWrite,
+	apikey.PermissionWebhooksWrite,
+}
+
+// Membership is the organization of a user and the role they have in it.
+type Membership struct {
+	Organization string
+	Role         Role
+}
+
+// Allows reports whether role may call a route declaring permission. Every
+// role may read, and manage its own API keys. Routes that declare no
+// permission are only open to owners and admins.
+func Allows(role Role, permission apikey.Permission) bool {
+	switch {
+	case role == Owner || role == Admin:
+		return true
+	case permission == "":
+		return false
+	case slices.Contains(adminPermissions, permission):
+		return false
+	case role == Member:
+		return true
+	case permission == apikey.PermissionAPIKeysWrite || isRead(permission):
+		return slices.Contains(Roles, role)
+	case role == PipelineAuthor:
+		return slices.Contains(pipelineAuthorPermissions, permission)
+	case role == RunnerOperator:
+		return slices.Contains(runnerOperatorPermissions, permission)
+	}
+	return false
+}
+
+// Find returns the membership of userID. It returns sql.ErrNoRows when the
+// user belongs to no organization.
+func Find(app core.App, userID string) (Membership, error) {
+	authorization, err := app.FindFirstRecordByFilter(
+		"orgAuthorizations",
+		"user = {:user}",
+		dbx.Params{"user": userID},
+	)
+	if err != nil {
+		return Membership{}, err
+	}
+	role, err := app.FindRecordById("orgRoles", authorization.GetString("role"))
+	if err != nil {
+		return Membership{}, fmt.Errorf("failed to find role of user %s: %w", userID, err)
+	}
+	return Membership{
+		Organization: authorization.GetString("organization"),
+		Role:         Role(role.GetString("name")),
+	}, nil
+}
+
+func isRead(permission apikey.Permission) bool {
+	return strings.HasSuffix(string(permission), ":read")
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package orgrole
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+const orgroleTestDataDir = "../../../test_pb_data"
+
+func TestAllows(t *testing.T) {
+	cases := []struct {
+		permission apikey.Permission
+		allowed    []Role
+	}{
+		{"", []Role{Owner, Admin}},
+		{apikey.PermissionPipelinesRead, Roles},
+		{apikey.PermissionAPIKeysWrite, Roles},
+		{apikey.PermissionPipelinesRun, []Role{Owner, Admin, Member, PipelineAuthor}},
+		{apikey.PermissionSchedulesWrite, []Role{Owner, Admin, Member, PipelineAuthor}},
+		{apikey.PermissionWalletsWrite, []Role{Owner, Admin, Member, PipelineAuthor}},
+		{apikey.PermissionRunnersHeartbeat, []Role{Owner, Admin, Member, RunnerOperator}},
+		{apikey.PermissionResultsWrite, []Role{Owner, Admin, Member, RunnerOperator}},
+		{apikey.PermissionAuditRead, []Role{Owner, Admin}},
+		{apikey.PermissionWebhooksRead, Roles},
+		{apikey.PermissionWebhooksWrite, []Role{Owner, Admin}},
+		{apikey.PermissionConfigRead, Roles},
+		{apikey.PermissionConfigWrite, []Role{Owner, Admin}},
+	}
+	for _, tt := range cases {
+		for _, role := range append(slices.Clone(Roles), "unknown") {
+			want := slices.Contains(tt.allowed, role)
+			require.Equal(t, want, Allows(role, tt.permission), "%s %s", role, tt.permission)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	app, err := tests.NewTestApp(orgroleTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	authorization, err := app.FindFirstRecordByData("orgAuthorizations", "user", user.Id)
+	require.NoError(t, err)
+
+	membership, err := Find(app, user.Id)
+	require.NoError(t, err)
+	require.Equal(t, Membership{
+		Organization: authorization.GetString("organization"),
+		Role:         Owner,
+	}, membership)
+
+	_, err = Find(app, "missing")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/forkbombeu/credimi/pkg/internal/pbutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const apiKeysCollectionName = "api_keys"
+
+var errAuditLogAppendOnly = errors.New("audit log entries cannot be changed or deleted")
+
+// RegisterAuditHooks keeps the audit log append-only and records the
+// pipeline and API key changes made through the records API. Entries are
+// only deleted with their organization.
+func RegisterAuditHooks(app core.App) {
+	app.OnRecordUpdate(audit.Collection).BindFunc(func(_ *core.RecordEvent) error {
+		return errAuditLogAppendOnly
+	})
+	app.OnRecordDelete(audit.Collection).BindFunc(func(e *core.RecordEvent) error {
+		// The cascade deleting an organization removes its entries after
+		// the organization itself, in the same transaction.
+		_, err := e.App.FindRecordById("organizations", e.Record.GetString("owner"))
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.Next()
+		}
+		if err != nil {
+			return err
+		}
+		return errAuditLogAppendOnly
+	})
+
+	app.OnRecordCreateRequest(pipelinesCollectionName).BindFunc(
+		func(e *core.RecordRequestEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			recordAudit(e, audit.ActionPipelineCreated, audit.Diff(nil, e.Record))
+			return nil
+		},
+	)
+	app.OnRecordUpdateRequest(pipelinesCollectionName).BindFunc(
+		func(e *core.RecordRequestEvent) error {
+			original := e.Record.Original()
+			changes := audit.Diff(original, e.Record)
+			action := audit.ActionPipelineUpdated
+			if onlyPublicationStatusChanged(original, e.Record) {
+				action = audit.ActionPipelineUnpublished
+				if e.Record.GetBool("published") {
+					action = audit.ActionPipelinePublished
+				}
+			}
+			if err := e.Next(); err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				recordAudit(e, action, changes)
+			}
+			return nil
+		},
+	)
+	app.OnRecordDeleteRequest(pipelinesCollectionName).BindFunc(
+		func(e *core.RecordRequestEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			recordAudit(e, audit.ActionPipelineDeleted, audit.Diff(e.Record, nil))
+			return nil
+		},
+	)
+
+	app.OnRecordUpdateRequest(apiKeysCollectionName).BindFunc(
+		func(e *core.RecordRequestEvent) error {
+			original := e.Record.Original()
+			changes := audit.Diff(original, e.Record)
+			action := audit.ActionAPIKeyUpdated
+			if !original.GetBool("revoked") && e.Record.GetBool("revoked") {
+				action = audit.ActionAPIKeyRevoked
+			}
+			if err := e.Next(); err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				recordAudit(e, action, changes)
+			}
+			return nil
+		},
+	)
+	app.OnRecordDeleteRequest(apiKeysCollectionName).BindFunc(
+		func(e *core.RecordRequestEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			recordAudit(e, audit.ActionAPIKeyDeleted, audit.Diff(e.Record, nil))
+			return nil
+		},
+	)
+}
+
+// recordAudit records an action on e.Record in the organization owning it:
+// the owner field, or the organization of the user owning an API key. The
+// request already succeeded, so failures are only logged.
+func recordAudit(e *core.RecordRequestEvent, action string, changes map[string]audit.Change) {
+	orgID := e.Record.GetString("owner")
+	if e.Record.Collection().Name == apiKeysCollectionName {
+		if userID := e.Record.GetString("user"); userID != "" {
+			orgID, _ = pbutils.GetUserOrganizationID(e.App, userID)
+		}
+	}
+
+	err := audit.Record(e.RequestEvent, audit.Entry{
+		Organization: orgID,
+		Action:       action,
+		ResourceType: e.Record.Collection().Name,
+		ResourceID:   e.Record.Id,
+		Changes:      changes,
+	})
+	if err != nil {
+		log.Printf("failed to record audit entry for %s %s: %v", action, e.Record.Id, err)
+	}
+}
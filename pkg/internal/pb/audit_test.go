@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/audit"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHooksKeepTheLogAppendOnly(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	RegisterAuditHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	entry := core.NewRecord(mustFindCollection(t, app, audit.Collection))
+	entry.Set("owner", orgID)
+	entry.Set("action", audit.ActionPipelineUpdated)
+	entry.Set("actor_type", audit.ActorUser)
+	require.NoError(t, app.Save(entry))
+
+	entry.Set("action", audit.ActionPipelineDeleted)
+	require.ErrorIs(t, app.Save(entry), errAuditLogAppendOnly)
+
+	require.ErrorIs(t, app.Delete(entry), errAuditLogAppendOnly)
+	_, err = app.FindRecordById(audit.Collection, entry.Id)
+	require.NoError(t, err)
+}
+
+func TestAuditHooksDeleteEntriesWithTheirOrganization(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	RegisterAuditHooks(app)
+
+	org := core.NewRecord(mustFindCollection(t, app, "organizations"))
+	org.Set("name", "audit-deleted-org")
+	require.NoError(t, app.Save(org))
+	entry := core.NewRecord(mustFindCollection(t, app, audit.Collection))
+	entry.Set("owner", org.Id)
+	entry.Set("action", audit.ActionPipelineUpdated)
+	entry.Set("actor_type", audit.ActorUser)
+	require.NoError(t, app.Save(entry))
+
+	require.NoError(t, app.Delete(org))
+	_, err = app.FindRecordById(audit.Collection, entry.Id)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAuditHooksRecordPipelineAndAPIKeyChanges(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	RegisterPipelineHooks(app)
+	RegisterAuditHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+
+	triggerUpdate := func(record *core.Record) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/", nil)
+		req.RemoteAddr = "192.0.2.10:5000"
+		event := &core.RecordRequestEvent{
+			RequestEvent: &core.RequestEvent{
+				App:   app,
+				Auth:  user,
+				Event: router.Event{Request: req, Response: httptest.NewRecorder()},
+			},
+			Record: record,
+		}
+		event.Collection = record.Collection()
+		err := app.OnRecordUpdateRequest(record.Collection().Name).Trigger(
+			event,
+			func(e *core.RecordRequestEvent) error { return e.App.Save(e.Record) },
+		)
+		require.NoError(t, err)
+	}
+
+	pipelineRecord := createTestPipelineRecord(t, app)
+	pipelineRecord, err = app.FindRecordById(pipelinesCollectionName, pipelineRecord.Id)
+	require.NoError(t, err)
+	pipelineRecord.Set("published", true)
+	triggerUpdate(pipelineRecord)
+
+	pipelineRecord, err = app.FindRecordById(pipelinesCollectionName, pipelineRecord.Id)
+	require.NoError(t, err)
+	pipelineRecord.Set("published", false)
+	triggerUpdate(pipelineRecord)
+
+	pipelineRecord, err = app.FindRecordById(pipelinesCollectionName, pipelineRecord.Id)
+	require.NoError(t, err)
+	pipelineRecord.Set("description", "edited after unpublishing")
+	triggerUpdate(pipelineRecord)
+
+	apiKey := core.NewRecord(mustFindCollection(t, app, apiKeysCollectionName))
+	apiKey.Set("name", "ci")
+	apiKey.Set("key", "hash")
+	apiKey.Set("user", user.Id)
+	require.NoError(t, app.Save(apiKey))
+	apiKey, err = app.FindRecordById(apiKeysCollectionName, apiKey.Id)
+	require.NoError(t, err)
+	apiKey.Set("revoked", true)
+	triggerUpdate(apiKey)
+
+	logs, _, err := audit.Find(app, orgID, audit.Filter{}, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 4)
+
+	byAction := map[string]audit.Log{}
+	for _, log := range logs {
+		byAction[log.Action] = log
+	}
+	published := byAction[audit.ActionPipelinePublished]
+	require.Equal(t, pipelineRecord.Id, published.ResourceID)
+	require.Equal(t, pipelinesCollectionName, published.ResourceType)
+	require.Equal(t, audit.Change{Before: false, After: true}, published.Changes["published"])
+	require.Equal(t, "192.0.2.10", published.IP)
+	require.Equal(t, user.Id, published.Actor.ID)
+
+	require.Equal(
+		t,
+		audit.Change{Before: true, After: false},
+		byAction[audit.ActionPipelineUnpublished].Changes["published"],
+	)
+	require.Equal(
+		t,
+		audit.Change{Before: "test pipeline", After: "edited after unpublishing"},
+		byAction[audit.ActionPipelineUpdated].Changes["description"],
+	)
+
+	revoked := byAction[audit.ActionAPIKeyRevoked]
+	require.Equal(t, apiKey.Id, revoked.ResourceID)
+	require.Equal(t, audit.Change{Before: false, After: true}, revoked.Changes["revoked"])
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"log"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// idempotencyCleanupJob is the cron job id removing expired idempotency keys.
+const idempotencyCleanupJob = "idempotencyKeysCleanup"
+
+// RegisterIdempotencyHooks removes expired idempotency keys every hour.
+// Expired keys are already ignored on lookup, this only keeps the table small.
+func RegisterIdempotencyHooks(app core.App) {
+	app.Cron().MustAdd(idempotencyCleanupJob, "0 * * * *", func() {
+		cleanupIdempotencyKeys(app)
+	})
+}
+
+func cleanupIdempotencyKeys(app core.App) {
+	deleted, err := idempotency.DeleteExpired(app, time.Now())
+	if err != nil {
+		log.Printf("failed to delete expired idempotency keys: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d expired idempotency keys", deleted)
+	}
+}
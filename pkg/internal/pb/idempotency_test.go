@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/idempotency"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterIdempotencyHooks(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	RegisterIdempotencyHooks(app)
+	var registered bool
+	for _, job := range app.Cron().Jobs() {
+		registered = registered || job.Id() == idempotencyCleanupJob
+	}
+	require.True(t, registered)
+
+	expired, _, err := idempotency.Claim(app, "user-1", "old", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	fresh, _, err := idempotency.Claim(app, "user-1", "fresh", time.Now())
+	require.NoError(t, err)
+
+	cleanupIdempotencyKeys(app)
+
+	_, err = idempotency.Reload(app, expired)
+	require.Error(t, err)
+	_, err = idempotency.Reload(app, fresh)
+	require.NoError(t, err)
+}
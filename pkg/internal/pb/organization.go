@@ -11,6 +11,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/hooks"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -49,6 +50,16 @@ var organizationPublicationCollections = []organizationPublicationCollection{
 
 const defaultMaxPipelinesInQueue = 1
 
+// organizationSuperuserOnlyIntFields are the limits members cannot change
+// on their own organization.
+var organizationSuperuserOnlyIntFields = []string{
+	"max_pipelines_in_queue",
+	quota.FieldConcurrentRuns,
+	quota.FieldRunsPerDay,
+	quota.FieldStorageMB,
+	quota.FieldRequestsPerMinute,
+}
+
 func HookOrganizations(app core.App) {
 	registerOrganizationNamespaceHooks(app)
 	registerOrganizationPublicationHooks(app)
@@ -180,8 +191,10 @@ func registerOrganizationProtectedFieldsHooks(app core.App) {
 			return e.Next()
 		}
 
-		if e.Record.GetInt("max_pipelines_in_queue") != original.GetInt("max_pipelines_in_queue") {
-			e.Record.Set("max_pipelines_in_queue", original.GetInt("max_pipelines_in_queue"))
+		for _, field := range organizationSuperuserOnlyIntFields {
+			if e.Record.GetInt(field) != original.GetInt(field) {
+				e.Record.Set(field, original.GetInt(field))
+			}
 		}
 
 		if e.Record.GetBool("published") != original.GetBool("published") {
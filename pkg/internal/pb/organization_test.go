@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
@@ -255,6 +256,33 @@ func TestOrganizationProtectedFieldsHooks_RevertsMaxPipelinesInQueueForUser(t *t
 	require.Equal(t, 3, event.Record.GetInt("max_pipelines_in_queue"))
 }
 
+func TestOrganizationProtectedFieldsHooks_RevertsQuotaFieldsForUser(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	registerOrganizationProtectedFieldsHooks(app)
+
+	org := loadOrgWithMaxPipelines(t, app, 3)
+	org.Set(quota.FieldRunsPerDay, 5)
+	require.NoError(t, app.Save(org))
+	org, err = app.FindRecordById("organizations", org.Id)
+	require.NoError(t, err)
+
+	userAuth := core.NewRecord(mustFindCollection(t, app, "users"))
+	event := newOrganizationUpdateRequestEvent(app, org, userAuth)
+	event.Record.Set(quota.FieldRunsPerDay, 0)
+	event.Record.Set(quota.FieldRequestsPerMinute, 100)
+
+	err = app.OnRecordUpdateRequest("organizations").Trigger(
+		event,
+		func(_ *core.RecordRequestEvent) error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, 5, event.Record.GetInt(quota.FieldRunsPerDay))
+	require.Equal(t, 0, event.Record.GetInt(quota.FieldRequestsPerMinute))
+}
+
 func TestOrganizationProtectedFieldsHooks_AllowsMaxPipelinesInQueueForSuperuser(t *testing.T) {
 	app, err := tests.NewTestApp(testDataDir)
 	require.NoError(t, err)
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+var startWebhookDeliveryWorkflow = func(
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return workflows.NewWebhookDeliveryWorkflow().Start(workflows.DefaultNamespace, input)
+}
+
+// RegisterWebhookHooks starts the delivery workflow of every queued webhook
+// delivery, and emits the webhook events that follow record changes: runs
+// starting and runners going offline.
+func RegisterWebhookHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess(webhook.DeliveryCollection).BindFunc(
+		func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			startWebhookDelivery(e.App, e.Record)
+			return nil
+		},
+	)
+
+	app.OnRecordAfterCreateSuccess("pipeline_results").BindFunc(
+		func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			emitWebhookEvent(e.App, e.Record.GetString("owner"), webhook.EventPipelineStarted,
+				map[string]any{
+					"result_id":   e.Record.Id,
+					"pipeline_id": e.Record.GetString("pipeline"),
+					"workflow_id": e.Record.GetString("workflow_id"),
+					"run_id":      e.Record.GetString("run_id"),
+					"type":        e.Record.GetString("type"),
+				},
+			)
+			return nil
+		},
+	)
+
+	app.OnRecordAfterUpdateSuccess("mobile_runners").BindFunc(
+		func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			if !e.Record.Original().GetBool("online") || e.Record.GetBool("online") {
+				return nil
+			}
+			emitWebhookEvent(e.App, e.Record.GetString("owner"), webhook.EventRunnerOffline,
+				map[string]any{
+					"runner_id":         e.Record.Id,
+					"name":              e.Record.GetString("name"),
+					"last_heartbeat_at": e.Record.GetString("last_heartbeat_at"),
+				},
+			)
+			return nil
+		},
+	)
+}
+
+// startWebhookDelivery starts the workflow sending delivery. The workflow id
+// is recorded before the workflow starts, so that this never writes over
+// what the delivery activity stores. A delivery whose workflow cannot be
+// started fails at once, so that it can be redelivered.
+func startWebhookDelivery(app core.App, delivery *core.Record) {
+	delivery.Set("workflow_id", workflows.WebhookDeliveryWorkflowID(delivery.Id))
+	if err := app.Save(delivery); err != nil {
+		app.Logger().Error(
+			"save webhook delivery failed",
+			"delivery_id", delivery.Id,
+			"error", err,
+		)
+		return
+	}
+
+	_, err := startWebhookDeliveryWorkflow(workflowengine.WorkflowInput{
+		Payload: workflows.WebhookDeliveryWorkflowInput{DeliveryID: delivery.Id},
+		Config: map[string]any{
+			"app_url": app.Settings().Meta.AppURL,
+		},
+	})
+	if err == nil {
+		return
+	}
+	app.Logger().Error(
+		"start webhook delivery workflow failed",
+		"delivery_id", delivery.Id,
+		"error", err,
+	)
+	delivery.Set("status", webhook.StatusFailed)
+	delivery.Set("last_error", "failed to start delivery: "+err.Error())
+	if err := app.Save(delivery); err != nil {
+		app.Logger().Error(
+			"save webhook delivery failed",
+			"delivery_id", delivery.Id,
+			"error", err,
+		)
+	}
+}
+
+func emitWebhookEvent(app core.App, orgID string, event webhook.Event, data any) {
+	if _, err := webhook.Emit(app, orgID, event, data); err != nil {
+		app.Logger().Error(
+			"emit webhook event failed",
+			"event", event,
+			"organization", orgID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/webhook"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/workflows"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func stubWebhookDeliveryWorkflow(t *testing.T, err error) *[]string {
+	t.Helper()
+
+	original := startWebhookDeliveryWorkflow
+	t.Cleanup(func() {
+		startWebhookDeliveryWorkflow = original
+	})
+	started := []string{}
+	startWebhookDeliveryWorkflow = func(
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		payload, ok := input.Payload.(workflows.WebhookDeliveryWorkflowInput)
+		require.True(t, ok)
+		started = append(started, payload.DeliveryID)
+		if err != nil {
+			return workflowengine.WorkflowResult{}, err
+		}
+		return workflowengine.WorkflowResult{
+			WorkflowID: workflows.WebhookDeliveryWorkflowID(payload.DeliveryID),
+		}, nil
+	}
+	return &started
+}
+
+func createTestWebhook(t *testing.T, app core.App, orgID string, events ...webhook.Event) {
+	t.Helper()
+
+	record := core.NewRecord(mustFindCollection(t, app, webhook.Collection))
+	record.Set("owner", orgID)
+	record.Set("name", "ci")
+	record.Set("url", "https://example.org/hooks")
+	record.Set("secret", webhook.NewSecret())
+	record.Set("events", events)
+	record.Set("enabled", true)
+	require.NoError(t, app.Save(record))
+}
+
+func findTestDeliveries(t *testing.T, app core.App, orgID string) []*core.Record {
+	t.Helper()
+
+	deliveries, err := app.FindRecordsByFilter(
+		webhook.DeliveryCollection,
+		"owner = {:owner}",
+		"created",
+		0,
+		0,
+		dbx.Params{"owner": orgID},
+	)
+	require.NoError(t, err)
+	return deliveries
+}
+
+func TestWebhookHooksStartDeliveries(t *testing.T) {
+	started := stubWebhookDeliveryWorkflow(t, nil)
+
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	RegisterWebhookHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	createTestWebhook(t, app, orgID, webhook.EventSchedulePaused)
+
+	deliveries, err := webhook.Emit(app, orgID, webhook.EventSchedulePaused, map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, []string{deliveries[0].Id}, *started)
+
+	stored, err := app.FindRecordById(webhook.DeliveryCollection, deliveries[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, "webhook-delivery-"+stored.Id, stored.GetString("workflow_id"))
+	require.Equal(t, webhook.StatusPending, stored.GetString("status"))
+}
+
+func TestWebhookHooksKeepWhatTheDeliveryStored(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	RegisterWebhookHooks(app)
+
+	// The delivery activity can run and store its outcome before the
+	// workflow start returns.
+	original := startWebhookDeliveryWorkflow
+	t.Cleanup(func() {
+		startWebhookDeliveryWorkflow = original
+	})
+	startWebhookDeliveryWorkflow = func(
+		input workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		payload := input.Payload.(workflows.WebhookDeliveryWorkflowInput)
+		delivery, err := app.FindRecordById(webhook.DeliveryCollection, payload.DeliveryID)
+		require.NoError(t, err)
+		require.Equal(t,
+			workflows.WebhookDeliveryWorkflowID(delivery.Id),
+			delivery.GetString("workflow_id"),
+		)
+		delivery.Set("attempts", 1)
+		delivery.Set("response_status", 200)
+		delivery.Set("status", webhook.StatusSucceeded)
+		require.NoError(t, app.Save(delivery))
+		return workflowengine.WorkflowResult{
+			WorkflowID: workflows.WebhookDeliveryWorkflowID(delivery.Id),
+		}, nil
+	}
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	createTestWebhook(t, app, orgID, webhook.EventSchedulePaused)
+
+	deliveries, err := webhook.Emit(app, orgID, webhook.EventSchedulePaused, map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	stored, err := app.FindRecordById(webhook.DeliveryCollection, deliveries[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, webhook.StatusSucceeded, stored.GetString("status"))
+	require.Equal(t, 1, stored.GetInt("attempts"))
+	require.Equal(t, 200, stored.GetInt("response_status"))
+}
+
+func TestWebhookHooksFailDeliveriesThatCannotStart(t *testing.T) {
+	stubWebhookDeliveryWorkflow(t, errors.New("temporal unavailable"))
+
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	RegisterWebhookHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	createTestWebhook(t, app, orgID, webhook.EventSchedulePaused)
+
+	deliveries, err := webhook.Emit(app, orgID, webhook.EventSchedulePaused, map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	stored, err := app.FindRecordById(webhook.DeliveryCollection, deliveries[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, webhook.StatusFailed, stored.GetString("status"))
+	require.Equal(t,
+		"failed to start delivery: temporal unavailable",
+		stored.GetString("last_error"),
+	)
+}
+
+func TestWebhookHooksEmitPipelineStarted(t *testing.T) {
+	stubWebhookDeliveryWorkflow(t, nil)
+
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	RegisterWebhookHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	createTestWebhook(t, app, orgID, webhook.EventPipelineStarted)
+
+	pipelineRecord := core.NewRecord(mustFindCollection(t, app, "pipelines"))
+	pipelineRecord.Set("owner", orgID)
+	pipelineRecord.Set("name", "webhook-pipeline")
+	pipelineRecord.Set("canonified_name", "webhook-pipeline")
+	pipelineRecord.Set("description", "test-description")
+	pipelineRecord.Set("yaml", "name: webhook-pipeline")
+	require.NoError(t, app.Save(pipelineRecord))
+
+	result := core.NewRecord(mustFindCollection(t, app, "pipeline_results"))
+	result.Set("owner", orgID)
+	result.Set("pipeline", pipelineRecord.Id)
+	result.Set("workflow_id", "workflow-1")
+	result.Set("run_id", "run-1")
+	require.NoError(t, app.Save(result))
+
+	deliveries := findTestDeliveries(t, app, orgID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, string(webhook.EventPipelineStarted), deliveries[0].GetString("event"))
+
+	var payload webhook.Payload
+	require.NoError(t, deliveries[0].UnmarshalJSONField("payload", &payload))
+	data, ok := payload.Data.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, result.Id, data["result_id"])
+	require.Equal(t, "workflow-1", data["workflow_id"])
+}
+
+func TestWebhookHooksEmitRunnerOffline(t *testing.T) {
+	stubWebhookDeliveryWorkflow(t, nil)
+
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	ensureLifecycleMonitorFields(t, app)
+	RegisterWebhookHooks(app)
+
+	orgID, err := getOrgIDfromName(app)
+	require.NoError(t, err)
+	createTestWebhook(t, app, orgID, webhook.EventRunnerOffline)
+	created := createLifecycleMonitorRunner(t, app, orgID, "runner-webhook", true, time.Now())
+	runner, err := app.FindRecordById("mobile_runners", created.Id)
+	require.NoError(t, err)
+
+	runner.Set("name", "runner-renamed")
+	require.NoError(t, app.Save(runner))
+	require.Empty(t, findTestDeliveries(t, app, orgID))
+
+	runner.Set("online", false)
+	require.NoError(t, app.Save(runner))
+
+	deliveries := findTestDeliveries(t, app, orgID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, string(webhook.EventRunnerOffline), deliveries[0].GetString("event"))
+}
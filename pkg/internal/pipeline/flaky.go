@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+)
+
+const (
+	// FlakyStepsPolicyRetry re-runs a known-flaky step before giving up on it.
+	FlakyStepsPolicyRetry = "retry"
+	// FlakyStepsPolicyQuarantine keeps a known-flaky step failure from failing the run.
+	FlakyStepsPolicyQuarantine = "quarantine"
+	// DefaultFlakyStepMaxRetries is used by the retry policy when max_retries is unset.
+	DefaultFlakyStepMaxRetries = 2
+
+	StepOutcomeSuccess = "success"
+	StepOutcomeFailed  = "failed"
+
+	// PipelineIdentifierConfigKey and PipelineYAMLHashConfigKey are set on the
+	// workflow config of top-level runs so that step outcomes can be recorded
+	// against the pipeline revision that produced them.
+	PipelineIdentifierConfigKey = "pipeline_identifier"
+	PipelineYAMLHashConfigKey   = "pipeline_yaml_hash"
+
+	// FlakyStepMinRuns is the number of runs on the same pipeline YAML and wallet
+	// version a step needs before it can be flagged as flaky.
+	FlakyStepMinRuns = 4
+	// FlakyStepScoreThreshold is the minimum flakiness score of a flaky step.
+	FlakyStepScoreThreshold = 0.3
+)
+
+// FlakyStepsConfig is the runtime.flaky_steps section of a pipeline.
+type FlakyStepsConfig struct {
+	Policy     string `yaml:"policy,omitempty"      json:"policy,omitempty"      jsonschema:"enum=retry,enum=quarantine"`
+	MaxRetries int    `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+}
+
+// Enabled reports whether a known policy is configured.
+func (c FlakyStepsConfig) Enabled() bool {
+	return c.Policy == FlakyStepsPolicyRetry || c.Policy == FlakyStepsPolicyQuarantine
+}
+
+// Retries returns how many extra attempts the retry policy grants.
+func (c FlakyStepsConfig) Retries() int {
+	if c.MaxRetries > 0 {
+		return c.MaxRetries
+	}
+	return DefaultFlakyStepMaxRetries
+}
+
+// StepOutcome is one recorded execution of a pipeline step. Steps re-run by
+// the retry policy keep the outcome of their first attempt, so that retrying
+// does not hide the flakiness it reacts to.
+type StepOutcome struct {
+	StepID        string `json:"step_id"`
+	Use           string `json:"use"`
+	Outcome       string `json:"outcome"`
+	Attempts      int    `json:"attempts,omitempty"`
+	Quarantined   bool   `json:"quarantined,omitempty"`
+	YAMLHash      string `json:"yaml_hash,omitempty"`
+	WalletVersion string `json:"wallet_version,omitempty"`
+}
+
+// FlakyStep summarizes the outcome history of a step flagged as flaky.
+type FlakyStep struct {
+	StepID        string  `json:"step_id"`
+	Use           string  `json:"use"`
+	WalletVersion string  `json:"wallet_version,omitempty"`
+	Runs          int     `json:"runs"`
+	Failures      int     `json:"failures"`
+	Flips         int     `json:"flips"`
+	Score         float64 `json:"score"`
+}
+
+// HashYAML identifies a pipeline revision, so that outcomes recorded before and
+// after an edit are not compared with each other.
+func HashYAML(yamlStr string) string {
+	sum := sha256.Sum256([]byte(yamlStr))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetStepOutcomeConfig stores the pipeline identifier and YAML hash used to
+// record step outcomes. Runs without an identifier record nothing.
+func SetStepOutcomeConfig(config map[string]any, pipelineIdentifier string, yamlStr string) {
+	if config == nil || pipelineIdentifier == "" {
+		return
+	}
+	config[PipelineIdentifierConfigKey] = pipelineIdentifier
+	config[PipelineYAMLHashConfigKey] = HashYAML(yamlStr)
+}
+
+// FlakinessScore counts pass/fail flips between consecutive outcomes and
+// returns them together with flips/(runs-1), rounded to two decimals. A step
+// alternating on every run scores 1, a step that never changes scores 0.
+func FlakinessScore(outcomes []string) (int, float64) {
+	if len(outcomes) < 2 {
+		return 0, 0
+	}
+	flips := 0
+	for i := 1; i < len(outcomes); i++ {
+		if outcomes[i] != outcomes[i-1] {
+			flips++
+		}
+	}
+	score := float64(flips) / float64(len(outcomes)-1)
+	return flips, math.Round(score*100) / 100
+}
+
+// DetectFlakySteps groups chronologically ordered outcomes by step, pipeline
+// YAML hash and wallet version, and flags the groups with enough runs whose
+// score reaches FlakyStepScoreThreshold. Each step is reported once, with its
+// worst group.
+func DetectFlakySteps(outcomes []StepOutcome) []FlakyStep {
+	type groupKey struct {
+		stepID        string
+		yamlHash      string
+		walletVersion string
+	}
+	groups := map[groupKey][]StepOutcome{}
+	order := []groupKey{}
+	for _, outcome := range outcomes {
+		key := groupKey{outcome.StepID, outcome.YAMLHash, outcome.WalletVersion}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], outcome)
+	}
+
+	byStep := map[string]FlakyStep{}
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < FlakyStepMinRuns {
+			continue
+		}
+		results := make([]string, 0, len(group))
+		failures := 0
+		for _, outcome := range group {
+			results = append(results, outcome.Outcome)
+			if outcome.Outcome != StepOutcomeSuccess {
+				failures++
+			}
+		}
+		flips, score := FlakinessScore(results)
+		if score < FlakyStepScoreThreshold {
+			continue
+		}
+		candidate := FlakyStep{
+			StepID:        key.stepID,
+			Use:           group[len(group)-1].Use,
+			WalletVersion: key.walletVersion,
+			Runs:          len(group),
+			Failures:      failures,
+			Flips:         flips,
+			Score:         score,
+		}
+		if current, ok := byStep[key.stepID]; !ok || candidate.Score > current.Score {
+			byStep[key.stepID] = candidate
+		}
+	}
+
+	flaky := make([]FlakyStep, 0, len(byStep))
+	for _, step := range byStep {
+		flaky = append(flaky, step)
+	}
+	sort.Slice(flaky, func(i, j int) bool {
+		if flaky[i].Score != flaky[j].Score {
+			return flaky[i].Score > flaky[j].Score
+		}
+		return flaky[i].StepID < flaky[j].StepID
+	})
+	return flaky
+}
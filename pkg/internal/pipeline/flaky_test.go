@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stepOutcomes(stepID, yamlHash, walletVersion string, results ...string) []StepOutcome {
+	outcomes := make([]StepOutcome, 0, len(results))
+	for _, result := range results {
+		outcomes = append(outcomes, StepOutcome{
+			StepID:        stepID,
+			Use:           "mobile-automation",
+			Outcome:       result,
+			YAMLHash:      yamlHash,
+			WalletVersion: walletVersion,
+		})
+	}
+	return outcomes
+}
+
+func TestFlakinessScore(t *testing.T) {
+	flips, score := FlakinessScore(nil)
+	require.Zero(t, flips)
+	require.Zero(t, score)
+
+	flips, score = FlakinessScore([]string{"success", "success", "success"})
+	require.Zero(t, flips)
+	require.Zero(t, score)
+
+	flips, score = FlakinessScore([]string{"success", "failed", "success", "failed"})
+	require.Equal(t, 3, flips)
+	require.Equal(t, 1.0, score)
+
+	flips, score = FlakinessScore([]string{"success", "success", "failed", "failed"})
+	require.Equal(t, 1, flips)
+	require.Equal(t, 0.33, score)
+}
+
+func TestDetectFlakySteps(t *testing.T) {
+	outcomes := []StepOutcome{}
+	// Alternating on the same YAML and wallet version: flaky.
+	outcomes = append(outcomes, stepOutcomes("login", "h1", "v1",
+		"success", "failed", "success", "failed", "success")...)
+	// Consistently broken: failing, but not flaky.
+	outcomes = append(outcomes, stepOutcomes("offer", "h1", "v1",
+		"failed", "failed", "failed", "failed")...)
+	// Flips only across wallet versions, each version is stable.
+	outcomes = append(outcomes, stepOutcomes("present", "h1", "v1",
+		"success", "success", "success", "success")...)
+	outcomes = append(outcomes, stepOutcomes("present", "h1", "v2",
+		"failed", "failed", "failed", "failed")...)
+	// Flaky, but not enough runs yet.
+	outcomes = append(outcomes, stepOutcomes("logout", "h1", "v1",
+		"success", "failed", "success")...)
+	// Mildly flaky on one revision, very flaky on another.
+	outcomes = append(outcomes, stepOutcomes("verify", "h1", "v1",
+		"success", "success", "success", "failed")...)
+	outcomes = append(outcomes, stepOutcomes("verify", "h2", "v1",
+		"failed", "success", "success", "failed")...)
+
+	flaky := DetectFlakySteps(outcomes)
+	require.Len(t, flaky, 2)
+
+	require.Equal(t, FlakyStep{
+		StepID:        "login",
+		Use:           "mobile-automation",
+		WalletVersion: "v1",
+		Runs:          5,
+		Failures:      2,
+		Flips:         4,
+		Score:         1,
+	}, flaky[0])
+
+	require.Equal(t, "verify", flaky[1].StepID)
+	require.Equal(t, 2, flaky[1].Flips)
+	require.Equal(t, 0.67, flaky[1].Score)
+}
+
+func TestFlakyStepsConfig(t *testing.T) {
+	require.False(t, FlakyStepsConfig{}.Enabled())
+	require.False(t, FlakyStepsConfig{Policy: "ignore"}.Enabled())
+	require.True(t, FlakyStepsConfig{Policy: FlakyStepsPolicyRetry}.Enabled())
+	require.True(t, FlakyStepsConfig{Policy: FlakyStepsPolicyQuarantine}.Enabled())
+
+	require.Equal(t, DefaultFlakyStepMaxRetries, FlakyStepsConfig{}.Retries())
+	require.Equal(t, 5, FlakyStepsConfig{MaxRetries: 5}.Retries())
+}
+
+func TestSetStepOutcomeConfig(t *testing.T) {
+	config := map[string]any{}
+	SetStepOutcomeConfig(config, "", "name: test")
+	require.Empty(t, config)
+
+	SetStepOutcomeConfig(config, "org/pipeline", "name: test")
+	require.Equal(t, "org/pipeline", config[PipelineIdentifierConfigKey])
+	require.Equal(t, HashYAML("name: test"), config[PipelineYAMLHashConfigKey])
+	require.NotEqual(t, HashYAML("name: test"), HashYAML("name: other"))
+	require.Len(t, HashYAML("name: test"), 64)
+}
+
+func TestParseWorkflowFlakySteps(t *testing.T) {
+	wf, err := ParseWorkflow(`
+name: flaky
+runtime:
+  flaky_steps:
+    policy: retry
+    max_retries: 3
+steps:
+  - id: step1
+    use: http-request
+    with:
+      url: https://example.com
+`)
+	require.NoError(t, err)
+	require.Equal(t, FlakyStepsPolicyRetry, wf.Runtime.FlakySteps.Policy)
+	require.Equal(t, 3, wf.Runtime.FlakySteps.Retries())
+}
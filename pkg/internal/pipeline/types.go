@@ -77,9 +77,10 @@ type RuntimeConfig struct {
 	Schedule struct {
 		Interval *time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
 	} `yaml:"schedule,omitempty"                   json:"schedule,omitempty"`
-	GlobalRunnerID          string `yaml:"global_runner_id,omitempty"           json:"global_runner_id,omitempty"`
-	DisableAndroidPlayStore bool   `yaml:"disable_android_play_store,omitempty" json:"disable_android_play_store,omitempty"`
-	Debug                   bool   `yaml:"debug,omitempty"                      json:"debug,omitempty"`
+	GlobalRunnerID          string           `yaml:"global_runner_id,omitempty"           json:"global_runner_id,omitempty"`
+	DisableAndroidPlayStore bool             `yaml:"disable_android_play_store,omitempty" json:"disable_android_play_store,omitempty"`
+	Debug                   bool             `yaml:"debug,omitempty"                      json:"debug,omitempty"`
+	FlakySteps              FlakyStepsConfig `yaml:"flaky_steps,omitempty"                json:"flaky_steps,omitempty"`
 	Temporal                struct {
 		ExecutionTimeout string                `yaml:"execution_timeout,omitempty" json:"execution_timeout,omitempty"`
 		ActivityOptions  ActivityOptionsConfig `yaml:"activity_options,omitempty" json:"activity_options,omitempty"`
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+)
+
+const grantPreAuthorizedCode = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+
+// IssuerData resolves a credential offer, by value or by reference, into
+// the values of the issuer fuzz template: credential_issuer,
+// credential_configuration_id, pre_authorized_code and tx_code. Only offers
+// with a pre-authorized code can be fuzzed without a user.
+func IssuerData(
+	ctx context.Context,
+	client *http.Client,
+	deeplink, txCode string,
+) (map[string]any, error) {
+	target, err := url.Parse(strings.TrimSpace(deeplink))
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential offer: %w", err)
+	}
+	query := target.Query()
+	raw := query.Get("credential_offer")
+	if offerURI := query.Get("credential_offer_uri"); raw == "" && offerURI != "" {
+		if raw, err = fetchOffer(ctx, client, offerURI); err != nil {
+			return nil, err
+		}
+	}
+	if raw == "" {
+		return nil, errors.New("credential offer has no credential_offer or credential_offer_uri")
+	}
+
+	var offer struct {
+		CredentialIssuer           string   `json:"credential_issuer"`
+		CredentialConfigurationIDs []string `json:"credential_configuration_ids"`
+		Grants                     map[string]struct {
+			PreAuthorizedCode string `json:"pre-authorized_code"`
+			TxCode            any    `json:"tx_code"`
+		} `json:"grants"`
+	}
+	if err := json.Unmarshal([]byte(raw), &offer); err != nil {
+		return nil, fmt.Errorf("invalid credential offer: %w", err)
+	}
+	grant, ok := offer.Grants[grantPreAuthorizedCode]
+	switch {
+	case offer.CredentialIssuer == "":
+		return nil, errors.New("credential offer has no credential_issuer")
+	case len(offer.CredentialConfigurationIDs) == 0:
+		return nil, errors.New("credential offer has no credential_configuration_ids")
+	case !ok || grant.PreAuthorizedCode == "":
+		return nil, errors.New("credential offer has no pre-authorized code grant")
+	case grant.TxCode != nil && txCode == "":
+		return nil, errors.New("credential offer requires a tx_code")
+	}
+	return map[string]any{
+		"credential_issuer":           strings.TrimSuffix(offer.CredentialIssuer, "/"),
+		"credential_configuration_id": offer.CredentialConfigurationIDs[0],
+		"pre_authorized_code":         grant.PreAuthorizedCode,
+		"tx_code":                     txCode,
+	}, nil
+}
+
+func fetchOffer(ctx context.Context, client *http.Client, offerURI string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, offerURI, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid credential_offer_uri: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch credential offer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch credential offer: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("fetch credential offer: %w", err)
+	}
+	return string(body), nil
+}
+
+// VerifierData parses an authorization request URL into the values of the
+// verifier fuzz template: client_id, request_uri and request_uri_method.
+// The request must be passed by reference.
+func VerifierData(deeplink string) (map[string]any, error) {
+	request, err := vprequest.ParseURL(deeplink)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestURI == "" {
+		return nil, errors.New("authorization request has no request_uri")
+	}
+	method := strings.ToUpper(request.RequestURIMethod)
+	if method == "" {
+		method = http.MethodGet
+	}
+	return map[string]any{
+		"client_id":          request.URLClientID,
+		"request_uri":        request.RequestURI,
+		"request_uri_method": method,
+	}, nil
+}
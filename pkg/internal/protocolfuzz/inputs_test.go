@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testOffer = `{
+	"credential_issuer": "https://issuer.example/",
+	"credential_configuration_ids": ["pid_sd_jwt"],
+	"grants": {
+		"urn:ietf:params:oauth:grant-type:pre-authorized_code": {
+			"pre-authorized_code": "code"
+		}
+	}
+}`
+
+func TestIssuerData(t *testing.T) {
+	want := map[string]any{
+		"credential_issuer":           "https://issuer.example",
+		"credential_configuration_id": "pid_sd_jwt",
+		"pre_authorized_code":         "code",
+		"tx_code":                     "",
+	}
+
+	t.Run("offer by value", func(t *testing.T) {
+		data, err := IssuerData(
+			context.Background(),
+			http.DefaultClient,
+			"openid-credential-offer://?credential_offer="+url.QueryEscape(testOffer),
+			"",
+		)
+		require.NoError(t, err)
+		require.Equal(t, want, data)
+	})
+
+	t.Run("offer by reference", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(testOffer))
+		}))
+		defer server.Close()
+		data, err := IssuerData(
+			context.Background(),
+			server.Client(),
+			"openid-credential-offer://?credential_offer_uri="+url.QueryEscape(server.URL),
+			"",
+		)
+		require.NoError(t, err)
+		require.Equal(t, want, data)
+	})
+
+	for name, offer := range map[string]string{
+		"authorization code offers": `{"credential_issuer":"https://issuer.example",` +
+			`"credential_configuration_ids":["pid"],` +
+			`"grants":{"authorization_code":{"issuer_state":"s"}}}`,
+		"offers without configurations": `{"credential_issuer":"https://issuer.example",` +
+			`"grants":{"urn:ietf:params:oauth:grant-type:pre-authorized_code":` +
+			`{"pre-authorized_code":"code"}}}`,
+		"offers needing a tx_code": `{"credential_issuer":"https://issuer.example",` +
+			`"credential_configuration_ids":["pid"],` +
+			`"grants":{"urn:ietf:params:oauth:grant-type:pre-authorized_code":` +
+			`{"pre-authorized_code":"code","tx_code":{"length":4}}}}`,
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := IssuerData(
+				context.Background(),
+				http.DefaultClient,
+				"openid-credential-offer://?credential_offer="+url.QueryEscape(offer),
+				"",
+			)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVerifierData(t *testing.T) {
+	data, err := VerifierData(
+		"openid4vp://?client_id=x509_san_dns%3Averifier.example" +
+			"&request_uri=https%3A%2F%2Fverifier.example%2Frequest.jwt&request_uri_method=post",
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"client_id":          "x509_san_dns:verifier.example",
+		"request_uri":        "https://verifier.example/request.jwt",
+		"request_uri_method": "POST",
+	}, data)
+
+	_, err = VerifierData("openid4vp://?client_id=verifier&response_type=vp_token")
+	require.ErrorContains(t, err, "no request_uri")
+}
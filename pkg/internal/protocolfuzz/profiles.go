@@ -0,0 +1,389 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TargetIssuer fuzzes the token and credential endpoints of an
+	// OpenID4VCI issuer.
+	TargetIssuer = "issuer"
+	// TargetVerifier fuzzes the response endpoint of an OpenID4VP verifier.
+	TargetVerifier = "verifier"
+
+	// OversizedPayloadBytes is the padding the oversized-payload profile
+	// adds to a request.
+	OversizedPayloadBytes = 4 << 20
+
+	stepToken      = "token"
+	stepNonce      = "nonce"
+	stepCredential = "credential"
+	stepResponse   = "response"
+)
+
+// Expectation is how a server must reject a mutated request: with one of
+// Status and an OAuth error response whose error is one of Errors. With
+// ErrorOptional the body may carry no error, but one that does must still
+// match.
+type Expectation struct {
+	Status        []int    `json:"status"`
+	Errors        []string `json:"errors,omitempty"`
+	ErrorOptional bool     `json:"error_optional,omitempty"`
+}
+
+// Mutation changes the baseline request of Step. Refresh lists earlier
+// steps that are sent again before it, so that single use values such as
+// nonces are fresh; Replay sends the mutated request twice and checks the
+// second response.
+type Mutation struct {
+	Name    string
+	Step    string
+	Refresh []string
+	Replay  bool
+	Apply   func(req *Request, s *Session) error
+	Expect  Expectation
+}
+
+// Profile is a named group of mutations.
+type Profile struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Mutations   []Mutation `json:"-"`
+}
+
+var (
+	rejectProof = Expectation{
+		Status: []int{http.StatusBadRequest},
+		Errors: []string{"invalid_proof", "invalid_credential_request", "invalid_request"},
+	}
+	rejectNonce = Expectation{
+		Status: []int{http.StatusBadRequest},
+		Errors: []string{"invalid_nonce", "invalid_proof"},
+	}
+	rejectOversized = Expectation{
+		Status:        []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge},
+		ErrorOptional: true,
+	}
+	rejectResponse = Expectation{
+		Status:        []int{http.StatusBadRequest},
+		Errors:        []string{"invalid_request", "access_denied"},
+		ErrorOptional: true,
+	}
+	refreshNonce = []string{stepNonce}
+)
+
+var profiles = map[string][]Profile{
+	TargetIssuer: {
+		{
+			Name:        "malformed-proof",
+			Description: "Credential requests with a missing, malformed or badly signed key proof",
+			Mutations: []Mutation{
+				{
+					Name:    "not-a-jwt",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply:   setProof(func(*Session) (string, error) { return "not-a-jwt", nil }),
+					Expect:  rejectProof,
+				},
+				{
+					Name:    "tampered-signature",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: setProof(func(s *Session) (string, error) {
+						proof, err := s.Proof(s.ProofClaims())
+						if err != nil {
+							return "", err
+						}
+						return tamper(proof), nil
+					}),
+					Expect: rejectProof,
+				},
+				{
+					Name:    "wrong-typ",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: setProof(func(s *Session) (string, error) {
+						return s.sign("JWT", s.ProofClaims())
+					}),
+					Expect: rejectProof,
+				},
+				{
+					Name:    "missing-proof",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: func(req *Request, _ *Session) error {
+						delete(req.JSON, "proof")
+						delete(req.JSON, "proofs")
+						return nil
+					},
+					Expect: rejectProof,
+				},
+			},
+		},
+		{
+			Name:        "expired-nonce",
+			Description: "Credential requests whose key proof has a c_nonce never issued",
+			Mutations: []Mutation{
+				{
+					Name:    "stale-nonce",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: setProof(func(s *Session) (string, error) {
+						claims := s.ProofClaims()
+						claims["nonce"] = "credimi-fuzz-stale-nonce"
+						return s.Proof(claims)
+					}),
+					Expect: rejectNonce,
+				},
+			},
+		},
+		{
+			Name:        "wrong-aud",
+			Description: "Credential requests whose key proof is addressed to another issuer",
+			Mutations: []Mutation{
+				{
+					Name:    "foreign-audience",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: setProof(func(s *Session) (string, error) {
+						claims := s.ProofClaims()
+						claims["aud"] = "https://issuer.credimi-fuzz.invalid"
+						return s.Proof(claims)
+					}),
+					Expect: rejectProof,
+				},
+				{
+					Name:    "missing-audience",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: setProof(func(s *Session) (string, error) {
+						claims := s.ProofClaims()
+						delete(claims, "aud")
+						return s.Proof(claims)
+					}),
+					Expect: rejectProof,
+				},
+			},
+		},
+		{
+			Name:        "replayed-dpop",
+			Description: "A credential request sent twice with the same DPoP and key proofs",
+			Mutations: []Mutation{
+				{
+					Name:    "replayed-request",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Replay:  true,
+					Apply: func(req *Request, s *Session) error {
+						proof, err := s.DPoP(req)
+						if err != nil {
+							return err
+						}
+						req.Header.Set("DPoP", proof)
+						return nil
+					},
+					Expect: Expectation{
+						Status: []int{http.StatusBadRequest, http.StatusUnauthorized},
+						Errors: []string{
+							"invalid_dpop_proof",
+							"use_dpop_nonce",
+							"invalid_token",
+							"invalid_nonce",
+							"invalid_proof",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "oversized-payload",
+			Description: "A credential request padded beyond any reasonable size",
+			Mutations: []Mutation{
+				{
+					Name:    "oversized-credential-request",
+					Step:    stepCredential,
+					Refresh: refreshNonce,
+					Apply: func(req *Request, _ *Session) error {
+						padding := strings.Repeat("A", OversizedPayloadBytes)
+						req.JSON["credimi_fuzz_padding"] = padding
+						return nil
+					},
+					Expect: rejectOversized,
+				},
+			},
+		},
+		{
+			Name:        "invalid-grant",
+			Description: "Token requests with an unknown code or a bad or missing grant type",
+			Mutations: []Mutation{
+				{
+					Name: "unknown-pre-authorized-code",
+					Step: stepToken,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Set("pre-authorized_code", "credimi-fuzz-unknown-code")
+						return nil
+					},
+					Expect: Expectation{
+						Status: []int{http.StatusBadRequest},
+						Errors: []string{"invalid_grant"},
+					},
+				},
+				{
+					Name: "unsupported-grant-type",
+					Step: stepToken,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Set("grant_type", "urn:credimi:fuzz:unsupported")
+						return nil
+					},
+					Expect: Expectation{
+						Status: []int{http.StatusBadRequest},
+						Errors: []string{"unsupported_grant_type"},
+					},
+				},
+				{
+					Name: "missing-grant-type",
+					Step: stepToken,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Del("grant_type")
+						return nil
+					},
+					Expect: Expectation{
+						Status: []int{http.StatusBadRequest},
+						Errors: []string{"invalid_request", "unsupported_grant_type"},
+					},
+				},
+			},
+		},
+	},
+	TargetVerifier: {
+		{
+			Name:        "malformed-vp-token",
+			Description: "Authorization responses whose vp_token is missing or not a JSON object",
+			Mutations: []Mutation{
+				{
+					Name: "non-json-vp-token",
+					Step: stepResponse,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Set("vp_token", "not-a-vp-token")
+						return nil
+					},
+					Expect: rejectResponse,
+				},
+				{
+					Name: "missing-vp-token",
+					Step: stepResponse,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Del("vp_token")
+						return nil
+					},
+					Expect: rejectResponse,
+				},
+			},
+		},
+		{
+			Name:        "wrong-state",
+			Description: "An authorization response for a state the verifier did not issue",
+			Mutations: []Mutation{
+				{
+					Name: "unknown-state",
+					Step: stepResponse,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Set("state", "credimi-fuzz-unknown-state")
+						return nil
+					},
+					Expect: rejectResponse,
+				},
+			},
+		},
+		{
+			Name:        "oversized-payload",
+			Description: "An authorization response padded beyond any reasonable size",
+			Mutations: []Mutation{
+				{
+					Name: "oversized-response",
+					Step: stepResponse,
+					Apply: func(req *Request, _ *Session) error {
+						req.Form.Set("vp_token", strings.Repeat("A", OversizedPayloadBytes))
+						return nil
+					},
+					Expect: rejectOversized,
+				},
+			},
+		},
+	},
+}
+
+// ProfileNames lists the profiles of target.
+func ProfileNames(target string) []string {
+	names := make([]string, 0, len(profiles[target]))
+	for _, profile := range profiles[target] {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// Profiles returns the named profiles of target, or all of them when names
+// is empty.
+func Profiles(target string, names []string) ([]Profile, error) {
+	available, ok := profiles[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+	if len(names) == 0 {
+		return available, nil
+	}
+	selected := make([]Profile, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, profile := range available {
+			if profile.Name == name {
+				selected = append(selected, profile)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(
+				"unknown %s profile %q, expected one of %s",
+				target,
+				name,
+				strings.Join(ProfileNames(target), ", "),
+			)
+		}
+	}
+	return selected, nil
+}
+
+// setProof replaces the key proofs of a credential request with the one
+// proof returns.
+func setProof(proof func(s *Session) (string, error)) func(*Request, *Session) error {
+	return func(req *Request, s *Session) error {
+		value, err := proof(s)
+		if err != nil {
+			return err
+		}
+		delete(req.JSON, "proof")
+		req.JSON["proofs"] = map[string]any{"jwt": []any{value}}
+		return nil
+	}
+}
+
+// tamper flips the first signature character of a compact JWS. The last
+// one may only carry padding bits.
+func tamper(jws string) string {
+	dot := strings.LastIndex(jws, ".")
+	if dot < 0 || dot == len(jws)-1 {
+		return jws
+	}
+	replacement := "A"
+	if jws[dot+1] == 'A' {
+		replacement = "B"
+	}
+	return jws[:dot+1] + replacement + jws[dot+2:]
+}
@@ -0,0 +1,457 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	proofType = "openid4vci-proof+jwt"
+	dpopType  = "dpop+jwt"
+
+	maxResponseBytes = 1 << 20
+)
+
+// Result is the outcome of one mutation.
+type Result struct {
+	Profile          string      `json:"profile"`
+	Mutation         string      `json:"mutation"`
+	Step             string      `json:"step"`
+	Passed           bool        `json:"passed"`
+	Status           int         `json:"status,omitempty"`
+	Error            string      `json:"error,omitempty"`
+	ErrorDescription string      `json:"error_description,omitempty"`
+	Expected         Expectation `json:"expected"`
+	Reason           string      `json:"reason,omitempty"`
+}
+
+// Report collects the results of a run.
+type Report struct {
+	Passed  bool     `json:"passed"`
+	Total   int      `json:"total"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+func (r *Report) add(result Result) {
+	r.Results = append(r.Results, result)
+	r.Total++
+	if !result.Passed {
+		r.Failed++
+	}
+	r.Passed = r.Failed == 0
+}
+
+// SetupError reports a baseline step that failed, after which the
+// remaining mutations cannot run.
+type SetupError struct {
+	Step string
+	Err  error
+}
+
+func (e *SetupError) Error() string {
+	return fmt.Sprintf("step %s: %v", e.Step, e.Err)
+}
+
+func (e *SetupError) Unwrap() error {
+	return e.Err
+}
+
+// Runner runs the mutations of profiles against the baseline requests of a
+// template. The zero value uses http.DefaultClient and time.Now.
+type Runner struct {
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+// Run sends the steps of template in order. Before a step it sends the
+// mutations that target it, each checked against its expectation; the
+// baseline step itself is sent afterwards for its captures, except for the
+// last step, which is only sent mutated. A failing baseline step stops the
+// run with a SetupError and the results so far.
+func (r *Runner) Run(ctx context.Context, template *Template, selected []Profile) (*Report, error) {
+	type targeted struct {
+		profile  string
+		mutation Mutation
+	}
+	byStep := map[string][]targeted{}
+	for _, profile := range selected {
+		for _, mutation := range profile.Mutations {
+			index := template.step(mutation.Step)
+			if index < 0 {
+				return nil, fmt.Errorf(
+					"profile %s: template has no step %q",
+					profile.Name,
+					mutation.Step,
+				)
+			}
+			for _, id := range mutation.Refresh {
+				if refresh := template.step(id); refresh < 0 || refresh >= index {
+					return nil, fmt.Errorf(
+						"profile %s: template has no step %q before %q",
+						profile.Name,
+						id,
+						mutation.Step,
+					)
+				}
+			}
+			byStep[mutation.Step] = append(byStep[mutation.Step], targeted{profile.Name, mutation})
+		}
+	}
+
+	holder, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{runner: r, holder: holder, captures: map[string]any{}}
+	report := &Report{Passed: true, Results: []Result{}}
+	for i, step := range template.Steps {
+		for _, target := range byStep[step.ID] {
+			result, err := session.mutate(ctx, template, step, target.mutation)
+			if err != nil {
+				return report, err
+			}
+			result.Profile = target.profile
+			report.add(result)
+		}
+		if i == len(template.Steps)-1 {
+			break
+		}
+		if err := session.baseline(ctx, step); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (r *Runner) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (r *Runner) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// Request is a baseline request, as mutations see it. Exactly one of Form,
+// JSON and Body carries the body.
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+	Form   url.Values
+	JSON   map[string]any
+	Body   string
+}
+
+func (req *Request) encode(ctx context.Context) (*http.Request, error) {
+	var body io.Reader
+	contentType := ""
+	switch {
+	case req.JSON != nil:
+		encoded, err := json.Marshal(req.JSON)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	case req.Form != nil:
+		body = strings.NewReader(req.Form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	case req.Body != "":
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = req.Header.Clone()
+	if contentType != "" && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	return httpReq, nil
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// Session holds the captures and the holder key of a run.
+type Session struct {
+	runner   *Runner
+	holder   *ecdsa.PrivateKey
+	captures map[string]any
+}
+
+// Capture returns a captured value.
+func (s *Session) Capture(name string) (string, bool) {
+	value, ok := s.captures[name]
+	return stringValue(value), ok
+}
+
+// ProofClaims are the claims of a valid key proof for the captured
+// credential_issuer and c_nonce.
+func (s *Session) ProofClaims() map[string]any {
+	claims := map[string]any{"iat": s.runner.now().Unix()}
+	if issuer, ok := s.Capture("credential_issuer"); ok {
+		claims["aud"] = issuer
+	}
+	if nonce, ok := s.Capture("c_nonce"); ok {
+		claims["nonce"] = nonce
+	}
+	return claims
+}
+
+// Proof signs a key proof with the holder key.
+func (s *Session) Proof(claims map[string]any) (string, error) {
+	return s.sign(proofType, claims)
+}
+
+// DPoP signs a DPoP proof for req, bound to the captured access_token.
+func (s *Session) DPoP(req *Request) (string, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return "", err
+	}
+	target.RawQuery = ""
+	target.Fragment = ""
+	claims := map[string]any{
+		"jti": rand.Text(),
+		"htm": req.Method,
+		"htu": target.String(),
+		"iat": s.runner.now().Unix(),
+	}
+	if token, ok := s.Capture("access_token"); ok {
+		digest := sha256.Sum256([]byte(token))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(digest[:])
+	}
+	return s.sign(dpopType, claims)
+}
+
+func (s *Session) sign(typ string, claims map[string]any) (string, error) {
+	public, err := s.holder.PublicKey.Bytes()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims(claims))
+	token.Header["typ"] = typ
+	token.Header["jwk"] = map[string]any{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(public[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(public[33:]),
+	}
+	return token.SignedString(s.holder)
+}
+
+func (s *Session) lookup(scope, name string) (string, error) {
+	if scope == "fuzz" {
+		if name != "proof" {
+			return "", fmt.Errorf("unknown value fuzz.%s", name)
+		}
+		return s.Proof(s.ProofClaims())
+	}
+	value, ok := s.Capture(name)
+	if !ok {
+		return "", fmt.Errorf("captures.%s is not set", name)
+	}
+	return value, nil
+}
+
+// skipped reports whether the if condition of step, which may only test
+// that a capture is set, is false.
+func (s *Session) skipped(step Step) bool {
+	name, ok := strings.CutPrefix(strings.TrimSpace(step.If), "captures.")
+	if !ok {
+		return false
+	}
+	value, ok := s.Capture(name)
+	return !ok || value == ""
+}
+
+// build substitutes the expressions of step into a Request.
+func (s *Session) build(step Step) (*Request, error) {
+	req := &Request{Method: strings.ToUpper(step.HTTP.Method), Header: http.Header{}}
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	var err error
+	if req.URL, err = substitute(step.HTTP.URL, s.lookup); err != nil {
+		return nil, err
+	}
+	for name, value := range step.HTTP.Headers {
+		resolved, err := substitute(value, s.lookup)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, resolved)
+	}
+	switch {
+	case step.HTTP.JSON != nil:
+		resolved, err := substituteJSON(step.HTTP.JSON, s.lookup)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := resolved.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("step %s: json must be an object", step.ID)
+		}
+		req.JSON = object
+	case step.HTTP.Form != nil:
+		form := make(map[string]string, len(step.HTTP.Form))
+		for key, value := range step.HTTP.Form {
+			if form[key], err = substitute(value, s.lookup); err != nil {
+				return nil, err
+			}
+		}
+		req.Form = formValues(form)
+	default:
+		if req.Body, err = substitute(step.HTTP.Body, s.lookup); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func (s *Session) send(ctx context.Context, req *Request) (*response, error) {
+	httpReq, err := req.encode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.runner.client().Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: resp.StatusCode, body: body}, nil
+}
+
+// baseline sends step unmutated and stores its captures.
+func (s *Session) baseline(ctx context.Context, step Step) error {
+	if s.skipped(step) {
+		return nil
+	}
+	req, err := s.build(step)
+	if err != nil {
+		return &SetupError{Step: step.ID, Err: err}
+	}
+	resp, err := s.send(ctx, req)
+	if err != nil {
+		return &SetupError{Step: step.ID, Err: err}
+	}
+	if resp.status < 200 || resp.status > 299 {
+		return &SetupError{
+			Step: step.ID,
+			Err:  fmt.Errorf("%s %s returned status %d", req.Method, req.URL, resp.status),
+		}
+	}
+	decoded, _ := decodeResponse(resp.body)
+	for name, capture := range step.HTTP.Captures {
+		if value, ok := lookupPath(decoded, capture.JSONPath); ok {
+			s.captures[name] = value
+		} else if capture.Default != nil {
+			s.captures[name] = *capture.Default
+		}
+	}
+	return nil
+}
+
+// mutate sends the mutated request of step and checks the response.
+func (s *Session) mutate(
+	ctx context.Context,
+	template *Template,
+	step Step,
+	mutation Mutation,
+) (Result, error) {
+	result := Result{Mutation: mutation.Name, Step: step.ID, Expected: mutation.Expect}
+	for _, id := range mutation.Refresh {
+		if err := s.baseline(ctx, template.Steps[template.step(id)]); err != nil {
+			return result, err
+		}
+	}
+	req, err := s.build(step)
+	if err != nil {
+		return result, &SetupError{Step: step.ID, Err: err}
+	}
+	if err := mutation.Apply(req, s); err != nil {
+		return result, fmt.Errorf("mutation %s: %w", mutation.Name, err)
+	}
+
+	resp, err := s.send(ctx, req)
+	if err == nil && mutation.Replay {
+		resp, err = s.send(ctx, req)
+	}
+	if err != nil {
+		result.Reason = "request failed: " + err.Error()
+		return result, nil
+	}
+	result.Status = resp.status
+	var oauthError struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if json.Unmarshal(resp.body, &oauthError) == nil {
+		result.Error = oauthError.Error
+		result.ErrorDescription = oauthError.ErrorDescription
+	}
+	result.Reason = mutation.Expect.check(result.Status, result.Error)
+	result.Passed = result.Reason == ""
+	return result, nil
+}
+
+// check returns why a response does not meet e, or "" when it does.
+func (e Expectation) check(status int, errorCode string) string {
+	if status >= 200 && status <= 399 {
+		return fmt.Sprintf("the mutated request was accepted with status %d", status)
+	}
+	if !slices.Contains(e.Status, status) {
+		return fmt.Sprintf("status %d, expected one of %v", status, e.Status)
+	}
+	if len(e.Errors) == 0 || (errorCode == "" && e.ErrorOptional) {
+		return ""
+	}
+	if errorCode == "" {
+		return fmt.Sprintf("no error code, expected one of %s", strings.Join(e.Errors, ", "))
+	}
+	if !slices.Contains(e.Errors, errorCode) {
+		return fmt.Sprintf(
+			"error %q, expected one of %s",
+			errorCode,
+			strings.Join(e.Errors, ", "),
+		)
+	}
+	return ""
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
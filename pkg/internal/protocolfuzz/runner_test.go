@@ -0,0 +1,404 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const issuerTemplate = `
+tests:
+  issuer:
+    steps:
+      - id: issuer_metadata
+        http:
+          url: %[1]s/.well-known/openid-credential-issuer
+          captures:
+            credential_issuer: {jsonpath: $.credential_issuer}
+            credential_endpoint: {jsonpath: $.credential_endpoint}
+            nonce_endpoint: {jsonpath: $.nonce_endpoint}
+            authorization_server:
+              jsonpath: $.authorization_servers[0]
+              default: %[1]s
+      - id: authorization_server_metadata
+        http:
+          url: ${{ captures.authorization_server }}/.well-known/oauth-authorization-server
+          captures:
+            token_endpoint: {jsonpath: $.token_endpoint}
+      - id: token
+        http:
+          url: ${{ captures.token_endpoint }}
+          method: POST
+          form:
+            grant_type: urn:ietf:params:oauth:grant-type:pre-authorized_code
+            pre-authorized_code: code
+          captures:
+            access_token: {jsonpath: $.access_token}
+      - id: nonce
+        if: captures.nonce_endpoint
+        http:
+          url: ${{ captures.nonce_endpoint }}
+          method: POST
+          captures:
+            c_nonce: {jsonpath: $.c_nonce}
+      - id: credential
+        http:
+          url: ${{ captures.credential_endpoint }}
+          method: POST
+          headers:
+            Authorization: Bearer ${{ captures.access_token }}
+          json:
+            credential_configuration_id: pid
+            proofs:
+              jwt:
+                - ${{ fuzz.proof }}
+`
+
+// fakeIssuer is a pre-authorized code issuer. A lenient one issues a
+// credential for any credential request.
+type fakeIssuer struct {
+	*httptest.Server
+	lenient     bool
+	tokenStatus int
+
+	mu         sync.Mutex
+	codeUsed   bool
+	nonce      string
+	nonces     int
+	seenDPoP   map[string]bool
+	credential int
+}
+
+func newFakeIssuer(t *testing.T, lenient bool) *fakeIssuer {
+	t.Helper()
+	issuer := &fakeIssuer{lenient: lenient, seenDPoP: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"GET /.well-known/openid-credential-issuer",
+		func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"credential_issuer":   issuer.URL,
+				"credential_endpoint": issuer.URL + "/credential",
+				"nonce_endpoint":      issuer.URL + "/nonce",
+			})
+		},
+	)
+	mux.HandleFunc(
+		"GET /.well-known/oauth-authorization-server",
+		func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]any{"token_endpoint": issuer.URL + "/token"})
+		},
+	)
+	mux.HandleFunc("POST /token", issuer.token)
+	mux.HandleFunc("POST /nonce", func(w http.ResponseWriter, _ *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		issuer.nonces++
+		issuer.nonce = "nonce-" + strconv.Itoa(issuer.nonces)
+		writeJSON(w, http.StatusOK, map[string]any{"c_nonce": issuer.nonce})
+	})
+	mux.HandleFunc("POST /credential", issuer.issue)
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (f *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tokenStatus != 0 {
+		w.WriteHeader(f.tokenStatus)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	switch grant := r.PostForm.Get("grant_type"); {
+	case grant == "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+	case grant != "urn:ietf:params:oauth:grant-type:pre-authorized_code":
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+	case f.codeUsed || r.PostForm.Get("pre-authorized_code") != "code":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+	default:
+		f.codeUsed = true
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": "token"})
+	}
+}
+
+func (f *fakeIssuer) issue(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	if f.lenient {
+		f.credential++
+		writeJSON(w, http.StatusOK, map[string]any{"credentials": []any{}})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+	if dpop := r.Header.Get("DPoP"); dpop != "" {
+		if f.seenDPoP[dpop] {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_dpop_proof")
+			return
+		}
+		f.seenDPoP[dpop] = true
+	}
+	var request map[string]any
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_credential_request")
+		return
+	}
+	parsed := reference.ParseCredentialRequest("token", request)
+	if len(parsed.Proofs) != 1 {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_proof")
+		return
+	}
+	if _, err := reference.VerifyProof(parsed.Proofs[0], f.URL, f.nonce, time.Now()); err != nil {
+		if strings.Contains(err.Error(), "nonce") {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_nonce")
+			return
+		}
+		writeOAuthError(w, http.StatusBadRequest, "invalid_proof")
+		return
+	}
+	f.nonce = ""
+	f.credential++
+	writeJSON(w, http.StatusOK, map[string]any{"credentials": []any{}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]any{"error": code, "error_description": code})
+}
+
+func requireAllPassed(t *testing.T, report *Report) {
+	t.Helper()
+	for _, result := range report.Results {
+		require.Truef(
+			t,
+			result.Passed,
+			"%s/%s: %s",
+			result.Profile,
+			result.Mutation,
+			result.Reason,
+		)
+	}
+}
+
+func parseIssuerTemplate(t *testing.T, url string) *Template {
+	t.Helper()
+	template, err := ParseTemplate(fmt.Sprintf(issuerTemplate, url))
+	require.NoError(t, err)
+	return template
+}
+
+func TestRunnerIssuer(t *testing.T) {
+	selected, err := Profiles(TargetIssuer, nil)
+	require.NoError(t, err)
+
+	t.Run("a strict issuer rejects every mutation", func(t *testing.T) {
+		issuer := newFakeIssuer(t, false)
+		runner := &Runner{HTTPClient: issuer.Client()}
+		report, err := runner.Run(
+			context.Background(),
+			parseIssuerTemplate(t, issuer.URL),
+			selected,
+		)
+		require.NoError(t, err)
+		requireAllPassed(t, report)
+		require.True(t, report.Passed)
+		require.Equal(t, 12, report.Total)
+		require.Zero(t, report.Failed)
+
+		// The token mutations run before the code is redeemed.
+		require.Equal(t, "token", report.Results[0].Step)
+		require.Equal(t, "invalid-grant", report.Results[0].Profile)
+		require.Equal(t, "invalid_grant", report.Results[0].Error)
+		// Only the first send of the replayed request is accepted.
+		require.Equal(t, 1, issuer.credential)
+	})
+
+	t.Run("a lenient issuer fails the credential mutations", func(t *testing.T) {
+		issuer := newFakeIssuer(t, true)
+		runner := &Runner{HTTPClient: issuer.Client()}
+		profiles, err := Profiles(TargetIssuer, []string{"wrong-aud", "oversized-payload"})
+		require.NoError(t, err)
+		report, err := runner.Run(
+			context.Background(),
+			parseIssuerTemplate(t, issuer.URL),
+			profiles,
+		)
+		require.NoError(t, err)
+		require.False(t, report.Passed)
+		require.Equal(t, 3, report.Total)
+		require.Equal(t, 2, report.Failed)
+
+		byName := map[string]Result{}
+		for _, result := range report.Results {
+			byName[result.Mutation] = result
+		}
+		require.False(t, byName["foreign-audience"].Passed)
+		require.Equal(t, http.StatusOK, byName["foreign-audience"].Status)
+		require.Contains(t, byName["foreign-audience"].Reason, "accepted with status 200")
+		oversized := byName["oversized-credential-request"]
+		require.True(t, oversized.Passed)
+		require.Equal(t, http.StatusRequestEntityTooLarge, oversized.Status)
+	})
+
+	t.Run("a failing baseline stops the run", func(t *testing.T) {
+		issuer := newFakeIssuer(t, false)
+		issuer.tokenStatus = http.StatusInternalServerError
+		runner := &Runner{HTTPClient: issuer.Client()}
+		profiles, err := Profiles(TargetIssuer, []string{"wrong-aud"})
+		require.NoError(t, err)
+		report, err := runner.Run(
+			context.Background(),
+			parseIssuerTemplate(t, issuer.URL),
+			profiles,
+		)
+		var setupErr *SetupError
+		require.ErrorAs(t, err, &setupErr)
+		require.Equal(t, "token", setupErr.Step)
+		require.Empty(t, report.Results)
+	})
+
+	t.Run("mutations must target template steps", func(t *testing.T) {
+		template, err := ParseTemplate(`
+tests:
+  t:
+    steps:
+      - id: only
+        http: {url: "https://example.org"}`)
+		require.NoError(t, err)
+		_, err = (&Runner{}).Run(context.Background(), template, selected)
+		require.ErrorContains(t, err, "template has no step")
+	})
+}
+
+func TestRunnerVerifier(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /request.jwt", func(w http.ResponseWriter, _ *http.Request) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"response_uri": server.URL + "/response",
+			"state":        "state",
+		})
+		signed, err := token.SignedString([]byte("secret"))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/oauth-authz-req+jwt")
+		_, _ = w.Write([]byte(signed))
+	})
+	mux.HandleFunc("POST /response", func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+		if err := r.ParseForm(); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+		var token map[string]any
+		if r.PostForm.Get("state") != "state" ||
+			json.Unmarshal([]byte(r.PostForm.Get("vp_token")), &token) != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	template, err := ParseTemplate(fmt.Sprintf(`
+tests:
+  verifier:
+    steps:
+      - id: request
+        http:
+          url: %s/request.jwt
+          captures:
+            response_uri: {jsonpath: $.response_uri}
+            state: {jsonpath: $.state, default: ""}
+      - id: response
+        http:
+          url: ${{ captures.response_uri }}
+          method: POST
+          form:
+            vp_token: "{}"
+            state: ${{ captures.state }}
+`, server.URL))
+	require.NoError(t, err)
+	selected, err := Profiles(TargetVerifier, nil)
+	require.NoError(t, err)
+
+	runner := &Runner{HTTPClient: server.Client()}
+	report, err := runner.Run(context.Background(), template, selected)
+	require.NoError(t, err)
+	requireAllPassed(t, report)
+	require.Equal(t, 4, report.Total)
+}
+
+func TestProfiles(t *testing.T) {
+	all, err := Profiles(TargetVerifier, nil)
+	require.NoError(t, err)
+	require.Len(t, all, len(ProfileNames(TargetVerifier)))
+
+	selected, err := Profiles(TargetIssuer, []string{"replayed-dpop"})
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	require.True(t, selected[0].Mutations[0].Replay)
+
+	_, err = Profiles(TargetIssuer, []string{"wrong-state"})
+	require.ErrorContains(t, err, `unknown issuer profile "wrong-state"`)
+
+	_, err = Profiles("wallet", nil)
+	require.ErrorContains(t, err, "unknown target")
+}
+
+func TestExpectationCheck(t *testing.T) {
+	expect := Expectation{Status: []int{http.StatusBadRequest}, Errors: []string{"invalid_proof"}}
+	require.Empty(t, expect.check(http.StatusBadRequest, "invalid_proof"))
+	require.Contains(t, expect.check(http.StatusOK, ""), "accepted")
+	require.Contains(t, expect.check(http.StatusInternalServerError, ""), "status 500")
+	require.Contains(t, expect.check(http.StatusBadRequest, ""), "no error code")
+	require.Contains(
+		t,
+		expect.check(http.StatusBadRequest, "invalid_grant"),
+		`error "invalid_grant"`,
+	)
+
+	expect.ErrorOptional = true
+	require.Empty(t, expect.check(http.StatusBadRequest, ""))
+	require.NotEmpty(t, expect.check(http.StatusBadRequest, "invalid_grant"))
+}
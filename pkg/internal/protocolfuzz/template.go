@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package protocolfuzz sends mutated OpenID4VCI and OpenID4VP requests to
+// issuers and verifiers and checks that they are rejected with spec
+// compliant errors. The baseline requests come from StepCI templates, of
+// which it runs the http steps; mutation profiles name the requests they
+// change and the errors they expect.
+package protocolfuzz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is the subset of a StepCI workflow the runner understands: its
+// tests are flattened, in name order, into one sequence of http steps.
+type Template struct {
+	Steps []Step
+}
+
+// Step is a StepCI http step. Steps need an id, by which mutations refer
+// to them.
+type Step struct {
+	ID   string   `yaml:"id"`
+	Name string   `yaml:"name"`
+	If   string   `yaml:"if"`
+	HTTP HTTPStep `yaml:"http"`
+}
+
+// HTTPStep is the request of a step and the values it captures from the
+// response.
+type HTTPStep struct {
+	URL      string             `yaml:"url"`
+	Method   string             `yaml:"method"`
+	Headers  map[string]string  `yaml:"headers"`
+	Form     map[string]string  `yaml:"form"`
+	JSON     any                `yaml:"json"`
+	Body     string             `yaml:"body"`
+	Captures map[string]Capture `yaml:"captures"`
+}
+
+// Capture reads a value from the JSON response body, or from the claims
+// when the body is a JWT. Default is used when the path does not match;
+// without it the capture stays unset.
+type Capture struct {
+	JSONPath string  `yaml:"jsonpath"`
+	Default  *string `yaml:"default"`
+}
+
+type stepCIWorkflow struct {
+	Tests map[string]struct {
+		Steps []Step `yaml:"steps"`
+	} `yaml:"tests"`
+}
+
+// ParseTemplate parses a rendered StepCI template.
+func ParseTemplate(source string) (*Template, error) {
+	var workflow stepCIWorkflow
+	if err := yaml.Unmarshal([]byte(source), &workflow); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	names := make([]string, 0, len(workflow.Tests))
+	for name := range workflow.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	template := &Template{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		for _, step := range workflow.Tests[name].Steps {
+			if step.ID == "" {
+				return nil, fmt.Errorf("test %s: every step needs an id", name)
+			}
+			if seen[step.ID] {
+				return nil, fmt.Errorf("test %s: duplicate step id %q", name, step.ID)
+			}
+			if step.HTTP.URL == "" {
+				return nil, fmt.Errorf("step %s: only http steps are supported", step.ID)
+			}
+			seen[step.ID] = true
+			template.Steps = append(template.Steps, step)
+		}
+	}
+	if len(template.Steps) == 0 {
+		return nil, errors.New("template has no steps")
+	}
+	return template, nil
+}
+
+// step returns the index of the step with id.
+func (t *Template) step(id string) int {
+	for i, step := range t.Steps {
+		if step.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+var expression = regexp.MustCompile(`\$\{\{\s*(captures|fuzz)\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// substitute replaces the ${{ captures.x }} and ${{ fuzz.x }} expressions
+// of value.
+func substitute(value string, lookup func(scope, name string) (string, error)) (string, error) {
+	var failure error
+	replaced := expression.ReplaceAllStringFunc(value, func(match string) string {
+		groups := expression.FindStringSubmatch(match)
+		resolved, err := lookup(groups[1], groups[2])
+		if err != nil && failure == nil {
+			failure = err
+		}
+		return resolved
+	})
+	return replaced, failure
+}
+
+// substituteJSON substitutes the strings of a decoded YAML value.
+func substituteJSON(value any, lookup func(scope, name string) (string, error)) (any, error) {
+	switch typed := value.(type) {
+	case string:
+		return substitute(typed, lookup)
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, item := range typed {
+			resolved, err := substituteJSON(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			resolved, err := substituteJSON(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+// decodeResponse decodes a JSON body, or the claims of a compact JWT body.
+func decodeResponse(body []byte) (any, bool) {
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		return decoded, true
+	}
+	parts := strings.Split(strings.TrimSpace(string(body)), ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+var pathSegment = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// lookupPath resolves a JSONPath of the form $.a.b[0] in value.
+func lookupPath(value any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return value, value != nil
+	}
+	current := value
+	for _, segment := range strings.Split(path, ".") {
+		groups := pathSegment.FindStringSubmatch(segment)
+		if groups == nil {
+			return nil, false
+		}
+		if groups[1] != "" {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[groups[1]]; !ok {
+				return nil, false
+			}
+		}
+		for _, index := range strings.Split(groups[2], "]") {
+			if index == "" {
+				continue
+			}
+			position, _ := strconv.Atoi(strings.TrimPrefix(index, "["))
+			list, ok := current.([]any)
+			if !ok || position >= len(list) {
+				return nil, false
+			}
+			current = list[position]
+		}
+	}
+	return current, current != nil
+}
+
+// stringValue formats a captured value for substitution.
+func stringValue(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(encoded)
+	}
+}
+
+// formValues converts the form of a step to url.Values.
+func formValues(form map[string]string) url.Values {
+	values := url.Values{}
+	for key, value := range form {
+		values.Set(key, value)
+	}
+	return values
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package protocolfuzz
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate(t *testing.T) {
+	t.Run("flattens the tests in name order", func(t *testing.T) {
+		template, err := ParseTemplate(`
+version: "1.1"
+tests:
+  second:
+    steps:
+      - id: c
+        http: {url: "https://example.org/c"}
+  first:
+    steps:
+      - id: a
+        http: {url: "https://example.org/a"}
+      - id: b
+        http: {url: "https://example.org/b", method: POST, form: {x: "1"}}
+`)
+		require.NoError(t, err)
+		require.Len(t, template.Steps, 3)
+		require.Equal(t, "a", template.Steps[0].ID)
+		require.Equal(t, "b", template.Steps[1].ID)
+		require.Equal(t, "c", template.Steps[2].ID)
+		require.Equal(t, map[string]string{"x": "1"}, template.Steps[1].HTTP.Form)
+	})
+
+	for name, source := range map[string]string{
+		"missing id": `
+tests:
+  t:
+    steps:
+      - http: {url: "https://example.org"}`,
+		"duplicate id": `
+tests:
+  t:
+    steps:
+      - id: a
+        http: {url: "https://example.org"}
+      - id: a
+        http: {url: "https://example.org"}`,
+		"plugin step": `
+tests:
+  t:
+    steps:
+      - id: a
+        plugin: {id: capture-plugin}`,
+		"no steps":     `tests: {}`,
+		"invalid yaml": `tests: [`,
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := ParseTemplate(source)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	lookup := func(scope, name string) (string, error) {
+		return scope + ":" + name, nil
+	}
+	value, err := substitute("Bearer ${{ captures.access_token }}/${{fuzz.proof}}", lookup)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer captures:access_token/fuzz:proof", value)
+
+	resolved, err := substituteJSON(map[string]any{
+		"proofs": map[string]any{"jwt": []any{"${{ fuzz.proof }}"}},
+		"count":  1,
+	}, lookup)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"proofs": map[string]any{"jwt": []any{"fuzz:proof"}},
+		"count":  1,
+	}, resolved)
+}
+
+func TestLookupPath(t *testing.T) {
+	value := map[string]any{
+		"credential_issuer":     "https://issuer.example",
+		"authorization_servers": []any{"https://as.example"},
+		"nested":                map[string]any{"list": []any{[]any{"deep"}}},
+	}
+	tests := []struct {
+		path  string
+		want  any
+		found bool
+	}{
+		{"$.credential_issuer", "https://issuer.example", true},
+		{"$.authorization_servers[0]", "https://as.example", true},
+		{"$.nested.list[0][0]", "deep", true},
+		{"$.authorization_servers[1]", nil, false},
+		{"$.missing", nil, false},
+		{"$.credential_issuer.x", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, found := lookupPath(value, tt.path)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	decoded, ok := decodeResponse([]byte(`{"c_nonce":"n"}`))
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"c_nonce": "n"}, decoded)
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"state":"s"}`))
+	decoded, ok = decodeResponse([]byte("eyJhbGciOiJub25lIn0." + payload + ".sig"))
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"state": "s"}, decoded)
+
+	_, ok = decodeResponse([]byte("plain text"))
+	require.False(t, ok)
+}
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package quota limits what an organization can start and store. Limits are
+// fields of the organizations record and a zero limit is unlimited, so
+// organizations keep their current behaviour until a superuser sets one.
+//
+// Runs per day count the pipelines, conformance checks, custom integrations
+// and reruns that organization members start through the API, in the
+// quota_usage collection.
+// Concurrent runs count the top-level pipeline runs that hold a reservation
+// in the quota_runs collection, taken before the run starts and given back
+// when it ends. Storage sums the pipeline
+// result files of the organization, whose sizes are kept on each record by
+// RegisterStorageHooks. API requests are counted per minute in
+// memory, so the limit applies to each server instance.
+package quota
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	FieldConcurrentRuns    = "quota_concurrent_runs"
+	FieldRunsPerDay        = "quota_runs_per_day"
+	FieldStorageMB         = "quota_storage_mb"
+	FieldRequestsPerMinute = "quota_requests_per_minute"
+
+	// ReservedRunConfigKey carries, in the config of a pipeline run, the
+	// namespace of the organization whose concurrent runs the run counts
+	// against. The run confirms its reservation when it starts, which is
+	// when scheduled runs take theirs, and releases it when it ends.
+	ReservedRunConfigKey = "quota_reserved_run"
+)
+
+// Quota names, used in errors and usage reports.
+const (
+	ConcurrentRuns    = "concurrent_runs"
+	RunsPerDay        = "runs_per_day"
+	Storage           = "storage"
+	RequestsPerMinute = "requests_per_minute"
+)
+
+// Limits are the quotas of an organization. Zero means unlimited.
+type Limits struct {
+	ConcurrentRuns    int `json:"concurrent_runs"`
+	RunsPerDay        int `json:"runs_per_day"`
+	StorageMB         int `json:"storage_mb"`
+	RequestsPerMinute int `json:"requests_per_minute"`
+}
+
+// Usage is what an organization currently consumes of its Limits.
+type Usage struct {
+	ConcurrentRuns     int64 `json:"concurrent_runs"`
+	RunsToday          int64 `json:"runs_today"`
+	StorageBytes       int64 `json:"storage_bytes"`
+	RequestsThisMinute int   `json:"requests_this_minute"`
+}
+
+// LimitsFromRecord reads the limits of an organizations record. Negative
+// values are treated as unlimited.
+func LimitsFromRecord(org *core.Record) Limits {
+	if org == nil {
+		return Limits{}
+	}
+	return Limits{
+		ConcurrentRuns:    max(org.GetInt(FieldConcurrentRuns), 0),
+		RunsPerDay:        max(org.GetInt(FieldRunsPerDay), 0),
+		StorageMB:         max(org.GetInt(FieldStorageMB), 0),
+		RequestsPerMinute: max(org.GetInt(FieldRequestsPerMinute), 0),
+	}
+}
+
+// StorageBytes is the storage limit in bytes.
+func (l Limits) StorageBytes() int64 {
+	return int64(l.StorageMB) << 20
+}
+
+// CheckConcurrentRuns fails when running executions leave no room for one
+// more.
+func (l Limits) CheckConcurrentRuns(running int64) error {
+	if l.ConcurrentRuns == 0 || running < int64(l.ConcurrentRuns) {
+		return nil
+	}
+	return &ExceededError{Quota: ConcurrentRuns, Limit: int64(l.ConcurrentRuns), Used: running}
+}
+
+// CheckRunsPerDay fails when the runs started today leave no room for one
+// more. The error asks to retry at the next UTC midnight.
+func (l Limits) CheckRunsPerDay(today int64, now time.Time) error {
+	if l.RunsPerDay == 0 || today < int64(l.RunsPerDay) {
+		return nil
+	}
+	return &ExceededError{
+		Quota:      RunsPerDay,
+		Limit:      int64(l.RunsPerDay),
+		Used:       today,
+		RetryAfter: StartOfDay(now).Add(24 * time.Hour).Sub(now.UTC()),
+	}
+}
+
+// CheckStorage fails when the stored bytes reach the storage limit.
+func (l Limits) CheckStorage(used int64) error {
+	if l.StorageMB == 0 || used < l.StorageBytes() {
+		return nil
+	}
+	return &ExceededError{Quota: Storage, Limit: l.StorageBytes(), Used: used}
+}
+
+// ExceededError reports a quota that does not allow the request.
+// RetryAfter is zero when waiting alone does not free the quota.
+type ExceededError struct {
+	Quota      string
+	Limit      int64
+	Used       int64
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf(exceededFormat, e.Quota, e.Used, e.Limit)
+}
+
+const exceededFormat = "organization %s quota exceeded: %d of %d used"
+
+// ParseExceededError reads back the message of an ExceededError, as found
+// in the body of a quota_exceeded API error. It returns nil for any other
+// message.
+func ParseExceededError(message string) *ExceededError {
+	var exceeded ExceededError
+	if _, err := fmt.Sscanf(message, exceededFormat, &exceeded.Quota, &exceeded.Used, &exceeded.Limit); err != nil {
+		return nil
+	}
+	return &exceeded
+}
+
+// StartOfDay returns the UTC midnight starting the day of t.
+func StartOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
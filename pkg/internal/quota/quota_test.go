@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/stretchr/testify/require"
+)
+
+const quotaTestDataDir = "../../../test_pb_data"
+
+func TestLimitsChecks(t *testing.T) {
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Limits{}.CheckConcurrentRuns(100))
+	require.NoError(t, Limits{}.CheckRunsPerDay(100, now))
+	require.NoError(t, Limits{}.CheckStorage(1<<40))
+
+	limits := Limits{ConcurrentRuns: 2, RunsPerDay: 10, StorageMB: 1}
+	require.NoError(t, limits.CheckConcurrentRuns(1))
+	require.NoError(t, limits.CheckRunsPerDay(9, now))
+	require.NoError(t, limits.CheckStorage(1<<20-1))
+
+	var exceeded *ExceededError
+	require.ErrorAs(t, limits.CheckConcurrentRuns(2), &exceeded)
+	require.Equal(t, ConcurrentRuns, exceeded.Quota)
+	require.Zero(t, exceeded.RetryAfter)
+
+	require.ErrorAs(t, limits.CheckRunsPerDay(10, now), &exceeded)
+	require.Equal(t, RunsPerDay, exceeded.Quota)
+	require.Equal(t, 6*time.Hour, exceeded.RetryAfter)
+
+	require.ErrorAs(t, limits.CheckStorage(1<<20), &exceeded)
+	require.Equal(t, Storage, exceeded.Quota)
+	require.Equal(t, int64(1<<20), exceeded.Limit)
+	require.Contains(t, exceeded.Error(), "storage quota exceeded")
+}
+
+func TestLimitsFromRecord(t *testing.T) {
+	collection := core.NewBaseCollection("organizations")
+	collection.Fields.Add(
+		&core.NumberField{Name: FieldConcurrentRuns},
+		&core.NumberField{Name: FieldRunsPerDay},
+		&core.NumberField{Name: FieldStorageMB},
+		&core.NumberField{Name: FieldRequestsPerMinute},
+	)
+	org := core.NewRecord(collection)
+	org.Set(FieldConcurrentRuns, 3)
+	org.Set(FieldRunsPerDay, -1)
+	org.Set(FieldStorageMB, 512)
+	org.Set(FieldRequestsPerMinute, 60)
+
+	require.Equal(t, Limits{
+		ConcurrentRuns:    3,
+		StorageMB:         512,
+		RequestsPerMinute: 60,
+	}, LimitsFromRecord(org))
+	require.Equal(t, Limits{}, LimitsFromRecord(nil))
+}
+
+func TestParseExceededError(t *testing.T) {
+	exceeded := &ExceededError{Quota: ConcurrentRuns, Limit: 2, Used: 2}
+	require.Equal(t, exceeded, ParseExceededError(exceeded.Error()))
+	require.Nil(t, ParseExceededError("organization not found"))
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Date(2026, 3, 1, 18, 0, 15, 0, time.UTC)
+
+	for range 2 {
+		allowed, _ := limiter.Allow("org", 2, now)
+		require.True(t, allowed)
+	}
+	allowed, retryAfter := limiter.Allow("org", 2, now)
+	require.False(t, allowed)
+	require.Equal(t, 45*time.Second, retryAfter)
+	require.Equal(t, 2, limiter.Used("org", now))
+
+	allowed, _ = limiter.Allow("other", 2, now)
+	require.True(t, allowed)
+
+	next := now.Add(time.Minute)
+	require.Zero(t, limiter.Used("org", next))
+	allowed, _ = limiter.Allow("org", 2, next)
+	require.True(t, allowed)
+	require.Len(t, limiter.windows, 1)
+}
+
+func TestRunsLedger(t *testing.T) {
+	app, err := tests.NewTestApp(quotaTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	org := createTestOrganization(t, app, "quota-ledger-org")
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+
+	runs, err := RunsToday(app, org.Id, now)
+	require.NoError(t, err)
+	require.Zero(t, runs)
+
+	require.NoError(t, RecordRun(app, org.Id, now))
+	require.NoError(t, RecordRun(app, org.Id, now))
+	runs, err = RunsToday(app, org.Id, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), runs)
+
+	tomorrow := now.Add(2 * time.Minute)
+	runs, err = RunsToday(app, org.Id, tomorrow)
+	require.NoError(t, err)
+	require.Zero(t, runs)
+}
+
+func TestReserveRunConcurrently(t *testing.T) {
+	app, err := tests.NewTestApp(quotaTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	org := createTestOrganization(t, app, "quota-reserve-org")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limits := Limits{RunsPerDay: 3}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+		exceeded int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ReserveRun(app, org.Id, limits, now)
+			mu.Lock()
+			defer mu.Unlock()
+			var exceededErr *ExceededError
+			switch {
+			case err == nil:
+				reserved++
+			case errors.As(err, &exceededErr):
+				exceeded++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 3, reserved)
+	require.Equal(t, 7, exceeded)
+
+	runs, err := RunsToday(app, org.Id, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), runs)
+
+	require.NoError(t, ReleaseRun(app, org.Id, now))
+	require.NoError(t, ReserveRun(app, org.Id, limits, now))
+	var exceededErr *ExceededError
+	require.ErrorAs(t, ReserveRun(app, org.Id, limits, now), &exceededErr)
+}
+
+func TestStorageBytes(t *testing.T) {
+	app, err := tests.NewTestApp(quotaTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	RegisterStorageHooks(app)
+
+	org := createTestOrganization(t, app, "quota-storage-org")
+	used, err := StorageBytes(app, org.Id)
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	collection, err := app.FindCollectionByNameOrId("pipeline_results")
+	require.NoError(t, err)
+	record := core.NewRecord(collection)
+	record.Set("owner", org.Id)
+	record.Set("workflow_id", "wf-quota")
+	record.Set("run_id", "run-quota")
+	video, err := filesystem.NewFileFromBytes(make([]byte, 1000), "run_result_video.mp4")
+	require.NoError(t, err)
+	report, err := filesystem.NewFileFromBytes(make([]byte, 24), "report.html")
+	require.NoError(t, err)
+	record.Set("video_results", []any{video})
+	record.Set("report", report)
+	require.NoError(t, app.Save(record))
+
+	used, err = StorageBytes(app, org.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1024), used)
+
+	record, err = app.FindRecordById("pipeline_results", record.Id)
+	require.NoError(t, err)
+	require.Equal(t, 1024, record.GetInt(FieldFilesBytes))
+	screenshot, err := filesystem.NewFileFromBytes(make([]byte, 2048), "screenshot.png")
+	require.NoError(t, err)
+	record.Set("screenshots+", []any{screenshot})
+	record.Set("report", nil)
+	require.NoError(t, app.Save(record))
+
+	used, err = StorageBytes(app, org.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(3048), used)
+
+	require.NoError(t, app.Delete(record))
+	used, err = StorageBytes(app, org.Id)
+	require.NoError(t, err)
+	require.Zero(t, used)
+}
+
+func TestReserveConcurrentRunConcurrently(t *testing.T) {
+	app, err := tests.NewTestApp(quotaTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	org := createTestOrganization(t, app, "quota-concurrent-org")
+	limits := Limits{ConcurrentRuns: 2}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved []string
+		exceeded int
+	)
+	for i := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workflowID := fmt.Sprintf("Pipeline-%d", i)
+			err := ReserveConcurrentRun(app, org.Id, workflowID, limits)
+			mu.Lock()
+			defer mu.Unlock()
+			var exceededErr *ExceededError
+			switch {
+			case err == nil:
+				reserved = append(reserved, workflowID)
+			case errors.As(err, &exceededErr):
+				exceeded++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Len(t, reserved, 2)
+	require.Equal(t, 4, exceeded)
+
+	running, err := RunningRuns(app, org.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), running)
+
+	// The run confirming its own reservation is accepted again.
+	require.NoError(t, ReserveConcurrentRun(app, org.Id, reserved[0], limits))
+
+	var exceededErr *ExceededError
+	require.ErrorAs(t, ReserveConcurrentRun(app, org.Id, "Pipeline-new", limits), &exceededErr)
+	require.NoError(t, ReleaseConcurrentRun(app, org.Id, reserved[0]))
+	require.NoError(t, ReleaseConcurrentRun(app, org.Id, reserved[0]))
+	require.NoError(t, ReserveConcurrentRun(app, org.Id, "Pipeline-new", limits))
+}
+
+func TestPruneConcurrentRuns(t *testing.T) {
+	app, err := tests.NewTestApp(quotaTestDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	org := createTestOrganization(t, app, "quota-prune-org")
+	for _, workflowID := range []string{"Pipeline-ended", "Pipeline-running"} {
+		require.NoError(t, ReserveConcurrentRun(app, org.Id, workflowID, Limits{}))
+	}
+
+	running := func(workflowID string) (bool, error) {
+		return workflowID == "Pipeline-running", nil
+	}
+	require.NoError(t, PruneConcurrentRuns(app, org.Id, time.Now().Add(-time.Hour), running))
+	count, err := RunningRuns(app, org.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	require.NoError(t, PruneConcurrentRuns(app, org.Id, time.Now().Add(time.Hour), running))
+	count, err = RunningRuns(app, org.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	_, err = findRunningRecord(app, org.Id, "Pipeline-running")
+	require.NoError(t, err)
+
+	require.ErrorContains(t, PruneConcurrentRuns(
+		app,
+		org.Id,
+		time.Now().Add(time.Hour),
+		func(string) (bool, error) { return false, errors.New("unavailable") },
+	), "unavailable")
+}
+
+func createTestOrganization(t *testing.T, app *tests.TestApp, name string) *core.Record {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId("organizations")
+	require.NoError(t, err)
+	org := core.NewRecord(collection)
+	org.Set("name", name)
+	require.NoError(t, app.Save(org))
+	return org
+}
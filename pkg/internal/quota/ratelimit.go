@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package quota
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter counts requests per key in fixed one minute windows. Counts are
+// kept in the memory of the process and are not shared: with N server
+// instances behind a load balancer, a key can make up to N times the limit.
+type Limiter struct {
+	mu      sync.Mutex
+	windows map[string]limiterWindow
+	pruned  time.Time
+}
+
+type limiterWindow struct {
+	start time.Time
+	count int
+}
+
+// Requests is the limiter of API requests per organization. Like every
+// Limiter, it applies the requests per minute quota to each server instance
+// on its own, and its counts restart with the process.
+var Requests = NewLimiter()
+
+func NewLimiter() *Limiter {
+	return &Limiter{windows: map[string]limiterWindow{}}
+}
+
+// Allow counts a request of key at now and reports whether it fits in
+// limit, with the time left before the window resets. Refused requests are
+// not counted.
+func (l *Limiter) Allow(key string, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := now.Truncate(time.Minute)
+	l.prune(start)
+	window := l.windows[key]
+	if !window.start.Equal(start) {
+		window = limiterWindow{start: start}
+	}
+	if window.count >= limit {
+		return false, start.Add(time.Minute).Sub(now)
+	}
+	window.count++
+	l.windows[key] = window
+	return true, 0
+}
+
+// Used returns the requests of key counted in the window of now.
+func (l *Limiter) Used(key string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window := l.windows[key]
+	if !window.start.Equal(now.Truncate(time.Minute)) {
+		return 0
+	}
+	return window.count
+}
+
+// prune drops the windows before start, once per window, so keys that stop
+// sending requests do not accumulate.
+func (l *Limiter) prune(start time.Time) {
+	if !l.pruned.Before(start) {
+		return
+	}
+	l.pruned = start
+	for key, window := range l.windows {
+		if window.start.Before(start) {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package quota
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// UsageCollection holds one record per organization and UTC day with the
+// number of runs started that day.
+const UsageCollection = "quota_usage"
+
+const dayLayout = "2006-01-02"
+
+// RunsToday returns how many runs the organization started on the UTC day
+// of now.
+func RunsToday(app core.App, orgID string, now time.Time) (int64, error) {
+	record, err := findUsageRecord(app, orgID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(record.GetInt("runs")), nil
+}
+
+// RecordRun counts one more run started by the organization on the UTC day
+// of now.
+func RecordRun(app core.App, orgID string, now time.Time) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		return addRuns(txApp, orgID, now, 1, nil)
+	})
+}
+
+// ReserveRun counts one more run started by the organization on the UTC day
+// of now, unless limits leave no room for it. The check and the count happen
+// in one transaction, so concurrent runs cannot go past the limit. A run
+// that then fails to start is given back with ReleaseRun.
+func ReserveRun(app core.App, orgID string, limits Limits, now time.Time) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		return addRuns(txApp, orgID, now, 1, func(today int64) error {
+			return limits.CheckRunsPerDay(today, now)
+		})
+	})
+}
+
+// ReleaseRun gives back a run reserved with ReserveRun on the UTC day of now.
+func ReleaseRun(app core.App, orgID string, now time.Time) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		return addRuns(txApp, orgID, now, -1, nil)
+	})
+}
+
+// addRuns adds delta to the runs of the organization on the UTC day of now,
+// after check, when given, accepts the runs already counted.
+func addRuns(
+	txApp core.App,
+	orgID string,
+	now time.Time,
+	delta int,
+	check func(today int64) error,
+) error {
+	record, err := findUsageRecord(txApp, orgID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := txApp.FindCollectionByNameOrId(UsageCollection)
+		if err != nil {
+			return err
+		}
+		record = core.NewRecord(collection)
+		record.Set("owner", orgID)
+		record.Set("day", now.UTC().Format(dayLayout))
+	} else if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(int64(record.GetInt("runs"))); err != nil {
+			return err
+		}
+	}
+	record.Set("runs", max(record.GetInt("runs")+delta, 0))
+	return txApp.Save(record)
+}
+
+func findUsageRecord(app core.App, orgID string, now time.Time) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		UsageCollection,
+		"owner = {:owner} && day = {:day}",
+		dbx.Params{"owner": orgID, "day": now.UTC().Format(dayLayout)},
+	)
+}
+
+// RunningCollection holds one record per pipeline run of the organization
+// that has not ended, keyed by the workflow id of the run. Child pipelines
+// run inside the run that started them and are not recorded.
+const RunningCollection = "quota_runs"
+
+// ReserveConcurrentRun records the run of workflowID as running for the
+// organization, unless limits leave no room for it. The check and the
+// record happen in one transaction, so concurrent starts cannot go past the
+// limit. A run already recorded is accepted again, so that the run can
+// confirm the reservation made by whoever started it. The run is given back
+// with ReleaseConcurrentRun when it ends.
+func ReserveConcurrentRun(app core.App, orgID, workflowID string, limits Limits) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		_, err := findRunningRecord(txApp, orgID, workflowID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		running, err := RunningRuns(txApp, orgID)
+		if err != nil {
+			return err
+		}
+		if err := limits.CheckConcurrentRuns(running); err != nil {
+			return err
+		}
+		collection, err := txApp.FindCollectionByNameOrId(RunningCollection)
+		if err != nil {
+			return err
+		}
+		record := core.NewRecord(collection)
+		record.Set("owner", orgID)
+		record.Set("workflow_id", workflowID)
+		return txApp.Save(record)
+	})
+}
+
+// ReleaseConcurrentRun gives back the run of workflowID. Releasing a run
+// that holds no reservation does nothing.
+func ReleaseConcurrentRun(app core.App, orgID, workflowID string) error {
+	record, err := findRunningRecord(app, orgID, workflowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return app.Delete(record)
+}
+
+// RunningRuns returns how many runs of the organization hold a
+// reservation.
+func RunningRuns(app core.App, orgID string) (int64, error) {
+	return app.CountRecords(RunningCollection, dbx.HashExp{"owner": orgID})
+}
+
+// PruneConcurrentRuns releases the runs reserved before the given time for
+// which running reports that the workflow ended, so that runs terminated
+// without releasing their reservation do not hold it forever.
+func PruneConcurrentRuns(
+	app core.App,
+	orgID string,
+	before time.Time,
+	running func(workflowID string) (bool, error),
+) error {
+	records, err := app.FindRecordsByFilter(
+		RunningCollection,
+		"owner = {:owner} && created < {:before}",
+		"",
+		-1,
+		0,
+		dbx.Params{"owner": orgID, "before": before.UTC().Format(types.DefaultDateLayout)},
+	)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		isRunning, err := running(record.GetString("workflow_id"))
+		if err != nil {
+			return err
+		}
+		if isRunning {
+			continue
+		}
+		if err := app.Delete(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findRunningRecord(app core.App, orgID, workflowID string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		RunningCollection,
+		"owner = {:owner} && workflow_id = {:workflow}",
+		dbx.Params{"owner": orgID, "workflow": workflowID},
+	)
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// FieldFilesBytes is the pipeline_results field holding the size of the
+// files of the record, kept by the storage hooks.
+const FieldFilesBytes = "files_bytes"
+
+const resultsCollection = "pipeline_results"
+
+// resultFileFields are the pipeline_results fields counted as storage.
+var resultFileFields = []string{
+	"video_results",
+	"screenshots",
+	"logcats",
+	"ios_logstreams",
+	"report",
+}
+
+// RegisterStorageHooks keeps the files_bytes field of pipeline_results up to
+// date when their files are created or changed. Deleted records drop out of
+// the sum with their row.
+func RegisterStorageHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess(resultsCollection).BindFunc(
+		func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			storeFilesBytes(e.App, e.Record)
+			return nil
+		},
+	)
+	app.OnRecordAfterUpdateSuccess(resultsCollection).BindFunc(
+		func(e *core.RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+			if filesChanged(e.Record) {
+				storeFilesBytes(e.App, e.Record)
+			}
+			return nil
+		},
+	)
+}
+
+// StorageBytes sums the size of the pipeline result files owned by the
+// organization, as recorded in their files_bytes field.
+func StorageBytes(app core.App, orgID string) (int64, error) {
+	var total int64
+	err := app.DB().
+		Select("COALESCE(SUM([[" + FieldFilesBytes + "]]), 0)").
+		From(resultsCollection).
+		Where(dbx.HashExp{"owner": orgID}).
+		Row(&total)
+	return total, err
+}
+
+// filesBytes sums the size of the files of a pipeline_results record. Files
+// missing from the storage are skipped.
+func filesBytes(app core.App, record *core.Record) (int64, error) {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open the file storage: %w", err)
+	}
+	defer fsys.Close()
+
+	var total int64
+	for _, field := range resultFileFields {
+		for _, name := range record.GetStringSlice(field) {
+			attrs, err := fsys.Attributes(path.Join(record.BaseFilesPath(), name))
+			if errors.Is(err, filesystem.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			total += attrs.Size
+		}
+	}
+	return total, nil
+}
+
+// storeFilesBytes writes the files size of record to its row alone, without
+// saving the record again.
+func storeFilesBytes(app core.App, record *core.Record) {
+	size, err := filesBytes(app, record)
+	if err != nil {
+		app.Logger().Error(
+			"measure pipeline result files failed",
+			"record_id", record.Id,
+			"error", err,
+		)
+		return
+	}
+	if size == int64(record.GetInt(FieldFilesBytes)) {
+		return
+	}
+	_, err = app.DB().Update(
+		resultsCollection,
+		dbx.Params{FieldFilesBytes: size},
+		dbx.HashExp{"id": record.Id},
+	).Execute()
+	if err != nil {
+		app.Logger().Error(
+			"store pipeline result files size failed",
+			"record_id", record.Id,
+			"error", err,
+		)
+		return
+	}
+	record.Set(FieldFilesBytes, size)
+}
+
+func filesChanged(record *core.Record) bool {
+	original := record.Original()
+	for _, field := range resultFileFields {
+		if !slices.Equal(record.GetStringSlice(field), original.GetStringSlice(field)) {
+			return true
+		}
+	}
+	return false
+}
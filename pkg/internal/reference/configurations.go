@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Credential formats of the reference services.
+const (
+	FormatSDJWT = "dc+sd-jwt"
+	FormatMDoc  = "mso_mdoc"
+)
+
+// Identifiers of the credential configurations the reference issuer offers.
+const (
+	ConfigurationPID = "pid_sd_jwt"
+	ConfigurationMDL = "mdl_mdoc"
+)
+
+// CredentialConfiguration describes a credential the reference issuer can
+// issue and the reference verifier can request.
+type CredentialConfiguration struct {
+	ID        string
+	Format    string
+	Name      string
+	VCT       string
+	DocType   string
+	Namespace string
+	// Claims are the default claim values, overridable per session.
+	Claims map[string]any
+	// DateClaims are mdoc elements encoded as CBOR full-date.
+	DateClaims []string
+}
+
+// Configurations lists the supported credential configurations.
+var Configurations = map[string]CredentialConfiguration{
+	ConfigurationPID: {
+		ID:     ConfigurationPID,
+		Format: FormatSDJWT,
+		Name:   "Reference PID",
+		VCT:    "urn:eudi:pid:1",
+		Claims: map[string]any{
+			"given_name":    "Erika",
+			"family_name":   "Mustermann",
+			"birthdate":     "1984-01-26",
+			"nationalities": []any{"DE"},
+			"age_equal_or_over": map[string]any{
+				"18": true,
+			},
+		},
+	},
+	ConfigurationMDL: {
+		ID:        ConfigurationMDL,
+		Format:    FormatMDoc,
+		Name:      "Reference mDL",
+		DocType:   "org.iso.18013.5.1.mDL",
+		Namespace: "org.iso.18013.5.1",
+		Claims: map[string]any{
+			"given_name":      "Erika",
+			"family_name":     "Mustermann",
+			"birth_date":      "1984-01-26",
+			"issue_date":      "2026-01-01",
+			"expiry_date":     "2036-01-01",
+			"issuing_country": "DE",
+			"document_number": "REF000001",
+			"age_over_18":     true,
+		},
+		DateClaims: []string{"birth_date", "issue_date", "expiry_date"},
+	},
+}
+
+// ConfigurationIDs returns the supported configuration identifiers, sorted.
+func ConfigurationIDs() []string {
+	return slices.Sorted(maps.Keys(Configurations))
+}
+
+// LookupConfiguration returns the configuration with id.
+func LookupConfiguration(id string) (CredentialConfiguration, error) {
+	configuration, ok := Configurations[id]
+	if !ok {
+		return CredentialConfiguration{}, fmt.Errorf(
+			"unknown credential configuration %q, expected one of %v",
+			id,
+			ConfigurationIDs(),
+		)
+	}
+	return configuration, nil
+}
+
+// ClaimValues returns the default claims with overrides applied.
+func (c CredentialConfiguration) ClaimValues(overrides map[string]any) map[string]any {
+	claims := maps.Clone(c.Claims)
+	maps.Copy(claims, overrides)
+	return claims
+}
+
+// ClaimNames returns the default claim names, sorted.
+func (c CredentialConfiguration) ClaimNames() []string {
+	return slices.Sorted(maps.Keys(c.Claims))
+}
+
+// IssuerMetadata is the credential_configurations_supported entry.
+func (c CredentialConfiguration) IssuerMetadata() map[string]any {
+	metadata := map[string]any{
+		"format": c.Format,
+		"scope":  c.ID,
+		"proof_types_supported": map[string]any{
+			"jwt": map[string]any{"proof_signing_alg_values_supported": []string{Algorithm}},
+		},
+		"credential_metadata": map[string]any{
+			"display": []map[string]any{{"name": c.Name, "locale": "en"}},
+		},
+	}
+	switch c.Format {
+	case FormatMDoc:
+		metadata["doctype"] = c.DocType
+		metadata["cryptographic_binding_methods_supported"] = []string{"cose_key"}
+		metadata["credential_signing_alg_values_supported"] = []int{-7}
+	default:
+		metadata["vct"] = c.VCT
+		metadata["cryptographic_binding_methods_supported"] = []string{"jwk"}
+		metadata["credential_signing_alg_values_supported"] = []string{Algorithm}
+	}
+	return metadata
+}
+
+// CredentialQuery builds the DCQL credential query for claims, or for every
+// default claim when claims is empty. The query id is the configuration id.
+func (c CredentialConfiguration) CredentialQuery(claims []string) map[string]any {
+	if len(claims) == 0 {
+		claims = c.ClaimNames()
+	}
+	claimQueries := make([]map[string]any, 0, len(claims))
+	for _, claim := range claims {
+		path := []string{claim}
+		if c.Format == FormatMDoc {
+			path = []string{c.Namespace, claim}
+		}
+		claimQueries = append(claimQueries, map[string]any{"path": path})
+	}
+	query := map[string]any{
+		"id":     c.ID,
+		"format": c.Format,
+		"claims": claimQueries,
+	}
+	if c.Format == FormatMDoc {
+		query["meta"] = map[string]any{"doctype_value": c.DocType}
+	} else {
+		query["meta"] = map[string]any{"vct_values": []string{c.VCT}}
+	}
+	return query
+}
+
+// VPFormats is the vp_formats_supported client metadata of the verifier.
+func VPFormats() map[string]any {
+	return map[string]any{
+		FormatSDJWT: map[string]any{
+			"sd-jwt_alg_values": []string{Algorithm},
+			"kb-jwt_alg_values": []string{Algorithm},
+		},
+		FormatMDoc: map[string]any{
+			"issuerauth_alg_values": []int{-7},
+			"deviceauth_alg_values": []int{-7},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupConfiguration(t *testing.T) {
+	require.Equal(t, []string{ConfigurationMDL, ConfigurationPID}, ConfigurationIDs())
+
+	configuration, err := LookupConfiguration(ConfigurationPID)
+	require.NoError(t, err)
+	require.Equal(t, FormatSDJWT, configuration.Format)
+
+	_, err = LookupConfiguration("unknown")
+	require.ErrorContains(t, err, "unknown credential configuration")
+}
+
+func TestClaimValuesDoNotMutateDefaults(t *testing.T) {
+	configuration := Configurations[ConfigurationPID]
+	claims := configuration.ClaimValues(map[string]any{"given_name": "Mario", "extra": 1})
+	require.Equal(t, "Mario", claims["given_name"])
+	require.Equal(t, 1, claims["extra"])
+	require.Equal(t, "Erika", Configurations[ConfigurationPID].Claims["given_name"])
+	require.NotContains(t, Configurations[ConfigurationPID].Claims, "extra")
+}
+
+func TestCredentialQuery(t *testing.T) {
+	query := Configurations[ConfigurationMDL].CredentialQuery([]string{"family_name"})
+	require.Equal(t, map[string]any{
+		"id":     ConfigurationMDL,
+		"format": FormatMDoc,
+		"meta":   map[string]any{"doctype_value": "org.iso.18013.5.1.mDL"},
+		"claims": []map[string]any{
+			{"path": []string{"org.iso.18013.5.1", "family_name"}},
+		},
+	}, query)
+
+	query = Configurations[ConfigurationPID].CredentialQuery(nil)
+	require.Len(t, query["claims"], len(Configurations[ConfigurationPID].Claims))
+}
@@ -0,0 +1,488 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Issuance flows of the reference issuer.
+const (
+	FlowPreAuthorizedCode = "pre-authorized_code"
+	FlowAuthorizationCode = "authorization_code"
+)
+
+// Grant types of the token endpoint.
+const (
+	GrantPreAuthorizedCode = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+	GrantAuthorizationCode = "authorization_code"
+)
+
+const (
+	typProof = "openid4vci-proof+jwt"
+	// AccessTokenLifetime is the expires_in of issued access tokens.
+	AccessTokenLifetime = 10 * time.Minute
+)
+
+// IssuerConfig selects what a reference issuer session offers.
+type IssuerConfig struct {
+	Configuration string         `json:"configuration"     yaml:"configuration"     validate:"required,oneof=pid_sd_jwt mdl_mdoc"`
+	Flow          string         `json:"flow,omitempty"    yaml:"flow,omitempty"    validate:"omitempty,oneof=pre-authorized_code authorization_code"`
+	TxCode        string         `json:"tx_code,omitempty" yaml:"tx_code,omitempty" validate:"omitempty,numeric,max=8"`
+	Claims        map[string]any `json:"claims,omitempty"  yaml:"claims,omitempty"`
+}
+
+// IssuerSecrets are the per session codes and tokens, generated once when
+// the session starts.
+type IssuerSecrets struct {
+	PreAuthorizedCode string `json:"pre_authorized_code"`
+	IssuerState       string `json:"issuer_state"`
+	AuthorizationCode string `json:"authorization_code"`
+	AccessToken       string `json:"access_token"`
+	Nonce             string `json:"nonce"`
+}
+
+// NewIssuerSecrets generates random session secrets.
+func NewIssuerSecrets() (IssuerSecrets, error) {
+	var secrets IssuerSecrets
+	for _, target := range []*string{
+		&secrets.PreAuthorizedCode,
+		&secrets.IssuerState,
+		&secrets.AuthorizationCode,
+		&secrets.AccessToken,
+		&secrets.Nonce,
+	} {
+		value, err := randomString(24)
+		if err != nil {
+			return IssuerSecrets{}, err
+		}
+		*target = value
+	}
+	return secrets, nil
+}
+
+// IssuerSession is the state of one issuance. Its methods implement the
+// OpenID4VCI and OAuth endpoints without side effects, so that a session
+// workflow can replay them deterministically.
+type IssuerSession struct {
+	IssuerURL string        `json:"issuer_url"`
+	Config    IssuerConfig  `json:"config"`
+	Secrets   IssuerSecrets `json:"secrets"`
+
+	CodeChallenge string `json:"code_challenge,omitempty"`
+	RedirectURI   string `json:"redirect_uri,omitempty"`
+	Authorized    bool   `json:"authorized"`
+	TokenIssued   bool   `json:"token_issued"`
+	Issued        int    `json:"issued"`
+}
+
+// ProtocolError is an OAuth style error response.
+type ProtocolError struct {
+	Status      int    `json:"status"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func protocolError(status int, code, format string, args ...any) *ProtocolError {
+	return &ProtocolError{Status: status, Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+func (s *IssuerSession) flow() string {
+	if s.Config.Flow == "" {
+		return FlowPreAuthorizedCode
+	}
+	return s.Config.Flow
+}
+
+// Endpoint returns the URL of an endpoint of the session issuer.
+func (s *IssuerSession) Endpoint(name string) string {
+	return s.IssuerURL + "/" + name
+}
+
+// Offer is the credential offer of the session.
+func (s *IssuerSession) Offer() map[string]any {
+	grants := map[string]any{}
+	if s.flow() == FlowAuthorizationCode {
+		grants[GrantAuthorizationCode] = map[string]any{"issuer_state": s.Secrets.IssuerState}
+	} else {
+		grant := map[string]any{"pre-authorized_code": s.Secrets.PreAuthorizedCode}
+		if s.Config.TxCode != "" {
+			grant["tx_code"] = map[string]any{
+				"input_mode": "numeric",
+				"length":     len(s.Config.TxCode),
+			}
+		}
+		grants[GrantPreAuthorizedCode] = grant
+	}
+	return map[string]any{
+		"credential_issuer":            s.IssuerURL,
+		"credential_configuration_ids": []string{s.Config.Configuration},
+		"grants":                       grants,
+	}
+}
+
+// OfferDeeplink is the credential offer passed by value.
+func (s *IssuerSession) OfferDeeplink() (string, error) {
+	offer, err := json.Marshal(s.Offer())
+	if err != nil {
+		return "", err
+	}
+	return "openid-credential-offer://?credential_offer=" + url.QueryEscape(string(offer)), nil
+}
+
+// CredentialIssuerMetadata is served at .well-known/openid-credential-issuer.
+func (s *IssuerSession) CredentialIssuerMetadata() (map[string]any, error) {
+	configuration, err := LookupConfiguration(s.Config.Configuration)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"credential_issuer":     s.IssuerURL,
+		"authorization_servers": []string{s.IssuerURL},
+		"credential_endpoint":   s.Endpoint("credential"),
+		"nonce_endpoint":        s.Endpoint("nonce"),
+		"display":               []map[string]any{{"name": "Credimi Reference Issuer"}},
+		"credential_configurations_supported": map[string]any{
+			configuration.ID: configuration.IssuerMetadata(),
+		},
+	}, nil
+}
+
+// AuthorizationServerMetadata is served at .well-known/oauth-authorization-server.
+func (s *IssuerSession) AuthorizationServerMetadata() map[string]any {
+	return map[string]any{
+		"issuer":                   s.IssuerURL,
+		"authorization_endpoint":   s.Endpoint("authorize"),
+		"token_endpoint":           s.Endpoint("token"),
+		"response_types_supported": []string{"code"},
+		"grant_types_supported": []string{
+			GrantAuthorizationCode,
+			GrantPreAuthorizedCode,
+		},
+		"code_challenge_methods_supported":                []string{"S256"},
+		"token_endpoint_auth_methods_supported":           []string{"none"},
+		"pre-authorized_grant_anonymous_access_supported": true,
+	}
+}
+
+// AuthorizeRequest holds the parameters of an authorization request.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	State               string `json:"state,omitempty"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	IssuerState         string `json:"issuer_state,omitempty"`
+}
+
+// Authorize approves an authorization request and returns the redirect
+// location carrying the authorization code.
+func (s *IssuerSession) Authorize(req AuthorizeRequest) (string, *ProtocolError) {
+	if s.flow() != FlowAuthorizationCode {
+		return "", protocolError(
+			http.StatusBadRequest,
+			"unauthorized_client",
+			"the session offers the pre-authorized code flow",
+		)
+	}
+	if req.ResponseType != "code" {
+		return "", protocolError(
+			http.StatusBadRequest,
+			"unsupported_response_type",
+			"response_type must be code",
+		)
+	}
+	redirect, err := url.Parse(req.RedirectURI)
+	if req.RedirectURI == "" || err != nil || !redirect.IsAbs() {
+		return "", protocolError(
+			http.StatusBadRequest,
+			"invalid_request",
+			"redirect_uri must be an absolute URI",
+		)
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return "", protocolError(
+			http.StatusBadRequest,
+			"invalid_request",
+			"a S256 code_challenge is required",
+		)
+	}
+	if req.IssuerState != "" && req.IssuerState != s.Secrets.IssuerState {
+		return "", protocolError(http.StatusBadRequest, "invalid_request", "unknown issuer_state")
+	}
+
+	s.Authorized = true
+	s.CodeChallenge = req.CodeChallenge
+	s.RedirectURI = req.RedirectURI
+	query := redirect.Query()
+	query.Set("code", s.Secrets.AuthorizationCode)
+	query.Set("iss", s.IssuerURL)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	return redirect.String(), nil
+}
+
+// TokenRequest holds the parameters of a token request.
+type TokenRequest struct {
+	GrantType         string `json:"grant_type"`
+	PreAuthorizedCode string `json:"pre-authorized_code,omitempty"`
+	TxCode            string `json:"tx_code,omitempty"`
+	Code              string `json:"code,omitempty"`
+	CodeVerifier      string `json:"code_verifier,omitempty"`
+	RedirectURI       string `json:"redirect_uri,omitempty"`
+}
+
+// TokenResponse is a successful token response.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Token exchanges a pre-authorized or authorization code for the access
+// token. Codes are single use.
+func (s *IssuerSession) Token(req TokenRequest) (TokenResponse, *ProtocolError) {
+	if s.TokenIssued {
+		return TokenResponse{}, protocolError(
+			http.StatusBadRequest,
+			"invalid_grant",
+			"the code was already used",
+		)
+	}
+	switch req.GrantType {
+	case GrantPreAuthorizedCode:
+		if s.flow() != FlowPreAuthorizedCode {
+			return TokenResponse{}, unsupportedGrant(req.GrantType)
+		}
+		if req.PreAuthorizedCode != s.Secrets.PreAuthorizedCode {
+			return TokenResponse{}, protocolError(
+				http.StatusBadRequest,
+				"invalid_grant",
+				"unknown pre-authorized_code",
+			)
+		}
+		if s.Config.TxCode != "" && req.TxCode != s.Config.TxCode {
+			return TokenResponse{}, protocolError(
+				http.StatusBadRequest,
+				"invalid_grant",
+				"invalid tx_code",
+			)
+		}
+	case GrantAuthorizationCode:
+		if s.flow() != FlowAuthorizationCode {
+			return TokenResponse{}, unsupportedGrant(req.GrantType)
+		}
+		if !s.Authorized || req.Code != s.Secrets.AuthorizationCode {
+			return TokenResponse{}, protocolError(
+				http.StatusBadRequest,
+				"invalid_grant",
+				"unknown authorization code",
+			)
+		}
+		if req.RedirectURI != s.RedirectURI {
+			return TokenResponse{}, protocolError(
+				http.StatusBadRequest,
+				"invalid_grant",
+				"redirect_uri does not match the authorization request",
+			)
+		}
+		if base64URLHash(req.CodeVerifier) != s.CodeChallenge {
+			return TokenResponse{}, protocolError(
+				http.StatusBadRequest,
+				"invalid_grant",
+				"code_verifier does not match the code_challenge",
+			)
+		}
+	default:
+		return TokenResponse{}, unsupportedGrant(req.GrantType)
+	}
+
+	s.TokenIssued = true
+	return TokenResponse{
+		AccessToken: s.Secrets.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(AccessTokenLifetime / time.Second),
+	}, nil
+}
+
+func unsupportedGrant(grantType string) *ProtocolError {
+	return protocolError(
+		http.StatusBadRequest,
+		"unsupported_grant_type",
+		"grant_type %q is not supported by the session",
+		grantType,
+	)
+}
+
+// CredentialRequest holds the parameters of a credential request.
+type CredentialRequest struct {
+	AccessToken             string   `json:"access_token"`
+	CredentialConfiguration string   `json:"credential_configuration_id"`
+	Proofs                  []string `json:"proofs"`
+}
+
+// ParseCredentialRequest reads the JSON body of a credential request.
+func ParseCredentialRequest(accessToken string, body map[string]any) CredentialRequest {
+	req := CredentialRequest{AccessToken: accessToken}
+	req.CredentialConfiguration, _ = body["credential_configuration_id"].(string)
+	if identifier, ok := body["credential_identifier"].(string); ok && identifier != "" {
+		req.CredentialConfiguration = identifier
+	}
+	if proof, ok := body["proof"].(map[string]any); ok {
+		if jwt, ok := proof["jwt"].(string); ok && proof["proof_type"] == "jwt" {
+			req.Proofs = append(req.Proofs, jwt)
+		}
+	}
+	if proofs, ok := body["proofs"].(map[string]any); ok {
+		list, _ := proofs["jwt"].([]any)
+		for _, item := range list {
+			if jwt, ok := item.(string); ok {
+				req.Proofs = append(req.Proofs, jwt)
+			}
+		}
+	}
+	return req
+}
+
+// CheckCredentialRequest validates the access token and the requested
+// configuration before any credential is signed.
+func (s *IssuerSession) CheckCredentialRequest(req CredentialRequest) *ProtocolError {
+	if !s.TokenIssued || req.AccessToken != s.Secrets.AccessToken {
+		return protocolError(http.StatusUnauthorized, "invalid_token", "unknown access token")
+	}
+	if req.CredentialConfiguration != s.Config.Configuration {
+		return protocolError(
+			http.StatusBadRequest,
+			"unknown_credential_configuration",
+			"the session offers %s",
+			s.Config.Configuration,
+		)
+	}
+	if len(req.Proofs) == 0 {
+		return protocolError(http.StatusBadRequest, "invalid_proof", "a jwt proof is required")
+	}
+	return nil
+}
+
+// IssueCredentials verifies the key proofs of a credential request and
+// issues one credential per proof.
+func (k *Keys) IssueCredentials(
+	session IssuerSession,
+	proofs []string,
+	now time.Time,
+) ([]string, *ProtocolError) {
+	configuration, err := LookupConfiguration(session.Config.Configuration)
+	if err != nil {
+		return nil, protocolError(
+			http.StatusBadRequest,
+			"unknown_credential_configuration",
+			"%s",
+			err.Error(),
+		)
+	}
+	claims := configuration.ClaimValues(session.Config.Claims)
+	credentials := make([]string, 0, len(proofs))
+	for i, proof := range proofs {
+		holder, err := VerifyProof(proof, session.IssuerURL, session.Secrets.Nonce, now)
+		if err != nil {
+			return nil, protocolError(
+				http.StatusBadRequest,
+				"invalid_proof",
+				"proof %d: %s",
+				i+1,
+				err.Error(),
+			)
+		}
+		var credential string
+		if configuration.Format == FormatMDoc {
+			credential, err = k.IssueMDoc(session.IssuerURL, configuration, claims, holder, now)
+		} else {
+			credential, err = k.IssueSDJWT(session.IssuerURL, configuration, claims, holder, now)
+		}
+		if err != nil {
+			return nil, protocolError(
+				http.StatusBadRequest,
+				"invalid_proof",
+				"proof %d: %s",
+				i+1,
+				err.Error(),
+			)
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, nil
+}
+
+// VerifyProof checks a jwt key proof for audience and nonce and returns the
+// holder key it carries.
+func VerifyProof(raw, audience, nonce string, now time.Time) (crypto.PublicKey, error) {
+	token, err := parseJWS(raw)
+	if err != nil {
+		return nil, err
+	}
+	if typ := token.headerString("typ"); typ != typProof {
+		return nil, fmt.Errorf("unexpected typ %q", typ)
+	}
+	jwk, ok := token.Header["jwk"]
+	if !ok {
+		return nil, errors.New("only proofs with a jwk header are supported")
+	}
+	holder, err := parsePublicJWK(jwk)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.verify(holder); err != nil {
+		return nil, err
+	}
+	if aud := token.claimString("aud"); aud != audience {
+		return nil, fmt.Errorf("aud %q does not match %q", aud, audience)
+	}
+	if got := token.claimString("nonce"); got != nonce {
+		return nil, errors.New("nonce does not match the c_nonce")
+	}
+	iat, ok := token.Payload["iat"].(float64)
+	if !ok {
+		return nil, errors.New("iat is required")
+	}
+	if issuedAt := time.Unix(int64(iat), 0); issuedAt.After(now.Add(maxClockSkew)) {
+		return nil, errors.New("iat is in the future")
+	}
+	return holder, nil
+}
+
+// SignProof creates a jwt key proof for holder.
+func SignProof(holder crypto.Signer, audience, nonce string, now time.Time) (string, error) {
+	jwk, err := publicJWK(holder.Public())
+	if err != nil {
+		return "", err
+	}
+	return signJWS(
+		map[string]any{"typ": typProof, "jwk": jwk},
+		map[string]any{"aud": audience, "nonce": nonce, "iat": now.Unix()},
+		holder,
+	)
+}
+
+// hostOf returns the host name of rawURL without port.
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
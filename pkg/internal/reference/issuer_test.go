@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuerURL = "https://credimi.example/api/reference/org/issuer/session1"
+
+func testIssuerSession(t *testing.T, config IssuerConfig) *IssuerSession {
+	t.Helper()
+	secrets, err := NewIssuerSecrets()
+	require.NoError(t, err)
+	return &IssuerSession{IssuerURL: testIssuerURL, Config: config, Secrets: secrets}
+}
+
+func testHolder(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	holder, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return holder
+}
+
+func TestIssuerPreAuthorizedFlow(t *testing.T) {
+	session := testIssuerSession(t, IssuerConfig{Configuration: ConfigurationPID, TxCode: "1234"})
+
+	deeplink, err := session.OfferDeeplink()
+	require.NoError(t, err)
+	parsed, err := url.Parse(deeplink)
+	require.NoError(t, err)
+	require.Equal(t, "openid-credential-offer", parsed.Scheme)
+	var offer map[string]any
+	require.NoError(t, json.Unmarshal([]byte(parsed.Query().Get("credential_offer")), &offer))
+	require.Equal(t, testIssuerURL, offer["credential_issuer"])
+	grant := offer["grants"].(map[string]any)[GrantPreAuthorizedCode].(map[string]any)
+	require.Equal(t, session.Secrets.PreAuthorizedCode, grant["pre-authorized_code"])
+	require.Equal(t, float64(4), grant["tx_code"].(map[string]any)["length"])
+
+	_, protoErr := session.Token(TokenRequest{
+		GrantType:         GrantPreAuthorizedCode,
+		PreAuthorizedCode: session.Secrets.PreAuthorizedCode,
+		TxCode:            "0000",
+	})
+	require.NotNil(t, protoErr)
+	require.Equal(t, "invalid_grant", protoErr.Code)
+
+	_, protoErr = session.Token(TokenRequest{GrantType: GrantAuthorizationCode})
+	require.Equal(t, "unsupported_grant_type", protoErr.Code)
+
+	token, protoErr := session.Token(TokenRequest{
+		GrantType:         GrantPreAuthorizedCode,
+		PreAuthorizedCode: session.Secrets.PreAuthorizedCode,
+		TxCode:            "1234",
+	})
+	require.Nil(t, protoErr)
+	require.Equal(t, session.Secrets.AccessToken, token.AccessToken)
+	require.Equal(t, "Bearer", token.TokenType)
+
+	_, protoErr = session.Token(TokenRequest{
+		GrantType:         GrantPreAuthorizedCode,
+		PreAuthorizedCode: session.Secrets.PreAuthorizedCode,
+		TxCode:            "1234",
+	})
+	require.Equal(t, "invalid_grant", protoErr.Code)
+}
+
+func TestIssuerAuthorizationCodeFlow(t *testing.T) {
+	session := testIssuerSession(t, IssuerConfig{
+		Configuration: ConfigurationMDL,
+		Flow:          FlowAuthorizationCode,
+	})
+	grants := session.Offer()["grants"].(map[string]any)
+	require.Contains(t, grants, GrantAuthorizationCode)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := base64URLHash(verifier)
+	request := AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "wallet",
+		RedirectURI:         "https://wallet.example/cb?x=1",
+		State:               "xyz",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "S256",
+		IssuerState:         session.Secrets.IssuerState,
+	}
+
+	plain := request
+	plain.CodeChallengeMethod = "plain"
+	_, protoErr := session.Authorize(plain)
+	require.Equal(t, "invalid_request", protoErr.Code)
+
+	_, protoErr = session.Token(TokenRequest{
+		GrantType: GrantAuthorizationCode,
+		Code:      session.Secrets.AuthorizationCode,
+	})
+	require.Equal(t, "invalid_grant", protoErr.Code)
+
+	location, protoErr := session.Authorize(request)
+	require.Nil(t, protoErr)
+	redirect, err := url.Parse(location)
+	require.NoError(t, err)
+	require.Equal(t, "wallet.example", redirect.Host)
+	require.Equal(t, "1", redirect.Query().Get("x"))
+	require.Equal(t, "xyz", redirect.Query().Get("state"))
+	require.Equal(t, testIssuerURL, redirect.Query().Get("iss"))
+	require.Equal(t, session.Secrets.AuthorizationCode, redirect.Query().Get("code"))
+
+	_, protoErr = session.Token(TokenRequest{
+		GrantType:    GrantAuthorizationCode,
+		Code:         session.Secrets.AuthorizationCode,
+		CodeVerifier: "wrong",
+		RedirectURI:  request.RedirectURI,
+	})
+	require.Equal(t, "invalid_grant", protoErr.Code)
+
+	token, protoErr := session.Token(TokenRequest{
+		GrantType:    GrantAuthorizationCode,
+		Code:         session.Secrets.AuthorizationCode,
+		CodeVerifier: verifier,
+		RedirectURI:  request.RedirectURI,
+	})
+	require.Nil(t, protoErr)
+	require.Equal(t, session.Secrets.AccessToken, token.AccessToken)
+}
+
+func TestIssuerRejectsAuthorizeForPreAuthorizedSessions(t *testing.T) {
+	session := testIssuerSession(t, IssuerConfig{Configuration: ConfigurationPID})
+	_, protoErr := session.Authorize(AuthorizeRequest{ResponseType: "code"})
+	require.Equal(t, "unauthorized_client", protoErr.Code)
+}
+
+func TestIssuerMetadata(t *testing.T) {
+	session := testIssuerSession(t, IssuerConfig{Configuration: ConfigurationMDL})
+	metadata, err := session.CredentialIssuerMetadata()
+	require.NoError(t, err)
+	require.Equal(t, testIssuerURL+"/credential", metadata["credential_endpoint"])
+	require.Equal(t, testIssuerURL+"/nonce", metadata["nonce_endpoint"])
+	configurations := metadata["credential_configurations_supported"].(map[string]any)
+	mdl := configurations[ConfigurationMDL].(map[string]any)
+	require.Equal(t, FormatMDoc, mdl["format"])
+	require.Equal(t, "org.iso.18013.5.1.mDL", mdl["doctype"])
+
+	server := session.AuthorizationServerMetadata()
+	require.Equal(t, testIssuerURL+"/token", server["token_endpoint"])
+	require.Equal(t, []string{"S256"}, server["code_challenge_methods_supported"])
+}
+
+func TestParseCredentialRequest(t *testing.T) {
+	req := ParseCredentialRequest("token", map[string]any{
+		"credential_configuration_id": ConfigurationPID,
+		"proof":                       map[string]any{"proof_type": "jwt", "jwt": "a"},
+		"proofs":                      map[string]any{"jwt": []any{"b", "c"}},
+	})
+	require.Equal(t, CredentialRequest{
+		AccessToken:             "token",
+		CredentialConfiguration: ConfigurationPID,
+		Proofs:                  []string{"a", "b", "c"},
+	}, req)
+}
+
+func TestIssueCredentials(t *testing.T) {
+	keys := testKeys(t)
+	holder := testHolder(t)
+	now := time.Now()
+
+	for _, configuration := range []string{ConfigurationPID, ConfigurationMDL} {
+		t.Run(configuration, func(t *testing.T) {
+			session := testIssuerSession(t, IssuerConfig{
+				Configuration: configuration,
+				Claims:        map[string]any{"given_name": "Mario"},
+			})
+			req := CredentialRequest{
+				AccessToken:             session.Secrets.AccessToken,
+				CredentialConfiguration: configuration,
+			}
+			require.Equal(t, "invalid_token", session.CheckCredentialRequest(req).Code)
+
+			_, protoErr := session.Token(TokenRequest{
+				GrantType:         GrantPreAuthorizedCode,
+				PreAuthorizedCode: session.Secrets.PreAuthorizedCode,
+			})
+			require.Nil(t, protoErr)
+			require.Equal(t, "invalid_proof", session.CheckCredentialRequest(req).Code)
+
+			proof, err := SignProof(holder, testIssuerURL, session.Secrets.Nonce, now)
+			require.NoError(t, err)
+			req.Proofs = []string{proof}
+			require.Nil(t, session.CheckCredentialRequest(req))
+
+			credentials, protoErr := keys.IssueCredentials(*session, req.Proofs, now)
+			require.Nil(t, protoErr)
+			require.Len(t, credentials, 1)
+
+			staleProof, err := SignProof(holder, testIssuerURL, "stale", now)
+			require.NoError(t, err)
+			_, protoErr = keys.IssueCredentials(*session, []string{staleProof}, now)
+			require.Equal(t, "invalid_proof", protoErr.Code)
+			require.Equal(t, http.StatusBadRequest, protoErr.Status)
+		})
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	holder := testHolder(t)
+	now := time.Now()
+
+	proof, err := SignProof(holder, testIssuerURL, "nonce", now)
+	require.NoError(t, err)
+	key, err := VerifyProof(proof, testIssuerURL, "nonce", now)
+	require.NoError(t, err)
+	require.True(t, holder.PublicKey.Equal(key))
+
+	_, err = VerifyProof(proof, "https://other.example", "nonce", now)
+	require.ErrorContains(t, err, "aud")
+
+	future, err := SignProof(holder, testIssuerURL, "nonce", now.Add(time.Hour))
+	require.NoError(t, err)
+	_, err = VerifyProof(future, testIssuerURL, "nonce", now)
+	require.ErrorContains(t, err, "future")
+
+	kb, err := signJWS(map[string]any{"typ": typKeyBinding}, map[string]any{}, holder)
+	require.NoError(t, err)
+	_, err = VerifyProof(kb, testIssuerURL, "nonce", now)
+	require.ErrorContains(t, err, "typ")
+}
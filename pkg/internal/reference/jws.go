@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+)
+
+// compactJWS is a parsed JWS in compact serialization.
+type compactJWS struct {
+	Header       map[string]any
+	Payload      map[string]any
+	signingInput string
+	signature    []byte
+}
+
+// signJWS signs payload with key, adding alg to header.
+func signJWS(header, payload map[string]any, key crypto.Signer) (string, error) {
+	header["alg"] = Algorithm
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." +
+		base64.RawURLEncoding.EncodeToString(encodedPayload)
+	signature, err := cose.SignData(Algorithm, key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parseJWS(raw string) (*compactJWS, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("JWS must have three parts")
+	}
+	token := &compactJWS{signingInput: parts[0] + "." + parts[1]}
+	for i, target := range []*map[string]any{&token.Header, &token.Payload} {
+		decoded, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("JWS part %d: %w", i+1, err)
+		}
+		if err := json.Unmarshal(decoded, target); err != nil {
+			return nil, fmt.Errorf("JWS part %d: %w", i+1, err)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("JWS signature: %w", err)
+	}
+	token.signature = signature
+	return token, nil
+}
+
+func (j *compactJWS) headerString(name string) string {
+	value, _ := j.Header[name].(string)
+	return value
+}
+
+func (j *compactJWS) claimString(name string) string {
+	value, _ := j.Payload[name].(string)
+	return value
+}
+
+// verify checks the signature with key using the alg of the header.
+func (j *compactJWS) verify(key crypto.PublicKey) error {
+	algorithm := j.headerString("alg")
+	if algorithm == "" || algorithm == "none" {
+		return errors.New("JWS is not signed")
+	}
+	return cose.VerifySignature(algorithm, key, []byte(j.signingInput), j.signature)
+}
+
+// x5c returns the certificates of the x5c header, leaf first.
+func (j *compactJWS) x5c() ([]*x509.Certificate, error) {
+	values, ok := j.Header["x5c"].([]any)
+	if !ok || len(values) == 0 {
+		return nil, errors.New("JWS has no x5c header")
+	}
+	certificates := make([]*x509.Certificate, 0, len(values))
+	for _, value := range values {
+		encoded, _ := value.(string)
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("x5c: %w", err)
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
+
+func x5cHeader(certificates ...*x509.Certificate) []string {
+	chain := make([]string, 0, len(certificates))
+	for _, certificate := range certificates {
+		chain = append(chain, base64.StdEncoding.EncodeToString(certificate.Raw))
+	}
+	return chain
+}
+
+// publicJWK encodes a public key as a JWK object.
+func publicJWK(key crypto.PublicKey) (map[string]any, error) {
+	jwk, err := federation.NewJWK("", key)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	return object, json.Unmarshal(encoded, &object)
+}
+
+// parsePublicJWK decodes a JWK object, rejecting private keys.
+func parsePublicJWK(value any) (crypto.PublicKey, error) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("jwk must be an object")
+	}
+	if _, ok := object["d"]; ok {
+		return nil, errors.New("jwk contains private key material")
+	}
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var jwk federation.JWK
+	if err := json.Unmarshal(encoded, &jwk); err != nil {
+		return nil, fmt.Errorf("invalid jwk: %w", err)
+	}
+	return jwk.PublicKey()
+}
+
+func base64URLHash(data string) string {
+	digest := sha256.Sum256([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package reference implements the protocol logic of the built-in OpenID4VCI
+// issuer and OpenID4VP verifier that wallet pipelines can target instead of
+// third-party services.
+package reference
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// SeedEnv names the environment variable the reference keys are derived
+// from. Keys stay stable across restarts as long as the seed does.
+const SeedEnv = "REFERENCE_SERVICE_SEED"
+
+const defaultSeed = "credimi-reference-service"
+
+// Signing algorithm of issued credentials, proofs and request objects.
+const Algorithm = "ES256"
+
+// Certificates are valid over a fixed window so that they are byte for byte
+// reproducible from the seed.
+var (
+	certificateNotBefore = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	certificateNotAfter  = time.Date(2036, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Keys holds the key material of the reference services. The root CA signs
+// the issuer and verifier certificates with Ed25519, whose deterministic
+// signatures keep the whole chain reproducible.
+type Keys struct {
+	root     ed25519.PrivateKey
+	rootCert *x509.Certificate
+	issuer   *ecdsa.PrivateKey
+	verifier *ecdsa.PrivateKey
+}
+
+// LoadKeys derives the keys from the seed in SeedEnv.
+func LoadKeys() (*Keys, error) {
+	seed := os.Getenv(SeedEnv)
+	if seed == "" {
+		seed = defaultSeed
+	}
+	return NewKeys(seed)
+}
+
+// NewKeys derives the reference keys from seed.
+func NewKeys(seed string) (*Keys, error) {
+	rootSeed := deriveSecret(seed, "root")
+	root := ed25519.NewKeyFromSeed(rootSeed[:])
+	issuer, err := deriveECKey(seed, "issuer")
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := deriveECKey(seed, "verifier")
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Credimi Reference CA"},
+		NotBefore:             certificateNotBefore,
+		NotAfter:              certificateNotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(nil, template, template, root.Public(), root)
+	if err != nil {
+		return nil, fmt.Errorf("root certificate: %w", err)
+	}
+	rootCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Keys{root: root, rootCert: rootCert, issuer: issuer, verifier: verifier}, nil
+}
+
+func deriveSecret(seed, label string) [32]byte {
+	return sha256.Sum256([]byte(seed + "|" + label))
+}
+
+func deriveECKey(seed, label string) (*ecdsa.PrivateKey, error) {
+	for counter := 0; ; counter++ {
+		secret := deriveSecret(seed, fmt.Sprintf("%s|%d", label, counter))
+		key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), secret[:])
+		if err == nil {
+			return key, nil
+		}
+		if counter > 8 {
+			return nil, fmt.Errorf("derive %s key: %w", label, err)
+		}
+	}
+}
+
+// RootCertificate is the trust anchor of every reference certificate.
+func (k *Keys) RootCertificate() *x509.Certificate {
+	return k.rootCert
+}
+
+// RootPEM returns the trust anchor PEM encoded, for wallets to import.
+func (k *Keys) RootPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: k.rootCert.Raw})
+}
+
+// IssuerKey signs credentials.
+func (k *Keys) IssuerKey() crypto.Signer {
+	return k.issuer
+}
+
+// VerifierKey signs request objects.
+func (k *Keys) VerifierKey() crypto.Signer {
+	return k.verifier
+}
+
+// IssuerCertificate returns the certificate of the issuer key for host.
+func (k *Keys) IssuerCertificate(host string) (*x509.Certificate, error) {
+	return k.leaf(2, "Credimi Reference Issuer", host, &k.issuer.PublicKey)
+}
+
+// VerifierCertificate returns the certificate of the verifier key for host,
+// which is also its x509_san_dns client identifier.
+func (k *Keys) VerifierCertificate(host string) (*x509.Certificate, error) {
+	return k.leaf(3, "Credimi Reference Verifier", host, &k.verifier.PublicKey)
+}
+
+func (k *Keys) leaf(
+	serial int64,
+	name string,
+	host string,
+	public crypto.PublicKey,
+) (*x509.Certificate, error) {
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(serial),
+		Subject:        pkix.Name{CommonName: name},
+		NotBefore:      certificateNotBefore,
+		NotAfter:       certificateNotAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		AuthorityKeyId: k.rootCert.SubjectKeyId,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else if host != "" {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(nil, template, k.rootCert, public, k.root)
+	if err != nil {
+		return nil, fmt.Errorf("%s certificate: %w", name, err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// Trusted reports whether certificate chains to the reference root.
+func (k *Keys) Trusted(certificate *x509.Certificate) bool {
+	roots := x509.NewCertPool()
+	roots.AddCert(k.rootCert)
+	_, err := certificate.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKeys(t *testing.T) *Keys {
+	t.Helper()
+	keys, err := NewKeys("test-seed")
+	require.NoError(t, err)
+	return keys
+}
+
+func TestKeysAreDeterministic(t *testing.T) {
+	first := testKeys(t)
+	second := testKeys(t)
+
+	require.Equal(t, first.RootPEM(), second.RootPEM())
+	require.True(t, first.issuer.Equal(second.issuer))
+	require.True(t, first.verifier.Equal(second.verifier))
+
+	firstLeaf, err := first.VerifierCertificate("credimi.example")
+	require.NoError(t, err)
+	secondLeaf, err := second.VerifierCertificate("credimi.example")
+	require.NoError(t, err)
+	require.Equal(t, firstLeaf.Raw, secondLeaf.Raw)
+
+	other, err := NewKeys("other-seed")
+	require.NoError(t, err)
+	require.False(t, first.issuer.Equal(other.issuer))
+	require.NotEqual(t, first.RootPEM(), other.RootPEM())
+}
+
+func TestLoadKeysUsesSeedEnv(t *testing.T) {
+	t.Setenv(SeedEnv, "test-seed")
+	keys, err := LoadKeys()
+	require.NoError(t, err)
+	require.Equal(t, testKeys(t).RootPEM(), keys.RootPEM())
+}
+
+func TestCertificatesChainToRoot(t *testing.T) {
+	keys := testKeys(t)
+
+	issuer, err := keys.IssuerCertificate("credimi.example")
+	require.NoError(t, err)
+	require.Equal(t, []string{"credimi.example"}, issuer.DNSNames)
+	require.True(t, keys.Trusted(issuer))
+
+	verifier, err := keys.VerifierCertificate("127.0.0.1")
+	require.NoError(t, err)
+	require.Empty(t, verifier.DNSNames)
+	require.Len(t, verifier.IPAddresses, 1)
+	require.True(t, keys.Trusted(verifier))
+
+	foreignKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "foreign"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &foreignKey.PublicKey, foreignKey)
+	require.NoError(t, err)
+	foreign, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	require.False(t, keys.Trusted(foreign))
+}
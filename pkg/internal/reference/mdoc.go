@@ -0,0 +1,497 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+)
+
+// COSE_Key labels and values (RFC 9053) of EC2 keys.
+const (
+	coseKeyKty    = 1
+	coseKeyCrv    = -1
+	coseKeyX      = -2
+	coseKeyY      = -3
+	coseKeyTypeEC = 2
+)
+
+var coseCurves = map[int64]elliptic.Curve{
+	1: elliptic.P256(),
+	2: elliptic.P384(),
+	3: elliptic.P521(),
+}
+
+// IssueMDoc issues the IssuerSigned structure of an mdoc bound to holder,
+// encoded as base64url CBOR as returned by an OpenID4VCI credential endpoint.
+func (k *Keys) IssueMDoc(
+	issuerURL string,
+	configuration CredentialConfiguration,
+	claims map[string]any,
+	holder crypto.PublicKey,
+	now time.Time,
+) (string, error) {
+	certificate, err := k.IssuerCertificate(hostOf(issuerURL))
+	if err != nil {
+		return "", err
+	}
+	deviceKey, err := coseKey(holder)
+	if err != nil {
+		return "", fmt.Errorf("holder key: %w", err)
+	}
+
+	items := make([]any, 0, len(claims))
+	digests := map[any]any{}
+	for id, name := range slices.Sorted(maps.Keys(claims)) {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+		value := claims[name]
+		if date, ok := value.(string); ok && slices.Contains(configuration.DateClaims, name) {
+			value = cbor.Tag{Number: cbor.TagFullDate, Content: date}
+		}
+		encoded, err := cbor.Encode(map[any]any{
+			"digestID":          int64(id),
+			"random":            random,
+			"elementIdentifier": name,
+			"elementValue":      value,
+		})
+		if err != nil {
+			return "", fmt.Errorf("claim %s: %w", name, err)
+		}
+		item := cbor.Tag{Number: cbor.TagEncodedCBOR, Content: encoded}
+		digest, err := itemDigest(item)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+		digests[int64(id)] = digest
+	}
+
+	tdate := func(t time.Time) cbor.Tag {
+		return cbor.Tag{Number: cbor.TagDateTime, Content: t.UTC().Format(time.RFC3339)}
+	}
+	mso, err := cbor.Encode(map[any]any{
+		"version":         "1.0",
+		"digestAlgorithm": "SHA-256",
+		"valueDigests":    map[any]any{configuration.Namespace: digests},
+		"deviceKeyInfo":   map[any]any{"deviceKey": deviceKey},
+		"docType":         configuration.DocType,
+		"validityInfo": map[any]any{
+			"signed":     tdate(now),
+			"validFrom":  tdate(now),
+			"validUntil": tdate(now.Add(credentialValidity)),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := cbor.Encode(cbor.Tag{Number: cbor.TagEncodedCBOR, Content: mso})
+	if err != nil {
+		return "", err
+	}
+	issuerAuth, err := cose.Sign(
+		Algorithm,
+		k.IssuerKey(),
+		nil,
+		map[any]any{int64(cose.HeaderX5Chain): certificate.Raw},
+		payload,
+	)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := cbor.Encode(map[any]any{
+		"nameSpaces": map[any]any{configuration.Namespace: items},
+		"issuerAuth": issuerAuth.Array(false),
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// SessionTranscript is the OpenID4VP SessionTranscript of an unencrypted
+// redirect-based presentation.
+func SessionTranscript(clientID, nonce, responseURI string) (any, error) {
+	info, err := cbor.Encode([]any{clientID, nonce, nil, responseURI})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(info)
+	return []any{nil, nil, []any{"OpenID4VPHandover", digest[:]}}, nil
+}
+
+// VerifyMDoc verifies the documents of a base64url DeviceResponse against
+// the session transcript.
+func (k *Keys) VerifyMDoc(
+	presentation string,
+	transcript any,
+	now time.Time,
+) ([]VerifiedCredential, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(presentation)
+	if err != nil {
+		return nil, fmt.Errorf("device response: %w", err)
+	}
+	item, err := cbor.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("device response: %w", err)
+	}
+	response, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("device response must be a map")
+	}
+	if status, ok := cbor.Lookup(response, "status"); ok {
+		if code, _ := cbor.Int(status); code != 0 {
+			return nil, fmt.Errorf("device response has status %d", code)
+		}
+	}
+	documents, _ := response["documents"].([]any)
+	if len(documents) == 0 {
+		return nil, errors.New("device response has no documents")
+	}
+	verified := make([]VerifiedCredential, 0, len(documents))
+	for i, document := range documents {
+		credential, err := k.verifyDocument(document, transcript, now)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		verified = append(verified, credential)
+	}
+	return verified, nil
+}
+
+func (k *Keys) verifyDocument(
+	item any,
+	transcript any,
+	now time.Time,
+) (VerifiedCredential, error) {
+	document, ok := item.(map[any]any)
+	if !ok {
+		return VerifiedCredential{}, errors.New("document must be a map")
+	}
+	docType, _ := document["docType"].(string)
+	issuerSigned, _ := document["issuerSigned"].(map[any]any)
+	if issuerSigned == nil {
+		return VerifiedCredential{}, errors.New("document has no issuerSigned")
+	}
+
+	issuerAuth, err := cose.FromItem(issuerSigned["issuerAuth"])
+	if err != nil {
+		return VerifiedCredential{}, fmt.Errorf("issuerAuth: %w", err)
+	}
+	chain, err := issuerAuth.X5Chain()
+	if err != nil {
+		return VerifiedCredential{}, fmt.Errorf("issuerAuth: %w", err)
+	}
+	if len(chain) == 0 {
+		return VerifiedCredential{}, errors.New("issuerAuth has no x5chain")
+	}
+	if err := issuerAuth.Verify(chain[0].PublicKey); err != nil {
+		return VerifiedCredential{}, fmt.Errorf("issuerAuth: %w", err)
+	}
+	mso, err := decodeMSO(issuerAuth.Payload)
+	if err != nil {
+		return VerifiedCredential{}, err
+	}
+	if msoDocType, _ := mso["docType"].(string); msoDocType != docType {
+		return VerifiedCredential{}, fmt.Errorf(
+			"docType %q does not match the MSO docType %q",
+			docType,
+			msoDocType,
+		)
+	}
+	if err := checkValidity(mso, now); err != nil {
+		return VerifiedCredential{}, err
+	}
+
+	claims, err := verifyNamespaces(issuerSigned["nameSpaces"], mso)
+	if err != nil {
+		return VerifiedCredential{}, err
+	}
+	if err := verifyDeviceSignature(document, mso, docType, transcript); err != nil {
+		return VerifiedCredential{}, err
+	}
+	return VerifiedCredential{
+		Format:  FormatMDoc,
+		Type:    docType,
+		Trusted: k.Trusted(chain[0]),
+		Claims:  claims,
+	}, nil
+}
+
+func decodeMSO(payload []byte) (map[any]any, error) {
+	item, err := cbor.Decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("MSO: %w", err)
+	}
+	if tag, ok := item.(cbor.Tag); ok && tag.Number == cbor.TagEncodedCBOR {
+		encoded, _ := tag.Content.([]byte)
+		if item, err = cbor.Decode(encoded); err != nil {
+			return nil, fmt.Errorf("MSO: %w", err)
+		}
+	}
+	mso, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("MSO must be a map")
+	}
+	if algorithm, _ := mso["digestAlgorithm"].(string); algorithm != "SHA-256" {
+		return nil, fmt.Errorf("unsupported MSO digest algorithm %q", algorithm)
+	}
+	return mso, nil
+}
+
+func checkValidity(mso map[any]any, now time.Time) error {
+	validity, _ := mso["validityInfo"].(map[any]any)
+	for name, check := range map[string]func(time.Time) bool{
+		"validFrom":  func(t time.Time) bool { return !now.Add(maxClockSkew).Before(t) },
+		"validUntil": func(t time.Time) bool { return now.Before(t) },
+	} {
+		tag, _ := validity[name].(cbor.Tag)
+		value, _ := tag.Content.(string)
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("MSO %s: %w", name, err)
+		}
+		if !check(parsed) {
+			return fmt.Errorf("MSO is not valid, %s is %s", name, value)
+		}
+	}
+	return nil
+}
+
+// verifyNamespaces checks the digest of every IssuerSignedItem and returns
+// the element values grouped by namespace.
+func verifyNamespaces(item any, mso map[any]any) (map[string]any, error) {
+	namespaces, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("issuerSigned nameSpaces must be a map")
+	}
+	valueDigests, _ := mso["valueDigests"].(map[any]any)
+	claims := map[string]any{}
+	for key, value := range namespaces {
+		namespace, _ := key.(string)
+		digests, _ := valueDigests[namespace].(map[any]any)
+		items, _ := value.([]any)
+		elements := map[string]any{}
+		for _, signedItem := range items {
+			tag, ok := signedItem.(cbor.Tag)
+			encoded, isBytes := tag.Content.([]byte)
+			if !ok || !isBytes || tag.Number != cbor.TagEncodedCBOR {
+				return nil, fmt.Errorf("namespace %s has an untagged item", namespace)
+			}
+			decoded, err := cbor.Decode(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+			}
+			element, _ := decoded.(map[any]any)
+			id, _ := cbor.Int(element["digestID"])
+			name, _ := element["elementIdentifier"].(string)
+			digest, err := itemDigest(tag)
+			if err != nil {
+				return nil, err
+			}
+			expected, _ := digests[id].([]byte)
+			if !bytes.Equal(expected, digest) {
+				return nil, fmt.Errorf("digest of %s/%s does not match the MSO", namespace, name)
+			}
+			elements[name] = plainValue(element["elementValue"])
+		}
+		claims[namespace] = elements
+	}
+	return claims, nil
+}
+
+func verifyDeviceSignature(
+	document map[any]any,
+	mso map[any]any,
+	docType string,
+	transcript any,
+) error {
+	deviceSigned, _ := document["deviceSigned"].(map[any]any)
+	if deviceSigned == nil {
+		return errors.New("document has no deviceSigned")
+	}
+	deviceAuth, _ := deviceSigned["deviceAuth"].(map[any]any)
+	signature, ok := deviceAuth["deviceSignature"]
+	if !ok {
+		return errors.New("only deviceSignature device authentication is supported")
+	}
+	nameSpaces, ok := deviceSigned["nameSpaces"].(cbor.Tag)
+	if !ok {
+		return errors.New("deviceSigned nameSpaces must be tagged encoded CBOR")
+	}
+	authentication, err := deviceAuthenticationBytes(transcript, docType, nameSpaces)
+	if err != nil {
+		return err
+	}
+	sign1, err := cose.FromDetachedItem(signature, authentication)
+	if err != nil {
+		return fmt.Errorf("deviceSignature: %w", err)
+	}
+
+	keyInfo, _ := mso["deviceKeyInfo"].(map[any]any)
+	deviceKey, err := parseCOSEKey(keyInfo["deviceKey"])
+	if err != nil {
+		return fmt.Errorf("deviceKey: %w", err)
+	}
+	if err := sign1.Verify(deviceKey); err != nil {
+		return fmt.Errorf("deviceSignature: %w", err)
+	}
+	return nil
+}
+
+func deviceAuthenticationBytes(
+	transcript any,
+	docType string,
+	nameSpaces cbor.Tag,
+) ([]byte, error) {
+	authentication, err := cbor.Encode(
+		[]any{"DeviceAuthentication", transcript, docType, nameSpaces},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Encode(cbor.Tag{Number: cbor.TagEncodedCBOR, Content: authentication})
+}
+
+// PresentMDoc wraps an issued mdoc in a DeviceResponse signed by holder for
+// the session transcript.
+func PresentMDoc(
+	credential string,
+	docType string,
+	holder crypto.Signer,
+	transcript any,
+) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(credential)
+	if err != nil {
+		return "", fmt.Errorf("issuerSigned: %w", err)
+	}
+	issuerSigned, err := cbor.Decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("issuerSigned: %w", err)
+	}
+	emptyNameSpaces, err := cbor.Encode(map[any]any{})
+	if err != nil {
+		return "", err
+	}
+	nameSpaces := cbor.Tag{Number: cbor.TagEncodedCBOR, Content: emptyNameSpaces}
+	authentication, err := deviceAuthenticationBytes(transcript, docType, nameSpaces)
+	if err != nil {
+		return "", err
+	}
+	signature, err := cose.Sign(Algorithm, holder, nil, nil, authentication)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := cbor.Encode(map[any]any{
+		"version": "1.0",
+		"documents": []any{map[any]any{
+			"docType":      docType,
+			"issuerSigned": issuerSigned,
+			"deviceSigned": map[any]any{
+				"nameSpaces": nameSpaces,
+				"deviceAuth": map[any]any{"deviceSignature": signature.Array(true)},
+			},
+		}},
+		"status": int64(0),
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func itemDigest(item cbor.Tag) ([]byte, error) {
+	encoded, err := cbor.Encode(item)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(encoded)
+	return digest[:], nil
+}
+
+// plainValue converts CBOR element values to their JSON counterparts.
+func plainValue(value any) any {
+	switch v := value.(type) {
+	case cbor.Tag:
+		return plainValue(v.Content)
+	case []byte:
+		return base64.RawURLEncoding.EncodeToString(v)
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			values = append(values, plainValue(item))
+		}
+		return values
+	case map[any]any:
+		values := map[string]any{}
+		for key, item := range v {
+			values[fmt.Sprint(key)] = plainValue(item)
+		}
+		return values
+	}
+	return value
+}
+
+func coseKey(key crypto.PublicKey) (map[any]any, error) {
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("mdoc device keys must be EC keys, got %T", key)
+	}
+	for crv, curve := range coseCurves {
+		if curve != pub.Curve {
+			continue
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		return map[any]any{
+			int64(coseKeyKty): int64(coseKeyTypeEC),
+			int64(coseKeyCrv): crv,
+			int64(coseKeyX):   pub.X.FillBytes(make([]byte, size)),
+			int64(coseKeyY):   pub.Y.FillBytes(make([]byte, size)),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+}
+
+func parseCOSEKey(item any) (*ecdsa.PublicKey, error) {
+	key, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("COSE_Key must be a map")
+	}
+	if kty, _ := cbor.Int(key[int64(coseKeyKty)]); kty != coseKeyTypeEC {
+		return nil, fmt.Errorf("unsupported COSE_Key kty %d", kty)
+	}
+	crv, _ := cbor.Int(key[int64(coseKeyCrv)])
+	curve, ok := coseCurves[crv]
+	if !ok {
+		return nil, fmt.Errorf("unsupported COSE_Key curve %d", crv)
+	}
+	x, _ := key[int64(coseKeyX)].([]byte)
+	y, _ := key[int64(coseKeyY)].([]byte)
+	pub := &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if _, err := pub.ECDH(); err != nil {
+		return nil, fmt.Errorf("invalid COSE_Key: %w", err)
+	}
+	return pub, nil
+}
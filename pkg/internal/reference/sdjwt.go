@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SD-JWT media types.
+const (
+	typSDJWT       = "dc+sd-jwt"
+	typSDJWTLegacy = "vc+sd-jwt"
+	typKeyBinding  = "kb+jwt"
+)
+
+// credentialValidity is how long issued credentials remain valid.
+const credentialValidity = 365 * 24 * time.Hour
+
+// maxClockSkew bounds how far in the future iat values may lie.
+const maxClockSkew = 5 * time.Minute
+
+// maxPresentationAge bounds how old key binding JWTs may be.
+const maxPresentationAge = 24 * time.Hour
+
+// VerifiedCredential is a presented credential whose signatures, digests and
+// holder binding were checked.
+type VerifiedCredential struct {
+	Format string `json:"format"`
+	// Type is the vct of SD-JWT VCs or the doctype of mdocs.
+	Type    string `json:"type"`
+	Issuer  string `json:"issuer,omitempty"`
+	Trusted bool   `json:"trusted"`
+	// Claims are the disclosed claims. mdoc claims are grouped by namespace.
+	Claims map[string]any `json:"claims"`
+}
+
+// IssueSDJWT issues an SD-JWT VC bound to holder, with every claim
+// selectively disclosable.
+func (k *Keys) IssueSDJWT(
+	issuerURL string,
+	configuration CredentialConfiguration,
+	claims map[string]any,
+	holder crypto.PublicKey,
+	now time.Time,
+) (string, error) {
+	certificate, err := k.IssuerCertificate(hostOf(issuerURL))
+	if err != nil {
+		return "", err
+	}
+	jwk, err := publicJWK(holder)
+	if err != nil {
+		return "", fmt.Errorf("holder key: %w", err)
+	}
+
+	disclosures := make([]string, 0, len(claims))
+	digests := make([]string, 0, len(claims))
+	for _, name := range slices.Sorted(maps.Keys(claims)) {
+		salt, err := randomString(16)
+		if err != nil {
+			return "", err
+		}
+		encoded, err := json.Marshal([]any{salt, name, claims[name]})
+		if err != nil {
+			return "", fmt.Errorf("claim %s: %w", name, err)
+		}
+		disclosure := base64.RawURLEncoding.EncodeToString(encoded)
+		disclosures = append(disclosures, disclosure)
+		digests = append(digests, base64URLHash(disclosure))
+	}
+	slices.Sort(digests)
+
+	payload := map[string]any{
+		"iss":     issuerURL,
+		"iat":     now.Unix(),
+		"exp":     now.Add(credentialValidity).Unix(),
+		"vct":     configuration.VCT,
+		"cnf":     map[string]any{"jwk": jwk},
+		"_sd_alg": "sha-256",
+		"_sd":     digests,
+	}
+	header := map[string]any{"typ": typSDJWT, "x5c": x5cHeader(certificate)}
+	issued, err := signJWS(header, payload, k.IssuerKey())
+	if err != nil {
+		return "", err
+	}
+	return issued + "~" + strings.Join(disclosures, "~") + "~", nil
+}
+
+// VerifySDJWT verifies an SD-JWT VC presentation with key binding for
+// audience and nonce.
+func (k *Keys) VerifySDJWT(
+	presentation string,
+	audience string,
+	nonce string,
+	now time.Time,
+) (VerifiedCredential, error) {
+	parts := strings.Split(presentation, "~")
+	if len(parts) < 2 {
+		return VerifiedCredential{}, errors.New("SD-JWT has no disclosures separator")
+	}
+	keyBinding := parts[len(parts)-1]
+	if keyBinding == "" {
+		return VerifiedCredential{}, errors.New("SD-JWT presentation has no key binding JWT")
+	}
+
+	issued, err := parseJWS(parts[0])
+	if err != nil {
+		return VerifiedCredential{}, fmt.Errorf("issuer-signed JWT: %w", err)
+	}
+	if typ := issued.headerString("typ"); typ != typSDJWT && typ != typSDJWTLegacy {
+		return VerifiedCredential{}, fmt.Errorf("unexpected SD-JWT typ %q", typ)
+	}
+	chain, err := issued.x5c()
+	if err != nil {
+		return VerifiedCredential{}, err
+	}
+	if err := issued.verify(chain[0].PublicKey); err != nil {
+		return VerifiedCredential{}, fmt.Errorf("issuer signature: %w", err)
+	}
+	if exp, ok := issued.Payload["exp"].(float64); ok && now.Unix() > int64(exp) {
+		return VerifiedCredential{}, errors.New("SD-JWT has expired")
+	}
+	if alg, ok := issued.Payload["_sd_alg"].(string); ok && alg != "sha-256" {
+		return VerifiedCredential{}, fmt.Errorf("unsupported _sd_alg %q", alg)
+	}
+
+	claims, err := discloseClaims(issued.Payload, parts[1:len(parts)-1])
+	if err != nil {
+		return VerifiedCredential{}, err
+	}
+
+	cnf, _ := issued.Payload["cnf"].(map[string]any)
+	holder, err := parsePublicJWK(cnf["jwk"])
+	if err != nil {
+		return VerifiedCredential{}, fmt.Errorf("cnf: %w", err)
+	}
+	signed := strings.TrimSuffix(presentation, keyBinding)
+	if err := verifyKeyBinding(keyBinding, holder, signed, audience, nonce, now); err != nil {
+		return VerifiedCredential{}, err
+	}
+
+	return VerifiedCredential{
+		Format:  FormatSDJWT,
+		Type:    issued.claimString("vct"),
+		Issuer:  issued.claimString("iss"),
+		Trusted: k.Trusted(chain[0]),
+		Claims:  claims,
+	}, nil
+}
+
+// discloseClaims checks that every disclosure is referenced by a digest and
+// returns the plain claims together with the disclosed top-level claims.
+func discloseClaims(payload map[string]any, disclosures []string) (map[string]any, error) {
+	referenced := map[string]bool{}
+	collectDigests(payload, referenced)
+
+	decoded := make([][]any, 0, len(disclosures))
+	for _, disclosure := range disclosures {
+		raw, err := base64.RawURLEncoding.DecodeString(disclosure)
+		if err != nil {
+			return nil, fmt.Errorf("disclosure: %w", err)
+		}
+		var values []any
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, fmt.Errorf("disclosure: %w", err)
+		}
+		if len(values) != 2 && len(values) != 3 {
+			return nil, errors.New("disclosure must have two or three elements")
+		}
+		collectDigests(values[len(values)-1], referenced)
+		decoded = append(decoded, values)
+	}
+
+	topLevel := map[string]bool{}
+	if digests, ok := payload["_sd"].([]any); ok {
+		for _, digest := range digests {
+			if value, ok := digest.(string); ok {
+				topLevel[value] = true
+			}
+		}
+	}
+
+	claims := map[string]any{}
+	for name, value := range payload {
+		if name != "_sd" && name != "_sd_alg" {
+			claims[name] = value
+		}
+	}
+	for i, values := range decoded {
+		digest := base64URLHash(disclosures[i])
+		if !referenced[digest] {
+			return nil, fmt.Errorf("disclosure %d is not referenced by the credential", i+1)
+		}
+		if len(values) != 3 || !topLevel[digest] {
+			continue
+		}
+		name, ok := values[1].(string)
+		if !ok || name == "_sd" || name == "..." {
+			return nil, fmt.Errorf("disclosure %d has an invalid claim name", i+1)
+		}
+		if _, exists := claims[name]; exists {
+			return nil, fmt.Errorf("claim %s is disclosed more than once", name)
+		}
+		claims[name] = values[2]
+	}
+	return claims, nil
+}
+
+// collectDigests gathers the _sd and array element digests of value.
+func collectDigests(value any, digests map[string]bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, item := range v {
+			switch name {
+			case "_sd":
+				list, _ := item.([]any)
+				for _, digest := range list {
+					if value, ok := digest.(string); ok {
+						digests[value] = true
+					}
+				}
+			case "...":
+				if digest, ok := item.(string); ok {
+					digests[digest] = true
+				}
+			default:
+				collectDigests(item, digests)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			collectDigests(item, digests)
+		}
+	}
+}
+
+func verifyKeyBinding(
+	raw string,
+	holder crypto.PublicKey,
+	signed string,
+	audience string,
+	nonce string,
+	now time.Time,
+) error {
+	token, err := parseJWS(raw)
+	if err != nil {
+		return fmt.Errorf("key binding JWT: %w", err)
+	}
+	if typ := token.headerString("typ"); typ != typKeyBinding {
+		return fmt.Errorf("unexpected key binding typ %q", typ)
+	}
+	if err := token.verify(holder); err != nil {
+		return fmt.Errorf("key binding signature: %w", err)
+	}
+	if got := token.claimString("nonce"); got != nonce {
+		return fmt.Errorf("key binding nonce %q does not match the request", got)
+	}
+	if got := token.claimString("aud"); got != audience {
+		return fmt.Errorf("key binding aud %q does not match %q", got, audience)
+	}
+	if got := token.claimString("sd_hash"); got != base64URLHash(signed) {
+		return errors.New("key binding sd_hash does not match the presentation")
+	}
+	iat, ok := token.Payload["iat"].(float64)
+	if !ok {
+		return errors.New("key binding JWT has no iat")
+	}
+	issuedAt := time.Unix(int64(iat), 0)
+	if issuedAt.After(now.Add(maxClockSkew)) || issuedAt.Before(now.Add(-maxPresentationAge)) {
+		return fmt.Errorf("key binding iat %s is out of range", issuedAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// PresentSDJWT creates a presentation disclosing claims, or every claim when
+// claims is empty, with a key binding JWT signed by holder.
+func PresentSDJWT(
+	credential string,
+	claims []string,
+	holder crypto.Signer,
+	audience string,
+	nonce string,
+	now time.Time,
+) (string, error) {
+	parts := strings.Split(strings.TrimSuffix(credential, "~"), "~")
+	presentation := parts[0] + "~"
+	for _, disclosure := range parts[1:] {
+		raw, err := base64.RawURLEncoding.DecodeString(disclosure)
+		if err != nil {
+			return "", fmt.Errorf("disclosure: %w", err)
+		}
+		var values []any
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return "", fmt.Errorf("disclosure: %w", err)
+		}
+		name, _ := values[1].(string)
+		if len(claims) == 0 || slices.Contains(claims, name) {
+			presentation += disclosure + "~"
+		}
+	}
+	keyBinding, err := signJWS(map[string]any{"typ": typKeyBinding}, map[string]any{
+		"iat":     now.Unix(),
+		"aud":     audience,
+		"nonce":   nonce,
+		"sd_hash": base64URLHash(presentation),
+	}, holder)
+	if err != nil {
+		return "", err
+	}
+	return presentation + keyBinding, nil
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,284 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Client identifier prefixes of the reference verifier.
+const (
+	SchemeX509SANDNS = "x509_san_dns"
+	SchemeX509Hash   = "x509_hash"
+)
+
+const (
+	typRequestObject = "oauth-authz-req+jwt"
+	// RequestObjectLifetime bounds the exp of signed request objects.
+	RequestObjectLifetime = time.Hour
+)
+
+// VerifierConfig selects what a reference verifier session requests.
+type VerifierConfig struct {
+	Credentials []string `json:"credentials"                yaml:"credentials"                validate:"required,min=1,dive,oneof=pid_sd_jwt mdl_mdoc"`
+	// Claims restricts the requested claims per credential configuration.
+	Claims         map[string][]string `json:"claims,omitempty"           yaml:"claims,omitempty"`
+	ClientIDScheme string              `json:"client_id_scheme,omitempty" yaml:"client_id_scheme,omitempty" validate:"omitempty,oneof=x509_san_dns x509_hash"`
+}
+
+// VerifierSecrets are the per session values bound into the presentation.
+type VerifierSecrets struct {
+	Nonce string `json:"nonce"`
+	State string `json:"state"`
+}
+
+// NewVerifierSecrets generates random session secrets.
+func NewVerifierSecrets() (VerifierSecrets, error) {
+	nonce, err := randomString(24)
+	if err != nil {
+		return VerifierSecrets{}, err
+	}
+	state, err := randomString(24)
+	if err != nil {
+		return VerifierSecrets{}, err
+	}
+	return VerifierSecrets{Nonce: nonce, State: state}, nil
+}
+
+// VerifierSession is the state of one presentation request.
+type VerifierSession struct {
+	VerifierURL   string          `json:"verifier_url"`
+	Config        VerifierConfig  `json:"config"`
+	Secrets       VerifierSecrets `json:"secrets"`
+	ClientID      string          `json:"client_id"`
+	RequestObject string          `json:"request_object"`
+}
+
+// VerificationResult is the outcome of a presentation response.
+type VerificationResult struct {
+	Valid bool `json:"valid"`
+	// Credentials are the verified presentations per DCQL query id.
+	Credentials map[string][]VerifiedCredential `json:"credentials,omitempty"`
+	Issues      []string                        `json:"issues,omitempty"`
+}
+
+// RequestURI is where wallets fetch the signed request object.
+func (s *VerifierSession) RequestURI() string {
+	return s.VerifierURL + "/request.jwt"
+}
+
+// ResponseURI is where wallets post the presentation.
+func (s *VerifierSession) ResponseURI() string {
+	return s.VerifierURL + "/response"
+}
+
+// DCQLQuery is the credential query of the session.
+func (s *VerifierSession) DCQLQuery() (map[string]any, error) {
+	queries := make([]map[string]any, 0, len(s.Config.Credentials))
+	for _, id := range s.Config.Credentials {
+		configuration, err := LookupConfiguration(id)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, configuration.CredentialQuery(s.Config.Claims[id]))
+	}
+	return map[string]any{"credentials": queries}, nil
+}
+
+// Deeplink is the authorization request passed by reference.
+func (s *VerifierSession) Deeplink() string {
+	query := url.Values{}
+	query.Set("client_id", s.ClientID)
+	query.Set("request_uri", s.RequestURI())
+	return "openid4vp://?" + query.Encode()
+}
+
+// CheckResponse validates the state of a response before it is verified.
+func (s *VerifierSession) CheckResponse(state string) *ProtocolError {
+	if state != s.Secrets.State {
+		return protocolError(http.StatusBadRequest, "invalid_request", "unknown state")
+	}
+	return nil
+}
+
+// ClientID returns the client identifier of the verifier at verifierURL.
+func (k *Keys) ClientID(verifierURL, scheme string) (string, error) {
+	host := hostOf(verifierURL)
+	if scheme == SchemeX509Hash {
+		certificate, err := k.VerifierCertificate(host)
+		if err != nil {
+			return "", err
+		}
+		digest := sha256.Sum256(certificate.Raw)
+		return SchemeX509Hash + ":" + base64.RawURLEncoding.EncodeToString(digest[:]), nil
+	}
+	if host == "" {
+		return "", fmt.Errorf("verifier URL %q has no host", verifierURL)
+	}
+	return SchemeX509SANDNS + ":" + host, nil
+}
+
+// SignRequest signs the request object of session.
+func (k *Keys) SignRequest(session VerifierSession, now time.Time) (string, error) {
+	certificate, err := k.VerifierCertificate(hostOf(session.VerifierURL))
+	if err != nil {
+		return "", err
+	}
+	query, err := session.DCQLQuery()
+	if err != nil {
+		return "", err
+	}
+	return signJWS(
+		map[string]any{"typ": typRequestObject, "x5c": x5cHeader(certificate)},
+		map[string]any{
+			"aud":           "https://self-issued.me/v2",
+			"client_id":     session.ClientID,
+			"response_type": "vp_token",
+			"response_mode": "direct_post",
+			"response_uri":  session.ResponseURI(),
+			"nonce":         session.Secrets.Nonce,
+			"state":         session.Secrets.State,
+			"dcql_query":    query,
+			"client_metadata": map[string]any{
+				"client_name":          "Credimi Reference Verifier",
+				"vp_formats_supported": VPFormats(),
+			},
+			"iat": now.Unix(),
+			"exp": now.Add(RequestObjectLifetime).Unix(),
+		},
+		k.VerifierKey(),
+	)
+}
+
+// VerifyResponse verifies the vp_token of a response against the DCQL query
+// of session.
+func (k *Keys) VerifyResponse(
+	session VerifierSession,
+	vpToken string,
+	now time.Time,
+) VerificationResult {
+	result := VerificationResult{Credentials: map[string][]VerifiedCredential{}}
+	var presentations map[string]any
+	if err := json.Unmarshal([]byte(vpToken), &presentations); err != nil {
+		result.Issues = append(result.Issues, "vp_token must be a JSON object: "+err.Error())
+		return result
+	}
+	query, err := session.DCQLQuery()
+	if err != nil {
+		result.Issues = append(result.Issues, err.Error())
+		return result
+	}
+
+	requested := []string{}
+	for _, credentialQuery := range query["credentials"].([]map[string]any) {
+		id, _ := credentialQuery["id"].(string)
+		requested = append(requested, id)
+		configuration := Configurations[id]
+		values, ok := presentations[id]
+		if !ok {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: no presentation", id))
+			continue
+		}
+		list, ok := values.([]any)
+		if !ok {
+			list = []any{values}
+		}
+		for i, value := range list {
+			presentation, _ := value.(string)
+			verified, err := k.verifyPresentation(session, configuration, presentation, now)
+			if err != nil {
+				result.Issues = append(result.Issues, fmt.Sprintf("%s[%d]: %s", id, i, err))
+				continue
+			}
+			for _, credential := range verified {
+				missing := missingClaims(configuration, session.Config.Claims[id], credential)
+				if len(missing) > 0 {
+					result.Issues = append(result.Issues, fmt.Sprintf(
+						"%s[%d]: missing claims %v",
+						id,
+						i,
+						missing,
+					))
+				}
+			}
+			result.Credentials[id] = append(result.Credentials[id], verified...)
+		}
+	}
+	for id := range presentations {
+		if !slices.Contains(requested, id) {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: was not requested", id))
+		}
+	}
+	slices.Sort(result.Issues)
+	result.Valid = len(result.Issues) == 0
+	return result
+}
+
+func (k *Keys) verifyPresentation(
+	session VerifierSession,
+	configuration CredentialConfiguration,
+	presentation string,
+	now time.Time,
+) ([]VerifiedCredential, error) {
+	if presentation == "" {
+		return nil, fmt.Errorf("presentation must be a string")
+	}
+	if configuration.Format == FormatMDoc {
+		transcript, err := SessionTranscript(
+			session.ClientID,
+			session.Secrets.Nonce,
+			session.ResponseURI(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		verified, err := k.VerifyMDoc(presentation, transcript, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, credential := range verified {
+			if credential.Type != configuration.DocType {
+				return nil, fmt.Errorf("unexpected doctype %q", credential.Type)
+			}
+		}
+		return verified, nil
+	}
+	verified, err := k.VerifySDJWT(presentation, session.ClientID, session.Secrets.Nonce, now)
+	if err != nil {
+		return nil, err
+	}
+	if verified.Type != configuration.VCT {
+		return nil, fmt.Errorf("unexpected vct %q", verified.Type)
+	}
+	return []VerifiedCredential{verified}, nil
+}
+
+func missingClaims(
+	configuration CredentialConfiguration,
+	requested []string,
+	credential VerifiedCredential,
+) []string {
+	if len(requested) == 0 {
+		requested = configuration.ClaimNames()
+	}
+	claims := credential.Claims
+	if configuration.Format == FormatMDoc {
+		claims, _ = credential.Claims[configuration.Namespace].(map[string]any)
+	}
+	var missing []string
+	for _, claim := range requested {
+		if _, ok := claims[claim]; !ok {
+			missing = append(missing, claim)
+		}
+	}
+	return missing
+}
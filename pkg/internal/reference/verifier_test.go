@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reference
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifierURL = "https://credimi.example/api/reference/org/verifier/session1"
+
+func testVerifierSession(t *testing.T, keys *Keys, config VerifierConfig) VerifierSession {
+	t.Helper()
+	secrets, err := NewVerifierSecrets()
+	require.NoError(t, err)
+	clientID, err := keys.ClientID(testVerifierURL, config.ClientIDScheme)
+	require.NoError(t, err)
+	return VerifierSession{
+		VerifierURL: testVerifierURL,
+		Config:      config,
+		Secrets:     secrets,
+		ClientID:    clientID,
+	}
+}
+
+// issueCredential runs the pre-authorized issuance of configuration.
+func issueCredential(
+	t *testing.T,
+	keys *Keys,
+	configuration string,
+	holder *ecdsa.PrivateKey,
+) string {
+	t.Helper()
+	session := testIssuerSession(t, IssuerConfig{Configuration: configuration})
+	proof, err := SignProof(holder, testIssuerURL, session.Secrets.Nonce, time.Now())
+	require.NoError(t, err)
+	credentials, protoErr := keys.IssueCredentials(*session, []string{proof}, time.Now())
+	require.Nil(t, protoErr)
+	return credentials[0]
+}
+
+func vpToken(t *testing.T, presentations map[string][]string) string {
+	t.Helper()
+	encoded, err := json.Marshal(presentations)
+	require.NoError(t, err)
+	return string(encoded)
+}
+
+func TestSignRequest(t *testing.T) {
+	keys := testKeys(t)
+	session := testVerifierSession(t, keys, VerifierConfig{
+		Credentials: []string{ConfigurationPID, ConfigurationMDL},
+		Claims:      map[string][]string{ConfigurationPID: {"given_name"}},
+	})
+	require.Equal(t, "x509_san_dns:credimi.example", session.ClientID)
+
+	deeplink, err := url.Parse(session.Deeplink())
+	require.NoError(t, err)
+	require.Equal(t, "openid4vp", deeplink.Scheme)
+	require.Equal(t, session.ClientID, deeplink.Query().Get("client_id"))
+	require.Equal(t, testVerifierURL+"/request.jwt", deeplink.Query().Get("request_uri"))
+
+	requestObject, err := keys.SignRequest(session, time.Now())
+	require.NoError(t, err)
+	token, err := parseJWS(requestObject)
+	require.NoError(t, err)
+	require.Equal(t, typRequestObject, token.headerString("typ"))
+	chain, err := token.x5c()
+	require.NoError(t, err)
+	require.Equal(t, []string{"credimi.example"}, chain[0].DNSNames)
+	require.NoError(t, token.verify(chain[0].PublicKey))
+
+	require.Equal(t, session.ClientID, token.claimString("client_id"))
+	require.Equal(t, "direct_post", token.claimString("response_mode"))
+	require.Equal(t, testVerifierURL+"/response", token.claimString("response_uri"))
+	require.Equal(t, session.Secrets.Nonce, token.claimString("nonce"))
+	require.Equal(t, session.Secrets.State, token.claimString("state"))
+	credentials := token.Payload["dcql_query"].(map[string]any)["credentials"].([]any)
+	require.Len(t, credentials, 2)
+	pid := credentials[0].(map[string]any)
+	require.Equal(t, []any{map[string]any{"path": []any{"given_name"}}}, pid["claims"])
+	mdl := credentials[1].(map[string]any)
+	require.Equal(t, "org.iso.18013.5.1.mDL", mdl["meta"].(map[string]any)["doctype_value"])
+}
+
+func TestClientIDHashScheme(t *testing.T) {
+	keys := testKeys(t)
+	clientID, err := keys.ClientID(testVerifierURL, SchemeX509Hash)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(clientID, SchemeX509Hash+":"))
+
+	again, err := keys.ClientID(testVerifierURL, SchemeX509Hash)
+	require.NoError(t, err)
+	require.Equal(t, clientID, again)
+
+	_, err = keys.ClientID("not a url", SchemeX509SANDNS)
+	require.Error(t, err)
+}
+
+func TestVerifyResponse(t *testing.T) {
+	keys := testKeys(t)
+	holder := testHolder(t)
+	now := time.Now()
+	session := testVerifierSession(t, keys, VerifierConfig{
+		Credentials: []string{ConfigurationPID, ConfigurationMDL},
+	})
+
+	pid, err := PresentSDJWT(
+		issueCredential(t, keys, ConfigurationPID, holder),
+		nil,
+		holder,
+		session.ClientID,
+		session.Secrets.Nonce,
+		now,
+	)
+	require.NoError(t, err)
+	transcript, err := SessionTranscript(
+		session.ClientID,
+		session.Secrets.Nonce,
+		session.ResponseURI(),
+	)
+	require.NoError(t, err)
+	mdl, err := PresentMDoc(
+		issueCredential(t, keys, ConfigurationMDL, holder),
+		Configurations[ConfigurationMDL].DocType,
+		holder,
+		transcript,
+	)
+	require.NoError(t, err)
+
+	result := keys.VerifyResponse(session, vpToken(t, map[string][]string{
+		ConfigurationPID: {pid},
+		ConfigurationMDL: {mdl},
+	}), now)
+	require.Empty(t, result.Issues)
+	require.True(t, result.Valid)
+
+	verifiedPID := result.Credentials[ConfigurationPID][0]
+	require.Equal(t, FormatSDJWT, verifiedPID.Format)
+	require.Equal(t, "urn:eudi:pid:1", verifiedPID.Type)
+	require.Equal(t, testIssuerURL, verifiedPID.Issuer)
+	require.True(t, verifiedPID.Trusted)
+	require.Equal(t, "Erika", verifiedPID.Claims["given_name"])
+
+	verifiedMDL := result.Credentials[ConfigurationMDL][0]
+	require.Equal(t, FormatMDoc, verifiedMDL.Format)
+	require.True(t, verifiedMDL.Trusted)
+	namespace := verifiedMDL.Claims["org.iso.18013.5.1"].(map[string]any)
+	require.Equal(t, "1984-01-26", namespace["birth_date"])
+	require.Equal(t, true, namespace["age_over_18"])
+}
+
+func TestVerifyResponseReportsIssues(t *testing.T) {
+	keys := testKeys(t)
+	holder := testHolder(t)
+	now := time.Now()
+	session := testVerifierSession(t, keys, VerifierConfig{
+		Credentials: []string{ConfigurationPID},
+		Claims:      map[string][]string{ConfigurationPID: {"given_name", "family_name"}},
+	})
+	credential := issueCredential(t, keys, ConfigurationPID, holder)
+
+	present := func(claims []string, audience, nonce string) string {
+		presentation, err := PresentSDJWT(credential, claims, holder, audience, nonce, now)
+		require.NoError(t, err)
+		return presentation
+	}
+
+	tests := map[string]struct {
+		token string
+		issue string
+	}{
+		"not json": {
+			token: "pid",
+			issue: "vp_token must be a JSON object",
+		},
+		"missing query": {
+			token: vpToken(t, map[string][]string{}),
+			issue: "pid_sd_jwt: no presentation",
+		},
+		"unexpected query": {
+			token: vpToken(t, map[string][]string{
+				ConfigurationPID: {present(nil, session.ClientID, session.Secrets.Nonce)},
+				"other":          {"x"},
+			}),
+			issue: "other: was not requested",
+		},
+		"wrong nonce": {
+			token: vpToken(t, map[string][]string{
+				ConfigurationPID: {present(nil, session.ClientID, "replayed")},
+			}),
+			issue: "nonce",
+		},
+		"wrong audience": {
+			token: vpToken(t, map[string][]string{
+				ConfigurationPID: {present(nil, "x509_san_dns:other.example", session.Secrets.Nonce)},
+			}),
+			issue: "aud",
+		},
+		"missing claims": {
+			token: vpToken(t, map[string][]string{
+				ConfigurationPID: {present([]string{"given_name"}, session.ClientID, session.Secrets.Nonce)},
+			}),
+			issue: "missing claims [family_name]",
+		},
+		"mdoc for sd-jwt query": {
+			token: vpToken(t, map[string][]string{ConfigurationPID: {"oWV4"}}),
+			issue: "pid_sd_jwt[0]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := keys.VerifyResponse(session, tt.token, now)
+			require.False(t, result.Valid)
+			require.NotEmpty(t, result.Issues)
+			require.Contains(t, strings.Join(result.Issues, "\n"), tt.issue)
+		})
+	}
+}
+
+func TestVerifySDJWTRejectsTampering(t *testing.T) {
+	keys := testKeys(t)
+	holder := testHolder(t)
+	now := time.Now()
+	credential := issueCredential(t, keys, ConfigurationPID, holder)
+	presentation, err := PresentSDJWT(credential, nil, holder, "aud", "nonce", now)
+	require.NoError(t, err)
+
+	_, err = keys.VerifySDJWT(presentation, "aud", "nonce", now)
+	require.NoError(t, err)
+
+	withoutKeyBinding := presentation[:strings.LastIndex(presentation, "~")+1]
+	_, err = keys.VerifySDJWT(withoutKeyBinding, "aud", "nonce", now)
+	require.ErrorContains(t, err, "key binding")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`["salt","given_name","Mallory"]`))
+	parts := strings.Split(presentation, "~")
+	parts[1] = forged
+	_, err = keys.VerifySDJWT(strings.Join(parts, "~"), "aud", "nonce", now)
+	require.Error(t, err)
+
+	_, err = keys.VerifySDJWT(presentation, "aud", "nonce", now.Add(2*credentialValidity))
+	require.ErrorContains(t, err, "expired")
+}
+
+func TestVerifyMDocRejectsWrongTranscript(t *testing.T) {
+	keys := testKeys(t)
+	holder := testHolder(t)
+	credential := issueCredential(t, keys, ConfigurationMDL, holder)
+	docType := Configurations[ConfigurationMDL].DocType
+
+	transcript, err := SessionTranscript("client", "nonce", "https://credimi.example/response")
+	require.NoError(t, err)
+	presentation, err := PresentMDoc(credential, docType, holder, transcript)
+	require.NoError(t, err)
+
+	_, err = keys.VerifyMDoc(presentation, transcript, time.Now())
+	require.NoError(t, err)
+
+	other, err := SessionTranscript("client", "other", "https://credimi.example/response")
+	require.NoError(t, err)
+	_, err = keys.VerifyMDoc(presentation, other, time.Now())
+	require.ErrorContains(t, err, "deviceSignature")
+
+	wrongHolder := testHolder(t)
+	presentation, err = PresentMDoc(credential, docType, wrongHolder, transcript)
+	require.NoError(t, err)
+	_, err = keys.VerifyMDoc(presentation, transcript, time.Now())
+	require.ErrorContains(t, err, "deviceSignature")
+}
+
+func TestCheckResponse(t *testing.T) {
+	session := VerifierSession{Secrets: VerifierSecrets{State: "state"}}
+	require.Nil(t, session.CheckResponse("state"))
+	require.Equal(t, "invalid_request", session.CheckResponse("other").Code)
+}
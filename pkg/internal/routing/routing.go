@@ -9,8 +9,10 @@ import (
 	"log"
 	"net/http"
 	"reflect"
+	"slices"
 
 	"github.com/forkbombeu/credimi/pkg/internal/apierror" // Adjust import path
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
@@ -34,6 +36,12 @@ type QuerySearchAttribute struct {
 	Description string `json:"description"`
 }
 
+// ContentTypeEventStream is the ResponseContentType of server-sent event routes.
+const ContentTypeEventStream = "text/event-stream"
+
+// NoContent is the ResponseSchema of routes that answer 204 without a body.
+type NoContent struct{}
+
 type RouteDefinition struct {
 	Method                string
 	Path                  string
@@ -47,6 +55,12 @@ type RouteDefinition struct {
 	Middlewares           []*hook.Handler[*core.RequestEvent]
 	ExcludedMiddlewares   []string
 	QuerySearchAttributes []QuerySearchAttribute
+	// ResponseContentType defaults to application/json. With
+	// ContentTypeEventStream, ResponseSchema describes each event payload.
+	ResponseContentType string
+	// Permission is required from API keys restricted to a set of
+	// permissions. Such keys cannot call routes that declare none.
+	Permission apikey.Permission
 }
 
 func GetValidatedInput[T any](e *core.RequestEvent) (T, error) {
@@ -99,6 +113,7 @@ func RegisterRoutesWithValidation(
 	// 1) Public routes: needsAuth=false and no auth middleware.
 	// 2) User-auth routes: needsAuth=true, accepts Bearer or Credimi-Api-Key.
 	// 3) Temporal-internal routes: explicit route middleware RequireInternalAdminAPIKey().
+	// User-auth routes are also limited to the requests per minute of the organization.
 	for _, route := range routes {
 		log.Printf("ADD [V] %s", route.Path)
 		inputType := reflect.TypeOf(route.RequestSchema)
@@ -108,8 +123,13 @@ func RegisterRoutesWithValidation(
 		needsValidationBinding := inputType != nil
 
 		if needsAuth {
-			route.Middlewares = append(route.Middlewares, middlewares.RequireAuthOrAPIKey())
+			route.Middlewares = append(
+				route.Middlewares,
+				middlewares.RequireAuthOrAPIKey(),
+				middlewares.OrganizationRateLimit(),
+			)
 		}
+		route.Middlewares = withPermission(route, route.RequiresAuth(needsAuth))
 
 		switch route.Method {
 		case http.MethodPost:
@@ -170,6 +190,7 @@ func RegisterRoutesWithoutValidation(
 ) {
 	for _, route := range routes {
 		log.Printf("ADD %s", route.Path)
+		route.Middlewares = withPermission(route, route.RequiresAuth(false))
 		switch route.Method {
 		case http.MethodPost:
 			group.POST(route.Path, route.Handler()).
@@ -199,3 +220,39 @@ func RegisterRoutesWithoutValidation(
 		}
 	}
 }
+
+// RequiresAuth reports whether callers of the route must authenticate,
+// either through the auth of its group or its own auth middleware.
+func (route RouteDefinition) RequiresAuth(groupAuth bool) bool {
+	if groupAuth && !slices.Contains(
+		route.ExcludedMiddlewares,
+		middlewares.RequireAuthOrAPIKeyMiddlewareID,
+	) {
+		return true
+	}
+	for _, m := range route.Middlewares {
+		if m != nil && (m.Id == middlewares.RequireAuthOrAPIKeyMiddlewareID ||
+			m.Id == middlewares.RequireInternalAdminOrAuthMiddlewareID) {
+			return true
+		}
+	}
+	return false
+}
+
+// withPermission declares the permission of the route to the API key
+// checks and enforces it on the organization role of the caller. Routes
+// requiring auth always check the role, so one that declares no permission
+// is left to owners and admins.
+func withPermission(
+	route RouteDefinition,
+	requiresAuth bool,
+) []*hook.Handler[*core.RequestEvent] {
+	var checks []*hook.Handler[*core.RequestEvent]
+	if route.Permission != "" {
+		checks = append(checks, middlewares.RequireAPIKeyPermission(route.Permission))
+	}
+	if requiresAuth || route.Permission != "" {
+		checks = append(checks, middlewares.RequireOrganizationRole(route.Permission))
+	}
+	return append(checks, route.Middlewares...)
+}
@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/forkbombeu/credimi/pkg/internal/apikey"
 	"github.com/forkbombeu/credimi/pkg/internal/middlewares"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
@@ -173,6 +174,74 @@ func TestRegisterRoutesWithValidation_RequireAuth(t *testing.T) {
 	require.True(t, handlerCalled.Load())
 }
 
+func TestRegisterRoutesWithValidation_Permission(t *testing.T) {
+	app, err := tests.NewTestApp("../../../test_pb_data")
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	okHandler := func() func(*core.RequestEvent) error {
+		return func(e *core.RequestEvent) error {
+			return e.String(http.StatusOK, "ok")
+		}
+	}
+	routes := []RouteDefinition{
+		{
+			Method:     http.MethodGet,
+			Path:       "/read",
+			Handler:    okHandler,
+			Permission: apikey.PermissionPipelinesRead,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "/run",
+			Handler:    okHandler,
+			Permission: apikey.PermissionPipelinesRun,
+		},
+	}
+
+	r := router.NewRouter(
+		func(w http.ResponseWriter, req *http.Request) (*core.RequestEvent, router.EventCleanupFunc) {
+			return &core.RequestEvent{
+				App:   app,
+				Event: router.Event{Response: w, Request: req},
+			}, nil
+		},
+	)
+	r.Bind(&hook.Handler[*core.RequestEvent]{Func: middlewares.ErrorHandlingMiddleware})
+
+	RegisterRoutesWithValidation(app, r.RouterGroup, routes, true)
+	mux, err := r.BuildMux()
+	require.NoError(t, err)
+
+	user, err := app.FindAuthRecordByEmail("users", "userA@example.org")
+	require.NoError(t, err)
+	apiKeysCollection, err := app.FindCollectionByNameOrId("api_keys")
+	require.NoError(t, err)
+	hash, err := bcrypt.GenerateFromPassword([]byte("read-only-key"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	keyRecord := core.NewRecord(apiKeysCollection)
+	keyRecord.Set("name", "routing-read-only")
+	keyRecord.Set("key", string(hash))
+	keyRecord.Set("user", user.Id)
+	keyRecord.Set("key_type", "user")
+	apikey.Grant{Permissions: []apikey.Permission{apikey.PermissionPipelinesRead}}.
+		Apply(keyRecord)
+	require.NoError(t, app.Save(keyRecord))
+
+	req := httptest.NewRequest(http.MethodGet, "/read", nil)
+	req.Header.Set("Credimi-Api-Key", "read-only-key")
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/run", nil)
+	req.Header.Set("Credimi-Api-Key", "read-only-key")
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+	require.Equal(t, http.StatusForbidden, res.Code)
+	require.Contains(t, res.Body.String(), "insufficient_api_key_permission")
+}
+
 func TestRegisterRoutesWithValidation_RequireAuthExcluded(t *testing.T) {
 	app, err := tests.NewTestApp("../../../test_pb_data")
 	require.NoError(t, err)
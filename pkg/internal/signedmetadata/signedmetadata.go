@@ -0,0 +1,432 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package signedmetadata verifies the signed_metadata JWT that OpenID4VCI
+// credential issuers may publish next to their plain JSON metadata, and
+// compares the signed claims with the unsigned ones.
+package signedmetadata
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// JWTType is the typ header required on signed issuer metadata.
+	JWTType = "openidvci-issuer-metadata+jwt"
+	// Claim is the metadata parameter carrying the signed metadata.
+	Claim = "signed_metadata"
+
+	jwtVCIssuerPath = "/.well-known/jwt-vc-issuer"
+	maxJWKSSize     = 1 << 20
+)
+
+// Status is the outcome of the verification.
+type Status string
+
+const (
+	StatusAbsent   Status = "absent"
+	StatusVerified Status = "verified"
+	StatusInvalid  Status = "invalid"
+)
+
+// Key sources the signature was verified with.
+const (
+	KeySourceX5C  = "x5c"
+	KeySourceJWKS = "jwks"
+)
+
+var signingMethods = []string{
+	"ES256", "ES384", "ES512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"EdDSA",
+}
+
+// registeredClaims are JWT claims that are not issuer metadata parameters.
+var registeredClaims = map[string]bool{
+	"iss": true,
+	"sub": true,
+	"iat": true,
+	"exp": true,
+	"nbf": true,
+	"jti": true,
+}
+
+// Mismatch is a metadata parameter whose unsigned value differs from the
+// signed one.
+type Mismatch struct {
+	Path     []string `json:"path"`
+	Signed   any      `json:"signed"`
+	Unsigned any      `json:"unsigned"`
+}
+
+// Field returns the slash separated path of the mismatching parameter.
+func (m Mismatch) Field() string {
+	return strings.Join(m.Path, "/")
+}
+
+// Result describes the outcome of verifying signed metadata.
+type Result struct {
+	Status       Status     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	Issuer       string     `json:"issuer,omitempty"`
+	Subject      string     `json:"subject,omitempty"`
+	KeySource    string     `json:"key_source,omitempty"`
+	Certificates []string   `json:"certificates,omitempty"`
+	Mismatches   []Mismatch `json:"mismatches,omitempty"`
+	CheckedAt    string     `json:"checked_at"`
+
+	// Claims holds the verified metadata parameters. They take precedence
+	// over the unsigned values.
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// Verifier checks signed_metadata JWTs.
+type Verifier struct {
+	HTTPClient *http.Client
+	// Roots anchors x5c chains. When nil the system pool is used.
+	Roots *x509.CertPool
+	Now   func() time.Time
+}
+
+// NewVerifier returns a verifier that trusts the system roots.
+func NewVerifier() *Verifier {
+	return &Verifier{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+// Verify checks the signed_metadata of metadata. credentialIssuer is the
+// identifier the metadata was fetched for; it is used when the metadata does
+// not carry credential_issuer. Verification failures are reported in the
+// result, never as an error.
+func (v *Verifier) Verify(
+	ctx context.Context,
+	metadata map[string]any,
+	credentialIssuer string,
+) Result {
+	now := v.now()
+	result := Result{Status: StatusAbsent, CheckedAt: now.UTC().Format(time.RFC3339)}
+
+	rawValue, ok := metadata[Claim]
+	if !ok || rawValue == nil {
+		return result
+	}
+	raw, ok := rawValue.(string)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return invalid(result, errors.New("signed_metadata must be a JWT string"))
+	}
+
+	claims, err := v.verifyJWT(ctx, raw, now, &result)
+	if err != nil {
+		return invalid(result, err)
+	}
+
+	if issuerID, ok := metadata["credential_issuer"].(string); ok && issuerID != "" {
+		credentialIssuer = issuerID
+	}
+	if result.Subject != credentialIssuer {
+		return invalid(result, fmt.Errorf(
+			"sub %q does not match credential issuer %q",
+			result.Subject,
+			credentialIssuer,
+		))
+	}
+
+	signed := map[string]any{}
+	for key, value := range claims {
+		if !registeredClaims[key] {
+			signed[key] = value
+		}
+	}
+	result.Claims = signed
+	result.Mismatches = Compare(signed, metadata)
+	result.Status = StatusVerified
+	return result
+}
+
+// Compare returns the parameters whose unsigned value differs from the signed
+// one. Parameters that are only signed are not mismatches.
+func Compare(signed, unsigned map[string]any) []Mismatch {
+	var mismatches []Mismatch
+	compareValues(nil, signed, unsigned, &mismatches)
+	return mismatches
+}
+
+func compareValues(path []string, signed, unsigned map[string]any, out *[]Mismatch) {
+	keys := make([]string, 0, len(signed))
+	for key := range signed {
+		if key != Claim {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		unsignedValue, ok := unsigned[key]
+		if !ok {
+			continue
+		}
+		signedValue := signed[key]
+		keyPath := append(append([]string{}, path...), key)
+
+		signedMap, signedIsMap := signedValue.(map[string]any)
+		unsignedMap, unsignedIsMap := unsignedValue.(map[string]any)
+		if signedIsMap && unsignedIsMap {
+			compareValues(keyPath, signedMap, unsignedMap, out)
+			continue
+		}
+		if !reflect.DeepEqual(normalize(signedValue), normalize(unsignedValue)) {
+			*out = append(*out, Mismatch{
+				Path:     keyPath,
+				Signed:   signedValue,
+				Unsigned: unsignedValue,
+			})
+		}
+	}
+}
+
+// normalize round-trips a value through JSON so that values decoded by
+// different parsers compare equal.
+func normalize(value any) any {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out any
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+func (v *Verifier) verifyJWT(
+	ctx context.Context,
+	raw string,
+	now time.Time,
+	result *Result,
+) (jwt.MapClaims, error) {
+	unverified := jwt.MapClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(raw, unverified)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signed_metadata: %w", err)
+	}
+	if typ, _ := token.Header["typ"].(string); typ != JWTType {
+		return nil, fmt.Errorf("unexpected typ %q, want %q", typ, JWTType)
+	}
+	issuer, _ := unverified["iss"].(string)
+	if issuer == "" {
+		return nil, errors.New("signed_metadata has no iss claim")
+	}
+	result.Issuer = issuer
+
+	var key any
+	if _, ok := token.Header["x5c"]; ok {
+		key, err = v.x5cKey(token.Header["x5c"], issuer, now, result)
+	} else {
+		key, err = v.jwksKey(ctx, token.Header, issuer, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+		jwt.WithIssuedAt(),
+	)
+	if _, err := parser.ParseWithClaims(raw, claims, func(*jwt.Token) (any, error) {
+		return key, nil
+	}); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+	if _, ok := claims["iat"]; !ok {
+		return nil, errors.New("signed_metadata has no iat claim")
+	}
+	result.Subject, _ = claims["sub"].(string)
+	if result.Subject == "" {
+		return nil, errors.New("signed_metadata has no sub claim")
+	}
+	return claims, nil
+}
+
+// x5cKey validates the certificate chain in the x5c header and checks that
+// the leaf certificate is bound to iss.
+func (v *Verifier) x5cKey(
+	header any,
+	issuer string,
+	now time.Time,
+	result *Result,
+) (any, error) {
+	rawChain, ok := header.([]any)
+	if !ok || len(rawChain) == 0 {
+		return nil, errors.New("x5c header must be a non-empty array")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawChain))
+	for i, entry := range rawChain {
+		encoded, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("x5c[%d] is not a string", i)
+		}
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: %w", i, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: %w", i, err)
+		}
+		certs = append(certs, cert)
+		result.Certificates = append(result.Certificates, cert.Subject.String())
+	}
+	result.KeySource = KeySourceX5C
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	leaf := certs[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, fmt.Errorf("x5c chain is not trusted: %w", err)
+	}
+	if err := checkCertificateBinding(leaf, issuer); err != nil {
+		return nil, err
+	}
+	return leaf.PublicKey, nil
+}
+
+// checkCertificateBinding requires iss to appear in the leaf certificate,
+// either as a URI subject alternative name or as the DNS name of its host.
+func checkCertificateBinding(leaf *x509.Certificate, issuer string) error {
+	for _, uri := range leaf.URIs {
+		if strings.TrimRight(uri.String(), "/") == strings.TrimRight(issuer, "/") {
+			return nil
+		}
+	}
+	parsed, err := url.Parse(issuer)
+	if err == nil && parsed.Hostname() != "" && leaf.VerifyHostname(parsed.Hostname()) == nil {
+		return nil
+	}
+	return fmt.Errorf("x5c leaf certificate is not issued for iss %q", issuer)
+}
+
+// jwksKey resolves the signing key from the JWT VC issuer metadata of iss.
+func (v *Verifier) jwksKey(
+	ctx context.Context,
+	header map[string]any,
+	issuer string,
+	result *Result,
+) (any, error) {
+	result.KeySource = KeySourceJWKS
+	jwks, err := v.issuerJWKS(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	kid, _ := header["kid"].(string)
+	jwk, err := jwks.Find(kid)
+	if err != nil {
+		return nil, fmt.Errorf("signing key of %s: %w", issuer, err)
+	}
+	return jwk.PublicKey()
+}
+
+type jwtVCIssuerMetadata struct {
+	Issuer  string             `json:"issuer"`
+	JWKS    *federation.JWKSet `json:"jwks,omitempty"`
+	JWKSURI string             `json:"jwks_uri,omitempty"`
+}
+
+func (v *Verifier) issuerJWKS(ctx context.Context, issuer string) (*federation.JWKSet, error) {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("iss %q is not a URL", issuer)
+	}
+	// The well-known segment is inserted between host and path.
+	metadataURL := parsed.Scheme + "://" + parsed.Host + jwtVCIssuerPath +
+		strings.TrimRight(parsed.Path, "/")
+
+	var metadata jwtVCIssuerMetadata
+	if err := v.getJSON(ctx, metadataURL, &metadata); err != nil {
+		return nil, fmt.Errorf("jwt-vc-issuer metadata: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != strings.TrimRight(issuer, "/") {
+		return nil, fmt.Errorf(
+			"jwt-vc-issuer metadata issuer %q does not match iss %q",
+			metadata.Issuer,
+			issuer,
+		)
+	}
+	if metadata.JWKS != nil {
+		return metadata.JWKS, nil
+	}
+	if metadata.JWKSURI == "" {
+		return nil, errors.New("jwt-vc-issuer metadata has neither jwks nor jwks_uri")
+	}
+	jwks := &federation.JWKSet{}
+	if err := v.getJSON(ctx, metadata.JWKSURI, jwks); err != nil {
+		return nil, fmt.Errorf("jwks_uri: %w", err)
+	}
+	return jwks, nil
+}
+
+func (v *Verifier) getJSON(ctx context.Context, target string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", target, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func invalid(result Result, err error) Result {
+	result.Status = StatusInvalid
+	result.Error = err.Error()
+	return result
+}
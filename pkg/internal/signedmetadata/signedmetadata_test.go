@@ -0,0 +1,322 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package signedmetadata
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var signedMetadataTestNow = time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+
+type testPKI struct {
+	roots   *x509.CertPool
+	leafKey *ecdsa.PrivateKey
+	leafDER []byte
+}
+
+func newTestPKI(t *testing.T, dnsName string) *testPKI {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             signedMetadataTestNow.Add(-time.Hour),
+		NotAfter:              signedMetadataTestNow.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(
+		rand.Reader,
+		caTemplate,
+		caTemplate,
+		&caKey.PublicKey,
+		caKey,
+	)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    signedMetadataTestNow.Add(-time.Hour),
+		NotAfter:     signedMetadataTestNow.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(
+		rand.Reader,
+		leafTemplate,
+		caCert,
+		&leafKey.PublicKey,
+		caKey,
+	)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	return &testPKI{
+		roots:   roots,
+		leafKey: leafKey,
+		leafDER: leafDER,
+	}
+}
+
+func signMetadata(
+	t *testing.T,
+	key *ecdsa.PrivateKey,
+	header map[string]any,
+	claims jwt.MapClaims,
+) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = JWTType
+	for name, value := range header {
+		token.Header[name] = value
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func testVerifier(roots *x509.CertPool, client *http.Client) *Verifier {
+	verifier := NewVerifier()
+	verifier.Roots = roots
+	if client != nil {
+		verifier.HTTPClient = client
+	}
+	verifier.Now = func() time.Time { return signedMetadataTestNow }
+	return verifier
+}
+
+func TestVerifyX5C(t *testing.T) {
+	pki := newTestPKI(t, "issuer.example")
+	x5c := []any{base64.StdEncoding.EncodeToString(pki.leafDER)}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                 "https://issuer.example",
+			"sub":                 "https://issuer.example",
+			"iat":                 signedMetadataTestNow.Unix(),
+			"credential_issuer":   "https://issuer.example",
+			"credential_endpoint": "https://issuer.example/credential",
+			"credential_configurations_supported": map[string]any{
+				"pid": map[string]any{"format": "dc+sd-jwt"},
+			},
+		}
+	}
+
+	t.Run("verified without mismatches", func(t *testing.T) {
+		metadata := map[string]any{
+			"credential_issuer":   "https://issuer.example",
+			"credential_endpoint": "https://issuer.example/credential",
+			"credential_configurations_supported": map[string]any{
+				"pid": map[string]any{"format": "dc+sd-jwt"},
+			},
+			"signed_metadata": signMetadata(t, pki.leafKey, map[string]any{"x5c": x5c}, claims()),
+		}
+
+		result := testVerifier(pki.roots, nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusVerified, result.Status, result.Error)
+		require.Equal(t, KeySourceX5C, result.KeySource)
+		require.Equal(t, []string{"CN=issuer.example"}, result.Certificates)
+		require.Empty(t, result.Mismatches)
+		require.Equal(t, "https://issuer.example/credential", result.Claims["credential_endpoint"])
+		require.NotContains(t, result.Claims, "iss")
+	})
+
+	t.Run("reports mismatching unsigned values", func(t *testing.T) {
+		metadata := map[string]any{
+			"credential_issuer":   "https://issuer.example",
+			"credential_endpoint": "https://evil.example/credential",
+			"credential_configurations_supported": map[string]any{
+				"pid": map[string]any{"format": "mso_mdoc"},
+			},
+			"signed_metadata": signMetadata(t, pki.leafKey, map[string]any{"x5c": x5c}, claims()),
+		}
+
+		result := testVerifier(pki.roots, nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusVerified, result.Status, result.Error)
+		require.Len(t, result.Mismatches, 2)
+		require.Equal(
+			t,
+			"credential_configurations_supported/pid/format",
+			result.Mismatches[0].Field(),
+		)
+		require.Equal(t, "dc+sd-jwt", result.Mismatches[0].Signed)
+		require.Equal(t, "credential_endpoint", result.Mismatches[1].Field())
+	})
+
+	t.Run("untrusted chain", func(t *testing.T) {
+		metadata := map[string]any{
+			"credential_issuer": "https://issuer.example",
+			"signed_metadata":   signMetadata(t, pki.leafKey, map[string]any{"x5c": x5c}, claims()),
+		}
+
+		result := testVerifier(x509.NewCertPool(), nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusInvalid, result.Status)
+		require.Contains(t, result.Error, "x5c chain is not trusted")
+	})
+
+	t.Run("certificate not issued for iss", func(t *testing.T) {
+		other := claims()
+		other["iss"] = "https://other.example"
+		metadata := map[string]any{
+			"credential_issuer": "https://issuer.example",
+			"signed_metadata":   signMetadata(t, pki.leafKey, map[string]any{"x5c": x5c}, other),
+		}
+
+		result := testVerifier(pki.roots, nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusInvalid, result.Status)
+		require.Contains(t, result.Error, "not issued for iss")
+	})
+
+	t.Run("sub does not match credential issuer", func(t *testing.T) {
+		other := claims()
+		other["sub"] = "https://other.example"
+		metadata := map[string]any{
+			"credential_issuer": "https://issuer.example",
+			"signed_metadata":   signMetadata(t, pki.leafKey, map[string]any{"x5c": x5c}, other),
+		}
+
+		result := testVerifier(pki.roots, nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusInvalid, result.Status)
+		require.Contains(t, result.Error, "does not match credential issuer")
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		metadata := map[string]any{
+			"credential_issuer": "https://issuer.example",
+			"signed_metadata":   signMetadata(t, otherKey, map[string]any{"x5c": x5c}, claims()),
+		}
+
+		result := testVerifier(pki.roots, nil).Verify(context.Background(), metadata, "")
+		require.Equal(t, StatusInvalid, result.Status)
+		require.Contains(t, result.Error, "signature verification failed")
+	})
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := federation.NewJWK("k1", &key.PublicKey)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/jwt-vc-issuer/tenant":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":   server.URL + "/tenant",
+				"jwks_uri": server.URL + "/jwks",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(federation.JWKSet{Keys: []federation.JWK{jwk}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	issuer := server.URL + "/tenant"
+	claims := jwt.MapClaims{
+		"iss":     issuer,
+		"sub":     issuer,
+		"iat":     signedMetadataTestNow.Unix(),
+		"display": []any{map[string]any{"name": "Tenant"}},
+	}
+
+	t.Run("verified with the issuer jwks", func(t *testing.T) {
+		metadata := map[string]any{
+			"display":         []any{map[string]any{"name": "Tenant"}},
+			"signed_metadata": signMetadata(t, key, map[string]any{"kid": "k1"}, claims),
+		}
+
+		result := testVerifier(nil, server.Client()).Verify(context.Background(), metadata, issuer)
+		require.Equal(t, StatusVerified, result.Status, result.Error)
+		require.Equal(t, KeySourceJWKS, result.KeySource)
+		require.Empty(t, result.Mismatches)
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		metadata := map[string]any{
+			"signed_metadata": signMetadata(t, key, map[string]any{"kid": "k2"}, claims),
+		}
+
+		result := testVerifier(nil, server.Client()).Verify(context.Background(), metadata, issuer)
+		require.Equal(t, StatusInvalid, result.Status)
+		require.Contains(t, result.Error, `no key with kid "k2"`)
+	})
+}
+
+func TestVerifyAbsentAndMalformed(t *testing.T) {
+	verifier := testVerifier(nil, nil)
+
+	result := verifier.Verify(context.Background(), map[string]any{}, "https://issuer.example")
+	require.Equal(t, StatusAbsent, result.Status)
+	require.Equal(t, "2026-05-04T10:00:00Z", result.CheckedAt)
+
+	result = verifier.Verify(
+		context.Background(),
+		map[string]any{"signed_metadata": 42},
+		"https://issuer.example",
+	)
+	require.Equal(t, StatusInvalid, result.Status)
+	require.Contains(t, result.Error, "must be a JWT string")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": "x", "sub": "x"})
+	token.Header["typ"] = "JWT"
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	result = verifier.Verify(
+		context.Background(),
+		map[string]any{"signed_metadata": raw},
+		"https://issuer.example",
+	)
+	require.Equal(t, StatusInvalid, result.Status)
+	require.Contains(t, result.Error, "unexpected typ")
+}
+
+func TestCompare(t *testing.T) {
+	mismatches := Compare(
+		map[string]any{
+			"nonce_endpoint": "https://issuer.example/nonce",
+			"batch_size":     float64(2),
+			"display":        []any{"a"},
+		},
+		map[string]any{
+			"batch_size": 2,
+			"display":    []any{"b"},
+		},
+	)
+	require.Len(t, mismatches, 1)
+	require.Equal(t, "display", mismatches[0].Field())
+}
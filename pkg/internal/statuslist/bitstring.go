@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// W3C status list credential subject types.
+const (
+	TypeBitstringStatusList = "BitstringStatusList"
+	TypeStatusList2021      = "StatusList2021"
+)
+
+// parseStatusListCredential verifies a W3C Bitstring Status List or
+// StatusList2021 credential, secured either as a JWT or with an embedded Data
+// Integrity proof, and decodes its list.
+func parseStatusListCredential(
+	data []byte,
+	format string,
+	keys Keys,
+	now time.Time,
+) (*statusList, error) {
+	trimmed := bytes.TrimSpace(data)
+	var credential map[string]any
+	var signature SignatureReport
+	encoding := "json-ld"
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &credential); err != nil {
+			return nil, fmt.Errorf("invalid status list credential: %w", err)
+		}
+		report, err := verifyDataIntegrity(credential, keys, now)
+		if err != nil {
+			return nil, &SignatureError{Err: err}
+		}
+		signature = report
+	} else {
+		var err error
+		encoding = "jwt"
+		credential, signature, err = verifyCredentialJWT(string(trimmed), keys, now)
+		if err != nil {
+			return nil, &SignatureError{Err: err}
+		}
+	}
+
+	list := &statusList{Encoding: encoding, Signature: signature}
+	list.Subject, _ = credential["id"].(string)
+	list.Issuer = credentialIssuer(credential)
+	list.IssuedAt = credentialTime(credential, "validFrom", "issuanceDate")
+	list.ExpiresAt = credentialTime(credential, "validUntil", "expirationDate")
+	if !list.ExpiresAt.IsZero() && now.After(list.ExpiresAt) {
+		return nil, &SignatureError{Err: errors.New("status list credential is expired")}
+	}
+	if ttl, ok := credential["ttl"].(float64); ok && ttl > 0 {
+		list.TTL = time.Duration(ttl) * time.Millisecond
+	}
+
+	subject, ok := credential["credentialSubject"].(map[string]any)
+	if !ok {
+		return nil, errors.New("status list credential has no credentialSubject")
+	}
+	subjectType, _ := subject["type"].(string)
+	wantType := TypeBitstringStatusList
+	if format == FormatStatusList2021 {
+		wantType = TypeStatusList2021
+	}
+	if subjectType != wantType {
+		return nil, fmt.Errorf("credentialSubject type is %q, want %q", subjectType, wantType)
+	}
+	switch purpose := subject["statusPurpose"].(type) {
+	case string:
+		list.Purpose = purpose
+	case []any:
+		if len(purpose) > 0 {
+			list.Purpose, _ = purpose[0].(string)
+		}
+	}
+	if ttl, ok := subject["ttl"].(float64); ok && ttl > 0 {
+		list.TTL = time.Duration(ttl) * time.Millisecond
+	}
+	list.Messages = statusMessages(subject["statusMessages"])
+
+	encoded, _ := subject["encodedList"].(string)
+	if encoded == "" {
+		return nil, errors.New("credentialSubject has no encodedList")
+	}
+	compressed, err := decodeEncodedList(encoded, format)
+	if err != nil {
+		return nil, err
+	}
+	data, err = inflateGzip(compressed)
+	if err != nil {
+		return nil, err
+	}
+	list.list = bitList{bits: 1, data: data, msbFirst: true}
+	return list, nil
+}
+
+// decodeEncodedList decodes the base64url encodedList, which Bitstring
+// Status Lists prefix with the multibase "u" header.
+func decodeEncodedList(encoded, format string) ([]byte, error) {
+	if format == FormatBitstringStatusList {
+		if !strings.HasPrefix(encoded, "u") {
+			return nil, errors.New("encodedList must be multibase base64url encoded")
+		}
+		encoded = encoded[1:]
+	}
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, fmt.Errorf("encodedList: %w", err)
+	}
+	return compressed, nil
+}
+
+// verifyCredentialJWT verifies a VC secured as a JWT. VC Data Model 2.0
+// JWTs carry the credential as claims, 1.1 ones in the vc claim.
+func verifyCredentialJWT(
+	raw string,
+	keys Keys,
+	now time.Time,
+) (map[string]any, SignatureReport, error) {
+	claims := jwt.MapClaims{}
+	var report SignatureReport
+	keyFunc := func(token *jwt.Token) (any, error) {
+		chain, err := parseX5C(token.Header["x5c"])
+		if err != nil {
+			return nil, err
+		}
+		kid, _ := token.Header["kid"].(string)
+		signing, err := keys.resolveKey(kid, chain, jwtCredentialIssuer(claims), now)
+		if err != nil {
+			return nil, err
+		}
+		report = signing.report
+		report.Algorithm = token.Method.Alg()
+		return signing.key, nil
+	}
+	_, err := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	).ParseWithClaims(raw, claims, keyFunc)
+	if err != nil {
+		return nil, SignatureReport{}, err
+	}
+	report.Verified = true
+	if vc, ok := claims["vc"].(map[string]any); ok {
+		return vc, report, nil
+	}
+	return claims, report, nil
+}
+
+func jwtCredentialIssuer(claims jwt.MapClaims) string {
+	if iss, ok := claims["iss"].(string); ok {
+		return iss
+	}
+	if vc, ok := claims["vc"].(map[string]any); ok {
+		return credentialIssuer(vc)
+	}
+	return credentialIssuer(claims)
+}
+
+// credentialIssuer returns the issuer id, given as a string or an object.
+func credentialIssuer(credential map[string]any) string {
+	switch issuer := credential["issuer"].(type) {
+	case string:
+		return issuer
+	case map[string]any:
+		id, _ := issuer["id"].(string)
+		return id
+	}
+	return ""
+}
+
+func credentialTime(credential map[string]any, names ...string) time.Time {
+	for _, name := range names {
+		value, _ := credential[name].(string)
+		if value == "" {
+			continue
+		}
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// statusMessages indexes the statusMessages of a message status list by
+// status value.
+func statusMessages(value any) map[int]string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	messages := map[int]string{}
+	for _, item := range items {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		status, _ := entry["status"].(string)
+		message, _ := entry["message"].(string)
+		parsed, err := strconv.ParseInt(strings.TrimPrefix(status, "0x"), 16, 64)
+		if err != nil {
+			continue
+		}
+		messages[int(parsed)] = message
+	}
+	return messages
+}
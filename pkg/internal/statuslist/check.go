@@ -0,0 +1,298 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a fetched list is reused when neither the list
+// nor the response says how long it stays fresh.
+const DefaultCacheTTL = 5 * time.Minute
+
+const maxResponseSize = 16 << 20
+
+// FetchError reports a status list that could not be downloaded.
+type FetchError struct {
+	URL string
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("fetch status list %s: %v", e.URL, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// SignatureError reports a status list whose signature, issuer key or
+// validity period could not be verified.
+type SignatureError struct {
+	Err error
+}
+
+func (e *SignatureError) Error() string {
+	return "status list signature: " + e.Err.Error()
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// Result is the status of a credential.
+type Result struct {
+	Reference Reference       `json:"reference"`
+	Status    int             `json:"status"`
+	Label     string          `json:"label"`
+	Message   string          `json:"message,omitempty"`
+	Encoding  string          `json:"encoding"`
+	Bits      int             `json:"bits"`
+	ListSize  int             `json:"list_size"`
+	Issuer    string          `json:"issuer,omitempty"`
+	IssuedAt  time.Time       `json:"issued_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Cached    bool            `json:"cached"`
+	Signature SignatureReport `json:"signature"`
+}
+
+// Cache keeps fetched status lists until they go stale. It is safe for
+// concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	body      []byte
+	fetchedAt time.Time
+	expires   time.Time
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{entries: map[string]cacheEntry{}}
+}
+
+func (c *Cache) get(key string, now time.Time) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !now.Before(entry.expires) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *Cache) put(key string, entry cacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+// Checker resolves the status of credentials.
+type Checker struct {
+	HTTPClient *http.Client
+	Cache      *Cache
+	Keys       Keys
+	// NoCache forces the list to be downloaded again, to observe a status
+	// change right after it happened.
+	NoCache bool
+	Now     func() time.Time
+}
+
+// Check fetches, verifies and reads the status list entry ref points to.
+func (c *Checker) Check(ctx context.Context, ref Reference) (Result, error) {
+	if err := ref.Validate(); err != nil {
+		return Result{}, err
+	}
+	now := c.now()
+	key := ref.Format + " " + ref.URI
+
+	entry, cached := cacheEntry{}, false
+	if !c.NoCache {
+		entry, cached = c.Cache.get(key, now)
+	}
+	var maxAge time.Duration
+	if !cached {
+		body, age, err := c.fetch(ctx, ref)
+		if err != nil {
+			return Result{}, err
+		}
+		entry = cacheEntry{body: body, fetchedAt: now}
+		maxAge = age
+	}
+
+	var list *statusList
+	var err error
+	if ref.Format == FormatTokenStatusList {
+		list, err = parseToken(entry.body, c.Keys, now)
+	} else {
+		list, err = parseStatusListCredential(entry.body, ref.Format, c.Keys, now)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if ref.Format == FormatTokenStatusList && list.Subject != "" && list.Subject != ref.URI {
+		return Result{}, &SignatureError{
+			Err: fmt.Errorf("status list sub %q does not match %q", list.Subject, ref.URI),
+		}
+	}
+	if !cached {
+		c.Cache.put(key, cacheEntry{
+			body:      entry.body,
+			fetchedAt: entry.fetchedAt,
+			expires:   cacheExpiry(now, list, maxAge),
+		})
+	}
+
+	purpose := ref.Purpose
+	if ref.Format != FormatTokenStatusList {
+		if purpose == "" {
+			purpose = list.Purpose
+		} else if list.Purpose != "" && purpose != list.Purpose {
+			return Result{}, fmt.Errorf(
+				"status list purpose is %q, the credential refers to %q",
+				list.Purpose,
+				purpose,
+			)
+		}
+		size := ref.StatusSize
+		if size == 0 {
+			size = 1
+		}
+		if list.list, err = newBitList(size, list.list.data, true); err != nil {
+			return Result{}, err
+		}
+	}
+
+	value, err := list.list.value(ref.Index)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{
+		Reference: ref,
+		Status:    value,
+		Encoding:  list.Encoding,
+		Bits:      list.list.bits,
+		ListSize:  list.list.size(),
+		Issuer:    list.Issuer,
+		IssuedAt:  list.IssuedAt,
+		ExpiresAt: list.ExpiresAt,
+		FetchedAt: entry.fetchedAt,
+		Cached:    cached,
+		Signature: list.Signature,
+	}
+	result.Reference.Purpose = purpose
+	if ref.Format == FormatTokenStatusList {
+		result.Label = tokenStatusLabel(value)
+	} else {
+		result.Label = purposeStatusLabel(purpose, value)
+		result.Message = list.Messages[value]
+	}
+	return result, nil
+}
+
+func (c *Checker) fetch(ctx context.Context, ref Reference) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URI, nil)
+	if err != nil {
+		return nil, 0, &FetchError{URL: ref.URI, Err: err}
+	}
+	if ref.Format == FormatTokenStatusList {
+		req.Header.Set("Accept", MediaTypeStatusListJWT+", "+MediaTypeStatusListCWT)
+	} else {
+		req.Header.Set(
+			"Accept",
+			"application/vc+jwt, application/vc+ld+json, application/ld+json, application/json",
+		)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, &FetchError{URL: ref.URI, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, &FetchError{
+			URL: ref.URI,
+			Err: fmt.Errorf("unexpected status %d", resp.StatusCode),
+		}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, 0, &FetchError{URL: ref.URI, Err: err}
+	}
+	if len(body) > maxResponseSize {
+		return nil, 0, &FetchError{URL: ref.URI, Err: fmt.Errorf("response exceeds 16MB")}
+	}
+	return body, maxAge(resp.Header.Get("Cache-Control")), nil
+}
+
+// maxAge reads the max-age directive of a Cache-Control header. It is
+// negative when no-store or no-cache forbid reusing the response.
+func maxAge(header string) time.Duration {
+	var age time.Duration
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return -1
+		case "max-age":
+			seconds, err := strconv.Atoi(value)
+			if err == nil && seconds >= 0 {
+				age = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return age
+}
+
+// cacheExpiry keeps a list for its ttl, else the response max-age, else
+// DefaultCacheTTL, and never past its expiry.
+func cacheExpiry(now time.Time, list *statusList, responseMaxAge time.Duration) time.Time {
+	ttl := DefaultCacheTTL
+	switch {
+	case list.TTL > 0:
+		ttl = list.TTL
+	case responseMaxAge < 0:
+		return now
+	case responseMaxAge > 0:
+		ttl = responseMaxAge
+	}
+	expires := now.Add(ttl)
+	if !list.ExpiresAt.IsZero() && list.ExpiresAt.Before(expires) {
+		expires = list.ExpiresAt
+	}
+	return expires
+}
+
+func (c *Checker) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,545 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// statusServer serves status lists by path and counts their downloads.
+type statusServer struct {
+	*httptest.Server
+	mu            sync.Mutex
+	bodies        map[string][]byte
+	cacheControls map[string]string
+	hits          map[string]int
+}
+
+func newStatusServer(t testing.TB) *statusServer {
+	t.Helper()
+	s := &statusServer{
+		bodies:        map[string][]byte{},
+		cacheControls: map[string]string{},
+		hits:          map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		body, ok := s.bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		s.hits[r.URL.Path]++
+		if cacheControl := s.cacheControls[r.URL.Path]; cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *statusServer) serve(path string, body []byte, cacheControl string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies[path] = body
+	s.cacheControls[path] = cacheControl
+}
+
+func (s *statusServer) hitCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+// packTokenList packs statuses as a Token Status List, least significant bits
+// first.
+func packTokenList(bits int, statuses []int) []byte {
+	data := make([]byte, (len(statuses)*bits+7)/8)
+	for index, status := range statuses {
+		position := index * bits
+		data[position/8] |= byte(status << (position % 8))
+	}
+	return data
+}
+
+// packBitstring packs statuses of size bits each, most significant bit first,
+// into a list of entries statuses.
+func packBitstring(size, entries int, statuses map[int]int) []byte {
+	data := make([]byte, (entries*size+7)/8)
+	for index, status := range statuses {
+		for bit := range size {
+			if status>>(size-1-bit)&1 == 1 {
+				position := index*size + bit
+				data[position/8] |= 1 << (7 - position%8)
+			}
+		}
+	}
+	return data
+}
+
+func newCertificate(
+	t testing.TB,
+	name string,
+	key crypto.Signer,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func newECKey(t testing.TB) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func signJWT(
+	t testing.TB,
+	claims jwt.MapClaims,
+	header map[string]any,
+	key crypto.Signer,
+) []byte {
+	t.Helper()
+	method := jwt.SigningMethod(jwt.SigningMethodES256)
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		method = jwt.SigningMethodEdDSA
+	}
+	token := jwt.NewWithClaims(method, claims)
+	for name, value := range header {
+		token.Header[name] = value
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return []byte(signed)
+}
+
+func signCWT(t testing.TB, claims map[any]any, key ed25519.PrivateKey, kid string) []byte {
+	t.Helper()
+	payload, err := cbor.Encode(claims)
+	require.NoError(t, err)
+	protected, err := cbor.Encode(map[any]any{
+		int64(cose.HeaderAlg): int64(-8),
+		int64(cose.HeaderTyp): MediaTypeStatusListCWT,
+	})
+	require.NoError(t, err)
+	toBeSigned, err := cbor.Encode([]any{"Signature1", protected, []byte{}, payload})
+	require.NoError(t, err)
+	encoded, err := cbor.Encode(cbor.Tag{Number: cose.TagSign1, Content: []any{
+		protected,
+		map[any]any{int64(cose.HeaderKid): []byte(kid)},
+		payload,
+		ed25519.Sign(key, toBeSigned),
+	}})
+	require.NoError(t, err)
+	return encoded
+}
+
+func certificateHeader(certificates ...*x509.Certificate) []any {
+	x5c := make([]any, 0, len(certificates))
+	for _, certificate := range certificates {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(certificate.Raw))
+	}
+	return x5c
+}
+
+// tokenListClaims returns the claims of a JWT Status List Token.
+func tokenListClaims(t testing.TB, uri string, now time.Time, statuses []int) jwt.MapClaims {
+	t.Helper()
+	return jwt.MapClaims{
+		"sub": uri,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+		"ttl": 600,
+		"status_list": map[string]any{
+			"bits": 2,
+			"lst": base64.RawURLEncoding.EncodeToString(
+				zlibCompress(t, packTokenList(2, statuses)),
+			),
+		},
+	}
+}
+
+func TestCheckTokenStatusListJWT(t *testing.T) {
+	server := newStatusServer(t)
+	uri := server.URL + "/tsl/1"
+	clock := time.Now().Truncate(time.Second)
+
+	caKey, leafKey := newECKey(t), newECKey(t)
+	ca := newCertificate(t, "Status CA", caKey, nil, nil)
+	leaf := newCertificate(t, "Status Issuer", leafKey, ca, caKey)
+	header := map[string]any{"typ": "statuslist+jwt", "x5c": certificateHeader(leaf, ca)}
+	statuses := []int{0, 1, 2, 3, 0, 0, 0, 0}
+	publish := func() {
+		claims := tokenListClaims(t, uri, clock, statuses)
+		server.serve("/tsl/1", signJWT(t, claims, header, leafKey), "")
+	}
+	publish()
+
+	checker := &Checker{
+		HTTPClient: server.Client(),
+		Cache:      NewCache(),
+		Keys:       Keys{TrustedCertificates: []*x509.Certificate{ca}},
+		Now:        func() time.Time { return clock },
+	}
+	for index, want := range []string{
+		StatusValid,
+		StatusInvalid,
+		StatusSuspended,
+		StatusApplicationSpecific,
+	} {
+		result, err := checker.Check(
+			context.Background(),
+			Reference{Format: FormatTokenStatusList, URI: uri, Index: index},
+		)
+		require.NoError(t, err)
+		require.Equal(t, index, result.Status)
+		require.Equal(t, want, result.Label)
+		require.Equal(t, index > 0, result.Cached)
+		require.Equal(t, "jwt", result.Encoding)
+		require.Equal(t, 2, result.Bits)
+		require.Equal(t, 8, result.ListSize)
+		require.Equal(t, clock.Add(time.Hour).UTC(), result.ExpiresAt)
+		require.Equal(t, SignatureReport{
+			Verified:  true,
+			Trusted:   true,
+			Algorithm: "ES256",
+			KeySource: KeySourceX5C,
+			Signer:    "CN=Status Issuer",
+		}, result.Signature)
+	}
+	require.Equal(t, 1, server.hitCount("/tsl/1"))
+
+	// The issuer revokes the first credential.
+	statuses[0] = 1
+	publish()
+	first := Reference{Format: FormatTokenStatusList, URI: uri}
+
+	result, err := checker.Check(context.Background(), first)
+	require.NoError(t, err)
+	require.True(t, result.Cached)
+	require.Equal(t, StatusValid, result.Label, "the cached list is still fresh")
+
+	checker.NoCache = true
+	result, err = checker.Check(context.Background(), first)
+	require.NoError(t, err)
+	require.False(t, result.Cached)
+	require.Equal(t, StatusInvalid, result.Label)
+	require.Equal(t, 2, server.hitCount("/tsl/1"))
+
+	// Once the ttl elapses the list is downloaded again.
+	checker.NoCache = false
+	clock = clock.Add(11 * time.Minute)
+	result, err = checker.Check(context.Background(), first)
+	require.NoError(t, err)
+	require.False(t, result.Cached)
+	require.Equal(t, 3, server.hitCount("/tsl/1"))
+}
+
+func TestCheckTokenStatusListCWT(t *testing.T) {
+	server := newStatusServer(t)
+	uri := server.URL + "/tsl/cwt"
+	now := time.Now()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwk, err := federation.NewJWK("status-key", pub)
+	require.NoError(t, err)
+	server.serve("/tsl/cwt", signCWT(t, map[any]any{
+		int64(cwtClaimSub): uri,
+		int64(cwtClaimIat): now.Unix(),
+		int64(cwtClaimExp): now.Add(time.Hour).Unix(),
+		int64(cwtClaimStatusList): map[any]any{
+			"bits": int64(1),
+			"lst":  zlibCompress(t, packTokenList(1, []int{0, 0, 0, 1})),
+		},
+	}, priv, "status-key"), "no-store")
+
+	checker := &Checker{
+		HTTPClient: server.Client(),
+		Cache:      NewCache(),
+		Keys:       Keys{JWKS: &federation.JWKSet{Keys: []federation.JWK{jwk}}},
+	}
+	for _, index := range []int{3, 0} {
+		result, err := checker.Check(
+			context.Background(),
+			Reference{Format: FormatTokenStatusList, URI: uri, Index: index},
+		)
+		require.NoError(t, err)
+		require.Equal(t, index == 3, result.Label == StatusInvalid)
+		require.False(t, result.Cached, "the response must not be stored")
+		require.Equal(t, "cwt", result.Encoding)
+		require.Equal(t, SignatureReport{
+			Verified:  true,
+			Trusted:   true,
+			Algorithm: "EdDSA",
+			KeySource: KeySourceJWKS,
+			KeyID:     "status-key",
+		}, result.Signature)
+	}
+	require.Equal(t, 2, server.hitCount("/tsl/cwt"))
+}
+
+func TestCheckBitstringStatusList(t *testing.T) {
+	server := newStatusServer(t)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	did := didKey(t, pub)
+
+	serveCredential := func(path string, subject map[string]any) string {
+		credential := testCredential(did)
+		credential["id"] = server.URL + path
+		credential["validFrom"] = "2026-01-01T00:00:00Z"
+		credential["credentialSubject"] = subject
+		signed, err := json.Marshal(signDataIntegrity(t, credential, priv, did+"#key-1"))
+		require.NoError(t, err)
+		server.serve(path, signed, "")
+		return server.URL + path
+	}
+	encode := func(data []byte) string {
+		return "u" + base64.RawURLEncoding.EncodeToString(gzipCompress(t, data))
+	}
+	revocation := serveCredential("/revocation", map[string]any{
+		"type":          TypeBitstringStatusList,
+		"statusPurpose": PurposeRevocation,
+		"encodedList":   encode(packBitstring(1, 131072, map[int]int{94567: 1})),
+	})
+	messages := serveCredential("/messages", map[string]any{
+		"type":          TypeBitstringStatusList,
+		"statusPurpose": PurposeMessage,
+		"statusMessages": []any{
+			map[string]any{"status": "0x0", "message": "pending_review"},
+			map[string]any{"status": "0x1", "message": "accepted"},
+			map[string]any{"status": "0x2", "message": "rejected"},
+		},
+		"encodedList": encode(packBitstring(2, 65536, map[int]int{42: 2})),
+	})
+
+	checker := &Checker{HTTPClient: server.Client(), Cache: NewCache()}
+	result, err := checker.Check(context.Background(), Reference{
+		Format: FormatBitstringStatusList,
+		URI:    revocation,
+		Index:  94567,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Status)
+	require.Equal(t, StatusInvalid, result.Label)
+	require.Equal(t, PurposeRevocation, result.Reference.Purpose)
+	require.Equal(t, "json-ld", result.Encoding)
+	require.Equal(t, did, result.Issuer)
+	require.Equal(t, 131072, result.ListSize)
+	require.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), result.IssuedAt)
+	require.True(t, result.Signature.Verified)
+	require.True(t, result.Signature.Trusted)
+	require.Equal(t, CryptosuiteEdDSAJCS2022, result.Signature.Algorithm)
+
+	result, err = checker.Check(context.Background(), Reference{
+		Format:  FormatBitstringStatusList,
+		URI:     revocation,
+		Index:   94566,
+		Purpose: PurposeRevocation,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusValid, result.Label)
+	require.True(t, result.Cached)
+
+	_, err = checker.Check(context.Background(), Reference{
+		Format:  FormatBitstringStatusList,
+		URI:     revocation,
+		Index:   1,
+		Purpose: PurposeSuspension,
+	})
+	require.ErrorContains(t, err, `status list purpose is "revocation"`)
+
+	result, err = checker.Check(context.Background(), Reference{
+		Format:     FormatBitstringStatusList,
+		URI:        messages,
+		Index:      42,
+		StatusSize: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Status)
+	require.Equal(t, StatusApplicationSpecific, result.Label)
+	require.Equal(t, "rejected", result.Message)
+	require.Equal(t, 2, result.Bits)
+	require.Equal(t, 65536, result.ListSize)
+}
+
+func TestCheckStatusList2021JWT(t *testing.T) {
+	server := newStatusServer(t)
+	key := newECKey(t)
+	jwk, err := federation.NewJWK("", &key.PublicKey)
+	require.NoError(t, err)
+	encodedJWK, err := json.Marshal(jwk)
+	require.NoError(t, err)
+	did := "did:jwk:" + base64.RawURLEncoding.EncodeToString(encodedJWK)
+
+	list := packBitstring(1, 16384, map[int]int{3: 1})
+	server.serve("/2021", signJWT(t, jwt.MapClaims{
+		"iss": did,
+		"nbf": time.Now().Unix(),
+		"vc": map[string]any{
+			"@context": []any{"https://www.w3.org/2018/credentials/v1"},
+			"type":     []any{"VerifiableCredential", "StatusList2021Credential"},
+			"issuer":   did,
+			"credentialSubject": map[string]any{
+				"type":          TypeStatusList2021,
+				"statusPurpose": PurposeSuspension,
+				"encodedList": base64.RawURLEncoding.EncodeToString(
+					gzipCompress(t, list),
+				),
+			},
+		},
+	}, map[string]any{"kid": did + "#0"}, key), "")
+
+	checker := &Checker{HTTPClient: server.Client()}
+	result, err := checker.Check(context.Background(), Reference{
+		Format: FormatStatusList2021,
+		URI:    server.URL + "/2021",
+		Index:  3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusSuspended, result.Label)
+	require.Equal(t, "jwt", result.Encoding)
+	require.Equal(t, SignatureReport{
+		Verified:  true,
+		Trusted:   true,
+		Algorithm: "ES256",
+		KeySource: KeySourceDID,
+		KeyID:     did + "#0",
+		Signer:    did,
+	}, result.Signature)
+}
+
+func TestCheckFailures(t *testing.T) {
+	server := newStatusServer(t)
+	now := time.Now()
+	caKey, leafKey := newECKey(t), newECKey(t)
+	ca := newCertificate(t, "Status CA", caKey, nil, nil)
+	leaf := newCertificate(t, "Status Issuer", leafKey, ca, caKey)
+	otherKey := newECKey(t)
+	otherCA := newCertificate(t, "Other CA", otherKey, nil, nil)
+	header := map[string]any{"typ": "statuslist+jwt", "x5c": certificateHeader(leaf, ca)}
+	statuses := []int{0, 1}
+
+	serveToken := func(path string, claims jwt.MapClaims, header map[string]any) {
+		if _, ok := claims["sub"]; !ok {
+			claims["sub"] = server.URL + path
+		}
+		server.serve(path, signJWT(t, claims, header, leafKey), "")
+	}
+	serveToken("/valid", tokenListClaims(t, server.URL+"/valid", now, statuses), header)
+	serveToken("/other-sub", tokenListClaims(t, server.URL+"/elsewhere", now, statuses), header)
+	expired := tokenListClaims(t, server.URL+"/expired", now, statuses)
+	expired["exp"] = now.Add(-time.Minute).Unix()
+	serveToken("/expired", expired, header)
+	serveToken(
+		"/wrong-typ",
+		tokenListClaims(t, server.URL+"/wrong-typ", now, statuses),
+		map[string]any{"typ": "JWT", "x5c": certificateHeader(leaf, ca)},
+	)
+	claims := tokenListClaims(t, server.URL+"/tampered", now, statuses)
+	parts := strings.Split(string(signJWT(t, claims, header, leafKey)), ".")
+	forged := tokenListClaims(t, server.URL+"/tampered", now, []int{0, 0})
+	payload, err := json.Marshal(forged)
+	require.NoError(t, err)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	server.serve("/tampered", []byte(strings.Join(parts, ".")), "")
+
+	trusted := Keys{TrustedCertificates: []*x509.Certificate{ca}}
+	tests := []struct {
+		name      string
+		path      string
+		index     int
+		keys      Keys
+		signature bool
+		fetch     bool
+		want      string
+	}{
+		{name: "not found", path: "/missing", keys: trusted, fetch: true, want: "status 404"},
+		{
+			name:      "untrusted chain",
+			path:      "/valid",
+			keys:      Keys{TrustedCertificates: []*x509.Certificate{otherCA}},
+			signature: true,
+			want:      "untrusted certificate chain",
+		},
+		{name: "sub mismatch", path: "/other-sub", keys: trusted, signature: true, want: "sub"},
+		{name: "expired", path: "/expired", keys: trusted, signature: true, want: "expired"},
+		{name: "wrong typ", path: "/wrong-typ", keys: trusted, signature: true, want: "typ"},
+		{name: "tampered", path: "/tampered", keys: trusted, signature: true, want: "signature"},
+		{
+			name:  "index out of range",
+			path:  "/valid",
+			index: 8,
+			keys:  trusted,
+			want:  "out of the list range",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &Checker{HTTPClient: server.Client(), Keys: tt.keys}
+			_, err := checker.Check(context.Background(), Reference{
+				Format: FormatTokenStatusList,
+				URI:    server.URL + tt.path,
+				Index:  tt.index,
+			})
+			require.ErrorContains(t, err, tt.want)
+			var signatureErr *SignatureError
+			require.Equal(t, tt.signature, errors.As(err, &signatureErr))
+			var fetchErr *FetchError
+			require.Equal(t, tt.fetch, errors.As(err, &fetchErr))
+		})
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	require.Equal(t, 30*time.Second, maxAge("public, max-age=30"))
+	require.Equal(t, time.Duration(-1), maxAge("max-age=30, no-store"))
+	require.Equal(t, time.Duration(0), maxAge(""))
+	require.Equal(t, time.Duration(0), maxAge("max-age=soon"))
+}
@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+)
+
+// Data Integrity cryptosuites that can be verified without RDF
+// canonicalization.
+const (
+	CryptosuiteEdDSAJCS2022 = "eddsa-jcs-2022"
+	CryptosuiteECDSAJCS2019 = "ecdsa-jcs-2019"
+)
+
+// verifyDataIntegrity checks the embedded Data Integrity proof of a
+// credential. Only the JCS cryptosuites are supported.
+func verifyDataIntegrity(
+	credential map[string]any,
+	keys Keys,
+	now time.Time,
+) (SignatureReport, error) {
+	proof, err := assertionProof(credential["proof"])
+	if err != nil {
+		return SignatureReport{}, err
+	}
+	proofType, _ := proof["type"].(string)
+	suite, _ := proof["cryptosuite"].(string)
+	if proofType != "DataIntegrityProof" ||
+		(suite != CryptosuiteEdDSAJCS2022 && suite != CryptosuiteECDSAJCS2019) {
+		return SignatureReport{}, fmt.Errorf(
+			"unsupported proof %s %s: only %s and %s can be verified",
+			proofType,
+			suite,
+			CryptosuiteEdDSAJCS2022,
+			CryptosuiteECDSAJCS2019,
+		)
+	}
+	if expires, _ := proof["expires"].(string); expires != "" {
+		if parsed, err := time.Parse(time.RFC3339, expires); err == nil && now.After(parsed) {
+			return SignatureReport{}, errors.New("proof is expired")
+		}
+	}
+
+	proofValue, _ := proof["proofValue"].(string)
+	if !strings.HasPrefix(proofValue, "z") {
+		return SignatureReport{}, errors.New("proofValue must be base58btc encoded")
+	}
+	signature, err := didresolver.DecodeBase58(proofValue[1:])
+	if err != nil {
+		return SignatureReport{}, fmt.Errorf("proofValue: %w", err)
+	}
+
+	method, _ := proof["verificationMethod"].(string)
+	signing, err := keys.resolveKey(method, nil, credentialIssuer(credential), now)
+	if err != nil {
+		return SignatureReport{}, err
+	}
+
+	document := map[string]any{}
+	for name, value := range credential {
+		if name != "proof" {
+			document[name] = value
+		}
+	}
+	config := map[string]any{}
+	for name, value := range proof {
+		if name != "proofValue" {
+			config[name] = value
+		}
+	}
+	if context, ok := credential["@context"]; ok {
+		config["@context"] = context
+	}
+
+	hashData, hash, err := dataIntegrityHashData(document, config, signing.key)
+	if err != nil {
+		return SignatureReport{}, err
+	}
+	switch key := signing.key.(type) {
+	case ed25519.PublicKey:
+		if suite != CryptosuiteEdDSAJCS2022 || !ed25519.Verify(key, hashData, signature) {
+			return SignatureReport{}, errors.New("invalid proof")
+		}
+	case *ecdsa.PublicKey:
+		algorithm := "ES256"
+		if hash == crypto.SHA384 {
+			algorithm = "ES384"
+		}
+		if suite != CryptosuiteECDSAJCS2019 {
+			return SignatureReport{}, errors.New("invalid proof")
+		}
+		if err := cose.VerifySignature(algorithm, key, hashData, signature); err != nil {
+			return SignatureReport{}, errors.New("invalid proof")
+		}
+	default:
+		return SignatureReport{}, fmt.Errorf("unsupported proof key type %T", signing.key)
+	}
+
+	report := signing.report
+	report.Verified = true
+	report.Algorithm = suite
+	return report, nil
+}
+
+// assertionProof returns the assertionMethod proof of a credential.
+func assertionProof(value any) (map[string]any, error) {
+	switch proof := value.(type) {
+	case map[string]any:
+		return proof, nil
+	case []any:
+		for _, item := range proof {
+			candidate, ok := item.(map[string]any)
+			if ok && candidate["proofPurpose"] == "assertionMethod" {
+				return candidate, nil
+			}
+		}
+	case nil:
+		return nil, errors.New("status list credential has no proof")
+	}
+	return nil, errors.New("status list credential has no assertionMethod proof")
+}
+
+// dataIntegrityHashData hashes the canonical proof configuration and
+// document, with SHA-384 for P-384 keys and SHA-256 otherwise.
+func dataIntegrityHashData(
+	document, config map[string]any,
+	key crypto.PublicKey,
+) ([]byte, crypto.Hash, error) {
+	canonicalDocument, err := canonicalJSON(document)
+	if err != nil {
+		return nil, 0, err
+	}
+	canonicalConfig, err := canonicalJSON(config)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ec, ok := key.(*ecdsa.PublicKey); ok && ec.Curve.Params().BitSize == 384 {
+		configHash := sha512.Sum384(canonicalConfig)
+		documentHash := sha512.Sum384(canonicalDocument)
+		return append(configHash[:], documentHash[:]...), crypto.SHA384, nil
+	}
+	configHash := sha256.Sum256(canonicalConfig)
+	documentHash := sha256.Sum256(canonicalDocument)
+	return append(configHash[:], documentHash[:]...), crypto.SHA256, nil
+}
+
+// canonicalJSON serializes value with the JSON Canonicalization Scheme
+// (RFC 8785).
+func canonicalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float64:
+		formatted, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(formatted)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		return writeCanonicalJSON(buf, f)
+	case string:
+		writeCanonicalString(buf, v)
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return lessUTF16(names[i], names[j])
+		})
+		buf.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, name)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[name]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot canonicalize %T", value)
+	}
+	return nil
+}
+
+// canonicalNumber formats a number the way ECMAScript does.
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("cannot canonicalize NaN or Infinity")
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	formatted := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent, _ := strings.Cut(formatted, "e")
+	sign := exponent[:1]
+	exponent = strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + exponent, nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 orders property names by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	// Example from RFC 8785, section 3.2.2.
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "€$\u000F\u000aA'B\"\\\\\"\/",
+		"literals": [null, true, false]
+	}`
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(input), &value))
+	canonical, err := canonicalJSON(value)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],`+
+			`"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		string(canonical),
+	)
+}
+
+func TestCanonicalJSONSortsByUTF16(t *testing.T) {
+	// U+1F600 sorts before U+FB33 in UTF-16, after it in UTF-8.
+	canonical, err := canonicalJSON(map[string]any{
+		"\U0001F600": 1.0,
+		"\uFB33":     2.0,
+		"a":          3.0,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "{\"a\":3,\"\U0001F600\":1,\"\uFB33\":2}", string(canonical))
+}
+
+func didKey(t testing.TB, pub crypto.PublicKey) string {
+	t.Helper()
+	multikey, err := didresolver.EncodeMultikey(pub)
+	require.NoError(t, err)
+	return "did:key:" + multikey
+}
+
+// signDataIntegrity adds a JCS Data Integrity proof to credential.
+func signDataIntegrity(
+	t testing.TB,
+	credential map[string]any,
+	key crypto.Signer,
+	verificationMethod string,
+) map[string]any {
+	t.Helper()
+	suite := CryptosuiteEdDSAJCS2022
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		suite = CryptosuiteECDSAJCS2019
+	}
+	config := map[string]any{
+		"type":               "DataIntegrityProof",
+		"cryptosuite":        suite,
+		"created":            "2026-01-01T00:00:00Z",
+		"verificationMethod": verificationMethod,
+		"proofPurpose":       "assertionMethod",
+		"@context":           credential["@context"],
+	}
+	hashData, _, err := dataIntegrityHashData(credential, config, key.Public())
+	require.NoError(t, err)
+
+	var signature []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, hashData)
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(hashData)
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	proof := map[string]any{}
+	for name, value := range config {
+		if name != "@context" {
+			proof[name] = value
+		}
+	}
+	proof["proofValue"] = "z" + didresolver.EncodeBase58(signature)
+	signed := map[string]any{"proof": proof}
+	for name, value := range credential {
+		signed[name] = value
+	}
+	return signed
+}
+
+func testCredential(issuer string) map[string]any {
+	return map[string]any{
+		"@context": []any{"https://www.w3.org/ns/credentials/v2"},
+		"id":       "https://status.example/1",
+		"type":     []any{"VerifiableCredential", "BitstringStatusListCredential"},
+		"issuer":   issuer,
+		"credentialSubject": map[string]any{
+			"type":          TypeBitstringStatusList,
+			"statusPurpose": PurposeRevocation,
+			"encodedList":   "uH4sIAAAAAAAAA-3BMQEAAADCoPVPbQwfoAAAAAAAAAAAAAAAAAAAAIC3AYbSVKsAQAAA",
+		},
+	}
+}
+
+func roundTripJSON(t testing.TB, value map[string]any) map[string]any {
+	t.Helper()
+	encoded, err := json.Marshal(value)
+	require.NoError(t, err)
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return decoded
+}
+
+func TestVerifyDataIntegrity(t *testing.T) {
+	now := time.Now()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	did := didKey(t, pub)
+	signed := roundTripJSON(t, signDataIntegrity(t, testCredential(did), priv, did+"#key-1"))
+	report, err := verifyDataIntegrity(signed, Keys{}, now)
+	require.NoError(t, err)
+	require.True(t, report.Verified)
+	require.True(t, report.Trusted)
+	require.Equal(t, CryptosuiteEdDSAJCS2022, report.Algorithm)
+	require.Equal(t, KeySourceDID, report.KeySource)
+	require.Equal(t, did, report.Signer)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDID := didKey(t, &ecKey.PublicKey)
+	signed = roundTripJSON(
+		t,
+		signDataIntegrity(t, testCredential("did:web:issuer.example"), ecKey, ecDID),
+	)
+	report, err = verifyDataIntegrity(signed, Keys{}, now)
+	require.NoError(t, err)
+	require.Equal(t, CryptosuiteECDSAJCS2019, report.Algorithm)
+	require.False(t, report.Trusted, "the key does not belong to the issuer")
+}
+
+func TestVerifyDataIntegrityFailures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	did := didKey(t, pub)
+	signed := roundTripJSON(t, signDataIntegrity(t, testCredential(did), priv, did))
+
+	tampered := roundTripJSON(t, signed)
+	tampered["credentialSubject"].(map[string]any)["encodedList"] = "uAAAA"
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	wrongKey := roundTripJSON(t, signed)
+	wrongKey["proof"].(map[string]any)["verificationMethod"] = didKey(t, otherPub)
+
+	rdfc := roundTripJSON(t, signed)
+	rdfc["proof"].(map[string]any)["cryptosuite"] = "eddsa-rdfc-2022"
+
+	expired := roundTripJSON(t, signed)
+	expired["proof"].(map[string]any)["expires"] = "2020-01-01T00:00:00Z"
+
+	unsigned := roundTripJSON(t, signed)
+	delete(unsigned, "proof")
+
+	unknownKey := roundTripJSON(t, signed)
+	unknownKey["proof"].(map[string]any)["verificationMethod"] = "https://issuer.example/keys/1"
+
+	tests := map[string]struct {
+		credential map[string]any
+		want       string
+	}{
+		"tampered":    {tampered, "invalid proof"},
+		"wrong key":   {wrongKey, "invalid proof"},
+		"rdfc":        {rdfc, "unsupported proof"},
+		"expired":     {expired, "proof is expired"},
+		"unsigned":    {unsigned, "has no proof"},
+		"unknown key": {unknownKey, "no trusted key"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifyDataIntegrity(tt.credential, Keys{}, time.Now())
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+)
+
+// Key sources reported in SignatureReport.
+const (
+	KeySourceJWKS = "jwks"
+	KeySourceX5C  = "x5c"
+	KeySourceDID  = "did"
+)
+
+// Keys is the key material trusted to sign status lists. Without any, lists
+// signed with an embedded certificate or a did:key/did:jwk verification
+// method are still verified, but reported as not trusted.
+type Keys struct {
+	JWKS                *federation.JWKSet
+	TrustedCertificates []*x509.Certificate
+}
+
+// SignatureReport describes how the signature of a status list was checked.
+type SignatureReport struct {
+	Verified  bool   `json:"verified"`
+	Trusted   bool   `json:"trusted"`
+	Algorithm string `json:"algorithm,omitempty"`
+	KeySource string `json:"key_source,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	Signer    string `json:"signer,omitempty"`
+}
+
+// signingKey is a public key resolved for a signature and how it was found.
+type signingKey struct {
+	key    crypto.PublicKey
+	report SignatureReport
+}
+
+// resolveKey picks the key that signed a status list: a configured JWK, the
+// leaf of the embedded certificate chain or the key a DID URL kid encodes.
+// issuer is the status list issuer, to which a DID key must belong to be
+// trusted.
+func (k Keys) resolveKey(
+	kid string,
+	chain []*x509.Certificate,
+	issuer string,
+	now time.Time,
+) (*signingKey, error) {
+	if k.JWKS != nil && len(k.JWKS.Keys) > 0 {
+		if jwk, err := k.JWKS.Find(kid); err == nil {
+			key, err := jwk.PublicKey()
+			if err != nil {
+				return nil, fmt.Errorf("jwks: %w", err)
+			}
+			return &signingKey{key: key, report: SignatureReport{
+				Trusted:   true,
+				KeySource: KeySourceJWKS,
+				KeyID:     jwk.Kid,
+			}}, nil
+		}
+	}
+
+	if len(chain) > 0 {
+		trusted := false
+		if len(k.TrustedCertificates) > 0 {
+			if err := verifyChain(chain, k.TrustedCertificates, now); err != nil {
+				return nil, err
+			}
+			trusted = true
+		}
+		return &signingKey{key: chain[0].PublicKey, report: SignatureReport{
+			Trusted:   trusted,
+			KeySource: KeySourceX5C,
+			KeyID:     kid,
+			Signer:    chain[0].Subject.String(),
+		}}, nil
+	}
+
+	if strings.HasPrefix(kid, "did:") {
+		did, key, err := didresolver.StaticKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		return &signingKey{key: key, report: SignatureReport{
+			Trusted:   issuer != "" && did == issuer,
+			KeySource: KeySourceDID,
+			KeyID:     kid,
+			Signer:    did,
+		}}, nil
+	}
+
+	if kid != "" {
+		return nil, fmt.Errorf("no trusted key with kid %q", kid)
+	}
+	return nil, errors.New("no key to verify the status list signature")
+}
+
+// verifyChain checks that chain, leaf first, leads to one of the trusted
+// certificates. A trusted leaf is accepted as is.
+func verifyChain(chain, trusted []*x509.Certificate, now time.Time) error {
+	roots := x509.NewCertPool()
+	for _, certificate := range trusted {
+		if certificate.Equal(chain[0]) {
+			return nil
+		}
+		roots.AddCert(certificate)
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("untrusted certificate chain: %w", err)
+	}
+	return nil
+}
+
+// parseX5C decodes a JOSE x5c header.
+func parseX5C(value any) ([]*x509.Certificate, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("x5c header must be an array")
+	}
+	certificates := make([]*x509.Certificate, 0, len(items))
+	for _, item := range items {
+		encoded, ok := item.(string)
+		if !ok {
+			return nil, errors.New("x5c entries must be strings")
+		}
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("x5c: %w", err)
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package statuslist checks the revocation and suspension status of
+// credentials against IETF Token Status Lists (JWT and CWT), W3C Bitstring
+// Status Lists and the legacy StatusList2021: it locates the status entry of a
+// credential, fetches and verifies the list and reads the entry.
+package statuslist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Status list formats.
+const (
+	FormatTokenStatusList     = "token_status_list"
+	FormatBitstringStatusList = "bitstring_status_list"
+	FormatStatusList2021      = "status_list_2021"
+)
+
+// Status labels. Token Status Lists define them directly; W3C lists map a set
+// revocation bit to StatusInvalid and a set suspension bit to
+// StatusSuspended.
+const (
+	StatusValid               = "valid"
+	StatusInvalid             = "invalid"
+	StatusSuspended           = "suspended"
+	StatusApplicationSpecific = "application_specific"
+)
+
+// W3C status purposes.
+const (
+	PurposeRevocation = "revocation"
+	PurposeSuspension = "suspension"
+	PurposeMessage    = "message"
+)
+
+// maxListSize bounds decompressed lists, far above the 2^17 entries the W3C
+// specification recommends as a minimum.
+const maxListSize = 64 << 20
+
+// bitList is a decompressed status list. Token Status Lists pack entries
+// from the least significant bit of each byte, W3C bitstrings from the most
+// significant one.
+type bitList struct {
+	bits     int
+	data     []byte
+	msbFirst bool
+}
+
+func newBitList(bits int, data []byte, msbFirst bool) (bitList, error) {
+	if msbFirst {
+		if bits < 1 || bits > 8 {
+			return bitList{}, fmt.Errorf("unsupported status size %d", bits)
+		}
+	} else if bits != 1 && bits != 2 && bits != 4 && bits != 8 {
+		return bitList{}, fmt.Errorf("unsupported bits value %d", bits)
+	}
+	return bitList{bits: bits, data: data, msbFirst: msbFirst}, nil
+}
+
+// size returns the number of entries in the list.
+func (l bitList) size() int {
+	return len(l.data) * 8 / l.bits
+}
+
+// value returns the status of entry index.
+func (l bitList) value(index int) (int, error) {
+	if index < 0 || index >= l.size() {
+		return 0, fmt.Errorf("index %d is out of the list range [0, %d)", index, l.size())
+	}
+	if !l.msbFirst {
+		offset := index * l.bits
+		mask := byte(1<<l.bits - 1)
+		return int(l.data[offset/8] >> (offset % 8) & mask), nil
+	}
+	value := 0
+	for bit := index * l.bits; bit < (index+1)*l.bits; bit++ {
+		value = value<<1 | int(l.data[bit/8]>>(7-bit%8)&1)
+	}
+	return value, nil
+}
+
+// tokenStatusLabel names a Token Status List value.
+func tokenStatusLabel(value int) string {
+	switch value {
+	case 0:
+		return StatusValid
+	case 1:
+		return StatusInvalid
+	case 2:
+		return StatusSuspended
+	default:
+		return StatusApplicationSpecific
+	}
+}
+
+// purposeStatusLabel names the value of a W3C status entry.
+func purposeStatusLabel(purpose string, value int) string {
+	switch {
+	case purpose == PurposeMessage:
+		return StatusApplicationSpecific
+	case value == 0:
+		return StatusValid
+	case purpose == PurposeSuspension:
+		return StatusSuspended
+	default:
+		return StatusInvalid
+	}
+}
+
+func inflateZlib(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress status list: %w", err)
+	}
+	defer reader.Close()
+	return readBounded(reader)
+}
+
+func inflateGzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress status list: %w", err)
+	}
+	defer reader.Close()
+	return readBounded(reader)
+}
+
+func readBounded(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress status list: %w", err)
+	}
+	if len(data) > maxListSize {
+		return nil, errors.New("status list exceeds the maximum size")
+	}
+	return data, nil
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStatusListExamples(t *testing.T) {
+	// Examples of the IETF Token Status List specification.
+	tests := []struct {
+		name     string
+		bits     int
+		lst      string
+		statuses []int
+	}{
+		{
+			name:     "one bit",
+			bits:     1,
+			lst:      "eNrbuRgAAhcBXQ",
+			statuses: []int{1, 0, 0, 1, 1, 1, 0, 1, 1, 1, 0, 0, 0, 1, 0, 1},
+		},
+		{
+			name:     "two bits",
+			bits:     2,
+			lst:      "eNo76fITAAPfAgc",
+			statuses: []int{1, 2, 0, 3, 0, 1, 0, 1, 1, 2, 3, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := base64.RawURLEncoding.DecodeString(tt.lst)
+			require.NoError(t, err)
+			data, err := inflateZlib(compressed)
+			require.NoError(t, err)
+			list, err := newBitList(tt.bits, data, false)
+			require.NoError(t, err)
+			for index, want := range tt.statuses {
+				got, err := list.value(index)
+				require.NoError(t, err)
+				require.Equal(t, want, got, "index %d", index)
+			}
+		})
+	}
+}
+
+func TestBitstringOrdering(t *testing.T) {
+	list, err := newBitList(1, []byte{0b10000001, 0b01000000}, true)
+	require.NoError(t, err)
+	require.Equal(t, 16, list.size())
+	for index, want := range map[int]int{0: 1, 1: 0, 7: 1, 8: 0, 9: 1} {
+		got, err := list.value(index)
+		require.NoError(t, err)
+		require.Equal(t, want, got, "index %d", index)
+	}
+
+	list, err = newBitList(3, []byte{0b10101100, 0b00000000}, true)
+	require.NoError(t, err)
+	require.Equal(t, 5, list.size())
+	first, err := list.value(0)
+	require.NoError(t, err)
+	require.Equal(t, 0b101, first)
+	second, err := list.value(1)
+	require.NoError(t, err)
+	require.Equal(t, 0b011, second)
+
+	_, err = list.value(5)
+	require.ErrorContains(t, err, "out of the list range")
+	_, err = list.value(-1)
+	require.Error(t, err)
+}
+
+func TestNewBitListRejectsUnsupportedSizes(t *testing.T) {
+	_, err := newBitList(3, nil, false)
+	require.ErrorContains(t, err, "unsupported bits value 3")
+	_, err = newBitList(9, nil, true)
+	require.ErrorContains(t, err, "unsupported status size 9")
+}
+
+func TestStatusLabels(t *testing.T) {
+	require.Equal(t, StatusValid, tokenStatusLabel(0))
+	require.Equal(t, StatusInvalid, tokenStatusLabel(1))
+	require.Equal(t, StatusSuspended, tokenStatusLabel(2))
+	require.Equal(t, StatusApplicationSpecific, tokenStatusLabel(3))
+
+	require.Equal(t, StatusValid, purposeStatusLabel(PurposeRevocation, 0))
+	require.Equal(t, StatusInvalid, purposeStatusLabel(PurposeRevocation, 1))
+	require.Equal(t, StatusSuspended, purposeStatusLabel(PurposeSuspension, 1))
+	require.Equal(t, StatusApplicationSpecific, purposeStatusLabel(PurposeMessage, 0))
+}
+
+func TestInflateRejectsOversizedLists(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(make([]byte, maxListSize+1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	_, err = inflateGzip(buf.Bytes())
+	require.ErrorContains(t, err, "maximum size")
+
+	_, err = inflateZlib([]byte("not zlib"))
+	require.Error(t, err)
+}
+
+func zlibCompress(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func gzipCompress(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,275 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+)
+
+// W3C credential status entry types.
+const (
+	TypeBitstringStatusListEntry = "BitstringStatusListEntry"
+	TypeStatusList2021Entry      = "StatusList2021Entry"
+)
+
+// cwtClaimStatus is the CWT claim key of the status of a referenced token.
+const cwtClaimStatus = 65535
+
+// Reference locates the status of a credential in a status list.
+type Reference struct {
+	Format     string `json:"format"                yaml:"format"`
+	URI        string `json:"uri"                   yaml:"uri"`
+	Index      int    `json:"index"                 yaml:"index"`
+	Purpose    string `json:"purpose,omitempty"     yaml:"purpose,omitempty"`
+	StatusSize int    `json:"status_size,omitempty" yaml:"status_size,omitempty"`
+}
+
+// Validate checks that the reference can be resolved.
+func (r Reference) Validate() error {
+	switch r.Format {
+	case FormatTokenStatusList, FormatBitstringStatusList, FormatStatusList2021:
+	default:
+		return fmt.Errorf("unsupported status list format %q", r.Format)
+	}
+	if !strings.HasPrefix(r.URI, "https://") && !strings.HasPrefix(r.URI, "http://") {
+		return fmt.Errorf("status list URI %q is not an http(s) URL", r.URI)
+	}
+	if r.Index < 0 {
+		return fmt.Errorf("status list index %d is negative", r.Index)
+	}
+	return nil
+}
+
+// ReferenceFromCredential finds the status entry of a credential. The
+// credential is a JWT, an SD-JWT, a JSON credential, a base64url encoded mdoc
+// or an already decoded object. purpose selects among several W3C entries.
+func ReferenceFromCredential(credential any, purpose string) (Reference, error) {
+	claims, err := credentialClaims(credential)
+	if err != nil {
+		return Reference{}, err
+	}
+
+	if status, ok := claims["status"].(map[string]any); ok {
+		if entry, ok := status["status_list"].(map[string]any); ok {
+			return tokenReference(entry)
+		}
+	}
+	if vc, ok := claims["vc"].(map[string]any); ok {
+		claims = vc
+	}
+	if status, ok := claims["credentialStatus"]; ok {
+		return w3cReference(status, purpose)
+	}
+	return Reference{}, errors.New("credential has no status entry")
+}
+
+func tokenReference(entry map[string]any) (Reference, error) {
+	uri, _ := entry["uri"].(string)
+	index, ok := entry["idx"].(float64)
+	if uri == "" || !ok {
+		return Reference{}, errors.New("status_list entry must have uri and idx")
+	}
+	return Reference{Format: FormatTokenStatusList, URI: uri, Index: int(index)}, nil
+}
+
+func w3cReference(status any, purpose string) (Reference, error) {
+	var entries []map[string]any
+	switch v := status.(type) {
+	case map[string]any:
+		entries = []map[string]any{v}
+	case []any:
+		for _, item := range v {
+			if entry, ok := item.(map[string]any); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	for _, entry := range entries {
+		entryPurpose, _ := entry["statusPurpose"].(string)
+		if purpose != "" && entryPurpose != purpose {
+			continue
+		}
+		var format string
+		switch entry["type"] {
+		case TypeBitstringStatusListEntry:
+			format = FormatBitstringStatusList
+		case TypeStatusList2021Entry:
+			format = FormatStatusList2021
+		default:
+			continue
+		}
+		uri, _ := entry["statusListCredential"].(string)
+		index, err := jsonInt(entry["statusListIndex"])
+		if uri == "" || err != nil {
+			return Reference{}, errors.New(
+				"credentialStatus must have statusListCredential and a numeric statusListIndex",
+			)
+		}
+		size := 1
+		if entry["statusSize"] != nil {
+			if size, err = jsonInt(entry["statusSize"]); err != nil {
+				return Reference{}, fmt.Errorf("statusSize: %w", err)
+			}
+		}
+		return Reference{
+			Format:     format,
+			URI:        uri,
+			Index:      index,
+			Purpose:    entryPurpose,
+			StatusSize: size,
+		}, nil
+	}
+	if purpose != "" {
+		return Reference{}, fmt.Errorf("credential has no %s status entry", purpose)
+	}
+	return Reference{}, errors.New("credential has no supported credentialStatus entry")
+}
+
+// jsonInt reads an integer given as a JSON number or a decimal string, as
+// statusListIndex is.
+func jsonInt(value any) (int, error) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("invalid integer %v", value)
+	}
+}
+
+// credentialClaims decodes the claims of a credential without verifying it:
+// only the status entry is read, the status list itself is verified.
+func credentialClaims(credential any) (map[string]any, error) {
+	// Values other than strings are normalised through JSON, so that numbers
+	// read the same whether the credential was decoded or built in Go.
+	switch v := credential.(type) {
+	case string:
+		return credentialClaimsFromString(strings.TrimSpace(v))
+	case nil:
+		return nil, errors.New("credential is empty")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		claims := map[string]any{}
+		if err := json.Unmarshal(encoded, &claims); err != nil {
+			return nil, errors.New("credential must be a string or an object")
+		}
+		return claims, nil
+	}
+}
+
+func credentialClaimsFromString(credential string) (map[string]any, error) {
+	if credential == "" {
+		return nil, errors.New("credential is empty")
+	}
+	if strings.HasPrefix(credential, "{") {
+		claims := map[string]any{}
+		if err := json.Unmarshal([]byte(credential), &claims); err != nil {
+			return nil, fmt.Errorf("invalid JSON credential: %w", err)
+		}
+		return claims, nil
+	}
+
+	// SD-JWTs append disclosures and a key binding JWT after a tilde.
+	compact, _, _ := strings.Cut(credential, "~")
+	if parts := strings.Split(compact, "."); len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT payload: %w", err)
+		}
+		claims := map[string]any{}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			return nil, fmt.Errorf("invalid JWT payload: %w", err)
+		}
+		return claims, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(credential, "="))
+	if err != nil {
+		return nil, errors.New("credential is neither a JWT, JSON nor a base64url mdoc")
+	}
+	return mdocClaims(raw)
+}
+
+// mdocClaims returns the status of an ISO 18013-5 mdoc, read from the Mobile
+// Security Object of an IssuerSigned structure or of the first document of a
+// DeviceResponse.
+func mdocClaims(raw []byte) (map[string]any, error) {
+	item, err := cbor.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mdoc: %w", err)
+	}
+	root, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid mdoc: not a map")
+	}
+	if documents, ok := root["documents"].([]any); ok && len(documents) > 0 {
+		document, _ := documents[0].(map[any]any)
+		root, _ = document["issuerSigned"].(map[any]any)
+	}
+	issuerAuth, ok := root["issuerAuth"]
+	if !ok {
+		return nil, errors.New("invalid mdoc: no issuerAuth")
+	}
+	sign1, err := cose.FromItem(issuerAuth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mdoc issuerAuth: %w", err)
+	}
+	msoItem, err := cbor.Decode(sign1.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mobile security object: %w", err)
+	}
+	if tag, ok := msoItem.(cbor.Tag); ok && tag.Number == cbor.TagEncodedCBOR {
+		encoded, _ := tag.Content.([]byte)
+		if msoItem, err = cbor.Decode(encoded); err != nil {
+			return nil, fmt.Errorf("invalid mobile security object: %w", err)
+		}
+	}
+	mso, ok := msoItem.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid mobile security object")
+	}
+	status, ok := mso["status"]
+	if !ok {
+		status, ok = cbor.Lookup(mso, cwtClaimStatus)
+	}
+	if !ok {
+		return nil, errors.New("credential has no status entry")
+	}
+	return map[string]any{"status": cborToJSON(status)}, nil
+}
+
+// cborToJSON converts decoded CBOR to the types encoding/json produces.
+func cborToJSON(value any) any {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = cborToJSON(item)
+		}
+		return items
+	case map[any]any:
+		items := make(map[string]any, len(v))
+		for key, item := range v {
+			items[fmt.Sprint(key)] = cborToJSON(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
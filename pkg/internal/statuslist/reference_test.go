@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+	"github.com/stretchr/testify/require"
+)
+
+func unsignedJWT(t testing.TB, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + ".c2ln"
+}
+
+func TestReferenceFromCredential(t *testing.T) {
+	tokenStatus := map[string]any{
+		"status": map[string]any{
+			"status_list": map[string]any{"idx": 7, "uri": "https://status.example/tsl/1"},
+		},
+	}
+	tokenReference := Reference{
+		Format: FormatTokenStatusList,
+		URI:    "https://status.example/tsl/1",
+		Index:  7,
+	}
+	w3cStatus := map[string]any{
+		"credentialStatus": []any{
+			map[string]any{
+				"type":                 TypeBitstringStatusListEntry,
+				"statusPurpose":        PurposeRevocation,
+				"statusListIndex":      "94567",
+				"statusListCredential": "https://status.example/credentials/3",
+			},
+			map[string]any{
+				"type":                 TypeBitstringStatusListEntry,
+				"statusPurpose":        PurposeSuspension,
+				"statusListIndex":      "23452",
+				"statusSize":           2,
+				"statusListCredential": "https://status.example/credentials/4",
+			},
+		},
+	}
+	mdoc := issuerSignedMDoc(t, map[any]any{
+		"docType": "eu.europa.ec.eudi.pid.1",
+		"status": map[any]any{
+			"status_list": map[any]any{"idx": int64(12), "uri": "https://status.example/cwt"},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		credential any
+		purpose    string
+		want       Reference
+	}{
+		{
+			name:       "sd-jwt",
+			credential: unsignedJWT(t, tokenStatus) + "~WyJzYWx0IiwibmFtZSIsIkFsaWNlIl0~",
+			want:       tokenReference,
+		},
+		{name: "decoded claims", credential: tokenStatus, want: tokenReference},
+		{
+			name:       "first w3c entry",
+			credential: w3cStatus,
+			want: Reference{
+				Format:     FormatBitstringStatusList,
+				URI:        "https://status.example/credentials/3",
+				Index:      94567,
+				Purpose:    PurposeRevocation,
+				StatusSize: 1,
+			},
+		},
+		{
+			name:       "w3c entry by purpose",
+			credential: w3cStatus,
+			purpose:    PurposeSuspension,
+			want: Reference{
+				Format:     FormatBitstringStatusList,
+				URI:        "https://status.example/credentials/4",
+				Index:      23452,
+				Purpose:    PurposeSuspension,
+				StatusSize: 2,
+			},
+		},
+		{
+			name: "vc 1.1 jwt",
+			credential: unsignedJWT(t, map[string]any{"vc": map[string]any{
+				"credentialStatus": map[string]any{
+					"type":                 TypeStatusList2021Entry,
+					"statusPurpose":        PurposeRevocation,
+					"statusListIndex":      "3",
+					"statusListCredential": "https://status.example/2021",
+				},
+			}}),
+			want: Reference{
+				Format:     FormatStatusList2021,
+				URI:        "https://status.example/2021",
+				Index:      3,
+				Purpose:    PurposeRevocation,
+				StatusSize: 1,
+			},
+		},
+		{
+			name:       "json string",
+			credential: `{"status":{"status_list":{"idx":7,"uri":"https://status.example/tsl/1"}}}`,
+			want:       tokenReference,
+		},
+		{
+			name:       "mdoc",
+			credential: mdoc,
+			want: Reference{
+				Format: FormatTokenStatusList,
+				URI:    "https://status.example/cwt",
+				Index:  12,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReferenceFromCredential(tt.credential, tt.purpose)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, got.Validate())
+		})
+	}
+}
+
+func TestReferenceFromCredentialErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential any
+		purpose    string
+		want       string
+	}{
+		{name: "empty", credential: "", want: "credential is empty"},
+		{name: "nil", credential: nil, want: "credential is empty"},
+		{name: "no status", credential: map[string]any{"sub": "alice"}, want: "no status entry"},
+		{name: "garbage", credential: "%%%", want: "neither a JWT"},
+		{
+			name: "missing index",
+			credential: map[string]any{"status": map[string]any{
+				"status_list": map[string]any{"uri": "https://status.example"},
+			}},
+			want: "uri and idx",
+		},
+		{
+			name: "unknown purpose",
+			credential: map[string]any{"credentialStatus": map[string]any{
+				"type":                 TypeBitstringStatusListEntry,
+				"statusPurpose":        PurposeRevocation,
+				"statusListIndex":      "1",
+				"statusListCredential": "https://status.example",
+			}},
+			purpose: PurposeSuspension,
+			want:    "no suspension status entry",
+		},
+		{
+			name: "unsupported entry",
+			credential: map[string]any{"credentialStatus": map[string]any{
+				"type": "RevocationList2020Status",
+			}},
+			want: "no supported credentialStatus entry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReferenceFromCredential(tt.credential, tt.purpose)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestReferenceValidate(t *testing.T) {
+	valid := Reference{Format: FormatTokenStatusList, URI: "https://status.example", Index: 1}
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Format = "revocation_list_2020"
+	require.ErrorContains(t, invalid.Validate(), "unsupported status list format")
+	invalid = valid
+	invalid.URI = "ftp://status.example"
+	require.ErrorContains(t, invalid.Validate(), "not an http(s) URL")
+	invalid = valid
+	invalid.Index = -1
+	require.ErrorContains(t, invalid.Validate(), "negative")
+}
+
+// issuerSignedMDoc encodes an IssuerSigned structure whose MSO is mso. The
+// issuerAuth signature is not checked when reading the status reference.
+func issuerSignedMDoc(t testing.TB, mso map[any]any) string {
+	t.Helper()
+	encodedMSO, err := cbor.Encode(mso)
+	require.NoError(t, err)
+	payload, err := cbor.Encode(cbor.Tag{Number: cbor.TagEncodedCBOR, Content: encodedMSO})
+	require.NoError(t, err)
+	protected, err := cbor.Encode(map[any]any{int64(cose.HeaderAlg): int64(-7)})
+	require.NoError(t, err)
+	issuerSigned, err := cbor.Encode(map[any]any{
+		"nameSpaces": map[any]any{},
+		"issuerAuth": []any{protected, map[any]any{}, payload, []byte("signature")},
+	})
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(issuerSigned)
+}
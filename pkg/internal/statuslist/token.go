@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package statuslist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/cbor"
+	"github.com/forkbombeu/credimi/pkg/internal/cose"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Status List Token media types.
+const (
+	MediaTypeStatusListJWT = "application/statuslist+jwt"
+	MediaTypeStatusListCWT = "application/statuslist+cwt"
+)
+
+// CWT claim keys of Status List Tokens.
+const (
+	cwtClaimIss        = 1
+	cwtClaimSub        = 2
+	cwtClaimExp        = 4
+	cwtClaimIat        = 6
+	cwtClaimStatusList = 65533
+	cwtClaimTTL        = 65534
+)
+
+// signingMethods are the JWS algorithms accepted for status lists.
+var signingMethods = []string{
+	"ES256", "ES384", "ES512",
+	"PS256", "PS384", "PS512",
+	"RS256", "RS384", "RS512",
+	"EdDSA",
+}
+
+// statusList is a verified list, whatever its format.
+type statusList struct {
+	Encoding  string
+	Subject   string
+	Issuer    string
+	Purpose   string
+	Messages  map[int]string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	TTL       time.Duration
+	Signature SignatureReport
+	list      bitList
+}
+
+// parseToken verifies a Status List Token, in JWT or CWT encoding, against
+// keys and decodes its list.
+func parseToken(data []byte, keys Keys, now time.Time) (*statusList, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("ey")) {
+		return parseTokenJWT(string(trimmed), keys, now)
+	}
+	return parseTokenCWT(data, keys, now)
+}
+
+func parseTokenJWT(raw string, keys Keys, now time.Time) (*statusList, error) {
+	claims := jwt.MapClaims{}
+	var report SignatureReport
+	keyFunc := func(token *jwt.Token) (any, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "statuslist+jwt" &&
+			typ != MediaTypeStatusListJWT {
+			return nil, fmt.Errorf("unexpected typ %q, want statuslist+jwt", typ)
+		}
+		chain, err := parseX5C(token.Header["x5c"])
+		if err != nil {
+			return nil, err
+		}
+		kid, _ := token.Header["kid"].(string)
+		issuer, _ := claims["iss"].(string)
+		signing, err := keys.resolveKey(kid, chain, issuer, now)
+		if err != nil {
+			return nil, err
+		}
+		report = signing.report
+		report.Algorithm = token.Method.Alg()
+		return signing.key, nil
+	}
+	_, err := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+		jwt.WithIssuedAt(),
+	).ParseWithClaims(raw, claims, keyFunc)
+	if err != nil {
+		return nil, &SignatureError{Err: err}
+	}
+	report.Verified = true
+
+	token := &statusList{Encoding: "jwt", Signature: report}
+	token.Subject, _ = claims["sub"].(string)
+	token.Issuer, _ = claims["iss"].(string)
+	if iat, ok := claims["iat"].(float64); ok {
+		token.IssuedAt = time.Unix(int64(iat), 0).UTC()
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		token.ExpiresAt = time.Unix(int64(exp), 0).UTC()
+	}
+	if ttl, ok := claims["ttl"].(float64); ok && ttl > 0 {
+		token.TTL = time.Duration(ttl) * time.Second
+	}
+
+	statusClaim, ok := claims["status_list"].(map[string]any)
+	if !ok {
+		return nil, errors.New("status list token has no status_list claim")
+	}
+	bits, ok := statusClaim["bits"].(float64)
+	if !ok {
+		return nil, errors.New("status_list has no bits")
+	}
+	encoded, ok := statusClaim["lst"].(string)
+	if !ok {
+		return nil, errors.New("status_list has no lst")
+	}
+	compressed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("status_list lst: %w", err)
+	}
+	if err := token.setTokenList(int(bits), compressed); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func parseTokenCWT(data []byte, keys Keys, now time.Time) (*statusList, error) {
+	sign1, err := cose.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status list CWT: %w", err)
+	}
+	if typ := sign1.Typ(); typ != MediaTypeStatusListCWT && typ != "statuslist+cwt" {
+		return nil, &SignatureError{
+			Err: fmt.Errorf("unexpected typ %q, want %s", typ, MediaTypeStatusListCWT),
+		}
+	}
+	item, err := cbor.Decode(sign1.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status list CWT claims: %w", err)
+	}
+	claims, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("status list CWT claims must be a map")
+	}
+
+	token := &statusList{Encoding: "cwt"}
+	if sub, ok := cbor.Lookup(claims, cwtClaimSub); ok {
+		token.Subject, _ = sub.(string)
+	}
+	if iss, ok := cbor.Lookup(claims, cwtClaimIss); ok {
+		token.Issuer, _ = iss.(string)
+	}
+	if iat, ok := cbor.Lookup(claims, cwtClaimIat); ok {
+		if seconds, ok := cbor.Int(iat); ok {
+			token.IssuedAt = time.Unix(seconds, 0).UTC()
+		}
+	}
+	if exp, ok := cbor.Lookup(claims, cwtClaimExp); ok {
+		if seconds, ok := cbor.Int(exp); ok {
+			token.ExpiresAt = time.Unix(seconds, 0).UTC()
+		}
+	}
+	if ttl, ok := cbor.Lookup(claims, cwtClaimTTL); ok {
+		if seconds, ok := cbor.Int(ttl); ok && seconds > 0 {
+			token.TTL = time.Duration(seconds) * time.Second
+		}
+	}
+
+	chain, err := sign1.X5Chain()
+	if err != nil {
+		return nil, &SignatureError{Err: err}
+	}
+	signing, err := keys.resolveKey(sign1.Kid(), chain, token.Issuer, now)
+	if err != nil {
+		return nil, &SignatureError{Err: err}
+	}
+	if err := sign1.Verify(signing.key); err != nil {
+		return nil, &SignatureError{Err: err}
+	}
+	token.Signature = signing.report
+	token.Signature.Algorithm, _ = sign1.Algorithm()
+	token.Signature.Verified = true
+	if !token.ExpiresAt.IsZero() && now.After(token.ExpiresAt) {
+		return nil, &SignatureError{Err: errors.New("status list token is expired")}
+	}
+
+	statusItem, _ := cbor.Lookup(claims, cwtClaimStatusList)
+	statusClaim, ok := statusItem.(map[any]any)
+	if !ok {
+		return nil, errors.New("status list CWT has no status_list claim")
+	}
+	bitsItem, _ := cbor.Lookup(statusClaim, "bits")
+	bits, ok := cbor.Int(bitsItem)
+	if !ok {
+		return nil, errors.New("status_list has no bits")
+	}
+	lstItem, _ := cbor.Lookup(statusClaim, "lst")
+	compressed, ok := lstItem.([]byte)
+	if !ok {
+		return nil, errors.New("status_list has no lst")
+	}
+	if err := token.setTokenList(int(bits), compressed); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (t *statusList) setTokenList(bits int, compressed []byte) error {
+	data, err := inflateZlib(compressed)
+	if err != nil {
+		return err
+	}
+	t.list, err = newBitList(bits, data, false)
+	return err
+}
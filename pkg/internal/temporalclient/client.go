@@ -10,9 +10,12 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalcrypto"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 )
 
 var (
@@ -36,9 +39,11 @@ func getTemporalClient(args ...string) (client.Client, error) {
 	}
 	hostPort := utils.GetEnvironmentVariable("TEMPORAL_ADDRESS", client.DefaultHostPort)
 	c, err := newLazyClient(client.Options{
-		HostPort:      hostPort,
-		Namespace:     namespace,
-		DataConverter: temporalcrypto.DataConverter(),
+		HostPort:       hostPort,
+		Namespace:      namespace,
+		DataConverter:  temporalcrypto.DataConverter(),
+		MetricsHandler: metrics.Temporal(),
+		Interceptors:   []interceptor.ClientInterceptor{tracing.TemporalInterceptor()},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to create client: %w", err)
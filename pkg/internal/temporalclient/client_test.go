@@ -30,6 +30,8 @@ func TestGetTemporalClientWithNamespaceCaches(t *testing.T) {
 	newLazyClient = func(options client.Options) (client.Client, error) {
 		callCount++
 		require.NotNil(t, options.DataConverter)
+		require.NotNil(t, options.MetricsHandler)
+		require.Len(t, options.Interceptors, 1)
 		if options.Namespace == "other" {
 			return mockOther, nil
 		}
@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package tracing wires OpenTelemetry tracing into Credimi. Temporal clients
+// and workers share a single tracing interceptor, pipeline steps open their own
+// spans from workflow code, and outgoing HTTP calls carry a W3C traceparent.
+//
+// Spans are exported over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT (or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is set; the exporter honours the other
+// standard OTEL_EXPORTER_OTLP_* variables. Without an endpoint the global
+// no-op provider is kept, but trace context is still propagated.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/pocketbase/pocketbase/core"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
+)
+
+const (
+	EnvOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvServiceName        = "OTEL_SERVICE_NAME"
+
+	defaultServiceName = "credimi"
+)
+
+var (
+	propagator = propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+
+	temporalTracer      interceptor.Tracer
+	temporalInterceptor interceptor.Interceptor
+
+	providerMu sync.Mutex
+	provider   *sdktrace.TracerProvider
+)
+
+func init() {
+	tracer, err := temporalotel.NewTracer(temporalotel.TracerOptions{
+		TextMapPropagator: propagator,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("tracing: unable to create Temporal tracer: %v", err))
+	}
+	temporalTracer = tracer
+	temporalInterceptor = interceptor.NewTracingInterceptor(tracer)
+}
+
+// Enabled reports whether an OTLP collector endpoint is configured.
+func Enabled() bool {
+	return utils.GetEnvironmentVariable(EnvOTLPEndpoint) != "" ||
+		utils.GetEnvironmentVariable(EnvOTLPTracesEndpoint) != ""
+}
+
+// Setup installs the OTLP tracer provider as the global provider when an
+// endpoint is configured. The propagator is always installed.
+func Setup(ctx context.Context) error {
+	otel.SetTextMapPropagator(propagator)
+	if !Enabled() {
+		return nil
+	}
+
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider != nil {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attribute.String(
+			"service.name",
+			utils.GetEnvironmentVariable(EnvServiceName, defaultServiceName),
+		)),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to build tracing resource: %w", err)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown flushes pending spans and stops the exporter, if any.
+func Shutdown(ctx context.Context) error {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider == nil {
+		return nil
+	}
+	err := provider.Shutdown(ctx)
+	provider = nil
+	return err
+}
+
+// RegisterHooks sets tracing up when the server starts and flushes it on
+// termination.
+func RegisterHooks(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if err := Setup(context.Background()); err != nil {
+			log.Printf("[tracing] %v; spans will not be exported", err)
+		}
+		return se.Next()
+	})
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		if err := Shutdown(context.Background()); err != nil {
+			log.Printf("[tracing] shutdown failed: %v", err)
+		}
+		return e.Next()
+	})
+}
+
+// Propagator returns the W3C trace context and baggage propagator.
+func Propagator() propagation.TextMapPropagator {
+	return propagator
+}
+
+// TemporalInterceptor returns the tracing interceptor set on Temporal clients.
+// Workers created from those clients inherit it.
+func TemporalInterceptor() interceptor.Interceptor {
+	return temporalInterceptor
+}
+
+// HTTPTransport wraps base so that outgoing requests get a client span and a
+// traceparent header derived from the request context.
+func HTTPTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base, otelhttp.WithPropagators(propagator))
+}
+
+// Environ returns TRACEPARENT/TRACESTATE entries for the span in ctx, following
+// the OpenTelemetry convention for propagating context to child processes.
+func Environ(ctx context.Context) []string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	env := []string{}
+	if value := carrier.Get("traceparent"); value != "" {
+		env = append(env, "TRACEPARENT="+value)
+	}
+	if value := carrier.Get("tracestate"); value != "" {
+		env = append(env, "TRACESTATE="+value)
+	}
+	return env
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+var recorder = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+}
+
+func testSpanContext(t *testing.T) trace.SpanContext {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestEnviron(t *testing.T) {
+	require.Empty(t, Environ(context.Background()))
+
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(t))
+	require.Equal(
+		t,
+		[]string{"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		Environ(ctx),
+	)
+}
+
+func TestHTTPTransportInjectsTraceparent(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(t))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: HTTPTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`, received)
+}
+
+func TestSetupWithoutEndpointKeepsProvider(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "")
+	t.Setenv(EnvOTLPTracesEndpoint, "")
+
+	require.False(t, Enabled())
+	require.NoError(t, Setup(context.Background()))
+	require.Nil(t, provider)
+	require.NoError(t, Shutdown(context.Background()))
+}
+
+func TestStartWorkflowSpan(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+
+	spanWorkflow := func(ctx workflow.Context) error {
+		stepCtx, span := StartWorkflowSpan(ctx, "RunPipelineStep", "step-1", map[string]string{
+			"credimi.step.use": "http-request",
+		})
+		_, nested := StartWorkflowSpan(stepCtx, "RunPipelineStep", "nested", nil)
+		nested.End(nil, nil)
+		span.End(errors.New("boom"), map[string]string{"credimi.step.outcome": "failed"})
+		return nil
+	}
+	env.RegisterWorkflowWithOptions(spanWorkflow, workflow.RegisterOptions{Name: "span-workflow"})
+	env.ExecuteWorkflow("span-workflow")
+	require.NoError(t, env.GetWorkflowError())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	step := spans["RunPipelineStep:step-1"]
+	nested := spans["RunPipelineStep:nested"]
+	require.NotNil(t, step)
+	require.NotNil(t, nested)
+	require.Equal(t, step.SpanContext().SpanID(), nested.Parent().SpanID())
+	require.Equal(t, codes.Error, step.Status().Code)
+
+	attrs := map[string]string{}
+	for _, attr := range step.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	require.Equal(t, "http-request", attrs["credimi.step.use"])
+	require.Equal(t, "failed", attrs["credimi.step.outcome"])
+}
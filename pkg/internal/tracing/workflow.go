@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package tracing
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/workflow"
+)
+
+// WorkflowSpan is a span opened from workflow code. The zero value is a no-op,
+// which is what StartWorkflowSpan returns while the workflow is replaying.
+type WorkflowSpan struct {
+	ctx  workflow.Context
+	span interceptor.TracerSpan
+}
+
+// StartWorkflowSpan opens a span named "<operation>:<name>" as a child of the
+// span the Temporal interceptor attached to ctx. Activities and child
+// workflows started with the returned context are nested under it.
+//
+// Spans are only opened outside of replay, mirroring the Temporal interceptor,
+// so a workflow evicted from the worker cache mid-span loses that span.
+func StartWorkflowSpan(
+	ctx workflow.Context,
+	operation string,
+	name string,
+	tags map[string]string,
+) (workflow.Context, *WorkflowSpan) {
+	if workflow.IsReplaying(ctx) {
+		return ctx, &WorkflowSpan{}
+	}
+
+	key := temporalTracer.Options().SpanContextKey
+	parent, _ := ctx.Value(key).(interceptor.TracerSpan)
+	info := workflow.GetInfo(ctx)
+	spanTags := map[string]string{
+		"temporalWorkflowID": info.WorkflowExecution.ID,
+		"temporalRunID":      info.WorkflowExecution.RunID,
+	}
+	for k, v := range tags {
+		spanTags[k] = v
+	}
+
+	span, err := temporalTracer.StartSpan(&interceptor.TracerStartSpanOptions{
+		Parent:    parent,
+		Operation: operation,
+		Name:      name,
+		Time:      time.Now(),
+		Tags:      spanTags,
+	})
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("unable to start span", "operation", operation, "error", err)
+		return ctx, &WorkflowSpan{}
+	}
+
+	spanCtx := workflow.WithValue(ctx, key, span)
+	return spanCtx, &WorkflowSpan{ctx: spanCtx, span: span}
+}
+
+// End records attrs on the span and finishes it, marking it as failed when
+// err is not nil.
+func (s *WorkflowSpan) End(err error, attrs map[string]string) {
+	if s == nil || s.span == nil {
+		return
+	}
+	if otelSpan, ok := temporalotel.SpanFromWorkflowContext(s.ctx); ok && len(attrs) > 0 {
+		kvs := make([]attribute.KeyValue, 0, len(attrs))
+		for k, v := range attrs {
+			kvs = append(kvs, attribute.String(k, v))
+		}
+		otelSpan.SetAttributes(kvs...)
+	}
+	s.span.Finish(&interceptor.TracerFinishSpanOptions{Error: err})
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// Canonicalization algorithms supported for signed trusted lists.
+const (
+	AlgorithmC14N    = string(dsig.CanonicalXML10RecAlgorithmId)
+	AlgorithmC14N11  = string(dsig.CanonicalXML11AlgorithmId)
+	AlgorithmExcC14N = string(dsig.CanonicalXML10ExclusiveAlgorithmId)
+)
+
+// parseDocument parses an XML document and returns its root element.
+func parseDocument(data []byte) (*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	switch roots := doc.ChildElements(); len(roots) {
+	case 0:
+		return nil, errors.New("empty XML document")
+	case 1:
+		return roots[0], nil
+	default:
+		return nil, errors.New("multiple root elements")
+	}
+}
+
+// canonicalize serializes target with the given canonicalization
+// algorithm, leaving out exclude (e.g. the enveloped signature). The
+// canonical forms are produced by goxmldsig.
+func canonicalize(algorithm, prefixList string, target, exclude *etree.Element) ([]byte, error) {
+	var c dsig.Canonicalizer
+	switch algorithm {
+	case AlgorithmC14N:
+		c = dsig.MakeC14N10RecCanonicalizer()
+	case AlgorithmC14N11:
+		c = dsig.MakeC14N11Canonicalizer()
+	case AlgorithmExcC14N:
+		c = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList(prefixList)
+	default:
+		return nil, fmt.Errorf("unsupported canonicalization %q", algorithm)
+	}
+
+	if exclude != nil {
+		if parent := exclude.Parent(); parent != nil {
+			index := exclude.Index()
+			parent.RemoveChildAt(index)
+			defer parent.InsertChildAt(index, exclude)
+		}
+	}
+	// The canonicalizers work on a detached copy of target that declares
+	// the namespaces in scope, and rewrite it in place.
+	ctx, err := etreeutils.NSBuildParentContext(target)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if algorithm != AlgorithmExcC14N {
+		inheritXMLAttributes(detached, target, algorithm)
+	}
+	return c.Canonicalize(detached)
+}
+
+// inheritXMLAttributes copies to detached the xml: attributes in scope of
+// target that it does not set itself, as inclusive canonicalization
+// requires. Canonical XML 1.1 does not inherit xml:id and xml:base.
+func inheritXMLAttributes(detached, target *etree.Element, algorithm string) {
+	for parent := target.Parent(); parent != nil; parent = parent.Parent() {
+		for _, a := range parent.Attr {
+			if a.Space != "xml" {
+				continue
+			}
+			if algorithm == AlgorithmC14N11 && (a.Key == "id" || a.Key == "base") {
+				continue
+			}
+			if detached.SelectAttr("xml:"+a.Key) == nil {
+				detached.CreateAttr("xml:"+a.Key, a.Value)
+			}
+		}
+	}
+}
+
+// is reports whether el has the given namespace and local name.
+func is(el *etree.Element, namespace, local string) bool {
+	return el.Tag == local && el.NamespaceURI() == namespace
+}
+
+// child returns the first child element of el with the given namespace and
+// local name.
+func child(el *etree.Element, namespace, local string) *etree.Element {
+	for _, c := range el.ChildElements() {
+		if is(c, namespace, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// attr returns the value of the unprefixed attribute local of el.
+func attr(el *etree.Element, local string) string {
+	for _, a := range el.Attr {
+		if a.Space == "" && a.Key == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// find returns the first element of the subtree, in document order, for
+// which match is true.
+func find(el *etree.Element, match func(*etree.Element) bool) *etree.Element {
+	if match(el) {
+		return el
+	}
+	for _, c := range el.ChildElements() {
+		if found := find(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll returns every element of the subtree, in document order, for
+// which match is true.
+func findAll(el *etree.Element, match func(*etree.Element) bool) []*etree.Element {
+	var found []*etree.Element
+	if match(el) {
+		found = append(found, el)
+	}
+	for _, c := range el.ChildElements() {
+		found = append(found, findAll(c, match)...)
+	}
+	return found
+}
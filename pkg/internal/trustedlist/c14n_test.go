@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/require"
+)
+
+func canonicalizeString(t *testing.T, algorithm string, el *etree.Element) string {
+	t.Helper()
+	canonical, err := canonicalize(algorithm, "", el, nil)
+	require.NoError(t, err)
+	return string(canonical)
+}
+
+func findLocal(t *testing.T, root *etree.Element, local string) *etree.Element {
+	t.Helper()
+	el := find(root, func(el *etree.Element) bool { return el.Tag == local })
+	require.NotNil(t, el)
+	return el
+}
+
+// The vectors below are the examples of the Canonical XML 1.0
+// recommendation (section 3) and of Exclusive XML Canonicalization 1.0
+// (section 2.2), leaving out the parts that depend on a DTD.
+func TestCanonicalizeW3CVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		algorithm string
+		apex      string
+		expected  string
+	}{
+		{
+			name:      "3.1 comments",
+			input:     `<?xml version="1.0"?>` + "\n" + `<doc>Hello, world!<!-- Comment 1 --></doc>`,
+			algorithm: AlgorithmC14N,
+			expected:  `<doc>Hello, world!</doc>`,
+		},
+		{
+			name: "3.2 whitespace in document content",
+			input: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+			algorithm: AlgorithmC14N,
+			expected: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+		},
+		{
+			name: "3.3 start and end tags",
+			input: `<?xml version="1.0"?>
+<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+			algorithm: AlgorithmC14N,
+			expected: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org"` +
+				` attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+		},
+		{
+			name: "3.4 character modifications and character references",
+			input: `<doc>
+   <text>First line&#x0d;&#10;Second line</text>
+   <value>&#x32;</value>
+   <compute><![CDATA[value>"0" && value<"10" ?"valid":"error"]]></compute>
+   <compute expr='value>"0" &amp;&amp; value&lt;"10" ?"valid":"error"'>valid</compute>
+   <norm attr=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+</doc>`,
+			algorithm: AlgorithmC14N,
+			expected: `<doc>
+   <text>First line&#xD;
+Second line</text>
+   <value>2</value>
+   <compute>value&gt;"0" &amp;&amp; value&lt;"10" ?"valid":"error"</compute>
+   <compute expr="value>&quot;0&quot; &amp;&amp; value&lt;&quot;10&quot; ?&quot;valid&quot;:&quot;error&quot;">valid</compute>
+   <norm attr=" '    &#xD;&#xA;&#x9;   ' "></norm>
+</doc>`,
+		},
+		{
+			name: "exc-c14n 2.2 inclusive subset of the first document",
+			input: `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n0:local>`,
+			algorithm: AlgorithmC14N,
+			apex:      "elem2",
+			expected: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+      <n3:stuff></n3:stuff>
+   </n1:elem2>`,
+		},
+		{
+			name: "exc-c14n 2.2 inclusive subset of the second document",
+			input: `<n2:pdu xmlns:n1="http://example.com" xmlns:n2="http://foo.example" xml:lang="fr" xml:space="retain">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n2:pdu>`,
+			algorithm: AlgorithmC14N,
+			apex:      "elem2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xmlns:n2="http://foo.example" xml:lang="en" xml:space="retain">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`,
+		},
+		{
+			name: "exc-c14n 2.2 exclusive subset of the first document",
+			input: `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n0:local>`,
+			algorithm: AlgorithmExcC14N,
+			apex:      "elem2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`,
+		},
+		{
+			name: "exc-c14n 2.2 exclusive subset of the second document",
+			input: `<n2:pdu xmlns:n1="http://example.com" xmlns:n2="http://foo.example" xml:lang="fr" xml:space="retain">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n2:pdu>`,
+			algorithm: AlgorithmExcC14N,
+			apex:      "elem2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseDocument([]byte(tt.input))
+			require.NoError(t, err)
+			apex := root
+			if tt.apex != "" {
+				apex = findLocal(t, root, tt.apex)
+			}
+			require.Equal(t, tt.expected, canonicalizeString(t, tt.algorithm, apex))
+		})
+	}
+}
+
+func TestCanonicalizeExclusivePrefixList(t *testing.T) {
+	root, err := parseDocument([]byte(
+		`<r xmlns:a="urn:a" xmlns:b="urn:b"><x:s xmlns:x="urn:x" b:t="1">` +
+			`<x:u a:v="&lt;&amp;&quot;"> &gt; </x:u></x:s></r>`,
+	))
+	require.NoError(t, err)
+	apex := root.ChildElements()[0]
+
+	canonical, err := canonicalize(AlgorithmExcC14N, "a", apex, nil)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`<x:s xmlns:a="urn:a" xmlns:b="urn:b" xmlns:x="urn:x" b:t="1">`+
+			`<x:u a:v="&lt;&amp;&quot;"> &gt; </x:u></x:s>`,
+		string(canonical),
+	)
+}
+
+func TestCanonicalizeExcludesElement(t *testing.T) {
+	root, err := parseDocument([]byte(`<r><keep/><drop><x/></drop><tail/></r>`))
+	require.NoError(t, err)
+	drop := root.ChildElements()[1]
+	for _, algorithm := range []string{AlgorithmC14N, AlgorithmC14N11, AlgorithmExcC14N} {
+		canonical, err := canonicalize(algorithm, "", root, drop)
+		require.NoError(t, err)
+		require.Equal(t, `<r><keep></keep><tail></tail></r>`, string(canonical))
+	}
+	// The excluded element is put back in place.
+	require.Equal(t, []*etree.Element{root.ChildElements()[0], drop, root.ChildElements()[2]},
+		root.ChildElements())
+	require.Same(t, root, drop.Parent())
+}
+
+func TestCanonicalizerUnsupportedAlgorithm(t *testing.T) {
+	root, err := parseDocument([]byte(`<r/>`))
+	require.NoError(t, err)
+	_, err = canonicalize("http://www.w3.org/2006/12/xml-c14n11#WithComments", "", root, nil)
+	require.ErrorContains(t, err, "unsupported canonicalization")
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	_, err := parseDocument([]byte(``))
+	require.Error(t, err)
+	_, err = parseDocument([]byte(`<a><b></a>`))
+	require.Error(t, err)
+	_, err = parseDocument([]byte(`<a></a><b></b>`))
+	require.Error(t, err)
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxListSize bounds the download of a trusted list; national lists with
+// their full history of qualified services reach a few megabytes.
+const maxListSize = 32 << 20
+
+// FetchError reports a trusted list that could not be downloaded.
+type FetchError struct {
+	URL string
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("fetch trusted list %s: %v", e.URL, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Fetch downloads the trusted list at url.
+func Fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &FetchError{URL: url, Err: err}
+	}
+	req.Header.Set("Accept", "application/vnd.etsi.tsl+xml, application/xml, text/xml")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &FetchError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &FetchError{URL: url, Err: fmt.Errorf("unexpected status %d", resp.StatusCode)}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxListSize+1))
+	if err != nil {
+		return nil, &FetchError{URL: url, Err: err}
+	}
+	if len(data) > maxListSize {
+		return nil, &FetchError{URL: url, Err: fmt.Errorf("list exceeds %d bytes", maxListSize)}
+	}
+	return data, nil
+}
+
+// Summary is the JSON friendly outcome of processing a trusted list: its
+// scheme information, the verified signer, the national lists it points
+// to and its EUDI wallet ecosystem entities.
+type Summary struct {
+	URL            string           `json:"url"`
+	Type           string           `json:"type"`
+	Territory      string           `json:"territory"`
+	OperatorName   string           `json:"operator_name"`
+	SequenceNumber int              `json:"sequence_number"`
+	IssueDate      time.Time        `json:"issue_date"`
+	NextUpdate     time.Time        `json:"next_update"`
+	Signer         Certificate      `json:"signer"`
+	SigningTime    string           `json:"signing_time,omitempty"`
+	Pointers       []PointerSummary `json:"pointers"`
+	Entities       []Entity         `json:"entities"`
+}
+
+// PointerSummary is a national list referenced by a List of Trusted
+// Lists, with the base64 DER certificates its signer is pinned to.
+type PointerSummary struct {
+	Location     string   `json:"location"`
+	Territory    string   `json:"territory"`
+	Certificates []string `json:"certificates"`
+}
+
+// Summarize describes a verified list fetched from url.
+func Summarize(url string, list *List, signature *Signature) Summary {
+	summary := Summary{
+		URL:            url,
+		Type:           list.Type,
+		Territory:      list.Territory,
+		OperatorName:   list.OperatorName,
+		SequenceNumber: list.SequenceNumber,
+		IssueDate:      list.IssueDate,
+		NextUpdate:     list.NextUpdate,
+		Signer:         NewCertificate(signature.Signer),
+		SigningTime:    signature.SigningTime,
+		Pointers:       []PointerSummary{},
+		Entities:       list.Entities(),
+	}
+	if summary.Entities == nil {
+		summary.Entities = []Entity{}
+	}
+	for _, pointer := range list.NationalLists() {
+		p := PointerSummary{
+			Location:     pointer.Location,
+			Territory:    pointer.Territory,
+			Certificates: []string{},
+		}
+		for _, certificate := range pointer.Certificates {
+			p.Certificates = append(
+				p.Certificates,
+				base64.StdEncoding.EncodeToString(certificate.Raw),
+			)
+		}
+		summary.Pointers = append(summary.Pointers, p)
+	}
+	return summary
+}
+
+// ParseTrustedCertificates decodes signer certificates given either as
+// PEM blocks or as base64 DER, the form used in trusted lists.
+func ParseTrustedCertificates(values []string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for _, value := range values {
+		if strings.Contains(value, "-----BEGIN") {
+			parsed, err := ParseCertificatesPEM([]byte(value))
+			if err != nil {
+				return nil, err
+			}
+			certificates = append(certificates, parsed...)
+			continue
+		}
+		certificate, err := ParseCertificate(value)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
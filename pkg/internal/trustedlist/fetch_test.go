@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchAndSummarize(t *testing.T) {
+	lotlSigner := newTestSigner(t, "LOTL signer")
+	nationalSigner := newTestSigner(t, "IT signer")
+
+	lists := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(list))
+	}))
+	defer server.Close()
+	lists["/lotl.xml"] = lotlSigner.sign(t, testLOTL(nationalSigner, server.URL+"/it.xml"))
+	lists["/it.xml"] = nationalSigner.sign(t, testNationalList(t))
+
+	data, err := Fetch(context.Background(), server.Client(), server.URL+"/lotl.xml")
+	require.NoError(t, err)
+	signature, err := VerifySignature(data, []*x509.Certificate{lotlSigner.certificate})
+	require.NoError(t, err)
+	lotl, err := Parse(data)
+	require.NoError(t, err)
+
+	summary := Summarize(server.URL+"/lotl.xml", lotl, signature)
+	require.Equal(t, "CN=LOTL signer,C=EU", summary.Signer.Subject)
+	require.Empty(t, summary.Entities)
+	require.Len(t, summary.Pointers, 1)
+	pointer := summary.Pointers[0]
+	require.Equal(t, "IT", pointer.Territory)
+	require.Equal(
+		t,
+		[]string{base64.StdEncoding.EncodeToString(nationalSigner.certificate.Raw)},
+		pointer.Certificates,
+	)
+
+	// The national list is pinned to the certificates of its LOTL pointer.
+	pinned, err := ParseTrustedCertificates(pointer.Certificates)
+	require.NoError(t, err)
+	data, err = Fetch(context.Background(), server.Client(), pointer.Location)
+	require.NoError(t, err)
+	signature, err = VerifySignature(data, pinned)
+	require.NoError(t, err)
+	national, err := Parse(data)
+	require.NoError(t, err)
+
+	summary = Summarize(pointer.Location, national, signature)
+	require.Equal(t, 12, summary.SequenceNumber)
+	require.Empty(t, summary.Pointers)
+	require.Len(t, summary.Entities, 3)
+
+	_, err = VerifySignature(data, []*x509.Certificate{lotlSigner.certificate})
+	require.ErrorContains(t, err, "untrusted certificate")
+}
+
+func TestFetchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := Fetch(context.Background(), server.Client(), server.URL+"/missing.xml")
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	require.Equal(t, server.URL+"/missing.xml", fetchErr.URL)
+	require.ErrorContains(t, err, "unexpected status 404")
+
+	_, err = Fetch(context.Background(), nil, "://invalid")
+	require.Error(t, err)
+}
@@ -0,0 +1,430 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package trustedlist parses EU trusted lists (ETSI TS 119 612), including
+// the List of Trusted Lists published by the European Commission, verifies
+// their XAdES signatures and extracts the EUDI wallet ecosystem entities
+// they register: PID and attestation providers and relying parties.
+package trustedlist
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NamespaceTSL is the namespace of ETSI TS 119 612 trusted lists.
+const NamespaceTSL = "http://uri.etsi.org/02231/v2#"
+
+// TSL types of the List of Trusted Lists and of the national lists.
+const (
+	TypeListOfTheLists = "http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUlistofthelists"
+	TypeGeneric        = "http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUgeneric"
+)
+
+// Role is the part an entity plays in the EUDI wallet ecosystem.
+type Role string
+
+const (
+	RolePIDProvider  Role = "pid_provider"
+	RoleEAAProvider  Role = "eaa_provider"
+	RoleRelyingParty Role = "relying_party"
+)
+
+// IsIssuer reports whether entities with the role issue credentials.
+func (r Role) IsIssuer() bool {
+	return r == RolePIDProvider || r == RoleEAAProvider
+}
+
+// inactiveStatuses end the supervision of a service; such services are not
+// imported.
+var inactiveStatuses = map[string]bool{
+	"withdrawn":                 true,
+	"deprecatedatnationallevel": true,
+	"deprecatedbynationallaw":   true,
+	"supervisionrevoked":        true,
+	"supervisionceased":         true,
+	"accreditationrevoked":      true,
+	"accreditationceased":       true,
+}
+
+// List is a parsed trusted list.
+type List struct {
+	Type           string
+	SequenceNumber int
+	Territory      string
+	OperatorName   string
+	IssueDate      time.Time
+	NextUpdate     time.Time
+	Pointers       []Pointer
+	Providers      []Provider
+}
+
+// Pointer references another trusted list, as found in the List of
+// Trusted Lists.
+type Pointer struct {
+	Location     string
+	Territory    string
+	Type         string
+	MimeType     string
+	Certificates []*x509.Certificate
+}
+
+// IsXML reports whether the pointed list is the machine processable XML
+// form rather than its human readable PDF rendering.
+func (p Pointer) IsXML() bool {
+	if p.MimeType != "" {
+		return strings.Contains(p.MimeType, "xml")
+	}
+	return strings.HasSuffix(strings.ToLower(p.Location), ".xml")
+}
+
+// Provider is a trust service provider and its services.
+type Provider struct {
+	Name      string
+	TradeName string
+	InfoURI   string
+	Services  []Service
+}
+
+// Service is a trust service with its service digital identities.
+type Service struct {
+	Type          string
+	Name          string
+	Status        string
+	StatusStarted time.Time
+	SupplyPoints  []string
+	Certificates  []*x509.Certificate
+}
+
+// Active reports whether the service is currently under supervision.
+func (s Service) Active() bool {
+	status := s.Status[strings.LastIndex(s.Status, "/")+1:]
+	return !inactiveStatuses[strings.ToLower(status)]
+}
+
+// Role classifies the service type. The EUDI wallet service types are
+// still being registered with ETSI and member states publish them under
+// slightly different URIs, so they are matched on their last path
+// segments rather than compared verbatim.
+func (s Service) Role() (Role, bool) {
+	segments := strings.Split(strings.ToLower(strings.TrimRight(s.Type, "/")), "/")
+	for i := len(segments) - 1; i >= 0 && i >= len(segments)-2; i-- {
+		switch segments[i] {
+		case "pid", "pidprovider", "pid-provider":
+			return RolePIDProvider, true
+		case "eaa", "qeaa", "pub-eaa", "pubeaa", "eaaprovider", "eaa-provider":
+			return RoleEAAProvider, true
+		case "relyingparty", "walletrelyingparty", "rp", "relying-party":
+			return RoleRelyingParty, true
+		}
+	}
+	return "", false
+}
+
+type xmlList struct {
+	XMLName           xml.Name      `xml:"TrustServiceStatusList"`
+	SchemeInformation xmlSchemeInfo `xml:"SchemeInformation"`
+	Providers         []xmlProvider `xml:"TrustServiceProviderList>TrustServiceProvider"`
+}
+
+type xmlSchemeInfo struct {
+	SequenceNumber int          `xml:"TSLSequenceNumber"`
+	Type           string       `xml:"TSLType"`
+	OperatorNames  []xmlText    `xml:"SchemeOperatorName>Name"`
+	Territory      string       `xml:"SchemeTerritory"`
+	Pointers       []xmlPointer `xml:"PointersToOtherTSL>OtherTSLPointer"`
+	IssueDate      string       `xml:"ListIssueDateTime"`
+	NextUpdate     string       `xml:"NextUpdate>dateTime"`
+}
+
+type xmlText struct {
+	Lang  string `xml:"lang,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlPointer struct {
+	Identities  []xmlIdentity  `xml:"ServiceDigitalIdentities>ServiceDigitalIdentity"`
+	Location    string         `xml:"TSLLocation"`
+	Information []xmlOtherInfo `xml:"AdditionalInformation>OtherInformation"`
+}
+
+type xmlIdentity struct {
+	Certificates []string `xml:"DigitalId>X509Certificate"`
+}
+
+type xmlOtherInfo struct {
+	Type      string `xml:"TSLType"`
+	Territory string `xml:"SchemeTerritory"`
+	MimeType  string `xml:"MimeType"`
+}
+
+type xmlProvider struct {
+	Names      []xmlText    `xml:"TSPInformation>TSPName>Name"`
+	TradeNames []xmlText    `xml:"TSPInformation>TSPTradeName>Name"`
+	InfoURIs   []xmlText    `xml:"TSPInformation>TSPInformationURI>URI"`
+	Services   []xmlService `xml:"TSPServices>TSPService>ServiceInformation"`
+}
+
+type xmlService struct {
+	Type          string      `xml:"ServiceTypeIdentifier"`
+	Names         []xmlText   `xml:"ServiceName>Name"`
+	Identity      xmlIdentity `xml:"ServiceDigitalIdentity"`
+	Status        string      `xml:"ServiceStatus"`
+	StatusStarted string      `xml:"StatusStartingTime"`
+	SupplyPoints  []string    `xml:"ServiceSupplyPoints>ServiceSupplyPoint"`
+}
+
+// Parse decodes a trusted list without verifying its signature.
+func Parse(data []byte) (*List, error) {
+	var raw xmlList
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse trusted list: %w", err)
+	}
+	if raw.XMLName.Space != NamespaceTSL {
+		return nil, fmt.Errorf("unexpected trusted list namespace %q", raw.XMLName.Space)
+	}
+
+	info := raw.SchemeInformation
+	list := &List{
+		Type:           strings.TrimSpace(info.Type),
+		SequenceNumber: info.SequenceNumber,
+		Territory:      strings.TrimSpace(info.Territory),
+		OperatorName:   englishText(info.OperatorNames),
+	}
+	var err error
+	if list.IssueDate, err = parseTime(info.IssueDate); err != nil {
+		return nil, fmt.Errorf("parse ListIssueDateTime: %w", err)
+	}
+	if list.NextUpdate, err = parseTime(info.NextUpdate); err != nil {
+		return nil, fmt.Errorf("parse NextUpdate: %w", err)
+	}
+
+	for _, p := range info.Pointers {
+		pointer := Pointer{Location: strings.TrimSpace(p.Location)}
+		for _, other := range p.Information {
+			pointer.Type = firstNonEmpty(pointer.Type, strings.TrimSpace(other.Type))
+			pointer.Territory = firstNonEmpty(pointer.Territory, strings.TrimSpace(other.Territory))
+			pointer.MimeType = firstNonEmpty(pointer.MimeType, strings.TrimSpace(other.MimeType))
+		}
+		if pointer.Certificates, err = parseIdentities(p.Identities...); err != nil {
+			return nil, fmt.Errorf("pointer %s: %w", pointer.Location, err)
+		}
+		list.Pointers = append(list.Pointers, pointer)
+	}
+
+	for _, p := range raw.Providers {
+		provider := Provider{
+			Name:      englishText(p.Names),
+			TradeName: englishText(p.TradeNames),
+			InfoURI:   englishText(p.InfoURIs),
+		}
+		for _, s := range p.Services {
+			service := Service{
+				Type:   strings.TrimSpace(s.Type),
+				Name:   englishText(s.Names),
+				Status: strings.TrimSpace(s.Status),
+			}
+			if service.StatusStarted, err = parseTime(s.StatusStarted); err != nil {
+				return nil, fmt.Errorf(
+					"service %s: parse StatusStartingTime: %w",
+					service.Name,
+					err,
+				)
+			}
+			for _, point := range s.SupplyPoints {
+				if point = strings.TrimSpace(point); point != "" {
+					service.SupplyPoints = append(service.SupplyPoints, point)
+				}
+			}
+			if service.Certificates, err = parseIdentities(s.Identity); err != nil {
+				return nil, fmt.Errorf("service %s: %w", service.Name, err)
+			}
+			provider.Services = append(provider.Services, service)
+		}
+		list.Providers = append(list.Providers, provider)
+	}
+	return list, nil
+}
+
+// NationalLists returns the pointers of a List of Trusted Lists to the XML
+// national trusted lists, sorted by territory.
+func (l *List) NationalLists() []Pointer {
+	var pointers []Pointer
+	for _, pointer := range l.Pointers {
+		if !pointer.IsXML() || pointer.Location == "" {
+			continue
+		}
+		if pointer.Type == TypeListOfTheLists {
+			continue
+		}
+		pointers = append(pointers, pointer)
+	}
+	sort.SliceStable(pointers, func(i, j int) bool {
+		return pointers[i].Territory < pointers[j].Territory
+	})
+	return pointers
+}
+
+// Entity is an active EUDI wallet ecosystem service of a trusted list.
+type Entity struct {
+	Role         Role          `json:"role"`
+	ProviderName string        `json:"provider_name"`
+	TradeName    string        `json:"trade_name,omitempty"`
+	InfoURI      string        `json:"info_uri,omitempty"`
+	ServiceName  string        `json:"service_name"`
+	ServiceType  string        `json:"service_type"`
+	Status       string        `json:"status"`
+	SupplyPoints []string      `json:"supply_points,omitempty"`
+	Certificates []Certificate `json:"certificates"`
+}
+
+// URL returns the endpoint the entity is reachable at: its first supply
+// point, or the provider information URI.
+func (e Entity) URL() string {
+	for _, point := range e.SupplyPoints {
+		if strings.HasPrefix(point, "https://") || strings.HasPrefix(point, "http://") {
+			return point
+		}
+	}
+	return e.InfoURI
+}
+
+// Certificate summarizes a service digital identity.
+type Certificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	Serial    string    `json:"serial"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	SHA256    string    `json:"sha256"`
+	PEM       string    `json:"pem"`
+}
+
+// NewCertificate summarizes a certificate.
+func NewCertificate(certificate *x509.Certificate) Certificate {
+	fingerprint := sha256.Sum256(certificate.Raw)
+	return Certificate{
+		Subject:   certificate.Subject.String(),
+		Issuer:    certificate.Issuer.String(),
+		Serial:    certificate.SerialNumber.String(),
+		NotBefore: certificate.NotBefore.UTC(),
+		NotAfter:  certificate.NotAfter.UTC(),
+		SHA256:    hex.EncodeToString(fingerprint[:]),
+		PEM: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: certificate.Raw,
+		})),
+	}
+}
+
+// Entities returns the active services of the list that play a role in
+// the EUDI wallet ecosystem.
+func (l *List) Entities() []Entity {
+	var entities []Entity
+	for _, provider := range l.Providers {
+		for _, service := range provider.Services {
+			role, ok := service.Role()
+			if !ok || !service.Active() {
+				continue
+			}
+			entity := Entity{
+				Role:         role,
+				ProviderName: provider.Name,
+				TradeName:    provider.TradeName,
+				InfoURI:      provider.InfoURI,
+				ServiceName:  service.Name,
+				ServiceType:  service.Type,
+				Status:       service.Status,
+				SupplyPoints: service.SupplyPoints,
+				Certificates: []Certificate{},
+			}
+			for _, certificate := range service.Certificates {
+				entity.Certificates = append(entity.Certificates, NewCertificate(certificate))
+			}
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+func parseIdentities(identities ...xmlIdentity) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for _, identity := range identities {
+		for _, value := range identity.Certificates {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			certificate, err := ParseCertificate(value)
+			if err != nil {
+				return nil, err
+			}
+			certificates = append(certificates, certificate)
+		}
+	}
+	return certificates, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// englishText picks the English variant of a multilingual name, falling
+// back to the first one.
+func englishText(values []xmlText) string {
+	for _, value := range values {
+		if strings.EqualFold(value.Lang, "en") {
+			return strings.TrimSpace(value.Value)
+		}
+	}
+	if len(values) > 0 {
+		return strings.TrimSpace(values[0].Value)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ParseCertificatesPEM decodes the PEM encoded certificates the List of
+// Trusted Lists signers are pinned to.
+func ParseCertificatesPEM(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return certificates, nil
+}
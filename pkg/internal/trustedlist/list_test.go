@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testLOTL(national testSigner, location string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<TrustServiceStatusList xmlns="http://uri.etsi.org/02231/v2#" `+
+		`xmlns:ns3="http://uri.etsi.org/02231/v2/additionaltypes#" Id="lotl">
+  <SchemeInformation>
+    <TSLVersionIdentifier>6</TSLVersionIdentifier>
+    <TSLSequenceNumber>342</TSLSequenceNumber>
+    <TSLType>http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUlistofthelists</TSLType>
+    <SchemeOperatorName>
+      <Name xml:lang="fr">Commission europeenne</Name>
+      <Name xml:lang="en">European Commission</Name>
+    </SchemeOperatorName>
+    <SchemeTerritory>EU</SchemeTerritory>
+    <PointersToOtherTSL>
+      <OtherTSLPointer>
+        <ServiceDigitalIdentities><ServiceDigitalIdentity><DigitalId>
+          <X509Certificate>%[1]s</X509Certificate>
+        </DigitalId></ServiceDigitalIdentity></ServiceDigitalIdentities>
+        <TSLLocation>%[2]s</TSLLocation>
+        <AdditionalInformation>
+          <OtherInformation><TSLType>%[3]s</TSLType></OtherInformation>
+          <OtherInformation><SchemeTerritory>IT</SchemeTerritory></OtherInformation>
+          <OtherInformation><ns3:MimeType>application/vnd.etsi.tsl+xml</ns3:MimeType>`+
+		`</OtherInformation>
+        </AdditionalInformation>
+      </OtherTSLPointer>
+      <OtherTSLPointer>
+        <ServiceDigitalIdentities><ServiceDigitalIdentity><DigitalId>
+          <X509Certificate>%[1]s</X509Certificate>
+        </DigitalId></ServiceDigitalIdentity></ServiceDigitalIdentities>
+        <TSLLocation>https://trust.example.it/tl.pdf</TSLLocation>
+        <AdditionalInformation>
+          <OtherInformation><SchemeTerritory>IT</SchemeTerritory></OtherInformation>
+          <OtherInformation><ns3:MimeType>application/pdf</ns3:MimeType></OtherInformation>
+        </AdditionalInformation>
+      </OtherTSLPointer>
+    </PointersToOtherTSL>
+    <ListIssueDateTime>2026-09-01T00:00:00Z</ListIssueDateTime>
+    <NextUpdate><dateTime>2027-03-01T00:00:00Z</dateTime></NextUpdate>
+  </SchemeInformation>
+</TrustServiceStatusList>
+`, national.encodedCertificate(), location, TypeGeneric)
+}
+
+func testService(serviceType, name, status string, certificate string, supplyPoint string) string {
+	return fmt.Sprintf(`
+      <TSPService><ServiceInformation>
+        <ServiceTypeIdentifier>%s</ServiceTypeIdentifier>
+        <ServiceName><Name xml:lang="en">%s</Name></ServiceName>
+        <ServiceDigitalIdentity><DigitalId>
+          <X509Certificate>%s</X509Certificate>
+        </DigitalId></ServiceDigitalIdentity>
+        <ServiceStatus>http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/%s</ServiceStatus>
+        <StatusStartingTime>2026-06-01T00:00:00Z</StatusStartingTime>
+        <ServiceSupplyPoints><ServiceSupplyPoint>%s</ServiceSupplyPoint></ServiceSupplyPoints>
+      </ServiceInformation></TSPService>`,
+		serviceType, name, certificate, status, supplyPoint)
+}
+
+func withTSLPrefix(unprefixed string) string {
+	return strings.NewReplacer("</", "</tsl:", "<", "<tsl:").Replace(unprefixed)
+}
+
+func testNationalList(t *testing.T) string {
+	t.Helper()
+	service := newTestSigner(t, "PID Provider").encodedCertificate()
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<tsl:TrustServiceStatusList xmlns:tsl="http://uri.etsi.org/02231/v2#" Id="tl-it">
+  <tsl:SchemeInformation>
+    <tsl:TSLSequenceNumber>12</tsl:TSLSequenceNumber>
+    <tsl:TSLType>http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUgeneric</tsl:TSLType>
+    <tsl:SchemeOperatorName><tsl:Name xml:lang="en">AgID</tsl:Name></tsl:SchemeOperatorName>
+    <tsl:SchemeTerritory>IT</tsl:SchemeTerritory>
+    <tsl:ListIssueDateTime>2026-09-15T10:00:00Z</tsl:ListIssueDateTime>
+  </tsl:SchemeInformation>
+  <tsl:TrustServiceProviderList>
+    <tsl:TrustServiceProvider>
+      <tsl:TSPInformation>
+        <tsl:TSPName><tsl:Name xml:lang="it">Istituto</tsl:Name>` +
+		`<tsl:Name xml:lang="en">State Printing Institute</tsl:Name></tsl:TSPName>
+        <tsl:TSPTradeName><tsl:Name xml:lang="en">VATIT-00399810589</tsl:Name></tsl:TSPTradeName>
+        <tsl:TSPInformationURI><tsl:URI xml:lang="en">https://ipzs.example.it</tsl:URI>` +
+		`</tsl:TSPInformationURI>
+      </tsl:TSPInformation>
+      <tsl:TSPServices>` +
+		withTSLPrefix(strings.Join([]string{
+			testService(
+				"http://uri.etsi.org/TrstSvc/Svctype/PID",
+				"PID issuance", "granted", service, "https://pid.example.it",
+			),
+			testService(
+				"http://uri.etsi.org/TrstSvc/Svctype/EAA/Q",
+				"Diploma QEAA", "granted", service, "https://eaa.example.it",
+			),
+			testService(
+				"http://uri.etsi.org/TrstSvc/Svctype/PID",
+				"Old PID issuance", "withdrawn", service, "https://old-pid.example.it",
+			),
+			testService(
+				"http://uri.etsi.org/TrstSvc/Svctype/CA/QC",
+				"Qualified CA", "granted", service, "https://ca.example.it",
+			),
+		}, "")) + `
+      </tsl:TSPServices>
+    </tsl:TrustServiceProvider>
+    <tsl:TrustServiceProvider>
+      <tsl:TSPInformation>
+        <tsl:TSPName><tsl:Name xml:lang="en">Example Bank</tsl:Name></tsl:TSPName>
+      </tsl:TSPInformation>
+      <tsl:TSPServices>` +
+		withTSLPrefix(testService(
+			"http://uri.etsi.org/TrstSvc/Svctype/WalletRelyingParty",
+			"Account opening", "recognisedatnationallevel", service, "https://bank.example.it/rp",
+		)) + `
+      </tsl:TSPServices>
+    </tsl:TrustServiceProvider>
+  </tsl:TrustServiceProviderList>
+</tsl:TrustServiceStatusList>
+`
+}
+
+func TestParseListOfTheLists(t *testing.T) {
+	national := newTestSigner(t, "IT signer")
+	list, err := Parse([]byte(testLOTL(national, "https://trust.example.it/tl.xml")))
+	require.NoError(t, err)
+
+	require.Equal(t, TypeListOfTheLists, list.Type)
+	require.Equal(t, 342, list.SequenceNumber)
+	require.Equal(t, "EU", list.Territory)
+	require.Equal(t, "European Commission", list.OperatorName)
+	require.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), list.IssueDate)
+	require.Equal(t, time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC), list.NextUpdate)
+	require.Len(t, list.Pointers, 2)
+
+	pointers := list.NationalLists()
+	require.Len(t, pointers, 1)
+	require.Equal(t, "https://trust.example.it/tl.xml", pointers[0].Location)
+	require.Equal(t, "IT", pointers[0].Territory)
+	require.Equal(t, TypeGeneric, pointers[0].Type)
+	require.Len(t, pointers[0].Certificates, 1)
+	require.Equal(t, national.certificate.Raw, pointers[0].Certificates[0].Raw)
+}
+
+func TestParseNationalList(t *testing.T) {
+	list, err := Parse([]byte(testNationalList(t)))
+	require.NoError(t, err)
+	require.Equal(t, "IT", list.Territory)
+	require.Equal(t, "AgID", list.OperatorName)
+	require.Len(t, list.Providers, 2)
+	require.Equal(t, "State Printing Institute", list.Providers[0].Name)
+	require.Len(t, list.Providers[0].Services, 4)
+
+	entities := list.Entities()
+	require.Len(t, entities, 3)
+
+	pid := entities[0]
+	require.Equal(t, RolePIDProvider, pid.Role)
+	require.Equal(t, "State Printing Institute", pid.ProviderName)
+	require.Equal(t, "VATIT-00399810589", pid.TradeName)
+	require.Equal(t, "PID issuance", pid.ServiceName)
+	require.Equal(t, "https://pid.example.it", pid.URL())
+	require.Len(t, pid.Certificates, 1)
+	require.Equal(t, "CN=PID Provider,C=EU", pid.Certificates[0].Subject)
+	require.Len(t, pid.Certificates[0].SHA256, 64)
+	block, _ := pem.Decode([]byte(pid.Certificates[0].PEM))
+	require.NotNil(t, block)
+
+	require.Equal(t, RoleEAAProvider, entities[1].Role)
+	require.Equal(t, "Diploma QEAA", entities[1].ServiceName)
+	require.Equal(t, RoleRelyingParty, entities[2].Role)
+	require.Equal(t, "Example Bank", entities[2].ProviderName)
+	require.Equal(t, "https://bank.example.it/rp", entities[2].URL())
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte(`<TrustServiceStatusList/>`))
+	require.ErrorContains(t, err, "unexpected trusted list namespace")
+
+	_, err = Parse([]byte(`not xml`))
+	require.Error(t, err)
+
+	_, err = Parse([]byte(`<TrustServiceStatusList xmlns="http://uri.etsi.org/02231/v2#">` +
+		`<SchemeInformation><ListIssueDateTime>yesterday</ListIssueDateTime>` +
+		`</SchemeInformation></TrustServiceStatusList>`))
+	require.ErrorContains(t, err, "ListIssueDateTime")
+}
+
+func TestServiceRole(t *testing.T) {
+	tests := []struct {
+		serviceType string
+		role        Role
+		ok          bool
+	}{
+		{"http://uri.etsi.org/TrstSvc/Svctype/PID", RolePIDProvider, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/PID/", RolePIDProvider, true},
+		{"http://uri.etsi.org/Svc/Svctype/Provider/PID", RolePIDProvider, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/EAA", RoleEAAProvider, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/EAA/Q", RoleEAAProvider, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/EAA/Pub-EAA", RoleEAAProvider, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/RelyingParty", RoleRelyingParty, true},
+		{"http://uri.etsi.org/TrstSvc/Svctype/CA/QC", "", false},
+		{"http://uri.etsi.org/TrstSvc/Svctype/TSA/QTST", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.serviceType, func(t *testing.T) {
+			role, ok := Service{Type: tt.serviceType}.Role()
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.role, role)
+		})
+	}
+	require.True(t, RolePIDProvider.IsIssuer())
+	require.True(t, RoleEAAProvider.IsIssuer())
+	require.False(t, RoleRelyingParty.IsIssuer())
+}
+
+func TestServiceActive(t *testing.T) {
+	prefix := "http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/"
+	require.True(t, Service{Status: prefix + "granted"}.Active())
+	require.True(t, Service{Status: prefix + "recognisedatnationallevel"}.Active())
+	require.False(t, Service{Status: prefix + "withdrawn"}.Active())
+	require.False(t, Service{Status: prefix + "deprecatedatnationallevel"}.Active())
+}
+
+func TestParseTrustedCertificates(t *testing.T) {
+	first := newTestSigner(t, "First")
+	second := newTestSigner(t, "Second")
+	encoded := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: first.certificate.Raw,
+	})) + string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: second.certificate.Raw,
+	}))
+
+	certificates, err := ParseTrustedCertificates([]string{encoded, first.encodedCertificate()})
+	require.NoError(t, err)
+	require.Len(t, certificates, 3)
+	require.Equal(t, "CN=Second,C=EU", certificates[1].Subject.String())
+
+	_, err = ParseTrustedCertificates([]string{"not a certificate"})
+	require.Error(t, err)
+	_, err = ParseCertificatesPEM([]byte("no pem here"))
+	require.ErrorContains(t, err, "no PEM certificates")
+}
@@ -0,0 +1,412 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// XML namespaces of the signature elements.
+const (
+	NamespaceDSig  = "http://www.w3.org/2000/09/xmldsig#"
+	NamespaceXAdES = "http://uri.etsi.org/01903/v1.3.2#"
+)
+
+const transformEnveloped = string(dsig.EnvelopedSignatureAltorithmId)
+
+var digestAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2001/04/xmlenc#sha256":         crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#sha384":   crypto.SHA384,
+	"http://www.w3.org/2001/04/xmlenc#sha512":         crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#sha224":   crypto.SHA224,
+	"http://www.w3.org/2007/05/xmldsig-more#sha3-256": crypto.SHA3_256,
+}
+
+type signatureAlgorithm struct {
+	hash crypto.Hash
+	kind string
+}
+
+var signatureAlgorithms = map[string]signatureAlgorithm{
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":      {crypto.SHA256, "rsa"},
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384":      {crypto.SHA384, "rsa"},
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":      {crypto.SHA512, "rsa"},
+	"http://www.w3.org/2007/05/xmldsig-more#sha256-rsa-MGF1": {crypto.SHA256, "rsa-pss"},
+	"http://www.w3.org/2007/05/xmldsig-more#sha384-rsa-MGF1": {crypto.SHA384, "rsa-pss"},
+	"http://www.w3.org/2007/05/xmldsig-more#sha512-rsa-MGF1": {crypto.SHA512, "rsa-pss"},
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256":    {crypto.SHA256, "ecdsa"},
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384":    {crypto.SHA384, "ecdsa"},
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512":    {crypto.SHA512, "ecdsa"},
+}
+
+// ErrNoTrustedSigners is returned when a list is verified without any
+// certificate to pin its signer to.
+var ErrNoTrustedSigners = errors.New("no trusted signing certificates configured")
+
+// Signature describes a verified trusted list signature.
+type Signature struct {
+	Signer      *x509.Certificate
+	Algorithm   string
+	SigningTime string
+}
+
+// VerifySignature checks the enveloped XAdES signature of a trusted list:
+// the reference digests, the signature value, the signing certificate
+// digest in the signed properties and that the signer is one of trusted.
+func VerifySignature(data []byte, trusted []*x509.Certificate) (*Signature, error) {
+	if len(trusted) == 0 {
+		return nil, ErrNoTrustedSigners
+	}
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse XML: %w", err)
+	}
+	return verifyDocument(root, trusted)
+}
+
+func verifyDocument(root *etree.Element, trusted []*x509.Certificate) (*Signature, error) {
+	var signature *etree.Element
+	for _, c := range root.ChildElements() {
+		if !is(c, NamespaceDSig, "Signature") {
+			continue
+		}
+		if signature != nil {
+			return nil, errors.New("trusted list has more than one signature")
+		}
+		signature = c
+	}
+	if signature == nil {
+		return nil, errors.New("trusted list is not signed")
+	}
+	signedInfo := child(signature, NamespaceDSig, "SignedInfo")
+	if signedInfo == nil {
+		return nil, errors.New("signature has no SignedInfo")
+	}
+
+	signer, err := signingCertificate(signature)
+	if err != nil {
+		return nil, err
+	}
+	if !isTrusted(signer, trusted) {
+		return nil, fmt.Errorf(
+			"trusted list signed by untrusted certificate %q",
+			signer.Subject.String(),
+		)
+	}
+
+	var coversList, coversProperties bool
+	var signedProperties *etree.Element
+	for _, reference := range signedInfo.ChildElements() {
+		if !is(reference, NamespaceDSig, "Reference") {
+			continue
+		}
+		target, err := verifyReference(root, signature, reference)
+		if err != nil {
+			return nil, err
+		}
+		if target == root {
+			coversList = true
+		}
+		if is(target, NamespaceXAdES, "SignedProperties") {
+			coversProperties = true
+			signedProperties = target
+		}
+	}
+	if !coversList {
+		return nil, errors.New("signature does not cover the trusted list")
+	}
+	if !coversProperties {
+		return nil, errors.New("signature has no signed XAdES properties")
+	}
+	if err := verifySigningCertificateDigest(signedProperties, signer); err != nil {
+		return nil, err
+	}
+
+	method := child(signedInfo, NamespaceDSig, "SignatureMethod")
+	if method == nil {
+		return nil, errors.New("signature has no SignatureMethod")
+	}
+	algorithm := attr(method, "Algorithm")
+	canonical, err := canonicalizeWith(
+		child(signedInfo, NamespaceDSig, "CanonicalizationMethod"),
+		signedInfo,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	value := child(signature, NamespaceDSig, "SignatureValue")
+	if value == nil {
+		return nil, errors.New("signature has no SignatureValue")
+	}
+	signatureValue, err := decodeBase64(value.Text())
+	if err != nil {
+		return nil, fmt.Errorf("decode SignatureValue: %w", err)
+	}
+	if err := verifySignatureValue(signer, algorithm, canonical, signatureValue); err != nil {
+		return nil, err
+	}
+
+	result := &Signature{Signer: signer, Algorithm: algorithm}
+	if props := child(signedProperties, NamespaceXAdES, "SignedSignatureProperties"); props != nil {
+		if signingTime := child(props, NamespaceXAdES, "SigningTime"); signingTime != nil {
+			result.SigningTime = strings.TrimSpace(signingTime.Text())
+		}
+	}
+	return result, nil
+}
+
+func signingCertificate(signature *etree.Element) (*x509.Certificate, error) {
+	keyInfo := child(signature, NamespaceDSig, "KeyInfo")
+	if keyInfo == nil {
+		return nil, errors.New("signature has no KeyInfo")
+	}
+	certificate := find(keyInfo, func(el *etree.Element) bool {
+		return is(el, NamespaceDSig, "X509Certificate")
+	})
+	if certificate == nil {
+		return nil, errors.New("signature has no X509Certificate")
+	}
+	return ParseCertificate(certificate.Text())
+}
+
+// ParseCertificate decodes a base64 DER certificate as found in
+// X509Certificate elements, ignoring whitespace.
+func ParseCertificate(value string) (*x509.Certificate, error) {
+	der, err := decodeBase64(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode certificate: %w", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	return certificate, nil
+}
+
+func isTrusted(signer *x509.Certificate, trusted []*x509.Certificate) bool {
+	for _, candidate := range trusted {
+		if candidate == nil {
+			continue
+		}
+		if bytes.Equal(candidate.Raw, signer.Raw) {
+			return true
+		}
+		// Scheme operators renew certificates over the same key pair.
+		if bytes.Equal(candidate.RawSubjectPublicKeyInfo, signer.RawSubjectPublicKeyInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyReference checks the digest of a Reference and returns the element
+// it points to. A fragment URI must identify exactly one element, so that
+// a wrapped copy of the signed content cannot stand in for it.
+func verifyReference(root, signature, reference *etree.Element) (*etree.Element, error) {
+	uri := attr(reference, "URI")
+	var target *etree.Element
+	switch {
+	case uri == "":
+		target = root
+	case strings.HasPrefix(uri, "#"):
+		id := strings.TrimPrefix(uri, "#")
+		matches := findAll(root, func(el *etree.Element) bool {
+			return attr(el, "Id") == id || attr(el, "ID") == id || attr(el, "id") == id
+		})
+		if len(matches) > 1 {
+			return nil, fmt.Errorf("reference %q matches %d elements", uri, len(matches))
+		}
+		if len(matches) == 1 {
+			target = matches[0]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("unresolvable reference %q", uri)
+	}
+
+	var exclude *etree.Element
+	var method *etree.Element
+	if transforms := child(reference, NamespaceDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.ChildElements() {
+			switch algorithm := attr(transform, "Algorithm"); algorithm {
+			case transformEnveloped:
+				exclude = signature
+			case AlgorithmC14N, AlgorithmC14N11, AlgorithmExcC14N:
+				method = transform
+			default:
+				return nil, fmt.Errorf("unsupported transform %q", algorithm)
+			}
+		}
+	}
+	canonical, err := canonicalizeWith(method, target, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	digestMethod := child(reference, NamespaceDSig, "DigestMethod")
+	digestValue := child(reference, NamespaceDSig, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return nil, fmt.Errorf("reference %q has no digest", uri)
+	}
+	digest, err := computeDigest(attr(digestMethod, "Algorithm"), canonical)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := decodeBase64(digestValue.Text())
+	if err != nil {
+		return nil, fmt.Errorf("decode digest of reference %q: %w", uri, err)
+	}
+	if subtle.ConstantTimeCompare(digest, expected) != 1 {
+		return nil, fmt.Errorf("digest mismatch for reference %q", uri)
+	}
+	return target, nil
+}
+
+// canonicalizeWith serializes target with the algorithm of method, which
+// defaults to Canonical XML 1.0 when absent.
+func canonicalizeWith(method, target, exclude *etree.Element) ([]byte, error) {
+	algorithm := AlgorithmC14N
+	prefixList := ""
+	if method != nil {
+		algorithm = attr(method, "Algorithm")
+		if inclusive := child(method, AlgorithmExcC14N, "InclusiveNamespaces"); inclusive != nil {
+			prefixList = attr(inclusive, "PrefixList")
+		}
+	}
+	return canonicalize(algorithm, prefixList, target, exclude)
+}
+
+func verifySigningCertificateDigest(signedProperties *etree.Element, signer *x509.Certificate) error {
+	signingCertificate := find(signedProperties, func(el *etree.Element) bool {
+		return is(el, NamespaceXAdES, "SigningCertificateV2") ||
+			is(el, NamespaceXAdES, "SigningCertificate")
+	})
+	if signingCertificate == nil {
+		return errors.New("signed properties have no SigningCertificate")
+	}
+	for _, cert := range signingCertificate.ChildElements() {
+		certDigest := child(cert, NamespaceXAdES, "CertDigest")
+		if certDigest == nil {
+			continue
+		}
+		method := child(certDigest, NamespaceDSig, "DigestMethod")
+		value := child(certDigest, NamespaceDSig, "DigestValue")
+		if method == nil || value == nil {
+			continue
+		}
+		digest, err := computeDigest(attr(method, "Algorithm"), signer.Raw)
+		if err != nil {
+			return err
+		}
+		expected, err := decodeBase64(value.Text())
+		if err != nil {
+			return fmt.Errorf("decode CertDigest: %w", err)
+		}
+		if subtle.ConstantTimeCompare(digest, expected) == 1 {
+			return nil
+		}
+	}
+	return errors.New("signing certificate does not match the signed properties")
+}
+
+func computeDigest(algorithm string, data []byte) ([]byte, error) {
+	h, ok := digestAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	hasher := newHash(h)
+	hasher.Write(data)
+	return hasher.Sum(nil), nil
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	switch h {
+	case crypto.SHA224:
+		return sha256.New224()
+	case crypto.SHA256:
+		return sha256.New()
+	case crypto.SHA384:
+		return sha512.New384()
+	case crypto.SHA512:
+		return sha512.New()
+	default:
+		return h.New()
+	}
+}
+
+func verifySignatureValue(
+	signer *x509.Certificate,
+	algorithm string,
+	signed []byte,
+	value []byte,
+) error {
+	method, ok := signatureAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+	hasher := newHash(method.hash)
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch key := signer.PublicKey.(type) {
+	case *rsa.PublicKey:
+		switch method.kind {
+		case "rsa":
+			if err := rsa.VerifyPKCS1v15(key, method.hash, digest, value); err != nil {
+				return fmt.Errorf("invalid signature: %w", err)
+			}
+			return nil
+		case "rsa-pss":
+			if err := rsa.VerifyPSS(key, method.hash, digest, value, nil); err != nil {
+				return fmt.Errorf("invalid signature: %w", err)
+			}
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if method.kind != "ecdsa" {
+			break
+		}
+		// XML-DSig encodes ECDSA signatures as the concatenation r || s.
+		if len(value) == 0 || len(value)%2 != 0 {
+			return errors.New("invalid signature: malformed ECDSA value")
+		}
+		half := len(value) / 2
+		r := new(big.Int).SetBytes(value[:half])
+		s := new(big.Int).SetBytes(value[half:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("signature algorithm %q does not match the signing key", algorithm)
+}
+
+func decodeBase64(value string) ([]byte, error) {
+	compact := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, value)
+	return base64.StdEncoding.DecodeString(compact)
+}
@@ -0,0 +1,395 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package trustedlist
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/require"
+)
+
+type testSigner struct {
+	key         crypto.Signer
+	certificate *x509.Certificate
+}
+
+func newTestSigner(t *testing.T, name string) testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return newTestSignerWithKey(t, name, key)
+}
+
+func newTestSignerWithKey(t *testing.T, name string, key crypto.Signer) testSigner {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name, Country: []string{"EU"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testSigner{key: key, certificate: certificate}
+}
+
+func (s testSigner) encodedCertificate() string {
+	return base64.StdEncoding.EncodeToString(s.certificate.Raw)
+}
+
+const testSignatureTemplate = `<ds:Signature ` +
+	`xmlns:ds="http://www.w3.org/2000/09/xmldsig#" Id="sig">` +
+	`<ds:SignedInfo>` +
+	`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+	`<ds:SignatureMethod Algorithm="SIGNATURE_METHOD"/>` +
+	`<ds:Reference Id="ref-list" URI="">` +
+	`<ds:Transforms>` +
+	`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>` +
+	`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+	`</ds:Transforms>` +
+	`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+	`<ds:DigestValue>LIST_DIGEST</ds:DigestValue>` +
+	`</ds:Reference>` +
+	`<ds:Reference Type="http://uri.etsi.org/01903#SignedProperties" URI="#xades-sig">` +
+	`<ds:Transforms>` +
+	`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+	`</ds:Transforms>` +
+	`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+	`<ds:DigestValue>PROPERTIES_DIGEST</ds:DigestValue>` +
+	`</ds:Reference>` +
+	`</ds:SignedInfo>` +
+	`<ds:SignatureValue>SIGNATURE_VALUE</ds:SignatureValue>` +
+	`<ds:KeyInfo><ds:X509Data><ds:X509Certificate>CERTIFICATE</ds:X509Certificate>` +
+	`</ds:X509Data></ds:KeyInfo>` +
+	`<ds:Object>` +
+	`<xades:QualifyingProperties xmlns:xades="http://uri.etsi.org/01903/v1.3.2#" Target="#sig">` +
+	`<xades:SignedProperties Id="xades-sig">` +
+	`<xades:SignedSignatureProperties>` +
+	`<xades:SigningTime>2026-01-02T03:04:05Z</xades:SigningTime>` +
+	`<xades:SigningCertificateV2><xades:Cert><xades:CertDigest>` +
+	`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+	`<ds:DigestValue>CERTIFICATE_DIGEST</ds:DigestValue>` +
+	`</xades:CertDigest></xades:Cert></xades:SigningCertificateV2>` +
+	`</xades:SignedSignatureProperties>` +
+	`</xades:SignedProperties>` +
+	`</xades:QualifyingProperties>` +
+	`</ds:Object>` +
+	`</ds:Signature>`
+
+// sign appends an enveloped XAdES-BES signature to the root element of
+// unsigned, the way trusted list operators sign their lists.
+func (s testSigner) sign(t *testing.T, unsigned string) string {
+	t.Helper()
+	method := "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	if _, ok := s.key.(*rsa.PrivateKey); ok {
+		method = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	}
+	certificateDigest := sha256.Sum256(s.certificate.Raw)
+	signature := strings.NewReplacer(
+		"SIGNATURE_METHOD", method,
+		"CERTIFICATE_DIGEST", base64.StdEncoding.EncodeToString(certificateDigest[:]),
+		">CERTIFICATE<", ">"+s.encodedCertificate()+"<",
+	).Replace(testSignatureTemplate)
+
+	end := strings.LastIndex(unsigned, "</")
+	document := unsigned[:end] + signature + unsigned[end:]
+
+	digestOf := func(document, uri string) string {
+		root, err := parseDocument([]byte(document))
+		require.NoError(t, err)
+		signature := child(root, NamespaceDSig, "Signature")
+		signedInfo := child(signature, NamespaceDSig, "SignedInfo")
+		for _, reference := range signedInfo.ChildElements() {
+			if !is(reference, NamespaceDSig, "Reference") || attr(reference, "URI") != uri {
+				continue
+			}
+			target := root
+			var exclude *etree.Element
+			if uri != "" {
+				target = find(root, func(el *etree.Element) bool {
+					return attr(el, "Id") == strings.TrimPrefix(uri, "#")
+				})
+			} else {
+				exclude = signature
+			}
+			canonical, err := canonicalize(AlgorithmExcC14N, "", target, exclude)
+			require.NoError(t, err)
+			digest := sha256.Sum256(canonical)
+			return base64.StdEncoding.EncodeToString(digest[:])
+		}
+		t.Fatalf("no reference %q", uri)
+		return ""
+	}
+	document = strings.Replace(
+		document, "PROPERTIES_DIGEST", digestOf(document, "#xades-sig"), 1,
+	)
+	document = strings.Replace(document, "LIST_DIGEST", digestOf(document, ""), 1)
+
+	root, err := parseDocument([]byte(document))
+	require.NoError(t, err)
+	signedInfo := child(child(root, NamespaceDSig, "Signature"), NamespaceDSig, "SignedInfo")
+	canonical, err := canonicalize(AlgorithmExcC14N, "", signedInfo, nil)
+	require.NoError(t, err)
+	hashed := sha256.Sum256(canonical)
+
+	var value []byte
+	switch key := s.key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hashed[:])
+		require.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		value = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+	case *rsa.PrivateKey:
+		value, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		require.NoError(t, err)
+	}
+	return strings.Replace(
+		document, "SIGNATURE_VALUE", base64.StdEncoding.EncodeToString(value), 1,
+	)
+}
+
+const testUnsignedList = `<?xml version="1.0" encoding="UTF-8"?>
+<tsl:TrustServiceStatusList xmlns:tsl="http://uri.etsi.org/02231/v2#" Id="tsl" ` +
+	`TSLTag="http://uri.etsi.org/19612/TSLTag">
+  <tsl:SchemeInformation>
+    <tsl:TSLSequenceNumber>7</tsl:TSLSequenceNumber>
+  </tsl:SchemeInformation>
+</tsl:TrustServiceStatusList>
+`
+
+func TestVerifySignature(t *testing.T) {
+	signer := newTestSigner(t, "LOTL signer")
+	signed := signer.sign(t, testUnsignedList)
+
+	signature, err := VerifySignature([]byte(signed), []*x509.Certificate{signer.certificate})
+	require.NoError(t, err)
+	require.Equal(t, "CN=LOTL signer,C=EU", signature.Signer.Subject.String())
+	require.Equal(t, "2026-01-02T03:04:05Z", signature.SigningTime)
+	require.Equal(t, "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256", signature.Algorithm)
+}
+
+func TestVerifySignatureRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := newTestSignerWithKey(t, "RSA signer", key)
+	signed := signer.sign(t, testUnsignedList)
+
+	_, err = VerifySignature([]byte(signed), []*x509.Certificate{signer.certificate})
+	require.NoError(t, err)
+}
+
+func TestVerifySignatureRenewedCertificate(t *testing.T) {
+	signer := newTestSigner(t, "LOTL signer")
+	renewed := newTestSignerWithKey(t, "LOTL signer 2027", signer.key)
+	signed := signer.sign(t, testUnsignedList)
+
+	_, err := VerifySignature([]byte(signed), []*x509.Certificate{renewed.certificate})
+	require.NoError(t, err)
+}
+
+func TestVerifySignatureFailures(t *testing.T) {
+	signer := newTestSigner(t, "LOTL signer")
+	other := newTestSigner(t, "Someone else")
+	signed := signer.sign(t, testUnsignedList)
+	trusted := []*x509.Certificate{signer.certificate}
+
+	tests := []struct {
+		name     string
+		document string
+		trusted  []*x509.Certificate
+		err      string
+	}{
+		{
+			name:     "no trusted certificates",
+			document: signed,
+			err:      ErrNoTrustedSigners.Error(),
+		},
+		{
+			name:     "untrusted signer",
+			document: signed,
+			trusted:  []*x509.Certificate{other.certificate},
+			err:      "untrusted certificate",
+		},
+		{
+			name:     "unsigned",
+			document: testUnsignedList,
+			trusted:  trusted,
+			err:      "not signed",
+		},
+		{
+			name: "tampered list",
+			document: strings.Replace(
+				signed,
+				"<tsl:TSLSequenceNumber>7<",
+				"<tsl:TSLSequenceNumber>8<",
+				1,
+			),
+			trusted: trusted,
+			err:     `digest mismatch for reference ""`,
+		},
+		{
+			name: "tampered signed properties",
+			document: strings.Replace(
+				signed,
+				"2026-01-02T03:04:05Z",
+				"2027-01-02T03:04:05Z",
+				1,
+			),
+			trusted: trusted,
+			err:     `digest mismatch for reference "#xades-sig"`,
+		},
+		{
+			name: "tampered signed info",
+			document: strings.Replace(
+				signed,
+				`<ds:Reference Id="ref-list" URI="">`,
+				`<ds:Reference Id="ref-other" URI="">`,
+				1,
+			),
+			trusted: trusted,
+			err:     "invalid signature",
+		},
+		{
+			name: "swapped key info",
+			document: strings.Replace(
+				signed,
+				signer.encodedCertificate(),
+				other.encodedCertificate(),
+				1,
+			),
+			trusted: []*x509.Certificate{signer.certificate, other.certificate},
+			err:     "does not match the signed properties",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifySignature([]byte(tt.document), tt.trusted)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestVerifySignatureRequiresListReference(t *testing.T) {
+	signer := newTestSigner(t, "LOTL signer")
+	signed := signer.sign(t, testUnsignedList)
+	root, err := parseDocument([]byte(signed))
+	require.NoError(t, err)
+
+	signedInfo := child(child(root, NamespaceDSig, "Signature"), NamespaceDSig, "SignedInfo")
+	for _, reference := range signedInfo.ChildElements() {
+		if attr(reference, "Id") == "ref-list" {
+			signedInfo.RemoveChild(reference)
+		}
+	}
+
+	_, err = verifyDocument(root, []*x509.Certificate{signer.certificate})
+	require.ErrorContains(t, err, "does not cover the trusted list")
+}
+
+// TestVerifySignatureWrappingAttacks moves the signed content around and
+// injects forged content, as signature wrapping attacks do: none of the
+// rearranged documents may verify.
+func TestVerifySignatureWrappingAttacks(t *testing.T) {
+	signer := newTestSigner(t, "LOTL signer")
+	trusted := []*x509.Certificate{signer.certificate}
+	signed := signer.sign(t, testUnsignedList)
+	_, err := VerifySignature([]byte(signed), trusted)
+	require.NoError(t, err)
+
+	start := strings.Index(signed, "<xades:SignedProperties")
+	end := strings.Index(signed, "</xades:SignedProperties>") + len("</xades:SignedProperties>")
+	signedProperties := signed[start:end]
+	forgedProperties := strings.Replace(
+		signedProperties, "2026-01-02T03:04:05Z", "2030-01-02T03:04:05Z", 1,
+	)
+	signatureStart := strings.Index(signed, "<ds:Signature ")
+	signatureEnd := strings.Index(signed, "</ds:Signature>") + len("</ds:Signature>")
+	signature := signed[signatureStart:signatureEnd]
+
+	tests := []struct {
+		name     string
+		document string
+		err      string
+	}{
+		{
+			name: "forged signed properties after the original",
+			document: strings.Replace(
+				signed,
+				"</ds:Signature>",
+				`<ds:Object><xades:QualifyingProperties `+
+					`xmlns:xades="http://uri.etsi.org/01903/v1.3.2#" Target="#sig">`+
+					forgedProperties+`</xades:QualifyingProperties></ds:Object></ds:Signature>`,
+				1,
+			),
+			err: `reference "#xades-sig" matches 2 elements`,
+		},
+		{
+			name: "forged signed properties before the original",
+			document: strings.Replace(
+				signed,
+				"<ds:Object>",
+				`<ds:Object><xades:QualifyingProperties `+
+					`xmlns:xades="http://uri.etsi.org/01903/v1.3.2#" Target="#sig">`+
+					forgedProperties+`</xades:QualifyingProperties></ds:Object><ds:Object>`,
+				1,
+			),
+			err: `reference "#xades-sig" matches 2 elements`,
+		},
+		{
+			name: "signed list wrapped under a forged root",
+			// The original signed list is kept intact inside a new root
+			// carrying the forged content.
+			document: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<tsl:TrustServiceStatusList xmlns:tsl="http://uri.etsi.org/02231/v2#" Id="forged">` +
+				`<tsl:SchemeInformation><tsl:TSLSequenceNumber>99</tsl:TSLSequenceNumber>` +
+				`</tsl:SchemeInformation>` +
+				`<tsl:Wrapper>` + strings.TrimPrefix(signed, `<?xml version="1.0" encoding="UTF-8"?>`) +
+				`</tsl:Wrapper>` +
+				`</tsl:TrustServiceStatusList>`,
+			err: "not signed",
+		},
+		{
+			name: "forged list with the original signature",
+			document: strings.Replace(
+				strings.Replace(signed, signature, "", 1),
+				"</tsl:TrustServiceStatusList>",
+				`<tsl:Original>`+signature+`</tsl:Original></tsl:TrustServiceStatusList>`,
+				1,
+			),
+			err: "not signed",
+		},
+		{
+			name: "second signature",
+			document: strings.Replace(
+				signed,
+				"</tsl:TrustServiceStatusList>",
+				signature+"</tsl:TrustServiceStatusList>",
+				1,
+			),
+			err: "more than one signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifySignature([]byte(tt.document), trusted)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
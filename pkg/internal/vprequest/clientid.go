@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/certchain"
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Client identifier schemes, as prefixes of the client_id in OpenID4VP 1.0
+// or as client_id_scheme values in draft 23.
+const (
+	SchemePreRegistered           = "pre-registered"
+	SchemeRedirectURI             = "redirect_uri"
+	SchemeDecentralizedIdentifier = "decentralized_identifier"
+	SchemeDID                     = "did"
+	SchemeVerifierAttestation     = "verifier_attestation"
+	SchemeOpenIDFederation        = "openid_federation"
+	SchemeEntityID                = "entity_id"
+	SchemeOrigin                  = "origin"
+)
+
+var (
+	prefixSchemes = []string{
+		SchemeRedirectURI,
+		SchemeDecentralizedIdentifier,
+		SchemeVerifierAttestation,
+		SchemeOpenIDFederation,
+		SchemeOrigin,
+		certchain.SchemeX509SanDNS,
+		certchain.SchemeX509SanURI,
+		certchain.SchemeX509Hash,
+	}
+	draftSchemes = map[string]bool{
+		SchemePreRegistered:        true,
+		SchemeRedirectURI:          true,
+		SchemeDID:                  true,
+		SchemeVerifierAttestation:  true,
+		SchemeEntityID:             true,
+		certchain.SchemeX509SanDNS: true,
+		certchain.SchemeX509SanURI: true,
+	}
+	signingMethods = []string{
+		"ES256", "ES384", "ES512",
+		"RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512",
+		"EdDSA",
+	}
+)
+
+// SplitClientID returns the scheme and the identifier of client_id, using
+// its prefix or, in draft 23, client_id_scheme. Identifiers without either
+// are pre-registered.
+func SplitClientID(clientID, clientIDScheme string) (string, string) {
+	if clientIDScheme != "" {
+		return clientIDScheme, clientID
+	}
+	for _, scheme := range prefixSchemes {
+		if id, ok := strings.CutPrefix(clientID, scheme+":"); ok {
+			return scheme, id
+		}
+	}
+	return SchemePreRegistered, clientID
+}
+
+func (v *validator) validateClientID() {
+	path := []string{"client_id"}
+	clientID := v.parameter("client_id")
+	if clientID == "" {
+		v.addf(path, "client_id is required")
+		return
+	}
+	if v.request.URLClientID != "" && v.request.URLClientID != clientID {
+		v.addf(
+			path,
+			"client_id %q of the request object differs from %q of the authorization request",
+			clientID,
+			v.request.URLClientID,
+		)
+	}
+	clientIDScheme := v.parameter("client_id_scheme")
+	scheme, id := SplitClientID(clientID, clientIDScheme)
+	v.report.ClientID = id
+	v.report.ClientIDScheme = scheme
+	if clientIDScheme != "" && !draftSchemes[clientIDScheme] {
+		v.addf(
+			[]string{"client_id_scheme"},
+			"unsupported client_id_scheme %q",
+			clientIDScheme,
+		)
+		return
+	}
+
+	if v.request.RequestObject != "" {
+		v.validateRequestObjectType()
+	}
+	switch scheme {
+	case SchemePreRegistered:
+	case SchemeRedirectURI:
+		v.validateRedirectURIClient(id)
+	case certchain.SchemeX509SanDNS, certchain.SchemeX509SanURI, certchain.SchemeX509Hash:
+		v.validateX509Client(scheme, id)
+	case SchemeDecentralizedIdentifier, SchemeDID:
+		v.validateDIDClient(id)
+	case SchemeVerifierAttestation:
+		v.validateAttestedClient(id)
+	case SchemeOpenIDFederation, SchemeEntityID:
+		if !isHTTPSURL(id) {
+			v.addf(path, "federation entity identifier %q is not an https URL", id)
+		}
+		v.requireSigned(scheme)
+	case SchemeOrigin:
+		v.addf(path, "the origin prefix is reserved to the Digital Credentials API")
+	}
+}
+
+func (v *validator) validateRequestObjectType() {
+	if v.report.Version != Version10 || !v.request.Signed() {
+		return
+	}
+	if typ, _ := v.request.Header["typ"].(string); typ != "oauth-authz-req+jwt" {
+		v.addf(
+			[]string{"request", "typ"},
+			"request object typ %q is not oauth-authz-req+jwt",
+			typ,
+		)
+	}
+}
+
+func (v *validator) requireSigned(scheme string) bool {
+	if v.request.Signed() {
+		return true
+	}
+	v.addf([]string{"request"}, "requests of the %s scheme must be signed request objects", scheme)
+	return false
+}
+
+// validateRedirectURIClient checks that the client_id is where responses
+// go, since nothing else authenticates it.
+func (v *validator) validateRedirectURIClient(id string) {
+	if v.request.Signed() {
+		v.addf([]string{"request"}, "requests of the redirect_uri scheme must not be signed")
+	}
+	target := v.parameter("response_uri")
+	name := "response_uri"
+	if target == "" {
+		target = v.parameter("redirect_uri")
+		name = "redirect_uri"
+	}
+	if parsed, err := url.Parse(id); err != nil || parsed.Scheme == "" {
+		v.addf([]string{"client_id"}, "client_id %q is not a URI", id)
+		return
+	}
+	if target != "" && target != id {
+		v.addf([]string{"client_id"}, "client_id %q is not the %s %q", id, name, target)
+	}
+}
+
+// validateX509Client checks that the request object is signed by the leaf
+// of its x5c header and that the leaf is bound to the client_id. Its trust
+// is left to the x509-chain-validation step.
+func (v *validator) validateX509Client(scheme, id string) {
+	if !v.requireSigned(scheme) {
+		return
+	}
+	chain, err := certchain.ParseJWSX5C(v.request.RequestObject)
+	if err != nil {
+		v.addf([]string{"request", "x5c"}, "%s", err.Error())
+		return
+	}
+	if err := certchain.CheckClientID(chain[0], scheme, id); err != nil {
+		v.addf([]string{"client_id"}, "%s", err.Error())
+	}
+	v.verifySignature(chain[0].PublicKey)
+}
+
+// validateDIDClient checks that the request object is signed with a key of
+// the DID. Only did:key and did:jwk signatures are verified here, other
+// methods need the did-resolve step.
+func (v *validator) validateDIDClient(id string) {
+	if !didresolver.IsDID(id) {
+		v.addf([]string{"client_id"}, "client_id %q is not a DID", id)
+		return
+	}
+	if !v.requireSigned(SchemeDecentralizedIdentifier) {
+		return
+	}
+	kid, _ := v.request.Header["kid"].(string)
+	if !strings.HasPrefix(kid, id+"#") {
+		v.addf(
+			[]string{"request", "kid"},
+			"kid %q is not a verification method of %s",
+			kid,
+			id,
+		)
+		return
+	}
+	if _, key, err := didresolver.StaticKey(kid); err == nil {
+		v.verifySignature(key)
+	}
+}
+
+// validateAttestedClient checks that the request object carries a verifier
+// attestation issued to the client_id.
+func (v *validator) validateAttestedClient(id string) {
+	if !v.requireSigned(SchemeVerifierAttestation) {
+		return
+	}
+	path := []string{"request", "jwt"}
+	attestation, _ := v.request.Header["jwt"].(string)
+	if attestation == "" {
+		v.addf(path, "request object has no verifier attestation in its jwt header")
+		return
+	}
+	parts := strings.Split(attestation, ".")
+	if len(parts) != 3 {
+		v.addf(path, "verifier attestation is not a compact JWT")
+		return
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if err == nil {
+		err = json.Unmarshal(raw, &claims)
+	}
+	if err != nil {
+		v.addf(path, "verifier attestation claims: %s", err.Error())
+		return
+	}
+	if claims.Sub != id {
+		v.addf(path, "verifier attestation sub %q is not the client_id %q", claims.Sub, id)
+	}
+}
+
+func (v *validator) verifySignature(key crypto.PublicKey) {
+	parser := jwt.NewParser(jwt.WithValidMethods(signingMethods), jwt.WithoutClaimsValidation())
+	if _, err := parser.Parse(v.request.RequestObject, func(*jwt.Token) (any, error) {
+		return key, nil
+	}); err != nil {
+		v.addf([]string{"request"}, "request object signature: %s", err.Error())
+	}
+}
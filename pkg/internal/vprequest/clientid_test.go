@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/certchain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// signRequest signs parameters as a request object with the given extra
+// header members.
+func signRequest(
+	t testing.TB,
+	key *ecdsa.PrivateKey,
+	parameters map[string]any,
+	header map[string]any,
+) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims(parameters))
+	token.Header["typ"] = "oauth-authz-req+jwt"
+	for name, value := range header {
+		token.Header[name] = value
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// requestObject returns the request carrying jws by value.
+func requestObject(t testing.TB, jws string) *Request {
+	t.Helper()
+	request := &Request{}
+	require.NoError(t, request.setRequestObject(jws))
+	return request
+}
+
+func newTestKey(t testing.TB) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+// newAccessCertificate returns a self-signed certificate for
+// verifier.example.
+func newAccessCertificate(t testing.TB, key *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Relying Party"},
+		DNSNames:     []string{"verifier.example"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func didJWK(t testing.TB, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	public, err := json.Marshal(map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	})
+	require.NoError(t, err)
+	return "did:jwk:" + base64.RawURLEncoding.EncodeToString(public)
+}
+
+func TestSplitClientID(t *testing.T) {
+	tests := []struct {
+		clientID, clientIDScheme string
+		wantScheme, wantID       string
+	}{
+		{"verifier.example", "", SchemePreRegistered, "verifier.example"},
+		{"x509_san_dns:verifier.example", "", certchain.SchemeX509SanDNS, "verifier.example"},
+		{
+			"redirect_uri:https://verifier.example/cb", "",
+			SchemeRedirectURI, "https://verifier.example/cb",
+		},
+		{
+			"decentralized_identifier:did:web:rp.example", "",
+			SchemeDecentralizedIdentifier, "did:web:rp.example",
+		},
+		{"did:web:rp.example", SchemeDID, SchemeDID, "did:web:rp.example"},
+	}
+	for _, tt := range tests {
+		scheme, id := SplitClientID(tt.clientID, tt.clientIDScheme)
+		require.Equal(t, tt.wantScheme, scheme, tt.clientID)
+		require.Equal(t, tt.wantID, id, tt.clientID)
+	}
+}
+
+func TestValidateX509Client(t *testing.T) {
+	key := newTestKey(t)
+	leaf := newAccessCertificate(t, key)
+	x5c := []string{base64.StdEncoding.EncodeToString(leaf.Raw)}
+
+	parameters := testParameters(t)
+	parameters["client_id"] = "x509_san_dns:verifier.example"
+	report := Validate(requestObject(t, signRequest(t, key, parameters, map[string]any{
+		"x5c": x5c,
+	})))
+	require.True(t, report.Valid, issueMessages(report))
+	require.True(t, report.Signed)
+	require.Equal(t, certchain.SchemeX509SanDNS, report.ClientIDScheme)
+
+	parameters["client_id"] = "x509_hash:" + certchain.LeafHash(leaf)
+	report = Validate(requestObject(t, signRequest(t, key, parameters, map[string]any{
+		"x5c": x5c,
+	})))
+	require.True(t, report.Valid, issueMessages(report))
+
+	t.Run("issues", func(t *testing.T) {
+		parameters := testParameters(t)
+		parameters["client_id"] = "x509_san_dns:other.example"
+		other := newTestKey(t)
+		report := Validate(requestObject(t, signRequest(t, other, parameters, map[string]any{
+			"x5c": x5c,
+			"typ": "JWT",
+		})))
+		messages := issueMessages(report)
+		require.Len(t, report.Issues, 3, messages)
+		require.Contains(t, messages, `request.typ: request object typ "JWT"`)
+		require.Contains(t, messages, `client_id: client_id "other.example" is not a dNSName`)
+		require.Contains(t, messages, "request: request object signature:")
+
+		report = Validate(&Request{Parameters: parameters})
+		require.Equal(
+			t,
+			"request: requests of the x509_san_dns scheme must be signed request objects",
+			issueMessages(report),
+		)
+	})
+}
+
+func TestValidateDIDClient(t *testing.T) {
+	key := newTestKey(t)
+	did := didJWK(t, key)
+	parameters := testParameters(t)
+	parameters["client_id"] = "decentralized_identifier:" + did
+
+	report := Validate(requestObject(t, signRequest(t, key, parameters, map[string]any{
+		"kid": did + "#0",
+	})))
+	require.True(t, report.Valid, issueMessages(report))
+	require.Equal(t, did, report.ClientID)
+
+	report = Validate(requestObject(t, signRequest(t, key, parameters, map[string]any{
+		"kid": "did:web:other.example#key-1",
+	})))
+	require.Contains(t, issueMessages(report), "is not a verification method of "+did)
+
+	report = Validate(requestObject(t, signRequest(t, newTestKey(t), parameters, map[string]any{
+		"kid": did + "#0",
+	})))
+	require.Contains(t, issueMessages(report), "request object signature:")
+}
+
+func TestValidateRedirectURIClient(t *testing.T) {
+	parameters := testParameters(t)
+	parameters["client_id"] = "redirect_uri:https://verifier.example/response"
+	report := Validate(&Request{Parameters: parameters})
+	require.True(t, report.Valid, issueMessages(report))
+
+	parameters["client_id"] = "redirect_uri:https://verifier.example/cb"
+	report = Validate(requestObject(t, signRequest(t, newTestKey(t), parameters, nil)))
+	messages := issueMessages(report)
+	require.Len(t, report.Issues, 2, messages)
+	require.Contains(t, messages, "request: requests of the redirect_uri scheme must not be signed")
+	require.Contains(t, messages, `is not the response_uri "https://verifier.example/response"`)
+}
+
+func TestValidateAttestedClient(t *testing.T) {
+	attestation := func(sub string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": sub})
+		signed, err := token.SignedString(newTestKey(t))
+		require.NoError(t, err)
+		return signed
+	}
+	parameters := testParameters(t)
+	parameters["client_id"] = "verifier_attestation:verifier.example"
+	report := Validate(requestObject(t, signRequest(t, newTestKey(t), parameters, map[string]any{
+		"jwt": attestation("verifier.example"),
+	})))
+	require.True(t, report.Valid, issueMessages(report))
+
+	report = Validate(requestObject(t, signRequest(t, newTestKey(t), parameters, map[string]any{
+		"jwt": attestation("other.example"),
+	})))
+	require.Contains(
+		t,
+		issueMessages(report),
+		`verifier attestation sub "other.example" is not the client_id "verifier.example"`,
+	)
+}
+
+func TestValidateClientIDMismatch(t *testing.T) {
+	request := &Request{URLClientID: "other.example", Parameters: testParameters(t)}
+	report := Validate(request)
+	require.Equal(
+		t,
+		`client_id: client_id "verifier.example" of the request object differs from `+
+			`"other.example" of the authorization request`,
+		issueMessages(report),
+	)
+
+	parameters := testParameters(t)
+	parameters["client_id_scheme"] = "web-origin"
+	report = Validate(&Request{Parameters: parameters})
+	require.Contains(t, issueMessages(report), `unsupported client_id_scheme "web-origin"`)
+}
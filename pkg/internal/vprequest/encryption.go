@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+)
+
+// keyManagementAlgorithms are the JWE alg values suited to encrypted
+// authorization responses.
+var keyManagementAlgorithms = map[string]bool{
+	"ECDH-ES":        true,
+	"ECDH-ES+A128KW": true,
+	"ECDH-ES+A192KW": true,
+	"ECDH-ES+A256KW": true,
+	"RSA-OAEP":       true,
+	"RSA-OAEP-256":   true,
+}
+
+var contentEncryptionAlgorithms = map[string]bool{
+	"A128GCM":       true,
+	"A192GCM":       true,
+	"A256GCM":       true,
+	"A128CBC-HS256": true,
+	"A192CBC-HS384": true,
+	"A256CBC-HS512": true,
+}
+
+// validateEncryption checks the client metadata the wallet needs to
+// encrypt the response. Encryption parameters are checked whenever
+// present, and required by the .jwt response modes.
+func (v *validator) validateEncryption() {
+	metadata := v.clientMetadata()
+	path := []string{"client_metadata"}
+
+	algorithm, hasAlgorithm := metadata["authorization_encrypted_response_alg"]
+	if hasAlgorithm {
+		value, _ := algorithm.(string)
+		if !keyManagementAlgorithms[value] {
+			v.addf(
+				child(path, "authorization_encrypted_response_alg"),
+				"unsupported key management algorithm %q",
+				value,
+			)
+		}
+	}
+	if encryption, ok := metadata["authorization_encrypted_response_enc"]; ok {
+		value, _ := encryption.(string)
+		if !contentEncryptionAlgorithms[value] {
+			v.addf(
+				child(path, "authorization_encrypted_response_enc"),
+				"unsupported content encryption algorithm %q",
+				value,
+			)
+		}
+	}
+	if raw, ok := metadata["encrypted_response_enc_values_supported"]; ok {
+		values, ok := stringArray(raw)
+		if !ok {
+			v.addf(
+				child(path, "encrypted_response_enc_values_supported"),
+				"encrypted_response_enc_values_supported is not a non-empty array of strings",
+			)
+		}
+		for i, value := range values {
+			if !contentEncryptionAlgorithms[value] {
+				v.addf(
+					child(path, "encrypted_response_enc_values_supported", i),
+					"unsupported content encryption algorithm %q",
+					value,
+				)
+			}
+		}
+	}
+
+	usableKeys, keyAlgorithms := 0, 0
+	if raw, ok := metadata["jwks"]; ok {
+		usableKeys, keyAlgorithms = v.validateEncryptionKeys(child(path, "jwks"), raw)
+	}
+	if !v.report.Encrypted {
+		return
+	}
+	_, hasJWKS := metadata["jwks"]
+	_, hasJWKSURI := metadata["jwks_uri"]
+	if !hasJWKS && !hasJWKSURI {
+		v.addf(
+			child(path, "jwks"),
+			"jwks is required with response_mode %s",
+			v.report.ResponseMode,
+		)
+		return
+	}
+	if !hasAlgorithm && usableKeys > 0 && keyAlgorithms == 0 {
+		v.addf(
+			child(path, "jwks"),
+			"no encryption key has an alg and authorization_encrypted_response_alg is absent",
+		)
+	}
+}
+
+// validateEncryptionKeys checks the public keys of jwks and returns how
+// many can encrypt responses and how many of those declare their key
+// management algorithm.
+func (v *validator) validateEncryptionKeys(path []string, raw any) (int, int) {
+	jwks, ok := raw.(map[string]any)
+	if !ok {
+		v.addf(path, "jwks is not a JWK set")
+		return 0, 0
+	}
+	keys, ok := jwks["keys"].([]any)
+	if !ok || len(keys) == 0 {
+		v.addf(child(path, "keys"), "keys is not a non-empty array")
+		return 0, 0
+	}
+	usable, withAlgorithm := 0, 0
+	for i, raw := range keys {
+		keyPath := child(path, "keys", i)
+		key, ok := raw.(map[string]any)
+		if !ok {
+			v.addf(keyPath, "key is not a JWK")
+			continue
+		}
+		if use, ok := key["use"]; ok && use != "enc" {
+			continue
+		}
+		if _, err := didresolver.ParseJWK(key); err != nil {
+			v.addf(keyPath, "%s", err.Error())
+			continue
+		}
+		usable++
+		if algorithm, ok := key["alg"]; ok {
+			value, _ := algorithm.(string)
+			if !keyManagementAlgorithms[value] {
+				v.addf(child(keyPath, "alg"), "unsupported key management algorithm %q", value)
+				continue
+			}
+			withAlgorithm++
+		}
+	}
+	if usable == 0 {
+		v.addf(child(path, "keys"), "jwks has no encryption key")
+	}
+	return usable, withAlgorithm
+}
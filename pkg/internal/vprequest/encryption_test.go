@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encryptionKey returns the public JWK of a fresh P-256 key.
+func encryptionKey(t testing.TB) map[string]any {
+	t.Helper()
+	key := newTestKey(t)
+	return map[string]any{
+		"kty": "EC",
+		"crv": "P-256",
+		"use": "enc",
+		"alg": "ECDH-ES",
+		"kid": "enc-1",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func encryptedParameters(t testing.TB, keys ...any) map[string]any {
+	t.Helper()
+	parameters := testParameters(t)
+	parameters["response_mode"] = ResponseModeDirectPostJWT
+	metadata := parameters["client_metadata"].(map[string]any)
+	metadata["jwks"] = map[string]any{"keys": keys}
+	metadata["encrypted_response_enc_values_supported"] = []any{"A128GCM", "A256GCM"}
+	return parameters
+}
+
+func TestValidateEncryption(t *testing.T) {
+	report := Validate(&Request{Parameters: encryptedParameters(t, encryptionKey(t))})
+	require.True(t, report.Valid, issueMessages(report))
+	require.True(t, report.Encrypted)
+
+	t.Run("draft 23 parameters", func(t *testing.T) {
+		key := encryptionKey(t)
+		delete(key, "alg")
+		parameters := encryptedParameters(t, key)
+		metadata := parameters["client_metadata"].(map[string]any)
+		delete(metadata, "encrypted_response_enc_values_supported")
+		metadata["authorization_encrypted_response_alg"] = "ECDH-ES"
+		metadata["authorization_encrypted_response_enc"] = "A256GCM"
+		report := Validate(&Request{Parameters: parameters})
+		require.True(t, report.Valid, issueMessages(report))
+	})
+
+	tests := []struct {
+		name       string
+		parameters func() map[string]any
+		want       []string
+	}{
+		{
+			name: "missing jwks",
+			parameters: func() map[string]any {
+				parameters := encryptedParameters(t)
+				delete(parameters["client_metadata"].(map[string]any), "jwks")
+				return parameters
+			},
+			want: []string{
+				"client_metadata.jwks: jwks is required with response_mode direct_post.jwt",
+			},
+		},
+		{
+			name: "no encryption key",
+			parameters: func() map[string]any {
+				key := encryptionKey(t)
+				key["use"] = "sig"
+				return encryptedParameters(t, key)
+			},
+			want: []string{"client_metadata.jwks.keys: jwks has no encryption key"},
+		},
+		{
+			name: "no key management algorithm",
+			parameters: func() map[string]any {
+				key := encryptionKey(t)
+				delete(key, "alg")
+				return encryptedParameters(t, key)
+			},
+			want: []string{"client_metadata.jwks: no encryption key has an alg"},
+		},
+		{
+			name: "invalid keys and algorithms",
+			parameters: func() map[string]any {
+				private := encryptionKey(t)
+				private["d"] = "secret"
+				weak := encryptionKey(t)
+				weak["alg"] = "RSA1_5"
+				parameters := encryptedParameters(t, private, weak, encryptionKey(t))
+				metadata := parameters["client_metadata"].(map[string]any)
+				metadata["encrypted_response_enc_values_supported"] = []any{"A128CBC"}
+				return parameters
+			},
+			want: []string{
+				`client_metadata.jwks.keys.0: jwk contains the private member "d"`,
+				`client_metadata.jwks.keys.1.alg: unsupported key management algorithm "RSA1_5"`,
+				"client_metadata.encrypted_response_enc_values_supported.0: " +
+					`unsupported content encryption algorithm "A128CBC"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Validate(&Request{Parameters: tt.parameters()})
+			require.False(t, report.Valid)
+			require.Len(t, report.Issues, len(tt.want), issueMessages(report))
+			for _, want := range tt.want {
+				require.Contains(t, issueMessages(report), want)
+			}
+		})
+	}
+}
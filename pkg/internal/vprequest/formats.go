@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"sort"
+	"strings"
+)
+
+// Credential format identifiers of OpenID4VP.
+const (
+	FormatSDJWT       = "dc+sd-jwt"
+	FormatSDJWTLegacy = "vc+sd-jwt"
+	FormatMDoc        = "mso_mdoc"
+	FormatJWTVCJSON   = "jwt_vc_json"
+	FormatJWTVCJSONLD = "jwt_vc_json-ld"
+	FormatLDPVC       = "ldp_vc"
+	FormatJWTVC       = "jwt_vc"
+	FormatJWTVP       = "jwt_vp"
+	FormatJWTVPJSON   = "jwt_vp_json"
+	FormatLDPVP       = "ldp_vp"
+)
+
+var formats = map[string]bool{
+	FormatSDJWT:       true,
+	FormatSDJWTLegacy: true,
+	FormatMDoc:        true,
+	FormatJWTVCJSON:   true,
+	FormatJWTVCJSONLD: true,
+	FormatLDPVC:       true,
+	FormatJWTVC:       true,
+	FormatJWTVP:       true,
+	FormatJWTVPJSON:   true,
+	FormatLDPVP:       true,
+}
+
+// IsSDJWT reports whether format is an SD-JWT VC format.
+func IsSDJWT(format string) bool {
+	return format == FormatSDJWT || format == FormatSDJWTLegacy
+}
+
+// validateClientMetadata checks the formats the verifier supports, in
+// vp_formats_supported or, in draft 23, vp_formats.
+func (v *validator) validateClientMetadata() {
+	raw, ok := v.request.Parameters["client_metadata"]
+	if !ok {
+		if v.report.ClientIDScheme != SchemePreRegistered {
+			v.addf(
+				[]string{"client_metadata"},
+				"client_metadata is required for the %s scheme",
+				v.report.ClientIDScheme,
+			)
+		}
+		return
+	}
+	if _, ok := raw.(map[string]any); !ok {
+		v.addf([]string{"client_metadata"}, "client_metadata is not an object")
+		return
+	}
+
+	name := "vp_formats_supported"
+	if _, ok := v.clientMetadata()[name]; !ok && v.report.Version == VersionDraft23 {
+		name = "vp_formats"
+	}
+	path := []string{"client_metadata", name}
+	supported, ok := v.clientMetadata()[name].(map[string]any)
+	if !ok || len(supported) == 0 {
+		v.addf(path, "client metadata declares no supported credential formats")
+		return
+	}
+	algorithms := map[string]bool{}
+	for _, format := range sortedKeys(supported) {
+		formatPath := child(path, format)
+		if !formats[format] {
+			v.addf(formatPath, "unknown credential format %q", format)
+			continue
+		}
+		v.report.Formats = append(v.report.Formats, format)
+		parameters, ok := supported[format].(map[string]any)
+		if !ok {
+			v.addf(formatPath, "format parameters are not an object")
+			continue
+		}
+		for _, parameter := range sortedKeys(parameters) {
+			if !strings.Contains(parameter, "alg") && parameter != "proof_type" {
+				continue
+			}
+			values, ok := stringArray(parameters[parameter])
+			if !ok {
+				v.addf(
+					child(formatPath, parameter),
+					"%s is not a non-empty array of strings",
+					parameter,
+				)
+				continue
+			}
+			for _, value := range values {
+				algorithms[value] = true
+			}
+		}
+	}
+	for algorithm := range algorithms {
+		v.report.Algorithms = append(v.report.Algorithms, algorithm)
+	}
+	sort.Strings(v.report.Algorithms)
+}
+
+// supportsFormat reports whether the client metadata declares format. When
+// no format is declared, which is already an issue, any format passes.
+func (v *validator) supportsFormat(format string) bool {
+	if len(v.report.Formats) == 0 {
+		return true
+	}
+	for _, supported := range v.report.Formats {
+		if supported == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,365 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"regexp"
+	"strings"
+)
+
+var dcqlIdentifier = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateQuery checks that the request asks for credentials with exactly
+// one of a DCQL query, a presentation definition or a scope.
+func (v *validator) validateQuery() {
+	var queries []string
+	for _, name := range []string{
+		"dcql_query",
+		"presentation_definition",
+		"presentation_definition_uri",
+		"scope",
+	} {
+		if _, ok := v.request.Parameters[name]; ok {
+			queries = append(queries, name)
+		}
+	}
+	switch len(queries) {
+	case 0:
+		v.addf(
+			[]string{"dcql_query"},
+			"one of dcql_query, presentation_definition and scope is required",
+		)
+		return
+	case 1:
+	default:
+		v.addf(
+			[]string{queries[1]},
+			"only one of %s is allowed",
+			strings.Join(queries, ", "),
+		)
+		return
+	}
+
+	switch queries[0] {
+	case "dcql_query":
+		v.report.Query = QueryDCQL
+		v.validateDCQL(v.request.Parameters["dcql_query"])
+	case "presentation_definition":
+		v.report.Query = QueryPresentationDefinition
+		v.validatePresentationDefinition(v.request.Parameters["presentation_definition"])
+	case "presentation_definition_uri":
+		v.report.Query = QueryPresentationDefinition
+		v.addf(
+			[]string{"presentation_definition_uri"},
+			"presentation_definition_uri is not supported, pass the definition by value",
+		)
+	default:
+		v.report.Query = QueryScope
+	}
+}
+
+func (v *validator) validateDCQL(raw any) {
+	path := []string{"dcql_query"}
+	query, ok := raw.(map[string]any)
+	if !ok {
+		v.addf(path, "dcql_query is not an object")
+		return
+	}
+	credentials, ok := query["credentials"].([]any)
+	if !ok || len(credentials) == 0 {
+		v.addf(child(path, "credentials"), "credentials is not a non-empty array")
+		return
+	}
+	ids := map[string]bool{}
+	for i, raw := range credentials {
+		credentialPath := child(path, "credentials", i)
+		credential, ok := raw.(map[string]any)
+		if !ok {
+			v.addf(credentialPath, "credential query is not an object")
+			continue
+		}
+		id, _ := credential["id"].(string)
+		switch {
+		case !dcqlIdentifier.MatchString(id):
+			v.addf(
+				child(credentialPath, "id"),
+				"id %q is not a non-empty string of alphanumerics, _ and -",
+				id,
+			)
+		case ids[id]:
+			v.addf(child(credentialPath, "id"), "duplicate credential query id %q", id)
+		default:
+			ids[id] = true
+			v.report.Credentials = append(v.report.Credentials, id)
+		}
+		v.validateDCQLCredential(credentialPath, credential)
+	}
+	if sets, ok := query["credential_sets"]; ok {
+		v.validateCredentialSets(child(path, "credential_sets"), sets, ids)
+	}
+}
+
+func (v *validator) validateDCQLCredential(path []string, credential map[string]any) {
+	format, _ := credential["format"].(string)
+	if format == "" {
+		v.addf(child(path, "format"), "format is required")
+	} else if !v.supportsFormat(format) {
+		v.addf(
+			child(path, "format"),
+			"format %q is not among the formats of the client metadata",
+			format,
+		)
+	}
+
+	meta, ok := credential["meta"].(map[string]any)
+	switch {
+	case !ok && credential["meta"] != nil:
+		v.addf(child(path, "meta"), "meta is not an object")
+	case !ok && v.report.Version == Version10:
+		v.addf(child(path, "meta"), "meta is required")
+	case ok && IsSDJWT(format):
+		if _, ok := stringArray(meta["vct_values"]); !ok {
+			v.addf(
+				child(path, "meta", "vct_values"),
+				"vct_values is not a non-empty array of strings",
+			)
+		}
+	case ok && format == FormatMDoc:
+		if doctype, _ := meta["doctype_value"].(string); doctype == "" {
+			v.addf(child(path, "meta", "doctype_value"), "doctype_value is required")
+		}
+	}
+
+	claimIDs := map[string]bool{}
+	if raw, ok := credential["claims"]; ok {
+		claims, ok := raw.([]any)
+		if !ok || len(claims) == 0 {
+			v.addf(child(path, "claims"), "claims is not a non-empty array")
+		}
+		for i, raw := range claims {
+			v.validateDCQLClaim(child(path, "claims", i), raw, format, claimIDs)
+		}
+	}
+	if raw, ok := credential["claim_sets"]; ok {
+		setsPath := child(path, "claim_sets")
+		sets, ok := raw.([]any)
+		if !ok || len(sets) == 0 {
+			v.addf(setsPath, "claim_sets is not a non-empty array")
+		}
+		if _, ok := credential["claims"]; !ok {
+			v.addf(setsPath, "claim_sets requires claims")
+		}
+		for i, raw := range sets {
+			v.validateReferences(child(setsPath, i), raw, claimIDs, "claim")
+		}
+	}
+}
+
+func (v *validator) validateDCQLClaim(
+	path []string,
+	raw any,
+	format string,
+	ids map[string]bool,
+) {
+	claim, ok := raw.(map[string]any)
+	if !ok {
+		v.addf(path, "claim query is not an object")
+		return
+	}
+	if id, ok := claim["id"]; ok {
+		text, _ := id.(string)
+		switch {
+		case !dcqlIdentifier.MatchString(text):
+			v.addf(
+				child(path, "id"),
+				"id %q is not a non-empty string of alphanumerics, _ and -",
+				text,
+			)
+		case ids[text]:
+			v.addf(child(path, "id"), "duplicate claim query id %q", text)
+		default:
+			ids[text] = true
+		}
+	}
+	elements, ok := claim["path"].([]any)
+	if !ok || len(elements) == 0 {
+		v.addf(child(path, "path"), "path is not a non-empty array")
+		return
+	}
+	for i, element := range elements {
+		switch element := element.(type) {
+		case string:
+		case nil:
+			if format == FormatMDoc {
+				v.addf(child(path, "path", i), "mso_mdoc claim paths cannot select all elements")
+			}
+		case float64:
+			if element < 0 || element != float64(int(element)) {
+				v.addf(
+					child(path, "path", i),
+					"path index %v is not a non-negative integer",
+					element,
+				)
+			}
+		default:
+			v.addf(child(path, "path", i), "path element is not a string, an index or null")
+		}
+	}
+	if format == FormatMDoc && len(elements) != 2 {
+		v.addf(child(path, "path"), "mso_mdoc claim paths are a namespace and an element")
+	}
+	if values, ok := claim["values"]; ok {
+		if list, ok := values.([]any); !ok || len(list) == 0 {
+			v.addf(child(path, "values"), "values is not a non-empty array")
+		}
+	}
+}
+
+func (v *validator) validateCredentialSets(path []string, raw any, ids map[string]bool) {
+	sets, ok := raw.([]any)
+	if !ok || len(sets) == 0 {
+		v.addf(path, "credential_sets is not a non-empty array")
+		return
+	}
+	for i, raw := range sets {
+		setPath := child(path, i)
+		set, ok := raw.(map[string]any)
+		if !ok {
+			v.addf(setPath, "credential set query is not an object")
+			continue
+		}
+		options, ok := set["options"].([]any)
+		if !ok || len(options) == 0 {
+			v.addf(child(setPath, "options"), "options is not a non-empty array")
+			continue
+		}
+		for j, option := range options {
+			v.validateReferences(child(setPath, "options", j), option, ids, "credential query")
+		}
+		if required, ok := set["required"]; ok {
+			if _, ok := required.(bool); !ok {
+				v.addf(child(setPath, "required"), "required is not a boolean")
+			}
+		}
+	}
+}
+
+// validateReferences checks an option of claim_sets or credential_sets: a
+// non-empty array of known identifiers.
+func (v *validator) validateReferences(path []string, raw any, ids map[string]bool, kind string) {
+	references, ok := stringArray(raw)
+	if !ok {
+		v.addf(path, "option is not a non-empty array of %s ids", kind)
+		return
+	}
+	for _, reference := range references {
+		if !ids[reference] {
+			v.addf(path, "unknown %s id %q", kind, reference)
+		}
+	}
+}
+
+func (v *validator) validatePresentationDefinition(raw any) {
+	path := []string{"presentation_definition"}
+	definition, ok := raw.(map[string]any)
+	if !ok {
+		v.addf(path, "presentation_definition is not an object")
+		return
+	}
+	if id, _ := definition["id"].(string); id == "" {
+		v.addf(child(path, "id"), "id is required")
+	}
+	if format, ok := definition["format"]; ok {
+		v.validateDefinitionFormat(child(path, "format"), format)
+	}
+	descriptors, ok := definition["input_descriptors"].([]any)
+	if !ok || len(descriptors) == 0 {
+		v.addf(child(path, "input_descriptors"), "input_descriptors is not a non-empty array")
+		return
+	}
+	ids := map[string]bool{}
+	for i, raw := range descriptors {
+		descriptorPath := child(path, "input_descriptors", i)
+		descriptor, ok := raw.(map[string]any)
+		if !ok {
+			v.addf(descriptorPath, "input descriptor is not an object")
+			continue
+		}
+		id, _ := descriptor["id"].(string)
+		switch {
+		case id == "":
+			v.addf(child(descriptorPath, "id"), "id is required")
+		case ids[id]:
+			v.addf(child(descriptorPath, "id"), "duplicate input descriptor id %q", id)
+		default:
+			ids[id] = true
+			v.report.Credentials = append(v.report.Credentials, id)
+		}
+		if format, ok := descriptor["format"]; ok {
+			v.validateDefinitionFormat(child(descriptorPath, "format"), format)
+		}
+		constraints, ok := descriptor["constraints"].(map[string]any)
+		if !ok {
+			v.addf(child(descriptorPath, "constraints"), "constraints is required")
+			continue
+		}
+		v.validateConstraints(child(descriptorPath, "constraints"), constraints)
+	}
+}
+
+func (v *validator) validateDefinitionFormat(path []string, raw any) {
+	format, ok := raw.(map[string]any)
+	if !ok || len(format) == 0 {
+		v.addf(path, "format is not a non-empty object")
+		return
+	}
+	for _, name := range sortedKeys(format) {
+		switch {
+		case !formats[name]:
+			v.addf(child(path, name), "unknown credential format %q", name)
+		case !v.supportsFormat(name):
+			v.addf(
+				child(path, name),
+				"format %q is not among the formats of the client metadata",
+				name,
+			)
+		}
+	}
+}
+
+func (v *validator) validateConstraints(path []string, constraints map[string]any) {
+	raw, ok := constraints["fields"]
+	if !ok {
+		return
+	}
+	fields, ok := raw.([]any)
+	if !ok {
+		v.addf(child(path, "fields"), "fields is not an array")
+		return
+	}
+	for i, raw := range fields {
+		fieldPath := child(path, "fields", i)
+		field, ok := raw.(map[string]any)
+		if !ok {
+			v.addf(fieldPath, "field is not an object")
+			continue
+		}
+		paths, ok := stringArray(field["path"])
+		if !ok {
+			v.addf(child(fieldPath, "path"), "path is not a non-empty array of JSONPaths")
+			continue
+		}
+		for j, jsonPath := range paths {
+			if !strings.HasPrefix(jsonPath, "$") {
+				v.addf(child(fieldPath, "path", j), "%q is not a JSONPath", jsonPath)
+			}
+		}
+		if filter, ok := field["filter"]; ok {
+			if _, ok := filter.(map[string]any); !ok {
+				v.addf(child(fieldPath, "filter"), "filter is not a JSON schema object")
+			}
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package vprequest fetches the OpenID4VP authorization requests of
+// verifiers, from an authorization request URL or from the endpoint that
+// serves them, and validates their client identifier, client metadata,
+// DCQL query or presentation definition and response encryption.
+package vprequest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// RequestObjectType is the media type of request objects.
+	RequestObjectType = "application/oauth-authz-req+jwt"
+	// AuthorizationRequestScheme is the URL scheme wallets register for
+	// OpenID4VP authorization requests.
+	AuthorizationRequestScheme = "openid4vp"
+
+	maxResponseSize = 1 << 20
+	maxRedirects    = 10
+)
+
+// Source tells how the authorization request was obtained.
+type Source string
+
+const (
+	// SourceURL is an authorization request URL given as is.
+	SourceURL Source = "url"
+	// SourceRedirect is an authorization request URL the verifier
+	// redirected to.
+	SourceRedirect Source = "redirect"
+	// SourceBody is an authorization request URL returned in the body of
+	// the verifier endpoint, usually a new one on every call.
+	SourceBody Source = "body"
+	// SourceJSON is an authorization request whose parameters the verifier
+	// endpoint returned as a JSON object.
+	SourceJSON Source = "json"
+	// SourceRequestObject is a request object served at the verifier
+	// endpoint, used as request_uri.
+	SourceRequestObject Source = "request_object"
+)
+
+// jsonParameters are the authorization request parameters whose values are
+// JSON when passed in a URL.
+var jsonParameters = []string{
+	"client_metadata",
+	"dcql_query",
+	"presentation_definition",
+	"transaction_data",
+	"verifier_info",
+	"verifier_attestations",
+}
+
+// Request is an authorization request of a verifier.
+type Request struct {
+	Source Source `json:"source"`
+	// Endpoint is the verifier URL the request was fetched from, if any.
+	Endpoint string `json:"endpoint,omitempty"`
+	// URL is the authorization request URL, such as an openid4vp:// link,
+	// that wallets are given.
+	URL string `json:"url"`
+	// URLClientID is the client_id of URL, which must be the one of the
+	// request object.
+	URLClientID      string `json:"url_client_id,omitempty"`
+	RequestURI       string `json:"request_uri,omitempty"`
+	RequestURIMethod string `json:"request_uri_method,omitempty"`
+	// RequestObject is the compact JWT passed by value or by reference.
+	RequestObject string         `json:"request_object,omitempty"`
+	Header        map[string]any `json:"header,omitempty"`
+	// Parameters are the authorization request parameters, those of the
+	// request object when there is one.
+	Parameters map[string]any `json:"parameters"`
+}
+
+// Parameter returns the string parameter name, or "".
+func (r *Request) Parameter(name string) string {
+	value, _ := r.Parameters[name].(string)
+	return value
+}
+
+// Signed reports whether the request object is signed.
+func (r *Request) Signed() bool {
+	if r.RequestObject == "" {
+		return false
+	}
+	alg, _ := r.Header["alg"].(string)
+	return alg != "" && alg != "none"
+}
+
+// IsAuthorizationRequest reports whether target carries authorization
+// request parameters in its query.
+func IsAuthorizationRequest(target *url.URL) bool {
+	query := target.Query()
+	return query.Has("client_id") || query.Has("request_uri") || query.Has("request")
+}
+
+// ParseURL parses an authorization request URL. A request object passed by
+// value is decoded, one passed by reference is left to Fetcher.
+func ParseURL(rawURL string) (*Request, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization request URL: %w", err)
+	}
+	if !IsAuthorizationRequest(target) {
+		return nil, errors.New("URL has no client_id, request_uri or request parameter")
+	}
+	parameters := map[string]any{}
+	for name, values := range target.Query() {
+		parameters[name] = values[0]
+	}
+	for _, name := range jsonParameters {
+		raw, ok := parameters[name].(string)
+		if !ok {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(raw), &decoded); err == nil {
+			parameters[name] = decoded
+		}
+	}
+	request, err := fromParameters(parameters)
+	if err != nil {
+		return nil, err
+	}
+	request.Source = SourceURL
+	request.URL = target.String()
+	return request, nil
+}
+
+func fromParameters(parameters map[string]any) (*Request, error) {
+	request := &Request{Parameters: parameters}
+	request.URLClientID = request.Parameter("client_id")
+	request.RequestURI = request.Parameter("request_uri")
+	request.RequestURIMethod = request.Parameter("request_uri_method")
+	if object, ok := parameters["request"]; ok {
+		jws, _ := object.(string)
+		if err := request.setRequestObject(jws); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+// setRequestObject decodes the header and claims of a request object
+// without verifying it, which is left to Validate.
+func (r *Request) setRequestObject(jws string) error {
+	jws = strings.TrimSpace(jws)
+	claims := jwt.MapClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(jws, claims)
+	if err != nil {
+		return fmt.Errorf("malformed request object: %w", err)
+	}
+	r.RequestObject = jws
+	r.Header = token.Header
+	r.Parameters = map[string]any(claims)
+	return nil
+}
+
+// Fetcher fetches authorization requests. The zero value uses
+// http.DefaultClient.
+type Fetcher struct {
+	HTTPClient *http.Client
+}
+
+// Fetch returns the authorization request at rawURL, which is either an
+// authorization request URL or a verifier endpoint returning one, as a
+// redirect, a URL, JSON parameters or a request object. Request objects
+// passed by reference are fetched.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Request, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	var request *Request
+	if IsAuthorizationRequest(target) {
+		request, err = ParseURL(target.String())
+	} else {
+		request, err = f.fetchEndpoint(ctx, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestURI != "" && request.RequestObject == "" {
+		if err := f.fetchRequestObject(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+func (f *Fetcher) fetchEndpoint(ctx context.Context, target *url.URL) (*Request, error) {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf(
+			"%q is neither an authorization request nor an http(s) URL",
+			target.String(),
+		)
+	}
+	response, body, err := f.do(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var request *Request
+	switch {
+	case isRedirect(response.StatusCode):
+		location, err := response.Location()
+		if err != nil {
+			return nil, fmt.Errorf("redirect without location: %w", err)
+		}
+		if request, err = ParseURL(location.String()); err != nil {
+			return nil, fmt.Errorf("redirect to %s: %w", location, err)
+		}
+		request.Source = SourceRedirect
+	case response.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: unexpected status %d", target, response.StatusCode)
+	case isRequestObject(response.Header.Get("Content-Type"), body):
+		request = &Request{Source: SourceRequestObject, RequestURI: target.String()}
+		if err := request.setRequestObject(body); err != nil {
+			return nil, err
+		}
+		query := url.Values{}
+		query.Set("client_id", request.Parameter("client_id"))
+		query.Set("request_uri", target.String())
+		request.URL = AuthorizationRequestScheme + "://?" + query.Encode()
+	case strings.HasPrefix(body, "{"):
+		parameters := map[string]any{}
+		if err := json.Unmarshal([]byte(body), &parameters); err != nil {
+			return nil, fmt.Errorf("%s: invalid JSON: %w", target, err)
+		}
+		if request, err = fromParameters(parameters); err != nil {
+			return nil, err
+		}
+		request.Source = SourceJSON
+		request.URL = AuthorizationRequestScheme + "://?" + encodeParameters(parameters)
+	default:
+		var quoted string
+		if json.Unmarshal([]byte(body), &quoted) == nil {
+			body = quoted
+		}
+		if request, err = ParseURL(body); err != nil {
+			return nil, fmt.Errorf(
+				"%s returned neither an authorization request nor a request object",
+				target,
+			)
+		}
+		request.Source = SourceBody
+	}
+	request.Endpoint = target.String()
+	return request, nil
+}
+
+func (f *Fetcher) fetchRequestObject(ctx context.Context, request *Request) error {
+	method := http.MethodGet
+	var form io.Reader
+	if strings.EqualFold(request.RequestURIMethod, "post") {
+		method = http.MethodPost
+		form = strings.NewReader("")
+	}
+	response, body, err := f.do(ctx, method, request.RequestURI, form)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf(
+			"request_uri %s: unexpected status %d",
+			request.RequestURI,
+			response.StatusCode,
+		)
+	}
+	if err := request.setRequestObject(body); err != nil {
+		return fmt.Errorf("request_uri %s: %w", request.RequestURI, err)
+	}
+	return nil
+}
+
+// do sends the request without following redirects to other schemes, such
+// as openid4vp://, and returns the response with its trimmed body.
+func (f *Fetcher) do(
+	ctx context.Context,
+	method, target string,
+	form io.Reader,
+) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, form)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", RequestObjectType+", application/json, text/plain")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	client := http.DefaultClient
+	if f.HTTPClient != nil {
+		client = f.HTTPClient
+	}
+	noCustomSchemes := *client
+	noCustomSchemes.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if next.URL.Scheme != "http" && next.URL.Scheme != "https" {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+	response, err := noCustomSchemes.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", target, err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, "", fmt.Errorf("%s: response exceeds %d bytes", target, maxResponseSize)
+	}
+	return response, strings.TrimSpace(string(body)), nil
+}
+
+func isRedirect(status int) bool {
+	return status >= http.StatusMultipleChoices && status < http.StatusBadRequest &&
+		status != http.StatusNotModified
+}
+
+// isRequestObject tells request objects from the other responses by media
+// type or, since verifiers often serve them as text, by their shape.
+func isRequestObject(contentType, body string) bool {
+	if strings.HasPrefix(contentType, RequestObjectType) {
+		return true
+	}
+	return strings.Count(body, ".") == 2 && !strings.ContainsAny(body, " {:/\n")
+}
+
+func encodeParameters(parameters map[string]any) string {
+	query := url.Values{}
+	for name, value := range parameters {
+		if text, ok := value.(string); ok {
+			query.Set(name, text)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err == nil {
+			query.Set(name, string(encoded))
+		}
+	}
+	return query.Encode()
+}
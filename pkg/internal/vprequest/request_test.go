@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testVerifier serves a request object and the endpoints that lead to it.
+func testVerifier(t testing.TB) (*httptest.Server, string) {
+	t.Helper()
+	object := requestObject(t, signRequest(t, newTestKey(t), testParameters(t), nil))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizationRequest := func() string {
+			query := url.Values{}
+			query.Set("client_id", "verifier.example")
+			query.Set("request_uri", server.URL+"/request.jwt")
+			query.Set("request_uri_method", "post")
+			return "openid4vp://?" + query.Encode()
+		}
+		switch r.URL.Path {
+		case "/request.jwt":
+			w.Header().Set("Content-Type", RequestObjectType)
+			_, _ = w.Write([]byte(object.RequestObject))
+		case "/redirect":
+			http.Redirect(w, r, authorizationRequest(), http.StatusFound)
+		case "/link":
+			_, _ = w.Write([]byte(authorizationRequest()))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"client_id":   "verifier.example",
+				"request_uri": server.URL + "/request.jwt",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, object.RequestObject
+}
+
+func TestFetch(t *testing.T) {
+	server, object := testVerifier(t)
+	fetcher := &Fetcher{HTTPClient: server.Client()}
+
+	tests := []struct {
+		url        string
+		wantSource Source
+	}{
+		{url: server.URL + "/request.jwt", wantSource: SourceRequestObject},
+		{url: server.URL + "/redirect", wantSource: SourceRedirect},
+		{url: server.URL + "/link", wantSource: SourceBody},
+		{url: server.URL + "/json", wantSource: SourceJSON},
+		{
+			url: "openid4vp://?client_id=verifier.example&request_uri=" +
+				url.QueryEscape(server.URL+"/request.jwt"),
+			wantSource: SourceURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.wantSource), func(t *testing.T) {
+			request, err := fetcher.Fetch(context.Background(), tt.url)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSource, request.Source)
+			require.Equal(t, object, request.RequestObject)
+			require.Equal(t, server.URL+"/request.jwt", request.RequestURI)
+			require.Equal(t, "verifier.example", request.Parameter("client_id"))
+
+			parsed, err := url.Parse(request.URL)
+			require.NoError(t, err)
+			require.Equal(t, AuthorizationRequestScheme, parsed.Scheme)
+			require.Equal(t, server.URL+"/request.jwt", parsed.Query().Get("request_uri"))
+			if tt.wantSource != SourceURL {
+				require.Equal(t, tt.url, request.Endpoint)
+			}
+		})
+	}
+}
+
+func TestParseURLByValue(t *testing.T) {
+	parameters := testParameters(t)
+	query := url.Values{}
+	for name, value := range parameters {
+		if text, ok := value.(string); ok {
+			query.Set(name, text)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		require.NoError(t, err)
+		query.Set(name, string(encoded))
+	}
+	request, err := ParseURL("openid4vp://?" + query.Encode())
+	require.NoError(t, err)
+	require.Equal(t, parameters, request.Parameters)
+	require.False(t, request.Signed())
+	report := Validate(request)
+	require.True(t, report.Valid, issueMessages(report))
+}
+
+func TestFetchErrors(t *testing.T) {
+	server, _ := testVerifier(t)
+	fetcher := &Fetcher{HTTPClient: server.Client()}
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/missing")
+	require.ErrorContains(t, err, "unexpected status 404")
+
+	_, err = fetcher.Fetch(context.Background(), "openid4vp://?client_id=a&request_uri="+
+		url.QueryEscape(server.URL+"/missing"))
+	require.ErrorContains(t, err, "request_uri "+server.URL+"/missing: unexpected status 404")
+
+	_, err = fetcher.Fetch(context.Background(), "ftp://verifier.example")
+	require.ErrorContains(t, err, "neither an authorization request nor an http(s) URL")
+
+	_, err = ParseURL("https://verifier.example/start")
+	require.ErrorContains(t, err, "no client_id, request_uri or request parameter")
+}
@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Versions of OpenID4VP an authorization request follows, named as the
+// openid4vp_verifier standard versions.
+const (
+	Version10      = "1.0"
+	VersionDraft23 = "draft-23"
+)
+
+// Query languages of an authorization request.
+const (
+	QueryDCQL                   = "dcql"
+	QueryPresentationDefinition = "presentation_definition"
+	QueryScope                  = "scope"
+)
+
+// Response modes of OpenID4VP.
+const (
+	ResponseModeFragment      = "fragment"
+	ResponseModeQuery         = "query"
+	ResponseModeDirectPost    = "direct_post"
+	ResponseModeDirectPostJWT = "direct_post.jwt"
+	ResponseModeDCAPI         = "dc_api"
+	ResponseModeDCAPIJWT      = "dc_api.jwt"
+)
+
+var responseModes = map[string]bool{
+	ResponseModeFragment:      true,
+	ResponseModeQuery:         true,
+	ResponseModeDirectPost:    true,
+	ResponseModeDirectPostJWT: true,
+	ResponseModeDCAPI:         true,
+	ResponseModeDCAPIJWT:      true,
+}
+
+// Issue is a problem found in an authorization request. Path locates it in
+// the request parameters.
+type Issue struct {
+	Field   string   `json:"field"`
+	Path    []string `json:"path"`
+	Message string   `json:"message"`
+}
+
+// Report is the outcome of the validation of an authorization request.
+type Report struct {
+	Valid          bool   `json:"valid"`
+	Version        string `json:"version"`
+	ClientID       string `json:"client_id"`
+	ClientIDScheme string `json:"client_id_scheme"`
+	Signed         bool   `json:"signed"`
+	ResponseMode   string `json:"response_mode"`
+	Encrypted      bool   `json:"encrypted"`
+	// Formats are the credential formats the verifier supports.
+	Formats    []string `json:"formats"`
+	Algorithms []string `json:"algorithms"`
+	Query      string   `json:"query,omitempty"`
+	// Credentials are the identifiers of the DCQL credential queries or of
+	// the input descriptors.
+	Credentials []string `json:"credentials"`
+	Issues      []Issue  `json:"issues"`
+}
+
+// Validate checks an authorization request against OpenID4VP, 1.0 or draft
+// 23 depending on the parameters it uses.
+func Validate(request *Request) Report {
+	v := &validator{
+		request: request,
+		report: Report{
+			Version:     detectVersion(request.Parameters),
+			Signed:      request.Signed(),
+			Formats:     []string{},
+			Algorithms:  []string{},
+			Credentials: []string{},
+			Issues:      []Issue{},
+		},
+	}
+	v.validateClientID()
+	v.validateResponse()
+	v.validateClientMetadata()
+	v.validateQuery()
+	v.validateEncryption()
+	v.report.Valid = len(v.report.Issues) == 0
+	return v.report
+}
+
+type validator struct {
+	request *Request
+	report  Report
+}
+
+func (v *validator) addf(path []string, format string, args ...any) {
+	v.report.Issues = append(v.report.Issues, Issue{
+		Field:   strings.Join(path, "."),
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) parameter(name string) string {
+	return v.request.Parameter(name)
+}
+
+// clientMetadata returns the client_metadata object, empty when absent.
+func (v *validator) clientMetadata() map[string]any {
+	metadata, _ := v.request.Parameters["client_metadata"].(map[string]any)
+	if metadata == nil {
+		return map[string]any{}
+	}
+	return metadata
+}
+
+// detectVersion tells draft 23 requests by the parameters 1.0 removed.
+func detectVersion(parameters map[string]any) string {
+	for _, name := range []string{
+		"client_id_scheme",
+		"presentation_definition",
+		"presentation_definition_uri",
+	} {
+		if _, ok := parameters[name]; ok {
+			return VersionDraft23
+		}
+	}
+	metadata, _ := parameters["client_metadata"].(map[string]any)
+	if _, ok := metadata["vp_formats"]; ok {
+		if _, ok := metadata["vp_formats_supported"]; !ok {
+			return VersionDraft23
+		}
+	}
+	return Version10
+}
+
+func (v *validator) validateResponse() {
+	responseType := v.parameter("response_type")
+	if !containsField(responseType, "vp_token") {
+		v.addf(
+			[]string{"response_type"},
+			"response_type %q does not include vp_token",
+			responseType,
+		)
+	}
+	if v.parameter("nonce") == "" {
+		v.addf([]string{"nonce"}, "nonce is required")
+	}
+
+	mode := v.parameter("response_mode")
+	if mode == "" {
+		mode = ResponseModeFragment
+	}
+	v.report.ResponseMode = mode
+	v.report.Encrypted = strings.HasSuffix(mode, ".jwt")
+	if !responseModes[mode] {
+		v.addf([]string{"response_mode"}, "unsupported response_mode %q", mode)
+		return
+	}
+	if mode != ResponseModeDirectPost && mode != ResponseModeDirectPostJWT {
+		return
+	}
+	responseURI := v.parameter("response_uri")
+	if responseURI == "" {
+		v.addf([]string{"response_uri"}, "response_uri is required with response_mode %s", mode)
+	} else if !isHTTPSURL(responseURI) {
+		v.addf([]string{"response_uri"}, "response_uri %q is not an https URL", responseURI)
+	}
+	if _, ok := v.request.Parameters["redirect_uri"]; ok {
+		v.addf(
+			[]string{"redirect_uri"},
+			"redirect_uri must not be present with response_mode %s",
+			mode,
+		)
+	}
+}
+
+// containsField reports whether the space separated list contains value.
+func containsField(list, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPSURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+// stringArray returns value as a non-empty array of strings.
+func stringArray(value any) ([]string, bool) {
+	values, ok := value.([]any)
+	if !ok || len(values) == 0 {
+		return nil, false
+	}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, text)
+	}
+	return out, true
+}
+
+func sortedKeys(values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func child(path []string, elements ...any) []string {
+	out := append([]string{}, path...)
+	for _, element := range elements {
+		switch element := element.(type) {
+		case int:
+			out = append(out, strconv.Itoa(element))
+		default:
+			out = append(out, fmt.Sprint(element))
+		}
+	}
+	return out
+}
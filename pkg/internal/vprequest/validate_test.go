@@ -0,0 +1,254 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package vprequest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testParameters returns a valid OpenID4VP 1.0 authorization request of a
+// pre-registered verifier, as decoded from JSON.
+func testParameters(t testing.TB) map[string]any {
+	t.Helper()
+	parameters := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"client_id": "verifier.example",
+		"response_type": "vp_token",
+		"response_mode": "direct_post",
+		"response_uri": "https://verifier.example/response",
+		"nonce": "n-0S6_WzA2Mj",
+		"client_metadata": {
+			"vp_formats_supported": {
+				"dc+sd-jwt": {
+					"sd-jwt_alg_values": ["ES256"],
+					"kb-jwt_alg_values": ["ES256", "EdDSA"]
+				},
+				"mso_mdoc": {}
+			}
+		},
+		"dcql_query": {
+			"credentials": [
+				{
+					"id": "pid",
+					"format": "dc+sd-jwt",
+					"meta": {"vct_values": ["urn:eudi:pid:1"]},
+					"claims": [
+						{"id": "given_name", "path": ["given_name"]},
+						{"id": "nationality", "path": ["nationalities", null]}
+					],
+					"claim_sets": [["given_name"], ["nationality"]]
+				},
+				{
+					"id": "mdl",
+					"format": "mso_mdoc",
+					"meta": {"doctype_value": "org.iso.18013.5.1.mDL"},
+					"claims": [{"path": ["org.iso.18013.5.1", "family_name"]}]
+				}
+			],
+			"credential_sets": [{"options": [["pid"], ["mdl"]], "required": true}]
+		}
+	}`), &parameters))
+	return parameters
+}
+
+func issueMessages(report Report) string {
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Field+": "+issue.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestValidate(t *testing.T) {
+	report := Validate(&Request{Parameters: testParameters(t)})
+	require.True(t, report.Valid, issueMessages(report))
+	require.Equal(t, Report{
+		Valid:          true,
+		Version:        Version10,
+		ClientID:       "verifier.example",
+		ClientIDScheme: SchemePreRegistered,
+		ResponseMode:   ResponseModeDirectPost,
+		Formats:        []string{FormatSDJWT, FormatMDoc},
+		Algorithms:     []string{"ES256", "EdDSA"},
+		Query:          QueryDCQL,
+		Credentials:    []string{"pid", "mdl"},
+		Issues:         []Issue{},
+	}, report)
+}
+
+func TestValidatePresentationDefinition(t *testing.T) {
+	parameters := testParameters(t)
+	delete(parameters, "dcql_query")
+	metadata := parameters["client_metadata"].(map[string]any)
+	metadata["vp_formats"] = metadata["vp_formats_supported"]
+	delete(metadata, "vp_formats_supported")
+	parameters["client_id_scheme"] = SchemePreRegistered
+	var definition any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "pid-request",
+		"input_descriptors": [{
+			"id": "pid",
+			"format": {"dc+sd-jwt": {"sd-jwt_alg_values": ["ES256"]}},
+			"constraints": {
+				"fields": [
+					{"path": ["$.vct"], "filter": {"type": "string", "const": "urn:eudi:pid:1"}},
+					{"path": ["$.given_name"]}
+				]
+			}
+		}]
+	}`), &definition))
+	parameters["presentation_definition"] = definition
+
+	report := Validate(&Request{Parameters: parameters})
+	require.True(t, report.Valid, issueMessages(report))
+	require.Equal(t, VersionDraft23, report.Version)
+	require.Equal(t, QueryPresentationDefinition, report.Query)
+	require.Equal(t, []string{"pid"}, report.Credentials)
+}
+
+func TestValidateIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(map[string]any)
+		want   []string
+	}{
+		{
+			name:   "missing client_id",
+			modify: func(p map[string]any) { delete(p, "client_id") },
+			want:   []string{"client_id: client_id is required"},
+		},
+		{
+			name: "response without vp_token and nonce",
+			modify: func(p map[string]any) {
+				p["response_type"] = "code"
+				delete(p, "nonce")
+			},
+			want: []string{
+				`response_type: response_type "code" does not include vp_token`,
+				"nonce: nonce is required",
+			},
+		},
+		{
+			name: "direct_post without response_uri",
+			modify: func(p map[string]any) {
+				delete(p, "response_uri")
+				p["redirect_uri"] = "https://verifier.example/cb"
+			},
+			want: []string{
+				"response_uri: response_uri is required with response_mode direct_post",
+				"redirect_uri: redirect_uri must not be present",
+			},
+		},
+		{
+			name:   "unknown response_mode",
+			modify: func(p map[string]any) { p["response_mode"] = "form_post" },
+			want:   []string{`response_mode: unsupported response_mode "form_post"`},
+		},
+		{
+			name: "unknown format and empty algorithms",
+			modify: func(p map[string]any) {
+				metadata := p["client_metadata"].(map[string]any)
+				formats := metadata["vp_formats_supported"].(map[string]any)
+				formats["ac_vc"] = map[string]any{}
+				formats["dc+sd-jwt"] = map[string]any{"sd-jwt_alg_values": []any{}}
+			},
+			want: []string{
+				`client_metadata.vp_formats_supported.ac_vc: unknown credential format "ac_vc"`,
+				"client_metadata.vp_formats_supported.dc+sd-jwt.sd-jwt_alg_values: " +
+					"sd-jwt_alg_values is not a non-empty array of strings",
+			},
+		},
+		{
+			name: "client metadata without formats",
+			modify: func(p map[string]any) {
+				p["client_metadata"] = map[string]any{}
+			},
+			want: []string{
+				"client_metadata.vp_formats_supported: client metadata declares no supported",
+			},
+		},
+		{
+			name: "no query",
+			modify: func(p map[string]any) {
+				delete(p, "dcql_query")
+			},
+			want: []string{
+				"dcql_query: one of dcql_query, presentation_definition and scope is required",
+			},
+		},
+		{
+			name: "two queries",
+			modify: func(p map[string]any) {
+				p["scope"] = "openid pid"
+			},
+			want: []string{"scope: only one of dcql_query, scope is allowed"},
+		},
+		{
+			name: "invalid DCQL",
+			modify: func(p map[string]any) {
+				credentials := p["dcql_query"].(map[string]any)["credentials"].([]any)
+				pid := credentials[0].(map[string]any)
+				pid["format"] = "ldp_vc"
+				pid["claim_sets"] = []any{[]any{"family_name"}}
+				mdl := credentials[1].(map[string]any)
+				mdl["id"] = "pid"
+				mdl["meta"] = map[string]any{}
+				mdl["claims"] = []any{map[string]any{"path": []any{"family_name", -1.0}}}
+			},
+			want: []string{
+				`dcql_query.credentials.0.format: format "ldp_vc" is not among the formats`,
+				`dcql_query.credentials.0.claim_sets.0: unknown claim id "family_name"`,
+				`dcql_query.credentials.1.id: duplicate credential query id "pid"`,
+				"dcql_query.credentials.1.meta.doctype_value: doctype_value is required",
+				"dcql_query.credentials.1.claims.0.path.1: path index -1 is not a non-negative",
+				`dcql_query.credential_sets.0.options.1: unknown credential query id "mdl"`,
+			},
+		},
+		{
+			name: "DCQL without meta",
+			modify: func(p map[string]any) {
+				credentials := p["dcql_query"].(map[string]any)["credentials"].([]any)
+				delete(credentials[0].(map[string]any), "meta")
+			},
+			want: []string{"dcql_query.credentials.0.meta: meta is required"},
+		},
+		{
+			name: "invalid presentation definition",
+			modify: func(p map[string]any) {
+				delete(p, "dcql_query")
+				p["presentation_definition"] = map[string]any{
+					"input_descriptors": []any{
+						map[string]any{"id": "pid", "constraints": map[string]any{
+							"fields": []any{map[string]any{"path": []any{"vct"}}},
+						}},
+						map[string]any{"id": "pid"},
+					},
+				}
+			},
+			want: []string{
+				"presentation_definition.id: id is required",
+				`presentation_definition.input_descriptors.0.constraints.fields.0.path.0: "vct" is not a JSONPath`,
+				`presentation_definition.input_descriptors.1.id: duplicate input descriptor id "pid"`,
+				"presentation_definition.input_descriptors.1.constraints: constraints is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := testParameters(t)
+			tt.modify(parameters)
+			report := Validate(&Request{Parameters: parameters})
+			require.False(t, report.Valid)
+			require.Len(t, report.Issues, len(tt.want), issueMessages(report))
+			for _, want := range tt.want {
+				require.Contains(t, issueMessages(report), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// attemptTimeout bounds how long a receiver may take to answer.
+	attemptTimeout = 15 * time.Second
+	// responseBodyLimit bounds the part of the receiver answer kept on the
+	// delivery for inspection.
+	responseBodyLimit = 2048
+
+	userAgent = "Credimi-Webhooks/1.0"
+)
+
+// HTTPClient sends the deliveries. Redirects are not followed, so that a
+// receiver cannot bounce a signed delivery to another host, and only public
+// addresses are dialed, whatever the host of the webhook resolves to at
+// delivery time. Deliveries bypass HTTP proxies, which would hide the address.
+var HTTPClient = &http.Client{
+	Timeout: attemptTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: attemptTimeout,
+			Control: refusePrivateAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: attemptTimeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// refusePrivateAddress is a net.Dialer Control refusing to connect to
+// addresses that are not public.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || utils.IsPrivateIP(ip) {
+		return fmt.Errorf("refusing to deliver to non-public address %s", host)
+	}
+	return nil
+}
+
+// Result is the state of a delivery after an attempt. A pending result is
+// a failed attempt that must be retried.
+type Result struct {
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Attempt sends the pending delivery deliveryID to its webhook and records
+// the outcome on it. Deliveries that already completed are not sent again,
+// and deliveries whose webhook was deleted or disabled fail. The returned
+// error is only about reading or saving the delivery.
+func Attempt(ctx context.Context, app core.App, deliveryID string) (Result, error) {
+	delivery, err := app.FindRecordById(DeliveryCollection, deliveryID)
+	if err != nil {
+		return Result{}, err
+	}
+	if delivery.GetString("status") != StatusPending {
+		return result(delivery), nil
+	}
+
+	webhook, err := app.FindRecordById(Collection, delivery.GetString("webhook"))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fail(app, delivery, "webhook was deleted")
+	case err != nil:
+		return Result{}, err
+	case !webhook.GetBool("enabled"):
+		return fail(app, delivery, "webhook is disabled")
+	}
+
+	body := []byte(delivery.GetString("payload"))
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		webhook.GetString("url"),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fail(app, delivery, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.GetString("event"))
+	req.Header.Set(HeaderDelivery, delivery.Id)
+	req.Header.Set(HeaderSignature, Sign(webhook.GetString("secret"), now(), body))
+
+	delivery.Set("attempts", delivery.GetInt("attempts")+1)
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		delivery.Set("response_status", 0)
+		delivery.Set("response_body", "")
+		delivery.Set("last_error", err.Error())
+		return save(app, delivery)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, responseBodyLimit))
+	delivery.Set("response_status", resp.StatusCode)
+	delivery.Set("response_body", string(responseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Set("last_error", "receiver answered "+resp.Status)
+		return save(app, delivery)
+	}
+	delivery.Set("status", StatusSucceeded)
+	delivery.Set("last_error", "")
+	delivery.Set("delivered_at", now().UTC())
+	return save(app, delivery)
+}
+
+// MarkFailed gives up on the delivery deliveryID, once its retries are
+// exhausted. Deliveries that already completed are left untouched.
+func MarkFailed(app core.App, deliveryID, reason string) (Result, error) {
+	delivery, err := app.FindRecordById(DeliveryCollection, deliveryID)
+	if err != nil {
+		return Result{}, err
+	}
+	if delivery.GetString("status") != StatusPending {
+		return result(delivery), nil
+	}
+	if delivery.GetString("last_error") != "" {
+		reason = delivery.GetString("last_error")
+	}
+	return fail(app, delivery, reason)
+}
+
+func fail(app core.App, delivery *core.Record, reason string) (Result, error) {
+	delivery.Set("status", StatusFailed)
+	delivery.Set("last_error", reason)
+	return save(app, delivery)
+}
+
+func save(app core.App, delivery *core.Record) (Result, error) {
+	if err := app.Save(delivery); err != nil {
+		return Result{}, fmt.Errorf("failed to save delivery %s: %w", delivery.Id, err)
+	}
+	return result(delivery), nil
+}
+
+func result(delivery *core.Record) Result {
+	return Result{
+		Status:         delivery.GetString("status"),
+		Attempts:       delivery.GetInt("attempts"),
+		ResponseStatus: delivery.GetInt("response_status"),
+		Error:          delivery.GetString("last_error"),
+	}
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func emitOne(t testing.TB, app core.App, orgID string, event Event) *core.Record {
+	t.Helper()
+	deliveries, err := Emit(app, orgID, event, map[string]any{"id": "x"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	return deliveries[0]
+}
+
+func TestAttempt(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	orgA := organizationID(t, app, "userA's organization")
+
+	status := http.StatusInternalServerError
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("answer"))
+	}))
+	defer server.Close()
+	// The server listens on loopback, which HTTPClient refuses to dial.
+	previousClient := HTTPClient
+	HTTPClient = server.Client()
+	defer func() { HTTPClient = previousClient }()
+
+	hook := createWebhook(t, app, orgA, server.URL, true, EventPipelineStarted)
+	delivery := emitOne(t, app, orgA, EventPipelineStarted)
+
+	result, err := Attempt(context.Background(), app, delivery.Id)
+	require.NoError(t, err)
+	require.Equal(t, Result{
+		Status:         StatusPending,
+		Attempts:       1,
+		ResponseStatus: http.StatusInternalServerError,
+		Error:          "receiver answered 500 Internal Server Error",
+	}, result)
+
+	status = http.StatusAccepted
+	result, err = Attempt(context.Background(), app, delivery.Id)
+	require.NoError(t, err)
+	require.Equal(t, Result{
+		Status:         StatusSucceeded,
+		Attempts:       2,
+		ResponseStatus: http.StatusAccepted,
+	}, result)
+
+	require.Equal(t, string(EventPipelineStarted), received.Header.Get(HeaderEvent))
+	require.Equal(t, delivery.Id, received.Header.Get(HeaderDelivery))
+	require.NoError(t, Verify(
+		hook.GetString("secret"),
+		received.Header.Get(HeaderSignature),
+		receivedBody,
+		time.Now(),
+		time.Minute,
+	))
+
+	stored, err := app.FindRecordById(DeliveryCollection, delivery.Id)
+	require.NoError(t, err)
+	require.Equal(t, "answer", stored.GetString("response_body"))
+	require.False(t, stored.GetDateTime("delivered_at").IsZero())
+
+	received = nil
+	result, err = Attempt(context.Background(), app, delivery.Id)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, result.Status)
+	require.Nil(t, received, "completed deliveries are not sent again")
+}
+
+func TestAttemptRefusesPrivateAddresses(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	orgA := organizationID(t, app, "userA's organization")
+
+	var received bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	createWebhook(t, app, orgA, server.URL, true, EventPipelineStarted)
+	delivery := emitOne(t, app, orgA, EventPipelineStarted)
+
+	result, err := Attempt(context.Background(), app, delivery.Id)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, result.Status)
+	require.Contains(t, result.Error, "refusing to deliver to non-public address 127.0.0.1")
+	require.False(t, received)
+}
+
+func TestAttemptFailsWithoutAnEnabledWebhook(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	orgA := organizationID(t, app, "userA's organization")
+
+	hook := createWebhook(t, app, orgA, "https://a.example/hook", true, EventRunnerOffline)
+	disabled := emitOne(t, app, orgA, EventRunnerOffline)
+	deleted := emitOne(t, app, orgA, EventRunnerOffline)
+
+	hook.Set("enabled", false)
+	require.NoError(t, app.Save(hook))
+	result, err := Attempt(context.Background(), app, disabled.Id)
+	require.NoError(t, err)
+	require.Equal(t, Result{Status: StatusFailed, Error: "webhook is disabled"}, result)
+
+	require.NoError(t, app.Delete(hook))
+	result, err = Attempt(context.Background(), app, deleted.Id)
+	require.NoError(t, err)
+	require.Equal(t, Result{Status: StatusFailed, Error: "webhook was deleted"}, result)
+}
+
+func TestMarkFailed(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+	orgA := organizationID(t, app, "userA's organization")
+
+	createWebhook(t, app, orgA, "https://a.example/hook", true, EventStepFailed)
+	delivery := emitOne(t, app, orgA, EventStepFailed)
+
+	result, err := MarkFailed(app, delivery.Id, "retries exhausted")
+	require.NoError(t, err)
+	require.Equal(t, Result{Status: StatusFailed, Error: "retries exhausted"}, result)
+
+	result, err = MarkFailed(app, delivery.Id, "again")
+	require.NoError(t, err)
+	require.Equal(t, "retries exhausted", result.Error)
+
+	_, err = MarkFailed(app, "missing", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderSignature = "Credimi-Signature"
+	HeaderEvent     = "Credimi-Event"
+	HeaderDelivery  = "Credimi-Delivery"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrSignatureExpired = errors.New("webhook signature timestamp is too old")
+)
+
+// Sign returns the Credimi-Signature header of body sent at timestamp:
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" keyed with
+// secret>. Signing the time lets receivers refuse replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + signature(secret, unix, body)
+}
+
+// Verify checks that header is a signature of body with secret, made at
+// most tolerance before now.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, expected string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			expected = value
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || expected == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature(secret, unix, body))) {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func signature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	sentAt := time.Unix(1767225600, 0)
+	body := []byte(`{"event":"pipeline.started"}`)
+
+	header := Sign("whsec_test", sentAt, body)
+	require.Equal(
+		t,
+		"t=1767225600,v1=fa8b817409d4cf877dfa12fb67615810cf9c5af10ad0888e9c51bc4bb6876ce4",
+		header,
+	)
+	require.NoError(t, Verify("whsec_test", header, body, sentAt.Add(time.Minute), 5*time.Minute))
+
+	testCases := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		err    error
+	}{
+		{"wrong secret", "whsec_other", header, body, sentAt, ErrInvalidSignature},
+		{"tampered body", "whsec_test", header, []byte(`{}`), sentAt, ErrInvalidSignature},
+		{"missing signature", "whsec_test", "t=1767225600", body, sentAt, ErrInvalidSignature},
+		{"malformed time", "whsec_test", "t=x,v1=00", body, sentAt, ErrInvalidSignature},
+		{
+			"expired",
+			"whsec_test",
+			header,
+			body,
+			sentAt.Add(10 * time.Minute),
+			ErrSignatureExpired,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorIs(t, Verify(tc.secret, tc.header, tc.body, tc.now, 5*time.Minute), tc.err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package webhook delivers the events of an organization to the URLs it
+// subscribed in the webhooks collection. Every delivery is a
+// webhook_deliveries record, signed with the secret of its webhook and sent
+// by the webhook delivery workflow, which retries it with backoff.
+package webhook
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	Collection         = "webhooks"
+	DeliveryCollection = "webhook_deliveries"
+)
+
+// Event is the type of a platform event a webhook can subscribe to.
+type Event string
+
+const (
+	EventPipelineStarted   Event = "pipeline.started"
+	EventPipelineFinished  Event = "pipeline.finished"
+	EventStepFailed        Event = "step.failed"
+	EventRunTicketQueued   Event = "run_ticket.queued"
+	EventRunTicketGranted  Event = "run_ticket.granted"
+	EventRunnerOffline     Event = "runner.offline"
+	EventSchedulePaused    Event = "schedule.paused"
+	EventScoreboardUpdated Event = "scoreboard.updated"
+)
+
+// Events lists every event a webhook can subscribe to.
+var Events = []Event{
+	EventPipelineStarted,
+	EventPipelineFinished,
+	EventStepFailed,
+	EventRunTicketQueued,
+	EventRunTicketGranted,
+	EventRunnerOffline,
+	EventSchedulePaused,
+	EventScoreboardUpdated,
+}
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// secretPrefix marks webhook signing secrets, so that they are recognized
+// when leaked.
+const secretPrefix = "whsec_"
+
+var now = time.Now
+
+// Payload is the JSON body sent to a webhook. ID identifies the event and
+// is kept by redeliveries, so that receivers can drop duplicates.
+type Payload struct {
+	ID           string `json:"id"`
+	Event        Event  `json:"event"`
+	Organization string `json:"organization"`
+	CreatedAt    string `json:"created_at"`
+	Data         any    `json:"data"`
+}
+
+// NewSecret returns a random signing secret for a webhook.
+func NewSecret() string {
+	return secretPrefix + security.RandomString(32)
+}
+
+// Emit queues a delivery of event to every enabled webhook of orgID that
+// subscribed to it, and returns the deliveries. The delivery workflow is
+// started by the hooks of the webhook_deliveries collection.
+func Emit(app core.App, orgID string, event Event, data any) ([]*core.Record, error) {
+	if orgID == "" {
+		return nil, nil
+	}
+	webhooks, err := app.FindRecordsByFilter(
+		Collection,
+		"owner = {:owner} && enabled = true",
+		"created",
+		-1,
+		0,
+		dbx.Params{"owner": orgID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhooks of %s: %w", orgID, err)
+	}
+	return deliver(app, webhooks, event, data)
+}
+
+// Broadcast queues a delivery of event to every enabled webhook that
+// subscribed to it, whatever its organization. It is used for the events
+// of the platform, such as scoreboard updates.
+func Broadcast(app core.App, event Event, data any) ([]*core.Record, error) {
+	webhooks, err := app.FindRecordsByFilter(Collection, "enabled = true", "created", -1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	return deliver(app, webhooks, event, data)
+}
+
+// Redeliver queues a new delivery of the payload of delivery to its
+// webhook. The original delivery is left untouched.
+func Redeliver(app core.App, delivery *core.Record) (*core.Record, error) {
+	if delivery.GetString("webhook") == "" {
+		return nil, fmt.Errorf("webhook of delivery %s was deleted", delivery.Id)
+	}
+	record := core.NewRecord(delivery.Collection())
+	record.Set("owner", delivery.GetString("owner"))
+	record.Set("webhook", delivery.GetString("webhook"))
+	record.Set("event", delivery.GetString("event"))
+	record.Set("payload", delivery.Get("payload"))
+	record.Set("status", StatusPending)
+	record.Set("redelivery_of", delivery.Id)
+	if err := app.Save(record); err != nil {
+		return nil, fmt.Errorf("failed to redeliver %s: %w", delivery.Id, err)
+	}
+	return record, nil
+}
+
+// SubscribedEvents returns the events webhook subscribed to.
+func SubscribedEvents(webhook *core.Record) []Event {
+	var events []Event
+	if err := webhook.UnmarshalJSONField("events", &events); err != nil {
+		return nil
+	}
+	return events
+}
+
+func deliver(
+	app core.App,
+	webhooks []*core.Record,
+	event Event,
+	data any,
+) ([]*core.Record, error) {
+	var deliveries []*core.Record
+	eventID := uuid.NewString()
+	createdAt := now().UTC().Format(time.RFC3339)
+	for _, webhook := range webhooks {
+		if !slices.Contains(SubscribedEvents(webhook), event) {
+			continue
+		}
+		collection, err := app.FindCachedCollectionByNameOrId(DeliveryCollection)
+		if err != nil {
+			return deliveries, err
+		}
+		record := core.NewRecord(collection)
+		record.Set("owner", webhook.GetString("owner"))
+		record.Set("webhook", webhook.Id)
+		record.Set("event", string(event))
+		record.Set("payload", Payload{
+			ID:           eventID,
+			Event:        event,
+			Organization: webhook.GetString("owner"),
+			CreatedAt:    createdAt,
+			Data:         data,
+		})
+		record.Set("status", StatusPending)
+		if err := app.Save(record); err != nil {
+			return deliveries, fmt.Errorf(
+				"failed to queue %s for webhook %s: %w",
+				event,
+				webhook.Id,
+				err,
+			)
+		}
+		deliveries = append(deliveries, record)
+	}
+	return deliveries, nil
+}
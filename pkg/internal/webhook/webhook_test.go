@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataDir = "../../../test_pb_data"
+
+func organizationID(t testing.TB, app core.App, name string) string {
+	t.Helper()
+	org, err := app.FindFirstRecordByData("organizations", "name", name)
+	require.NoError(t, err)
+	return org.Id
+}
+
+func createWebhook(
+	t testing.TB,
+	app core.App,
+	orgID string,
+	url string,
+	enabled bool,
+	events ...Event,
+) *core.Record {
+	t.Helper()
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	require.NoError(t, err)
+	record := core.NewRecord(collection)
+	record.Set("owner", orgID)
+	record.Set("name", "hook")
+	record.Set("url", url)
+	record.Set("secret", NewSecret())
+	record.Set("events", events)
+	record.Set("enabled", enabled)
+	require.NoError(t, app.Save(record))
+	return record
+}
+
+func decodePayload(t testing.TB, delivery *core.Record) Payload {
+	t.Helper()
+	var payload Payload
+	require.NoError(t, json.Unmarshal([]byte(delivery.GetString("payload")), &payload))
+	return payload
+}
+
+func TestEmit(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	orgA := organizationID(t, app, "userA's organization")
+	orgB := organizationID(t, app, "userB's organization")
+	subscribed := createWebhook(
+		t, app, orgA, "https://a.example/hook", true,
+		EventPipelineStarted, EventStepFailed,
+	)
+	createWebhook(t, app, orgA, "https://a.example/other", true, EventSchedulePaused)
+	createWebhook(t, app, orgA, "https://a.example/disabled", false, EventPipelineStarted)
+	createWebhook(t, app, orgB, "https://b.example/hook", true, EventPipelineStarted)
+
+	deliveries, err := Emit(app, orgA, EventPipelineStarted, map[string]any{"pipeline": "p1"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	delivery, err := app.FindRecordById(DeliveryCollection, deliveries[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, subscribed.Id, delivery.GetString("webhook"))
+	require.Equal(t, orgA, delivery.GetString("owner"))
+	require.Equal(t, StatusPending, delivery.GetString("status"))
+	payload := decodePayload(t, delivery)
+	require.NotEmpty(t, payload.ID)
+	require.Equal(t, EventPipelineStarted, payload.Event)
+	require.Equal(t, orgA, payload.Organization)
+	require.Equal(t, map[string]any{"pipeline": "p1"}, payload.Data)
+
+	deliveries, err = Emit(app, orgA, EventRunnerOffline, nil)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+
+	deliveries, err = Emit(app, "", EventPipelineStarted, nil)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func TestBroadcast(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	orgA := organizationID(t, app, "userA's organization")
+	orgB := organizationID(t, app, "userB's organization")
+	createWebhook(t, app, orgA, "https://a.example/hook", true, EventScoreboardUpdated)
+	createWebhook(t, app, orgB, "https://b.example/hook", true, EventScoreboardUpdated)
+	createWebhook(t, app, orgB, "https://b.example/other", true, EventPipelineStarted)
+
+	deliveries, err := Broadcast(app, EventScoreboardUpdated, map[string]any{"pipelines": 3})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	owners := []string{deliveries[0].GetString("owner"), deliveries[1].GetString("owner")}
+	require.ElementsMatch(t, []string{orgA, orgB}, owners)
+	require.Equal(t,
+		decodePayload(t, deliveries[0]).ID,
+		decodePayload(t, deliveries[1]).ID,
+		"one event is delivered with one id",
+	)
+}
+
+func TestRedeliver(t *testing.T) {
+	app, err := tests.NewTestApp(testDataDir)
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	orgA := organizationID(t, app, "userA's organization")
+	hook := createWebhook(t, app, orgA, "https://a.example/hook", true, EventSchedulePaused)
+	deliveries, err := Emit(app, orgA, EventSchedulePaused, map[string]any{"schedule": "s1"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	original, err := app.FindRecordById(DeliveryCollection, deliveries[0].Id)
+	require.NoError(t, err)
+	original.Set("status", StatusFailed)
+	require.NoError(t, app.Save(original))
+
+	redelivery, err := Redeliver(app, original)
+	require.NoError(t, err)
+	require.NotEqual(t, original.Id, redelivery.Id)
+	require.Equal(t, original.Id, redelivery.GetString("redelivery_of"))
+	require.Equal(t, StatusPending, redelivery.GetString("status"))
+	require.Equal(t, decodePayload(t, original), decodePayload(t, redelivery))
+
+	require.NoError(t, app.Delete(hook))
+	original, err = app.FindRecordById(DeliveryCollection, original.Id)
+	require.NoError(t, err, "deliveries outlive their webhook")
+	_, err = Redeliver(app, original)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "deleted"))
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/forkbombeu/credimi/pkg/internal/logo"
 	"github.com/forkbombeu/credimi/pkg/internal/pb"
 	pipelineresults "github.com/forkbombeu/credimi/pkg/internal/pipeline_results"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/internal/recordsecrets"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
 	walletversions "github.com/forkbombeu/credimi/pkg/internal/wallet_versions"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/hooks"
@@ -57,6 +59,7 @@ func bindAppHooks(app core.App) {
 //     for JavaScript-based templates and automatic migration.
 func Setup(app *pocketbase.PocketBase) {
 	bindAppHooks(app)
+	tracing.RegisterHooks(app)
 	pb.HookOrganizations(app)
 	pb.RegisterMobileRunnerWorkerManagerHooks(app)
 	pb.HookNamespaceOrgs(app)
@@ -64,6 +67,9 @@ func Setup(app *pocketbase.PocketBase) {
 	pb.RegisterPipelineHooks(app)
 	pb.RegisterWalletActionHooks(app)
 	pb.RegisterSchedulesHooks(app)
+	pb.RegisterAuditHooks(app)
+	pb.RegisterWebhookHooks(app)
+	pb.RegisterIdempotencyHooks(app)
 	apis.RegisterMyRoutes(app)
 	hooks.WorkersHook(app)
 	canonify.RegisterCanonifyHooks(app)
@@ -73,6 +79,7 @@ func Setup(app *pocketbase.PocketBase) {
 	logo.LogoHooks(app)
 	walletversions.WalletVersionHooks(app)
 	pipelineresults.RegisterPipelineResultsHooks(app)
+	quota.RegisterStorageHooks(app)
 	recordsecrets.RegisterHooks(app)
 	// apis.IssuersRoutes.Add(app)
 
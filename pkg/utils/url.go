@@ -5,6 +5,7 @@
 package utils
 
 import (
+	"net"
 	"net/url"
 )
 
@@ -20,3 +21,11 @@ func JoinURL(base string, parts ...string) string {
 	}
 	return u.String()
 }
+
+// IsPrivateIP reports whether ip is not reachable on the public internet:
+// private, loopback, link-local or unspecified. Servers refuse to send
+// requests chosen by users to such addresses.
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
@@ -5,6 +5,7 @@
 package utils
 
 import (
+	"net"
 	"testing"
 )
 
@@ -34,3 +35,19 @@ func TestJoinURLReturnsBaseForInvalidURL(t *testing.T) {
 		t.Fatalf("JoinURL returned %q, want original base", got)
 	}
 }
+
+func TestIsPrivateIP(t *testing.T) {
+	for _, ip := range []string{
+		"10.0.0.1", "172.16.0.1", "192.168.1.1", "127.0.0.1", "169.254.169.254",
+		"0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+	} {
+		if !IsPrivateIP(net.ParseIP(ip)) {
+			t.Errorf("IsPrivateIP(%s) = false; want true", ip)
+		}
+	}
+	for _, ip := range []string{"8.8.8.8", "2606:4700:4700::1111"} {
+		if IsPrivateIP(net.ParseIP(ip)) {
+			t.Errorf("IsPrivateIP(%s) = true; want false", ip)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/forkbombeu/credimi/pkg/internal/statuslist"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+const statusListFetchTimeout = 30 * time.Second
+
+// statusListCache is shared by the activities of a worker, so that pipelines
+// checking many credentials against the same list download it once.
+var statusListCache = statuslist.NewCache()
+
+// CheckCredentialStatusActivity resolves the revocation or suspension status
+// of a credential from its IETF Token Status List, W3C Bitstring Status List
+// or StatusList2021 entry.
+type CheckCredentialStatusActivity struct {
+	workflowengine.BaseActivity
+}
+
+// CheckCredentialStatusActivityPayload locates the status either through
+// Credential, as produced by an earlier step, or explicitly with
+// StatusListURI and Index, which take precedence. JWKS and
+// TrustedCertificates, as PEM or base64 DER, are the keys trusted to sign the
+// list. ExpectedStatus makes the step fail when the status differs.
+type CheckCredentialStatusActivityPayload struct {
+	Credential          any                `json:"credential,omitempty"             yaml:"credential,omitempty"`
+	StatusListURI       string             `json:"status_list_uri,omitempty"        yaml:"status_list_uri,omitempty"`
+	Index               *int               `json:"index,omitempty"                  yaml:"index,omitempty"`
+	Format              string             `json:"format,omitempty"                 yaml:"format,omitempty"                 validate:"omitempty,oneof=token_status_list bitstring_status_list status_list_2021"`
+	Purpose             string             `json:"purpose,omitempty"                yaml:"purpose,omitempty"`
+	StatusSize          int                `json:"status_size,omitempty"            yaml:"status_size,omitempty"`
+	ExpectedStatus      string             `json:"expected_status,omitempty"        yaml:"expected_status,omitempty"        validate:"omitempty,oneof=valid invalid revoked suspended application_specific"`
+	JWKS                *federation.JWKSet `json:"jwks,omitempty"                   yaml:"jwks,omitempty"`
+	TrustedCertificates []string           `json:"trusted_certificates,omitempty"   yaml:"trusted_certificates,omitempty"`
+	RequireTrusted      bool               `json:"require_trusted_signer,omitempty" yaml:"require_trusted_signer,omitempty"`
+	NoCache             bool               `json:"no_cache,omitempty"               yaml:"no_cache,omitempty"`
+}
+
+func NewCheckCredentialStatusActivity() *CheckCredentialStatusActivity {
+	return &CheckCredentialStatusActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Check the status of a credential in its status list",
+		},
+	}
+}
+
+// Name returns the name of the CheckCredentialStatusActivity.
+func (a *CheckCredentialStatusActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute fetches and verifies the status list and reads the credential
+// entry. The output is a statuslist.Result encoded as a map.
+func (a *CheckCredentialStatusActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[CheckCredentialStatusActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	ref, err := payload.reference()
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	trusted, err := trustedlist.ParseTrustedCertificates(payload.TrustedCertificates)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	checker := &statuslist.Checker{
+		HTTPClient: &http.Client{
+			Timeout:   statusListFetchTimeout,
+			Transport: tracing.HTTPTransport(nil),
+		},
+		Cache:   statusListCache,
+		Keys:    statuslist.Keys{JWKS: payload.JWKS, TrustedCertificates: trusted},
+		NoCache: payload.NoCache,
+	}
+	status, err := checker.Check(ctx, ref)
+	if err != nil {
+		return result, newStatusListError(&a.BaseActivity, ref, err)
+	}
+	if payload.RequireTrusted && !status.Signature.Trusted {
+		return result, newStatusListError(&a.BaseActivity, ref, &statuslist.SignatureError{
+			Err: errors.New("the status list is not signed by a trusted key"),
+		})
+	}
+
+	output, err := statusResultMap(status)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+
+	expected := payload.ExpectedStatus
+	if expected == "revoked" {
+		expected = statuslist.StatusInvalid
+	}
+	if expected != "" && expected != status.Label {
+		errCode := errorcodes.Codes[errorcodes.CredentialStatusMismatch]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: fmt.Sprintf(
+				"credential status is %q, expected %q",
+				status.Label,
+				payload.ExpectedStatus,
+			),
+			Details: output,
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+// reference reads the status entry of the payload credential, overridden by
+// the explicit fields.
+func (p CheckCredentialStatusActivityPayload) reference() (statuslist.Reference, error) {
+	var ref statuslist.Reference
+	if p.Credential != nil {
+		var err error
+		if ref, err = statuslist.ReferenceFromCredential(p.Credential, p.Purpose); err != nil {
+			return ref, err
+		}
+	} else if p.StatusListURI == "" || p.Index == nil {
+		return ref, errors.New("credential or status_list_uri and index are required")
+	}
+
+	if p.StatusListURI != "" {
+		ref.URI = p.StatusListURI
+	}
+	if p.Index != nil {
+		ref.Index = *p.Index
+	}
+	if p.Format != "" {
+		ref.Format = p.Format
+	}
+	if ref.Format == "" {
+		ref.Format = statuslist.FormatTokenStatusList
+	}
+	if p.Purpose != "" {
+		ref.Purpose = p.Purpose
+	}
+	if p.StatusSize > 0 {
+		ref.StatusSize = p.StatusSize
+	}
+	return ref, ref.Validate()
+}
+
+func newStatusListError(
+	a *workflowengine.BaseActivity,
+	ref statuslist.Reference,
+	err error,
+) error {
+	code := errorcodes.StatusListInvalid
+	var fetchErr *statuslist.FetchError
+	var signatureErr *statuslist.SignatureError
+	switch {
+	case errors.As(err, &fetchErr):
+		code = errorcodes.ExecuteHTTPRequestFailed
+	case errors.As(err, &signatureErr):
+		code = errorcodes.StatusListSignatureInvalid
+	}
+	errCode := errorcodes.Codes[code]
+	return a.NewActivityError(workflowengine.ActivityError{
+		Code:    errCode.Code,
+		Summary: errCode.Description,
+		Message: err.Error(),
+		Details: map[string]any{"uri": ref.URI, "index": ref.Index, "format": ref.Format},
+	})
+}
+
+func statusResultMap(status statuslist.Result) (map[string]any, error) {
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/forkbombeu/credimi/pkg/internal/statuslist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// newStatusListServer serves at /statuslists/1 a Status List Token where the
+// credential at index 1 is revoked, signed with the key in the returned JWKS.
+func newStatusListServer(t *testing.T) (*httptest.Server, *federation.JWKSet) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := federation.NewJWK("status", &key.PublicKey)
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err = writer.Write([]byte{0b00000010})
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/statuslists/1" {
+			http.NotFound(w, r)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"sub": server.URL + "/statuslists/1",
+			"iat": time.Now().Unix(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"status_list": map[string]any{
+				"bits": 1,
+				"lst":  base64.RawURLEncoding.EncodeToString(compressed.Bytes()),
+			},
+		})
+		token.Header["typ"] = "statuslist+jwt"
+		token.Header["kid"] = "status"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", statuslist.MediaTypeStatusListJWT)
+		_, _ = w.Write([]byte(signed))
+	}))
+	t.Cleanup(server.Close)
+	return server, &federation.JWKSet{Keys: []federation.JWK{jwk}}
+}
+
+func TestCheckCredentialStatusActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewCheckCredentialStatusActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	server, jwks := newStatusListServer(t)
+	uri := server.URL + "/statuslists/1"
+	index := func(i int) *int { return &i }
+
+	// An SD-JWT VC issued earlier in the pipeline, referring to index 1.
+	credential := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"vct": "urn:eudi:pid:1",
+		"status": map[string]any{
+			"status_list": map[string]any{"idx": 1, "uri": uri},
+		},
+	})
+	sdJWT, err := credential.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	t.Run("reads the status of a credential", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialStatusActivityPayload{
+				Credential:     sdJWT + "~",
+				ExpectedStatus: "revoked",
+				JWKS:           jwks,
+				NoCache:        true,
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, statuslist.StatusInvalid, output["label"])
+		require.Equal(t, float64(1), output["status"])
+		reference := output["reference"].(map[string]any)
+		require.Equal(t, statuslist.FormatTokenStatusList, reference["format"])
+		require.Equal(t, uri, reference["uri"])
+		signature := output["signature"].(map[string]any)
+		require.Equal(t, true, signature["verified"])
+		require.Equal(t, true, signature["trusted"])
+	})
+
+	t.Run("fails when the status differs from the expected one", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialStatusActivityPayload{
+				StatusListURI:  uri,
+				Index:          index(0),
+				ExpectedStatus: statuslist.StatusInvalid,
+				JWKS:           jwks,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.CredentialStatusMismatch].Code,
+		)
+		require.Contains(t, err.Error(), `credential status is "valid"`)
+	})
+
+	t.Run("rejects lists signed by an unknown key", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialStatusActivityPayload{
+				Credential: sdJWT,
+				NoCache:    true,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.StatusListSignatureInvalid].Code,
+		)
+	})
+
+	t.Run("reports lists that cannot be fetched", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialStatusActivityPayload{
+				StatusListURI: server.URL + "/statuslists/2",
+				Index:         index(0),
+				JWKS:          jwks,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.ExecuteHTTPRequestFailed].Code,
+		)
+	})
+
+	t.Run("reports indexes outside the list", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialStatusActivityPayload{
+				StatusListURI: uri,
+				Index:         index(8),
+				JWKS:          jwks,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.StatusListInvalid].Code)
+	})
+
+	t.Run("requires a status reference", func(t *testing.T) {
+		for _, payload := range []CheckCredentialStatusActivityPayload{
+			{StatusListURI: uri},
+			{StatusListURI: uri, Index: index(0), Format: "revocation_list_2020"},
+			{StatusListURI: uri, Index: index(0), ExpectedStatus: "unknown"},
+			{Credential: map[string]any{"vct": "urn:eudi:pid:1"}},
+		} {
+			_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+				Payload: payload,
+			})
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code,
+			)
+		}
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 )
@@ -33,6 +34,11 @@ type CheckCredentialsIssuerActivity struct {
 
 type CheckCredentialsIssuerActivityPayload struct {
 	BaseURL string `json:"base_url" yaml:"base_url" validate:"required"`
+	// TrustAnchors enables trust chain verification of federation metadata.
+	// When empty, the anchors from FEDERATION_TRUST_ANCHORS are used, and
+	// without those the entity statement is only decoded.
+	TrustAnchors       []federation.TrustAnchor `json:"trust_anchors,omitempty"        yaml:"trust_anchors,omitempty"`
+	RequiredTrustMarks []string                 `json:"required_trust_marks,omitempty" yaml:"required_trust_marks,omitempty"`
 }
 
 func NewCheckCredentialsIssuerActivity() *CheckCredentialsIssuerActivity {
@@ -115,13 +121,7 @@ func (a *CheckCredentialsIssuerActivity) Execute(
 			return result, err
 		}
 
-		return workflowengine.ActivityResult{
-			Output: map[string]any{
-				"rawJSON":  federationJSON,
-				"base_url": payload.BaseURL,
-				"source":   ".well-known/openid-federation",
-			},
-		}, nil
+		return a.federationResult(ctx, payload, federationEntityID(cleanURL), federationJSON)
 	}
 	if isCredentialIssuerWellKnownURL(cleanURL) {
 		issuerJSON, err := fetchJSONFromURL(ctx, cleanURL, false, a)
@@ -143,13 +143,7 @@ func (a *CheckCredentialsIssuerActivity) Execute(
 		federationURL := utils.JoinURL(baseForFederation, ".well-known", "openid-federation")
 		federationJSON, err := fetchJSONFromURL(ctx, federationURL, true, a)
 		if err == nil {
-			return workflowengine.ActivityResult{
-				Output: map[string]any{
-					"rawJSON":  federationJSON,
-					"base_url": payload.BaseURL,
-					"source":   ".well-known/openid-federation",
-				},
-			}, nil
+			return a.federationResult(ctx, payload, baseForFederation, federationJSON)
 		}
 	}
 	// 2. Fallback to credential issuer
@@ -169,6 +163,63 @@ func (a *CheckCredentialsIssuerActivity) Execute(
 	}, nil
 }
 
+// federationResult builds the output for metadata read from an entity
+// configuration. When trust anchors are configured the trust chain of the
+// entity is verified and the policy-resolved metadata replaces the metadata
+// decoded from the entity configuration.
+func (a *CheckCredentialsIssuerActivity) federationResult(
+	ctx context.Context,
+	payload CheckCredentialsIssuerActivityPayload,
+	entityID string,
+	federationJSON string,
+) (workflowengine.ActivityResult, error) {
+	output := map[string]any{
+		"rawJSON":  federationJSON,
+		"base_url": payload.BaseURL,
+		"source":   ".well-known/openid-federation",
+	}
+
+	anchors := payload.TrustAnchors
+	if len(anchors) == 0 {
+		anchors = defaultFederationTrustAnchors()
+	}
+	if len(anchors) == 0 {
+		return workflowengine.ActivityResult{Output: output}, nil
+	}
+
+	resolver := newFederationResolver(anchors)
+	resolver.RequiredTrustMarks = payload.RequiredTrustMarks
+	resolution, err := resolver.Resolve(ctx, entityID, federation.EntityTypeCredentialIssuer)
+	if err != nil {
+		return workflowengine.ActivityResult{}, newTrustChainError(&a.BaseActivity, entityID, err)
+	}
+	metadata, err := json.Marshal(resolution.Metadata)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return workflowengine.ActivityResult{}, a.NewActivityError(
+			workflowengine.ActivityError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: err.Error(),
+			},
+		)
+	}
+	report, err := chainReportMap(resolution.Report)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return workflowengine.ActivityResult{}, a.NewActivityError(
+			workflowengine.ActivityError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: err.Error(),
+			},
+		)
+	}
+	output["rawJSON"] = string(metadata)
+	output["trust_chain"] = report
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
 func fetchJSONFromURL(
 	ctx context.Context,
 	url string,
@@ -303,3 +354,12 @@ func isFederationWellKnownURL(rawURL string) bool {
 	return strings.Contains(rawURL, wellKnownPath+"/") ||
 		strings.HasSuffix(rawURL, wellKnownPath)
 }
+
+// federationEntityID derives the entity identifier from the URL of its entity
+// configuration, undoing the path insertion of the well-known segment.
+func federationEntityID(rawURL string) string {
+	const wellKnownPath = "/.well-known/openid-federation"
+
+	prefix, suffix, _ := strings.Cut(rawURL, wellKnownPath)
+	return strings.TrimRight(prefix+suffix, "/")
+}
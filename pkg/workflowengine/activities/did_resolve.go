@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+// EBSIDIDRegistryURLEnv overrides the EBSI DID Registry identifiers endpoint,
+// for instance with a local stub, when a payload does not set one.
+const EBSIDIDRegistryURLEnv = "EBSI_DID_REGISTRY_URL"
+
+const didResolveTimeout = 30 * time.Second
+
+// ResolveDIDActivity resolves a did:web, did:jwk, did:key or did:ebsi DID and
+// validates the structure, verification methods and keys of its document.
+type ResolveDIDActivity struct {
+	workflowengine.BaseActivity
+}
+
+type ResolveDIDActivityPayload struct {
+	DID             string `json:"did"                         yaml:"did"                         validate:"required"`
+	EBSIRegistryURL string `json:"ebsi_registry_url,omitempty" yaml:"ebsi_registry_url,omitempty"`
+}
+
+func NewResolveDIDActivity() *ResolveDIDActivity {
+	return &ResolveDIDActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Resolve and validate a DID",
+		},
+	}
+}
+
+// Name returns the name of the ResolveDIDActivity.
+func (a *ResolveDIDActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute resolves the payload DID. The output is the DID resolution result,
+// with the did, its method and the validation report of the document.
+func (a *ResolveDIDActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[ResolveDIDActivityPayload](input.Payload)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	did := strings.TrimSpace(payload.DID)
+
+	registry := payload.EBSIRegistryURL
+	if registry == "" {
+		registry = utils.GetEnvironmentVariable(
+			EBSIDIDRegistryURLEnv,
+			didresolver.DefaultEBSIRegistryURL,
+		)
+	}
+	resolver := &didresolver.Resolver{
+		HTTPClient: &http.Client{
+			Timeout:   didResolveTimeout,
+			Transport: tracing.HTTPTransport(nil),
+		},
+		EBSIRegistryURL: registry,
+	}
+	resolution, err := resolver.Resolve(ctx, did)
+	if err != nil {
+		details := map[string]any{"did": did}
+		var resolutionErr *didresolver.ResolutionError
+		if errors.As(err, &resolutionErr) {
+			details["error"] = resolutionErr.Code
+		}
+		errCode := errorcodes.Codes[errorcodes.DIDResolutionFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+			Details: details,
+		})
+	}
+
+	report := didresolver.Validate(did, resolution.DIDDocument)
+	output, err := didResolutionMap(did, resolution, report)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	if !report.Valid {
+		errCode := errorcodes.Codes[errorcodes.DIDDocumentInvalid]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: fmt.Sprintf(
+				"DID document of %s is invalid: %s",
+				did,
+				strings.Join(report.Errors, "; "),
+			),
+			Details: output,
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+func didResolutionMap(
+	did string,
+	resolution *didresolver.Resolution,
+	report didresolver.Report,
+) (map[string]any, error) {
+	encoded, err := json.Marshal(resolution)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	encoded, err = json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	validation := map[string]any{}
+	if err := json.Unmarshal(encoded, &validation); err != nil {
+		return nil, err
+	}
+	out["did"] = did
+	out["method"] = resolution.ResolutionMetadata.Method
+	out["validation"] = validation
+	return out, nil
+}
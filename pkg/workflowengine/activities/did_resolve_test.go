@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/didresolver"
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const (
+	ebsiDID      = "did:ebsi:zktk1AQHwChDgxvEAdJLmjv"
+	otherEBSIDID = "did:ebsi:zktk1AQHwChDgxvEAdJLmjw"
+)
+
+// newEBSIRegistryStub serves the DID Registry API documents of ebsiDID, valid,
+// and otherEBSIDID, whose verification method has no key.
+func newEBSIRegistryStub(t *testing.T, multikey string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		did := strings.TrimPrefix(r.URL.Path, "/identifiers/")
+		if did != ebsiDID && did != otherEBSIDID {
+			http.NotFound(w, r)
+			return
+		}
+		method := map[string]any{
+			"id":         did + "#key-1",
+			"type":       "Multikey",
+			"controller": did,
+		}
+		if did == ebsiDID {
+			method["publicKeyMultibase"] = multikey
+		}
+		w.Header().Set("Content-Type", didresolver.ContentTypeDIDLDJSON)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"@context":           []any{didresolver.ContextDIDv1},
+			"id":                 did,
+			"verificationMethod": []any{method},
+			"assertionMethod":    []any{did + "#key-1"},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveDIDActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewResolveDIDActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	multikey, err := didresolver.EncodeMultikey(edPub)
+	require.NoError(t, err)
+	registry := newEBSIRegistryStub(t, multikey)
+
+	t.Run("resolves a did:key", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveDIDActivityPayload{DID: "did:key:" + multikey},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, didresolver.MethodKey, output["method"])
+		document := output["didDocument"].(map[string]any)
+		require.Equal(t, "did:key:"+multikey, document["id"])
+		validation := output["validation"].(map[string]any)
+		require.Equal(t, true, validation["valid"])
+		methods := validation["verification_methods"].([]any)
+		require.Equal(t, didresolver.KeyTypeEd25519, methods[0].(map[string]any)["key_type"])
+	})
+
+	t.Run("resolves a did:ebsi against the configured registry", func(t *testing.T) {
+		t.Setenv(EBSIDIDRegistryURLEnv, registry.URL+"/identifiers")
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveDIDActivityPayload{DID: ebsiDID},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		metadata := output["didResolutionMetadata"].(map[string]any)
+		require.Equal(t, didresolver.ContentTypeDIDLDJSON, metadata["contentType"])
+		require.Equal(t, registry.URL+"/identifiers/"+ebsiDID, metadata["documentUrl"])
+	})
+
+	t.Run("rejects invalid documents", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveDIDActivityPayload{
+				DID:             otherEBSIDID,
+				EBSIRegistryURL: registry.URL + "/identifiers",
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.DIDDocumentInvalid].Code)
+		require.Contains(t, err.Error(), "it has no public key")
+	})
+
+	t.Run("reports DIDs that cannot be resolved", func(t *testing.T) {
+		for _, did := range []string{
+			"did:ebsi:zktk1AQHwChDhdLwQvnu4br",
+			"did:example:123",
+			"did:jwk:e30",
+		} {
+			_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+				Payload: ResolveDIDActivityPayload{
+					DID:             did,
+					EBSIRegistryURL: registry.URL + "/identifiers",
+				},
+			})
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				errorcodes.Codes[errorcodes.DIDResolutionFailed].Code,
+			)
+		}
+	})
+
+	t.Run("requires a DID", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveDIDActivityPayload{},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
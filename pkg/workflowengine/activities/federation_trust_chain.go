@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+// FederationTrustAnchorsEnv lists the entity IDs of the default trust anchors,
+// comma separated, used when a payload does not configure any.
+const FederationTrustAnchorsEnv = "FEDERATION_TRUST_ANCHORS"
+
+const federationResolveTimeout = 30 * time.Second
+
+// ResolveFederationTrustChainActivity builds and verifies the OpenID
+// Federation trust chain of an entity and returns its resolved metadata.
+type ResolveFederationTrustChainActivity struct {
+	workflowengine.BaseActivity
+}
+
+type ResolveFederationTrustChainActivityPayload struct {
+	EntityID           string                   `json:"entity_id"                      yaml:"entity_id"                      validate:"required"`
+	EntityType         string                   `json:"entity_type,omitempty"          yaml:"entity_type,omitempty"`
+	TrustAnchors       []federation.TrustAnchor `json:"trust_anchors,omitempty"        yaml:"trust_anchors,omitempty"`
+	RequiredTrustMarks []string                 `json:"required_trust_marks,omitempty" yaml:"required_trust_marks,omitempty"`
+	MaxPathLength      int                      `json:"max_path_length,omitempty"      yaml:"max_path_length,omitempty"`
+}
+
+func NewResolveFederationTrustChainActivity() *ResolveFederationTrustChainActivity {
+	return &ResolveFederationTrustChainActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Resolve and verify an OpenID Federation trust chain",
+		},
+	}
+}
+
+// Name returns the name of the ResolveFederationTrustChainActivity.
+func (a *ResolveFederationTrustChainActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute resolves the trust chain of the payload entity. The output holds the
+// resolved metadata, the trust anchor that was reached and the chain report.
+func (a *ResolveFederationTrustChainActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[ResolveFederationTrustChainActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	anchors := payload.TrustAnchors
+	if len(anchors) == 0 {
+		anchors = defaultFederationTrustAnchors()
+	}
+	if len(anchors) == 0 {
+		return result, a.NewMissingOrInvalidPayloadError(
+			errors.New("trust_anchors is required when FEDERATION_TRUST_ANCHORS is not set"),
+		)
+	}
+
+	resolver := newFederationResolver(anchors)
+	resolver.RequiredTrustMarks = payload.RequiredTrustMarks
+	if payload.MaxPathLength > 0 {
+		resolver.MaxPathLength = payload.MaxPathLength
+	}
+
+	entityType := payload.EntityType
+	if entityType == "" {
+		entityType = federation.EntityTypeCredentialIssuer
+	}
+
+	resolution, err := resolver.Resolve(ctx, payload.EntityID, entityType)
+	if err != nil {
+		return result, newTrustChainError(&a.BaseActivity, payload.EntityID, err)
+	}
+
+	report, err := chainReportMap(resolution.Report)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+
+	return workflowengine.ActivityResult{
+		Output: map[string]any{
+			"entity_id":    payload.EntityID,
+			"entity_type":  entityType,
+			"trust_anchor": resolution.Report.TrustAnchor,
+			"metadata":     resolution.Metadata,
+			"report":       report,
+		},
+	}, nil
+}
+
+// defaultFederationTrustAnchors reads the trust anchors configured for the
+// instance. Anchors configured this way are trusted by entity ID only.
+func defaultFederationTrustAnchors() []federation.TrustAnchor {
+	var anchors []federation.TrustAnchor
+	configured := utils.GetEnvironmentVariable(FederationTrustAnchorsEnv)
+	for _, entityID := range strings.Split(configured, ",") {
+		entityID = strings.TrimRight(strings.TrimSpace(entityID), "/")
+		if entityID != "" {
+			anchors = append(anchors, federation.TrustAnchor{EntityID: entityID})
+		}
+	}
+	return anchors
+}
+
+func newFederationResolver(anchors []federation.TrustAnchor) *federation.Resolver {
+	resolver := federation.NewResolver(anchors)
+	resolver.HTTPClient = &http.Client{
+		Timeout:   federationResolveTimeout,
+		Transport: tracing.HTTPTransport(nil),
+	}
+	return resolver
+}
+
+func newTrustChainError(
+	a *workflowengine.BaseActivity,
+	entityID string,
+	err error,
+) error {
+	errCode := errorcodes.Codes[errorcodes.FederationTrustChainFailed]
+	details := map[string]any{"entity_id": entityID}
+	var resolutionErr *federation.ResolutionError
+	if errors.As(err, &resolutionErr) {
+		details["failures"] = resolutionErr.Failures
+	}
+	return a.NewActivityError(workflowengine.ActivityError{
+		Code:    errCode.Code,
+		Summary: errCode.Description,
+		Message: err.Error(),
+		Details: details,
+	})
+}
+
+func chainReportMap(report federation.ChainReport) (map[string]any, error) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
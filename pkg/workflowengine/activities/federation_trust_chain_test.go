@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/federation"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// newTrustChainServer serves a two level federation: /anchor is the trust
+// anchor and /issuer a credential issuer subordinate to it. The anchor
+// restricts the issuer's signing algorithms to ES256.
+func newTrustChainServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	anchorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := func(kid string, key *ecdsa.PrivateKey) map[string]any {
+		jwk, err := federation.NewJWK(kid, &key.PublicKey)
+		require.NoError(t, err)
+		return map[string]any{"keys": []any{jwk}}
+	}
+	sign := func(kid string, key *ecdsa.PrivateKey, claims jwt.MapClaims) string {
+		claims["iat"] = time.Now().Unix()
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["typ"] = federation.EntityStatementType
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anchorID := server.URL + "/anchor"
+		issuerID := server.URL + "/issuer"
+
+		var statement string
+		switch r.URL.Path {
+		case "/anchor/.well-known/openid-federation":
+			statement = sign("anchor", anchorKey, jwt.MapClaims{
+				"iss":  anchorID,
+				"sub":  anchorID,
+				"jwks": jwks("anchor", anchorKey),
+				"metadata": map[string]any{
+					"federation_entity": map[string]any{
+						"federation_fetch_endpoint": anchorID + "/fetch",
+					},
+				},
+			})
+		case "/anchor/fetch":
+			if r.URL.Query().Get("sub") != issuerID {
+				http.NotFound(w, r)
+				return
+			}
+			statement = sign("anchor", anchorKey, jwt.MapClaims{
+				"iss":  anchorID,
+				"sub":  issuerID,
+				"jwks": jwks("issuer", issuerKey),
+				"metadata_policy": map[string]any{
+					"openid_credential_issuer": map[string]any{
+						"credential_signing_alg_values_supported": map[string]any{
+							"subset_of": []any{"ES256"},
+						},
+					},
+				},
+			})
+		case "/issuer/.well-known/openid-federation":
+			statement = sign("issuer", issuerKey, jwt.MapClaims{
+				"iss":             issuerID,
+				"sub":             issuerID,
+				"jwks":            jwks("issuer", issuerKey),
+				"authority_hints": []string{anchorID},
+				"metadata": map[string]any{
+					"openid_credential_issuer": map[string]any{
+						"credential_issuer": issuerID,
+						"credential_signing_alg_values_supported": []any{
+							"ES256",
+							"RS256",
+						},
+					},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/entity-statement+jwt")
+		_, _ = w.Write([]byte(statement))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveFederationTrustChainActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewResolveFederationTrustChainActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	server := newTrustChainServer(t)
+
+	t.Run("resolves metadata through the trust anchor", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveFederationTrustChainActivityPayload{
+				EntityID: server.URL + "/issuer",
+				TrustAnchors: []federation.TrustAnchor{
+					{EntityID: server.URL + "/anchor"},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, server.URL+"/anchor", output["trust_anchor"])
+		require.Equal(t, federation.EntityTypeCredentialIssuer, output["entity_type"])
+		metadata := output["metadata"].(map[string]any)
+		require.Equal(
+			t,
+			[]any{"ES256"},
+			metadata["credential_signing_alg_values_supported"],
+		)
+		report := output["report"].(map[string]any)
+		require.Len(t, report["statements"], 3)
+	})
+
+	t.Run("uses anchors from the environment", func(t *testing.T) {
+		t.Setenv(FederationTrustAnchorsEnv, " https://elsewhere.example , ")
+
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveFederationTrustChainActivityPayload{
+				EntityID: server.URL + "/issuer",
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.FederationTrustChainFailed].Code,
+		)
+		require.Contains(t, err.Error(), "no authority_hints")
+	})
+
+	t.Run("requires trust anchors", func(t *testing.T) {
+		t.Setenv(FederationTrustAnchorsEnv, "")
+
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ResolveFederationTrustChainActivityPayload{
+				EntityID: server.URL + "/issuer",
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
+
+func TestCheckCredentialsIssuerActivity_FederationTrustChain(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewCheckCredentialsIssuerActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	server := newTrustChainServer(t)
+
+	t.Run("returns policy-resolved metadata and the chain report", func(t *testing.T) {
+		t.Setenv(FederationTrustAnchorsEnv, server.URL+"/anchor")
+
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialsIssuerActivityPayload{
+				BaseURL: server.URL + "/issuer/.well-known/openid-federation",
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, ".well-known/openid-federation", output["source"])
+
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal([]byte(output["rawJSON"].(string)), &metadata))
+		require.Equal(
+			t,
+			[]any{"ES256"},
+			metadata["credential_signing_alg_values_supported"],
+		)
+		report := output["trust_chain"].(map[string]any)
+		require.Equal(t, server.URL+"/anchor", report["trust_anchor"])
+	})
+
+	t.Run("fails when the chain does not reach a trust anchor", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: CheckCredentialsIssuerActivityPayload{
+				BaseURL: server.URL + "/issuer",
+				TrustAnchors: []federation.TrustAnchor{
+					{EntityID: "https://elsewhere.example"},
+				},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.FederationTrustChainFailed].Code,
+		)
+	})
+}
+
+func TestFederationEntityID(t *testing.T) {
+	require.Equal(
+		t,
+		"https://issuer.example/tenant",
+		federationEntityID("https://issuer.example/tenant/.well-known/openid-federation"),
+	)
+	require.Equal(
+		t,
+		"https://issuer.example/organisation/1/service",
+		federationEntityID(
+			"https://issuer.example/.well-known/openid-federation/organisation/1/service",
+		),
+	)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/antchfx/htmlquery"
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"golang.org/x/net/html"
 )
@@ -181,7 +182,7 @@ func executeHTTPRequest(
 		Body:    payload.Body,
 	}
 
-	client := &http.Client{Timeout: timeout}
+	client := &http.Client{Timeout: timeout, Transport: tracing.HTTPTransport(nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		errCode := errorcodes.Codes[errorcodes.ExecuteHTTPRequestFailed]
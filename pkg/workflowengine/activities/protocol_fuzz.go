@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/protocolfuzz"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+const protocolFuzzRequestTimeout = 30 * time.Second
+
+// protocolFuzzTemplates are the StepCI templates with the baseline requests
+// of each target.
+var protocolFuzzTemplates = map[string]string{
+	protocolfuzz.TargetIssuer:   "pkg/workflowengine/workflows/openid4vci_issuer_config/stepci_issuer_fuzz_template_v1_0.yaml",
+	protocolfuzz.TargetVerifier: "pkg/workflowengine/workflows/openid4vp_verifier_config/stepci_verifier_fuzz_template_v1_0.yaml",
+}
+
+// ProtocolFuzzActivity sends the requests of an issuer or verifier StepCI
+// template mutated according to named profiles, and checks that each one is
+// rejected with a spec compliant error.
+type ProtocolFuzzActivity struct {
+	workflowengine.BaseActivity
+}
+
+// ProtocolFuzzActivityPayload takes a pre-authorized code credential offer
+// for an issuer, or an authorization request passed by reference for a
+// verifier. Without profiles every profile of the target runs.
+type ProtocolFuzzActivityPayload struct {
+	Target   string   `json:"target"             yaml:"target"             validate:"required,oneof=issuer verifier"`
+	Deeplink string   `json:"deeplink"           yaml:"deeplink"           validate:"required"`
+	Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	TxCode   string   `json:"tx_code,omitempty"  yaml:"tx_code,omitempty"`
+}
+
+func NewProtocolFuzzActivity() *ProtocolFuzzActivity {
+	return &ProtocolFuzzActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Send mutated protocol requests to an issuer or verifier",
+		},
+	}
+}
+
+// Name returns the name of the ProtocolFuzzActivity.
+func (a *ProtocolFuzzActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute runs the selected profiles. The output is a protocolfuzz.Report
+// encoded as a map, with one result per mutation. Any mutation the server
+// does not reject as expected fails the step with the report as details.
+// Both failures are non retryable, since the baseline requests redeem
+// single use codes.
+func (a *ProtocolFuzzActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[ProtocolFuzzActivityPayload](input.Payload)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	profiles, err := protocolfuzz.Profiles(payload.Target, payload.Profiles)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	client := &http.Client{
+		Timeout:   protocolFuzzRequestTimeout,
+		Transport: tracing.HTTPTransport(nil),
+	}
+	var data map[string]any
+	if payload.Target == protocolfuzz.TargetIssuer {
+		data, err = protocolfuzz.IssuerData(ctx, client, payload.Deeplink, payload.TxCode)
+	} else {
+		data, err = protocolfuzz.VerifierData(payload.Deeplink)
+	}
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	source, err := os.ReadFile(protocolFuzzTemplates[payload.Target])
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ReadFromReaderFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	rendered, err := RenderYAML(string(source), data)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.TemplateRenderFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	template, err := protocolfuzz.ParseTemplate(rendered)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.TemplateRenderFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+
+	runner := &protocolfuzz.Runner{HTTPClient: client}
+	report, runErr := runner.Run(ctx, template, profiles)
+	if runErr != nil && ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	output, err := protocolFuzzOutputMap(payload.Target, report)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	if runErr != nil {
+		var setupErr *protocolfuzz.SetupError
+		if errors.As(runErr, &setupErr) {
+			output["step"] = setupErr.Step
+		}
+		errCode := errorcodes.Codes[errorcodes.ProtocolFuzzSetupFailed]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: runErr.Error(),
+			Details: output,
+		})
+	}
+	if !report.Passed {
+		first := protocolFuzzFirstFailure(report)
+		errCode := errorcodes.Codes[errorcodes.ProtocolFuzzFailed]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: fmt.Sprintf(
+				"%d of %d mutated requests were not rejected as expected: %s/%s: %s",
+				report.Failed,
+				report.Total,
+				first.Profile,
+				first.Mutation,
+				first.Reason,
+			),
+			Details: output,
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+func protocolFuzzFirstFailure(report *protocolfuzz.Report) protocolfuzz.Result {
+	for _, result := range report.Results {
+		if !result.Passed {
+			return result
+		}
+	}
+	return protocolfuzz.Result{}
+}
+
+func protocolFuzzOutputMap(target string, report *protocolfuzz.Report) (map[string]any, error) {
+	out := map[string]any{}
+	if report != nil {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &out); err != nil {
+			return nil, err
+		}
+	}
+	out["target"] = target
+	return out, nil
+}
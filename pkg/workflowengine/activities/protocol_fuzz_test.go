@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/protocolfuzz"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// useRepositoryFuzzTemplates points the activity at the templates from the
+// package directory, as the workers run from the repository root.
+func useRepositoryFuzzTemplates(t *testing.T) {
+	t.Helper()
+	original := protocolFuzzTemplates
+	protocolFuzzTemplates = map[string]string{}
+	for target, path := range original {
+		protocolFuzzTemplates[target] = filepath.Join("..", "..", "..", path)
+	}
+	t.Cleanup(func() { protocolFuzzTemplates = original })
+}
+
+func newFuzzVerifier(t *testing.T, strict bool) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /request.jwt", func(w http.ResponseWriter, _ *http.Request) {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"response_uri": server.URL + "/response",
+			"state":        "state",
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		_, _ = w.Write([]byte(signed))
+	})
+	mux.HandleFunc("POST /response", func(w http.ResponseWriter, r *http.Request) {
+		if !strict {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+		var token map[string]any
+		if r.ParseForm() != nil || r.PostForm.Get("state") != "state" ||
+			json.Unmarshal([]byte(r.PostForm.Get("vp_token")), &token) != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_request"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func verifierDeeplink(server *httptest.Server) string {
+	return "openid4vp://?client_id=verifier&request_uri=" +
+		url.QueryEscape(server.URL+"/request.jwt")
+}
+
+func TestProtocolFuzzActivity(t *testing.T) {
+	useRepositoryFuzzTemplates(t)
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	act := NewProtocolFuzzActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+
+	t.Run("reports a sub-result per mutation", func(t *testing.T) {
+		server := newFuzzVerifier(t, true)
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ProtocolFuzzActivityPayload{
+				Target:   protocolfuzz.TargetVerifier,
+				Deeplink: verifierDeeplink(server),
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, true, output["passed"])
+		require.Equal(t, "verifier", output["target"])
+		require.Len(t, output["results"], 4)
+	})
+
+	t.Run("fails when a mutation is accepted", func(t *testing.T) {
+		server := newFuzzVerifier(t, false)
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ProtocolFuzzActivityPayload{
+				Target:   protocolfuzz.TargetVerifier,
+				Deeplink: verifierDeeplink(server),
+				Profiles: []string{"wrong-state"},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.ProtocolFuzzFailed].Code)
+		require.Contains(t, err.Error(), "wrong-state/unknown-state")
+	})
+
+	t.Run("fails when the baseline cannot be reached", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		offer := `{"credential_issuer":"` + server.URL + `",` +
+			`"credential_configuration_ids":["pid_sd_jwt"],` +
+			`"grants":{"urn:ietf:params:oauth:grant-type:pre-authorized_code":` +
+			`{"pre-authorized_code":"code"}}}`
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ProtocolFuzzActivityPayload{
+				Target:   protocolfuzz.TargetIssuer,
+				Deeplink: "openid-credential-offer://?credential_offer=" + url.QueryEscape(offer),
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.ProtocolFuzzSetupFailed].Code)
+		require.Contains(t, err.Error(), "issuer_metadata")
+	})
+
+	t.Run("rejects unknown profiles", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ProtocolFuzzActivityPayload{
+				Target:   protocolfuzz.TargetVerifier,
+				Deeplink: "openid4vp://?client_id=verifier&request_uri=https%3A%2F%2Fv.example",
+				Profiles: []string{"replayed-dpop"},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
+
+func TestProtocolFuzzIssuerTemplate(t *testing.T) {
+	source, err := os.ReadFile(
+		filepath.Join("..", "..", "..", protocolFuzzTemplates[protocolfuzz.TargetIssuer]),
+	)
+	require.NoError(t, err)
+	rendered, err := RenderYAML(string(source), map[string]any{
+		"credential_issuer":           "https://issuer.example",
+		"credential_configuration_id": "pid_sd_jwt",
+		"pre_authorized_code":         `code "with" quotes`,
+		"tx_code":                     "1234",
+	})
+	require.NoError(t, err)
+	template, err := protocolfuzz.ParseTemplate(rendered)
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, step := range template.Steps {
+		ids = append(ids, step.ID)
+	}
+	require.Equal(t, []string{
+		"issuer_metadata",
+		"authorization_server_metadata",
+		"token",
+		"nonce",
+		"credential",
+	}, ids)
+	require.Equal(t, `code "with" quotes`, template.Steps[2].HTTP.Form["pre-authorized_code"])
+	require.Equal(t, "1234", template.Steps[2].HTTP.Form["tx_code"])
+	require.Equal(t, map[string]any{
+		"credential_configuration_id": "pid_sd_jwt",
+		"proofs":                      map[string]any{"jwt": []any{"${{ fuzz.proof }}"}},
+	}, template.Steps[4].HTTP.JSON)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
 	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
@@ -185,7 +186,6 @@ func (a *StartQueuedPipelineActivity) Execute(
 	if namespace, ok := config["namespace"].(string); !ok || namespace == "" {
 		config["namespace"] = payload.OwnerNamespace
 	}
-
 	appURL, ok := config["app_url"].(string)
 	if !ok || strings.TrimSpace(appURL) == "" {
 		errCode := errorcodes.Codes[errorcodes.MissingOrInvalidPayload]
@@ -229,6 +229,8 @@ func (a *StartQueuedPipelineActivity) Execute(
 	}
 	config["disable_android_play_store"] = workflowDef.Runtime.DisableAndroidPlayStore
 	applySemaphoreTicketMetadata(config, payload)
+	config[quota.ReservedRunConfigKey] = payload.OwnerNamespace
+	pipeline.SetStepOutcomeConfig(config, payload.PipelineIdentifier, payload.YAML)
 
 	memo["test"] = workflowDef.Name
 	options := prepareQueuedWorkflowOptions(workflowDef.Runtime)
@@ -274,6 +276,29 @@ func (a *StartQueuedPipelineActivity) Execute(
 		)
 	}
 
+	httpDoer := a.httpDoer
+	if httpDoer == nil {
+		httpDoer = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	err = ReserveOrganizationRun(
+		ctx,
+		httpDoer,
+		appURL,
+		payload.OwnerNamespace,
+		options.Options.ID,
+	)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.PipelineExecutionError]
+		return result, a.NewActivityError(
+			workflowengine.ActivityError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: err.Error(),
+			},
+		)
+	}
+
 	workflowInput := map[string]any{
 		"workflow_definition": workflowDefMap,
 		"workflow_input": workflowengine.WorkflowInput{
@@ -290,6 +315,22 @@ func (a *StartQueuedPipelineActivity) Execute(
 		workflowInput,
 	)
 	if err != nil {
+		releaseErr := ReleaseOrganizationRun(
+			ctx,
+			httpDoer,
+			appURL,
+			payload.OwnerNamespace,
+			options.Options.ID,
+		)
+		if releaseErr != nil && activity.IsActivity(ctx) {
+			activity.GetLogger(ctx).Warn(
+				"failed to release organization run",
+				"workflow_id",
+				options.Options.ID,
+				"error",
+				releaseErr,
+			)
+		}
 		errCode := errorcodes.Codes[errorcodes.PipelineExecutionError]
 		return result, a.NewActivityError(
 			workflowengine.ActivityError{
@@ -311,11 +352,6 @@ func (a *StartQueuedPipelineActivity) Execute(
 	}
 	result.Output = output
 
-	httpDoer := a.httpDoer
-	if httpDoer == nil {
-		httpDoer = &http.Client{Timeout: 15 * time.Second}
-	}
-
 	if err := createPipelineExecutionResultWithRetry(
 		ctx,
 		httpDoer,
@@ -325,6 +361,7 @@ func (a *StartQueuedPipelineActivity) Execute(
 		workflowID,
 		runID,
 		pipelineRunTypeFromMemo(memo),
+		payload.TicketID,
 	); err != nil {
 		if activity.IsActivity(ctx) {
 			logger := activity.GetLogger(ctx)
@@ -463,6 +500,7 @@ func createPipelineExecutionResultWithRetry(
 	workflowID string,
 	runID string,
 	runType string,
+	ticketID string,
 ) error {
 	backoffs := []time.Duration{
 		250 * time.Millisecond,
@@ -481,6 +519,7 @@ func createPipelineExecutionResultWithRetry(
 			workflowID,
 			runID,
 			runType,
+			ticketID,
 		)
 		if err == nil {
 			return nil
@@ -507,6 +546,7 @@ func postPipelineExecutionResult(
 	workflowID string,
 	runID string,
 	runType string,
+	ticketID string,
 ) (int, error) {
 	payload := map[string]any{
 		"owner":       ownerNamespace,
@@ -514,6 +554,7 @@ func postPipelineExecutionResult(
 		"workflow_id": workflowID,
 		"run_id":      runID,
 		"type":        runType,
+		"ticket_id":   ticketID,
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -548,6 +589,9 @@ func postPipelineExecutionResult(
 	return resp.StatusCode, nil
 }
 
+// fetchOrganizationLimits asks the app for the current quota limits of the
+// organization owning ownerNamespace, so that runs started long after they
+// were queued or scheduled follow limit changes.
 func pipelineRunTypeFromMemo(memo map[string]any) string {
 	if memo != nil {
 		if value, ok := memo[pipeline.RunTypeMemoKey].(string); ok && pipeline.ValidRunType(value) {
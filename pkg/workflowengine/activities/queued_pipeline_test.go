@@ -4,6 +4,7 @@
 package activities
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/stretchr/testify/require"
 	"go.temporal.io/sdk/client"
@@ -109,6 +111,42 @@ func (d *headerCaptureDoer) Do(req *http.Request) (*http.Response, error) {
 	}, nil
 }
 
+// quotaRunsDoer answers the organization concurrent runs reservations,
+// refusing them with a quota_exceeded error when exceeded is set, and
+// passes the other requests to next.
+type quotaRunsDoer struct {
+	exceeded *quota.ExceededError
+	next     httpDoer
+	requests []string
+}
+
+func (d *quotaRunsDoer) Do(req *http.Request) (*http.Response, error) {
+	if !strings.Contains(req.URL.Path, "/quota/runs") {
+		return d.next.Do(req)
+	}
+	d.requests = append(d.requests, req.Method+" "+req.URL.Path)
+	status := http.StatusOK
+	body := []byte("{}")
+	if req.Method == http.MethodPost && d.exceeded != nil {
+		status = http.StatusTooManyRequests
+		var err error
+		body, err = json.Marshal(map[string]any{
+			"status":  status,
+			"error":   "quota",
+			"reason":  "quota_exceeded",
+			"message": d.exceeded.Error(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
 func TestStartQueuedPipelineActivityNonFatalResultFailure(t *testing.T) {
 	t.Setenv("CREDIMI_INTERNAL_ADMIN_KEY", "test-internal-key")
 	act := NewStartQueuedPipelineActivity()
@@ -120,7 +158,7 @@ func TestStartQueuedPipelineActivityNonFatalResultFailure(t *testing.T) {
 			},
 		}, nil
 	}
-	act.httpDoer = failingDoer{err: errors.New("boom")}
+	act.httpDoer = &quotaRunsDoer{next: failingDoer{err: errors.New("boom")}}
 
 	result, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 		Payload: StartQueuedPipelineActivityInput{
@@ -167,7 +205,7 @@ func TestStartQueuedPipelineActivityRetriesPipelineResult(t *testing.T) {
 			},
 		}, nil
 	}
-	act.httpDoer = server.Client()
+	act.httpDoer = &quotaRunsDoer{next: server.Client()}
 
 	result, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 		Payload: StartQueuedPipelineActivityInput{
@@ -208,7 +246,7 @@ func TestStartQueuedPipelineActivityPostsRunType(t *testing.T) {
 			},
 		}, nil
 	}
-	act.httpDoer = server.Client()
+	act.httpDoer = &quotaRunsDoer{next: server.Client()}
 
 	_, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 		Payload: StartQueuedPipelineActivityInput{
@@ -228,6 +266,114 @@ func TestStartQueuedPipelineActivityPostsRunType(t *testing.T) {
 	require.Equal(t, pipelineinternal.RunTypeCI, posted["type"])
 }
 
+// TestStartQueuedPipelineActivityConcurrentRunsQuota verifies runner-backed
+// runs reserve one of the organization concurrent runs before they start,
+// like immediate ones.
+func TestStartQueuedPipelineActivityConcurrentRunsQuota(t *testing.T) {
+	t.Setenv("CREDIMI_INTERNAL_ADMIN_KEY", "test-internal-key")
+	start := func(
+		temporalClient temporalWorkflowStarter,
+		exceeded *quota.ExceededError,
+	) (*quotaRunsDoer, error) {
+		act := NewStartQueuedPipelineActivity()
+		act.temporalClientFactory = func(namespace string) (temporalWorkflowStarter, error) {
+			return temporalClient, nil
+		}
+		doer := &quotaRunsDoer{exceeded: exceeded, next: failingDoer{err: errors.New("boom")}}
+		act.httpDoer = doer
+		_, err := act.Execute(context.Background(), workflowengine.ActivityInput{
+			Payload: StartQueuedPipelineActivityInput{
+				TicketID:           "ticket-quota",
+				OwnerNamespace:     "tenant-quota",
+				RequiredRunnerIDs:  []string{"tenant-quota/runner"},
+				PipelineIdentifier: "tenant-quota/pipeline",
+				YAML:               "name: test\nsteps: []\n",
+				PipelineConfig:     map[string]any{"app_url": "https://example.com"},
+			},
+		})
+		return doer, err
+	}
+
+	temporalClient := &capturingTemporalClient{
+		run: fakeWorkflowRun{id: "wf-quota", runID: "run-quota"},
+	}
+	exceeded := &quota.ExceededError{Quota: quota.ConcurrentRuns, Limit: 2, Used: 2}
+	doer, err := start(temporalClient, exceeded)
+	require.ErrorContains(t, err, "organization concurrent_runs quota exceeded: 2 of 2 used")
+	require.Nil(t, temporalClient.lastArgs, "the pipeline must not start")
+	require.Len(t, doer.requests, 1)
+
+	doer, err = start(temporalClient, nil)
+	require.NoError(t, err)
+	require.Len(t, temporalClient.lastArgs, 1)
+	require.Equal(t, []string{
+		"POST /api/organizations/tenant-quota/quota/runs",
+	}, doer.requests)
+	input, ok := temporalClient.lastArgs[0].(map[string]any)
+	require.True(t, ok)
+	workflowInput, ok := input["workflow_input"].(workflowengine.WorkflowInput)
+	require.True(t, ok)
+	require.Equal(t, "tenant-quota", workflowInput.Config[quota.ReservedRunConfigKey])
+
+	doer, err = start(failingTemporalClient{err: errors.New("temporal down")}, nil)
+	require.ErrorContains(t, err, "temporal down")
+	require.Len(t, doer.requests, 2)
+	require.True(t, strings.HasPrefix(doer.requests[1], "DELETE /api/organizations/tenant-quota/quota/runs/Pipeline-"))
+}
+
+func TestReserveOrganizationRun(t *testing.T) {
+	t.Setenv("CREDIMI_INTERNAL_ADMIN_KEY", "test-internal-key")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "test-internal-key", r.Header.Get("Credimi-Api-Key"))
+		switch r.Method {
+		case http.MethodPost:
+			require.Equal(t, "/api/organizations/tenant-quota/quota/runs", r.URL.Path)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["workflow_id"] == "wf-over" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = io.WriteString(w, `{"status":429,"error":"quota","reason":"quota_exceeded",`+
+					`"message":"organization concurrent_runs quota exceeded: 3 of 3 used"}`)
+			}
+		case http.MethodDelete:
+			require.Equal(t, "/api/organizations/tenant-quota/quota/runs/wf-1", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	require.NoError(t, ReserveOrganizationRun(ctx, server.Client(), server.URL, "tenant-quota", "wf-1"))
+	require.NoError(t, ReleaseOrganizationRun(ctx, server.Client(), server.URL, "tenant-quota", "wf-1"))
+
+	err := ReserveOrganizationRun(ctx, server.Client(), server.URL, "tenant-quota", "wf-over")
+	var exceeded *quota.ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	require.Equal(t, &quota.ExceededError{Quota: quota.ConcurrentRuns, Limit: 3, Used: 3}, exceeded)
+
+	err = ReserveOrganizationRun(
+		ctx,
+		failingDoer{err: errors.New("unreachable")},
+		server.URL,
+		"tenant-quota",
+		"wf-1",
+	)
+	require.ErrorContains(t, err, "unreachable")
+}
+
+// failingTemporalClient refuses to start workflows.
+type failingTemporalClient struct {
+	err error
+}
+
+func (f failingTemporalClient) ExecuteWorkflow(
+	ctx context.Context,
+	options client.StartWorkflowOptions,
+	workflow interface{},
+	args ...interface{},
+) (client.WorkflowRun, error) {
+	return nil, f.err
+}
+
 // TestStartQueuedPipelineActivityWorkflowIDPrefix verifies scheduled tickets get a distinct ID prefix.
 func TestStartQueuedPipelineActivityWorkflowIDPrefix(t *testing.T) {
 	t.Setenv("CREDIMI_INTERNAL_ADMIN_KEY", "test-internal-key")
@@ -262,7 +408,7 @@ func TestStartQueuedPipelineActivityWorkflowIDPrefix(t *testing.T) {
 			act.temporalClientFactory = func(namespace string) (temporalWorkflowStarter, error) {
 				return captured, nil
 			}
-			act.httpDoer = failingDoer{err: errors.New("boom")}
+			act.httpDoer = &quotaRunsDoer{next: failingDoer{err: errors.New("boom")}}
 
 			_, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 				Payload: StartQueuedPipelineActivityInput{
@@ -302,7 +448,7 @@ func TestStartQueuedPipelineActivityPropagatesDisableAndroidPlayStore(t *testing
 	act.temporalClientFactory = func(namespace string) (temporalWorkflowStarter, error) {
 		return captured, nil
 	}
-	act.httpDoer = failingDoer{err: errors.New("boom")}
+	act.httpDoer = &quotaRunsDoer{next: failingDoer{err: errors.New("boom")}}
 
 	_, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 		Payload: StartQueuedPipelineActivityInput{
@@ -342,7 +488,7 @@ func TestStartQueuedPipelineActivitySkipsReservedYAMLConfig(t *testing.T) {
 	act.temporalClientFactory = func(namespace string) (temporalWorkflowStarter, error) {
 		return captured, nil
 	}
-	act.httpDoer = failingDoer{err: errors.New("boom")}
+	act.httpDoer = &quotaRunsDoer{next: failingDoer{err: errors.New("boom")}}
 
 	_, err := act.Execute(context.Background(), workflowengine.ActivityInput{
 		Payload: StartQueuedPipelineActivityInput{
@@ -396,6 +542,7 @@ func TestCreatePipelineExecutionResultWithRetryAttempts(t *testing.T) {
 		"wf-1",
 		"run-1",
 		pipelineinternal.RunTypeManual,
+		"ticket-1",
 	)
 
 	require.Error(t, err)
@@ -415,11 +562,15 @@ func TestPostPipelineExecutionResultAddsInternalAPIKeyHeader(t *testing.T) {
 		"wf-1",
 		"run-1",
 		pipelineinternal.RunTypeManual,
+		"ticket-1",
 	)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, status)
 	require.NotNil(t, doer.lastRequest)
 	require.Equal(t, "internal-key", doer.lastRequest.Header.Get("Credimi-Api-Key"))
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(doer.lastRequest.Body).Decode(&body))
+	require.Equal(t, "ticket-1", body["ticket_id"])
 }
 
 func TestPostPipelineExecutionResultMissingInternalAPIKey(t *testing.T) {
@@ -435,6 +586,7 @@ func TestPostPipelineExecutionResultMissingInternalAPIKey(t *testing.T) {
 		"wf-1",
 		"run-1",
 		pipelineinternal.RunTypeManual,
+		"ticket-1",
 	)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "CREDIMI_INTERNAL_ADMIN_KEY is required")
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/forkbombeu/credimi/pkg/utils"
+)
+
+// OrganizationRunsURL is the app endpoint holding the concurrent runs
+// reserved by the organization owning ownerNamespace.
+func OrganizationRunsURL(appURL, ownerNamespace string) string {
+	return utils.JoinURL(appURL, "api", "organizations", ownerNamespace, "quota", "runs")
+}
+
+// ReserveOrganizationRun reserves one of the concurrent runs of the
+// organization owning ownerNamespace for the run of workflowID, before the
+// run starts. It fails with a *quota.ExceededError when the organization
+// quota leaves no room for it.
+func ReserveOrganizationRun(
+	ctx context.Context,
+	doer httpDoer,
+	appURL string,
+	ownerNamespace string,
+	workflowID string,
+) error {
+	body, err := json.Marshal(map[string]string{"workflow_id": workflowID})
+	if err != nil {
+		return fmt.Errorf("encode organization run: %w", err)
+	}
+	resp, err := doOrganizationRunsRequest(
+		ctx,
+		doer,
+		http.MethodPost,
+		OrganizationRunsURL(appURL, ownerNamespace),
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("reserve organization run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil {
+			if exceeded := quota.ParseExceededError(apiErr.Message); exceeded != nil {
+				return exceeded
+			}
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("reserve organization run status: %s", resp.Status)
+	}
+	return nil
+}
+
+// ReleaseOrganizationRun gives back the concurrent run reserved for the run
+// of workflowID.
+func ReleaseOrganizationRun(
+	ctx context.Context,
+	doer httpDoer,
+	appURL string,
+	ownerNamespace string,
+	workflowID string,
+) error {
+	resp, err := doOrganizationRunsRequest(
+		ctx,
+		doer,
+		http.MethodDelete,
+		utils.JoinURL(OrganizationRunsURL(appURL, ownerNamespace), workflowID),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("release organization run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("release organization run status: %s", resp.Status)
+	}
+	return nil
+}
+
+func doOrganizationRunsRequest(
+	ctx context.Context,
+	doer httpDoer,
+	method string,
+	url string,
+	body []byte,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	internalKey := strings.TrimSpace(os.Getenv("CREDIMI_INTERNAL_ADMIN_KEY"))
+	if internalKey == "" {
+		return nil, fmt.Errorf("CREDIMI_INTERNAL_ADMIN_KEY is required")
+	}
+	req.Header.Set("Credimi-Api-Key", internalKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doer.Do(req)
+}
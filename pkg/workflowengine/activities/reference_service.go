@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+// referenceKeys derives the reference service keys once per worker.
+var referenceKeys = sync.OnceValues(reference.LoadKeys)
+
+// referenceNow is the clock of the reference activities.
+var referenceNow = time.Now
+
+// SignReferenceRequestActivity signs the OpenID4VP request object of a
+// reference verifier session.
+type SignReferenceRequestActivity struct {
+	workflowengine.BaseActivity
+}
+
+// SignReferenceRequestActivityPayload is a verifier session whose client
+// identifier and request object are not set yet.
+type SignReferenceRequestActivityPayload struct {
+	Session reference.VerifierSession `json:"session" validate:"required"`
+}
+
+// SignReferenceRequestActivityOutput is the signed request object.
+type SignReferenceRequestActivityOutput struct {
+	ClientID      string `json:"client_id"`
+	RequestObject string `json:"request_object"`
+}
+
+func NewSignReferenceRequestActivity() *SignReferenceRequestActivity {
+	return &SignReferenceRequestActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Sign a reference verifier request",
+		},
+	}
+}
+
+// Name returns the name of the SignReferenceRequestActivity.
+func (a *SignReferenceRequestActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute derives the client identifier of the session and signs its
+// request object with the reference verifier key.
+func (a *SignReferenceRequestActivity) Execute(
+	_ context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+	payload, err := workflowengine.DecodePayload[SignReferenceRequestActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	keys, err := referenceKeys()
+	if err != nil {
+		return result, a.referenceServiceError(err)
+	}
+
+	session := payload.Session
+	session.ClientID, err = keys.ClientID(session.VerifierURL, session.Config.ClientIDScheme)
+	if err != nil {
+		return result, a.referenceServiceError(err)
+	}
+	requestObject, err := keys.SignRequest(session, referenceNow())
+	if err != nil {
+		return result, a.referenceServiceError(err)
+	}
+	return workflowengine.ActivityResult{Output: SignReferenceRequestActivityOutput{
+		ClientID:      session.ClientID,
+		RequestObject: requestObject,
+	}}, nil
+}
+
+func (a *SignReferenceRequestActivity) referenceServiceError(err error) error {
+	errCode := errorcodes.Codes[errorcodes.ReferenceServiceFailed]
+	return a.NewNonRetryableActivityError(workflowengine.ActivityError{
+		Code:    errCode.Code,
+		Summary: errCode.Description,
+		Message: err.Error(),
+	})
+}
+
+// IssueReferenceCredentialActivity verifies the key proofs of a credential
+// request to a reference issuer session and signs the credentials.
+type IssueReferenceCredentialActivity struct {
+	workflowengine.BaseActivity
+}
+
+// IssueReferenceCredentialActivityPayload holds the issuer session and the
+// jwt proofs of the credential request.
+type IssueReferenceCredentialActivityPayload struct {
+	Session reference.IssuerSession `json:"session" validate:"required"`
+	Proofs  []string                `json:"proofs"  validate:"required,min=1"`
+}
+
+// IssueReferenceCredentialActivityOutput carries either the issued
+// credentials or the error to answer the wallet with.
+type IssueReferenceCredentialActivityOutput struct {
+	Credentials []string                 `json:"credentials,omitempty"`
+	Error       *reference.ProtocolError `json:"error,omitempty"`
+}
+
+func NewIssueReferenceCredentialActivity() *IssueReferenceCredentialActivity {
+	return &IssueReferenceCredentialActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Issue a reference credential",
+		},
+	}
+}
+
+// Name returns the name of the IssueReferenceCredentialActivity.
+func (a *IssueReferenceCredentialActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute issues one credential per valid proof. Invalid proofs are a
+// protocol error of the wallet, reported in the output rather than failing
+// the activity.
+func (a *IssueReferenceCredentialActivity) Execute(
+	_ context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+	payload, err := workflowengine.DecodePayload[IssueReferenceCredentialActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	keys, err := referenceKeys()
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ReferenceServiceFailed]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+
+	credentials, protoErr := keys.IssueCredentials(
+		payload.Session,
+		payload.Proofs,
+		referenceNow(),
+	)
+	return workflowengine.ActivityResult{Output: IssueReferenceCredentialActivityOutput{
+		Credentials: credentials,
+		Error:       protoErr,
+	}}, nil
+}
+
+// VerifyReferencePresentationActivity verifies the vp_token posted to a
+// reference verifier session.
+type VerifyReferencePresentationActivity struct {
+	workflowengine.BaseActivity
+}
+
+// VerifyReferencePresentationActivityPayload holds the verifier session and
+// the posted vp_token.
+type VerifyReferencePresentationActivityPayload struct {
+	Session reference.VerifierSession `json:"session"  validate:"required"`
+	VPToken string                    `json:"vp_token" validate:"required"`
+}
+
+func NewVerifyReferencePresentationActivity() *VerifyReferencePresentationActivity {
+	return &VerifyReferencePresentationActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Verify a reference verifier presentation",
+		},
+	}
+}
+
+// Name returns the name of the VerifyReferencePresentationActivity.
+func (a *VerifyReferencePresentationActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute verifies the presentations against the DCQL query of the session
+// and returns a reference.VerificationResult. An invalid presentation is a
+// result, not an activity failure.
+func (a *VerifyReferencePresentationActivity) Execute(
+	_ context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+	payload, err := workflowengine.DecodePayload[VerifyReferencePresentationActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	keys, err := referenceKeys()
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ReferenceServiceFailed]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	return workflowengine.ActivityResult{
+		Output: keys.VerifyResponse(payload.Session, payload.VPToken, referenceNow()),
+	}, nil
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const (
+	testReferenceIssuerURL   = "https://credimi.example/api/reference/org/issuer/session1"
+	testReferenceVerifierURL = "https://credimi.example/api/reference/org/verifier/session1"
+)
+
+func stubReferenceKeys(t *testing.T, err error) {
+	t.Helper()
+	original := referenceKeys
+	referenceKeys = func() (*reference.Keys, error) {
+		if err != nil {
+			return nil, err
+		}
+		return reference.NewKeys("test-seed")
+	}
+	t.Cleanup(func() { referenceKeys = original })
+}
+
+func decodeOutput[T any](t *testing.T, result workflowengine.ActivityResult) T {
+	t.Helper()
+	output, err := workflowengine.DecodePayload[T](result.Output)
+	require.NoError(t, err)
+	return output
+}
+
+func TestSignReferenceRequestActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	act := NewSignReferenceRequestActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	session := reference.VerifierSession{
+		VerifierURL: testReferenceVerifierURL,
+		Config:      reference.VerifierConfig{Credentials: []string{reference.ConfigurationPID}},
+		Secrets:     reference.VerifierSecrets{Nonce: "nonce", State: "state"},
+	}
+
+	t.Run("signs the request object", func(t *testing.T) {
+		stubReferenceKeys(t, nil)
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: SignReferenceRequestActivityPayload{Session: session},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := decodeOutput[SignReferenceRequestActivityOutput](t, result)
+		require.Equal(t, "x509_san_dns:credimi.example", output.ClientID)
+		require.NotEmpty(t, output.RequestObject)
+	})
+
+	t.Run("fails when the keys cannot be loaded", func(t *testing.T) {
+		stubReferenceKeys(t, errors.New("bad seed"))
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: SignReferenceRequestActivityPayload{Session: session},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.ReferenceServiceFailed].Code)
+	})
+
+	t.Run("fails on a missing session", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: map[string]any{},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
+
+func TestIssueReferenceCredentialActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	act := NewIssueReferenceCredentialActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	stubReferenceKeys(t, nil)
+
+	holder, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	session := reference.IssuerSession{
+		IssuerURL: testReferenceIssuerURL,
+		Config:    reference.IssuerConfig{Configuration: reference.ConfigurationPID},
+		Secrets:   reference.IssuerSecrets{Nonce: "nonce"},
+	}
+
+	t.Run("issues a credential per proof", func(t *testing.T) {
+		proof, err := reference.SignProof(holder, testReferenceIssuerURL, "nonce", time.Now())
+		require.NoError(t, err)
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: IssueReferenceCredentialActivityPayload{
+				Session: session,
+				Proofs:  []string{proof},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := decodeOutput[IssueReferenceCredentialActivityOutput](t, result)
+		require.Nil(t, output.Error)
+		require.Len(t, output.Credentials, 1)
+	})
+
+	t.Run("reports invalid proofs as protocol errors", func(t *testing.T) {
+		proof, err := reference.SignProof(holder, testReferenceIssuerURL, "stale", time.Now())
+		require.NoError(t, err)
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: IssueReferenceCredentialActivityPayload{
+				Session: session,
+				Proofs:  []string{proof},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := decodeOutput[IssueReferenceCredentialActivityOutput](t, result)
+		require.Empty(t, output.Credentials)
+		require.NotNil(t, output.Error)
+		require.Equal(t, "invalid_proof", output.Error.Code)
+	})
+
+	t.Run("requires proofs", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: IssueReferenceCredentialActivityPayload{Session: session},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
+
+func TestVerifyReferencePresentationActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	act := NewVerifyReferencePresentationActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	stubReferenceKeys(t, nil)
+
+	session := reference.VerifierSession{
+		VerifierURL: testReferenceVerifierURL,
+		Config:      reference.VerifierConfig{Credentials: []string{reference.ConfigurationPID}},
+		Secrets:     reference.VerifierSecrets{Nonce: "nonce", State: "state"},
+		ClientID:    "x509_san_dns:credimi.example",
+	}
+
+	future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+		Payload: VerifyReferencePresentationActivityPayload{
+			Session: session,
+			VPToken: `{"pid_sd_jwt":["not-a-credential"]}`,
+		},
+	})
+	require.NoError(t, err)
+
+	var result workflowengine.ActivityResult
+	require.NoError(t, future.Get(&result))
+	output := decodeOutput[reference.VerificationResult](t, result)
+	require.False(t, output.Valid)
+	require.NotEmpty(t, output.Issues)
+}
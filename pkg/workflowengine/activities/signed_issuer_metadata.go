@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/signedmetadata"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+// SignedMetadataCAFileEnv points to a PEM bundle of extra roots trusted for
+// x5c chains of signed issuer metadata, on top of the system roots.
+const SignedMetadataCAFileEnv = "SIGNED_METADATA_CA_FILE"
+
+// VerifySignedIssuerMetadataActivity verifies the signed_metadata JWT of
+// credential issuer metadata and compares it with the unsigned parameters.
+type VerifySignedIssuerMetadataActivity struct {
+	workflowengine.BaseActivity
+}
+
+type VerifySignedIssuerMetadataActivityPayload struct {
+	Metadata         map[string]any `json:"metadata"                    yaml:"metadata"                    validate:"required"`
+	CredentialIssuer string         `json:"credential_issuer,omitempty" yaml:"credential_issuer,omitempty"`
+}
+
+func NewVerifySignedIssuerMetadataActivity() *VerifySignedIssuerMetadataActivity {
+	return &VerifySignedIssuerMetadataActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Verify signed credential issuer metadata",
+		},
+	}
+}
+
+// Name returns the name of the VerifySignedIssuerMetadataActivity.
+func (a *VerifySignedIssuerMetadataActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute verifies the signed metadata. A failed verification is not an
+// activity error: the output status is "invalid" and error explains why.
+func (a *VerifySignedIssuerMetadataActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[VerifySignedIssuerMetadataActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	verifier := signedmetadata.NewVerifier()
+	verifier.HTTPClient = &http.Client{
+		Timeout:   verifier.HTTPClient.Timeout,
+		Transport: tracing.HTTPTransport(nil),
+	}
+	roots, err := signedMetadataRoots()
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ReadFileFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	verifier.Roots = roots
+
+	verification := verifier.Verify(ctx, payload.Metadata, payload.CredentialIssuer)
+	encoded, err := json.Marshal(verification)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	output := map[string]any{}
+	if err := json.Unmarshal(encoded, &output); err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONUnmarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+// signedMetadataRoots returns nil, meaning the system roots, unless extra
+// roots are configured.
+func signedMetadataRoots() (*x509.CertPool, error) {
+	caFile := utils.GetEnvironmentVariable(SignedMetadataCAFileEnv)
+	if caFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.New(SignedMetadataCAFileEnv + " contains no PEM certificates")
+	}
+	return roots, nil
+}
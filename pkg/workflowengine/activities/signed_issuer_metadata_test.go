@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestVerifySignedIssuerMetadataActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewVerifySignedIssuerMetadataActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+
+	t.Run("absent signed metadata", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: VerifySignedIssuerMetadataActivityPayload{
+				Metadata: map[string]any{"credential_issuer": "https://issuer.example"},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, "absent", output["status"])
+		require.NotEmpty(t, output["checked_at"])
+	})
+
+	t.Run("invalid signed metadata is not an activity error", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: VerifySignedIssuerMetadataActivityPayload{
+				Metadata: map[string]any{
+					"credential_issuer": "https://issuer.example",
+					"signed_metadata":   "not-a-jwt",
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, "invalid", output["status"])
+		require.Contains(t, output["error"], "malformed signed_metadata")
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "roots.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("no certificates"), 0o600))
+		t.Setenv(SignedMetadataCAFileEnv, caFile)
+
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: VerifySignedIssuerMetadataActivityPayload{
+				Metadata: map[string]any{"signed_metadata": "a.b.c"},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.ReadFileFailed].Code)
+		require.Contains(t, err.Error(), "contains no PEM certificates")
+	})
+
+	t.Run("missing metadata", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: VerifySignedIssuerMetadataActivityPayload{},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+const trustedListFetchTimeout = 2 * time.Minute
+
+var verifyTrustedListSignature = trustedlist.VerifySignature
+
+// FetchTrustedListActivity downloads an ETSI TS 119 612 trusted list,
+// verifies its XAdES signature against pinned certificates and returns the
+// national lists it points to and its EUDI wallet ecosystem entities.
+type FetchTrustedListActivity struct {
+	workflowengine.BaseActivity
+}
+
+// FetchTrustedListActivityPayload pins the list signer with
+// TrustedCertificates, given as PEM or base64 DER.
+type FetchTrustedListActivityPayload struct {
+	URL                 string   `json:"url"                  yaml:"url"                  validate:"required"`
+	TrustedCertificates []string `json:"trusted_certificates" yaml:"trusted_certificates" validate:"required,min=1"`
+}
+
+func NewFetchTrustedListActivity() *FetchTrustedListActivity {
+	return &FetchTrustedListActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Fetch and verify an EU trusted list",
+		},
+	}
+}
+
+// Name returns the name of the FetchTrustedListActivity.
+func (a *FetchTrustedListActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute fetches the payload list. The output is a trustedlist.Summary
+// encoded as a map.
+func (a *FetchTrustedListActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[FetchTrustedListActivityPayload](input.Payload)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	trusted, err := trustedlist.ParseTrustedCertificates(payload.TrustedCertificates)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	client := &http.Client{
+		Timeout:   trustedListFetchTimeout,
+		Transport: tracing.HTTPTransport(nil),
+	}
+	data, err := trustedlist.Fetch(ctx, client, payload.URL)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ExecuteHTTPRequestFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+			Details: map[string]any{"url": payload.URL},
+		})
+	}
+
+	list, err := trustedlist.Parse(data)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.TrustedListParseFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+			Details: map[string]any{"url": payload.URL},
+		})
+	}
+	signature, err := verifyTrustedListSignature(data, trusted)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.TrustedListSignatureInvalid]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+			Details: map[string]any{"url": payload.URL, "territory": list.Territory},
+		})
+	}
+
+	output, err := trustedListSummaryMap(trustedlist.Summarize(payload.URL, list, signature))
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+func trustedListSummaryMap(summary trustedlist.Summary) (map[string]any, error) {
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const testTrustedListXML = `<?xml version="1.0" encoding="UTF-8"?>
+<TrustServiceStatusList xmlns="http://uri.etsi.org/02231/v2#">
+  <SchemeInformation>
+    <TSLSequenceNumber>3</TSLSequenceNumber>
+    <TSLType>http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUgeneric</TSLType>
+    <SchemeTerritory>DE</SchemeTerritory>
+  </SchemeInformation>
+  <TrustServiceProviderList>
+    <TrustServiceProvider>
+      <TSPInformation><TSPName><Name xml:lang="en">Federal PID Provider</Name></TSPName>
+      </TSPInformation>
+      <TSPServices><TSPService><ServiceInformation>
+        <ServiceTypeIdentifier>http://uri.etsi.org/TrstSvc/Svctype/PID</ServiceTypeIdentifier>
+        <ServiceName><Name xml:lang="en">PID</Name></ServiceName>
+        <ServiceStatus>http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/granted</ServiceStatus>
+        <ServiceSupplyPoints><ServiceSupplyPoint>https://pid.example.de</ServiceSupplyPoint>
+        </ServiceSupplyPoints>
+      </ServiceInformation></TSPService></TSPServices>
+    </TrustServiceProvider>
+  </TrustServiceProviderList>
+</TrustServiceStatusList>`
+
+func newTrustedListTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "DE trusted list signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate
+}
+
+func TestFetchTrustedListActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewFetchTrustedListActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tl.xml":
+			_, _ = w.Write([]byte(testTrustedListXML))
+		case "/broken.xml":
+			_, _ = w.Write([]byte("<html>maintenance</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	certificate := newTrustedListTestCertificate(t)
+	trusted := []string{base64.StdEncoding.EncodeToString(certificate.Raw)}
+
+	t.Run("returns the entities of a verified list", func(t *testing.T) {
+		original := verifyTrustedListSignature
+		t.Cleanup(func() { verifyTrustedListSignature = original })
+		verifyTrustedListSignature = func(
+			_ []byte,
+			pinned []*x509.Certificate,
+		) (*trustedlist.Signature, error) {
+			require.Len(t, pinned, 1)
+			require.Equal(t, certificate.Raw, pinned[0].Raw)
+			return &trustedlist.Signature{Signer: certificate}, nil
+		}
+
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{
+				URL:                 server.URL + "/tl.xml",
+				TrustedCertificates: trusted,
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, "DE", output["territory"])
+		require.Equal(t, float64(3), output["sequence_number"])
+		require.Equal(
+			t,
+			"CN=DE trusted list signer",
+			output["signer"].(map[string]any)["subject"],
+		)
+		entities := output["entities"].([]any)
+		require.Len(t, entities, 1)
+		entity := entities[0].(map[string]any)
+		require.Equal(t, string(trustedlist.RolePIDProvider), entity["role"])
+		require.Equal(t, "Federal PID Provider", entity["provider_name"])
+	})
+
+	t.Run("rejects unsigned lists", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{
+				URL:                 server.URL + "/tl.xml",
+				TrustedCertificates: trusted,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.TrustedListSignatureInvalid].Code,
+		)
+		require.Contains(t, err.Error(), "not signed")
+	})
+
+	t.Run("reports lists that cannot be parsed", func(t *testing.T) {
+		original := verifyTrustedListSignature
+		t.Cleanup(func() { verifyTrustedListSignature = original })
+		verifyTrustedListSignature = func(
+			[]byte,
+			[]*x509.Certificate,
+		) (*trustedlist.Signature, error) {
+			return nil, errors.New("not reached")
+		}
+
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{
+				URL:                 server.URL + "/broken.xml",
+				TrustedCertificates: trusted,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.TrustedListParseFailed].Code)
+	})
+
+	t.Run("reports download failures", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{
+				URL:                 server.URL + "/missing.xml",
+				TrustedCertificates: trusted,
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.ExecuteHTTPRequestFailed].Code)
+	})
+
+	t.Run("requires pinned certificates", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{URL: server.URL + "/tl.xml"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+
+	t.Run("rejects malformed certificates", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchTrustedListActivityPayload{
+				URL:                 server.URL + "/tl.xml",
+				TrustedCertificates: []string{"not-a-certificate"},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
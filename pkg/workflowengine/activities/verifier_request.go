@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+const verifierRequestFetchTimeout = 30 * time.Second
+
+// FetchVerifierRequestActivity fetches the OpenID4VP authorization request
+// of a verifier and validates its client identifier, client metadata,
+// credential query and response encryption.
+type FetchVerifierRequestActivity struct {
+	workflowengine.BaseActivity
+}
+
+// FetchVerifierRequestActivityPayload takes an authorization request URL,
+// such as an openid4vp:// link, or a verifier endpoint that returns one or
+// serves a request object.
+type FetchVerifierRequestActivityPayload struct {
+	URL string `json:"url" yaml:"url" validate:"required"`
+}
+
+// FetchVerifierRequestActivityOutput is the validation report of the
+// fetched request, which it carries under "request".
+type FetchVerifierRequestActivityOutput struct {
+	vprequest.Report
+	Request vprequest.Request `json:"request"`
+}
+
+func NewFetchVerifierRequestActivity() *FetchVerifierRequestActivity {
+	return &FetchVerifierRequestActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Fetch and validate an OpenID4VP verifier request",
+		},
+	}
+}
+
+// Name returns the name of the FetchVerifierRequestActivity.
+func (a *FetchVerifierRequestActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute fetches and validates the payload request. The output is a
+// FetchVerifierRequestActivityOutput encoded as a map, whose issues have the
+// shape of SchemaValidationIssue. A request with issues fails the
+// step with the output as details.
+func (a *FetchVerifierRequestActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[FetchVerifierRequestActivityPayload](
+		input.Payload,
+	)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+
+	fetcher := &vprequest.Fetcher{HTTPClient: &http.Client{
+		Timeout:   verifierRequestFetchTimeout,
+		Transport: tracing.HTTPTransport(nil),
+	}}
+	request, err := fetcher.Fetch(ctx, payload.URL)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.VerifierRequestFetchFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+			Details: map[string]any{"url": payload.URL},
+		})
+	}
+
+	report := vprequest.Validate(request)
+	issues := verifierRequestIssues(report.Issues)
+	output, err := verifierRequestOutputMap(
+		FetchVerifierRequestActivityOutput{Report: report, Request: *request},
+		issues,
+	)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	if len(issues) > 0 {
+		errCode := errorcodes.Codes[errorcodes.VerifierRequestInvalid]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: fmt.Sprintf(
+				"verifier request has %d issues: %s",
+				len(issues),
+				issues[0].Message,
+			),
+			Details: output,
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+func verifierRequestIssues(requestIssues []vprequest.Issue) []SchemaValidationIssue {
+	issues := make([]SchemaValidationIssue, 0, len(requestIssues))
+	for _, issue := range requestIssues {
+		issues = append(issues, SchemaValidationIssue{
+			Scope:   "verifier",
+			Field:   schemaValidationField(issue.Path),
+			Path:    issue.Path,
+			Message: issue.Message,
+		})
+	}
+	return issues
+}
+
+func verifierRequestOutputMap(
+	output FetchVerifierRequestActivityOutput,
+	issues []SchemaValidationIssue,
+) (map[string]any, error) {
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	encoded, err = json.Marshal(issues)
+	if err != nil {
+		return nil, err
+	}
+	var issueList []any
+	if err := json.Unmarshal(encoded, &issueList); err != nil {
+		return nil, err
+	}
+	out["issues"] = issueList
+	return out, nil
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// verifierRequestQuery is an OpenID4VP 1.0 request by value of a
+// pre-registered verifier.
+func verifierRequestQuery() url.Values {
+	query := url.Values{}
+	query.Set("client_id", "verifier.example")
+	query.Set("response_type", "vp_token")
+	query.Set("response_mode", "direct_post")
+	query.Set("response_uri", "https://verifier.example/response")
+	query.Set("nonce", "n-0S6_WzA2Mj")
+	query.Set(
+		"client_metadata",
+		`{"vp_formats_supported":{"dc+sd-jwt":{"sd-jwt_alg_values":["ES256"]}}}`,
+	)
+	query.Set("dcql_query", `{"credentials":[{"id":"pid","format":"dc+sd-jwt",`+
+		`"meta":{"vct_values":["urn:eudi:pid:1"]},"claims":[{"path":["given_name"]}]}]}`)
+	return query
+}
+
+func TestFetchVerifierRequestActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	act := NewFetchVerifierRequestActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := verifierRequestQuery()
+		if r.URL.Path == "/invalid" {
+			query.Del("nonce")
+		}
+		_, _ = w.Write([]byte("openid4vp://?" + query.Encode()))
+	}))
+	defer server.Close()
+
+	t.Run("validates the request of a verifier endpoint", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchVerifierRequestActivityPayload{URL: server.URL + "/start"},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, true, output["valid"])
+		require.Equal(t, vprequest.Version10, output["version"])
+		require.Equal(t, vprequest.SchemePreRegistered, output["client_id_scheme"])
+		require.Equal(t, []any{"dc+sd-jwt"}, output["formats"])
+		require.Equal(t, []any{"pid"}, output["credentials"])
+		require.Equal(t, []any{}, output["issues"])
+		request := output["request"].(map[string]any)
+		require.Equal(t, string(vprequest.SourceBody), request["source"])
+		require.Equal(t, server.URL+"/start", request["endpoint"])
+	})
+
+	t.Run("reports issues like schema validation", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchVerifierRequestActivityPayload{URL: server.URL + "/invalid"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.VerifierRequestInvalid].Code)
+		require.Equal(t, []SchemaValidationIssue{{
+			Scope:   "verifier",
+			Field:   "nonce",
+			Path:    []string{"nonce"},
+			Message: "nonce is required",
+		}}, schemaValidationIssuesFromError(t, err))
+	})
+
+	t.Run("reports fetch failures", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchVerifierRequestActivityPayload{URL: "ftp://verifier.example"},
+		})
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			errorcodes.Codes[errorcodes.VerifierRequestFetchFailed].Code,
+		)
+	})
+
+	t.Run("requires a url", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: FetchVerifierRequestActivityPayload{},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code)
+	})
+}
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/certchain"
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+)
+
+// X509TrustAnchorsFileEnv points to a PEM bundle of trust anchors for
+// certificate chains, added to those of the payload.
+const X509TrustAnchorsFileEnv = "X509_TRUST_ANCHORS_FILE"
+
+const x509RevocationTimeout = 30 * time.Second
+
+// ValidateX509ChainActivity validates the certificate chain an issuer or a
+// verifier presents, as used by the x509 client identifier schemes.
+type ValidateX509ChainActivity struct {
+	workflowengine.BaseActivity
+}
+
+// ValidateX509ChainActivityPayload takes the chain, leaf first, from one of
+// X5C, JWS (its x5c header) or PEM. Trust anchors are TrustAnchors, as PEM
+// or base64 DER, the certificates of TrustedListEntities, such as the
+// provenance of imported issuers and verifiers, and X509_TRUST_ANCHORS_FILE.
+// Without any, the system roots are used. Role is the scope of the issues.
+type ValidateX509ChainActivityPayload struct {
+	X5C                 []string             `json:"x5c,omitempty"                   yaml:"x5c,omitempty"`
+	JWS                 string               `json:"jws,omitempty"                   yaml:"jws,omitempty"`
+	PEM                 string               `json:"pem,omitempty"                   yaml:"pem,omitempty"`
+	Role                string               `json:"role,omitempty"                  yaml:"role,omitempty"                  validate:"omitempty,oneof=issuer verifier"`
+	ClientID            string               `json:"client_id,omitempty"             yaml:"client_id,omitempty"`
+	ClientIDScheme      string               `json:"client_id_scheme,omitempty"      yaml:"client_id_scheme,omitempty"      validate:"omitempty,oneof=x509_san_dns x509_san_uri x509_hash"`
+	TrustAnchors        []string             `json:"trust_anchors,omitempty"         yaml:"trust_anchors,omitempty"`
+	TrustedListEntities []trustedlist.Entity `json:"trusted_list_entities,omitempty" yaml:"trusted_list_entities,omitempty"`
+	UseSystemRoots      bool                 `json:"use_system_roots,omitempty"      yaml:"use_system_roots,omitempty"`
+	ExtKeyUsages        []string             `json:"ext_key_usages,omitempty"        yaml:"ext_key_usages,omitempty"`
+	SkipRevocation      bool                 `json:"skip_revocation,omitempty"       yaml:"skip_revocation,omitempty"`
+}
+
+func NewValidateX509ChainActivity() *ValidateX509ChainActivity {
+	return &ValidateX509ChainActivity{
+		BaseActivity: workflowengine.BaseActivity{
+			Name: "Validate an X.509 certificate chain",
+		},
+	}
+}
+
+// Name returns the name of the ValidateX509ChainActivity.
+func (a *ValidateX509ChainActivity) Name() string {
+	return a.BaseActivity.Name
+}
+
+// Execute validates the payload chain. The output is a certchain.Report
+// encoded as a map, whose issues have the shape of SchemaValidationIssue. A
+// chain with issues fails the step with the report as details.
+func (a *ValidateX509ChainActivity) Execute(
+	ctx context.Context,
+	input workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	result := workflowengine.ActivityResult{}
+
+	payload, err := workflowengine.DecodePayload[ValidateX509ChainActivityPayload](input.Payload)
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	source, err := payload.source()
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	roots, err := payload.trustAnchors()
+	if err != nil {
+		return result, a.NewMissingOrInvalidPayloadError(err)
+	}
+	configured, err := x509TrustAnchorsFromFile()
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ReadFileFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	roots = append(roots, configured...)
+
+	var report certchain.Report
+	chain, err := payload.chain()
+	if err != nil {
+		report = certchain.Report{
+			Chain:  []trustedlist.Certificate{},
+			Issues: []certchain.Issue{{Message: err.Error()}},
+		}
+	} else {
+		validator := &certchain.Validator{
+			Roots:           roots,
+			SystemRoots:     payload.UseSystemRoots,
+			CheckRevocation: !payload.SkipRevocation,
+			HTTPClient: &http.Client{
+				Timeout:   x509RevocationTimeout,
+				Transport: tracing.HTTPTransport(nil),
+			},
+		}
+		report = validator.Validate(ctx, chain, certchain.Options{
+			ClientID:       payload.ClientID,
+			ClientIDScheme: payload.ClientIDScheme,
+			ExtKeyUsages:   payload.ExtKeyUsages,
+		})
+	}
+
+	issues := x509ChainIssues(payload.scope(), source, report.Issues)
+	output, err := x509ChainReportMap(report, issues)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.JSONMarshalFailed]
+		return result, a.NewActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: err.Error(),
+		})
+	}
+	if len(issues) > 0 {
+		errCode := errorcodes.Codes[errorcodes.CertificateChainInvalid]
+		return result, a.NewNonRetryableActivityError(workflowengine.ActivityError{
+			Code:    errCode.Code,
+			Summary: errCode.Description,
+			Message: fmt.Sprintf(
+				"certificate chain has %d issues: %s",
+				len(issues),
+				issues[0].Message,
+			),
+			Details: output,
+		})
+	}
+	return workflowengine.ActivityResult{Output: output}, nil
+}
+
+// source names where the chain comes from, which prefixes the issue paths.
+func (p ValidateX509ChainActivityPayload) source() (string, error) {
+	var sources []string
+	if len(p.X5C) > 0 {
+		sources = append(sources, "x5c")
+	}
+	if p.JWS != "" {
+		sources = append(sources, "jws")
+	}
+	if p.PEM != "" {
+		sources = append(sources, "pem")
+	}
+	if len(sources) != 1 {
+		return "", errors.New("exactly one of x5c, jws and pem is required")
+	}
+	return sources[0], nil
+}
+
+func (p ValidateX509ChainActivityPayload) chain() ([]*x509.Certificate, error) {
+	switch {
+	case len(p.X5C) > 0:
+		return certchain.ParseX5C(p.X5C)
+	case p.JWS != "":
+		return certchain.ParseJWSX5C(p.JWS)
+	default:
+		return certchain.ParsePEM(p.PEM)
+	}
+}
+
+func (p ValidateX509ChainActivityPayload) trustAnchors() ([]*x509.Certificate, error) {
+	roots, err := trustedlist.ParseTrustedCertificates(p.TrustAnchors)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range p.TrustedListEntities {
+		for _, certificate := range entity.Certificates {
+			parsed, err := trustedlist.ParseCertificatesPEM([]byte(certificate.PEM))
+			if err != nil {
+				return nil, fmt.Errorf("trusted list entity %q: %w", entity.ServiceName, err)
+			}
+			roots = append(roots, parsed...)
+		}
+	}
+	return roots, nil
+}
+
+func (p ValidateX509ChainActivityPayload) scope() string {
+	if p.Role != "" {
+		return p.Role
+	}
+	return "certificate"
+}
+
+// x509TrustAnchorsFromFile reads the trust anchors configured for the
+// instance, if any.
+func x509TrustAnchorsFromFile() ([]*x509.Certificate, error) {
+	path := utils.GetEnvironmentVariable(X509TrustAnchorsFileEnv)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := trustedlist.ParseCertificatesPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", X509TrustAnchorsFileEnv, err)
+	}
+	return roots, nil
+}
+
+func x509ChainIssues(
+	scope, source string,
+	chainIssues []certchain.Issue,
+) []SchemaValidationIssue {
+	issues := make([]SchemaValidationIssue, 0, len(chainIssues))
+	for _, issue := range chainIssues {
+		path := append([]string{source}, issue.Path...)
+		issues = append(issues, SchemaValidationIssue{
+			Scope:   scope,
+			Field:   schemaValidationField(path),
+			Path:    path,
+			Message: issue.Message,
+		})
+	}
+	return issues
+}
+
+func x509ChainReportMap(
+	report certchain.Report,
+	issues []SchemaValidationIssue,
+) (map[string]any, error) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	encoded, err = json.Marshal(issues)
+	if err != nil {
+		return nil, err
+	}
+	var issueList []any
+	if err := json.Unmarshal(encoded, &issueList); err != nil {
+		return nil, err
+	}
+	out["issues"] = issueList
+	return out, nil
+}
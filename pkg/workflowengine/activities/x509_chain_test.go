@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package activities
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/certchain"
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// newX509TestChain returns a root CA and a leaf it issued to
+// verifier.example.
+func newX509TestChain(t *testing.T) (root, leaf *x509.Certificate) {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Access CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader,
+		rootTemplate,
+		rootTemplate,
+		&rootKey.PublicKey,
+		rootKey,
+	)
+	require.NoError(t, err)
+	root, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err = x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Relying Party"},
+		DNSNames:     []string{"verifier.example"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, root, &leafKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return root, leaf
+}
+
+func pemCertificate(certificate *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}))
+}
+
+func TestValidateX509ChainActivity(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	act := NewValidateX509ChainActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{
+		Name: act.Name(),
+	})
+	root, leaf := newX509TestChain(t)
+	x5c := []string{base64.StdEncoding.EncodeToString(leaf.Raw)}
+	t.Setenv(X509TrustAnchorsFileEnv, "")
+
+	t.Run("validates an x5c chain", func(t *testing.T) {
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{
+				X5C:          x5c,
+				ClientID:     "x509_san_dns:verifier.example",
+				TrustAnchors: []string{pemCertificate(root)},
+			},
+		})
+		require.NoError(t, err)
+
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		output := result.Output.(map[string]any)
+		require.Equal(t, true, output["valid"])
+		require.Equal(t, certchain.SchemeX509SanDNS, output["client_id_scheme"])
+		require.Equal(t, []any{}, output["issues"])
+		anchor := output["trust_anchor"].(map[string]any)
+		require.Equal(t, "CN=Access CA", anchor["subject"])
+	})
+
+	t.Run("trusts the certificates of trusted list entities", func(t *testing.T) {
+		// The provenance of an imported verifier.
+		provenance := map[string]any{
+			"source":       "eu_trusted_list",
+			"role":         trustedlist.RoleRelyingParty,
+			"service_name": "Access certificate authority",
+			"certificates": []trustedlist.Certificate{trustedlist.NewCertificate(root)},
+		}
+		encoded, err := json.Marshal(provenance)
+		require.NoError(t, err)
+		var entity trustedlist.Entity
+		require.NoError(t, json.Unmarshal(encoded, &entity))
+
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{
+				PEM:                 pemCertificate(leaf),
+				Role:                "verifier",
+				TrustedListEntities: []trustedlist.Entity{entity},
+			},
+		})
+		require.NoError(t, err)
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		require.Equal(t, true, result.Output.(map[string]any)["valid"])
+	})
+
+	t.Run("trusts the configured anchors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "anchors.pem")
+		require.NoError(t, os.WriteFile(path, []byte(pemCertificate(root)), 0o600))
+		t.Setenv(X509TrustAnchorsFileEnv, path)
+
+		future, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{X5C: x5c},
+		})
+		require.NoError(t, err)
+		var result workflowengine.ActivityResult
+		require.NoError(t, future.Get(&result))
+		require.Equal(t, true, result.Output.(map[string]any)["valid"])
+	})
+
+	t.Run("reports issues like schema validation", func(t *testing.T) {
+		header, err := json.Marshal(map[string]any{"alg": "ES256", "x5c": x5c})
+		require.NoError(t, err)
+		jws := base64.RawURLEncoding.EncodeToString(header) + ".e30.c2ln"
+
+		_, err = env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{
+				JWS:          jws,
+				Role:         "verifier",
+				ClientID:     "x509_hash:abc",
+				TrustAnchors: []string{base64.StdEncoding.EncodeToString(root.Raw)},
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.CertificateChainInvalid].Code)
+		require.Equal(t, []SchemaValidationIssue{{
+			Scope: "verifier",
+			Field: "jws.0.subjectAltName",
+			Path:  []string{"jws", "0", "subjectAltName"},
+			Message: `client_id "abc" does not match the leaf hash "` +
+				certchain.LeafHash(leaf) + `"`,
+		}}, schemaValidationIssuesFromError(t, err))
+	})
+
+	t.Run("reports untrusted chains", func(t *testing.T) {
+		otherRoot, _ := newX509TestChain(t)
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{
+				X5C:          x5c,
+				TrustAnchors: []string{pemCertificate(otherRoot)},
+			},
+		})
+		require.Error(t, err)
+		issues := schemaValidationIssuesFromError(t, err)
+		require.Len(t, issues, 1)
+		require.Equal(t, "certificate", issues[0].Scope)
+		require.Equal(t, "x5c.0.trust", issues[0].Field)
+	})
+
+	t.Run("reports chains that cannot be parsed", func(t *testing.T) {
+		_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+			Payload: ValidateX509ChainActivityPayload{X5C: []string{"AAAA"}},
+		})
+		require.Error(t, err)
+		issues := schemaValidationIssuesFromError(t, err)
+		require.Len(t, issues, 1)
+		require.Equal(t, "x5c", issues[0].Field)
+		require.Contains(t, issues[0].Message, "x5c[0]")
+	})
+
+	t.Run("requires a single chain", func(t *testing.T) {
+		for _, payload := range []ValidateX509ChainActivityPayload{
+			{},
+			{X5C: x5c, PEM: pemCertificate(leaf)},
+			{X5C: x5c, ClientIDScheme: "redirect_uri"},
+			{X5C: x5c, TrustAnchors: []string{"not a certificate"}},
+		} {
+			_, err := env.ExecuteActivity(act.Execute, workflowengine.ActivityInput{
+				Payload: payload,
+			})
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				errorcodes.Codes[errorcodes.MissingOrInvalidPayload].Code,
+			)
+		}
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
 	"github.com/forkbombeu/credimi/pkg/internal/temporalclient"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
@@ -30,6 +31,7 @@ import (
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
@@ -161,6 +163,7 @@ var OrgWorkers = []workerConfig{
 		},
 		Activities: []workflowengine.ExecutableActivity{
 			activities.NewCheckCredentialsIssuerActivity(),
+			activities.NewVerifySignedIssuerMetadataActivity(),
 			activities.NewJSONActivity(
 				map[string]reflect.Type{
 					"map": reflect.TypeOf(
@@ -231,6 +234,7 @@ var OrgWorkers = []workerConfig{
 			activities.NewHTTPActivity(),
 			activities.NewParseFidesCredentialIssuersActivity(),
 			activities.NewCheckCredentialsIssuerActivity(),
+			activities.NewVerifySignedIssuerMetadataActivity(),
 			activities.NewJSONActivity(
 				map[string]reflect.Type{
 					"map": reflect.TypeOf(
@@ -242,6 +246,46 @@ var OrgWorkers = []workerConfig{
 			activities.NewInternalHTTPActivity(),
 		},
 	},
+	{
+		TaskQueue: workflows.IssuerMetadataMonitorTaskQueue,
+		Workflows: []workflowengine.Workflow{
+			workflows.NewIssuerMetadataMonitorWorkflow(),
+		},
+		Activities: []workflowengine.ExecutableActivity{
+			activities.NewCheckCredentialsIssuerActivity(),
+			activities.NewVerifySignedIssuerMetadataActivity(),
+			activities.NewJSONActivity(
+				map[string]reflect.Type{
+					"map": reflect.TypeOf(
+						map[string]any{},
+					),
+				},
+			),
+			activities.NewSchemaValidationActivity(),
+			activities.NewInternalHTTPActivity(),
+			activities.NewSendMailActivity(),
+		},
+	},
+	{
+		TaskQueue: workflows.TrustedListImportTaskQueue,
+		Workflows: []workflowengine.Workflow{
+			workflows.NewTrustedListImportWorkflow(),
+		},
+		Activities: []workflowengine.ExecutableActivity{
+			activities.NewFetchTrustedListActivity(),
+			activities.NewInternalHTTPActivity(),
+		},
+	},
+	{
+		TaskQueue: workflows.VerifierImportTaskQueue,
+		Workflows: []workflowengine.Workflow{
+			workflows.NewVerifierImportWorkflow(),
+		},
+		Activities: []workflowengine.ExecutableActivity{
+			activities.NewFetchVerifierRequestActivity(),
+			activities.NewInternalHTTPActivity(),
+		},
+	},
 }
 
 var DefaultWorkers = []workerConfig{
@@ -312,6 +356,15 @@ var DefaultWorkers = []workerConfig{
 			activities.NewInternalHTTPActivity(),
 		},
 	},
+	{
+		TaskQueue: workflows.WebhookDeliveryTaskQueue,
+		Workflows: []workflowengine.Workflow{
+			workflows.NewWebhookDeliveryWorkflow(),
+		},
+		Activities: []workflowengine.ExecutableActivity{
+			activities.NewInternalHTTPActivity(),
+		},
+	},
 }
 
 var (
@@ -349,7 +402,7 @@ const (
 func startWorker(ctx context.Context, c client.Client, config workerConfig, wg *sync.WaitGroup) {
 	defer wg.Done()
 	runWorkerWithRetry(ctx, config.TaskQueue, func() worker.Worker {
-		w := newWorkerFn(c, config.TaskQueue, worker.Options{})
+		w := newWorkerFn(c, config.TaskQueue, workerOptions())
 
 		for _, wf := range config.Workflows {
 			w.RegisterWorkflowWithOptions(wf.Workflow, workflow.RegisterOptions{Name: wf.Name()})
@@ -366,7 +419,7 @@ func startWorker(ctx context.Context, c client.Client, config workerConfig, wg *
 func startPipelineWorker(ctx context.Context, c client.Client, wg *sync.WaitGroup) {
 	defer wg.Done()
 	runWorkerWithRetry(ctx, pipeline.PipelineTaskQueue, func() worker.Worker {
-		w := newWorkerFn(c, pipeline.PipelineTaskQueue, worker.Options{})
+		w := newWorkerFn(c, pipeline.PipelineTaskQueue, workerOptions())
 
 		pipelineWf := pipeline.NewPipelineWorkflow()
 		w.RegisterWorkflowWithOptions(
@@ -422,6 +475,12 @@ func startPipelineWorker(ctx context.Context, c client.Client, wg *sync.WaitGrou
 	})
 }
 
+func workerOptions() worker.Options {
+	return worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{metrics.NewWorkerInterceptor()},
+	}
+}
+
 func runWorkerWithRetry(ctx context.Context, taskQueue string, build func() worker.Worker) {
 	backoff := workerStartInitialBackoff
 	deadline := nowFn().Add(workerStartMaxRetryTime)
@@ -459,6 +518,7 @@ func runWorkerWithRetry(ctx context.Context, taskQueue string, build func() work
 			return
 		}
 
+		metrics.IncWorkerRestart(taskQueue)
 		log.Printf(
 			"Worker for %s stopped with retryable error: %v (retrying in %s)",
 			taskQueue,
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	pipelineinternal "github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const quarantinedStepsOutputKey = "quarantined_steps"
+
+// flakyStepsTracker collects the outcome of each regular step of a top-level
+// run and applies the runtime.flaky_steps policy to the steps that previous
+// runs flagged as flaky.
+type flakyStepsTracker struct {
+	policy             pipelineinternal.FlakyStepsConfig
+	known              map[string]bool
+	outcomes           []pipelineinternal.StepOutcome
+	pipelineIdentifier string
+	yamlHash           string
+	appURL             string
+}
+
+// newFlakyStepsTracker returns nil for runs that were not started from a
+// stored pipeline (child pipelines, direct executions), which record nothing.
+func newFlakyStepsTracker(
+	ctx workflow.Context,
+	wfDef *pipelineinternal.WorkflowDefinition,
+	ao workflow.ActivityOptions,
+	config map[string]any,
+	logger log.Logger,
+) *flakyStepsTracker {
+	pipelineIdentifier, _ := config[pipelineinternal.PipelineIdentifierConfigKey].(string)
+	appURL, _ := config["app_url"].(string)
+	if strings.TrimSpace(pipelineIdentifier) == "" || strings.TrimSpace(appURL) == "" {
+		return nil
+	}
+	yamlHash, _ := config[pipelineinternal.PipelineYAMLHashConfigKey].(string)
+
+	tracker := &flakyStepsTracker{
+		policy:             wfDef.Runtime.FlakySteps,
+		known:              map[string]bool{},
+		pipelineIdentifier: pipelineIdentifier,
+		yamlHash:           yamlHash,
+		appURL:             appURL,
+	}
+	if !tracker.policy.Enabled() {
+		return tracker
+	}
+
+	flaky, err := fetchFlakySteps(ctx, ao, appURL, pipelineIdentifier, yamlHash)
+	if err != nil {
+		logger.Warn("Unable to load flaky steps, policy not applied", "error", err)
+		return tracker
+	}
+	for _, step := range flaky {
+		tracker.known[step.StepID] = true
+	}
+	return tracker
+}
+
+func fetchFlakySteps(
+	ctx workflow.Context,
+	ao workflow.ActivityOptions,
+	appURL string,
+	pipelineIdentifier string,
+	yamlHash string,
+) ([]pipelineinternal.FlakyStep, error) {
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	req := workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodGet,
+			URL:    utils.JoinURL(appURL, "api", "pipeline", "flaky-steps"),
+			QueryParams: map[string]string{
+				"pipeline_identifier": pipelineIdentifier,
+				"yaml_hash":           yamlHash,
+			},
+			ExpectedStatus: http.StatusOK,
+			Timeout:        "30",
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(
+		ctx,
+		evidenceActivityOptions(&ao, time.Minute, 3),
+	)
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(activityCtx, internalHTTPActivity.Name(), req).
+		Get(activityCtx, &result); err != nil {
+		return nil, err
+	}
+
+	output, _ := result.Output.(map[string]any)
+	raw, err := json.Marshal(output["body"])
+	if err != nil {
+		return nil, fmt.Errorf("marshal flaky steps: %w", err)
+	}
+	var body struct {
+		FlakySteps []pipelineinternal.FlakyStep `json:"flaky_steps"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("decode flaky steps: %w", err)
+	}
+	return body.FlakySteps, nil
+}
+
+// shouldRetry reports whether the retry policy grants stepID another attempt
+// after err. Cancellations and timeouts are never retried.
+func (t *flakyStepsTracker) shouldRetry(stepID string, attempts int, err error) bool {
+	if t == nil || err == nil || !t.known[stepID] ||
+		t.policy.Policy != pipelineinternal.FlakyStepsPolicyRetry {
+		return false
+	}
+	if temporal.IsCanceledError(err) || temporal.IsTimeoutError(err) {
+		return false
+	}
+	return attempts <= t.policy.Retries()
+}
+
+// quarantines reports whether a failure of stepID must not fail the run.
+func (t *flakyStepsTracker) quarantines(stepID string, err error) bool {
+	if t == nil || !t.known[stepID] ||
+		t.policy.Policy != pipelineinternal.FlakyStepsPolicyQuarantine {
+		return false
+	}
+	return !temporal.IsCanceledError(err) && !temporal.IsTimeoutError(err)
+}
+
+func (t *flakyStepsTracker) record(
+	step pipelineinternal.StepDefinition,
+	firstErr error,
+	attempts int,
+	quarantined bool,
+) {
+	if t == nil || temporal.IsCanceledError(firstErr) {
+		return
+	}
+	outcome := pipelineinternal.StepOutcomeSuccess
+	if firstErr != nil {
+		outcome = pipelineinternal.StepOutcomeFailed
+	}
+	walletVersion, _ := step.With.Payload["version_id"].(string)
+	t.outcomes = append(t.outcomes, pipelineinternal.StepOutcome{
+		StepID:        step.ID,
+		Use:           step.Use,
+		Outcome:       outcome,
+		Attempts:      attempts,
+		Quarantined:   quarantined,
+		YAMLHash:      t.yamlHash,
+		WalletVersion: walletVersion,
+	})
+}
+
+// report stores the collected outcomes together with the result of the run.
+// Failures are only logged: outcome history is best effort and must not
+// change the result of the run.
+func (t *flakyStepsTracker) report(
+	ctx workflow.Context,
+	ao workflow.ActivityOptions,
+	result string,
+	logger log.Logger,
+) {
+	if t == nil || len(t.outcomes) == 0 {
+		return
+	}
+	info := workflow.GetInfo(ctx)
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	req := workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method:         http.MethodPost,
+			URL:            utils.JoinURL(t.appURL, "api", "pipeline", "step-outcomes"),
+			ExpectedStatus: http.StatusOK,
+			Timeout:        "30",
+			Body: map[string]any{
+				"pipeline_identifier": t.pipelineIdentifier,
+				"workflow_id":         info.WorkflowExecution.ID,
+				"run_id":              info.WorkflowExecution.RunID,
+				"outcomes":            t.outcomes,
+				"result":              result,
+			},
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(
+		ctx,
+		evidenceActivityOptions(&ao, 2*time.Minute, 5),
+	)
+	if err := workflow.ExecuteActivity(activityCtx, internalHTTPActivity.Name(), req).
+		Get(activityCtx, nil); err != nil {
+		logger.Warn("Unable to record pipeline step outcomes", "error", err)
+	}
+}
+
+func appendQuarantinedStep(finalOutput map[string]any, stepID string, err error) {
+	quarantined, _ := finalOutput[quarantinedStepsOutputKey].([]map[string]any)
+	finalOutput[quarantinedStepsOutputKey] = append(quarantined, map[string]any{
+		"step_id": stepID,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,300 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/registry"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+const flakyTestStepUse = "flaky-test-step"
+
+// flakyTestActivity fails its first `failures` executions.
+type flakyTestActivity struct {
+	failures int
+	calls    int
+}
+
+func (a *flakyTestActivity) Name() string {
+	return flakyTestStepUse
+}
+
+func (a *flakyTestActivity) Execute(
+	_ context.Context,
+	_ workflowengine.ActivityInput,
+) (workflowengine.ActivityResult, error) {
+	a.calls++
+	if a.calls <= a.failures {
+		return workflowengine.ActivityResult{}, errors.New("flaky failure")
+	}
+	return workflowengine.ActivityResult{Output: map[string]any{"calls": a.calls}}, nil
+}
+
+func (a *flakyTestActivity) NewActivityError(workflowengine.ActivityError) error {
+	return errors.New("activity error")
+}
+
+func (a *flakyTestActivity) NewNonRetryableActivityError(workflowengine.ActivityError) error {
+	return errors.New("activity error")
+}
+
+func (a *flakyTestActivity) NewMissingOrInvalidPayloadError(err error) error {
+	return err
+}
+
+type flakyStepsTestServer struct {
+	flakySteps []string
+	fetches    int
+	outcomes   []any
+	result     string
+}
+
+func registerFlakyStepsTestEnv(
+	t *testing.T,
+	env *testsuite.TestWorkflowEnvironment,
+	act *flakyTestActivity,
+	server *flakyStepsTestServer,
+) {
+	t.Helper()
+
+	pipelineWf := NewPipelineWorkflow()
+	env.RegisterWorkflowWithOptions(
+		pipelineWf.Workflow,
+		workflow.RegisterOptions{Name: pipelineWf.Name()},
+	)
+	env.RegisterActivityWithOptions(
+		act.Execute,
+		activity.RegisterOptions{Name: act.Name()},
+	)
+	orig, hadOrig := registry.Registry[flakyTestStepUse]
+	t.Cleanup(func() {
+		if hadOrig {
+			registry.Registry[flakyTestStepUse] = orig
+			return
+		}
+		delete(registry.Registry, flakyTestStepUse)
+	})
+	registry.Registry[flakyTestStepUse] = registry.TaskFactory{
+		Kind:        registry.TaskActivity,
+		NewFunc:     func() any { return act },
+		PayloadType: reflect.TypeOf(map[string]any{}),
+		OutputKind:  workflowengine.OutputMap,
+	}
+
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	env.RegisterActivityWithOptions(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.InternalHTTPActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			switch payload.URL {
+			case "https://credimi.test/api/pipeline/flaky-steps":
+				require.Equal(t, http.MethodGet, payload.Method)
+				require.Equal(t, "org/flaky", payload.QueryParams["pipeline_identifier"])
+				require.Equal(t, "hash-1", payload.QueryParams["yaml_hash"])
+				server.fetches++
+				flaky := make([]any, 0, len(server.flakySteps))
+				for _, stepID := range server.flakySteps {
+					flaky = append(flaky, map[string]any{"step_id": stepID, "score": 1})
+				}
+				return workflowengine.ActivityResult{Output: map[string]any{
+					"status": http.StatusOK,
+					"body":   map[string]any{"flaky_steps": flaky},
+				}}, nil
+			case "https://credimi.test/api/pipeline/step-outcomes":
+				require.Equal(t, http.MethodPost, payload.Method)
+				body, ok := payload.Body.(map[string]any)
+				require.True(t, ok)
+				require.Equal(t, "org/flaky", body["pipeline_identifier"])
+				require.Equal(t, "default-test-workflow-id", body["workflow_id"])
+				server.outcomes, _ = body["outcomes"].([]any)
+				server.result, _ = body["result"].(string)
+				return workflowengine.ActivityResult{Output: map[string]any{
+					"status": http.StatusOK,
+				}}, nil
+			}
+			t.Fatalf("unexpected internal request to %s", payload.URL)
+			return workflowengine.ActivityResult{}, nil
+		},
+		activity.RegisterOptions{Name: internalHTTPActivity.Name()},
+	)
+}
+
+func flakyStepsTestInput(policy string, maxRetries int) PipelineWorkflowInput {
+	return PipelineWorkflowInput{
+		WorkflowDefinition: &pipeline.WorkflowDefinition{
+			Name: "flaky",
+			Runtime: pipeline.RuntimeConfig{
+				FlakySteps: pipeline.FlakyStepsConfig{
+					Policy:     policy,
+					MaxRetries: maxRetries,
+				},
+			},
+			Steps: []pipeline.StepDefinition{
+				{
+					StepSpec: pipeline.StepSpec{
+						ID:  "login",
+						Use: flakyTestStepUse,
+						With: pipeline.StepInputs{
+							Payload: map[string]any{"version_id": "org/wallet/v1"},
+						},
+					},
+				},
+			},
+		},
+		WorkflowInput: workflowengine.WorkflowInput{
+			Config: map[string]any{
+				"app_url":                            "https://credimi.test",
+				pipeline.PipelineIdentifierConfigKey: "org/flaky",
+				pipeline.PipelineYAMLHashConfigKey:   "hash-1",
+			},
+			ActivityOptions: &workflow.ActivityOptions{
+				StartToCloseTimeout: time.Second,
+				RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+			},
+		},
+	}
+}
+
+func TestPipelineWorkflowRecordsStepOutcomesWithoutPolicy(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{}
+	server := &flakyStepsTestServer{flakySteps: []string{"login"}}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	env.ExecuteWorkflow(NewPipelineWorkflow().Name(), flakyStepsTestInput("", 0))
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Zero(t, server.fetches)
+	require.Equal(t, []any{map[string]any{
+		"step_id":        "login",
+		"use":            flakyTestStepUse,
+		"outcome":        pipeline.StepOutcomeSuccess,
+		"attempts":       float64(1),
+		"yaml_hash":      "hash-1",
+		"wallet_version": "org/wallet/v1",
+	}}, server.outcomes)
+	require.Equal(t, resultSuccess, server.result)
+}
+
+func TestPipelineWorkflowRetriesKnownFlakyStep(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{failures: 2}
+	server := &flakyStepsTestServer{flakySteps: []string{"login"}}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	env.ExecuteWorkflow(
+		NewPipelineWorkflow().Name(),
+		flakyStepsTestInput(pipeline.FlakyStepsPolicyRetry, 2),
+	)
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, 1, server.fetches)
+	require.Equal(t, 3, act.calls)
+	require.Len(t, server.outcomes, 1)
+	outcome := server.outcomes[0].(map[string]any)
+	require.Equal(t, pipeline.StepOutcomeFailed, outcome["outcome"])
+	require.Equal(t, float64(3), outcome["attempts"])
+}
+
+func TestPipelineWorkflowRetryPolicyGivesUp(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{failures: 5}
+	server := &flakyStepsTestServer{flakySteps: []string{"login"}}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	env.ExecuteWorkflow(
+		NewPipelineWorkflow().Name(),
+		flakyStepsTestInput(pipeline.FlakyStepsPolicyRetry, 1),
+	)
+
+	require.Error(t, env.GetWorkflowError())
+	require.Equal(t, 2, act.calls)
+	require.Len(t, server.outcomes, 1)
+	require.Equal(t, resultFailed, server.result)
+}
+
+func TestPipelineWorkflowRetryPolicySkipsStepsNotFlagged(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{failures: 1}
+	server := &flakyStepsTestServer{}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	env.ExecuteWorkflow(
+		NewPipelineWorkflow().Name(),
+		flakyStepsTestInput(pipeline.FlakyStepsPolicyRetry, 2),
+	)
+
+	require.Error(t, env.GetWorkflowError())
+	require.Equal(t, 1, act.calls)
+}
+
+func TestPipelineWorkflowQuarantinesKnownFlakyStep(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{failures: 1}
+	server := &flakyStepsTestServer{flakySteps: []string{"login"}}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	env.ExecuteWorkflow(
+		NewPipelineWorkflow().Name(),
+		flakyStepsTestInput(pipeline.FlakyStepsPolicyQuarantine, 0),
+	)
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, 1, act.calls)
+
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	output, ok := result.Output.(map[string]any)
+	require.True(t, ok)
+	quarantined, ok := output[quarantinedStepsOutputKey].([]any)
+	require.True(t, ok)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, "login", quarantined[0].(map[string]any)["step_id"])
+
+	require.Len(t, server.outcomes, 1)
+	outcome := server.outcomes[0].(map[string]any)
+	require.Equal(t, pipeline.StepOutcomeFailed, outcome["outcome"])
+	require.Equal(t, true, outcome["quarantined"])
+}
+
+func TestPipelineWorkflowSkipsStepOutcomesWithoutPipelineIdentifier(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	act := &flakyTestActivity{}
+	server := &flakyStepsTestServer{flakySteps: []string{"login"}}
+	registerFlakyStepsTestEnv(t, env, act, server)
+
+	input := flakyStepsTestInput(pipeline.FlakyStepsPolicyRetry, 0)
+	delete(input.WorkflowInput.Config, pipeline.PipelineIdentifierConfigKey)
+	env.ExecuteWorkflow(NewPipelineWorkflow().Name(), input)
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Zero(t, server.fetches)
+	require.Nil(t, server.outcomes)
+}
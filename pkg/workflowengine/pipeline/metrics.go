@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
+	"go.temporal.io/sdk/workflow"
+)
+
+// recordPipelineRunMetrics reports the pipeline outcome and its wall-clock
+// duration since the workflow started. The workflow metrics handler skips
+// emission while replaying, so each run is counted once.
+func recordPipelineRunMetrics(ctx workflow.Context, finalResult string) {
+	handler := workflow.GetMetricsHandler(ctx).WithTags(map[string]string{
+		"outcome": finalResult,
+	})
+	handler.Counter(metrics.PipelineRunsTotal).Inc(1)
+	handler.Timer(metrics.PipelineDurationSeconds).Record(
+		workflow.Now(ctx).Sub(workflow.GetInfo(ctx).WorkflowStartTime),
+	)
+}
+
+// recordStepMetrics reports a single step execution labelled by its `use`.
+func recordStepMetrics(ctx workflow.Context, use string, start time.Time, err error) {
+	handler := workflow.GetMetricsHandler(ctx).WithTags(map[string]string{
+		"use":     use,
+		"outcome": metrics.Outcome(err),
+	})
+	handler.Counter(metrics.PipelineStepsTotal).Inc(1)
+	handler.Timer(metrics.PipelineStepDurationSeconds).Record(workflow.Now(ctx).Sub(start))
+}
@@ -5,12 +5,14 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/canonify"
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
 	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	temporalclient "github.com/forkbombeu/credimi/pkg/internal/temporalclient"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
@@ -47,6 +49,8 @@ type pipelineExecutionState struct {
 	failures       []pipelineStepFailure
 	finalOutput    map[string]any
 	previousStepID string
+	flaky          *flakyStepsTracker
+	progress       *pipelineProgress
 }
 
 func NewPipelineWorkflow() *PipelineWorkflow {
@@ -107,6 +111,7 @@ func (w *PipelineWorkflow) Workflow(
 
 	defer func() {
 		finalResult := pipelineFinalResult(ctx, finalErr)
+		recordPipelineRunMetrics(ctx, finalResult)
 		reportGitHubPRCommentDone(
 			ctx,
 			logger,
@@ -125,6 +130,11 @@ func (w *PipelineWorkflow) Workflow(
 		)
 	}()
 
+	if err := reserveConcurrentRun(ctx, ao, config); err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(err, runMetadata)
+	}
+	defer releaseConcurrentRun(ctx, ao, config, logger)
+
 	if wfDef == nil {
 		return workflowengine.WorkflowResult{}, workflowengine.NewMissingOrInvalidPayloadError(
 			fmt.Errorf("workflow_definition is required"),
@@ -138,10 +148,18 @@ func (w *PipelineWorkflow) Workflow(
 
 	state := &pipelineExecutionState{
 		finalOutput: map[string]any{},
+		progress:    newPipelineProgress(),
 	}
+	if err := state.progress.register(ctx); err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(err, runMetadata)
+	}
+	defer func() {
+		state.progress.finish(pipelineFinalResult(ctx, finalErr))
+	}()
 	if hasMobileAutomationStep(wfDef.Steps) {
 		state.finalOutput["result_video_warning"] = "Video recordings are limited to 30 minutes. " +
 			"Tests exceeding this duration may result in an incomplete video."
+		state.progress.warning(ctx, "", state.finalOutput["result_video_warning"].(string))
 	}
 
 	runData := map[string]any{
@@ -166,6 +184,12 @@ func (w *PipelineWorkflow) Workflow(
 		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(err, runMetadata)
 	}
 
+	state.flaky = newFlakyStepsTracker(ctx, wfDef, ao, config, logger)
+	defer func() {
+		reportCtx, _ := workflow.NewDisconnectedContext(ctx)
+		state.flaky.report(reportCtx, ao, pipelineFinalResult(ctx, finalErr), logger)
+	}()
+
 	defer func() {
 		finalResult := pipelineFinalResult(ctx, finalErr)
 		drainPipelineCancellationPolicySignal(ctx, &runData)
@@ -213,6 +237,9 @@ func (w *PipelineWorkflow) Workflow(
 			}
 			state.finalOutput["finally_errors"] = finallyErrorStrs
 			logger.Warn("Finally steps failed", "errors", finallyErrorStrs)
+			for _, message := range finallyErrorStrs {
+				state.progress.warning(ctx, "", "finally step failed: "+message)
+			}
 		}
 	}()
 
@@ -376,6 +403,38 @@ func (w *PipelineWorkflow) executeStep(
 	state *pipelineExecutionState,
 	debug bool,
 	logger log.Logger,
+) (workflow.ActivityOptions, error) {
+	if step.Use == "debug" {
+		return w.dispatchStep(ctx, input, step, ao, config, runMetadata, state, debug, logger)
+	}
+
+	state.progress.stepStarted(ctx, step.ID, step.Use)
+	failuresBefore := len(state.failures)
+	nextAO, err := w.dispatchStep(
+		ctx,
+		input,
+		step,
+		ao,
+		config,
+		runMetadata,
+		state,
+		debug,
+		logger,
+	)
+	state.progress.stepFinished(ctx, step.ID, step.Use, state, failuresBefore, err)
+	return nextAO, err
+}
+
+func (w *PipelineWorkflow) dispatchStep(
+	ctx workflow.Context,
+	input PipelineWorkflowInput,
+	step pipeline.StepDefinition,
+	ao workflow.ActivityOptions,
+	config map[string]any,
+	runMetadata *workflowengine.WorkflowRunMetadata,
+	state *pipelineExecutionState,
+	debug bool,
+	logger log.Logger,
 ) (workflow.ActivityOptions, error) {
 	switch step.Use {
 	case "debug":
@@ -531,7 +590,11 @@ func (w *PipelineWorkflow) executeChildPipelineStep(
 		pipelineURL,
 		len(state.failures) > 0,
 	)
-	childOut, err := runChildPipeline(ctx, step, input, w.Name(), stepInputs, runMetadata)
+	stepCtx, span := startStepSpan(ctx, step, config)
+	stepStart := workflow.Now(ctx)
+	childOut, err := runChildPipeline(stepCtx, step, input, w.Name(), stepInputs, runMetadata)
+	recordStepMetrics(ctx, step.Use, stepStart, err)
+	endStepSpan(span, err)
 	if err != nil {
 		return handleChildPipelineStepError(
 			ctx,
@@ -667,7 +730,42 @@ func (w *PipelineWorkflow) executeRegularStep(
 		len(state.failures) > 0,
 	)
 
-	stepOutput, err := Execute(&step, ctx, config, enrichedStepInputs, ao)
+	var stepOutput any
+	var err, firstErr error
+	attempts := 0
+	for {
+		attempts++
+		stepCtx, span := startStepSpan(ctx, step, config)
+		stepStart := workflow.Now(ctx)
+		stepOutput, err = Execute(&step, stepCtx, config, enrichedStepInputs, ao)
+		recordStepMetrics(ctx, step.Use, stepStart, err)
+		endStepSpan(span, err)
+		if attempts == 1 {
+			firstErr = err
+		}
+		if !state.flaky.shouldRetry(step.ID, attempts, err) {
+			break
+		}
+		logger.Warn("Retrying flaky step", "id", step.ID, "attempt", attempts+1, "error", err)
+		state.progress.add(ctx, ProgressEvent{
+			Type:     ProgressEventWarning,
+			StepID:   step.ID,
+			Attempts: attempts,
+			Message:  "retrying flaky step: " + err.Error(),
+		})
+	}
+	if err != nil && state.flaky.quarantines(step.ID, err) {
+		logger.Warn("Flaky step failed, quarantined", "id", step.ID, "error", err)
+		state.progress.warning(ctx, step.ID, "flaky step failed, quarantined: "+err.Error())
+		state.flaky.record(step, firstErr, attempts, true)
+		if stepOutput != nil {
+			state.finalOutput[step.ID] = map[string]any{"outputs": stepOutput}
+		}
+		appendQuarantinedStep(state.finalOutput, step.ID, err)
+		state.previousStepID = step.ID
+		return ao, nil
+	}
+	state.flaky.record(step, firstErr, attempts, false)
 	if err != nil {
 		if stepOutput != nil {
 			state.finalOutput[step.ID] = map[string]any{"outputs": stepOutput}
@@ -829,7 +927,10 @@ func runStepSuccessHooks(
 	return ExecuteEventStepsOnSuccess(ctx, step.OnSuccess, stepInputs, failures, ao, config)
 }
 
-// Start launches the pipeline workflow via Temporal client
+// Start launches the pipeline workflow via Temporal client. An immediate run
+// reserves one of the concurrent runs of the organization first and fails
+// with a *quota.ExceededError when there is none left; scheduled runs
+// reserve theirs when each run starts.
 func (w *PipelineWorkflow) Start(
 	inputYaml string,
 	config map[string]any,
@@ -880,6 +981,8 @@ func (w *PipelineWorkflow) Start(
 	globalRunnerID := GlobalRunnerIDFromConfig(config)
 	runnerIDs := RunnerIDsWithGlobal(runnerInfo, globalRunnerID)
 	config["disable_android_play_store"] = wfDef.Runtime.DisableAndroidPlayStore
+	pipeline.SetStepOutcomeConfig(config, pipelineIdentifier, inputYaml)
+	config[quota.ReservedRunConfigKey] = namespace
 	entityIDs, err := pipeline.ParseEntityIDs(inputYaml)
 	if err != nil {
 		return result, fmt.Errorf("failed to parse entity IDs: %w", err)
@@ -954,9 +1057,16 @@ func (w *PipelineWorkflow) Start(
 		return result, nil
 	}
 
+	if err := reserveImmediateRun(config, namespace, options.Options.ID); err != nil {
+		return result, err
+	}
+
 	// Start the workflow execution.
 	wf, err := c.ExecuteWorkflow(context.Background(), options.Options, w.Name(), input)
 	if err != nil {
+		if releaseErr := releaseImmediateRun(config, namespace, options.Options.ID); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
 		return result, fmt.Errorf("failed to start workflow: %w", err)
 	}
 	result = workflowengine.WorkflowResult{
@@ -6,10 +6,15 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
 	"github.com/stretchr/testify/mock"
@@ -104,10 +109,15 @@ steps:
 		expectedSearchAttrs,
 		capturedAction.TypedSearchAttributes,
 	)
+	// Each scheduled run reserves its concurrent run when it starts.
+	scheduledInput, ok := capturedAction.Args[0].(PipelineWorkflowInput)
+	require.True(t, ok)
+	require.Equal(t, "default", scheduledInput.WorkflowInput.Config[quota.ReservedRunConfigKey])
 }
 
 func TestPipelineStartImmediate(t *testing.T) {
 	pipelineWf := NewPipelineWorkflow()
+	stubQuotaRuns(t, nil)
 
 	originalClient := pipelineTemporalClient
 	defer func() {
@@ -138,7 +148,7 @@ func TestPipelineStartImmediate(t *testing.T) {
 
 	result, err := pipelineWf.Start(
 		"name: immediate-pipeline\nsteps: []\n",
-		map[string]any{"namespace": "default"},
+		map[string]any{"namespace": "default", "app_url": "https://credimi.test"},
 		map[string]any{},
 		"tenant-1/immediate-pipeline",
 	)
@@ -152,8 +162,119 @@ func TestPipelineStartImmediate(t *testing.T) {
 	require.NotContains(t, capturedInput.WorkflowInput.Config, tempWalletVersionConfigKey)
 }
 
+// quotaRunsStub answers the concurrent runs reservations made by Start.
+type quotaRunsStub struct {
+	exceeded *quota.ExceededError
+	requests []string
+}
+
+func (s *quotaRunsStub) Do(req *http.Request) (*http.Response, error) {
+	s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+	status := http.StatusOK
+	body := "{}"
+	if req.Method == http.MethodPost && s.exceeded != nil {
+		status = http.StatusTooManyRequests
+		body = `{"status":429,"error":"quota","reason":"quota_exceeded","message":"` +
+			s.exceeded.Error() + `"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func stubQuotaRuns(t *testing.T, exceeded *quota.ExceededError) *quotaRunsStub {
+	t.Helper()
+	t.Setenv("CREDIMI_INTERNAL_ADMIN_KEY", "test-internal-key")
+	stub := &quotaRunsStub{exceeded: exceeded}
+	original := quotaRunsHTTPClient
+	quotaRunsHTTPClient = stub
+	t.Cleanup(func() {
+		quotaRunsHTTPClient = original
+	})
+	return stub
+}
+
+func TestPipelineStartEnforcesConcurrentRunsQuota(t *testing.T) {
+	pipelineWf := NewPipelineWorkflow()
+
+	originalClient := pipelineTemporalClient
+	defer func() {
+		pipelineTemporalClient = originalClient
+	}()
+
+	mockClient := temporalmocks.NewClient(t)
+	pipelineTemporalClient = func(_ string) (client.Client, error) {
+		return mockClient, nil
+	}
+	config := func() map[string]any {
+		return map[string]any{"namespace": "acme", "app_url": "https://credimi.test"}
+	}
+
+	stub := stubQuotaRuns(t, &quota.ExceededError{Quota: quota.ConcurrentRuns, Limit: 2, Used: 2})
+	_, err := pipelineWf.Start(
+		"name: limited-pipeline\nsteps: []\n",
+		config(),
+		map[string]any{},
+		"acme/limited-pipeline",
+	)
+	var exceeded *quota.ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	require.Equal(t, quota.ConcurrentRuns, exceeded.Quota)
+	require.Equal(t, []string{"POST /api/organizations/acme/quota/runs"}, stub.requests)
+
+	workflowRun := temporalmocks.NewWorkflowRun(t)
+	workflowRun.On("GetID").Return("workflow-123")
+	workflowRun.On("GetRunID").Return("run-456")
+	var capturedInput PipelineWorkflowInput
+	mockClient.On(
+		"ExecuteWorkflow",
+		mock.Anything,
+		mock.Anything,
+		pipelineWf.Name(),
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		capturedInput = args.Get(3).(PipelineWorkflowInput)
+	}).Return(workflowRun, nil).Once()
+
+	stub = stubQuotaRuns(t, nil)
+	result, err := pipelineWf.Start(
+		"name: limited-pipeline\nsteps: []\n",
+		config(),
+		map[string]any{},
+		"acme/limited-pipeline",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "workflow-123", result.WorkflowID)
+	require.Equal(t, "acme", capturedInput.WorkflowInput.Config[quota.ReservedRunConfigKey])
+	require.Equal(t, []string{"POST /api/organizations/acme/quota/runs"}, stub.requests)
+
+	mockClient.On(
+		"ExecuteWorkflow",
+		mock.Anything,
+		mock.Anything,
+		pipelineWf.Name(),
+		mock.Anything,
+	).Return(nil, errors.New("temporal down")).Once()
+	stub = stubQuotaRuns(t, nil)
+	_, err = pipelineWf.Start(
+		"name: limited-pipeline\nsteps: []\n",
+		config(),
+		map[string]any{},
+		"acme/limited-pipeline",
+	)
+	require.ErrorContains(t, err, "temporal down")
+	require.Len(t, stub.requests, 2)
+	require.True(t, strings.HasPrefix(
+		stub.requests[1],
+		"DELETE /api/organizations/acme/quota/runs/Pipeline-limited-pipeline-",
+	))
+}
+
 func TestPipelineStartIgnoresReservedYAMLConfig(t *testing.T) {
 	pipelineWf := NewPipelineWorkflow()
+	stubQuotaRuns(t, nil)
 
 	originalClient := pipelineTemporalClient
 	defer func() {
@@ -195,7 +316,7 @@ config:
     pull_request_number: 17
 steps: []
 `,
-		map[string]any{"namespace": "default"},
+		map[string]any{"namespace": "default", "app_url": "https://credimi.test"},
 		map[string]any{},
 		"tenant-1/reserved-config",
 	)
@@ -1390,6 +1390,7 @@ func TestPipelineWorkflowFinallyValidationFails(t *testing.T) {
 
 func TestPipelineWorkflowStartWithValidFinally(t *testing.T) {
 	pipelineWf := NewPipelineWorkflow()
+	stubQuotaRuns(t, nil)
 
 	originalClient := pipelineTemporalClient
 	defer func() {
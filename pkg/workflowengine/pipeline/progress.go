@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// PipelineProgressQuery returns the progress events recorded after the given
+// sequence number, so readers can follow a run without replaying its history.
+const PipelineProgressQuery = "GetPipelineProgress"
+
+const (
+	ProgressEventStepStarted  = "step.started"
+	ProgressEventStepFinished = "step.finished"
+	ProgressEventOutput       = "output"
+	ProgressEventWarning      = "warning"
+
+	ProgressStepSuccess     = "success"
+	ProgressStepFailed      = "failed"
+	ProgressStepCanceled    = "canceled"
+	ProgressStepQuarantined = "quarantined"
+
+	// maxProgressEvents bounds the query result; older events are dropped and
+	// readers that fell behind resume from the oldest event still kept.
+	maxProgressEvents = 1000
+	// maxProgressOutputBytes keeps single step outputs from bloating queries.
+	maxProgressOutputBytes = 16 * 1024
+)
+
+// ProgressEvent is one entry of a pipeline run progress stream.
+type ProgressEvent struct {
+	Seq      int       `json:"seq"`
+	Type     string    `json:"type"`
+	StepID   string    `json:"step_id,omitempty"`
+	Use      string    `json:"use,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Attempts int       `json:"attempts,omitempty"`
+	Output   any       `json:"output,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// ProgressSnapshot is the answer to PipelineProgressQuery. Next is the
+// sequence number to pass on the following query; Result is set once Done.
+type ProgressSnapshot struct {
+	Events []ProgressEvent `json:"events"`
+	Next   int             `json:"next"`
+	Done   bool            `json:"done"`
+	Result string          `json:"result,omitempty"`
+}
+
+type pipelineProgress struct {
+	events []ProgressEvent
+	seq    int
+	done   bool
+	result string
+}
+
+func newPipelineProgress() *pipelineProgress {
+	return &pipelineProgress{}
+}
+
+func (p *pipelineProgress) register(ctx workflow.Context) error {
+	return workflow.SetQueryHandler(ctx, PipelineProgressQuery, p.since)
+}
+
+func (p *pipelineProgress) since(after int) (ProgressSnapshot, error) {
+	snapshot := ProgressSnapshot{
+		Events: []ProgressEvent{},
+		Next:   p.seq,
+		Done:   p.done,
+		Result: p.result,
+	}
+	for _, event := range p.events {
+		if event.Seq > after {
+			snapshot.Events = append(snapshot.Events, event)
+		}
+	}
+	return snapshot, nil
+}
+
+func (p *pipelineProgress) add(ctx workflow.Context, event ProgressEvent) {
+	if p == nil {
+		return
+	}
+	p.seq++
+	event.Seq = p.seq
+	event.Time = workflow.Now(ctx).UTC()
+	p.events = append(p.events, event)
+	if len(p.events) > maxProgressEvents {
+		p.events = p.events[len(p.events)-maxProgressEvents:]
+	}
+}
+
+func (p *pipelineProgress) warning(ctx workflow.Context, stepID, message string) {
+	p.add(ctx, ProgressEvent{Type: ProgressEventWarning, StepID: stepID, Message: message})
+}
+
+func (p *pipelineProgress) finish(result string) {
+	if p == nil {
+		return
+	}
+	p.done = true
+	p.result = result
+}
+
+// stepStarted and stepFinished bracket executeStep; the finished status is
+// derived from the state the step left behind.
+func (p *pipelineProgress) stepStarted(ctx workflow.Context, stepID, use string) {
+	p.add(ctx, ProgressEvent{Type: ProgressEventStepStarted, StepID: stepID, Use: use})
+}
+
+func (p *pipelineProgress) stepFinished(
+	ctx workflow.Context,
+	stepID string,
+	use string,
+	state *pipelineExecutionState,
+	failuresBefore int,
+	err error,
+) {
+	if p == nil {
+		return
+	}
+	if entry, ok := state.finalOutput[stepID].(map[string]any); ok {
+		if output, ok := entry["outputs"]; ok && output != nil {
+			p.add(ctx, ProgressEvent{
+				Type:   ProgressEventOutput,
+				StepID: stepID,
+				Output: boundedProgressOutput(output),
+			})
+		}
+	}
+	p.add(ctx, ProgressEvent{
+		Type:   ProgressEventStepFinished,
+		StepID: stepID,
+		Use:    use,
+		Status: progressStepStatus(ctx, stepID, state, failuresBefore, err),
+	})
+}
+
+func progressStepStatus(
+	ctx workflow.Context,
+	stepID string,
+	state *pipelineExecutionState,
+	failuresBefore int,
+	err error,
+) string {
+	if err != nil {
+		if temporal.IsCanceledError(err) || ctx.Err() != nil {
+			return ProgressStepCanceled
+		}
+		return ProgressStepFailed
+	}
+	for _, failure := range state.failures[min(failuresBefore, len(state.failures)):] {
+		if failure.StepID == stepID {
+			return ProgressStepFailed
+		}
+	}
+	quarantined, _ := state.finalOutput[quarantinedStepsOutputKey].([]map[string]any)
+	for _, entry := range quarantined {
+		if entry["step_id"] == stepID {
+			return ProgressStepQuarantined
+		}
+	}
+	return ProgressStepSuccess
+}
+
+func boundedProgressOutput(output any) any {
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return map[string]any{"truncated": true}
+	}
+	if len(encoded) <= maxProgressOutputBytes {
+		return output
+	}
+	return map[string]any{"truncated": true, "size": len(encoded)}
+}
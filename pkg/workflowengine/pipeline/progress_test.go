@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func progressTestStep(id string, rawJSON string, continueOnError bool) pipeline.StepDefinition {
+	payload := map[string]any{"struct_type": "map"}
+	if rawJSON != "" {
+		payload["rawJSON"] = rawJSON
+	}
+	return pipeline.StepDefinition{
+		StepSpec: pipeline.StepSpec{
+			ID:   id,
+			Use:  "json-parse",
+			With: pipeline.StepInputs{Payload: payload},
+		},
+		ContinueOnError: continueOnError,
+	}
+}
+
+func TestPipelineWorkflowRecordsProgress(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	pipelineWf := NewPipelineWorkflow()
+	env.RegisterWorkflowWithOptions(
+		pipelineWf.Workflow,
+		workflow.RegisterOptions{Name: pipelineWf.Name()},
+	)
+	jsonAct := activities.NewJSONActivity(map[string]reflect.Type{
+		"map": reflect.TypeOf(map[string]any{}),
+	})
+	env.RegisterActivityWithOptions(
+		jsonAct.Execute,
+		activity.RegisterOptions{Name: jsonAct.Name()},
+	)
+
+	env.ExecuteWorkflow(
+		pipelineWf.Name(),
+		PipelineWorkflowInput{
+			WorkflowDefinition: &pipeline.WorkflowDefinition{
+				Name: "progress",
+				Steps: []pipeline.StepDefinition{
+					progressTestStep("broken", "", true),
+					progressTestStep("parse", `{"ok":true}`, false),
+				},
+			},
+			WorkflowInput: workflowengine.WorkflowInput{
+				Config: map[string]any{
+					"app_url": "https://example.test",
+				},
+				ActivityOptions: &workflow.ActivityOptions{
+					StartToCloseTimeout: time.Second,
+				},
+			},
+		},
+	)
+	require.Error(t, env.GetWorkflowError())
+
+	value, err := env.QueryWorkflow(PipelineProgressQuery, 0)
+	require.NoError(t, err)
+	var snapshot ProgressSnapshot
+	require.NoError(t, value.Get(&snapshot))
+
+	require.True(t, snapshot.Done)
+	require.Equal(t, resultFailed, snapshot.Result)
+	require.Equal(t, len(snapshot.Events), snapshot.Next)
+
+	summary := make([]string, 0, len(snapshot.Events))
+	for i, event := range snapshot.Events {
+		require.Equal(t, i+1, event.Seq)
+		summary = append(summary, strings.TrimSuffix(
+			event.Type+" "+event.StepID+" "+event.Status, " ",
+		))
+	}
+	require.Equal(t, []string{
+		"step.started broken",
+		"output broken",
+		"step.finished broken failed",
+		"step.started parse",
+		"output parse",
+		"step.finished parse success",
+	}, summary)
+	require.Equal(t, map[string]any{"ok": true}, snapshot.Events[4].Output)
+
+	value, err = env.QueryWorkflow(PipelineProgressQuery, 4)
+	require.NoError(t, err)
+	var tail ProgressSnapshot
+	require.NoError(t, value.Get(&tail))
+	require.Len(t, tail.Events, 2)
+	require.Equal(t, 5, tail.Events[0].Seq)
+}
+
+func TestBoundedProgressOutput(t *testing.T) {
+	small := map[string]any{"ok": true}
+	require.Equal(t, small, boundedProgressOutput(small))
+
+	large := strings.Repeat("x", maxProgressOutputBytes+1)
+	require.Equal(t,
+		map[string]any{"truncated": true, "size": maxProgressOutputBytes + 3},
+		boundedProgressOutput(large),
+	)
+}
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+// quotaRunsHTTPClient reserves the concurrent runs of immediate runs in
+// Start; it is stubbed in unit tests.
+var quotaRunsHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+} = &http.Client{Timeout: 15 * time.Second}
+
+// reserveImmediateRun reserves one of the concurrent runs of the
+// organization owning namespace before Start starts workflowID.
+func reserveImmediateRun(config map[string]any, namespace, workflowID string) error {
+	appURL, _ := config["app_url"].(string)
+	if appURL == "" {
+		return fmt.Errorf("app_url is required")
+	}
+	return activities.ReserveOrganizationRun(
+		context.Background(),
+		quotaRunsHTTPClient,
+		appURL,
+		namespace,
+		workflowID,
+	)
+}
+
+// releaseImmediateRun gives back the run reserved by reserveImmediateRun
+// when the workflow could not be started.
+func releaseImmediateRun(config map[string]any, namespace, workflowID string) error {
+	appURL, _ := config["app_url"].(string)
+	return activities.ReleaseOrganizationRun(
+		context.Background(),
+		quotaRunsHTTPClient,
+		appURL,
+		namespace,
+		workflowID,
+	)
+}
+
+// reservedRunNamespace returns the namespace of the organization whose
+// concurrent runs the run counts against. Child pipelines run inside the
+// run that started them and count nothing.
+func reservedRunNamespace(ctx workflow.Context, config map[string]any) (string, bool) {
+	namespace, _ := config[quota.ReservedRunConfigKey].(string)
+	appURL, _ := config["app_url"].(string)
+	if namespace == "" || appURL == "" || workflow.GetInfo(ctx).ParentWorkflowExecution != nil {
+		return "", false
+	}
+	return namespace, true
+}
+
+// reserveConcurrentRun confirms the concurrent run reserved for the run.
+// Runs started by Start or by the runner queue reserved it already; runs
+// started by a schedule take it here and fail when the quota is exceeded.
+func reserveConcurrentRun(
+	ctx workflow.Context,
+	ao workflow.ActivityOptions,
+	config map[string]any,
+) error {
+	namespace, ok := reservedRunNamespace(ctx, config)
+	if !ok {
+		return nil
+	}
+	appURL, _ := config["app_url"].(string)
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	req := workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method:         http.MethodPost,
+			URL:            activities.OrganizationRunsURL(appURL, namespace),
+			ExpectedStatus: http.StatusOK,
+			Timeout:        "30",
+			Body: map[string]any{
+				"workflow_id": workflow.GetInfo(ctx).WorkflowExecution.ID,
+			},
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(
+		ctx,
+		evidenceActivityOptions(&ao, time.Minute, 3),
+	)
+	if err := workflow.ExecuteActivity(activityCtx, internalHTTPActivity.Name(), req).
+		Get(activityCtx, nil); err != nil {
+		return fmt.Errorf("reserve concurrent run: %w", err)
+	}
+	return nil
+}
+
+// releaseConcurrentRun gives back the concurrent run of the run when it
+// ends. Failures are only logged: the app releases the runs of ended
+// workflows when the quota is next exceeded.
+func releaseConcurrentRun(
+	ctx workflow.Context,
+	ao workflow.ActivityOptions,
+	config map[string]any,
+	logger log.Logger,
+) {
+	namespace, ok := reservedRunNamespace(ctx, config)
+	if !ok {
+		return
+	}
+	appURL, _ := config["app_url"].(string)
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	req := workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodDelete,
+			URL: utils.JoinURL(
+				activities.OrganizationRunsURL(appURL, namespace),
+				workflow.GetInfo(ctx).WorkflowExecution.ID,
+			),
+			ExpectedStatus: http.StatusOK,
+			Timeout:        "30",
+		},
+	}
+	releaseCtx, _ := workflow.NewDisconnectedContext(ctx)
+	releaseCtx = workflow.WithActivityOptions(
+		releaseCtx,
+		evidenceActivityOptions(&ao, time.Minute, 5),
+	)
+	if err := workflow.ExecuteActivity(releaseCtx, internalHTTPActivity.Name(), req).
+		Get(releaseCtx, nil); err != nil {
+		logger.Warn("Unable to release concurrent run", "error", err)
+	}
+}
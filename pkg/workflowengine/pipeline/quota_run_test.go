@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/quota"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// registerQuotaRunsActivity answers the concurrent run requests of the
+// pipeline workflow, refusing reservations with reserveErr, and returns the
+// requests made.
+func registerQuotaRunsActivity(
+	t *testing.T,
+	env *testsuite.TestWorkflowEnvironment,
+	reserveErr error,
+) *[]string {
+	t.Helper()
+	requests := []string{}
+	internalHTTPActivity := activities.NewInternalHTTPActivity()
+	env.RegisterActivityWithOptions(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.InternalHTTPActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			requests = append(requests, payload.Method+" "+payload.URL)
+			if payload.Method == http.MethodPost && reserveErr != nil {
+				return workflowengine.ActivityResult{}, reserveErr
+			}
+			return workflowengine.ActivityResult{Output: map[string]any{
+				"status": http.StatusOK,
+			}}, nil
+		},
+		activity.RegisterOptions{Name: internalHTTPActivity.Name()},
+	)
+	return &requests
+}
+
+func quotaRunInput() PipelineWorkflowInput {
+	return PipelineWorkflowInput{
+		WorkflowDefinition: &pipeline.WorkflowDefinition{
+			Name:  "quota-run",
+			Steps: []pipeline.StepDefinition{},
+		},
+		WorkflowInput: workflowengine.WorkflowInput{
+			Config: map[string]any{
+				"app_url":                  "https://credimi.test",
+				quota.ReservedRunConfigKey: "acme",
+			},
+		},
+	}
+}
+
+func TestPipelineWorkflowReservesAndReleasesConcurrentRun(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	pipelineWf := NewPipelineWorkflow()
+	env.RegisterWorkflowWithOptions(
+		pipelineWf.Workflow,
+		workflow.RegisterOptions{Name: pipelineWf.Name()},
+	)
+	requests := registerQuotaRunsActivity(t, env, nil)
+
+	env.ExecuteWorkflow(pipelineWf.Name(), quotaRunInput())
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, []string{
+		"POST https://credimi.test/api/organizations/acme/quota/runs",
+		"DELETE https://credimi.test/api/organizations/acme/quota/runs/default-test-workflow-id",
+	}, *requests)
+}
+
+func TestPipelineWorkflowFailsWhenConcurrentRunRefused(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	pipelineWf := NewPipelineWorkflow()
+	env.RegisterWorkflowWithOptions(
+		pipelineWf.Workflow,
+		workflow.RegisterOptions{Name: pipelineWf.Name()},
+	)
+	requests := registerQuotaRunsActivity(
+		t,
+		env,
+		errors.New("organization concurrent_runs quota exceeded: 2 of 2 used"),
+	)
+
+	env.ExecuteWorkflow(pipelineWf.Name(), quotaRunInput())
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.ErrorContains(t, env.GetWorkflowError(), "concurrent_runs quota exceeded")
+	for _, request := range *requests {
+		require.NotContains(t, request, "DELETE", "a refused run has nothing to release")
+	}
+}
+
+func TestChildPipelineDoesNotReserveConcurrentRun(t *testing.T) {
+	suite := testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	pipelineWf := NewPipelineWorkflow()
+	env.RegisterWorkflowWithOptions(
+		pipelineWf.Workflow,
+		workflow.RegisterOptions{Name: pipelineWf.Name()},
+	)
+	env.RegisterWorkflowWithOptions(
+		func(ctx workflow.Context, input PipelineWorkflowInput) error {
+			return workflow.ExecuteChildWorkflow(ctx, pipelineWf.Name(), input).Get(ctx, nil)
+		},
+		workflow.RegisterOptions{Name: "parent-pipeline"},
+	)
+	requests := registerQuotaRunsActivity(t, env, nil)
+
+	env.ExecuteWorkflow("parent-pipeline", quotaRunInput())
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Empty(t, *requests)
+}
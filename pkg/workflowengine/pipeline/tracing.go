@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/forkbombeu/credimi/pkg/internal/tracing"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	stepSpanOperation = "RunPipelineStep"
+
+	stepSpanAttrID       = "credimi.step.id"
+	stepSpanAttrUse      = "credimi.step.use"
+	stepSpanAttrRunnerID = "credimi.runner.id"
+	stepSpanAttrOutcome  = "credimi.step.outcome"
+)
+
+// startStepSpan opens the span wrapping a single pipeline step. Activities and
+// child workflows started with the returned context become its children.
+func startStepSpan(
+	ctx workflow.Context,
+	step pipeline.StepDefinition,
+	config map[string]any,
+) (workflow.Context, *tracing.WorkflowSpan) {
+	tags := map[string]string{
+		stepSpanAttrID:  step.ID,
+		stepSpanAttrUse: step.Use,
+	}
+	if runnerID := stepRunnerID(step, config); runnerID != "" {
+		tags[stepSpanAttrRunnerID] = runnerID
+	}
+	return tracing.StartWorkflowSpan(ctx, stepSpanOperation, step.ID, tags)
+}
+
+func endStepSpan(span *tracing.WorkflowSpan, err error) {
+	span.End(err, map[string]string{stepSpanAttrOutcome: metrics.Outcome(err)})
+}
+
+func stepRunnerID(step pipeline.StepDefinition, config map[string]any) string {
+	if runnerID, ok := step.With.Payload["runner_id"].(string); ok && runnerID != "" {
+		return runnerID
+	}
+	runnerID, _ := config["global_runner_id"].(string)
+	return runnerID
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepRunnerID(t *testing.T) {
+	step := pipeline.StepDefinition{
+		StepSpec: pipeline.StepSpec{
+			ID:  "mobile",
+			Use: "mobile-automation",
+			With: pipeline.StepInputs{
+				Payload: map[string]any{"runner_id": "org/runner-a"},
+			},
+		},
+	}
+	config := map[string]any{"global_runner_id": "org/global"}
+
+	require.Equal(t, "org/runner-a", stepRunnerID(step, config))
+
+	step.With.Payload = map[string]any{}
+	require.Equal(t, "org/global", stepRunnerID(step, config))
+	require.Empty(t, stepRunnerID(step, map[string]any{}))
+}
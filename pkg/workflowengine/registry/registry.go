@@ -77,6 +77,36 @@ var Registry = map[string]TaskFactory{
 		PayloadType: reflect.TypeOf(activities.CheckCredentialsIssuerActivityPayload{}),
 		OutputKind:  workflowengine.OutputMap,
 	},
+	"federation-trust-chain": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewResolveFederationTrustChainActivity() },
+		PayloadType: reflect.TypeOf(activities.ResolveFederationTrustChainActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"credential-status-check": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewCheckCredentialStatusActivity() },
+		PayloadType: reflect.TypeOf(activities.CheckCredentialStatusActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"did-resolve": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewResolveDIDActivity() },
+		PayloadType: reflect.TypeOf(activities.ResolveDIDActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"x509-chain-validation": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewValidateX509ChainActivity() },
+		PayloadType: reflect.TypeOf(activities.ValidateX509ChainActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"protocol-fuzz": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewProtocolFuzzActivity() },
+		PayloadType: reflect.TypeOf(activities.ProtocolFuzzActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
 	"cesr-parse": {
 		Kind:        TaskActivity,
 		NewFunc:     func() any { return activities.NewCESRParsingActivity() },
@@ -176,4 +206,32 @@ var PipelineInternalRegistry = map[string]TaskFactory{
 		PayloadType: reflect.TypeOf(activities.PipelineReportGenerationInput{}),
 		OutputKind:  workflowengine.OutputMap,
 	},
+	"reference-issuer": {
+		Kind:        TaskWorkflow,
+		NewFunc:     func() any { return workflows.NewReferenceIssuerWorkflow() },
+		PayloadType: reflect.TypeOf(workflows.ReferenceIssuerWorkflowPayload{}),
+	},
+	"reference-verifier": {
+		Kind:        TaskWorkflow,
+		NewFunc:     func() any { return workflows.NewReferenceVerifierWorkflow() },
+		PayloadType: reflect.TypeOf(workflows.ReferenceVerifierWorkflowPayload{}),
+	},
+	"reference-sign-request": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewSignReferenceRequestActivity() },
+		PayloadType: reflect.TypeOf(activities.SignReferenceRequestActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"reference-issue-credential": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewIssueReferenceCredentialActivity() },
+		PayloadType: reflect.TypeOf(activities.IssueReferenceCredentialActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
+	"reference-verify-presentation": {
+		Kind:        TaskActivity,
+		NewFunc:     func() any { return activities.NewVerifyReferencePresentationActivity() },
+		PayloadType: reflect.TypeOf(activities.VerifyReferencePresentationActivityPayload{}),
+		OutputKind:  workflowengine.OutputMap,
+	},
 }
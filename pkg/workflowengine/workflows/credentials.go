@@ -18,6 +18,8 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/internal/signedmetadata"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
@@ -95,6 +97,7 @@ func (w *CredentialsIssuersWorkflow) ExecuteWorkflow(
 	credentialsIssuerDataReady := false
 	var issuerName, logo string
 	var credentialsNumber int
+	var signedMetadata map[string]any
 
 	workflow.SetQueryHandler(ctx, CredentialsIssuerDataQuery, func() (map[string]any, error) {
 		if !credentialsIssuerDataReady {
@@ -104,6 +107,7 @@ func (w *CredentialsIssuersWorkflow) ExecuteWorkflow(
 			"issuerName":        issuerName,
 			"logo":              logo,
 			"credentialsNumber": credentialsNumber,
+			"signedMetadata":    signedMetadata,
 		}, nil
 	})
 	baseURL, appURL, issuerSchema, issuerID, err := validateInput(input)
@@ -139,6 +143,7 @@ func (w *CredentialsIssuersWorkflow) ExecuteWorkflow(
 	issuerName = metadata.IssuerName
 	logo = metadata.Logo
 	credentialsNumber = len(metadata.CredentialConfigurations)
+	signedMetadata = metadata.SignedMetadata
 	credentialsIssuerDataReady = true
 
 	storeResult, err := storeCredentialIssuerCredentials(
@@ -176,6 +181,12 @@ type credentialIssuerMetadata struct {
 	CredentialConfigurations map[string]any
 	InvalidCredentials       map[string]bool
 	Errors                   map[string]any
+	// SignedMetadata is the outcome of verifying signed_metadata, stored on
+	// the credential_issuers record.
+	SignedMetadata map[string]any
+	// Metadata is the whole issuer metadata, with verified signed values
+	// applied, as snapshotted by the metadata monitor.
+	Metadata map[string]any
 }
 
 type credentialIssuerCredentialStoreParams struct {
@@ -297,6 +308,25 @@ func fetchCredentialIssuerMetadata(
 			input.RunMetadata,
 		)
 	}
+	signedMetadata := map[string]any{"status": string(signedmetadata.StatusAbsent)}
+	var signedMetadataIssues []activities.SchemaValidationIssue
+	if _, ok := issuerData[signedmetadata.Claim]; ok {
+		signedMetadata, err = verifySignedIssuerMetadata(ctx, input, issuerData, baseURL)
+		if err != nil {
+			return credentialIssuerMetadata{}, err
+		}
+		signedMetadataIssues = signedMetadataValidationIssues(signedMetadata)
+		// Signed values take precedence over the plain JSON ones.
+		if signedMetadata["status"] == string(signedmetadata.StatusVerified) {
+			if claims, ok := signedMetadata["claims"].(map[string]any); ok {
+				for key, value := range claims {
+					issuerData[key] = value
+				}
+			}
+		}
+		delete(signedMetadata, "claims")
+	}
+
 	validateJSON := activities.NewSchemaValidationActivity()
 	validateErr := workflow.ExecuteActivity(ctx, validateJSON.Name(), workflowengine.ActivityInput{
 		Payload: activities.SchemaValidationActivityPayload{
@@ -315,6 +345,16 @@ func fetchCredentialIssuerMetadata(
 		issuerLevelValidationErrors = hasIssuerLevelValidationIssues(issues)
 		invalidCred = invalidCredentialsFromSchemaValidationIssues(issues)
 	}
+	if len(signedMetadataIssues) > 0 {
+		issues, _ := errs["SchemaValidation"].([]activities.SchemaValidationIssue)
+		errs["SchemaValidation"] = append(issues, signedMetadataIssues...)
+		if hasIssuerLevelValidationIssues(signedMetadataIssues) {
+			issuerLevelValidationErrors = true
+		}
+		for credKey := range invalidCredentialsFromSchemaValidationIssues(signedMetadataIssues) {
+			invalidCred[credKey] = true
+		}
+	}
 
 	if displayList, ok := issuerData["display"].([]any); ok && len(displayList) > 0 {
 		if first, ok := displayList[0].(map[string]any); ok {
@@ -356,9 +396,83 @@ func fetchCredentialIssuerMetadata(
 		CredentialConfigurations: credConfigs,
 		InvalidCredentials:       invalidCred,
 		Errors:                   errs,
+		SignedMetadata:           signedMetadata,
+		Metadata:                 issuerData,
 	}, nil
 }
 
+func verifySignedIssuerMetadata(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	issuerData map[string]any,
+	baseURL string,
+) (map[string]any, error) {
+	verifyAct := activities.NewVerifySignedIssuerMetadataActivity()
+	var result workflowengine.ActivityResult
+	err := workflow.ExecuteActivity(ctx, verifyAct.Name(), workflowengine.ActivityInput{
+		Payload: activities.VerifySignedIssuerMetadataActivityPayload{
+			Metadata:         issuerData,
+			CredentialIssuer: baseURL,
+		},
+	}).Get(ctx, &result)
+	if err != nil {
+		return nil, workflowengine.NewWorkflowError(err, input.RunMetadata)
+	}
+	verification, ok := result.Output.(map[string]any)
+	if !ok {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: output", verifyAct.Name()),
+			},
+		)
+		return nil, workflowengine.NewWorkflowError(appErr, input.RunMetadata)
+	}
+	return verification, nil
+}
+
+// signedMetadataValidationIssues reports a failed verification as an issuer
+// level issue and every unsigned value that differs from the signed one as
+// an issue on its own path.
+func signedMetadataValidationIssues(
+	verification map[string]any,
+) []activities.SchemaValidationIssue {
+	var issues []activities.SchemaValidationIssue
+	if verification["status"] == string(signedmetadata.StatusInvalid) {
+		issues = append(issues, activities.SchemaValidationIssue{
+			Field: signedmetadata.Claim,
+			Path:  []string{signedmetadata.Claim},
+			Message: fmt.Sprintf(
+				"signed_metadata verification failed: %s",
+				stringFromIssueMap(verification, "error"),
+			),
+		})
+	}
+	mismatches, _ := verification["mismatches"].([]any)
+	for _, raw := range mismatches {
+		mismatch, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		path := workflowengine.AsSliceOfStrings(mismatch["path"])
+		if len(path) == 0 {
+			continue
+		}
+		issues = append(issues, activities.SchemaValidationIssue{
+			Field: path[len(path)-1],
+			Path:  path,
+			Message: fmt.Sprintf(
+				"value differs from signed_metadata: signed %v, unsigned %v",
+				mismatch["signed"],
+				mismatch["unsigned"],
+			),
+		})
+	}
+	return credentialSchemaValidationIssues(issues)
+}
+
 func storeCredentialIssuerCredentials(
 	ctx workflow.Context,
 	input workflowengine.WorkflowInput,
@@ -467,8 +581,11 @@ type GetCredentialOfferWorkflow struct {
 	WorkflowFunc workflowengine.WorkflowFn
 }
 
+// GetCredentialOfferWorkflowPayload selects either a stored credential or a
+// session of the built-in reference issuer.
 type GetCredentialOfferWorkflowPayload struct {
-	CredentialID string `json:"credential_id" yaml:"credential_id" validate:"required"`
+	CredentialID    string                  `json:"credential_id,omitempty"    yaml:"credential_id,omitempty"    validate:"required_without=ReferenceIssuer" xoneof:"credential_offer"`
+	ReferenceIssuer *reference.IssuerConfig `json:"reference_issuer,omitempty" yaml:"reference_issuer,omitempty"                                             xoneof:"credential_offer"`
 }
 
 func NewGetCredentialOfferWorkflow() *GetCredentialOfferWorkflow {
@@ -487,6 +604,7 @@ func (w *GetCredentialOfferWorkflow) GetOptions() workflow.ActivityOptions {
 
 // ExecuteWorkflow retrieves a credential offer for a stored credential. Static offers are
 // returned directly; dynamic offers execute the stored StepCI workflow and return its deeplink.
+// With a reference issuer it starts a reference issuer session and returns its offer.
 //
 // Parameters:
 // - ctx: The workflow context.
@@ -523,6 +641,16 @@ func (w *GetCredentialOfferWorkflow) ExecuteWorkflow(
 			input.RunMetadata,
 		)
 	}
+	if payload.ReferenceIssuer != nil {
+		credentialOffer, err := startReferenceIssuer(ctx, input, appURL, *payload.ReferenceIssuer)
+		if err != nil {
+			return workflowengine.WorkflowResult{}, err
+		}
+		return workflowengine.WorkflowResult{
+			Message: "Successfully started reference issuer session",
+			Output:  credentialOffer,
+		}, nil
+	}
 	act := activities.NewInternalHTTPActivity()
 	var result workflowengine.ActivityResult
 	request := workflowengine.ActivityInput{
@@ -4,6 +4,7 @@
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -931,3 +932,129 @@ func Test_GetCredentialOfferWorkflow(t *testing.T) {
 		})
 	}
 }
+
+func Test_CredentialsIssuersWorkflowSignedMetadata(t *testing.T) {
+	var testSuite testsuite.WorkflowTestSuite
+	env := testSuite.NewTestWorkflowEnvironment()
+
+	checkAct := activities.NewCheckCredentialsIssuerActivity()
+	jsonAct := activities.NewJSONActivity(nil)
+	verifyAct := activities.NewVerifySignedIssuerMetadataActivity()
+	validateAct := activities.NewSchemaValidationActivity()
+	httpAct := activities.NewInternalHTTPActivity()
+	for _, act := range []workflowengine.ExecutableActivity{
+		checkAct, jsonAct, verifyAct, validateAct, httpAct,
+	} {
+		env.RegisterActivityWithOptions(
+			act.Execute,
+			activity.RegisterOptions{Name: act.Name()},
+		)
+	}
+
+	env.OnActivity(checkAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{Output: map[string]any{
+			"rawJSON": "{}",
+			"source":  ".well-known/openid-credential-issuer",
+		}}, nil)
+	env.OnActivity(jsonAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{Output: map[string]any{
+			"credential_issuer": "https://issuer.example",
+			"display":           []any{map[string]any{"name": "Unsigned Name"}},
+			"credential_configurations_supported": map[string]any{
+				"cred1": map[string]any{"format": "mso_mdoc"},
+				"cred2": map[string]any{"format": "dc+sd-jwt"},
+			},
+			"signed_metadata": "header.payload.signature",
+		}}, nil)
+	env.OnActivity(verifyAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{Output: map[string]any{
+			"status":     "verified",
+			"issuer":     "https://issuer.example",
+			"key_source": "x5c",
+			"claims": map[string]any{
+				"display": []any{map[string]any{"name": "Signed Name"}},
+				"credential_configurations_supported": map[string]any{
+					"cred1": map[string]any{"format": "dc+sd-jwt"},
+					"cred2": map[string]any{"format": "dc+sd-jwt"},
+				},
+			},
+			"mismatches": []any{
+				map[string]any{
+					"path": []any{
+						"credential_configurations_supported",
+						"cred1",
+						"format",
+					},
+					"signed":   "dc+sd-jwt",
+					"unsigned": "mso_mdoc",
+				},
+			},
+		}}, nil)
+	env.OnActivity(validateAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{}, nil)
+
+	conformant := map[string]bool{}
+	env.OnActivity(httpAct.Name(), mock.Anything, mock.Anything).
+		Return(func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			body := input.Payload.(map[string]any)["body"].(map[string]any)
+			credKey := body["credKey"].(string)
+			conformant[credKey] = body["conformant"].(bool)
+			return workflowengine.ActivityResult{
+				Output: map[string]any{"body": map[string]any{"key": credKey}},
+			}, nil
+		})
+
+	wf := NewCredentialsIssuersWorkflow()
+	env.ExecuteWorkflow(wf.Workflow, workflowengine.WorkflowInput{
+		Config: map[string]any{
+			"app_url":       "https://example.com",
+			"issuer_schema": "{}",
+			"orgID":         "org123",
+		},
+		Payload: CredentialsIssuersWorkflowPayload{
+			IssuerID: "issuer123",
+			BaseURL:  "https://issuer.example",
+		},
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	// Signed values take precedence over unsigned ones.
+	require.Equal(t, "Signed Name", result.Output.(map[string]any)["issuerName"])
+	require.Equal(t, map[string]bool{"cred1": false, "cred2": true}, conformant)
+
+	issues := result.Errors.(map[string]any)["SchemaValidation"].([]any)
+	require.Len(t, issues, 1)
+	issue := issues[0].(map[string]any)
+	require.Equal(t, "credential", issue["scope"])
+	require.Equal(t, "cred1", issue["credential_id"])
+	require.Equal(t, "format", issue["field"])
+	require.Contains(t, issue["message"], "differs from signed_metadata")
+
+	encoded, err := env.QueryWorkflow(CredentialsIssuerDataQuery)
+	require.NoError(t, err)
+	var queryResult map[string]any
+	require.NoError(t, encoded.Get(&queryResult))
+	signedMetadata := queryResult["signedMetadata"].(map[string]any)
+	require.Equal(t, "verified", signedMetadata["status"])
+	require.NotContains(t, signedMetadata, "claims")
+}
+
+func TestSignedMetadataValidationIssues(t *testing.T) {
+	issues := signedMetadataValidationIssues(map[string]any{
+		"status": "invalid",
+		"error":  "x5c chain is not trusted",
+	})
+	require.Len(t, issues, 1)
+	require.Equal(t, "issuer", issues[0].Scope)
+	require.Equal(t, "signed_metadata", issues[0].Field)
+	require.Contains(t, issues[0].Message, "x5c chain is not trusted")
+	require.True(t, hasIssuerLevelValidationIssues(issues))
+
+	require.Empty(t, signedMetadataValidationIssues(map[string]any{"status": "verified"}))
+}
@@ -132,6 +132,7 @@ func (w *FidesCredentialIssuersWorkflow) ExecuteWorkflow(
 			orgID,
 			metadata.IssuerName,
 			metadata.Logo,
+			metadata.SignedMetadata,
 		)
 		if err != nil {
 			errs[issuerURL] = err.Error()
@@ -259,6 +260,7 @@ func storeOrUpdateCredentialIssuerRecord(
 	orgID string,
 	name string,
 	logo string,
+	signedMetadata map[string]any,
 ) (string, error) {
 	internalHTTPActivity := activities.NewInternalHTTPActivity()
 	var storeResult workflowengine.ActivityResult
@@ -270,10 +272,11 @@ func storeOrUpdateCredentialIssuerRecord(
 				"api", "credentials_issuers", "store-or-update",
 			),
 			Body: map[string]any{
-				"url":   issuerURL,
-				"orgID": orgID,
-				"name":  name,
-				"logo":  logo,
+				"url":             issuerURL,
+				"orgID":           orgID,
+				"name":            name,
+				"logo":            logo,
+				"signed_metadata": signedMetadata,
 			},
 			ExpectedStatus: http.StatusOK,
 		},
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/issuerdrift"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	IssuerMetadataMonitorTaskQueue    = "IssuerMetadataMonitorTaskQueue"
+	IssuerMetadataMonitorWorkflowName = "Monitor Credential Issuer Metadata"
+)
+
+var issuerMetadataMonitorStartWorkflowWithOptions = workflowengine.StartWorkflowWithOptions
+
+// IssuerMetadataMonitorWorkflow re-fetches the metadata of every credential
+// issuer of an organization, stores a new revision when it drifted and
+// emails the organization owners and admins about breaking changes.
+type IssuerMetadataMonitorWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+// IssuerMetadataMonitorTarget is a credential issuer to snapshot.
+type IssuerMetadataMonitorTarget struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// IssuerMetadataMonitorTargets lists the credential issuers of an organization.
+type IssuerMetadataMonitorTargets struct {
+	Issuers []IssuerMetadataMonitorTarget `json:"issuers"`
+}
+
+// IssuerMetadataRevision is the outcome of storing a metadata snapshot.
+type IssuerMetadataRevision struct {
+	Changed    bool             `json:"changed"`
+	Revision   int              `json:"revision"`
+	Breaking   bool             `json:"breaking"`
+	Diff       issuerdrift.Diff `json:"diff"`
+	Recipients []string         `json:"recipients"`
+}
+
+func NewIssuerMetadataMonitorWorkflow() *IssuerMetadataMonitorWorkflow {
+	w := &IssuerMetadataMonitorWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *IssuerMetadataMonitorWorkflow) Name() string {
+	return IssuerMetadataMonitorWorkflowName
+}
+
+func (w *IssuerMetadataMonitorWorkflow) GetOptions() workflow.ActivityOptions {
+	return DefaultActivityOptions
+}
+
+func (w *IssuerMetadataMonitorWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *IssuerMetadataMonitorWorkflow) Start(
+	namespace string,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       "Issuer-Metadata-Monitor-" + uuid.NewString(),
+		TaskQueue:                IssuerMetadataMonitorTaskQueue,
+		WorkflowExecutionTimeout: 24 * time.Hour,
+	}
+
+	return issuerMetadataMonitorStartWorkflowWithOptions(
+		namespace,
+		workflowOptions,
+		w.Name(),
+		input,
+	)
+}
+
+func (w *IssuerMetadataMonitorWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, w.GetOptions())
+	logger := workflow.GetLogger(ctx)
+
+	appURL, ok := input.Config["app_url"].(string)
+	if !ok || appURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"app_url",
+			input.RunMetadata,
+		)
+	}
+	issuerSchema, ok := input.Config["issuer_schema"].(string)
+	if !ok || issuerSchema == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"issuer_schema",
+			input.RunMetadata,
+		)
+	}
+	orgID, ok := input.Config["orgID"].(string)
+	if !ok || orgID == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"orgID",
+			input.RunMetadata,
+		)
+	}
+
+	targets, err := fetchIssuerMetadataMonitorTargets(ctx, input, appURL, orgID)
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+
+	changed := []string{}
+	breaking := []string{}
+	logs := map[string][]any{}
+	errs := map[string]any{}
+	for _, target := range targets {
+		metadata, err := fetchCredentialIssuerMetadata(ctx, input, target.URL, issuerSchema)
+		if err != nil {
+			errs[target.URL] = err.Error()
+			continue
+		}
+
+		revision, err := storeIssuerMetadataRevision(
+			ctx,
+			input,
+			appURL,
+			target.ID,
+			metadata.Metadata,
+		)
+		if err != nil {
+			errs[target.URL] = err.Error()
+			continue
+		}
+		if !revision.Changed {
+			continue
+		}
+
+		changed = append(changed, target.URL)
+		logs[target.URL] = []any{revision.Diff}
+		if !revision.Breaking {
+			continue
+		}
+
+		breaking = append(breaking, target.URL)
+		for _, recipient := range revision.Recipients {
+			if err := notifyIssuerMetadataChange(ctx, target.URL, recipient, revision); err != nil {
+				logger.Error("Failed to notify breaking metadata change", "error", err)
+				errs[target.URL] = err.Error()
+			}
+		}
+	}
+
+	return workflowengine.WorkflowResult{
+		Message: fmt.Sprintf(
+			"Checked %d credential issuers: %d changed, %d with breaking changes",
+			len(targets),
+			len(changed),
+			len(breaking),
+		),
+		Output: map[string]any{
+			"checked":  len(targets),
+			"changed":  changed,
+			"breaking": breaking,
+		},
+		Log:    logs,
+		Errors: errs,
+	}, nil
+}
+
+func fetchIssuerMetadataMonitorTargets(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	orgID string,
+) ([]IssuerMetadataMonitorTarget, error) {
+	act := activities.NewInternalHTTPActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodGet,
+			URL: utils.JoinURL(
+				appURL,
+				"api", "credentials_issuers", "monitor-targets",
+			),
+			QueryParams: map[string]string{
+				"orgID": orgID,
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+	}).Get(ctx, &result); err != nil {
+		return nil, workflowengine.NewWorkflowError(err, input.RunMetadata)
+	}
+
+	body, _ := result.Output.(map[string]any)["body"].(map[string]any)
+	targets, err := workflowengine.DecodePayload[IssuerMetadataMonitorTargets](body)
+	if body == nil || err != nil {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: body", act.Name()),
+			},
+		)
+		return nil, workflowengine.NewWorkflowError(appErr, input.RunMetadata)
+	}
+	return targets.Issuers, nil
+}
+
+func storeIssuerMetadataRevision(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	issuerID string,
+	metadata map[string]any,
+) (IssuerMetadataRevision, error) {
+	act := activities.NewInternalHTTPActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodPost,
+			URL: utils.JoinURL(
+				appURL,
+				"api", "credentials_issuers", "revisions",
+			),
+			Headers: map[string]string{
+				workflowengine.HTTPHeaderContentType: workflowengine.MIMEApplicationJSON,
+			},
+			Body: map[string]any{
+				"issuerID": issuerID,
+				"metadata": metadata,
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+	}).Get(ctx, &result); err != nil {
+		return IssuerMetadataRevision{}, workflowengine.NewWorkflowError(err, input.RunMetadata)
+	}
+
+	body, _ := result.Output.(map[string]any)["body"].(map[string]any)
+	revision, err := workflowengine.DecodePayload[IssuerMetadataRevision](body)
+	if body == nil || err != nil {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: body", act.Name()),
+			},
+		)
+		return IssuerMetadataRevision{}, workflowengine.NewWorkflowError(appErr, input.RunMetadata)
+	}
+	return revision, nil
+}
+
+func notifyIssuerMetadataChange(
+	ctx workflow.Context,
+	issuerURL string,
+	recipient string,
+	revision IssuerMetadataRevision,
+) error {
+	emailActivity := activities.NewSendMailActivity()
+	emailInput := workflowengine.ActivityInput{
+		Payload: activities.SendMailActivityPayload{
+			Recipient: recipient,
+			Subject:   "[CREDIMI] Breaking changes in credential issuer metadata",
+			Body:      issuerMetadataChangeEmailBody(issuerURL, revision),
+		},
+	}
+	if err := emailActivity.Configure(&emailInput); err != nil {
+		return err
+	}
+	return workflow.ExecuteActivity(ctx, emailActivity.Name(), emailInput).Get(ctx, nil)
+}
+
+func issuerMetadataChangeEmailBody(issuerURL string, revision IssuerMetadataRevision) string {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"The metadata of the credential issuer %s changed (revision %d): %s.\n\n",
+		issuerURL,
+		revision.Revision,
+		revision.Diff.Summary(),
+	)
+	for _, change := range revision.Diff.Changes {
+		prefix := "-"
+		if change.Breaking {
+			prefix = "- [breaking]"
+		}
+		fmt.Fprintf(&b, "%s %s %s %s", prefix, change.Kind, change.Category, change.Path)
+		switch {
+		case change.Old != nil && change.New != nil:
+			fmt.Fprintf(&b, ": %v -> %v", change.Old, change.New)
+		case change.Old != nil:
+			fmt.Fprintf(&b, ": %v", change.Old)
+		case change.New != nil:
+			fmt.Fprintf(&b, ": %v", change.New)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
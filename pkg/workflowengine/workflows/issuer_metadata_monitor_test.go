@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/issuerdrift"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func registerIssuerMetadataMonitorActivities(env *testsuite.TestWorkflowEnvironment) {
+	internalAct := activities.NewInternalHTTPActivity()
+	checkAct := activities.NewCheckCredentialsIssuerActivity()
+	jsonAct := activities.NewJSONActivity(nil)
+	validateAct := activities.NewSchemaValidationActivity()
+	mailAct := activities.NewSendMailActivity()
+
+	env.RegisterActivityWithOptions(
+		internalAct.Execute,
+		activity.RegisterOptions{Name: internalAct.Name()},
+	)
+	env.RegisterActivityWithOptions(
+		checkAct.Execute,
+		activity.RegisterOptions{Name: checkAct.Name()},
+	)
+	env.RegisterActivityWithOptions(jsonAct.Execute, activity.RegisterOptions{Name: jsonAct.Name()})
+	env.RegisterActivityWithOptions(
+		validateAct.Execute,
+		activity.RegisterOptions{Name: validateAct.Name()},
+	)
+	env.RegisterActivityWithOptions(mailAct.Execute, activity.RegisterOptions{Name: mailAct.Name()})
+}
+
+func TestIssuerMetadataMonitorWorkflow(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerIssuerMetadataMonitorActivities(env)
+
+	breakingDiff := issuerdrift.Diff{
+		Breaking: true,
+		Changes: []issuerdrift.Change{{
+			Category: issuerdrift.CategoryCredentialConfiguration,
+			Kind:     issuerdrift.KindRemoved,
+			Path:     "credential_configurations_supported/pid",
+			Breaking: true,
+		}},
+	}
+
+	var mu sync.Mutex
+	storedRevisions := map[string]map[string]any{}
+	internalAct := activities.NewInternalHTTPActivity()
+	env.OnActivity(internalAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.InternalHTTPActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			switch {
+			case strings.HasSuffix(payload.URL, "/monitor-targets"):
+				require.Equal(t, "org123", payload.QueryParams["orgID"])
+				return workflowengine.ActivityResult{Output: map[string]any{
+					"body": map[string]any{"issuers": []any{
+						map[string]any{"id": "issuer1", "url": "https://issuer-1"},
+						map[string]any{"id": "issuer2", "url": "https://issuer-2"},
+					}},
+				}}, nil
+			case strings.HasSuffix(payload.URL, "/revisions"):
+				body := payload.Body.(map[string]any)
+				issuerID := body["issuerID"].(string)
+				mu.Lock()
+				storedRevisions[issuerID] = body["metadata"].(map[string]any)
+				mu.Unlock()
+				revision := IssuerMetadataRevision{Revision: 4}
+				if issuerID == "issuer1" {
+					revision = IssuerMetadataRevision{
+						Changed:    true,
+						Revision:   5,
+						Breaking:   true,
+						Diff:       breakingDiff,
+						Recipients: []string{"owner@example.org", "admin@example.org"},
+					}
+				}
+				return workflowengine.ActivityResult{Output: map[string]any{
+					"body": revision,
+				}}, nil
+			}
+			t.Fatalf("unexpected internal request %s", payload.URL)
+			return workflowengine.ActivityResult{}, nil
+		},
+	)
+	checkAct := activities.NewCheckCredentialsIssuerActivity()
+	env.OnActivity(checkAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{Output: map[string]any{
+			"rawJSON": `{}`,
+			"source":  ".well-known/openid-credential-issuer",
+		}}, nil)
+	env.OnActivity(activities.NewJSONActivity(nil).Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{Output: map[string]any{
+			"credential_endpoint": "https://issuer/credential",
+		}}, nil)
+	env.OnActivity(activities.NewSchemaValidationActivity().Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{}, nil)
+
+	var recipients []string
+	env.OnActivity(activities.NewSendMailActivity().Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.SendMailActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			require.Contains(t, payload.Body, "https://issuer-1")
+			require.Contains(t, payload.Body, "[breaking] removed")
+			mu.Lock()
+			recipients = append(recipients, payload.Recipient)
+			mu.Unlock()
+			return workflowengine.ActivityResult{Output: "Email sent successfully"}, nil
+		},
+	)
+
+	env.ExecuteWorkflow(NewIssuerMetadataMonitorWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: map[string]any{
+			"app_url":       "https://example.com",
+			"issuer_schema": "{}",
+			"orgID":         "org123",
+		},
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(
+		t,
+		"Checked 2 credential issuers: 1 changed, 1 with breaking changes",
+		result.Message,
+	)
+	require.ElementsMatch(t, []string{"owner@example.org", "admin@example.org"}, recipients)
+	require.Equal(
+		t,
+		"https://issuer/credential",
+		storedRevisions["issuer2"]["credential_endpoint"],
+	)
+}
+
+func TestIssuerMetadataMonitorWorkflowMissingConfig(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	env.ExecuteWorkflow(NewIssuerMetadataMonitorWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: map[string]any{
+			"app_url":       "https://example.com",
+			"issuer_schema": "{}",
+		},
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), errorcodes.Codes[errorcodes.MissingOrInvalidConfig].Code)
+}
+
+func TestIssuerMetadataChangeEmailBody(t *testing.T) {
+	body := issuerMetadataChangeEmailBody("https://issuer.example", IssuerMetadataRevision{
+		Revision: 3,
+		Diff: issuerdrift.Diff{
+			Breaking: true,
+			Changes: []issuerdrift.Change{
+				{
+					Category: issuerdrift.CategoryEndpoint,
+					Kind:     issuerdrift.KindModified,
+					Path:     "credential_endpoint",
+					Old:      "https://issuer.example/credential",
+					New:      "https://issuer.example/v2/credential",
+					Breaking: true,
+				},
+				{
+					Category: issuerdrift.CategoryAlgorithm,
+					Kind:     issuerdrift.KindAdded,
+					Path:     "credential_signing_alg_values_supported",
+					New:      "EdDSA",
+				},
+			},
+		},
+	})
+
+	require.Equal(
+		t,
+		"The metadata of the credential issuer https://issuer.example changed (revision 3): "+
+			"2 change(s), 1 breaking.\n\n"+
+			"- [breaking] modified endpoint credential_endpoint: "+
+			"https://issuer.example/credential -> https://issuer.example/v2/credential\n"+
+			"- added algorithm credential_signing_alg_values_supported: EdDSA\n",
+		body,
+	)
+}
+
+func TestIssuerMetadataMonitorWorkflowOptions(t *testing.T) {
+	require.Equal(t, DefaultActivityOptions, NewIssuerMetadataMonitorWorkflow().GetOptions())
+}
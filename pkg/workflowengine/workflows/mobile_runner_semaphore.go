@@ -856,6 +856,7 @@ func (r *mobileRunnerSemaphoreRuntime) awaitContinue() error {
 }
 
 func (r *mobileRunnerSemaphoreRuntime) processRunQueue(ctx workflow.Context) {
+	defer r.recordSemaphoreMetrics(ctx)
 	defer r.flushQueuedPositionUpdates(ctx)
 	if r.shutdownRequested || r.paused {
 		return
@@ -1017,6 +1018,7 @@ func (r *mobileRunnerSemaphoreRuntime) markRunTicketFailed(
 }
 
 func (r *mobileRunnerSemaphoreRuntime) checkRunCompletion(ctx workflow.Context) {
+	defer r.recordSemaphoreMetrics(ctx)
 	if len(r.runTickets) == 0 {
 		return
 	}
@@ -1074,6 +1076,7 @@ func (r *mobileRunnerSemaphoreRuntime) checkRunCompletion(ctx workflow.Context)
 }
 
 func (r *mobileRunnerSemaphoreRuntime) reconcileStartingTickets(ctx workflow.Context) {
+	defer r.recordSemaphoreMetrics(ctx)
 	logger := workflow.GetLogger(ctx)
 	ticketIDs := r.sortedRunTicketIDs()
 	for _, ticketID := range ticketIDs {
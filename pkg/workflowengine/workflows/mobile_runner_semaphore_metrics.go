@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/metrics"
+	"go.temporal.io/sdk/workflow"
+)
+
+// mobileRunnerSemaphoreStuckStartingAfter is how long a ticket may stay in the
+// starting state before it is reported as stuck.
+const mobileRunnerSemaphoreStuckStartingAfter = 5 * time.Minute
+
+// recordSemaphoreMetrics publishes the current queue and slot usage of the
+// runner. Gauges are emitted through the workflow metrics handler, which is a
+// no-op while replaying.
+func (r *mobileRunnerSemaphoreRuntime) recordSemaphoreMetrics(ctx workflow.Context) {
+	now := workflow.Now(ctx)
+	starting := 0
+	stuck := 0
+	for _, state := range r.runTickets {
+		if state.Status != mobileRunnerSemaphoreRunStarting {
+			continue
+		}
+		starting++
+		if state.StartedAt != nil && now.Sub(*state.StartedAt) > mobileRunnerSemaphoreStuckStartingAfter {
+			stuck++
+		}
+	}
+	paused := 0.0
+	if r.paused {
+		paused = 1
+	}
+
+	handler := workflow.GetMetricsHandler(ctx).WithTags(map[string]string{
+		"runner_id": r.runnerID,
+	})
+	handler.Gauge(metrics.SemaphoreQueueDepth).Update(float64(len(r.runQueue)))
+	handler.Gauge(metrics.SemaphoreSlotsUsed).Update(float64(r.runSlotsUsed()))
+	handler.Gauge(metrics.SemaphoreCapacity).Update(float64(r.capacity))
+	handler.Gauge(metrics.SemaphoreStartingTickets).Update(float64(starting))
+	handler.Gauge(metrics.SemaphoreStuckStartingTickets).Update(float64(stuck))
+	handler.Gauge(metrics.SemaphorePaused).Update(paused)
+}
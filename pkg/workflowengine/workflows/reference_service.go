@@ -0,0 +1,537 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	ReferenceIssuerWorkflowName   = "Reference OpenID4VCI issuer"
+	ReferenceVerifierWorkflowName = "Reference OpenID4VP verifier"
+
+	// ReferenceSessionQuery returns the session of a reference workflow.
+	ReferenceSessionQuery = "reference-session"
+	// ReferenceAuthorizeUpdate, ReferenceTokenUpdate and
+	// ReferenceCredentialUpdate drive the endpoints of an issuer session.
+	ReferenceAuthorizeUpdate  = "reference-authorize"
+	ReferenceTokenUpdate      = "reference-token"
+	ReferenceCredentialUpdate = "reference-credential"
+	// ReferenceResponseUpdate posts the presentation of a verifier session.
+	ReferenceResponseUpdate = "reference-response"
+
+	// ReferenceSessionTimeout is how long a session waits for the wallet.
+	ReferenceSessionTimeout = 30 * time.Minute
+)
+
+// ReferenceIssuerWorkflowPayload is the session a reference issuer serves.
+type ReferenceIssuerWorkflowPayload struct {
+	Session reference.IssuerSession `json:"session" validate:"required"`
+}
+
+// ReferenceVerifierWorkflowPayload is the session a reference verifier
+// serves, with its request object already signed.
+type ReferenceVerifierWorkflowPayload struct {
+	Session reference.VerifierSession `json:"session" validate:"required"`
+}
+
+// ReferenceAuthorizeResponse is the redirect of an authorization request.
+type ReferenceAuthorizeResponse struct {
+	Location string                   `json:"location,omitempty"`
+	Error    *reference.ProtocolError `json:"error,omitempty"`
+}
+
+// ReferenceTokenResponse is the answer of the token endpoint.
+type ReferenceTokenResponse struct {
+	Token *reference.TokenResponse `json:"token,omitempty"`
+	Error *reference.ProtocolError `json:"error,omitempty"`
+}
+
+// ReferenceCredentialResponse is the answer of the credential endpoint.
+type ReferenceCredentialResponse struct {
+	Credentials []string                 `json:"credentials,omitempty"`
+	Error       *reference.ProtocolError `json:"error,omitempty"`
+}
+
+// ReferenceResponseRequest is a direct_post presentation response.
+type ReferenceResponseRequest struct {
+	VPToken string `json:"vp_token"`
+	State   string `json:"state"`
+}
+
+// ReferenceResponseResult is the verification of a presentation response.
+type ReferenceResponseResult struct {
+	Result *reference.VerificationResult `json:"result,omitempty"`
+	Error  *reference.ProtocolError      `json:"error,omitempty"`
+}
+
+// ReferenceIssuerWorkflow serves one OpenID4VCI issuance of the built-in
+// reference issuer. The HTTP endpoints reach it through queries and updates;
+// it completes once credentials were issued or the session timed out.
+type ReferenceIssuerWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+func NewReferenceIssuerWorkflow() *ReferenceIssuerWorkflow {
+	w := &ReferenceIssuerWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *ReferenceIssuerWorkflow) Name() string {
+	return ReferenceIssuerWorkflowName
+}
+
+func (w *ReferenceIssuerWorkflow) GetOptions() workflow.ActivityOptions {
+	return DefaultActivityOptions
+}
+
+func (w *ReferenceIssuerWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *ReferenceIssuerWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	payload, err := workflowengine.DecodePayload[ReferenceIssuerWorkflowPayload](input.Payload)
+	if err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingOrInvalidPayloadError(
+			err,
+			input.RunMetadata,
+		)
+	}
+	session := payload.Session
+	// update handlers run on their own context, without the activity options
+	ao := workflow.GetActivityOptions(ctx)
+
+	if err := workflow.SetQueryHandler(
+		ctx,
+		ReferenceSessionQuery,
+		func() (reference.IssuerSession, error) {
+			return session, nil
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.SetUpdateHandler(
+		ctx,
+		ReferenceAuthorizeUpdate,
+		func(
+			_ workflow.Context,
+			req reference.AuthorizeRequest,
+		) (ReferenceAuthorizeResponse, error) {
+			location, protoErr := session.Authorize(req)
+			return ReferenceAuthorizeResponse{Location: location, Error: protoErr}, nil
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.SetUpdateHandler(
+		ctx,
+		ReferenceTokenUpdate,
+		func(_ workflow.Context, req reference.TokenRequest) (ReferenceTokenResponse, error) {
+			token, protoErr := session.Token(req)
+			if protoErr != nil {
+				return ReferenceTokenResponse{Error: protoErr}, nil
+			}
+			return ReferenceTokenResponse{Token: &token}, nil
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.SetUpdateHandler(
+		ctx,
+		ReferenceCredentialUpdate,
+		func(
+			ctx workflow.Context,
+			req reference.CredentialRequest,
+		) (ReferenceCredentialResponse, error) {
+			if protoErr := session.CheckCredentialRequest(req); protoErr != nil {
+				return ReferenceCredentialResponse{Error: protoErr}, nil
+			}
+			ctx = workflow.WithActivityOptions(ctx, ao)
+			act := activities.NewIssueReferenceCredentialActivity()
+			var result workflowengine.ActivityResult
+			if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+				Payload: activities.IssueReferenceCredentialActivityPayload{
+					Session: session,
+					Proofs:  req.Proofs,
+				},
+			}).Get(ctx, &result); err != nil {
+				return ReferenceCredentialResponse{}, err
+			}
+			output, err := workflowengine.DecodePayload[activities.IssueReferenceCredentialActivityOutput](
+				result.Output,
+			)
+			if err != nil {
+				return ReferenceCredentialResponse{}, err
+			}
+			session.Issued += len(output.Credentials)
+			return ReferenceCredentialResponse{
+				Credentials: output.Credentials,
+				Error:       output.Error,
+			}, nil
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+
+	issued, err := workflow.AwaitWithTimeout(ctx, ReferenceSessionTimeout, func() bool {
+		return session.Issued > 0
+	})
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.Await(ctx, func() bool {
+		return workflow.AllHandlersFinished(ctx)
+	}); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if !issued {
+		return workflowengine.WorkflowResult{
+			Message: "Reference issuer session expired before a credential was issued",
+			Output:  map[string]any{"issued": 0},
+		}, nil
+	}
+	return workflowengine.WorkflowResult{
+		Message: fmt.Sprintf("Reference issuer issued %d credential(s)", session.Issued),
+		Output: map[string]any{
+			"configuration": session.Config.Configuration,
+			"issued":        session.Issued,
+		},
+	}, nil
+}
+
+// ReferenceVerifierWorkflow serves one OpenID4VP presentation request of the
+// built-in reference verifier. It fails when the wallet posts a presentation
+// the verifier rejects.
+type ReferenceVerifierWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+func NewReferenceVerifierWorkflow() *ReferenceVerifierWorkflow {
+	w := &ReferenceVerifierWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *ReferenceVerifierWorkflow) Name() string {
+	return ReferenceVerifierWorkflowName
+}
+
+func (w *ReferenceVerifierWorkflow) GetOptions() workflow.ActivityOptions {
+	return DefaultActivityOptions
+}
+
+func (w *ReferenceVerifierWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *ReferenceVerifierWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	payload, err := workflowengine.DecodePayload[ReferenceVerifierWorkflowPayload](input.Payload)
+	if err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingOrInvalidPayloadError(
+			err,
+			input.RunMetadata,
+		)
+	}
+	session := payload.Session
+	ao := workflow.GetActivityOptions(ctx)
+	var verified *reference.VerificationResult
+
+	if err := workflow.SetQueryHandler(
+		ctx,
+		ReferenceSessionQuery,
+		func() (reference.VerifierSession, error) {
+			return session, nil
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.SetUpdateHandlerWithOptions(
+		ctx,
+		ReferenceResponseUpdate,
+		func(ctx workflow.Context, req ReferenceResponseRequest) (ReferenceResponseResult, error) {
+			if protoErr := session.CheckResponse(req.State); protoErr != nil {
+				return ReferenceResponseResult{Error: protoErr}, nil
+			}
+			ctx = workflow.WithActivityOptions(ctx, ao)
+			act := activities.NewVerifyReferencePresentationActivity()
+			var result workflowengine.ActivityResult
+			if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+				Payload: activities.VerifyReferencePresentationActivityPayload{
+					Session: session,
+					VPToken: req.VPToken,
+				},
+			}).Get(ctx, &result); err != nil {
+				return ReferenceResponseResult{}, err
+			}
+			output, err := workflowengine.DecodePayload[reference.VerificationResult](
+				result.Output,
+			)
+			if err != nil {
+				return ReferenceResponseResult{}, err
+			}
+			verified = &output
+			if !output.Valid {
+				return ReferenceResponseResult{
+					Result: verified,
+					Error: &reference.ProtocolError{
+						Status:      http.StatusBadRequest,
+						Code:        "invalid_request",
+						Description: strings.Join(output.Issues, "; "),
+					},
+				}, nil
+			}
+			return ReferenceResponseResult{Result: verified}, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(_ workflow.Context, _ ReferenceResponseRequest) error {
+				if verified != nil {
+					return fmt.Errorf("the session already received a response")
+				}
+				return nil
+			},
+		},
+	); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+
+	received, err := workflow.AwaitWithTimeout(ctx, ReferenceSessionTimeout, func() bool {
+		return verified != nil
+	})
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if err := workflow.Await(ctx, func() bool {
+		return workflow.AllHandlersFinished(ctx)
+	}); err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	if !received {
+		return workflowengine.WorkflowResult{
+			Message: "Reference verifier session expired before a presentation was received",
+		}, nil
+	}
+	if !verified.Valid {
+		errCode := errorcodes.Codes[errorcodes.ReferencePresentationInvalid]
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(
+			workflowengine.NewAppError(
+				workflowengine.WorkflowError{
+					Code:    errCode.Code,
+					Summary: errCode.Description,
+					Message: strings.Join(verified.Issues, "; "),
+					Details: map[string]any{"issues": verified.Issues},
+				},
+			),
+			input.RunMetadata,
+		)
+	}
+	return workflowengine.WorkflowResult{
+		Message: "Reference verifier accepted the presentation",
+		Output:  verified,
+	}, nil
+}
+
+// referenceSessionURL is the base URL of the endpoints of a reference
+// session workflow.
+func referenceSessionURL(appURL, namespace, role, workflowID string) string {
+	return utils.JoinURL(
+		appURL,
+		"api", "reference",
+		url.PathEscape(namespace),
+		role,
+		url.PathEscape(workflowID),
+	)
+}
+
+// startReferenceSession starts a reference session workflow that outlives
+// the step starting it.
+func startReferenceSession(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	workflowName string,
+	workflowID string,
+	payload any,
+) error {
+	ctx = workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:               workflowID,
+		ParentClosePolicy:        enums.PARENT_CLOSE_POLICY_ABANDON,
+		WorkflowExecutionTimeout: ReferenceSessionTimeout + time.Hour,
+	})
+	err := workflow.ExecuteChildWorkflow(ctx, workflowName, workflowengine.WorkflowInput{
+		Payload: payload,
+		Config: workflowengine.MergeTelemetryConfig(ctx, map[string]any{
+			"app_url": input.Config["app_url"],
+		}),
+	}).GetChildWorkflowExecution().Get(ctx, nil)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.ChildWorkflowExecutionError]
+		return workflowengine.NewWorkflowError(
+			workflowengine.NewAppError(
+				workflowengine.WorkflowError{
+					Code:    errCode.Code,
+					Summary: errCode.Description,
+					Message: err.Error(),
+				},
+			),
+			input.RunMetadata,
+		)
+	}
+	return nil
+}
+
+// referenceServiceError reports a failure to set up a reference session.
+func referenceServiceError(err error, input workflowengine.WorkflowInput) error {
+	errCode := errorcodes.Codes[errorcodes.ReferenceServiceFailed]
+	return workflowengine.NewWorkflowError(
+		workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: err.Error(),
+			},
+		),
+		input.RunMetadata,
+	)
+}
+
+// startReferenceIssuer starts a reference issuer session for config and
+// returns its credential offer deeplink.
+func startReferenceIssuer(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	config reference.IssuerConfig,
+) (string, error) {
+	var secrets reference.IssuerSecrets
+	var secretsErr string
+	if err := workflow.SideEffect(ctx, func(_ workflow.Context) any {
+		generated, err := reference.NewIssuerSecrets()
+		if err != nil {
+			secretsErr = err.Error()
+		}
+		return generated
+	}).Get(&secrets); err != nil {
+		return "", referenceServiceError(err, input)
+	}
+	if secretsErr != "" {
+		return "", referenceServiceError(fmt.Errorf("%s", secretsErr), input)
+	}
+
+	info := workflow.GetInfo(ctx)
+	workflowID := info.WorkflowExecution.ID + "-reference-issuer"
+	session := reference.IssuerSession{
+		IssuerURL: referenceSessionURL(appURL, info.Namespace, "issuer", workflowID),
+		Config:    config,
+		Secrets:   secrets,
+	}
+	deeplink, err := session.OfferDeeplink()
+	if err != nil {
+		return "", referenceServiceError(err, input)
+	}
+	if err := startReferenceSession(
+		ctx,
+		input,
+		ReferenceIssuerWorkflowName,
+		workflowID,
+		ReferenceIssuerWorkflowPayload{Session: session},
+	); err != nil {
+		return "", err
+	}
+	return deeplink, nil
+}
+
+// startReferenceVerifier signs the request of a reference verifier session
+// for config, starts the session and returns its deeplink.
+func startReferenceVerifier(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	config reference.VerifierConfig,
+) (string, error) {
+	var secrets reference.VerifierSecrets
+	var secretsErr string
+	if err := workflow.SideEffect(ctx, func(_ workflow.Context) any {
+		generated, err := reference.NewVerifierSecrets()
+		if err != nil {
+			secretsErr = err.Error()
+		}
+		return generated
+	}).Get(&secrets); err != nil {
+		return "", referenceServiceError(err, input)
+	}
+	if secretsErr != "" {
+		return "", referenceServiceError(fmt.Errorf("%s", secretsErr), input)
+	}
+
+	info := workflow.GetInfo(ctx)
+	workflowID := info.WorkflowExecution.ID + "-reference-verifier"
+	session := reference.VerifierSession{
+		VerifierURL: referenceSessionURL(appURL, info.Namespace, "verifier", workflowID),
+		Config:      config,
+		Secrets:     secrets,
+	}
+
+	act := activities.NewSignReferenceRequestActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.SignReferenceRequestActivityPayload{Session: session},
+	}).Get(ctx, &result); err != nil {
+		return "", workflowengine.NewWorkflowError(err, input.RunMetadata)
+	}
+	signed, err := workflowengine.DecodePayload[activities.SignReferenceRequestActivityOutput](
+		result.Output,
+	)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		return "", workflowengine.NewWorkflowError(
+			workflowengine.NewAppError(
+				workflowengine.WorkflowError{
+					Code:    errCode.Code,
+					Summary: errCode.Description,
+					Message: fmt.Sprintf("%s: output", act.Name()),
+					Details: map[string]any{"payload": result.Output},
+				},
+			),
+			input.RunMetadata,
+		)
+	}
+	session.ClientID = signed.ClientID
+	session.RequestObject = signed.RequestObject
+
+	if err := startReferenceSession(
+		ctx,
+		input,
+		ReferenceVerifierWorkflowName,
+		workflowID,
+		ReferenceVerifierWorkflowPayload{Session: session},
+	); err != nil {
+		return "", err
+	}
+	return session.Deeplink(), nil
+}
@@ -0,0 +1,335 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+const testReferenceIssuerURL = "https://example.com/api/reference/default/issuer/session"
+
+// referenceUpdate sends an update and hands its result to onComplete.
+func referenceUpdate(
+	t *testing.T,
+	env *testsuite.TestWorkflowEnvironment,
+	name string,
+	arg any,
+	onComplete func(result any),
+) {
+	env.UpdateWorkflow(name, uuid.NewString(), &testsuite.TestUpdateCallback{
+		OnAccept: func() {},
+		OnReject: func(err error) {
+			t.Errorf("update %s rejected: %v", name, err)
+		},
+		OnComplete: func(result any, err error) {
+			require.NoError(t, err)
+			onComplete(result)
+		},
+	}, arg)
+}
+
+func TestReferenceIssuerWorkflow(t *testing.T) {
+	holder, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	act := activities.NewIssueReferenceCredentialActivity()
+	env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{Name: act.Name()})
+
+	session := reference.IssuerSession{
+		IssuerURL: testReferenceIssuerURL,
+		Config:    reference.IssuerConfig{Configuration: reference.ConfigurationPID},
+		Secrets: reference.IssuerSecrets{
+			PreAuthorizedCode: "code",
+			AccessToken:       "token",
+			Nonce:             "nonce",
+		},
+	}
+	proof, err := reference.SignProof(holder, testReferenceIssuerURL, "nonce", time.Now())
+	require.NoError(t, err)
+
+	var credentialResponse ReferenceCredentialResponse
+	env.RegisterDelayedCallback(func() {
+		referenceUpdate(t, env, ReferenceCredentialUpdate, reference.CredentialRequest{
+			AccessToken:             "token",
+			CredentialConfiguration: reference.ConfigurationPID,
+			Proofs:                  []string{proof},
+		}, func(result any) {
+			response := result.(ReferenceCredentialResponse)
+			require.Equal(t, "invalid_token", response.Error.Code)
+		})
+	}, time.Second)
+	env.RegisterDelayedCallback(func() {
+		referenceUpdate(t, env, ReferenceTokenUpdate, reference.TokenRequest{
+			GrantType:         reference.GrantPreAuthorizedCode,
+			PreAuthorizedCode: "code",
+		}, func(result any) {
+			response := result.(ReferenceTokenResponse)
+			require.Nil(t, response.Error)
+			require.Equal(t, "token", response.Token.AccessToken)
+		})
+	}, 2*time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.UpdateWorkflow(ReferenceCredentialUpdate, "credential", &testsuite.TestUpdateCallback{
+			OnAccept: func() {},
+			OnReject: func(err error) { t.Errorf("credential update rejected: %v", err) },
+			OnComplete: func(result any, err error) {
+				require.NoError(t, err)
+				credentialResponse = result.(ReferenceCredentialResponse)
+			},
+		}, reference.CredentialRequest{
+			AccessToken:             "token",
+			CredentialConfiguration: reference.ConfigurationPID,
+			Proofs:                  []string{proof},
+		})
+	}, 3*time.Second)
+
+	env.ExecuteWorkflow(NewReferenceIssuerWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config:  map[string]any{"app_url": "https://example.com"},
+		Payload: ReferenceIssuerWorkflowPayload{Session: session},
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Nil(t, credentialResponse.Error)
+	require.Len(t, credentialResponse.Credentials, 1)
+
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, "Reference issuer issued 1 credential(s)", result.Message)
+}
+
+func TestReferenceIssuerWorkflowExpires(t *testing.T) {
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+
+	env.ExecuteWorkflow(NewReferenceIssuerWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: map[string]any{"app_url": "https://example.com"},
+		Payload: ReferenceIssuerWorkflowPayload{Session: reference.IssuerSession{
+			IssuerURL: testReferenceIssuerURL,
+			Config:    reference.IssuerConfig{Configuration: reference.ConfigurationPID},
+		}},
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Contains(t, result.Message, "expired")
+}
+
+func TestReferenceVerifierWorkflow(t *testing.T) {
+	session := reference.VerifierSession{
+		VerifierURL: "https://example.com/api/reference/default/verifier/session",
+		Config:      reference.VerifierConfig{Credentials: []string{reference.ConfigurationPID}},
+		Secrets:     reference.VerifierSecrets{Nonce: "nonce", State: "state"},
+	}
+
+	testCases := []struct {
+		name      string
+		result    reference.VerificationResult
+		errorCode string
+	}{
+		{
+			name:   "accepts a valid presentation",
+			result: reference.VerificationResult{Valid: true},
+		},
+		{
+			name: "fails on a rejected presentation",
+			result: reference.VerificationResult{
+				Issues: []string{"pid_sd_jwt: no presentation"},
+			},
+			errorCode: errorcodes.Codes[errorcodes.ReferencePresentationInvalid].Code,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testSuite := &testsuite.WorkflowTestSuite{}
+			env := testSuite.NewTestWorkflowEnvironment()
+			act := activities.NewVerifyReferencePresentationActivity()
+			env.RegisterActivityWithOptions(
+				act.Execute,
+				activity.RegisterOptions{Name: act.Name()},
+			)
+			env.OnActivity(act.Name(), mock.Anything, mock.Anything).
+				Return(workflowengine.ActivityResult{Output: tc.result}, nil).Once()
+
+			env.RegisterDelayedCallback(func() {
+				referenceUpdate(t, env, ReferenceResponseUpdate, ReferenceResponseRequest{
+					VPToken: "{}",
+					State:   "other",
+				}, func(result any) {
+					response := result.(ReferenceResponseResult)
+					require.Equal(t, "invalid_request", response.Error.Code)
+					require.Nil(t, response.Result)
+				})
+			}, time.Second)
+			env.RegisterDelayedCallback(func() {
+				referenceUpdate(t, env, ReferenceResponseUpdate, ReferenceResponseRequest{
+					VPToken: "{}",
+					State:   "state",
+				}, func(result any) {
+					response := result.(ReferenceResponseResult)
+					require.Equal(t, tc.result.Valid, response.Result.Valid)
+					require.Equal(t, tc.result.Valid, response.Error == nil)
+				})
+			}, 2*time.Second)
+
+			env.ExecuteWorkflow(
+				NewReferenceVerifierWorkflow().Workflow,
+				workflowengine.WorkflowInput{
+					Config:  map[string]any{"app_url": "https://example.com"},
+					Payload: ReferenceVerifierWorkflowPayload{Session: session},
+				},
+			)
+
+			require.True(t, env.IsWorkflowCompleted())
+			if tc.errorCode != "" {
+				require.ErrorContains(t, env.GetWorkflowError(), tc.errorCode)
+				return
+			}
+			require.NoError(t, env.GetWorkflowError())
+		})
+	}
+}
+
+func TestGetCredentialOfferWorkflowReferenceIssuer(t *testing.T) {
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	child := NewReferenceIssuerWorkflow()
+	env.RegisterWorkflowWithOptions(child.Workflow, workflow.RegisterOptions{Name: child.Name()})
+	w := NewGetCredentialOfferWorkflow()
+	env.RegisterWorkflowWithOptions(w.Workflow, workflow.RegisterOptions{Name: w.Name()})
+
+	var started ReferenceIssuerWorkflowPayload
+	env.OnWorkflow(child.Name(), mock.Anything, mock.Anything).
+		Return(func(_ workflow.Context, input workflowengine.WorkflowInput) (
+			workflowengine.WorkflowResult, error,
+		) {
+			payload, err := workflowengine.DecodePayload[ReferenceIssuerWorkflowPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			started = payload
+			return workflowengine.WorkflowResult{}, nil
+		})
+
+	env.ExecuteWorkflow(w.Name(), workflowengine.WorkflowInput{
+		Config: map[string]any{"app_url": "https://example.com"},
+		Payload: GetCredentialOfferWorkflowPayload{
+			ReferenceIssuer: &reference.IssuerConfig{
+				Configuration: reference.ConfigurationMDL,
+				TxCode:        "1234",
+			},
+		},
+		ActivityOptions: &DefaultActivityOptions,
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+
+	deeplink, err := url.Parse(result.Output.(string))
+	require.NoError(t, err)
+	require.Equal(t, "openid-credential-offer", deeplink.Scheme)
+	var offer map[string]any
+	require.NoError(t, json.Unmarshal([]byte(deeplink.Query().Get("credential_offer")), &offer))
+	require.Equal(
+		t,
+		"https://example.com/api/reference/default-test-namespace/issuer/"+
+			"default-test-workflow-id-reference-issuer",
+		offer["credential_issuer"],
+	)
+	require.Equal(t, []any{reference.ConfigurationMDL}, offer["credential_configuration_ids"])
+
+	require.Equal(t, offer["credential_issuer"], started.Session.IssuerURL)
+	require.Equal(t, "1234", started.Session.Config.TxCode)
+	require.NotEmpty(t, started.Session.Secrets.PreAuthorizedCode)
+}
+
+func TestGetUseCaseVerificationDeeplinkWorkflowReferenceVerifier(t *testing.T) {
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	signAct := activities.NewSignReferenceRequestActivity()
+	env.RegisterActivityWithOptions(
+		signAct.Execute,
+		activity.RegisterOptions{Name: signAct.Name()},
+	)
+	child := NewReferenceVerifierWorkflow()
+	env.RegisterWorkflowWithOptions(child.Workflow, workflow.RegisterOptions{Name: child.Name()})
+	w := NewGetUseCaseVerificationDeeplinkWorkflow()
+	env.RegisterWorkflowWithOptions(w.Workflow, workflow.RegisterOptions{Name: w.Name()})
+
+	env.OnActivity(signAct.Name(), mock.Anything, mock.Anything).
+		Return(workflowengine.ActivityResult{
+			Output: activities.SignReferenceRequestActivityOutput{
+				ClientID:      "x509_san_dns:example.com",
+				RequestObject: "request-object",
+			},
+		}, nil)
+	var started ReferenceVerifierWorkflowPayload
+	env.OnWorkflow(child.Name(), mock.Anything, mock.Anything).
+		Return(func(_ workflow.Context, input workflowengine.WorkflowInput) (
+			workflowengine.WorkflowResult, error,
+		) {
+			payload, err := workflowengine.DecodePayload[ReferenceVerifierWorkflowPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			started = payload
+			return workflowengine.WorkflowResult{}, nil
+		})
+
+	env.ExecuteWorkflow(
+		w.Name(),
+		workflowengine.WorkflowInput{
+			Config: map[string]any{"app_url": "https://example.com"},
+			Payload: GetUseCaseVerificationDeeplinkWorkflowPayload{
+				ReferenceVerifier: &reference.VerifierConfig{
+					Credentials: []string{reference.ConfigurationPID},
+				},
+			},
+			ActivityOptions: &DefaultActivityOptions,
+		},
+	)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+
+	deeplink, err := url.Parse(result.Output.(string))
+	require.NoError(t, err)
+	require.Equal(t, "openid4vp", deeplink.Scheme)
+	require.Equal(t, "x509_san_dns:example.com", deeplink.Query().Get("client_id"))
+	require.Equal(
+		t,
+		"https://example.com/api/reference/default-test-namespace/verifier/"+
+			"default-test-workflow-id-reference-verifier/request.jwt",
+		deeplink.Query().Get("request_uri"),
+	)
+	require.Equal(t, "request-object", started.Session.RequestObject)
+	require.NotEmpty(t, started.Session.Secrets.State)
+}
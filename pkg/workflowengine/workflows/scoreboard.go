@@ -4,6 +4,7 @@
 package workflows
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/pipeline"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
@@ -38,6 +40,7 @@ type AggregatedPipelineStats struct {
 	FirstExecutionDate  string                  `json:"first_execution_date"`
 	LastExecutionDate   string                  `json:"last_execution_date"`
 	LastExecution       *LatestExecutionDetails `json:"last_execution,omitempty"`
+	FlakySteps          []pipeline.FlakyStep    `json:"flaky_steps,omitempty"`
 }
 
 type LatestExecutionDetails struct {
@@ -362,6 +365,8 @@ func (w *AggregateScoreboardWorkflow) aggregateSinglePipeline(
 	// Update dates
 	w.updateDates(stats, pipeline)
 
+	w.aggregateFlakySteps(stats, pipeline)
+
 	// Track last successful run
 	w.trackLastRun(pipeline, namespace, pipelineID, lastRunMap)
 }
@@ -413,6 +418,30 @@ func (w *AggregateScoreboardWorkflow) aggregateRunnerTypes(
 	}
 }
 
+// aggregateFlakySteps keeps the first non-empty flaky_steps list: flakiness is
+// computed per pipeline, so every namespace reports the same steps.
+func (w *AggregateScoreboardWorkflow) aggregateFlakySteps(
+	stats *AggregatedPipelineStats,
+	pipelineStats map[string]any,
+) {
+	if len(stats.FlakySteps) > 0 {
+		return
+	}
+	raw, ok := pipelineStats["flaky_steps"].([]any)
+	if !ok || len(raw) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	var flaky []pipeline.FlakyStep
+	if err := json.Unmarshal(encoded, &flaky); err != nil {
+		return
+	}
+	stats.FlakySteps = flaky
+}
+
 func (w *AggregateScoreboardWorkflow) updateDates(
 	stats *AggregatedPipelineStats,
 	pipeline map[string]any,
@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	TrustedListImportTaskQueue    = "TrustedListImportTaskQueue"
+	TrustedListImportWorkflowName = "Import EU Trusted List Entities"
+)
+
+var trustedListImportStartWorkflowWithOptions = workflowengine.StartWorkflowWithOptions
+
+// TrustedListImportWorkflow walks the EU List of Trusted Lists, verifies
+// every national trusted list against the signing certificates the LOTL
+// pins for it and imports their PID/EAA providers as credential issuers
+// and their relying parties as verifiers.
+type TrustedListImportWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+// TrustedListSource identifies the trusted list an entity was found in.
+type TrustedListSource struct {
+	URL            string    `json:"url"`
+	Territory      string    `json:"territory"`
+	OperatorName   string    `json:"operator_name"`
+	SequenceNumber int       `json:"sequence_number"`
+	IssueDate      time.Time `json:"issue_date"`
+}
+
+// StoreTrustedListEntityRequest asks to create or update the record of a
+// trusted list entity.
+type StoreTrustedListEntityRequest struct {
+	OrgID   string             `json:"orgID"    validate:"required"`
+	LOTLURL string             `json:"lotl_url" validate:"required"`
+	List    TrustedListSource  `json:"list"`
+	Entity  trustedlist.Entity `json:"entity"`
+}
+
+// StoreTrustedListEntityResponse is the record an entity was stored in.
+type StoreTrustedListEntityResponse struct {
+	Collection string `json:"collection"`
+	ID         string `json:"id"`
+	Created    bool   `json:"created"`
+}
+
+func NewTrustedListImportWorkflow() *TrustedListImportWorkflow {
+	w := &TrustedListImportWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *TrustedListImportWorkflow) Name() string {
+	return TrustedListImportWorkflowName
+}
+
+func (w *TrustedListImportWorkflow) GetOptions() workflow.ActivityOptions {
+	return DefaultActivityOptions
+}
+
+func (w *TrustedListImportWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *TrustedListImportWorkflow) Start(
+	namespace string,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       "Trusted-List-Import-" + uuid.NewString(),
+		TaskQueue:                TrustedListImportTaskQueue,
+		WorkflowExecutionTimeout: 24 * time.Hour,
+	}
+
+	return trustedListImportStartWorkflowWithOptions(
+		namespace,
+		workflowOptions,
+		w.Name(),
+		input,
+	)
+}
+
+func (w *TrustedListImportWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, w.GetOptions())
+
+	appURL, ok := input.Config["app_url"].(string)
+	if !ok || appURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"app_url",
+			input.RunMetadata,
+		)
+	}
+	orgID, ok := input.Config["orgID"].(string)
+	if !ok || orgID == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"orgID",
+			input.RunMetadata,
+		)
+	}
+	lotlURL, ok := input.Config["lotl_url"].(string)
+	if !ok || lotlURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"lotl_url",
+			input.RunMetadata,
+		)
+	}
+	lotlCertificates := workflowengine.AsSliceOfStrings(input.Config["lotl_certificates"])
+	if len(lotlCertificates) == 0 {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"lotl_certificates",
+			input.RunMetadata,
+		)
+	}
+	territories := map[string]bool{}
+	for _, territory := range workflowengine.AsSliceOfStrings(input.Config["territories"]) {
+		territories[strings.ToUpper(territory)] = true
+	}
+
+	lotl, err := fetchTrustedList(ctx, input, lotlURL, lotlCertificates)
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+
+	issuers := []string{}
+	verifiers := []string{}
+	lists := []string{}
+	logs := map[string][]any{}
+	errs := map[string]any{}
+	for _, pointer := range lotl.Pointers {
+		if len(territories) > 0 && !territories[strings.ToUpper(pointer.Territory)] {
+			continue
+		}
+		national, err := fetchTrustedList(ctx, input, pointer.Location, pointer.Certificates)
+		if err != nil {
+			errs[pointer.Location] = err.Error()
+			continue
+		}
+		lists = append(lists, pointer.Location)
+
+		source := TrustedListSource{
+			URL:            national.URL,
+			Territory:      national.Territory,
+			OperatorName:   national.OperatorName,
+			SequenceNumber: national.SequenceNumber,
+			IssueDate:      national.IssueDate,
+		}
+		for _, entity := range national.Entities {
+			key := national.Territory + "/" + entity.ProviderName + "/" + entity.ServiceName
+			stored, err := storeTrustedListEntity(ctx, input, appURL, StoreTrustedListEntityRequest{
+				OrgID:   orgID,
+				LOTLURL: lotlURL,
+				List:    source,
+				Entity:  entity,
+			})
+			if err != nil {
+				errs[key] = err.Error()
+				continue
+			}
+			if entity.Role.IsIssuer() {
+				issuers = append(issuers, stored.ID)
+			} else {
+				verifiers = append(verifiers, stored.ID)
+			}
+			logs[key] = []any{stored}
+		}
+	}
+
+	return workflowengine.WorkflowResult{
+		Message: fmt.Sprintf(
+			"Imported %d credential issuers and %d verifiers from %d trusted lists "+
+				"with %d errors",
+			len(issuers),
+			len(verifiers),
+			len(lists),
+			len(errs),
+		),
+		Output: map[string]any{
+			"lotl_sequence_number": lotl.SequenceNumber,
+			"trusted_lists":        lists,
+			"issuers":              issuers,
+			"verifiers":            verifiers,
+		},
+		Log:    logs,
+		Errors: errs,
+	}, nil
+}
+
+func fetchTrustedList(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	url string,
+	certificates []string,
+) (trustedlist.Summary, error) {
+	act := activities.NewFetchTrustedListActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.FetchTrustedListActivityPayload{
+			URL:                 url,
+			TrustedCertificates: certificates,
+		},
+	}).Get(ctx, &result); err != nil {
+		return trustedlist.Summary{}, workflowengine.NewWorkflowError(err, input.RunMetadata)
+	}
+
+	summary, err := workflowengine.DecodePayload[trustedlist.Summary](result.Output)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: output", act.Name()),
+			},
+		)
+		return trustedlist.Summary{}, workflowengine.NewWorkflowError(appErr, input.RunMetadata)
+	}
+	return summary, nil
+}
+
+func storeTrustedListEntity(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	request StoreTrustedListEntityRequest,
+) (StoreTrustedListEntityResponse, error) {
+	act := activities.NewInternalHTTPActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodPost,
+			URL: utils.JoinURL(
+				appURL,
+				"api", "trusted-lists", "store-entity",
+			),
+			Headers: map[string]string{
+				workflowengine.HTTPHeaderContentType: workflowengine.MIMEApplicationJSON,
+			},
+			Body:           request,
+			ExpectedStatus: http.StatusOK,
+		},
+	}).Get(ctx, &result); err != nil {
+		return StoreTrustedListEntityResponse{}, workflowengine.NewWorkflowError(
+			err,
+			input.RunMetadata,
+		)
+	}
+
+	body, _ := result.Output.(map[string]any)["body"].(map[string]any)
+	stored, err := workflowengine.DecodePayload[StoreTrustedListEntityResponse](body)
+	if body == nil || err != nil || stored.ID == "" {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: body", act.Name()),
+			},
+		)
+		return StoreTrustedListEntityResponse{}, workflowengine.NewWorkflowError(
+			appErr,
+			input.RunMetadata,
+		)
+	}
+	return stored, nil
+}
@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/trustedlist"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func registerTrustedListImportActivities(env *testsuite.TestWorkflowEnvironment) {
+	fetchAct := activities.NewFetchTrustedListActivity()
+	internalAct := activities.NewInternalHTTPActivity()
+	env.RegisterActivityWithOptions(
+		fetchAct.Execute,
+		activity.RegisterOptions{Name: fetchAct.Name()},
+	)
+	env.RegisterActivityWithOptions(
+		internalAct.Execute,
+		activity.RegisterOptions{Name: internalAct.Name()},
+	)
+}
+
+func trustedListImportConfig() map[string]any {
+	return map[string]any{
+		"app_url":           "https://example.com",
+		"orgID":             "org123",
+		"lotl_url":          "https://lotl.example/eu-lotl.xml",
+		"lotl_certificates": []any{"LOTLCERT"},
+	}
+}
+
+func TestTrustedListImportWorkflow(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerTrustedListImportActivities(env)
+
+	summaries := map[string]trustedlist.Summary{
+		"https://lotl.example/eu-lotl.xml": {
+			Type:           trustedlist.TypeListOfTheLists,
+			SequenceNumber: 342,
+			Pointers: []trustedlist.PointerSummary{
+				{
+					Location:     "https://it.example/tl.xml",
+					Territory:    "IT",
+					Certificates: []string{"ITCERT"},
+				},
+				{
+					Location:     "https://de.example/tl.xml",
+					Territory:    "DE",
+					Certificates: []string{"DECERT"},
+				},
+				{
+					Location:     "https://fr.example/tl.xml",
+					Territory:    "FR",
+					Certificates: []string{"FRCERT"},
+				},
+			},
+		},
+		"https://it.example/tl.xml": {
+			URL:            "https://it.example/tl.xml",
+			Territory:      "IT",
+			SequenceNumber: 12,
+			Entities: []trustedlist.Entity{
+				{
+					Role:         trustedlist.RolePIDProvider,
+					ProviderName: "IPZS",
+					ServiceName:  "PID",
+					SupplyPoints: []string{"https://pid.example.it"},
+				},
+				{
+					Role:         trustedlist.RoleRelyingParty,
+					ProviderName: "Bank",
+					ServiceName:  "Onboarding",
+					SupplyPoints: []string{"https://bank.example.it"},
+				},
+			},
+		},
+		"https://de.example/tl.xml": {
+			URL:       "https://de.example/tl.xml",
+			Territory: "DE",
+			Entities: []trustedlist.Entity{{
+				Role:         trustedlist.RoleEAAProvider,
+				ProviderName: "Bundesdruckerei",
+				ServiceName:  "EAA",
+			}},
+		},
+	}
+	expectedCertificates := map[string]string{
+		"https://lotl.example/eu-lotl.xml": "LOTLCERT",
+		"https://it.example/tl.xml":        "ITCERT",
+		"https://de.example/tl.xml":        "DECERT",
+		"https://fr.example/tl.xml":        "FRCERT",
+	}
+
+	fetchAct := activities.NewFetchTrustedListActivity()
+	env.OnActivity(fetchAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.FetchTrustedListActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			require.Equal(
+				t,
+				[]string{expectedCertificates[payload.URL]},
+				payload.TrustedCertificates,
+			)
+			summary, ok := summaries[payload.URL]
+			if !ok {
+				return workflowengine.ActivityResult{}, temporal.NewNonRetryableApplicationError(
+					"signature invalid",
+					errorcodes.TrustedListSignatureInvalid,
+					nil,
+				)
+			}
+			return workflowengine.ActivityResult{Output: summary}, nil
+		},
+	)
+
+	var mu sync.Mutex
+	var stored []StoreTrustedListEntityRequest
+	internalAct := activities.NewInternalHTTPActivity()
+	env.OnActivity(internalAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.InternalHTTPActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			require.True(t, strings.HasSuffix(payload.URL, "/api/trusted-lists/store-entity"))
+			request, err := workflowengine.DecodePayload[StoreTrustedListEntityRequest](
+				payload.Body,
+			)
+			require.NoError(t, err)
+			mu.Lock()
+			stored = append(stored, request)
+			id := fmt.Sprintf("record%d", len(stored))
+			mu.Unlock()
+			collection := "verifiers"
+			if request.Entity.Role.IsIssuer() {
+				collection = "credential_issuers"
+			}
+			return workflowengine.ActivityResult{Output: map[string]any{
+				"body": StoreTrustedListEntityResponse{
+					Collection: collection,
+					ID:         id,
+					Created:    true,
+				},
+			}}, nil
+		},
+	)
+
+	env.ExecuteWorkflow(NewTrustedListImportWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: trustedListImportConfig(),
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(
+		t,
+		"Imported 2 credential issuers and 1 verifiers from 2 trusted lists with 1 errors",
+		result.Message,
+	)
+	require.Contains(t, result.Errors, "https://fr.example/tl.xml")
+
+	require.Len(t, stored, 3)
+	require.Equal(t, "org123", stored[0].OrgID)
+	require.Equal(t, "https://lotl.example/eu-lotl.xml", stored[0].LOTLURL)
+	require.Equal(t, "IT", stored[0].List.Territory)
+	require.Equal(t, 12, stored[0].List.SequenceNumber)
+	require.Equal(t, "IPZS", stored[0].Entity.ProviderName)
+	require.Equal(t, trustedlist.RoleRelyingParty, stored[1].Entity.Role)
+	require.Equal(t, "DE", stored[2].List.Territory)
+}
+
+func TestTrustedListImportWorkflowTerritories(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerTrustedListImportActivities(env)
+
+	var fetched []string
+	fetchAct := activities.NewFetchTrustedListActivity()
+	env.OnActivity(fetchAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.FetchTrustedListActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			fetched = append(fetched, payload.URL)
+			summary := trustedlist.Summary{URL: payload.URL}
+			if payload.URL == "https://lotl.example/eu-lotl.xml" {
+				summary.Pointers = []trustedlist.PointerSummary{
+					{
+						Location:     "https://it.example/tl.xml",
+						Territory:    "IT",
+						Certificates: []string{"A"},
+					},
+					{
+						Location:     "https://de.example/tl.xml",
+						Territory:    "DE",
+						Certificates: []string{"B"},
+					},
+				}
+			}
+			return workflowengine.ActivityResult{Output: summary}, nil
+		},
+	)
+
+	config := trustedListImportConfig()
+	config["territories"] = []any{"de"}
+	env.ExecuteWorkflow(NewTrustedListImportWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: config,
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(
+		t,
+		[]string{"https://lotl.example/eu-lotl.xml", "https://de.example/tl.xml"},
+		fetched,
+	)
+}
+
+func TestTrustedListImportWorkflowLOTLFailure(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerTrustedListImportActivities(env)
+
+	fetchAct := activities.NewFetchTrustedListActivity()
+	env.OnActivity(fetchAct.Name(), mock.Anything, mock.Anything).Return(
+		workflowengine.ActivityResult{},
+		temporal.NewNonRetryableApplicationError(
+			"untrusted certificate",
+			errorcodes.TrustedListSignatureInvalid,
+			nil,
+		),
+	)
+
+	env.ExecuteWorkflow(NewTrustedListImportWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: trustedListImportConfig(),
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+}
+
+func TestTrustedListImportWorkflowMissingConfig(t *testing.T) {
+	for _, key := range []string{"orgID", "lotl_url", "lotl_certificates"} {
+		t.Run(key, func(t *testing.T) {
+			suite := &testsuite.WorkflowTestSuite{}
+			env := suite.NewTestWorkflowEnvironment()
+
+			config := trustedListImportConfig()
+			delete(config, key)
+			env.ExecuteWorkflow(
+				NewTrustedListImportWorkflow().Workflow,
+				workflowengine.WorkflowInput{Config: config},
+			)
+
+			require.True(t, env.IsWorkflowCompleted())
+			err := env.GetWorkflowError()
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				errorcodes.Codes[errorcodes.MissingOrInvalidConfig].Code,
+			)
+			require.Contains(t, err.Error(), key)
+		})
+	}
+}
+
+func TestTrustedListImportWorkflowOptions(t *testing.T) {
+	require.Equal(t, DefaultActivityOptions, NewTrustedListImportWorkflow().GetOptions())
+}
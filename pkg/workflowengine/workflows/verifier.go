@@ -12,6 +12,7 @@ import (
 	"net/http"
 
 	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/reference"
 	"github.com/forkbombeu/credimi/pkg/utils"
 	"github.com/forkbombeu/credimi/pkg/workflowengine"
 	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
@@ -25,9 +26,11 @@ type GetUseCaseVerificationDeeplinkWorkflow struct {
 }
 
 // GetUseCaseVerificationDeeplinkWorkflowPayload is the payload
-// for the GetUseCaseVerificationDeeplinkWorkflow.
+// for the GetUseCaseVerificationDeeplinkWorkflow. It selects either a stored
+// use case or a session of the built-in reference verifier.
 type GetUseCaseVerificationDeeplinkWorkflowPayload struct {
-	UseCaseIdentifier string `json:"use_case_id" yaml:"use_case_id" validate:"required"`
+	UseCaseIdentifier string                    `json:"use_case_id,omitempty"        yaml:"use_case_id,omitempty"        validate:"required_without=ReferenceVerifier" xoneof:"use_case"`
+	ReferenceVerifier *reference.VerifierConfig `json:"reference_verifier,omitempty" yaml:"reference_verifier,omitempty"                                               xoneof:"use_case"`
 }
 
 func NewGetUseCaseVerificationDeeplinkWorkflow() *GetUseCaseVerificationDeeplinkWorkflow {
@@ -77,13 +80,23 @@ func (w *GetUseCaseVerificationDeeplinkWorkflow) ExecuteWorkflow(
 			input.RunMetadata,
 		)
 	}
+	if payload.ReferenceVerifier != nil {
+		deeplink, err := startReferenceVerifier(ctx, input, appURL, *payload.ReferenceVerifier)
+		if err != nil {
+			return workflowengine.WorkflowResult{}, err
+		}
+		return workflowengine.WorkflowResult{
+			Message: "Successfully started reference verifier session",
+			Output:  deeplink,
+		}, nil
+	}
 	act := activities.NewInternalHTTPActivity()
 	var result workflowengine.ActivityResult
 	request := workflowengine.ActivityInput{
 		Payload: activities.InternalHTTPActivityPayload{
 			Method: http.MethodGet,
 			URL: utils.JoinURL(
-				input.Config["app_url"].(string),
+				appURL,
 				"api", "verifier", "get-use-case-verification-deeplink",
 			),
 			QueryParams: map[string]string{
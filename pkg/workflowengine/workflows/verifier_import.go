@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	VerifierImportTaskQueue    = "VerifierImportTaskQueue"
+	VerifierImportWorkflowName = "Import OpenID4VP Verifier"
+)
+
+var verifierImportStartWorkflowWithOptions = workflowengine.StartWorkflowWithOptions
+
+// VerifierImportWorkflow fetches the OpenID4VP authorization request of a
+// verifier, validates its client identifier, client metadata, credential
+// query and encryption parameters and stores the verifier together with a
+// verification use case that replays the request.
+type VerifierImportWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+// StoreImportedVerifierRequest asks to create or update the verifier and
+// the use case of a validated authorization request.
+type StoreImportedVerifierRequest struct {
+	OrgID   string            `json:"orgID" validate:"required"`
+	URL     string            `json:"url"   validate:"required"`
+	Request vprequest.Request `json:"request"`
+	Report  vprequest.Report  `json:"report"`
+}
+
+// StoreImportedVerifierResponse is the records an import was stored in.
+type StoreImportedVerifierResponse struct {
+	VerifierID string `json:"verifier_id"`
+	UseCaseID  string `json:"use_case_id"`
+	Created    bool   `json:"created"`
+}
+
+func NewVerifierImportWorkflow() *VerifierImportWorkflow {
+	w := &VerifierImportWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *VerifierImportWorkflow) Name() string {
+	return VerifierImportWorkflowName
+}
+
+func (w *VerifierImportWorkflow) GetOptions() workflow.ActivityOptions {
+	return DefaultActivityOptions
+}
+
+func (w *VerifierImportWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *VerifierImportWorkflow) Start(
+	namespace string,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       "Verifier-Import-" + uuid.NewString(),
+		TaskQueue:                VerifierImportTaskQueue,
+		WorkflowExecutionTimeout: time.Hour,
+	}
+
+	return verifierImportStartWorkflowWithOptions(
+		namespace,
+		workflowOptions,
+		w.Name(),
+		input,
+	)
+}
+
+func (w *VerifierImportWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, w.GetOptions())
+
+	appURL, ok := input.Config["app_url"].(string)
+	if !ok || appURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"app_url",
+			input.RunMetadata,
+		)
+	}
+	orgID, ok := input.Config["orgID"].(string)
+	if !ok || orgID == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"orgID",
+			input.RunMetadata,
+		)
+	}
+	verifierURL, ok := input.Config["verifier_url"].(string)
+	if !ok || verifierURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"verifier_url",
+			input.RunMetadata,
+		)
+	}
+
+	fetchAct := activities.NewFetchVerifierRequestActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, fetchAct.Name(), workflowengine.ActivityInput{
+		Payload: activities.FetchVerifierRequestActivityPayload{URL: verifierURL},
+	}).Get(ctx, &result); err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(
+			err,
+			input.RunMetadata,
+		)
+	}
+	fetched, err := workflowengine.DecodePayload[activities.FetchVerifierRequestActivityOutput](
+		result.Output,
+	)
+	if err != nil {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: output", fetchAct.Name()),
+			},
+		)
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(
+			appErr,
+			input.RunMetadata,
+		)
+	}
+
+	stored, err := storeImportedVerifier(ctx, input, appURL, StoreImportedVerifierRequest{
+		OrgID:   orgID,
+		URL:     verifierURL,
+		Request: fetched.Request,
+		Report:  fetched.Report,
+	})
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+
+	return workflowengine.WorkflowResult{
+		Message: fmt.Sprintf(
+			"Imported verifier %s with %d credential queries",
+			fetched.ClientID,
+			len(fetched.Credentials),
+		),
+		Output: map[string]any{
+			"verifier_id":      stored.VerifierID,
+			"use_case_id":      stored.UseCaseID,
+			"created":          stored.Created,
+			"version":          fetched.Version,
+			"client_id":        fetched.ClientID,
+			"client_id_scheme": fetched.ClientIDScheme,
+		},
+		Log: map[string][]any{"report": {fetched.Report}},
+	}, nil
+}
+
+func storeImportedVerifier(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+	appURL string,
+	request StoreImportedVerifierRequest,
+) (StoreImportedVerifierResponse, error) {
+	act := activities.NewInternalHTTPActivity()
+	var result workflowengine.ActivityResult
+	if err := workflow.ExecuteActivity(ctx, act.Name(), workflowengine.ActivityInput{
+		Payload: activities.InternalHTTPActivityPayload{
+			Method: http.MethodPost,
+			URL: utils.JoinURL(
+				appURL,
+				"api", "verifier", "store-imported",
+			),
+			Headers: map[string]string{
+				workflowengine.HTTPHeaderContentType: workflowengine.MIMEApplicationJSON,
+			},
+			Body:           request,
+			ExpectedStatus: http.StatusOK,
+		},
+	}).Get(ctx, &result); err != nil {
+		return StoreImportedVerifierResponse{}, workflowengine.NewWorkflowError(
+			err,
+			input.RunMetadata,
+		)
+	}
+
+	body, _ := result.Output.(map[string]any)["body"].(map[string]any)
+	stored, err := workflowengine.DecodePayload[StoreImportedVerifierResponse](body)
+	if body == nil || err != nil || stored.VerifierID == "" || stored.UseCaseID == "" {
+		errCode := errorcodes.Codes[errorcodes.UnexpectedActivityOutput]
+		appErr := workflowengine.NewAppError(
+			workflowengine.WorkflowError{
+				Code:    errCode.Code,
+				Summary: errCode.Description,
+				Message: fmt.Sprintf("%s: body", act.Name()),
+			},
+		)
+		return StoreImportedVerifierResponse{}, workflowengine.NewWorkflowError(
+			appErr,
+			input.RunMetadata,
+		)
+	}
+	return stored, nil
+}
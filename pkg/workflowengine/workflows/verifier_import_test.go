@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/internal/errorcodes"
+	"github.com/forkbombeu/credimi/pkg/internal/vprequest"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func registerVerifierImportActivities(env *testsuite.TestWorkflowEnvironment) {
+	fetchAct := activities.NewFetchVerifierRequestActivity()
+	internalAct := activities.NewInternalHTTPActivity()
+	env.RegisterActivityWithOptions(
+		fetchAct.Execute,
+		activity.RegisterOptions{Name: fetchAct.Name()},
+	)
+	env.RegisterActivityWithOptions(
+		internalAct.Execute,
+		activity.RegisterOptions{Name: internalAct.Name()},
+	)
+}
+
+func verifierImportConfig() map[string]any {
+	return map[string]any{
+		"app_url":      "https://example.com",
+		"orgID":        "org123",
+		"verifier_url": "https://verifier.example/start",
+	}
+}
+
+func TestVerifierImportWorkflow(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerVerifierImportActivities(env)
+
+	fetched := activities.FetchVerifierRequestActivityOutput{
+		Report: vprequest.Report{
+			Valid:          true,
+			Version:        vprequest.Version10,
+			ClientID:       "verifier.example",
+			ClientIDScheme: vprequest.SchemePreRegistered,
+			Formats:        []string{vprequest.FormatSDJWT},
+			Query:          vprequest.QueryDCQL,
+			Credentials:    []string{"pid"},
+		},
+		Request: vprequest.Request{
+			Source:     vprequest.SourceBody,
+			Endpoint:   "https://verifier.example/start",
+			URL:        "openid4vp://?client_id=verifier.example",
+			Parameters: map[string]any{"client_id": "verifier.example"},
+		},
+	}
+	fetchAct := activities.NewFetchVerifierRequestActivity()
+	env.OnActivity(fetchAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.FetchVerifierRequestActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			require.Equal(t, "https://verifier.example/start", payload.URL)
+			return workflowengine.ActivityResult{Output: fetched}, nil
+		},
+	)
+
+	var stored StoreImportedVerifierRequest
+	internalAct := activities.NewInternalHTTPActivity()
+	env.OnActivity(internalAct.Name(), mock.Anything, mock.Anything).Return(
+		func(
+			_ context.Context,
+			input workflowengine.ActivityInput,
+		) (workflowengine.ActivityResult, error) {
+			payload, err := workflowengine.DecodePayload[activities.InternalHTTPActivityPayload](
+				input.Payload,
+			)
+			require.NoError(t, err)
+			require.True(t, strings.HasSuffix(payload.URL, "/api/verifier/store-imported"))
+			stored, err = workflowengine.DecodePayload[StoreImportedVerifierRequest](
+				payload.Body,
+			)
+			require.NoError(t, err)
+			return workflowengine.ActivityResult{Output: map[string]any{
+				"body": StoreImportedVerifierResponse{
+					VerifierID: "verifier1",
+					UseCaseID:  "usecase1",
+					Created:    true,
+				},
+			}}, nil
+		},
+	)
+
+	env.ExecuteWorkflow(NewVerifierImportWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: verifierImportConfig(),
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result workflowengine.WorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, "Imported verifier verifier.example with 1 credential queries", result.Message)
+	output := result.Output.(map[string]any)
+	require.Equal(t, "verifier1", output["verifier_id"])
+	require.Equal(t, "usecase1", output["use_case_id"])
+
+	require.Equal(t, "org123", stored.OrgID)
+	require.Equal(t, "https://verifier.example/start", stored.URL)
+	require.Equal(t, fetched.Request, stored.Request)
+	require.Equal(t, fetched.Report, stored.Report)
+}
+
+func TestVerifierImportWorkflowInvalidRequest(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	registerVerifierImportActivities(env)
+
+	fetchAct := activities.NewFetchVerifierRequestActivity()
+	env.OnActivity(fetchAct.Name(), mock.Anything, mock.Anything).Return(
+		workflowengine.ActivityResult{},
+		temporal.NewNonRetryableApplicationError(
+			"nonce is required",
+			errorcodes.VerifierRequestInvalid,
+			nil,
+		),
+	)
+
+	env.ExecuteWorkflow(NewVerifierImportWorkflow().Workflow, workflowengine.WorkflowInput{
+		Config: verifierImportConfig(),
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nonce is required")
+}
+
+func TestVerifierImportWorkflowMissingConfig(t *testing.T) {
+	for _, key := range []string{"orgID", "verifier_url"} {
+		t.Run(key, func(t *testing.T) {
+			suite := &testsuite.WorkflowTestSuite{}
+			env := suite.NewTestWorkflowEnvironment()
+
+			config := verifierImportConfig()
+			delete(config, key)
+			env.ExecuteWorkflow(
+				NewVerifierImportWorkflow().Workflow,
+				workflowengine.WorkflowInput{Config: config},
+			)
+
+			require.True(t, env.IsWorkflowCompleted())
+			err := env.GetWorkflowError()
+			require.Error(t, err)
+			require.Contains(
+				t,
+				err.Error(),
+				errorcodes.Codes[errorcodes.MissingOrInvalidConfig].Code,
+			)
+			require.Contains(t, err.Error(), key)
+		})
+	}
+}
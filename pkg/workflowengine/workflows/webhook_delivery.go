@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forkbombeu/credimi/pkg/utils"
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const WebhookDeliveryTaskQueue = "WebhookDeliveryTaskQueue"
+
+// webhookDeliveryRetryPolicy retries a delivery for about an hour, waiting
+// twice as long after every failed attempt.
+var webhookDeliveryRetryPolicy = &temporal.RetryPolicy{
+	InitialInterval:    30 * time.Second,
+	BackoffCoefficient: 2.0,
+	MaximumInterval:    time.Hour,
+	MaximumAttempts:    8,
+}
+
+var webhookDeliveryActivityOptions = workflow.ActivityOptions{
+	ScheduleToCloseTimeout: 2 * time.Hour,
+	StartToCloseTimeout:    time.Minute,
+	RetryPolicy:            webhookDeliveryRetryPolicy,
+}
+
+var webhookDeliveryStartWorkflowWithOptions = workflowengine.StartWorkflowWithOptions
+
+// WebhookDeliveryWorkflow sends a webhook delivery through the app, which
+// signs it and records every attempt, retrying failed attempts with
+// backoff. The delivery is marked failed once the retries are exhausted.
+type WebhookDeliveryWorkflow struct {
+	WorkflowFunc workflowengine.WorkflowFn
+}
+
+type WebhookDeliveryWorkflowInput struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+func NewWebhookDeliveryWorkflow() *WebhookDeliveryWorkflow {
+	w := &WebhookDeliveryWorkflow{}
+	w.WorkflowFunc = workflowengine.BuildWorkflow(w)
+	return w
+}
+
+func (w *WebhookDeliveryWorkflow) Name() string {
+	return "WebhookDeliveryWorkflow"
+}
+
+func (w *WebhookDeliveryWorkflow) GetOptions() workflow.ActivityOptions {
+	return webhookDeliveryActivityOptions
+}
+
+// WebhookDeliveryWorkflowID returns the id of the workflow sending
+// deliveryID.
+func WebhookDeliveryWorkflowID(deliveryID string) string {
+	return "webhook-delivery-" + deliveryID
+}
+
+func (w *WebhookDeliveryWorkflow) Start(
+	namespace string,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	payload, err := workflowengine.DecodePayload[WebhookDeliveryWorkflowInput](input.Payload)
+	if err != nil {
+		return workflowengine.WorkflowResult{}, err
+	}
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       WebhookDeliveryWorkflowID(payload.DeliveryID),
+		TaskQueue:                WebhookDeliveryTaskQueue,
+		WorkflowExecutionTimeout: 3 * time.Hour,
+	}
+
+	return webhookDeliveryStartWorkflowWithOptions(namespace, workflowOptions, w.Name(), input)
+}
+
+func (w *WebhookDeliveryWorkflow) Workflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	return w.WorkflowFunc(ctx, input)
+}
+
+func (w *WebhookDeliveryWorkflow) ExecuteWorkflow(
+	ctx workflow.Context,
+	input workflowengine.WorkflowInput,
+) (workflowengine.WorkflowResult, error) {
+	payload, err := workflowengine.DecodePayload[WebhookDeliveryWorkflowInput](input.Payload)
+	if err == nil && payload.DeliveryID == "" {
+		err = fmt.Errorf("delivery_id is required")
+	}
+	if err != nil {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingOrInvalidPayloadError(
+			err,
+			input.RunMetadata,
+		)
+	}
+
+	appURL, ok := input.Config["app_url"].(string)
+	if !ok || appURL == "" {
+		return workflowengine.WorkflowResult{}, workflowengine.NewMissingConfigError(
+			"app_url",
+			input.RunMetadata,
+		)
+	}
+
+	httpActivity := activities.NewInternalHTTPActivity()
+	deliveryRequest := func(action string, body map[string]any) workflowengine.ActivityInput {
+		return workflowengine.ActivityInput{
+			Payload: activities.InternalHTTPActivityPayload{
+				Method: http.MethodPost,
+				URL: utils.JoinURL(
+					appURL,
+					"api", "webhooks", "deliveries", payload.DeliveryID, action,
+				),
+				Headers: map[string]string{
+					workflowengine.HTTPHeaderContentType: workflowengine.MIMEApplicationJSON,
+				},
+				Body:           body,
+				ExpectedStatus: http.StatusOK,
+			},
+		}
+	}
+
+	var httpResult workflowengine.ActivityResult
+	attemptErr := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, webhookDeliveryActivityOptions),
+		httpActivity.Name(),
+		deliveryRequest("attempt", nil),
+	).Get(ctx, &httpResult)
+	if attemptErr != nil {
+		failCtx := workflow.WithActivityOptions(ctx, DefaultActivityOptions)
+		if err := workflow.ExecuteActivity(
+			failCtx,
+			httpActivity.Name(),
+			deliveryRequest("fail", map[string]any{"reason": "delivery retries exhausted"}),
+		).Get(failCtx, nil); err != nil {
+			workflow.GetLogger(ctx).Error("Unable to mark webhook delivery failed", "error", err)
+		}
+		return workflowengine.WorkflowResult{}, workflowengine.NewWorkflowError(
+			attemptErr,
+			input.RunMetadata,
+		)
+	}
+
+	output, _ := httpResult.Output.(map[string]any)
+	body, _ := output["body"].(map[string]any)
+	status, _ := body["status"].(string)
+	return workflowengine.WorkflowResult{
+		Message: "Webhook delivery " + status,
+		Output:  body,
+	}, nil
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Forkbomb BV
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package workflows
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/forkbombeu/credimi/pkg/workflowengine"
+	"github.com/forkbombeu/credimi/pkg/workflowengine/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func webhookDeliveryActionIs(action string) any {
+	return mock.MatchedBy(func(input workflowengine.ActivityInput) bool {
+		payload, ok := input.Payload.(map[string]any)
+		if !ok {
+			return false
+		}
+		url, _ := payload["url"].(string)
+		return strings.HasSuffix(url, "/api/webhooks/deliveries/delivery-1/"+action)
+	})
+}
+
+func TestWebhookDeliveryWorkflow(t *testing.T) {
+	input := workflowengine.WorkflowInput{
+		Payload: WebhookDeliveryWorkflowInput{DeliveryID: "delivery-1"},
+		Config:  map[string]any{"app_url": "https://credimi.test"},
+	}
+
+	testCases := []struct {
+		name           string
+		input          workflowengine.WorkflowInput
+		mockActivities func(env *testsuite.TestWorkflowEnvironment, name string)
+		expectError    bool
+		expectMessage  string
+	}{
+		{
+			name:  "delivered",
+			input: input,
+			mockActivities: func(env *testsuite.TestWorkflowEnvironment, name string) {
+				env.OnActivity(name, mock.Anything, webhookDeliveryActionIs("attempt")).Return(
+					workflowengine.ActivityResult{
+						Output: map[string]any{
+							"body": map[string]any{"status": "succeeded", "attempts": 1.0},
+						},
+					},
+					nil,
+				).Once()
+			},
+			expectMessage: "Webhook delivery succeeded",
+		},
+		{
+			name:  "retries exhausted",
+			input: input,
+			mockActivities: func(env *testsuite.TestWorkflowEnvironment, name string) {
+				env.OnActivity(name, mock.Anything, webhookDeliveryActionIs("attempt")).Return(
+					workflowengine.ActivityResult{},
+					errors.New("receiver answered 500"),
+				)
+				env.OnActivity(name, mock.Anything, webhookDeliveryActionIs("fail")).Return(
+					workflowengine.ActivityResult{},
+					nil,
+				).Once()
+			},
+			expectError: true,
+		},
+		{
+			name: "missing delivery",
+			input: workflowengine.WorkflowInput{
+				Payload: WebhookDeliveryWorkflowInput{},
+				Config:  map[string]any{"app_url": "https://credimi.test"},
+			},
+			mockActivities: func(*testsuite.TestWorkflowEnvironment, string) {},
+			expectError:    true,
+		},
+		{
+			name: "missing app_url",
+			input: workflowengine.WorkflowInput{
+				Payload: WebhookDeliveryWorkflowInput{DeliveryID: "delivery-1"},
+				Config:  map[string]any{},
+			},
+			mockActivities: func(*testsuite.TestWorkflowEnvironment, string) {},
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			suite := &testsuite.WorkflowTestSuite{}
+			env := suite.NewTestWorkflowEnvironment()
+			act := activities.NewInternalHTTPActivity()
+			env.RegisterActivityWithOptions(act.Execute, activity.RegisterOptions{Name: act.Name()})
+			tc.mockActivities(env, act.Name())
+
+			env.ExecuteWorkflow(NewWebhookDeliveryWorkflow().Workflow, tc.input)
+
+			var result workflowengine.WorkflowResult
+			err := env.GetWorkflowResult(&result)
+			env.AssertExpectations(t)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectMessage, result.Message)
+		})
+	}
+}
+
+func TestWebhookDeliveryWorkflowStart(t *testing.T) {
+	orig := webhookDeliveryStartWorkflowWithOptions
+	t.Cleanup(func() {
+		webhookDeliveryStartWorkflowWithOptions = orig
+	})
+
+	var capturedOptions client.StartWorkflowOptions
+	webhookDeliveryStartWorkflowWithOptions = func(
+		_ string,
+		options client.StartWorkflowOptions,
+		_ string,
+		_ workflowengine.WorkflowInput,
+	) (workflowengine.WorkflowResult, error) {
+		capturedOptions = options
+		return workflowengine.WorkflowResult{WorkflowID: options.ID}, nil
+	}
+
+	result, err := NewWebhookDeliveryWorkflow().Start(
+		DefaultNamespace,
+		workflowengine.WorkflowInput{
+			Payload: WebhookDeliveryWorkflowInput{DeliveryID: "delivery-1"},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "webhook-delivery-delivery-1", result.WorkflowID)
+	require.Equal(t, WebhookDeliveryTaskQueue, capturedOptions.TaskQueue)
+}